@@ -29,6 +29,7 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/tracing"
 	"github.com/livekit/protocol/logger"
 
 	"github.com/livekit/livekit-server/pkg/config"
@@ -272,10 +273,13 @@ func startServer(c *cli.Context) error {
 		return err
 	}
 
-	if err := prometheus.Init(currentNode.Id, currentNode.Type); err != nil {
+	if err := prometheus.Init(currentNode.Id, currentNode.Type, conf.Prometheus); err != nil {
 		return err
 	}
 
+	stopTracing := tracing.Init(conf.Tracing, currentNode.Id)
+	defer stopTracing()
+
 	server, err := service.InitializeServer(conf, currentNode)
 	if err != nil {
 		return err
@@ -51,6 +51,8 @@ const (
 	StatsUpdateInterval                  = time.Second * 10
 	TelemetryStatsUpdateInterval         = time.Second * 30
 	TelemetryNonMediaStatsUpdateInterval = time.Minute * 5
+
+	DefaultTURNCredentialTTL = 24 * time.Hour
 )
 
 var (
@@ -72,7 +74,12 @@ type Config struct {
 	TURN           TURNConfig               `yaml:"turn,omitempty"`
 	Ingress        IngressConfig            `yaml:"ingress,omitempty"`
 	SIP            SIPConfig                `yaml:"sip,omitempty"`
+	HLS            HLSConfig                `yaml:"hls,omitempty"`
+	LoadTest       LoadTestConfig           `yaml:"load_test,omitempty"`
+	Drain          DrainConfig              `yaml:"drain,omitempty"`
 	WebHook        WebHookConfig            `yaml:"webhook,omitempty"`
+	Telemetry      TelemetryConfig          `yaml:"telemetry,omitempty"`
+	Tracing        TracingConfig            `yaml:"tracing,omitempty"`
 	NodeSelector   NodeSelectorConfig       `yaml:"node_selector,omitempty"`
 	KeyFile        string                   `yaml:"key_file,omitempty"`
 	Keys           map[string]string        `yaml:"keys,omitempty"`
@@ -84,12 +91,18 @@ type Config struct {
 	Logging  LoggingConfig `yaml:"logging,omitempty"`
 	Limit    LimitConfig   `yaml:"limit,omitempty"`
 
+	SignalCompression SignalCompressionConfig `yaml:"signal_compression,omitempty"`
+
 	Development bool `yaml:"development,omitempty"`
 }
 
 type RTCConfig struct {
 	rtcconfig.RTCConfig `yaml:",inline"`
 
+	// run all ICE traffic through one port, UDP and TCP are both muxed on it.
+	// replaces udp_port and tcp_port, cannot be used with port_range_start/end
+	SinglePort uint32 `yaml:"single_port,omitempty"`
+
 	TURNServers []TURNServer `yaml:"turn_servers,omitempty"`
 
 	StrictACKs bool `yaml:"strict_acks,omitempty"`
@@ -100,6 +113,9 @@ type RTCConfig struct {
 	PacketBufferSizeVideo int `yaml:"packet_buffer_size_video,omitempty"`
 	// Number of packets to buffer for NACK - audio
 	PacketBufferSizeAudio int `yaml:"packet_buffer_size_audio,omitempty"`
+	// max number of bytes held by packet buffers of all tracks on the node, 0 means unlimited.
+	// when set, buffers also shrink when the packet rate of their track drops
+	PacketBufferMemoryLimit uint64 `yaml:"packet_buffer_memory_limit,omitempty"`
 
 	// Throttle periods for pli/fir rtcp packets
 	PLIThrottle PLIThrottleConfig `yaml:"pli_throttle,omitempty"`
@@ -109,6 +125,22 @@ type RTCConfig struct {
 	// allow TCP and TURN/TLS fallback
 	AllowTCPFallback *bool `yaml:"allow_tcp_fallback,omitempty"`
 
+	// allow the server to dial out active ICE-TCP connections to clients that prefer TCP,
+	// useful for clients behind firewalls that only permit outbound TCP
+	EnableActiveTCP bool `yaml:"enable_active_tcp,omitempty"`
+
+	// IP family preference of ICE candidate pairs on dual-stack hosts
+	ICEIPFamily ICEIPFamilyConfig `yaml:"ice_ip_family,omitempty"`
+
+	// on-demand capture of decrypted RTP/RTCP of a participant, started through the admin API
+	PacketCapture PacketCaptureConfig `yaml:"packet_capture,omitempty"`
+
+	// DSCP marking of packets for QoS-aware networks
+	DSCP DSCPConfig `yaml:"dscp,omitempty"`
+
+	// pre-bound UDP sockets new peer connections draw from when gathering candidates
+	ICESocketPool ICESocketPoolConfig `yaml:"ice_socket_pool,omitempty"`
+
 	// force a reconnect on a publication error
 	ReconnectOnPublicationError *bool `yaml:"reconnect_on_publication_error,omitempty"`
 
@@ -121,7 +153,88 @@ type RTCConfig struct {
 	// max number of bytes to buffer for data channel. 0 means unlimited
 	DataChannelMaxBufferedAmount uint64 `yaml:"data_channel_max_buffered_amount,omitempty"`
 
+	// renegotiate subscriber peer connection right away when this many transceivers are dormant after unsubscribes,
+	// so that they can be recycled for new subscriptions instead of growing SDP. 0 means unlimited
+	MaxDormantTransceivers int `yaml:"max_dormant_transceivers,omitempty"`
+
+	// limit media sections of publisher answers to the codec a track is received with (and its rtx/red)
+	// once the track is flowing, instead of all enabled codecs. red is kept for audio when offered, default false
+	PruneAnswerCodecs bool `yaml:"prune_answer_codecs,omitempty"`
+
 	ForwardStats ForwardStatsConfig `yaml:"forward_stats,omitempty"`
+
+	NackResponder NackResponderConfig `yaml:"nack_responder,omitempty"`
+
+	// interval to batch RTCP packets of a peer connection into compound packets, 0 disables batching
+	RTCPBatchInterval time.Duration `yaml:"rtcp_batch_interval,omitempty"`
+
+	ConnectionQuality ConnectionQualityConfig `yaml:"connection_quality,omitempty"`
+
+	BlankFramesOnStall BlankFramesOnStallConfig `yaml:"blank_frames_on_stall,omitempty"`
+
+	// time without video forwarded to a subscriber while the publisher is sending (e.g. a layer switch
+	// stuck waiting for a key frame) after which forwarding is restarted with a key frame request, 0 disables
+	FreezeRecovery time.Duration `yaml:"freeze_recovery,omitempty"`
+
+	// drop the remainder of H.264 frames broken by packet loss not recovered in time (and all frames
+	// referencing them) until the next key frame, trading a short freeze for decoder artifacts
+	FrameIntegrity bool `yaml:"frame_integrity,omitempty"`
+
+	// send black key frames to subscribers of a video track when the publisher mutes it,
+	// so that players do not keep showing the last frame
+	BlankFramesOnMute bool `yaml:"blank_frames_on_mute,omitempty"`
+
+	// order in which forwarders of video tracks try layers when bandwidth is constrained, per track source
+	LayerSelection LayerSelectionConfig `yaml:"layer_selection,omitempty"`
+
+	PacketMetadataCache PacketMetadataCacheConfig `yaml:"packet_metadata_cache,omitempty"`
+
+	HeaderExtensions RTPHeaderExtensionsConfig `yaml:"header_extensions,omitempty"`
+}
+
+const (
+	ConnectionQualityModelDefault = ""
+	ConnectionQualityModelMOS     = "mos"
+)
+
+// ConnectionQualityConfig selects the model scoring connection quality of tracks
+type ConnectionQualityConfig struct {
+	// "" for the default packet/bitrate/layer heuristic, "mos" for an E-model based MOS estimate
+	Model string `yaml:"model,omitempty"`
+	// multipliers of impairments in the mos model, 0 uses 1.0
+	LossWeight    float64 `yaml:"loss_weight,omitempty"`
+	DelayWeight   float64 `yaml:"delay_weight,omitempty"`
+	JitterWeight  float64 `yaml:"jitter_weight,omitempty"`
+	BitrateWeight float64 `yaml:"bitrate_weight,omitempty"`
+}
+
+// BlankFramesOnStallConfig is the time without media from an active up track after which subscribers are sent
+// blank frames (black key frames for video, silence for audio), so that players do not freeze on the last
+// decoded frame, 0 disables
+type BlankFramesOnStallConfig struct {
+	Video time.Duration `yaml:"video,omitempty"`
+	Audio time.Duration `yaml:"audio,omitempty"`
+}
+
+// RTPHeaderExtensionsConfig enables or disables RTP header extensions negotiated on publisher and subscriber
+// peer connections. Extensions are referred to by name, one of video-orientation, abs-send-time, transport-cc
+// and playout-delay, extensions required for forwarding (mid, rid, dependency descriptor) cannot be changed.
+type RTPHeaderExtensionsConfig struct {
+	Publisher  RTPHeaderExtensionToggles `yaml:"publisher,omitempty"`
+	Subscriber RTPHeaderExtensionToggles `yaml:"subscriber,omitempty"`
+}
+
+type RTPHeaderExtensionToggles struct {
+	Enable  []string `yaml:"enable,omitempty"`
+	Disable []string `yaml:"disable,omitempty"`
+}
+
+type NackResponderConfig struct {
+	// number of recently sent packets per down track to hold for answering NACKs when
+	// the packet is not available upstream, 0 disables the retransmission buffer
+	BufferSize int `yaml:"buffer_size,omitempty"`
+	// max bitrate that can be spent on retransmissions per down track, 0 means unlimited
+	BudgetKbps int `yaml:"budget_kbps,omitempty"`
 }
 
 type TURNServer struct {
@@ -130,6 +243,10 @@ type TURNServer struct {
 	Protocol   string `yaml:"protocol,omitempty"`
 	Username   string `yaml:"username,omitempty"`
 	Credential string `yaml:"credential,omitempty"`
+	// secret shared with the TURN server to issue time limited credentials per participant (TURN REST API),
+	// used instead of username/credential when set
+	Secret        string        `yaml:"secret,omitempty"`
+	CredentialTTL time.Duration `yaml:"credential_ttl,omitempty"`
 }
 
 type PLIThrottleConfig struct {
@@ -142,6 +259,11 @@ type CongestionControlProbeConfig struct {
 	BaseInterval  time.Duration `yaml:"base_interval,omitempty"`
 	BackoffFactor float64       `yaml:"backoff_factor,omitempty"`
 	MaxInterval   time.Duration `yaml:"max_interval,omitempty"`
+	// number of consecutive failed probes before probe interval backs off, 0 or 1 to back off on every failure
+	BackoffAfterFailures int `yaml:"backoff_after_failures,omitempty"`
+
+	// abort probe when packet loss (as measured by NACKs) during probe exceeds this percentage, 0 to disable
+	AbortLossPct float64 `yaml:"abort_loss_pct,omitempty"`
 
 	SettleWait    time.Duration `yaml:"settle_wait,omitempty"`
 	SettleWaitMax time.Duration `yaml:"settle_wait_max,omitempty"`
@@ -174,6 +296,8 @@ type CongestionControlConfig struct {
 	NackRatioAttenuator              float64                                `yaml:"nack_ratio_attenuator,omitempty"`
 	ExpectedUsageThreshold           float64                                `yaml:"expected_usage_threshold,omitempty"`
 	UseSendSideBWE                   bool                                   `yaml:"send_side_bandwidth_estimation,omitempty"`
+	BandwidthEstimator               string                                 `yaml:"bandwidth_estimator,omitempty"`
+	PublisherAudioTWCC               bool                                   `yaml:"publisher_audio_twcc,omitempty"`
 	ProbeMode                        CongestionControlProbeMode             `yaml:"probe_mode,omitempty"`
 	MinChannelCapacity               int64                                  `yaml:"min_channel_capacity,omitempty"`
 	ProbeConfig                      CongestionControlProbeConfig           `yaml:"probe_config,omitempty"`
@@ -193,6 +317,11 @@ type AudioConfig struct {
 	// smoothing for audioLevel values sent to the client.
 	// audioLevel will be an average of `smooth_intervals`, 0 to disable
 	SmoothIntervals uint32 `yaml:"smooth_intervals,omitempty"`
+	// hysteresis for active speakers, a speaker stays active till level is quieter than InactiveLevel,
+	// 0-127, should be larger than ActiveLevel, 0 to use ActiveLevel
+	InactiveLevel uint8 `yaml:"inactive_level,omitempty"`
+	// number of update intervals a speaker is held active after going quiet
+	ActiveHoldIntervals uint32 `yaml:"active_hold_intervals,omitempty"`
 	// enable red encoding downtrack for opus only audio up track
 	ActiveREDEncoding bool `yaml:"active_red_encoding,omitempty"`
 	// enable proxying weakest subscriber loss to publisher in RTCP Receiver Report
@@ -227,9 +356,123 @@ type PlayoutDelayConfig struct {
 	Max     int  `yaml:"max,omitempty"`
 }
 
+type ICEIPFamilyConfig struct {
+	// ipv4 or ipv6, remote candidates of the other family are held back for the head start
+	Prefer string `yaml:"prefer,omitempty"`
+	// defaults to 250ms when a family is preferred
+	HeadStart time.Duration `yaml:"head_start,omitempty"`
+	// drop remote candidates of the other family once the first pair connects
+	Prune bool `yaml:"prune,omitempty"`
+}
+
+type ICESocketPoolConfig struct {
+	// idle sockets kept per local address candidates are gathered on, 0 disables the pool
+	Size int `yaml:"size,omitempty"`
+}
+
+// DSCPConfig holds DSCP code points (0-63) packets are marked with, 0 leaves packets unmarked.
+// Marking is supported on Linux.
+type DSCPConfig struct {
+	// audio RTP packets, e.g. 46 (EF)
+	Audio uint8 `yaml:"audio,omitempty"`
+	// all other packets of ICE sockets: video, RTCP, data channels, e.g. 34 (AF41)
+	Video uint8 `yaml:"video,omitempty"`
+	// packets relayed by the embedded TURN server
+	TURN uint8 `yaml:"turn,omitempty"`
+	// signaling connections to the HTTP/WebSocket port
+	Signal uint8 `yaml:"signal,omitempty"`
+}
+
+func (d DSCPConfig) Validate() error {
+	for name, v := range map[string]uint8{"audio": d.Audio, "video": d.Video, "turn": d.TURN, "signal": d.Signal} {
+		if v > 63 {
+			return fmt.Errorf("dscp %s %d out of range 0-63", name, v)
+		}
+	}
+	return nil
+}
+
+// PacketMetadataCacheConfig sizes the window of packets sent to a subscriber that are remembered to account
+// receiver reports. Sizes are rounded up to a power of 2, a window grows with packets sent between receiver reports.
+type PacketMetadataCacheConfig struct {
+	Audio   int `yaml:"audio,omitempty"`
+	Video   int `yaml:"video,omitempty"`
+	MaxSize int `yaml:"max_size,omitempty"`
+}
+
+const (
+	// temporal layers of the current spatial layer are moved first, spatial layer changes weigh key frame cost
+	LayerSelectionPolicyBalanced = "balanced"
+	// resolution is kept at the expense of frame rate
+	LayerSelectionPolicyResolution = "resolution"
+)
+
+// LayerSelectionConfig is the layer selection policy per track source, one of LayerSelectionPolicy*
+type LayerSelectionConfig struct {
+	Camera      string `yaml:"camera,omitempty"`
+	ScreenShare string `yaml:"screen_share,omitempty"`
+}
+
+func (l LayerSelectionConfig) Validate() error {
+	for name, v := range map[string]string{"camera": l.Camera, "screen_share": l.ScreenShare} {
+		switch v {
+		case "", LayerSelectionPolicyBalanced, LayerSelectionPolicyResolution:
+		default:
+			return fmt.Errorf("layer selection policy %s %q is not one of %s, %s", name, v, LayerSelectionPolicyBalanced, LayerSelectionPolicyResolution)
+		}
+	}
+	return nil
+}
+
+type PacketCaptureConfig struct {
+	// directory captures are written to, captures are disabled when empty
+	Dir string `yaml:"dir,omitempty"`
+	// upper bound of a capture's duration, defaults to 1m
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`
+	// size at which a capture rotates to a new file, defaults to 20MB
+	MaxFileSize int64 `yaml:"max_file_size,omitempty"`
+	// number of files kept per capture, oldest is deleted on rotation, defaults to 5
+	MaxFiles int `yaml:"max_files,omitempty"`
+}
+
+type RateLimitConfig struct {
+	// requests allowed per second, 0 for no limit
+	Rate float64 `yaml:"rate,omitempty"`
+	// requests allowed at once before rate applies
+	Burst int `yaml:"burst,omitempty"`
+	// requests which would be allowed within this wait are queued instead of rejected
+	MaxWait time.Duration `yaml:"max_wait,omitempty"`
+}
+
+type DataAuditConfig struct {
+	// mirror user data packets to telemetry
+	Enabled bool `yaml:"enabled,omitempty"`
+	// include packet payload, only metadata (topic, size, destinations) is recorded otherwise
+	IncludePayload bool `yaml:"include_payload,omitempty"`
+}
+
 type VideoConfig struct {
 	DynacastPauseDelay time.Duration        `yaml:"dynacast_pause_delay,omitempty"`
 	StreamTracker      StreamTrackersConfig `yaml:"stream_tracker,omitempty"`
+	// recent media retained for late subscribers, so they start with a key frame without waiting for a PLI
+	ReplayBuffer ReplayBufferConfig `yaml:"replay_buffer,omitempty"`
+	LayerBitrate LayerBitrateConfig `yaml:"layer_bitrate,omitempty"`
+}
+
+// LayerBitrateConfig enables measurement of simulcast/SVC layer bitrates with an exponentially weighted
+// moving average, used for stream allocation instead of the stream tracker measurement
+type LayerBitrateConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// interval over which bytes are accumulated into a bitrate sample, defaults to 250ms
+	SampleInterval time.Duration `yaml:"sample_interval,omitempty"`
+	// duration after which the weight of a sample halves, defaults to 1s
+	HalfLife time.Duration `yaml:"half_life,omitempty"`
+}
+
+// ReplayBufferConfig is the duration of media retained per source type, starting at a key frame, 0 disables
+type ReplayBufferConfig struct {
+	Camera      time.Duration `yaml:"camera,omitempty"`
+	ScreenShare time.Duration `yaml:"screen_share,omitempty"`
 }
 
 type RoomConfig struct {
@@ -242,6 +485,18 @@ type RoomConfig struct {
 	EnableRemoteUnmute bool               `yaml:"enable_remote_unmute,omitempty"`
 	PlayoutDelay       PlayoutDelayConfig `yaml:"playout_delay,omitempty"`
 	SyncStreams        bool               `yaml:"sync_streams,omitempty"`
+	// aggregate bitrate (bps) forwarded to all subscribers of a room, shared among subscribers, 0 for no limit
+	MaxEgressBitrate int64 `yaml:"max_egress_bitrate,omitempty"`
+	// aggregate bitrate of rooms created with the named room configuration, overrides MaxEgressBitrate
+	MaxEgressBitrates map[string]int64 `yaml:"max_egress_bitrates,omitempty"`
+	// allocation priority (1-255) of camera tracks of the dominant speaker in subscriber stream allocators,
+	// screen share tracks are at 255 by default, 0 to disable
+	DominantSpeakerVideoPriority uint8 `yaml:"dominant_speaker_video_priority,omitempty"`
+	// minimum time a participant stays dominant speaker before boost moves to another speaker
+	DominantSpeakerMinHold time.Duration   `yaml:"dominant_speaker_min_hold,omitempty"`
+	DataAudit              DataAuditConfig `yaml:"data_audit,omitempty"`
+	// rate of participants joining a room, protects the node from reconnect storms
+	JoinRateLimit RateLimitConfig `yaml:"join_rate_limit,omitempty"`
 	// deprecated, moved to limits
 	MaxMetadataSize uint32 `yaml:"max_metadata_size,omitempty"`
 	// deprecated, moved to limits
@@ -249,6 +504,33 @@ type RoomConfig struct {
 	// deprecated, moved to limits
 	MaxParticipantIdentityLength int                                  `yaml:"max_participant_identity_length,omitempty"`
 	RoomConfigurations           map[string]livekit.RoomConfiguration `yaml:"room_configurations,omitempty"`
+	// ordered codec preference of rooms created with the named room configuration, replaces EnabledCodecs for those rooms
+	CodecPreferences map[string][]CodecSpec `yaml:"codec_preferences,omitempty"`
+	// header extensions of participants joining with a token naming the room configuration, applied on top of rtc.header_extensions
+	HeaderExtensions map[string]RTPHeaderExtensionsConfig `yaml:"header_extensions,omitempty"`
+	// rooms are closed once open this long, 0 for no limit
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`
+	// max duration of rooms created with the named room configuration, overrides MaxDuration
+	MaxDurations map[string]time.Duration `yaml:"max_durations,omitempty"`
+	// remaining time of a room with a max duration at which participants are warned
+	ExpiryWarnings []time.Duration `yaml:"expiry_warnings,omitempty"`
+}
+
+// MaxDurationFor returns the max duration of rooms created with the named room configuration
+func (r RoomConfig) MaxDurationFor(configName string) time.Duration {
+	if d, ok := r.MaxDurations[configName]; ok && configName != "" {
+		return d
+	}
+	return r.MaxDuration
+}
+
+// MaxEgressBitrateFor returns the egress bitrate limit of rooms created with the named room configuration,
+// false when the configuration does not override MaxEgressBitrate
+func (r RoomConfig) MaxEgressBitrateFor(configName string) (int64, bool) {
+	if bps, ok := r.MaxEgressBitrates[configName]; ok && configName != "" {
+		return bps, true
+	}
+	return r.MaxEgressBitrate, false
 }
 
 type CodecSpec struct {
@@ -271,6 +553,8 @@ type TURNConfig struct {
 	RelayPortRangeStart uint16 `yaml:"relay_range_start,omitempty"`
 	RelayPortRangeEnd   uint16 `yaml:"relay_range_end,omitempty"`
 	ExternalTLS         bool   `yaml:"external_tls,omitempty"`
+	// when set, credentials issued for the embedded TURN server expire after this duration
+	CredentialTTL time.Duration `yaml:"credential_ttl,omitempty"`
 }
 
 type WebHookConfig struct {
@@ -279,6 +563,29 @@ type WebHookConfig struct {
 	APIKey string `yaml:"api_key,omitempty"`
 }
 
+type TelemetryConfig struct {
+	// analytics are sent as produced when 0, otherwise buffered and sent in batches
+	// every flush interval or when a batch reaches batch size
+	FlushInterval time.Duration `yaml:"flush_interval,omitempty"`
+	// max stats or events in a batch, default 100
+	BatchSize int `yaml:"batch_size,omitempty"`
+	// fraction of tracks whose periodic stats are sent, default 1
+	StatsSampleRate float64 `yaml:"stats_sample_rate,omitempty"`
+	// fraction of rooms whose lifecycle events (room, participant, track, egress, ingress) are sent, default 1
+	LifecycleSampleRate float64 `yaml:"lifecycle_sample_rate,omitempty"`
+}
+
+type TracingConfig struct {
+	// OTLP/HTTP collector base url, e.g. http://localhost:4318, tracing is disabled when empty
+	OTLPEndpoint string `yaml:"otlp_endpoint,omitempty"`
+	// extra headers sent with each export request, e.g. for authentication
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// fraction of root spans that are sampled, child spans follow their parent, default 1
+	SampleRatio float64 `yaml:"sample_ratio,omitempty"`
+	// reported as service.name resource attribute, default livekit-server
+	ServiceName string `yaml:"service_name,omitempty"`
+}
+
 type NodeSelectorConfig struct {
 	Kind         string         `yaml:"kind,omitempty"`
 	SortBy       string         `yaml:"sort_by,omitempty"`
@@ -294,6 +601,15 @@ type SignalRelayConfig struct {
 	StreamBufferSize int           `yaml:"stream_buffer_size,omitempty"`
 }
 
+type SignalCompressionConfig struct {
+	// negotiate permessage-deflate on signal websocket connections
+	Enabled bool `yaml:"enabled,omitempty"`
+	// flate compression level, 1 (best speed) to 9 (best compression)
+	Level int `yaml:"level,omitempty"`
+	// only messages of at least this many bytes are compressed, small messages are not worth the CPU
+	MinSize int `yaml:"min_size,omitempty"`
+}
+
 // RegionConfig lists available regions and their latitude/longitude, so the selector would prefer
 // regions that are closer
 type RegionConfig struct {
@@ -313,6 +629,35 @@ type LimitConfig struct {
 	MaxRoomNameLength            int    `yaml:"max_room_name_length,omitempty"`
 	MaxParticipantIdentityLength int    `yaml:"max_participant_identity_length,omitempty"`
 	MaxParticipantNameLength     int    `yaml:"max_participant_name_length,omitempty"`
+	// total bitrate (bps) a participant can publish, 0 for no limit
+	MaxUplinkBitrate int64 `yaml:"max_uplink_bitrate,omitempty"`
+	// number of ICE servers a participant token can carry in place of the server's, 0 for no limit
+	MaxParticipantICEServers int `yaml:"max_participant_ice_servers,omitempty"`
+	// rate of CreateRoom API requests handled by a node
+	CreateRoomRateLimit RateLimitConfig `yaml:"create_room_rate_limit,omitempty"`
+	// quotas of tenants, rooms and participants scoped to a tenant by the lk.tenant token attribute
+	Tenants TenantLimitConfig `yaml:"tenants,omitempty"`
+}
+
+type TenantLimitConfig struct {
+	// quota of tenants not listed in quotas
+	Default TenantQuota            `yaml:"default,omitempty"`
+	Quotas  map[string]TenantQuota `yaml:"quotas,omitempty"`
+}
+
+// TenantQuota limits usage of a tenant across the cluster, 0 for no limit
+type TenantQuota struct {
+	MaxRooms        int `yaml:"max_rooms,omitempty"`
+	MaxParticipants int `yaml:"max_participants,omitempty"`
+	// aggregate bitrate (bps) forwarded to subscribers, shared among rooms of the tenant
+	MaxEgressBitrate int64 `yaml:"max_egress_bitrate,omitempty"`
+}
+
+func (t TenantLimitConfig) QuotaFor(tenant string) TenantQuota {
+	if q, ok := t.Quotas[tenant]; ok {
+		return q
+	}
+	return t.Default
 }
 
 func (l LimitConfig) CheckRoomNameLength(name string) bool {
@@ -346,6 +691,31 @@ type IngressConfig struct {
 
 type SIPConfig struct{}
 
+type HLSConfig struct {
+	// directory HLS output of rooms is written to, HLS output is disabled when empty
+	OutputDir string `yaml:"output_dir,omitempty"`
+	// target duration of segments, default 4s
+	SegmentDuration time.Duration `yaml:"segment_duration,omitempty"`
+	// target duration of low latency parts, default 1s
+	PartDuration time.Duration `yaml:"part_duration,omitempty"`
+	// number of segments kept in playlist, default 6
+	PlaylistSize int `yaml:"playlist_size,omitempty"`
+}
+
+type LoadTestConfig struct {
+	// allow starting load tests with synthetic participants through the admin API
+	Enabled bool `yaml:"enabled,omitempty"`
+	// max synthetic publishers of a load test, default 50
+	MaxPublishers int `yaml:"max_publishers,omitempty"`
+	// max synthetic subscribers of a load test, default 500
+	MaxSubscribers int `yaml:"max_subscribers,omitempty"`
+}
+
+type DrainConfig struct {
+	// participants migrated off the node per second when draining, default 5
+	MigrationRate float64 `yaml:"migration_rate,omitempty"`
+}
+
 type APIConfig struct {
 	// amount of time to wait for API to execute, default 2s
 	ExecutionTimeout time.Duration `yaml:"execution_timeout,omitempty"`
@@ -361,6 +731,15 @@ type PrometheusConfig struct {
 	Port     uint32 `yaml:"port,omitempty"`
 	Username string `yaml:"username,omitempty"`
 	Password string `yaml:"password,omitempty"`
+
+	// upper bounds of jitter (in microseconds) and RTT (in milliseconds) histogram buckets
+	JitterBucketsUs []float64 `yaml:"jitter_buckets_us,omitempty"`
+	RTTBucketsMs    []float64 `yaml:"rtt_buckets_ms,omitempty"`
+
+	// number of participants using the most goroutines, queued ops and packets/sec exported with
+	// per participant labels, sampled every participant_resources_interval, 0 disables
+	HottestParticipants          int           `yaml:"hottest_participants,omitempty"`
+	ParticipantResourcesInterval time.Duration `yaml:"participant_resources_interval,omitempty"`
 }
 
 type ForwardStatsConfig struct {
@@ -403,9 +782,10 @@ var DefaultConfig = Config{
 			ExpectedUsageThreshold: 0.95,
 			ProbeMode:              CongestionControlProbeModePadding,
 			ProbeConfig: CongestionControlProbeConfig{
-				BaseInterval:  3 * time.Second,
-				BackoffFactor: 1.5,
-				MaxInterval:   2 * time.Minute,
+				BaseInterval:         3 * time.Second,
+				BackoffFactor:        1.5,
+				MaxInterval:          2 * time.Minute,
+				BackoffAfterFailures: 1,
 
 				SettleWait:    250 * time.Millisecond,
 				SettleWaitMax: 10 * time.Second,
@@ -442,6 +822,16 @@ var DefaultConfig = Config{
 				NackRatioThreshold:             0.08,
 			},
 		},
+		LayerSelection: LayerSelectionConfig{
+			Camera:      LayerSelectionPolicyBalanced,
+			ScreenShare: LayerSelectionPolicyBalanced,
+		},
+		PacketMetadataCache: PacketMetadataCacheConfig{
+			Audio:   1024,
+			Video:   4096,
+			MaxSize: 32768,
+		},
+		MaxDormantTransceivers: 20,
 	},
 	Audio: AudioConfig{
 		ActiveLevel:     35, // -35dBov
@@ -537,8 +927,10 @@ var DefaultConfig = Config{
 			{Mime: webrtc.MimeTypeVP9},
 			{Mime: webrtc.MimeTypeAV1},
 		},
-		EmptyTimeout:     5 * 60,
-		DepartureTimeout: 20,
+		EmptyTimeout:           5 * 60,
+		DepartureTimeout:       20,
+		DominantSpeakerMinHold: 2 * time.Second,
+		ExpiryWarnings:         []time.Duration{5 * time.Minute, time.Minute, 10 * time.Second},
 	},
 	Limit: LimitConfig{
 		MaxMetadataSize:              64000,
@@ -553,12 +945,40 @@ var DefaultConfig = Config{
 	TURN: TURNConfig{
 		Enabled: false,
 	},
+	HLS: HLSConfig{
+		SegmentDuration: 4 * time.Second,
+		PartDuration:    time.Second,
+		PlaylistSize:    6,
+	},
+	LoadTest: LoadTestConfig{
+		MaxPublishers:  50,
+		MaxSubscribers: 500,
+	},
+	Drain: DrainConfig{
+		MigrationRate: 5,
+	},
+	Prometheus: PrometheusConfig{
+		ParticipantResourcesInterval: 30 * time.Second,
+	},
+	Telemetry: TelemetryConfig{
+		BatchSize:           100,
+		StatsSampleRate:     1,
+		LifecycleSampleRate: 1,
+	},
+	Tracing: TracingConfig{
+		SampleRatio: 1,
+		ServiceName: "livekit-server",
+	},
 	NodeSelector: NodeSelectorConfig{
 		Kind:         "any",
 		SortBy:       "random",
 		SysloadLimit: 0.9,
 		CPULoadLimit: 0.9,
 	},
+	SignalCompression: SignalCompressionConfig{
+		Level:   1,
+		MinSize: 1024,
+	},
 	SignalRelay: SignalRelayConfig{
 		RetryTimeout:     7500 * time.Millisecond,
 		MinRetryInterval: 500 * time.Millisecond,
@@ -596,9 +1016,18 @@ func NewConfig(confString string, strictMode bool, c *cli.Context, baseFlags []c
 		}
 	}
 
+	if err := conf.RTC.applySinglePort(); err != nil {
+		return nil, fmt.Errorf("could not validate RTC config: %v", err)
+	}
 	if err := conf.RTC.Validate(conf.Development); err != nil {
 		return nil, fmt.Errorf("could not validate RTC config: %v", err)
 	}
+	if err := conf.RTC.DSCP.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate RTC config: %v", err)
+	}
+	if err := conf.RTC.LayerSelection.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate RTC config: %v", err)
+	}
 
 	// expand env vars in filenames
 	file, err := homedir.Expand(os.ExpandEnv(conf.KeyFile))
@@ -647,6 +1076,22 @@ func NewConfig(confString string, strictMode bool, c *cli.Context, baseFlags []c
 	return &conf, nil
 }
 
+func (r *RTCConfig) applySinglePort() error {
+	if r.SinglePort == 0 {
+		return nil
+	}
+	if r.ICEPortRangeStart != 0 || r.ICEPortRangeEnd != 0 {
+		return errors.New("single_port cannot be used with port_range_start/port_range_end")
+	}
+	if r.UDPPort.Valid() && (r.UDPPort.Start != int(r.SinglePort) || (r.UDPPort.End != 0 && r.UDPPort.End != r.UDPPort.Start)) {
+		return fmt.Errorf("udp_port %d-%d conflicts with single_port %d", r.UDPPort.Start, r.UDPPort.End, r.SinglePort)
+	}
+
+	r.UDPPort = rtcconfig.PortRange{Start: int(r.SinglePort)}
+	r.TCPPort = r.SinglePort
+	return nil
+}
+
 func (conf *Config) IsTURNSEnabled() bool {
 	if conf.TURN.Enabled && conf.TURN.TLSPort != 0 {
 		return true
@@ -49,6 +49,53 @@ room:
 	require.Error(t, err)
 }
 
+func TestConfig_SinglePort(t *testing.T) {
+	conf, err := NewConfig(`rtc:
+  single_port: 7882`, true, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 7882, conf.RTC.UDPPort.Start)
+	require.Equal(t, 0, conf.RTC.UDPPort.End)
+	require.Equal(t, uint32(7882), conf.RTC.TCPPort)
+
+	_, err = NewConfig(`rtc:
+  single_port: 7882
+  port_range_start: 50000
+  port_range_end: 60000`, true, nil, nil)
+	require.Error(t, err)
+
+	_, err = NewConfig(`rtc:
+  single_port: 7882
+  udp_port: 7882-7892`, true, nil, nil)
+	require.Error(t, err)
+}
+
+func TestConfig_DSCP(t *testing.T) {
+	conf, err := NewConfig(`rtc:
+  dscp:
+    audio: 46
+    video: 34`, true, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, DSCPConfig{Audio: 46, Video: 34}, conf.RTC.DSCP)
+
+	_, err = NewConfig(`rtc:
+  dscp:
+    signal: 64`, true, nil, nil)
+	require.Error(t, err)
+}
+
+func TestConfig_LayerSelection(t *testing.T) {
+	conf, err := NewConfig(`rtc:
+  layer_selection:
+    screen_share: resolution`, true, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, LayerSelectionConfig{Camera: LayerSelectionPolicyBalanced, ScreenShare: LayerSelectionPolicyResolution}, conf.RTC.LayerSelection)
+
+	_, err = NewConfig(`rtc:
+  layer_selection:
+    camera: sharpest`, true, nil, nil)
+	require.Error(t, err)
+}
+
 func TestGeneratedFlags(t *testing.T) {
 	generatedFlags, err := GenerateCLIFlags(nil, false)
 	require.NoError(t, err)
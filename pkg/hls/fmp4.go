@@ -0,0 +1,322 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hls
+
+import (
+	"encoding/binary"
+)
+
+// minimal fragmented MP4 (CMAF) writer, supporting H.264 video and Opus audio
+
+const (
+	videoTimescale = 90000
+	audioTimescale = 48000
+
+	opusPreSkip = 312
+
+	sampleFlagsSync    = 0x02000000 // sample_depends_on = 2
+	sampleFlagsNonSync = 0x01010000 // sample_depends_on = 1, sample_is_non_sync_sample = 1
+)
+
+var unityMatrix = []uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000}
+
+type boxWriter struct {
+	b []byte
+}
+
+func (w *boxWriter) u8(v uint8) *boxWriter {
+	w.b = append(w.b, v)
+	return w
+}
+
+func (w *boxWriter) u16(v uint16) *boxWriter {
+	w.b = binary.BigEndian.AppendUint16(w.b, v)
+	return w
+}
+
+func (w *boxWriter) u32(v uint32) *boxWriter {
+	w.b = binary.BigEndian.AppendUint32(w.b, v)
+	return w
+}
+
+func (w *boxWriter) u64(v uint64) *boxWriter {
+	w.b = binary.BigEndian.AppendUint64(w.b, v)
+	return w
+}
+
+func (w *boxWriter) bytes(v []byte) *boxWriter {
+	w.b = append(w.b, v...)
+	return w
+}
+
+func (w *boxWriter) zeros(n int) *boxWriter {
+	w.b = append(w.b, make([]byte, n)...)
+	return w
+}
+
+func (w *boxWriter) matrix() *boxWriter {
+	for _, v := range unityMatrix {
+		w.u32(v)
+	}
+	return w
+}
+
+func box(typ string, children ...[]byte) []byte {
+	size := 8
+	for _, c := range children {
+		size += len(c)
+	}
+
+	b := make([]byte, 0, size)
+	b = binary.BigEndian.AppendUint32(b, uint32(size))
+	b = append(b, typ...)
+	for _, c := range children {
+		b = append(b, c...)
+	}
+	return b
+}
+
+func fullBox(typ string, version uint8, flags uint32, children ...[]byte) []byte {
+	header := binary.BigEndian.AppendUint32(nil, uint32(version)<<24|flags&0xFFFFFF)
+	return box(typ, append([][]byte{header}, children...)...)
+}
+
+// ------------------------------------------------------------
+
+type trackKind int
+
+const (
+	trackKindVideo trackKind = iota
+	trackKindAudio
+)
+
+type trackConfig struct {
+	id        uint32
+	kind      trackKind
+	timescale uint32
+
+	// video only
+	width  uint16
+	height uint16
+	sps    []byte
+	pps    []byte
+}
+
+func initSegment(tracks []*trackConfig) []byte {
+	ftyp := (&boxWriter{}).
+		bytes([]byte("iso6")).
+		u32(0).
+		bytes([]byte("iso6")).
+		bytes([]byte("cmfc")).
+		bytes([]byte("mp41")).b
+
+	mvhd := (&boxWriter{}).
+		u32(0).          // creation_time
+		u32(0).          // modification_time
+		u32(1000).       // timescale
+		u32(0).          // duration
+		u32(0x00010000). // rate
+		u16(0x0100).     // volume
+		zeros(10).       // reserved
+		matrix().
+		zeros(24). // pre_defined
+		u32(uint32(len(tracks) + 1)).b
+
+	moov := [][]byte{fullBox("mvhd", 0, 0, mvhd)}
+	var trexs [][]byte
+	for _, t := range tracks {
+		moov = append(moov, trak(t))
+		trexs = append(trexs, fullBox("trex", 0, 0, (&boxWriter{}).
+			u32(t.id).
+			u32(1). // default_sample_description_index
+			u32(0). // default_sample_duration
+			u32(0). // default_sample_size
+			u32(0). // default_sample_flags
+			b,
+		))
+	}
+	moov = append(moov, box("mvex", trexs...))
+
+	return append(box("ftyp", ftyp), box("moov", moov...)...)
+}
+
+func trak(t *trackConfig) []byte {
+	var volume uint16
+	if t.kind == trackKindAudio {
+		volume = 0x0100
+	}
+	tkhd := (&boxWriter{}).
+		u32(0).    // creation_time
+		u32(0).    // modification_time
+		u32(t.id). // track_ID
+		u32(0).    // reserved
+		u32(0).    // duration
+		zeros(8).  // reserved
+		u16(0).    // layer
+		u16(0).    // alternate_group
+		u16(volume).
+		u16(0). // reserved
+		matrix().
+		u32(uint32(t.width) << 16).
+		u32(uint32(t.height) << 16).b
+
+	mdhd := (&boxWriter{}).
+		u32(0). // creation_time
+		u32(0). // modification_time
+		u32(t.timescale).
+		u32(0).      // duration
+		u16(0x55c4). // language "und"
+		u16(0).b     // pre_defined
+
+	handlerType, handlerName := "vide", "VideoHandler"
+	mediaHeader := fullBox("vmhd", 0, 1, (&boxWriter{}).zeros(8).b)
+	if t.kind == trackKindAudio {
+		handlerType, handlerName = "soun", "SoundHandler"
+		mediaHeader = fullBox("smhd", 0, 0, (&boxWriter{}).zeros(4).b)
+	}
+	hdlr := (&boxWriter{}).
+		u32(0). // pre_defined
+		bytes([]byte(handlerType)).
+		zeros(12). // reserved
+		bytes([]byte(handlerName)).
+		u8(0).b
+
+	dinf := box("dinf", fullBox("dref", 0, 0, (&boxWriter{}).u32(1).b, fullBox("url ", 0, 1)))
+
+	stbl := box("stbl",
+		fullBox("stsd", 0, 0, (&boxWriter{}).u32(1).b, sampleEntry(t)),
+		fullBox("stts", 0, 0, (&boxWriter{}).u32(0).b),
+		fullBox("stsc", 0, 0, (&boxWriter{}).u32(0).b),
+		fullBox("stsz", 0, 0, (&boxWriter{}).u32(0).u32(0).b),
+		fullBox("stco", 0, 0, (&boxWriter{}).u32(0).b),
+	)
+
+	return box("trak",
+		fullBox("tkhd", 0, 3, tkhd), // enabled | in_movie
+		box("mdia",
+			fullBox("mdhd", 0, 0, mdhd),
+			fullBox("hdlr", 0, 0, hdlr),
+			box("minf", mediaHeader, dinf, stbl),
+		),
+	)
+}
+
+func sampleEntry(t *trackConfig) []byte {
+	if t.kind == trackKindAudio {
+		entry := (&boxWriter{}).
+			zeros(6). // reserved
+			u16(1).   // data_reference_index
+			zeros(8). // reserved
+			u16(2).   // channelcount
+			u16(16).  // samplesize
+			u16(0).   // pre_defined
+			u16(0).   // reserved
+			u32(audioTimescale << 16).b
+		dOps := (&boxWriter{}).
+			u8(0). // Version
+			u8(2). // OutputChannelCount
+			u16(opusPreSkip).
+			u32(audioTimescale). // InputSampleRate
+			u16(0).              // OutputGain
+			u8(0).b              // ChannelMappingFamily
+		return box("Opus", entry, box("dOps", dOps))
+	}
+
+	entry := (&boxWriter{}).
+		zeros(6).  // reserved
+		u16(1).    // data_reference_index
+		u16(0).    // pre_defined
+		u16(0).    // reserved
+		zeros(12). // pre_defined
+		u16(t.width).
+		u16(t.height).
+		u32(0x00480000). // horizresolution, 72 dpi
+		u32(0x00480000). // vertresolution, 72 dpi
+		u32(0).          // reserved
+		u16(1).          // frame_count
+		zeros(32).       // compressorname
+		u16(0x0018).     // depth
+		u16(0xFFFF).b    // pre_defined
+
+	avcC := (&boxWriter{}).
+		u8(1). // configurationVersion
+		u8(t.sps[1]).
+		u8(t.sps[2]).
+		u8(t.sps[3]).
+		u8(0xFF). // lengthSizeMinusOne = 3
+		u8(0xE1). // numOfSequenceParameterSets = 1
+		u16(uint16(len(t.sps))).
+		bytes(t.sps).
+		u8(1). // numOfPictureParameterSets
+		u16(uint16(len(t.pps))).
+		bytes(t.pps).b
+	return box("avc1", entry, box("avcC", avcC))
+}
+
+// ------------------------------------------------------------
+
+type sample struct {
+	// decode time in track timescale
+	dts      uint64
+	duration uint32
+	key      bool
+	data     []byte
+}
+
+type trackFragment struct {
+	track   *trackConfig
+	samples []*sample
+}
+
+// fragment returns a moof and mdat with samples of given tracks
+func fragment(sequenceNumber uint32, fragments []*trackFragment) []byte {
+	build := func(dataOffsets []uint32) []byte {
+		children := [][]byte{fullBox("mfhd", 0, 0, (&boxWriter{}).u32(sequenceNumber).b)}
+		for i, f := range fragments {
+			trun := (&boxWriter{}).
+				u32(uint32(len(f.samples))).
+				u32(dataOffsets[i])
+			for _, s := range f.samples {
+				flags := uint32(sampleFlagsSync)
+				if f.track.kind == trackKindVideo && !s.key {
+					flags = sampleFlagsNonSync
+				}
+				trun.u32(s.duration).u32(uint32(len(s.data))).u32(flags)
+			}
+
+			children = append(children, box("traf",
+				fullBox("tfhd", 0, 0x020000, (&boxWriter{}).u32(f.track.id).b), // default-base-is-moof
+				fullBox("tfdt", 1, 0, (&boxWriter{}).u64(f.samples[0].dts).b),
+				// data-offset-present | sample-duration-present | sample-size-present | sample-flags-present
+				fullBox("trun", 0, 0x000001|0x000100|0x000200|0x000400, trun.b),
+			))
+		}
+		return box("moof", children...)
+	}
+
+	// sizes do not depend on offsets, build once to learn the size of moof
+	dataOffsets := make([]uint32, len(fragments))
+	moofSize := uint32(len(build(dataOffsets)))
+
+	var mdat []byte
+	for i, f := range fragments {
+		dataOffsets[i] = moofSize + 8 + uint32(len(mdat))
+		for _, s := range f.samples {
+			mdat = append(mdat, s.data...)
+		}
+	}
+
+	return append(build(dataOffsets), box("mdat", mdat)...)
+}
@@ -0,0 +1,392 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hls
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/pion/rtp/codecs"
+)
+
+const (
+	naluTypeIDR = 5
+	naluTypeSPS = 7
+	naluTypePPS = 8
+	naluTypeAUD = 9
+)
+
+var errInvalidSPS = errors.New("invalid SPS")
+
+// AccessUnit is a complete H.264 picture, NAL units with 4 byte length prefixes
+type AccessUnit struct {
+	Timestamp uint64
+	Data      []byte
+	KeyFrame  bool
+}
+
+// H264Depacketizer assembles access units from RTP packets of an H.264 track.
+// After a packet is lost, access units are dropped till the next key frame.
+type H264Depacketizer struct {
+	packet codecs.H264Packet
+
+	sps []byte
+	pps []byte
+
+	initialized  bool
+	lastSN       uint64
+	needKeyFrame bool
+
+	au        []byte
+	auTS      uint64
+	auKey     bool
+	auStarted bool
+}
+
+func NewH264Depacketizer() *H264Depacketizer {
+	return &H264Depacketizer{
+		packet:       codecs.H264Packet{IsAVC: true},
+		needKeyFrame: true,
+	}
+}
+
+// ParameterSets returns the last SPS and PPS seen in the stream
+func (d *H264Depacketizer) ParameterSets() ([]byte, []byte) {
+	return d.sps, d.pps
+}
+
+// NeedKeyFrame returns true while access units are dropped waiting for a key frame
+func (d *H264Depacketizer) NeedKeyFrame() bool {
+	return d.needKeyFrame
+}
+
+// Push adds a packet, sequenceNumber and timestamp must be unwrapped.
+// Returns the access units completed by this packet.
+func (d *H264Depacketizer) Push(sequenceNumber uint64, timestamp uint64, marker bool, payload []byte) []*AccessUnit {
+	if d.initialized && sequenceNumber <= d.lastSN {
+		return nil
+	}
+
+	var aus []*AccessUnit
+	if d.initialized && sequenceNumber != d.lastSN+1 {
+		// lost packets, the access unit in progress is broken and so are the ones depending on it
+		d.resetAccessUnit()
+		d.packet = codecs.H264Packet{IsAVC: true}
+		d.needKeyFrame = true
+	}
+	d.initialized = true
+	d.lastSN = sequenceNumber
+
+	if d.auStarted && timestamp != d.auTS {
+		// marker of previous access unit was missed
+		if au := d.finishAccessUnit(); au != nil {
+			aus = append(aus, au)
+		}
+	}
+
+	nalus, err := d.packet.Unmarshal(payload)
+	if err != nil {
+		d.resetAccessUnit()
+		d.needKeyFrame = true
+		return aus
+	}
+
+	if !d.auStarted {
+		d.auStarted = true
+		d.auTS = timestamp
+	}
+	for len(nalus) >= 4 {
+		size := int(binary.BigEndian.Uint32(nalus))
+		if size == 0 || len(nalus) < 4+size {
+			break
+		}
+		nalu := nalus[4 : 4+size]
+		switch nalu[0] & 0x1F {
+		case naluTypeSPS:
+			d.sps = append(d.sps[:0], nalu...)
+		case naluTypePPS:
+			d.pps = append(d.pps[:0], nalu...)
+		case naluTypeIDR:
+			d.auKey = true
+		}
+		if nalu[0]&0x1F != naluTypeAUD {
+			d.au = append(d.au, nalus[:4+size]...)
+		}
+		nalus = nalus[4+size:]
+	}
+
+	if marker {
+		if au := d.finishAccessUnit(); au != nil {
+			aus = append(aus, au)
+		}
+	}
+	return aus
+}
+
+func (d *H264Depacketizer) finishAccessUnit() *AccessUnit {
+	defer d.resetAccessUnit()
+
+	if len(d.au) == 0 {
+		return nil
+	}
+	if d.needKeyFrame {
+		if !d.auKey {
+			return nil
+		}
+		d.needKeyFrame = false
+	}
+
+	return &AccessUnit{
+		Timestamp: d.auTS,
+		Data:      append([]byte(nil), d.au...),
+		KeyFrame:  d.auKey,
+	}
+}
+
+func (d *H264Depacketizer) resetAccessUnit() {
+	d.au = d.au[:0]
+	d.auKey = false
+	d.auStarted = false
+}
+
+// ------------------------------------------------------------
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) bit() (uint32, error) {
+	if r.pos >= len(r.data)*8 {
+		return 0, errInvalidSPS
+	}
+	b := (r.data[r.pos/8] >> (7 - r.pos%8)) & 1
+	r.pos++
+	return uint32(b), nil
+}
+
+func (r *bitReader) bits(n int) (uint32, error) {
+	var v uint32
+	for i := 0; i < n; i++ {
+		b, err := r.bit()
+		if err != nil {
+			return 0, err
+		}
+		v = v<<1 | b
+	}
+	return v, nil
+}
+
+// ue reads an unsigned exp-Golomb code
+func (r *bitReader) ue() (uint32, error) {
+	zeros := 0
+	for {
+		b, err := r.bit()
+		if err != nil {
+			return 0, err
+		}
+		if b == 1 {
+			break
+		}
+		zeros++
+		if zeros > 31 {
+			return 0, errInvalidSPS
+		}
+	}
+	v, err := r.bits(zeros)
+	if err != nil {
+		return 0, err
+	}
+	return (1<<zeros - 1) + v, nil
+}
+
+// se reads a signed exp-Golomb code
+func (r *bitReader) se() (int32, error) {
+	v, err := r.ue()
+	if err != nil {
+		return 0, err
+	}
+	if v&1 == 1 {
+		return int32(v+1) / 2, nil
+	}
+	return -int32(v / 2), nil
+}
+
+// spsDimensions returns the picture size signalled in an SPS NAL unit
+func spsDimensions(sps []byte) (uint16, uint16, error) {
+	if len(sps) < 4 {
+		return 0, 0, errInvalidSPS
+	}
+
+	// strip emulation prevention bytes
+	rbsp := make([]byte, 0, len(sps))
+	for i := 1; i < len(sps); i++ {
+		if i >= 3 && sps[i] == 3 && sps[i-1] == 0 && sps[i-2] == 0 {
+			continue
+		}
+		rbsp = append(rbsp, sps[i])
+	}
+
+	profile := rbsp[0]
+	r := &bitReader{data: rbsp, pos: 24}
+	read := func(fns ...func() error) error {
+		for _, fn := range fns {
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	skipUE := func() error { _, err := r.ue(); return err }
+	skipSE := func() error { _, err := r.se(); return err }
+	skipBit := func() error { _, err := r.bit(); return err }
+
+	if err := skipUE(); err != nil { // seq_parameter_set_id
+		return 0, 0, err
+	}
+
+	chromaFormat := uint32(1)
+	switch profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		var err error
+		if chromaFormat, err = r.ue(); err != nil {
+			return 0, 0, err
+		}
+		if chromaFormat == 3 {
+			if err := skipBit(); err != nil { // separate_colour_plane_flag
+				return 0, 0, err
+			}
+		}
+		// bit_depth_luma_minus8, bit_depth_chroma_minus8, qpprime_y_zero_transform_bypass_flag
+		if err := read(skipUE, skipUE, skipBit); err != nil {
+			return 0, 0, err
+		}
+		scalingMatrixPresent, err := r.bit()
+		if err != nil {
+			return 0, 0, err
+		}
+		if scalingMatrixPresent == 1 {
+			numLists := 8
+			if chromaFormat == 3 {
+				numLists = 12
+			}
+			for i := 0; i < numLists; i++ {
+				present, err := r.bit()
+				if err != nil {
+					return 0, 0, err
+				}
+				if present == 0 {
+					continue
+				}
+				size := 16
+				if i >= 6 {
+					size = 64
+				}
+				lastScale, nextScale := int32(8), int32(8)
+				for j := 0; j < size && nextScale != 0; j++ {
+					delta, err := r.se()
+					if err != nil {
+						return 0, 0, err
+					}
+					nextScale = (lastScale + delta + 256) % 256
+					if nextScale != 0 {
+						lastScale = nextScale
+					}
+				}
+			}
+		}
+	}
+
+	if err := skipUE(); err != nil { // log2_max_frame_num_minus4
+		return 0, 0, err
+	}
+	pocType, err := r.ue()
+	if err != nil {
+		return 0, 0, err
+	}
+	switch pocType {
+	case 0:
+		if err := skipUE(); err != nil { // log2_max_pic_order_cnt_lsb_minus4
+			return 0, 0, err
+		}
+	case 1:
+		// delta_pic_order_always_zero_flag, offset_for_non_ref_pic, offset_for_top_to_bottom_field
+		if err := read(skipBit, skipSE, skipSE); err != nil {
+			return 0, 0, err
+		}
+		numRefFrames, err := r.ue()
+		if err != nil {
+			return 0, 0, err
+		}
+		for i := uint32(0); i < numRefFrames; i++ {
+			if err := skipSE(); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	// max_num_ref_frames, gaps_in_frame_num_value_allowed_flag
+	if err := read(skipUE, skipBit); err != nil {
+		return 0, 0, err
+	}
+	widthInMbs, err := r.ue()
+	if err != nil {
+		return 0, 0, err
+	}
+	heightInMapUnits, err := r.ue()
+	if err != nil {
+		return 0, 0, err
+	}
+	frameMbsOnly, err := r.bit()
+	if err != nil {
+		return 0, 0, err
+	}
+	if frameMbsOnly == 0 {
+		if err := skipBit(); err != nil { // mb_adaptive_frame_field_flag
+			return 0, 0, err
+		}
+	}
+	if err := skipBit(); err != nil { // direct_8x8_inference_flag
+		return 0, 0, err
+	}
+
+	var cropLeft, cropRight, cropTop, cropBottom uint32
+	cropping, err := r.bit()
+	if err != nil {
+		return 0, 0, err
+	}
+	if cropping == 1 {
+		for _, v := range []*uint32{&cropLeft, &cropRight, &cropTop, &cropBottom} {
+			if *v, err = r.ue(); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	cropUnitX, cropUnitY := uint32(1), 2-frameMbsOnly
+	switch chromaFormat {
+	case 1:
+		cropUnitX, cropUnitY = 2, 2*(2-frameMbsOnly)
+	case 2:
+		cropUnitX = 2
+	}
+
+	width := (widthInMbs+1)*16 - cropUnitX*(cropLeft+cropRight)
+	height := (2-frameMbsOnly)*(heightInMapUnits+1)*16 - cropUnitY*(cropTop+cropBottom)
+	if width > 0xFFFF || height > 0xFFFF {
+		return 0, 0, errInvalidSPS
+	}
+	return uint16(width), uint16(height), nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hls
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type bitWriter struct {
+	data []byte
+	n    int
+}
+
+func (w *bitWriter) bits(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if (v>>i)&1 == 1 {
+			w.data[len(w.data)-1] |= 1 << (7 - w.n%8)
+		}
+		w.n++
+	}
+}
+
+func (w *bitWriter) ue(v uint32) {
+	v++
+	n := 0
+	for x := v; x > 1; x >>= 1 {
+		n++
+	}
+	w.bits(0, n)
+	w.bits(v, n+1)
+}
+
+// testSPS builds a baseline SPS, cropping 8 lines from the bottom when height is not a multiple of 16
+func testSPS(width, height uint32) []byte {
+	w := &bitWriter{}
+	w.bits(0x67, 8) // nal header
+	w.bits(66, 8)   // profile_idc
+	w.bits(0, 8)    // constraint flags
+	w.bits(31, 8)   // level_idc
+	w.ue(0)         // seq_parameter_set_id
+	w.ue(0)         // log2_max_frame_num_minus4
+	w.ue(0)         // pic_order_cnt_type
+	w.ue(0)         // log2_max_pic_order_cnt_lsb_minus4
+	w.ue(1)         // max_num_ref_frames
+	w.bits(0, 1)    // gaps_in_frame_num_value_allowed_flag
+	w.ue((width+15)/16 - 1)
+	w.ue((height+15)/16 - 1)
+	w.bits(1, 1) // frame_mbs_only_flag
+	w.bits(1, 1) // direct_8x8_inference_flag
+	if crop := ((height+15)/16*16 - height) / 2; crop != 0 {
+		w.bits(1, 1)
+		w.ue(0)
+		w.ue(0)
+		w.ue(0)
+		w.ue(crop)
+	} else {
+		w.bits(0, 1)
+	}
+	w.bits(0, 1) // vui_parameters_present_flag
+	w.bits(1, 1) // rbsp_stop_one_bit
+	w.bits(0, (8-w.n%8)%8)
+	return w.data
+}
+
+func TestSPSDimensions(t *testing.T) {
+	for _, size := range [][2]uint32{{1280, 720}, {1920, 1080}, {320, 180}} {
+		width, height, err := spsDimensions(testSPS(size[0], size[1]))
+		require.NoError(t, err)
+		require.Equal(t, uint16(size[0]), width)
+		require.Equal(t, uint16(size[1]), height)
+	}
+
+	_, _, err := spsDimensions([]byte{0x67, 66})
+	require.ErrorIs(t, err, errInvalidSPS)
+}
+
+func fuaPackets(nalu []byte, size int) [][]byte {
+	var payloads [][]byte
+	header := nalu[0]
+	data := nalu[1:]
+	for i := 0; i < len(data); i += size {
+		end := min(i+size, len(data))
+		fuHeader := header & 0x1F
+		if i == 0 {
+			fuHeader |= 0x80
+		}
+		if end == len(data) {
+			fuHeader |= 0x40
+		}
+		payload := []byte{header&0xE0 | 28, fuHeader}
+		payloads = append(payloads, append(payload, data[i:end]...))
+	}
+	return payloads
+}
+
+func stapA(nalus ...[]byte) []byte {
+	payload := []byte{24}
+	for _, nalu := range nalus {
+		payload = binary.BigEndian.AppendUint16(payload, uint16(len(nalu)))
+		payload = append(payload, nalu...)
+	}
+	return payload
+}
+
+func TestH264Depacketizer(t *testing.T) {
+	sps := testSPS(640, 360)
+	pps := []byte{0x68, 0xce, 0x38, 0x80}
+	idr := append([]byte{0x65}, make([]byte, 3000)...)
+	nonIDR := []byte{0x41, 1, 2, 3}
+
+	d := NewH264Depacketizer()
+
+	// delta frames before a key frame are dropped
+	require.Empty(t, d.Push(1, 0, true, nonIDR))
+	require.True(t, d.NeedKeyFrame())
+
+	require.Empty(t, d.Push(2, 3000, false, stapA(sps, pps)))
+	fragments := fuaPackets(idr, 1000)
+	var aus []*AccessUnit
+	for i, fragment := range fragments {
+		aus = append(aus, d.Push(uint64(3+i), 3000, i == len(fragments)-1, fragment)...)
+	}
+	require.Len(t, aus, 1)
+	require.True(t, aus[0].KeyFrame)
+	require.Equal(t, uint64(3000), aus[0].Timestamp)
+	require.Equal(t, 3*4+len(sps)+len(pps)+len(idr), len(aus[0].Data))
+	require.False(t, d.NeedKeyFrame())
+
+	gotSPS, gotPPS := d.ParameterSets()
+	require.Equal(t, sps, gotSPS)
+	require.Equal(t, pps, gotPPS)
+
+	sn := uint64(3 + len(fragments))
+	aus = d.Push(sn, 6000, true, nonIDR)
+	require.Len(t, aus, 1)
+	require.False(t, aus[0].KeyFrame)
+	require.Equal(t, append([]byte{0, 0, 0, 4}, nonIDR...), aus[0].Data)
+
+	// a gap drops frames till the next key frame
+	require.Empty(t, d.Push(sn+2, 12000, true, nonIDR))
+	require.True(t, d.NeedKeyFrame())
+	aus = d.Push(sn+3, 15000, true, append([]byte{0x65}, 1, 2, 3))
+	require.Len(t, aus, 1)
+	require.True(t, aus[0].KeyFrame)
+}
@@ -0,0 +1,481 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hls
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	PlaylistName    = "index.m3u8"
+	initSegmentName = "init.mp4"
+
+	writeQueueSize = 1024
+
+	// used for the duration of the last sample of a track when closing
+	defaultVideoSampleDuration = videoTimescale / 30
+	defaultAudioSampleDuration = audioTimescale / 50
+)
+
+var ErrMuxerClosed = errors.New("hls muxer closed")
+
+type MuxerParams struct {
+	// directory playlist and media are written to
+	Dir             string
+	SegmentDuration time.Duration
+	PartDuration    time.Duration
+	// number of segments listed in playlist, older segments are removed
+	PlaylistSize int
+}
+
+type Status struct {
+	Started         bool
+	Ended           bool
+	SegmentsWritten int
+	PartsWritten    int
+	Duration        time.Duration
+	LastError       error
+	LastErrorAt     time.Time
+	PendingWrites   int
+}
+
+type partInfo struct {
+	name        string
+	duration    time.Duration
+	independent bool
+}
+
+type segmentInfo struct {
+	index    int
+	name     string
+	duration time.Duration
+	parts    []*partInfo
+	data     []byte
+}
+
+// Muxer packages samples of H.264 and Opus tracks into fMP4 segments and parts of a
+// low latency HLS playlist. Segments of video playlists start at key frames.
+type Muxer struct {
+	params MuxerParams
+
+	lock   sync.Mutex
+	tracks []*muxerTrack
+
+	started   bool
+	ended     bool
+	startTime time.Time
+
+	fragmentSeq        uint32
+	partStart          time.Duration
+	segmentStart       time.Duration
+	current            *segmentInfo
+	segments           []*segmentInfo
+	maxSegmentDuration time.Duration
+	maxPartDuration    time.Duration
+
+	segmentsWritten int
+	partsWritten    int
+
+	writes    chan func() error
+	writeDone chan struct{}
+	errLock   sync.Mutex
+	lastErr   error
+	lastErrAt time.Time
+}
+
+func NewMuxer(params MuxerParams) (*Muxer, error) {
+	if params.SegmentDuration <= 0 || params.PartDuration <= 0 || params.PartDuration > params.SegmentDuration {
+		return nil, fmt.Errorf("invalid segment duration %s and part duration %s", params.SegmentDuration, params.PartDuration)
+	}
+	if params.PlaylistSize <= 0 {
+		params.PlaylistSize = 1
+	}
+	if err := os.MkdirAll(params.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	m := &Muxer{
+		params:    params,
+		current:   &segmentInfo{name: segmentName(0)},
+		writes:    make(chan func() error, writeQueueSize),
+		writeDone: make(chan struct{}),
+	}
+	go m.writeWorker()
+	return m, nil
+}
+
+// AddVideoTrack adds an H.264 track, tracks have to be added before writing samples
+func (m *Muxer) AddVideoTrack(width, height uint16) *Track {
+	return m.addTrack(&trackConfig{
+		kind:      trackKindVideo,
+		timescale: videoTimescale,
+		width:     width,
+		height:    height,
+	})
+}
+
+// AddAudioTrack adds an Opus track, tracks have to be added before writing samples
+func (m *Muxer) AddAudioTrack() *Track {
+	return m.addTrack(&trackConfig{
+		kind:      trackKindAudio,
+		timescale: audioTimescale,
+	})
+}
+
+func (m *Muxer) addTrack(config *trackConfig) *Track {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	config.id = uint32(len(m.tracks) + 1)
+	t := &muxerTrack{
+		config: config,
+		ready:  config.kind == trackKindAudio,
+	}
+	m.tracks = append(m.tracks, t)
+	return &Track{muxer: m, track: t}
+}
+
+// Close writes out buffered samples and ends the playlist
+func (m *Muxer) Close() {
+	m.lock.Lock()
+	if m.ended {
+		m.lock.Unlock()
+		return
+	}
+	m.ended = true
+
+	if m.started {
+		var end time.Duration
+		for _, t := range m.tracks {
+			if t.pending != nil {
+				if t.pending.duration == 0 {
+					t.pending.duration = defaultVideoSampleDuration
+					if t.config.kind == trackKindAudio {
+						t.pending.duration = defaultAudioSampleDuration
+					}
+				}
+				t.queued = append(t.queued, t.pending)
+				if pendingEnd := t.sessionTime(t.pending.dts + uint64(t.pending.duration)); pendingEnd > end {
+					end = pendingEnd
+				}
+				t.pending = nil
+			}
+		}
+		if end > m.partStart {
+			m.flushPartLocked(end)
+		} else {
+			end = m.partStart
+		}
+		m.closeSegmentLocked(end)
+		m.writePlaylistLocked()
+	}
+	m.lock.Unlock()
+
+	close(m.writes)
+	<-m.writeDone
+}
+
+func (m *Muxer) Status() Status {
+	m.lock.Lock()
+	status := Status{
+		Started:         m.started,
+		Ended:           m.ended,
+		SegmentsWritten: m.segmentsWritten,
+		PartsWritten:    m.partsWritten,
+		PendingWrites:   len(m.writes),
+	}
+	if m.started {
+		status.Duration = m.partStart
+	}
+	m.lock.Unlock()
+
+	m.errLock.Lock()
+	status.LastError = m.lastErr
+	status.LastErrorAt = m.lastErrAt
+	m.errLock.Unlock()
+	return status
+}
+
+func (m *Muxer) leadTrackLocked() *muxerTrack {
+	for _, t := range m.tracks {
+		if t.config.kind == trackKindVideo {
+			return t
+		}
+	}
+	if len(m.tracks) != 0 {
+		return m.tracks[0]
+	}
+	return nil
+}
+
+func (m *Muxer) writeSample(t *muxerTrack, rtpTimestamp uint64, arrival time.Time, data []byte, key bool) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.ended {
+		return ErrMuxerClosed
+	}
+
+	lead := m.leadTrackLocked()
+	if !m.started {
+		// start with a key frame of lead track once all tracks are configured
+		if t != lead || (t.config.kind == trackKindVideo && !key) {
+			return nil
+		}
+		for _, other := range m.tracks {
+			if !other.ready {
+				return nil
+			}
+		}
+
+		m.started = true
+		m.startTime = arrival
+		m.enqueueWrite(initSegmentName, initSegment(m.configsLocked()))
+	}
+
+	if !t.initialized {
+		if t.config.kind == trackKindVideo && !key {
+			return nil
+		}
+		t.initialized = true
+		t.firstRTPTimestamp = rtpTimestamp
+		if offset := arrival.Sub(m.startTime); offset > 0 {
+			t.baseDTS = uint64(offset) * uint64(t.config.timescale) / uint64(time.Second)
+		}
+	}
+
+	if rtpTimestamp < t.firstRTPTimestamp {
+		return nil
+	}
+	s := &sample{
+		dts:  t.baseDTS + rtpTimestamp - t.firstRTPTimestamp,
+		key:  key || t.config.kind == trackKindAudio,
+		data: data,
+	}
+	if t.pending != nil {
+		if s.dts <= t.pending.dts {
+			// out of order or duplicate
+			return nil
+		}
+		t.pending.duration = uint32(s.dts - t.pending.dts)
+		t.queued = append(t.queued, t.pending)
+	}
+	t.pending = s
+
+	if t != lead {
+		return nil
+	}
+
+	now := t.sessionTime(s.dts)
+	switch {
+	case s.key && now-m.segmentStart >= m.params.SegmentDuration:
+		m.flushPartLocked(now)
+		m.closeSegmentLocked(now)
+		m.writePlaylistLocked()
+
+	case now-m.partStart >= m.params.PartDuration:
+		m.flushPartLocked(now)
+		m.writePlaylistLocked()
+	}
+	return nil
+}
+
+func (m *Muxer) configsLocked() []*trackConfig {
+	configs := make([]*trackConfig, 0, len(m.tracks))
+	for _, t := range m.tracks {
+		configs = append(configs, t.config)
+	}
+	return configs
+}
+
+// flushPartLocked writes queued samples before end as a part of current segment
+func (m *Muxer) flushPartLocked(end time.Duration) {
+	var fragments []*trackFragment
+	independent := false
+	lead := m.leadTrackLocked()
+	for _, t := range m.tracks {
+		n := 0
+		for n < len(t.queued) && t.sessionTime(t.queued[n].dts) < end {
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+
+		if t == lead {
+			independent = t.queued[0].key
+		}
+		fragments = append(fragments, &trackFragment{track: t.config, samples: t.queued[:n]})
+		t.queued = t.queued[n:]
+	}
+	if len(fragments) == 0 {
+		return
+	}
+
+	m.fragmentSeq++
+	data := fragment(m.fragmentSeq, fragments)
+
+	part := &partInfo{
+		name:        fmt.Sprintf("seg%d.%d.m4s", m.current.index, len(m.current.parts)),
+		duration:    end - m.partStart,
+		independent: independent,
+	}
+	m.current.parts = append(m.current.parts, part)
+	m.current.data = append(m.current.data, data...)
+	if part.duration > m.maxPartDuration {
+		m.maxPartDuration = part.duration
+	}
+	m.partStart = end
+	m.partsWritten++
+
+	m.enqueueWrite(part.name, data)
+}
+
+func (m *Muxer) closeSegmentLocked(end time.Duration) {
+	if len(m.current.parts) == 0 {
+		return
+	}
+
+	segment := m.current
+	segment.duration = end - m.segmentStart
+	if segment.duration > m.maxSegmentDuration {
+		m.maxSegmentDuration = segment.duration
+	}
+	m.enqueueWrite(segment.name, segment.data)
+	segment.data = nil
+	m.segmentsWritten++
+
+	m.segments = append(m.segments, segment)
+	for len(m.segments) > m.params.PlaylistSize {
+		m.enqueueRemove(m.segments[0])
+		m.segments = m.segments[1:]
+	}
+
+	m.current = &segmentInfo{
+		index: segment.index + 1,
+		name:  segmentName(segment.index + 1),
+	}
+	m.segmentStart = end
+}
+
+func (m *Muxer) writePlaylistLocked() {
+	m.enqueueWrite(PlaylistName, []byte(m.playlistLocked()))
+}
+
+func segmentName(index int) string {
+	return fmt.Sprintf("seg%d.m4s", index)
+}
+
+// ------------------------------------------------------------
+
+func (m *Muxer) enqueueWrite(name string, data []byte) {
+	path := filepath.Join(m.params.Dir, name)
+	m.writes <- func() error {
+		// write to a temporary file first, so that readers never see partial content
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return err
+		}
+		return os.Rename(tmp, path)
+	}
+}
+
+func (m *Muxer) enqueueRemove(segment *segmentInfo) {
+	names := []string{segment.name}
+	for _, part := range segment.parts {
+		names = append(names, part.name)
+	}
+	m.writes <- func() error {
+		var err error
+		for _, name := range names {
+			if rerr := os.Remove(filepath.Join(m.params.Dir, name)); rerr != nil && !os.IsNotExist(rerr) {
+				err = rerr
+			}
+		}
+		return err
+	}
+}
+
+func (m *Muxer) writeWorker() {
+	defer close(m.writeDone)
+
+	for write := range m.writes {
+		if err := write(); err != nil {
+			m.errLock.Lock()
+			m.lastErr = err
+			m.lastErrAt = time.Now()
+			m.errLock.Unlock()
+		}
+	}
+}
+
+// ------------------------------------------------------------
+
+type muxerTrack struct {
+	config *trackConfig
+	ready  bool
+
+	initialized       bool
+	firstRTPTimestamp uint64
+	baseDTS           uint64
+
+	// last sample, waiting for the next one to know its duration
+	pending *sample
+	// samples not yet written to a part
+	queued []*sample
+}
+
+func (t *muxerTrack) sessionTime(dts uint64) time.Duration {
+	timescale := uint64(t.config.timescale)
+	return time.Duration(dts/timescale)*time.Second + time.Duration((dts%timescale)*uint64(time.Second)/timescale)
+}
+
+// Track writes samples of a track to the muxer
+type Track struct {
+	muxer *Muxer
+	track *muxerTrack
+}
+
+// SetParameterSets sets SPS and PPS of a video track, needed before samples are written
+func (t *Track) SetParameterSets(sps, pps []byte) {
+	if len(sps) < 4 || len(pps) == 0 {
+		return
+	}
+
+	t.muxer.lock.Lock()
+	defer t.muxer.lock.Unlock()
+
+	if t.muxer.started {
+		// init segment is already out, stream is expected to keep its parameters
+		return
+	}
+	t.track.config.sps = append([]byte(nil), sps...)
+	t.track.config.pps = append([]byte(nil), pps...)
+	if width, height, err := spsDimensions(sps); err == nil && (t.track.config.width == 0 || t.track.config.height == 0) {
+		t.track.config.width, t.track.config.height = width, height
+	}
+	t.track.ready = true
+}
+
+// WriteSample adds a sample, rtpTimestamp is in track clock rate and must be unwrapped,
+// video samples are AVC access units, i. e. NAL units with 4 byte length prefixes.
+func (t *Track) WriteSample(rtpTimestamp uint64, arrival time.Time, data []byte, key bool) error {
+	return t.muxer.writeSample(t.track, rtpTimestamp, arrival, data, key)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hls
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuxer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewMuxer(MuxerParams{
+		Dir:             dir,
+		SegmentDuration: time.Second,
+		PartDuration:    200 * time.Millisecond,
+		PlaylistSize:    2,
+	})
+	require.NoError(t, err)
+
+	video := m.AddVideoTrack(0, 0)
+	audio := m.AddAudioTrack()
+
+	start := time.Now()
+	frame := []byte{0, 0, 0, 2, 0x41, 0}
+	keyFrame := []byte{0, 0, 0, 2, 0x65, 0}
+
+	// samples are ignored until parameter sets are known
+	require.NoError(t, video.WriteSample(0, start, keyFrame, true))
+	require.False(t, m.Status().Started)
+	video.SetParameterSets(testSPS(640, 360), []byte{0x68, 0xce, 0x38, 0x80})
+
+	// 4 seconds of 30 fps video with a key frame every second and 20 ms audio frames
+	for i := 0; i < 200; i++ {
+		arrival := start.Add(time.Duration(i) * 20 * time.Millisecond)
+		require.NoError(t, audio.WriteSample(uint64(i*960), arrival, []byte{0xfc, byte(i)}, false))
+		if i%2 == 0 {
+			n := i / 2
+			data := frame
+			if n%30 == 0 {
+				data = keyFrame
+			}
+			require.NoError(t, video.WriteSample(uint64(n*3000), arrival, data, n%30 == 0))
+		}
+	}
+	require.True(t, m.Status().Started)
+
+	m.Close()
+	require.ErrorIs(t, video.WriteSample(1_000_000, time.Now(), keyFrame, true), ErrMuxerClosed)
+
+	status := m.Status()
+	require.True(t, status.Ended)
+	require.Equal(t, 4, status.SegmentsWritten)
+	require.NoError(t, status.LastError)
+	require.Zero(t, status.PendingWrites)
+
+	init, err := os.ReadFile(filepath.Join(dir, initSegmentName))
+	require.NoError(t, err)
+	require.Equal(t, []byte("ftyp"), init[4:8])
+	require.True(t, bytes.Contains(init, []byte("avcC")))
+	require.True(t, bytes.Contains(init, []byte("dOps")))
+
+	playlist, err := os.ReadFile(filepath.Join(dir, PlaylistName))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(playlist)), "\n")
+	require.Equal(t, "#EXTM3U", lines[0])
+	require.Contains(t, lines, "#EXT-X-TARGETDURATION:1")
+	require.Contains(t, lines, "#EXT-X-MEDIA-SEQUENCE:2")
+	require.Contains(t, lines, `#EXT-X-MAP:URI="init.mp4"`)
+	require.Equal(t, "#EXT-X-ENDLIST", lines[len(lines)-1])
+
+	// only segments in the window remain on disk
+	for _, name := range []string{"seg0.m4s", "seg1.m4s", "seg0.0.m4s"} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		require.True(t, os.IsNotExist(err), name)
+	}
+	for _, name := range []string{"seg2.m4s", "seg3.m4s"} {
+		require.Contains(t, lines, name)
+		segment, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.Equal(t, []byte("moof"), segment[4:8])
+	}
+	require.Contains(t, string(playlist), `#EXT-X-PART:DURATION=0.20000,URI="seg3.0.m4s",INDEPENDENT=YES`)
+}
+
+func TestMuxerParams(t *testing.T) {
+	_, err := NewMuxer(MuxerParams{Dir: t.TempDir(), SegmentDuration: time.Second, PartDuration: 2 * time.Second})
+	require.Error(t, err)
+}
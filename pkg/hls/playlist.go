@@ -0,0 +1,70 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hls
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// parts are listed for the last completed segments only
+	partsListedSegments = 2
+)
+
+// playlistLocked renders the low latency media playlist, assumes lock is already acquired
+func (m *Muxer) playlistLocked() string {
+	targetDuration := max(m.params.SegmentDuration, m.maxSegmentDuration)
+	partTarget := max(m.params.PartDuration, m.maxPartDuration)
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:9\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(targetDuration.Seconds())))
+	fmt.Fprintf(&b, "#EXT-X-SERVER-CONTROL:PART-HOLD-BACK=%.5f\n", 3*partTarget.Seconds())
+	fmt.Fprintf(&b, "#EXT-X-PART-INF:PART-TARGET=%.5f\n", partTarget.Seconds())
+
+	mediaSequence := m.current.index
+	if len(m.segments) != 0 {
+		mediaSequence = m.segments[0].index
+	}
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", mediaSequence)
+	fmt.Fprintf(&b, "#EXT-X-MAP:URI=\"%s\"\n", initSegmentName)
+
+	for i, segment := range m.segments {
+		if i >= len(m.segments)-partsListedSegments {
+			writeParts(&b, segment.parts)
+		}
+		fmt.Fprintf(&b, "#EXTINF:%.5f,\n%s\n", segment.duration.Seconds(), segment.name)
+	}
+
+	if m.ended {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	} else {
+		writeParts(&b, m.current.parts)
+	}
+	return b.String()
+}
+
+func writeParts(b *strings.Builder, parts []*partInfo) {
+	for _, part := range parts {
+		fmt.Fprintf(b, "#EXT-X-PART:DURATION=%.5f,URI=\"%s\"", part.duration.Seconds(), part.name)
+		if part.independent {
+			b.WriteString(",INDEPENDENT=YES")
+		}
+		b.WriteString("\n")
+	}
+}
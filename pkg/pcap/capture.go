@@ -0,0 +1,274 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+const (
+	packetQueueSize = 1024
+	writeBufferSize = 64 * 1024
+)
+
+var ErrInvalidCaptureParams = errors.New("invalid packet capture parameters")
+
+type CaptureParams struct {
+	// directory the capture files are written to
+	Dir string
+	// capture files are named <Name>-<index>.pcapng
+	Name string
+	// the capture closes itself after this long
+	Duration time.Duration
+	// size at which a new file is started
+	MaxFileSize int64
+	// number of files kept, the oldest one is removed when a new file is started
+	MaxFiles int
+}
+
+// Flow is a pair of endpoints packets are attributed to in the capture, packets are
+// captured after decryption, addresses do not need to be the ones used on the wire.
+// Packets are written as IPv4, other addresses are written as 0.0.0.0
+type Flow struct {
+	Local  netip.AddrPort
+	Remote netip.AddrPort
+}
+
+type Status struct {
+	Files          []string
+	PacketsWritten uint64
+	BytesWritten   int64
+	PacketsDropped uint64
+	LastError      error
+}
+
+type packet struct {
+	at      time.Time
+	flow    Flow
+	inbound bool
+	data    []byte
+}
+
+// Capture writes packets into a rotating set of pcapng files, with bounded duration and size.
+// Writes never block, packets are dropped when the file writer falls behind.
+type Capture struct {
+	params CaptureParams
+
+	lock    sync.RWMutex
+	closed  bool
+	packets chan *packet
+	timer   *time.Timer
+	done    chan struct{}
+
+	dropped atomic.Uint64
+
+	// accessed only in write worker until done
+	file     *os.File
+	buf      *bufio.Writer
+	writer   *pcapngWriter
+	fileSize int64
+	index    int
+	files    []string
+	written  uint64
+	size     int64
+	lastErr  error
+}
+
+func NewCapture(params CaptureParams) (*Capture, error) {
+	if params.Dir == "" || params.Name == "" || params.Duration <= 0 || params.MaxFileSize <= 0 || params.MaxFiles <= 0 {
+		return nil, ErrInvalidCaptureParams
+	}
+	if err := os.MkdirAll(params.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &Capture{
+		params:  params,
+		packets: make(chan *packet, packetQueueSize),
+		done:    make(chan struct{}),
+	}
+	if err := c.openFile(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	c.timer = time.AfterFunc(params.Duration, c.Close)
+	c.lock.Unlock()
+	go c.writeWorker()
+	return c, nil
+}
+
+// Write queues a copy of pkt, it is a no-op after the capture is closed
+func (c *Capture) Write(flow Flow, inbound bool, pkt []byte) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.closed {
+		return
+	}
+
+	p := &packet{
+		at:      time.Now(),
+		flow:    flow,
+		inbound: inbound,
+		data:    append([]byte(nil), pkt...),
+	}
+	select {
+	case c.packets <- p:
+	default:
+		c.dropped.Inc()
+	}
+}
+
+// Close stops capturing, queued packets are written out before files are closed
+func (c *Capture) Close() {
+	c.lock.Lock()
+	if c.closed {
+		c.lock.Unlock()
+		return
+	}
+	c.closed = true
+	c.timer.Stop()
+	close(c.packets)
+	c.lock.Unlock()
+}
+
+// Done is closed once the capture is closed and all files are written
+func (c *Capture) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Capture) IsClosed() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.closed
+}
+
+// Status returns stats of the capture, available once Done is closed
+func (c *Capture) Status() Status {
+	<-c.done
+
+	return Status{
+		Files:          append([]string(nil), c.files...),
+		PacketsWritten: c.written,
+		BytesWritten:   c.size,
+		PacketsDropped: c.dropped.Load(),
+		LastError:      c.lastErr,
+	}
+}
+
+// FirstFile returns path of the file capture starts with
+func (c *Capture) FirstFile() string {
+	return c.fileName(0)
+}
+
+func (c *Capture) fileName(index int) string {
+	return filepath.Join(c.params.Dir, fmt.Sprintf("%s-%03d.pcapng", c.params.Name, index))
+}
+
+func (c *Capture) openFile() error {
+	name := c.fileName(c.index)
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriterSize(f, writeBufferSize)
+	writer, n, err := newPCAPNGWriter(buf)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	c.file = f
+	c.buf = buf
+	c.writer = writer
+	c.fileSize = int64(n)
+	c.size += int64(n)
+	c.index++
+	c.files = append(c.files, name)
+	if len(c.files) > c.params.MaxFiles {
+		if err := os.Remove(c.files[0]); err != nil && !os.IsNotExist(err) {
+			c.lastErr = err
+		}
+		c.files = c.files[1:]
+	}
+	return nil
+}
+
+func (c *Capture) closeFile() error {
+	if c.file == nil {
+		return nil
+	}
+
+	err := c.buf.Flush()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	c.file = nil
+	c.buf = nil
+	c.writer = nil
+	return err
+}
+
+func (c *Capture) writeWorker() {
+	defer close(c.done)
+
+	for p := range c.packets {
+		if c.file == nil {
+			// stopped writing after an error, keep draining
+			continue
+		}
+
+		if err := c.writePacket(p); err != nil {
+			c.lastErr = err
+			_ = c.closeFile()
+		}
+	}
+
+	if err := c.closeFile(); err != nil {
+		c.lastErr = err
+	}
+}
+
+func (c *Capture) writePacket(p *packet) error {
+	src, dst := p.flow.Local, p.flow.Remote
+	if p.inbound {
+		src, dst = dst, src
+	}
+	n, err := c.writer.writePacket(p.at, src, dst, p.inbound, p.data)
+	if err != nil {
+		return err
+	}
+	c.written++
+	c.size += int64(n)
+	c.fileSize += int64(n)
+
+	if c.fileSize >= c.params.MaxFileSize {
+		if err := c.closeFile(); err != nil {
+			return err
+		}
+		return c.openFile()
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testFlow = Flow{
+	Local:  netip.MustParseAddrPort("10.0.0.1:50000"),
+	Remote: netip.MustParseAddrPort("10.0.0.2:40000"),
+}
+
+type testBlock struct {
+	blockType uint32
+	body      []byte
+}
+
+func readBlocks(t *testing.T, name string) []testBlock {
+	b, err := os.ReadFile(name)
+	require.NoError(t, err)
+
+	var blocks []testBlock
+	for len(b) > 0 {
+		require.GreaterOrEqual(t, len(b), 12)
+		blockLen := int(binary.LittleEndian.Uint32(b[4:]))
+		require.Zero(t, blockLen%4)
+		require.LessOrEqual(t, blockLen, len(b))
+		require.Equal(t, uint32(blockLen), binary.LittleEndian.Uint32(b[blockLen-4:]))
+		blocks = append(blocks, testBlock{
+			blockType: binary.LittleEndian.Uint32(b),
+			body:      b[8 : blockLen-4],
+		})
+		b = b[blockLen:]
+	}
+	return blocks
+}
+
+func TestCapture(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCapture(CaptureParams{
+		Dir:         dir,
+		Name:        "test",
+		Duration:    time.Minute,
+		MaxFileSize: 1 << 20,
+		MaxFiles:    1,
+	})
+	require.NoError(t, err)
+
+	rtpPacket := []byte{0x80, 0x60, 0x00, 0x01, 0, 0, 0, 1, 0, 0, 0, 2, 0xaa}
+	rtcpPacket := []byte{0x81, 0xc9, 0x00, 0x01, 0, 0, 0, 2}
+	c.Write(testFlow, true, rtpPacket)
+	c.Write(testFlow, false, rtcpPacket)
+	c.Close()
+	<-c.Done()
+
+	// writes after close are ignored
+	c.Write(testFlow, true, rtpPacket)
+
+	status := c.Status()
+	require.NoError(t, status.LastError)
+	require.Equal(t, uint64(2), status.PacketsWritten)
+	require.Equal(t, []string{c.FirstFile()}, status.Files)
+
+	blocks := readBlocks(t, c.FirstFile())
+	require.Len(t, blocks, 4)
+	require.Equal(t, uint32(pcapngBlockTypeSHB), blocks[0].blockType)
+	require.Equal(t, uint32(pcapngByteOrderMagic), binary.LittleEndian.Uint32(blocks[0].body))
+	require.Equal(t, uint32(pcapngBlockTypeIDB), blocks[1].blockType)
+	require.Equal(t, uint16(pcapngLinkTypeRaw), binary.LittleEndian.Uint16(blocks[1].body))
+
+	for i, expected := range []struct {
+		payload  []byte
+		src, dst netip.AddrPort
+		flags    uint32
+	}{
+		{rtpPacket, testFlow.Remote, testFlow.Local, pcapngEPBFlagsInbound},
+		{rtcpPacket, testFlow.Local, testFlow.Remote, pcapngEPBFlagsOutbound},
+	} {
+		block := blocks[2+i]
+		require.Equal(t, uint32(pcapngBlockTypeEPB), block.blockType)
+
+		packetLen := int(binary.LittleEndian.Uint32(block.body[12:]))
+		require.Equal(t, ipv4HeaderSize+udpHeaderSize+len(expected.payload), packetLen)
+		pkt := block.body[20 : 20+packetLen]
+
+		// IPv4 header checksums to zero
+		var sum uint32
+		for j := 0; j < ipv4HeaderSize; j += 2 {
+			sum += uint32(binary.BigEndian.Uint16(pkt[j:]))
+		}
+		for sum > 0xffff {
+			sum = (sum >> 16) + (sum & 0xffff)
+		}
+		require.Equal(t, uint32(0xffff), sum)
+
+		require.Equal(t, expected.src.Addr().AsSlice(), pkt[12:16])
+		require.Equal(t, expected.dst.Addr().AsSlice(), pkt[16:20])
+		require.Equal(t, expected.src.Port(), binary.BigEndian.Uint16(pkt[20:]))
+		require.Equal(t, expected.dst.Port(), binary.BigEndian.Uint16(pkt[22:]))
+		require.Equal(t, expected.payload, pkt[ipv4HeaderSize+udpHeaderSize:])
+
+		opts := block.body[20+(packetLen+3)&^3:]
+		require.Equal(t, uint16(pcapngOptionEPBFlags), binary.LittleEndian.Uint16(opts))
+		require.Equal(t, expected.flags, binary.LittleEndian.Uint32(opts[4:]))
+	}
+}
+
+func TestCaptureRotation(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCapture(CaptureParams{
+		Dir:         dir,
+		Name:        "test",
+		Duration:    time.Minute,
+		MaxFileSize: 1000,
+		MaxFiles:    2,
+	})
+	require.NoError(t, err)
+
+	payload := make([]byte, 200)
+	for i := 0; i < 20; i++ {
+		c.Write(testFlow, true, payload)
+	}
+	c.Close()
+
+	status := c.Status()
+	require.NoError(t, status.LastError)
+	require.Equal(t, uint64(20), status.PacketsWritten)
+	require.Len(t, status.Files, 2)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// oldest files are removed
+	_, err = os.Stat(c.FirstFile())
+	require.True(t, os.IsNotExist(err))
+	for _, name := range status.Files {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		require.LessOrEqual(t, info.Size(), int64(1000+300))
+	}
+}
+
+func TestCaptureDuration(t *testing.T) {
+	c, err := NewCapture(CaptureParams{
+		Dir:         t.TempDir(),
+		Name:        "test",
+		Duration:    50 * time.Millisecond,
+		MaxFileSize: 1000,
+		MaxFiles:    1,
+	})
+	require.NoError(t, err)
+
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("capture did not close after duration")
+	}
+	require.True(t, c.IsClosed())
+}
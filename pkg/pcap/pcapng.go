@@ -0,0 +1,149 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcap
+
+import (
+	"encoding/binary"
+	"io"
+	"net/netip"
+	"time"
+)
+
+// minimal pcapng (https://www.ietf.org/archive/id/draft-ietf-opsawg-pcapng-02.html) writer,
+// packets are written as raw IPv4/UDP so that tools like Wireshark can decode RTP/RTCP in them
+const (
+	pcapngBlockTypeSHB = 0x0a0d0d0a
+	pcapngBlockTypeIDB = 0x00000001
+	pcapngBlockTypeEPB = 0x00000006
+
+	pcapngByteOrderMagic = 0x1a2b3c4d
+	pcapngLinkTypeRaw    = 101
+
+	pcapngOptionEndOfOpt = 0
+	pcapngOptionEPBFlags = 2
+
+	pcapngEPBFlagsInbound  = 1
+	pcapngEPBFlagsOutbound = 2
+
+	ipv4HeaderSize = 20
+	udpHeaderSize  = 8
+)
+
+type pcapngWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+// newPCAPNGWriter writes section and interface headers, returns number of bytes written
+func newPCAPNGWriter(w io.Writer) (*pcapngWriter, int, error) {
+	p := &pcapngWriter{w: w}
+
+	// section header block, no options, unspecified section length
+	shb := make([]byte, 28)
+	binary.LittleEndian.PutUint32(shb[0:], pcapngBlockTypeSHB)
+	binary.LittleEndian.PutUint32(shb[4:], uint32(len(shb)))
+	binary.LittleEndian.PutUint32(shb[8:], pcapngByteOrderMagic)
+	binary.LittleEndian.PutUint16(shb[12:], 1)
+	binary.LittleEndian.PutUint16(shb[14:], 0)
+	binary.LittleEndian.PutUint64(shb[16:], 0xffffffffffffffff)
+	binary.LittleEndian.PutUint32(shb[24:], uint32(len(shb)))
+
+	// interface description block, no snap length, default microsecond timestamp resolution
+	idb := make([]byte, 20)
+	binary.LittleEndian.PutUint32(idb[0:], pcapngBlockTypeIDB)
+	binary.LittleEndian.PutUint32(idb[4:], uint32(len(idb)))
+	binary.LittleEndian.PutUint16(idb[8:], pcapngLinkTypeRaw)
+	binary.LittleEndian.PutUint32(idb[12:], 0)
+	binary.LittleEndian.PutUint32(idb[16:], uint32(len(idb)))
+
+	n, err := w.Write(append(shb, idb...))
+	if err != nil {
+		return nil, n, err
+	}
+	return p, n, nil
+}
+
+// writePacket writes payload as a UDP datagram from src to dst, returns number of bytes written
+func (p *pcapngWriter) writePacket(at time.Time, src, dst netip.AddrPort, inbound bool, payload []byte) (int, error) {
+	packetLen := ipv4HeaderSize + udpHeaderSize + len(payload)
+	paddedLen := (packetLen + 3) &^ 3
+	// block header, packet, epb_flags option, end of options, block trailer
+	blockLen := 28 + paddedLen + 8 + 4 + 4
+
+	if cap(p.buf) < blockLen {
+		p.buf = make([]byte, blockLen)
+	}
+	b := p.buf[:blockLen]
+	clear(b)
+
+	ts := uint64(at.UnixMicro())
+	binary.LittleEndian.PutUint32(b[0:], pcapngBlockTypeEPB)
+	binary.LittleEndian.PutUint32(b[4:], uint32(blockLen))
+	binary.LittleEndian.PutUint32(b[8:], 0)
+	binary.LittleEndian.PutUint32(b[12:], uint32(ts>>32))
+	binary.LittleEndian.PutUint32(b[16:], uint32(ts))
+	binary.LittleEndian.PutUint32(b[20:], uint32(packetLen))
+	binary.LittleEndian.PutUint32(b[24:], uint32(packetLen))
+
+	pkt := b[28 : 28+packetLen]
+	putIPv4UDPHeader(pkt, src, dst, len(payload))
+	copy(pkt[ipv4HeaderSize+udpHeaderSize:], payload)
+
+	opts := b[28+paddedLen:]
+	flags := uint32(pcapngEPBFlagsOutbound)
+	if inbound {
+		flags = pcapngEPBFlagsInbound
+	}
+	binary.LittleEndian.PutUint16(opts[0:], pcapngOptionEPBFlags)
+	binary.LittleEndian.PutUint16(opts[2:], 4)
+	binary.LittleEndian.PutUint32(opts[4:], flags)
+	binary.LittleEndian.PutUint16(opts[8:], pcapngOptionEndOfOpt)
+	binary.LittleEndian.PutUint16(opts[10:], 0)
+	binary.LittleEndian.PutUint32(b[blockLen-4:], uint32(blockLen))
+
+	return p.w.Write(b)
+}
+
+func putIPv4UDPHeader(b []byte, src, dst netip.AddrPort, payloadLen int) {
+	ip := b[:ipv4HeaderSize]
+	ip[0] = 0x45 // version 4, 5 words header
+	binary.BigEndian.PutUint16(ip[2:], uint16(ipv4HeaderSize+udpHeaderSize+payloadLen))
+	binary.BigEndian.PutUint16(ip[6:], 0x4000) // don't fragment
+	ip[8] = 64
+	ip[9] = 17 // UDP
+	if src.Addr().Is4() {
+		srcIP := src.Addr().As4()
+		copy(ip[12:16], srcIP[:])
+	}
+	if dst.Addr().Is4() {
+		dstIP := dst.Addr().As4()
+		copy(ip[16:20], dstIP[:])
+	}
+
+	var sum uint32
+	for i := 0; i < ipv4HeaderSize; i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(ip[i:]))
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	binary.BigEndian.PutUint16(ip[10:], ^uint16(sum))
+
+	// UDP checksum is optional over IPv4, leave it zero
+	udp := b[ipv4HeaderSize : ipv4HeaderSize+udpHeaderSize]
+	binary.BigEndian.PutUint16(udp[0:], src.Port())
+	binary.BigEndian.PutUint16(udp[2:], dst.Port())
+	binary.BigEndian.PutUint16(udp[4:], uint16(udpHeaderSize+payloadLen))
+}
@@ -62,6 +62,17 @@ type ParticipantInit struct {
 	ID                   livekit.ParticipantID
 	SubscriberAllowPause *bool
 	DisableICELite       bool
+	// ICE servers given by the access token, replacing the ones of the server
+	ICEServers []*livekit.ICEServer
+	// comma separated capabilities the client opted into
+	ClientCapabilities string
+}
+
+// grants are relayed to the RTC node as JSON, ICE servers of the token and client capabilities go along with them
+type startSessionGrants struct {
+	*auth.ClaimGrants
+	ICEServers         []*livekit.ICEServer `json:"iceServers,omitempty"`
+	ClientCapabilities string               `json:"clientCapabilities,omitempty"`
 }
 
 // Router allows multiple nodes to coordinate the participant session
@@ -113,7 +124,11 @@ func CreateRouter(rc redis.UniversalClient, node LocalNode, signalClient SignalC
 }
 
 func (pi *ParticipantInit) ToStartSession(roomName livekit.RoomName, connectionID livekit.ConnectionID) (*livekit.StartSession, error) {
-	claims, err := json.Marshal(pi.Grants)
+	claims, err := json.Marshal(startSessionGrants{
+		ClaimGrants:        pi.Grants,
+		ICEServers:         pi.ICEServers,
+		ClientCapabilities: pi.ClientCapabilities,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -143,22 +158,25 @@ func (pi *ParticipantInit) ToStartSession(roomName livekit.RoomName, connectionI
 
 func ParticipantInitFromStartSession(ss *livekit.StartSession, region string) (*ParticipantInit, error) {
 	claims := &auth.ClaimGrants{}
-	if err := json.Unmarshal([]byte(ss.GrantsJson), claims); err != nil {
+	grants := startSessionGrants{ClaimGrants: claims}
+	if err := json.Unmarshal([]byte(ss.GrantsJson), &grants); err != nil {
 		return nil, err
 	}
 
 	pi := &ParticipantInit{
-		Identity:        livekit.ParticipantIdentity(ss.Identity),
-		Name:            livekit.ParticipantName(ss.Name),
-		Reconnect:       ss.Reconnect,
-		ReconnectReason: ss.ReconnectReason,
-		Client:          ss.Client,
-		AutoSubscribe:   ss.AutoSubscribe,
-		Grants:          claims,
-		Region:          region,
-		AdaptiveStream:  ss.AdaptiveStream,
-		ID:              livekit.ParticipantID(ss.ParticipantId),
-		DisableICELite:  ss.DisableIceLite,
+		Identity:           livekit.ParticipantIdentity(ss.Identity),
+		Name:               livekit.ParticipantName(ss.Name),
+		Reconnect:          ss.Reconnect,
+		ReconnectReason:    ss.ReconnectReason,
+		Client:             ss.Client,
+		AutoSubscribe:      ss.AutoSubscribe,
+		Grants:             claims,
+		Region:             region,
+		AdaptiveStream:     ss.AdaptiveStream,
+		ID:                 livekit.ParticipantID(ss.ParticipantId),
+		DisableICELite:     ss.DisableIceLite,
+		ICEServers:         grants.ICEServers,
+		ClientCapabilities: grants.ClientCapabilities,
 	}
 	if ss.SubscriberAllowPause != nil {
 		subscriberAllowPause := *ss.SubscriberAllowPause
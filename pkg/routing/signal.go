@@ -25,9 +25,11 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/tracing"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/protocol/tracer"
 	"github.com/livekit/protocol/utils/guid"
 	"github.com/livekit/psrpc"
 	"github.com/livekit/psrpc/pkg/middleware"
@@ -99,7 +101,19 @@ func (r *signalClient) StartParticipantSignal(
 
 	l.Debugw("starting signal connection")
 
-	stream, err := r.client.RelaySignal(ctx, nodeID)
+	ctx, span := tracer.Start(ctx, "SignalClient.StartParticipantSignal",
+		tracing.SpanKindClient,
+		tracing.Attr("room", string(roomName)),
+		tracing.Attr("participant", string(pi.Identity)),
+		tracing.Attr("nodeID", string(nodeID)),
+		tracing.Attr("connID", string(connectionID)),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	stream, err := r.client.RelaySignal(tracing.AppendToOutgoingContext(ctx), nodeID)
 	if err != nil {
 		prometheus.MessageCounter.WithLabelValues("signal", "failure").Add(1)
 		return
@@ -0,0 +1,147 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const admissionTimeout = 5 * time.Second
+
+// AdmissionRequest describes a participant joining a room
+type AdmissionRequest struct {
+	Room        *livekit.Room
+	Participant *livekit.ParticipantInfo
+	// number of participants in the room, not counting dependent participants (agents, egress)
+	NumParticipants uint32
+}
+
+// AdmissionDecision is the outcome of admission, zero value admits the participant unchanged
+type AdmissionDecision struct {
+	Reject bool
+	Reason string
+
+	// force the participant to be hidden from others
+	Hidden bool
+	// relabel the participant, nil/empty leaves the values from the token
+	Name       *string
+	Metadata   *string
+	Attributes map[string]string
+	// cap total bitrate published by the participant in bps, 0 for no cap
+	MaxUplinkBitrate int64
+}
+
+// AdmissionController lets deployments plug in custom admission logic, e.g. a Go plugin
+// or a callout to a policy service, beyond the static max participants check.
+// It is invoked before the participant joins, without holding room locks.
+type AdmissionController interface {
+	Admit(ctx context.Context, req *AdmissionRequest) (*AdmissionDecision, error)
+}
+
+// AdmissionControllerFunc adapts a function to AdmissionController
+type AdmissionControllerFunc func(ctx context.Context, req *AdmissionRequest) (*AdmissionDecision, error)
+
+func (f AdmissionControllerFunc) Admit(ctx context.Context, req *AdmissionRequest) (*AdmissionDecision, error) {
+	return f(ctx, req)
+}
+
+// SetAdmissionController sets the controller invoked for participants joining the room, nil to admit all
+func (r *Room) SetAdmissionController(ac AdmissionController) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.admissionController = ac
+}
+
+func (r *Room) admit(participant types.LocalParticipant) (*AdmissionDecision, error) {
+	r.lock.RLock()
+	ac := r.admissionController
+	numParticipants := uint32(0)
+	for _, p := range r.participants {
+		if !p.IsDependent() {
+			numParticipants++
+		}
+	}
+	r.lock.RUnlock()
+
+	if ac == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), admissionTimeout)
+	defer cancel()
+
+	decision, err := ac.Admit(ctx, &AdmissionRequest{
+		Room:            r.ToProto(),
+		Participant:     participant.ToProto(),
+		NumParticipants: numParticipants,
+	})
+	if err != nil {
+		participant.GetLogger().Warnw("admission failed", err)
+		return nil, fmt.Errorf("%w: %v", ErrAdmissionFailed, err)
+	}
+	if decision == nil {
+		return nil, nil
+	}
+	if decision.Reject {
+		participant.GetLogger().Infow("participant rejected by admission controller", "reason", decision.Reason)
+		if decision.Reason != "" {
+			return nil, fmt.Errorf("%w: %s", ErrParticipantRejected, decision.Reason)
+		}
+		return nil, ErrParticipantRejected
+	}
+	return decision, nil
+}
+
+func applyAdmissionDecision(participant types.LocalParticipant, decision *AdmissionDecision) {
+	if decision == nil {
+		return
+	}
+
+	participant.GetLogger().Debugw("applying admission decision",
+		"hidden", decision.Hidden,
+		"name", decision.Name,
+		"metadata", decision.Metadata,
+		"attributes", decision.Attributes,
+		"maxUplinkBitrate", decision.MaxUplinkBitrate,
+	)
+	if decision.Hidden && !participant.Hidden() {
+		// ToProto builds permission from grants, safe to modify
+		permission := participant.ToProto().Permission
+		if permission == nil {
+			permission = &livekit.ParticipantPermission{}
+		}
+		permission.Hidden = true
+		participant.SetPermission(permission)
+	}
+	if decision.Name != nil {
+		participant.SetName(*decision.Name)
+	}
+	if decision.Metadata != nil {
+		participant.SetMetadata(*decision.Metadata)
+	}
+	if len(decision.Attributes) != 0 {
+		participant.SetAttributes(decision.Attributes)
+	}
+	if decision.MaxUplinkBitrate > 0 {
+		participant.SetMaxUplinkBitrate(decision.MaxUplinkBitrate)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DataRPCMethodSetAudioOnly is handled by the participant. A subscriber enters (payload "true") and exits
+// ("false") audio only mode with it, e.g. when the app goes to background. In audio only mode, the server
+// pauses all video subscribed by the participant without the participant having to update settings or
+// unsubscribe track by track. It needs the data_rpc client capability, the mode is not visible to others.
+const DataRPCMethodSetAudioOnly = "lk.set_audio_only"
+
+func parseAudioOnly(payload string) (bool, error) {
+	audioOnly, err := strconv.ParseBool(strings.TrimSpace(payload))
+	if err != nil {
+		return false, ErrDataRPCInvalidPayload
+	}
+	return audioOnly, nil
+}
+
+// --------------------------------------
+
+func (p *ParticipantImpl) handleSetAudioOnlyRPC(_ context.Context, _ types.LocalParticipant, payload string) (string, error) {
+	audioOnly, err := parseAudioOnly(payload)
+	if err != nil {
+		return "", err
+	}
+
+	p.clientSettingsLock.Lock()
+	defer p.clientSettingsLock.Unlock()
+
+	if p.audioOnly.Swap(audioOnly) != audioOnly {
+		p.applyAudioOnly()
+	}
+	return "", nil
+}
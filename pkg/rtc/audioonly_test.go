@@ -0,0 +1,57 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAudioOnly(t *testing.T) {
+	for _, payload := range []string{"true", "1", "TRUE", " true "} {
+		audioOnly, err := parseAudioOnly(payload)
+		require.NoError(t, err, payload)
+		require.True(t, audioOnly, payload)
+	}
+	for _, payload := range []string{"false", "0"} {
+		audioOnly, err := parseAudioOnly(payload)
+		require.NoError(t, err, payload)
+		require.False(t, audioOnly, payload)
+	}
+	for _, payload := range []string{"", "yes"} {
+		_, err := parseAudioOnly(payload)
+		require.ErrorIs(t, err, ErrDataRPCInvalidPayload, payload)
+	}
+}
+
+func TestSetAudioOnlyRPC(t *testing.T) {
+	p := newParticipantForTest("test")
+	require.NotNil(t, p.dataRPC.handlers[DataRPCMethodSetAudioOnly])
+
+	_, err := p.handleSetAudioOnlyRPC(context.Background(), p, "true")
+	require.NoError(t, err)
+	require.True(t, p.audioOnly.Load())
+
+	// invalid payload keeps the mode
+	_, err = p.handleSetAudioOnlyRPC(context.Background(), p, "maybe")
+	require.ErrorIs(t, err, ErrDataRPCInvalidPayload)
+	require.True(t, p.audioOnly.Load())
+
+	_, err = p.handleSetAudioOnlyRPC(context.Background(), p, "false")
+	require.NoError(t, err)
+	require.False(t, p.audioOnly.Load())
+}
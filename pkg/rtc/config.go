@@ -15,20 +15,46 @@
 package rtc
 
 import (
+	"fmt"
+	"slices"
+	"time"
+
 	"github.com/pion/sdp/v3"
+	"github.com/pion/transport/v2/stdnet"
 	"github.com/pion/webrtc/v3"
 
+	"github.com/livekit/protocol/logger"
+
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	dd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/dependencydescriptor"
+	pd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/playoutdelay"
+	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
 )
 
 const (
-	frameMarking        = "urn:ietf:params:rtp-hdrext:framemarking"
 	repairedRTPStreamID = "urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id"
+	videoOrientationURI = "urn:3gpp:video-orientation"
+
+	// as recommended by Happy Eyeballs (RFC 8305)
+	defaultICEIPFamilyHeadStart = 250 * time.Millisecond
+
+	defaultPacketCaptureMaxDuration = time.Minute
+	defaultPacketCaptureMaxFileSize = 20_000_000
+	defaultPacketCaptureMaxFiles    = 5
 )
 
+// header extensions that can be enabled/disabled by configuration, by name,
+// extensions needed for forwarding (mid, rid, dependency descriptor) are not in here
+var configurableRTPHeaderExtensions = map[string]string{
+	"video-orientation": videoOrientationURI,
+	"abs-send-time":     sdp.ABSSendTimeURI,
+	"transport-cc":      sdp.TransportCCURI,
+	"playout-delay":     pd.PlayoutDelayURI,
+}
+
 type WebRTCConfig struct {
 	rtcconfig.WebRTCConfig
 
@@ -36,16 +62,50 @@ type WebRTCConfig struct {
 	Receiver      ReceiverConfig
 	Publisher     DirectionConfig
 	Subscriber    DirectionConfig
+
+	RTCPBatchInterval time.Duration
+	EnableActiveTCP   bool
+	ICEIPFamily       ICEIPFamilyConfig
+	PacketCapture     config.PacketCaptureConfig
+	// nil when sockets are not pooled
+	ICESocketPool *ICESocketPool
+	// 0 means unlimited
+	MaxDormantTransceivers int
+	PruneAnswerCodecs      bool
+}
+
+type ICEIPFamilyConfig struct {
+	// unknown when no family is preferred
+	Prefer    types.ICEIPFamily
+	HeadStart time.Duration
+	Prune     bool
 }
 
 type ReceiverConfig struct {
 	PacketBufferSizeVideo int
 	PacketBufferSizeAudio int
+	NackResponder         config.NackResponderConfig
+	ConnectionQuality     config.ConnectionQualityConfig
+	BlankFramesOnStall    config.BlankFramesOnStallConfig
+	FreezeRecovery        time.Duration
+	FrameIntegrity        bool
+	BlankFramesOnMute     bool
+	LayerSelection        config.LayerSelectionConfig
+	PacketMetadataCache   config.PacketMetadataCacheConfig
+
+	// shared by the buffers of all rooms, nil when packet buffer memory is not limited
+	PacketBufferPool *buffer.MemoryPool
 }
 
 type RTPHeaderExtensionConfig struct {
 	Audio []string
 	Video []string
+	// not negotiated even when added conditionally, e. g. playout delay of rooms with playout delay
+	Disabled []string
+}
+
+func (r RTPHeaderExtensionConfig) IsDisabled(uri string) bool {
+	return slices.Contains(r.Disabled, uri)
 }
 
 type RTCPFeedbackConfig struct {
@@ -59,6 +119,77 @@ type DirectionConfig struct {
 	StrictACKs         bool
 }
 
+// WithRTPHeaderExtensions returns a copy of the config with header extensions enabled/disabled,
+// transport-cc feedback follows the transport-cc extension. Enabled extensions are negotiated for video.
+func (d DirectionConfig) WithRTPHeaderExtensions(toggles config.RTPHeaderExtensionToggles) (DirectionConfig, error) {
+	c := DirectionConfig{
+		RTPHeaderExtension: RTPHeaderExtensionConfig{
+			Audio:    slices.Clone(d.RTPHeaderExtension.Audio),
+			Video:    slices.Clone(d.RTPHeaderExtension.Video),
+			Disabled: slices.Clone(d.RTPHeaderExtension.Disabled),
+		},
+		RTCPFeedback: RTCPFeedbackConfig{
+			Audio: slices.Clone(d.RTCPFeedback.Audio),
+			Video: slices.Clone(d.RTCPFeedback.Video),
+		},
+		StrictACKs: d.StrictACKs,
+	}
+	isTransportCCFeedback := func(fb webrtc.RTCPFeedback) bool {
+		return fb.Type == webrtc.TypeRTCPFBTransportCC
+	}
+
+	for _, name := range toggles.Enable {
+		uri, ok := configurableRTPHeaderExtensions[name]
+		if !ok {
+			return d, fmt.Errorf("unknown RTP header extension: %s", name)
+		}
+
+		c.RTPHeaderExtension.Disabled = slices.DeleteFunc(c.RTPHeaderExtension.Disabled, func(u string) bool { return u == uri })
+		if !slices.Contains(c.RTPHeaderExtension.Video, uri) {
+			c.RTPHeaderExtension.Video = append(c.RTPHeaderExtension.Video, uri)
+		}
+		if uri == sdp.TransportCCURI && !slices.ContainsFunc(c.RTCPFeedback.Video, isTransportCCFeedback) {
+			c.RTCPFeedback.Video = append(c.RTCPFeedback.Video, webrtc.RTCPFeedback{Type: webrtc.TypeRTCPFBTransportCC})
+		}
+	}
+
+	for _, name := range toggles.Disable {
+		uri, ok := configurableRTPHeaderExtensions[name]
+		if !ok {
+			return d, fmt.Errorf("unknown RTP header extension: %s", name)
+		}
+
+		c.RTPHeaderExtension.Audio = slices.DeleteFunc(c.RTPHeaderExtension.Audio, func(u string) bool { return u == uri })
+		c.RTPHeaderExtension.Video = slices.DeleteFunc(c.RTPHeaderExtension.Video, func(u string) bool { return u == uri })
+		if !c.RTPHeaderExtension.IsDisabled(uri) {
+			c.RTPHeaderExtension.Disabled = append(c.RTPHeaderExtension.Disabled, uri)
+		}
+		if uri == sdp.TransportCCURI {
+			c.RTCPFeedback.Audio = slices.DeleteFunc(c.RTCPFeedback.Audio, isTransportCCFeedback)
+			c.RTCPFeedback.Video = slices.DeleteFunc(c.RTCPFeedback.Video, isTransportCCFeedback)
+		}
+	}
+
+	return c, nil
+}
+
+// WithRTPHeaderExtensions returns a copy of the config with header extensions of both directions enabled/disabled
+func (c *WebRTCConfig) WithRTPHeaderExtensions(conf config.RTPHeaderExtensionsConfig) (*WebRTCConfig, error) {
+	publisherConfig, err := c.Publisher.WithRTPHeaderExtensions(conf.Publisher)
+	if err != nil {
+		return nil, err
+	}
+	subscriberConfig, err := c.Subscriber.WithRTPHeaderExtensions(conf.Subscriber)
+	if err != nil {
+		return nil, err
+	}
+
+	cloned := *c
+	cloned.Publisher = publisherConfig
+	cloned.Subscriber = subscriberConfig
+	return &cloned, nil
+}
+
 func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 	rtcConf := conf.RTC
 
@@ -67,7 +198,31 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 		return nil, err
 	}
 
-	// we don't want to use active TCP on a server, clients should be dialing
+	if webRTCConfig.UDPMux != nil {
+		udpMux := newUDPMuxWithMetrics(webRTCConfig.UDPMux)
+		webRTCConfig.UDPMux = udpMux
+		webRTCConfig.SettingEngine.SetICEUDPMux(udpMux)
+	}
+
+	n, err := stdnet.NewNet()
+	if err != nil {
+		return nil, err
+	}
+	iceNet := configureDSCP(webRTCConfig, rtcConf.DSCP, n)
+	var iceSocketPool *ICESocketPool
+	if rtcConf.ICESocketPool.Size > 0 {
+		if rtcConf.ICEPortRangeStart != 0 || rtcConf.ICEPortRangeEnd != 0 {
+			logger.Infow("ICE socket pool is not used for sockets of the port range")
+		}
+		iceSocketPool = NewICESocketPool(iceNet, rtcConf.ICESocketPool.Size)
+		iceNet = iceSocketPool
+	}
+	if iceNet != n {
+		webRTCConfig.SettingEngine.SetNet(iceNet)
+	}
+
+	// we don't want to use active TCP on a server by default, clients should be dialing.
+	// when enabled, it is turned back on per peer connection for clients that prefer TCP
 	webRTCConfig.SettingEngine.DisableActiveTCP(true)
 
 	if rtcConf.PacketBufferSize == 0 {
@@ -80,6 +235,11 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 		rtcConf.PacketBufferSizeAudio = rtcConf.PacketBufferSize
 	}
 
+	var packetBufferPool *buffer.MemoryPool
+	if rtcConf.PacketBufferMemoryLimit > 0 {
+		packetBufferPool = buffer.NewMemoryPool(int64(rtcConf.PacketBufferMemoryLimit))
+	}
+
 	// publisher configuration
 	publisherConfig := DirectionConfig{
 		StrictACKs: true, // publisher is dialed, and will always reply with ACK
@@ -94,7 +254,7 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 				sdp.SDESMidURI,
 				sdp.SDESRTPStreamIDURI,
 				sdp.TransportCCURI,
-				frameMarking,
+				buffer.FrameMarkingURI,
 				dd.ExtensionURI,
 				repairedRTPStreamID,
 				//act.AbsCaptureTimeURI,
@@ -113,6 +273,11 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 		},
 	}
 
+	if rtcConf.CongestionControl.PublisherAudioTWCC {
+		publisherConfig.RTPHeaderExtension.Audio = append(publisherConfig.RTPHeaderExtension.Audio, sdp.TransportCCURI)
+		publisherConfig.RTCPFeedback.Audio = append(publisherConfig.RTCPFeedback.Audio, webrtc.RTCPFeedback{Type: webrtc.TypeRTCPFBTransportCC})
+	}
+
 	// subscriber configuration
 	subscriberConfig := DirectionConfig{
 		StrictACKs: conf.RTC.StrictACKs,
@@ -134,6 +299,9 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 		},
 	}
 	if rtcConf.CongestionControl.UseSendSideBWE {
+		if _, err := streamallocator.GetBandwidthEstimatorFactory(rtcConf.CongestionControl.BandwidthEstimator); err != nil {
+			return nil, err
+		}
 		subscriberConfig.RTPHeaderExtension.Video = append(subscriberConfig.RTPHeaderExtension.Video, sdp.TransportCCURI)
 		subscriberConfig.RTCPFeedback.Video = append(subscriberConfig.RTCPFeedback.Video, webrtc.RTCPFeedback{Type: webrtc.TypeRTCPFBTransportCC})
 	} else {
@@ -141,14 +309,76 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 		subscriberConfig.RTCPFeedback.Video = append(subscriberConfig.RTCPFeedback.Video, webrtc.RTCPFeedback{Type: webrtc.TypeRTCPFBGoogREMB})
 	}
 
+	publisherConfig, err = publisherConfig.WithRTPHeaderExtensions(rtcConf.HeaderExtensions.Publisher)
+	if err != nil {
+		return nil, err
+	}
+	subscriberConfig, err = subscriberConfig.WithRTPHeaderExtensions(rtcConf.HeaderExtensions.Subscriber)
+	if err != nil {
+		return nil, err
+	}
+	// fail early on misconfigured room configurations, they are applied when participants join
+	for name, headerExtensions := range conf.Room.HeaderExtensions {
+		if _, err := publisherConfig.WithRTPHeaderExtensions(headerExtensions.Publisher); err != nil {
+			return nil, fmt.Errorf("room configuration %s: %w", name, err)
+		}
+		if _, err := subscriberConfig.WithRTPHeaderExtensions(headerExtensions.Subscriber); err != nil {
+			return nil, fmt.Errorf("room configuration %s: %w", name, err)
+		}
+	}
+
+	iceIPFamily := ICEIPFamilyConfig{
+		Prefer:    types.ICEIPFamilyUnknown,
+		HeadStart: rtcConf.ICEIPFamily.HeadStart,
+		Prune:     rtcConf.ICEIPFamily.Prune,
+	}
+	switch prefer := types.ICEIPFamily(rtcConf.ICEIPFamily.Prefer); prefer {
+	case "":
+	case types.ICEIPFamilyIPv4, types.ICEIPFamilyIPv6:
+		iceIPFamily.Prefer = prefer
+		if iceIPFamily.HeadStart == 0 {
+			iceIPFamily.HeadStart = defaultICEIPFamilyHeadStart
+		}
+	default:
+		return nil, fmt.Errorf("invalid ice_ip_family.prefer %s, must be ipv4 or ipv6", prefer)
+	}
+
+	packetCapture := rtcConf.PacketCapture
+	if packetCapture.MaxDuration <= 0 {
+		packetCapture.MaxDuration = defaultPacketCaptureMaxDuration
+	}
+	if packetCapture.MaxFileSize <= 0 {
+		packetCapture.MaxFileSize = defaultPacketCaptureMaxFileSize
+	}
+	if packetCapture.MaxFiles <= 0 {
+		packetCapture.MaxFiles = defaultPacketCaptureMaxFiles
+	}
+
 	return &WebRTCConfig{
 		WebRTCConfig: *webRTCConfig,
 		Receiver: ReceiverConfig{
 			PacketBufferSizeVideo: rtcConf.PacketBufferSizeVideo,
 			PacketBufferSizeAudio: rtcConf.PacketBufferSizeAudio,
+			PacketBufferPool:      packetBufferPool,
+			NackResponder:         rtcConf.NackResponder,
+			ConnectionQuality:     rtcConf.ConnectionQuality,
+			BlankFramesOnStall:    rtcConf.BlankFramesOnStall,
+			FreezeRecovery:        rtcConf.FreezeRecovery,
+			FrameIntegrity:        rtcConf.FrameIntegrity,
+			BlankFramesOnMute:     rtcConf.BlankFramesOnMute,
+			LayerSelection:        rtcConf.LayerSelection,
+			PacketMetadataCache:   rtcConf.PacketMetadataCache,
 		},
-		Publisher:  publisherConfig,
-		Subscriber: subscriberConfig,
+		Publisher:         publisherConfig,
+		Subscriber:        subscriberConfig,
+		RTCPBatchInterval: rtcConf.RTCPBatchInterval,
+		EnableActiveTCP:   rtcConf.EnableActiveTCP,
+		ICEIPFamily:       iceIPFamily,
+		PacketCapture:     packetCapture,
+		ICESocketPool:     iceSocketPool,
+
+		MaxDormantTransceivers: rtcConf.MaxDormantTransceivers,
+		PruneAnswerCodecs:      rtcConf.PruneAnswerCodecs,
 	}, nil
 }
 
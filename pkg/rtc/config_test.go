@@ -0,0 +1,72 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	pd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/playoutdelay"
+)
+
+func TestWithRTPHeaderExtensions(t *testing.T) {
+	dc := DirectionConfig{
+		RTPHeaderExtension: RTPHeaderExtensionConfig{
+			Audio: []string{sdp.SDESMidURI, sdp.TransportCCURI},
+			Video: []string{sdp.SDESMidURI, sdp.TransportCCURI},
+		},
+		RTCPFeedback: RTCPFeedbackConfig{
+			Audio: []webrtc.RTCPFeedback{{Type: webrtc.TypeRTCPFBTransportCC}},
+			Video: []webrtc.RTCPFeedback{{Type: webrtc.TypeRTCPFBTransportCC}, {Type: webrtc.TypeRTCPFBNACK}},
+		},
+	}
+
+	t.Run("enable and disable", func(t *testing.T) {
+		c, err := dc.WithRTPHeaderExtensions(config.RTPHeaderExtensionToggles{
+			Enable:  []string{"video-orientation"},
+			Disable: []string{"transport-cc", "playout-delay"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{sdp.SDESMidURI}, c.RTPHeaderExtension.Audio)
+		require.Equal(t, []string{sdp.SDESMidURI, videoOrientationURI}, c.RTPHeaderExtension.Video)
+		require.Empty(t, c.RTCPFeedback.Audio)
+		require.Equal(t, []webrtc.RTCPFeedback{{Type: webrtc.TypeRTCPFBNACK}}, c.RTCPFeedback.Video)
+		require.True(t, c.RTPHeaderExtension.IsDisabled(pd.PlayoutDelayURI))
+
+		// original is not modified
+		require.Equal(t, []string{sdp.SDESMidURI, sdp.TransportCCURI}, dc.RTPHeaderExtension.Video)
+		require.Len(t, dc.RTCPFeedback.Video, 2)
+	})
+
+	t.Run("enable after disable", func(t *testing.T) {
+		c, err := dc.WithRTPHeaderExtensions(config.RTPHeaderExtensionToggles{Disable: []string{"transport-cc"}})
+		require.NoError(t, err)
+
+		c, err = c.WithRTPHeaderExtensions(config.RTPHeaderExtensionToggles{Enable: []string{"transport-cc"}})
+		require.NoError(t, err)
+		require.Equal(t, []string{sdp.SDESMidURI, sdp.TransportCCURI}, c.RTPHeaderExtension.Video)
+		require.Equal(t, []webrtc.RTCPFeedback{{Type: webrtc.TypeRTCPFBNACK}, {Type: webrtc.TypeRTCPFBTransportCC}}, c.RTCPFeedback.Video)
+		require.False(t, c.RTPHeaderExtension.IsDisabled(sdp.TransportCCURI))
+	})
+
+	t.Run("unknown extension", func(t *testing.T) {
+		_, err := dc.WithRTPHeaderExtensions(config.RTPHeaderExtensionToggles{Disable: []string{"mid"}})
+		require.Error(t, err)
+	})
+}
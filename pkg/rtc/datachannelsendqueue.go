@@ -0,0 +1,134 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+
+	"github.com/livekit/protocol/logger"
+)
+
+// dataChannelWriter is the part of webrtc.DataChannel used by dataChannelSendQueue
+type dataChannelWriter interface {
+	Label() string
+	Send(data []byte) error
+	BufferedAmount() uint64
+	SetBufferedAmountLowThreshold(th uint64)
+	OnBufferedAmountLow(f func())
+}
+
+// dataChannelSendQueue paces writes to a data channel. When the data channel buffer is above
+// the limit, messages are held in a queue (of the same size limit) and sent once the data
+// channel signals that its buffered amount is low. Only when the queue is also full, a send fails
+// with ErrDataChannelBufferFull, or, with dropOldest, the oldest queued messages are dropped to make room.
+// onDrained is called with the number of messages dropped once the queue is empty again.
+type dataChannelSendQueue struct {
+	dc                dataChannelWriter
+	maxBufferedAmount uint64
+	dropOldest        bool
+	onDrained         func(dropped int)
+	logger            logger.Logger
+
+	lock         sync.Mutex
+	queue        [][]byte
+	queuedAmount uint64
+	// messages dropped since queue last drained
+	dropped int
+}
+
+func newDataChannelSendQueue(
+	dc dataChannelWriter,
+	maxBufferedAmount uint64,
+	dropOldest bool,
+	onDrained func(dropped int),
+	logger logger.Logger,
+) *dataChannelSendQueue {
+	q := &dataChannelSendQueue{
+		dc:                dc,
+		maxBufferedAmount: maxBufferedAmount,
+		dropOldest:        dropOldest,
+		onDrained:         onDrained,
+		logger:            logger,
+	}
+
+	dc.SetBufferedAmountLowThreshold(maxBufferedAmount / 2)
+	dc.OnBufferedAmountLow(func() {
+		// callback fires from the SCTP association, send from a different goroutine
+		go q.drain()
+	})
+	return q
+}
+
+func (q *dataChannelSendQueue) Send(data []byte) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	// keep order, send directly only if nothing is waiting
+	if len(q.queue) == 0 && q.dc.BufferedAmount() <= q.maxBufferedAmount {
+		return q.dc.Send(data)
+	}
+
+	if q.queuedAmount+uint64(len(data)) > q.maxBufferedAmount {
+		if !q.dropOldest || uint64(len(data)) > q.maxBufferedAmount {
+			q.dropped++
+			return ErrDataChannelBufferFull
+		}
+
+		for q.queuedAmount+uint64(len(data)) > q.maxBufferedAmount {
+			q.queuedAmount -= uint64(len(q.queue[0]))
+			q.queue[0] = nil
+			q.queue = q.queue[1:]
+			q.dropped++
+		}
+	}
+
+	q.queue = append(q.queue, data)
+	q.queuedAmount += uint64(len(data))
+	return nil
+}
+
+func (q *dataChannelSendQueue) drain() {
+	q.lock.Lock()
+	if len(q.queue) == 0 {
+		q.lock.Unlock()
+		return
+	}
+
+	for len(q.queue) != 0 && q.dc.BufferedAmount() <= q.maxBufferedAmount {
+		data := q.queue[0]
+		q.queue[0] = nil
+		q.queue = q.queue[1:]
+		q.queuedAmount -= uint64(len(data))
+
+		if err := q.dc.Send(data); err != nil {
+			q.logger.Warnw("could not send queued data", err, "label", q.dc.Label(), "remaining", len(q.queue))
+			q.queue = nil
+			q.queuedAmount = 0
+			q.lock.Unlock()
+			return
+		}
+	}
+	drained := len(q.queue) == 0
+	dropped := 0
+	if drained {
+		dropped = q.dropped
+		q.dropped = 0
+	}
+	q.lock.Unlock()
+
+	if drained && q.onDrained != nil {
+		q.onDrained(dropped)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+)
+
+type testDataChannelWriter struct {
+	buffered            uint64
+	sent                [][]byte
+	lowThreshold        uint64
+	onBufferedAmountLow func()
+}
+
+func (w *testDataChannelWriter) Label() string { return "test" }
+
+func (w *testDataChannelWriter) Send(data []byte) error {
+	w.sent = append(w.sent, data)
+	w.buffered += uint64(len(data))
+	return nil
+}
+
+func (w *testDataChannelWriter) BufferedAmount() uint64 { return w.buffered }
+
+func (w *testDataChannelWriter) SetBufferedAmountLowThreshold(th uint64) { w.lowThreshold = th }
+
+func (w *testDataChannelWriter) OnBufferedAmountLow(f func()) { w.onBufferedAmountLow = f }
+
+func TestDataChannelSendQueue(t *testing.T) {
+	w := &testDataChannelWriter{}
+	drained := 0
+	dropped := 0
+	q := newDataChannelSendQueue(w, 10, false, func(d int) { drained++; dropped = d }, logger.GetLogger())
+	require.Equal(t, uint64(5), w.lowThreshold)
+	require.NotNil(t, w.onBufferedAmountLow)
+
+	// sent directly while buffer is below limit
+	require.NoError(t, q.Send([]byte("0123456789")))
+	require.NoError(t, q.Send([]byte("a")))
+	require.Len(t, w.sent, 2)
+
+	// buffer above limit, queued
+	require.NoError(t, q.Send([]byte("bcdef")))
+	require.NoError(t, q.Send([]byte("ghij")))
+	require.Len(t, w.sent, 2)
+
+	// queue full
+	require.ErrorIs(t, q.Send([]byte("kl")), ErrDataChannelBufferFull)
+
+	// queued data keeps order even if buffer has room again
+	w.buffered = 0
+	require.NoError(t, q.Send([]byte("m")))
+	require.Len(t, w.sent, 2)
+
+	// drain sends queued data in order while buffer has room
+	w.buffered = 6
+	q.drain()
+	require.Equal(t, []string{"0123456789", "a", "bcdef"}, toStrings(w.sent))
+	require.Equal(t, 0, drained)
+
+	w.buffered = 0
+	q.drain()
+	require.Equal(t, []string{"0123456789", "a", "bcdef", "ghij", "m"}, toStrings(w.sent))
+	require.Equal(t, 1, drained)
+	require.Equal(t, 1, dropped)
+
+	// nothing queued, no notification
+	q.drain()
+	require.Equal(t, 1, drained)
+}
+
+func TestDataChannelSendQueueDropOldest(t *testing.T) {
+	w := &testDataChannelWriter{}
+	drained := 0
+	dropped := 0
+	q := newDataChannelSendQueue(w, 10, true, func(d int) { drained++; dropped = d }, logger.GetLogger())
+
+	require.NoError(t, q.Send([]byte("0123456789")))
+	require.NoError(t, q.Send([]byte("a")))
+
+	// buffer above limit, queued up to the limit
+	require.NoError(t, q.Send([]byte("bcdef")))
+	require.NoError(t, q.Send([]byte("ghij")))
+
+	// queue full, oldest messages make room for newer ones
+	require.NoError(t, q.Send([]byte("kl")))
+	require.Equal(t, 1, q.dropped)
+	require.NoError(t, q.Send([]byte("mnopqr")))
+	require.Equal(t, 2, q.dropped)
+	require.Equal(t, uint64(8), q.queuedAmount)
+
+	// larger than the queue, cannot be held
+	require.ErrorIs(t, q.Send([]byte("0123456789x")), ErrDataChannelBufferFull)
+	require.Equal(t, 3, q.dropped)
+
+	w.buffered = 0
+	q.drain()
+	require.Equal(t, []string{"0123456789", "a", "kl", "mnopqr"}, toStrings(w.sent))
+	require.Equal(t, 1, drained)
+	require.Equal(t, 3, dropped)
+	require.Equal(t, 0, q.dropped)
+}
+
+func toStrings(data [][]byte) []string {
+	out := make([]string, 0, len(data))
+	for _, d := range data {
+		out = append(out, string(d))
+	}
+	return out
+}
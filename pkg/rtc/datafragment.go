@@ -0,0 +1,161 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Data packets larger than what some clients accept as a single SCTP message are sent in fragments,
+// each prefixed with a header
+//
+//	magic (1) | version (1) | message id (4) | fragment index (2) | fragment count (2)
+//
+// Magic byte is an invalid protobuf tag (wire type 7), fragments cannot be mistaken for a DataPacket.
+const (
+	dataFragmentMagic      = 0xff
+	dataFragmentVersion    = 1
+	dataFragmentHeaderSize = 10
+
+	// stays under 16 KiB, the message size all browsers handle
+	maxDataFragmentSize = 15 * 1024
+
+	maxDataMessageSize         = 1024 * 1024
+	maxPendingDataMessages     = 16
+	dataReassemblyTimeout      = 10 * time.Second
+	maxDataFragmentsPerMessage = (maxDataMessageSize + maxDataFragmentSize - dataFragmentHeaderSize - 1) / (maxDataFragmentSize - dataFragmentHeaderSize)
+)
+
+func isDataFragment(data []byte) bool {
+	return len(data) > 0 && data[0] == dataFragmentMagic
+}
+
+// fragmentDataPacket splits an encoded data packet into fragments of at most maxDataFragmentSize
+func fragmentDataPacket(messageID uint32, encoded []byte) [][]byte {
+	payloadSize := maxDataFragmentSize - dataFragmentHeaderSize
+	count := (len(encoded) + payloadSize - 1) / payloadSize
+
+	fragments := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		payload := encoded[i*payloadSize : min(len(encoded), (i+1)*payloadSize)]
+
+		fragment := make([]byte, dataFragmentHeaderSize+len(payload))
+		fragment[0] = dataFragmentMagic
+		fragment[1] = dataFragmentVersion
+		binary.BigEndian.PutUint32(fragment[2:], messageID)
+		binary.BigEndian.PutUint16(fragment[6:], uint16(i))
+		binary.BigEndian.PutUint16(fragment[8:], uint16(count))
+		copy(fragment[dataFragmentHeaderSize:], payload)
+		fragments = append(fragments, fragment)
+	}
+	return fragments
+}
+
+type dataReassembly struct {
+	fragments [][]byte
+	received  int
+	size      int
+	startedAt time.Time
+}
+
+// dataReassembler puts fragmented data packets of a participant back together,
+// fragments may arrive out of order on the lossy channel and incomplete messages are dropped after a timeout
+type dataReassembler struct {
+	lock     sync.Mutex
+	messages map[uint32]*dataReassembly
+}
+
+func newDataReassembler() *dataReassembler {
+	return &dataReassembler{
+		messages: make(map[uint32]*dataReassembly),
+	}
+}
+
+// add returns the encoded data packet once all its fragments have been received, nil otherwise
+func (d *dataReassembler) add(fragment []byte) ([]byte, error) {
+	return d.addAt(time.Now(), fragment)
+}
+
+func (d *dataReassembler) addAt(now time.Time, fragment []byte) ([]byte, error) {
+	if len(fragment) < dataFragmentHeaderSize || fragment[0] != dataFragmentMagic {
+		return nil, ErrInvalidDataFragment
+	}
+	if fragment[1] != dataFragmentVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidDataFragment, fragment[1])
+	}
+	messageID := binary.BigEndian.Uint32(fragment[2:])
+	index := int(binary.BigEndian.Uint16(fragment[6:]))
+	count := int(binary.BigEndian.Uint16(fragment[8:]))
+	if count == 0 || index >= count {
+		return nil, fmt.Errorf("%w: fragment %d of %d", ErrInvalidDataFragment, index, count)
+	}
+	if count > maxDataFragmentsPerMessage {
+		return nil, ErrDataMessageTooLarge
+	}
+	payload := fragment[dataFragmentHeaderSize:]
+	if len(payload) == 0 {
+		return nil, fmt.Errorf("%w: empty fragment", ErrInvalidDataFragment)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for id, m := range d.messages {
+		if now.Sub(m.startedAt) > dataReassemblyTimeout {
+			delete(d.messages, id)
+		}
+	}
+
+	m := d.messages[messageID]
+	if m == nil {
+		if len(d.messages) >= maxPendingDataMessages {
+			return nil, fmt.Errorf("%w: too many pending messages", ErrInvalidDataFragment)
+		}
+		m = &dataReassembly{
+			fragments: make([][]byte, count),
+			startedAt: now,
+		}
+		d.messages[messageID] = m
+	}
+	if len(m.fragments) != count {
+		delete(d.messages, messageID)
+		return nil, fmt.Errorf("%w: fragment count changed", ErrInvalidDataFragment)
+	}
+	if m.fragments[index] != nil {
+		// duplicate
+		return nil, nil
+	}
+
+	m.size += len(payload)
+	if m.size > maxDataMessageSize {
+		delete(d.messages, messageID)
+		return nil, ErrDataMessageTooLarge
+	}
+	m.fragments[index] = append([]byte(nil), payload...)
+	m.received++
+	if m.received < count {
+		return nil, nil
+	}
+
+	delete(d.messages, messageID)
+	encoded := make([]byte, 0, m.size)
+	for _, f := range m.fragments {
+		encoded = append(encoded, f...)
+	}
+	return encoded, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataFragmentation(t *testing.T) {
+	encoded := make([]byte, 2*maxDataFragmentSize+100)
+	_, _ = rand.Read(encoded)
+
+	fragments := fragmentDataPacket(1, encoded)
+	require.Len(t, fragments, 3)
+	for _, f := range fragments {
+		require.True(t, isDataFragment(f))
+		require.LessOrEqual(t, len(f), maxDataFragmentSize)
+	}
+
+	t.Run("out of order with duplicates", func(t *testing.T) {
+		d := newDataReassembler()
+		for _, f := range [][]byte{fragments[2], fragments[0], fragments[0]} {
+			res, err := d.add(f)
+			require.NoError(t, err)
+			require.Nil(t, res)
+		}
+		res, err := d.add(fragments[1])
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(encoded, res))
+		require.Empty(t, d.messages)
+	})
+
+	t.Run("interleaved messages", func(t *testing.T) {
+		other := fragmentDataPacket(2, encoded[:maxDataFragmentSize+1])
+		require.Len(t, other, 2)
+
+		d := newDataReassembler()
+		for _, f := range [][]byte{fragments[0], other[0], fragments[1]} {
+			res, err := d.add(f)
+			require.NoError(t, err)
+			require.Nil(t, res)
+		}
+		res, err := d.add(other[1])
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(encoded[:maxDataFragmentSize+1], res))
+
+		res, err = d.add(fragments[2])
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(encoded, res))
+	})
+
+	t.Run("incomplete message expires", func(t *testing.T) {
+		d := newDataReassembler()
+		now := time.Now()
+		_, err := d.addAt(now, fragments[0])
+		require.NoError(t, err)
+
+		next := fragmentDataPacket(2, encoded[:maxDataFragmentSize+1])[0]
+		_, err = d.addAt(now.Add(dataReassemblyTimeout+time.Second), next)
+		require.NoError(t, err)
+		require.Len(t, d.messages, 1)
+		require.NotContains(t, d.messages, uint32(1))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		d := newDataReassembler()
+
+		_, err := d.add(fragments[0][:dataFragmentHeaderSize-1])
+		require.ErrorIs(t, err, ErrInvalidDataFragment)
+
+		unsupported := bytes.Clone(fragments[0])
+		unsupported[1] = dataFragmentVersion + 1
+		_, err = d.add(unsupported)
+		require.ErrorIs(t, err, ErrInvalidDataFragment)
+
+		tooLarge := bytes.Clone(fragments[0])
+		tooLarge[8], tooLarge[9] = 0xff, 0xff
+		_, err = d.add(tooLarge)
+		require.ErrorIs(t, err, ErrDataMessageTooLarge)
+	})
+}
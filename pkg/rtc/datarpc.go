@@ -0,0 +1,281 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DataRPCTopic carries request/response frames between the server and a client on the reliable data channel.
+// Clients opt in with the data_rpc capability, packets of those clients on the topic are handled by the server
+// and never forwarded to other participants. For other clients it is an ordinary topic.
+//
+//	request:  {"type": "request", "id": "RPC_...", "method": "...", "payload": "...", "caller": "..."}
+//	response: {"type": "response", "id": "RPC_...", "payload": "...", "error": "..."}
+//
+// Either side can send requests, a response carries the id of its request. Requests the server sends
+// on behalf of another participant (see DataRPCMethodForward) carry the identity of that participant
+// in caller, requests of the server itself have none. caller is set by the server only, it is ignored
+// in requests of clients. Packets on the topic from other participants are not delivered to clients
+// with the capability, so a frame received on the topic always comes from the server.
+const DataRPCTopic = "lk.rpc"
+
+// DataRPCMethodForward is handled by the room. It performs the request in its payload, a JSON encoded
+// DataRPCForwardRequest, on another participant of the room and responds with that participant's response.
+// The destination gets the identity of the requesting participant as caller. Forwarding needs data
+// publish permission.
+const DataRPCMethodForward = "lk.forward"
+
+type DataRPCForwardRequest struct {
+	Destination string `json:"destination"`
+	Method      string `json:"method"`
+	Payload     string `json:"payload,omitempty"`
+}
+
+const (
+	dataRPCRequest  = "request"
+	dataRPCResponse = "response"
+
+	// used when the context of a request does not have a deadline
+	dataRPCDefaultTimeout = 10 * time.Second
+
+	// requests of a client handled at the same time, further ones are rejected until one completes
+	dataRPCMaxActiveRequests = 8
+)
+
+type dataRPCFrame struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Method  string `json:"method,omitempty"`
+	Payload string `json:"payload,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Caller  string `json:"caller,omitempty"`
+}
+
+// DataRPCError is a failure the other side reported in its response
+type DataRPCError struct {
+	Message string
+}
+
+func (e *DataRPCError) Error() string {
+	return "rpc failed: " + e.Message
+}
+
+type dataRPC struct {
+	send   func(encoded []byte) error
+	logger logger.Logger
+
+	lock           sync.Mutex
+	handlers       map[string]types.DataRPCHandler
+	pending        map[string]chan *dataRPCFrame
+	activeRequests int
+}
+
+func newDataRPC(send func(encoded []byte) error, logger logger.Logger) *dataRPC {
+	return &dataRPC{
+		send:     send,
+		logger:   logger,
+		handlers: make(map[string]types.DataRPCHandler),
+		pending:  make(map[string]chan *dataRPCFrame),
+	}
+}
+
+func encodeDataRPCFrame(frame *dataRPCFrame) ([]byte, error) {
+	payload, err := json.Marshal(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	topic := DataRPCTopic
+	return proto.Marshal(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   &topic,
+			},
+		},
+	})
+}
+
+// register sets the handler of requests for a method, a nil handler removes it
+func (d *dataRPC) register(method string, handler types.DataRPCHandler) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if handler == nil {
+		delete(d.handlers, method)
+	} else {
+		d.handlers[method] = handler
+	}
+}
+
+// perform sends a request and waits for its response
+func (d *dataRPC) perform(ctx context.Context, caller livekit.ParticipantIdentity, method string, payload string, done <-chan struct{}) (string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dataRPCDefaultTimeout)
+		defer cancel()
+	}
+
+	id := guid.New("RPC_")
+	encoded, err := encodeDataRPCFrame(&dataRPCFrame{Type: dataRPCRequest, ID: id, Method: method, Payload: payload, Caller: string(caller)})
+	if err != nil {
+		return "", err
+	}
+
+	resCh := make(chan *dataRPCFrame, 1)
+	d.lock.Lock()
+	d.pending[id] = resCh
+	d.lock.Unlock()
+
+	defer func() {
+		d.lock.Lock()
+		delete(d.pending, id)
+		d.lock.Unlock()
+	}()
+
+	if err := d.send(encoded); err != nil {
+		return "", err
+	}
+
+	select {
+	case res := <-resCh:
+		if res.Error != "" {
+			return "", &DataRPCError{Message: res.Error}
+		}
+		return res.Payload, nil
+	case <-done:
+		return "", ErrDataChannelUnavailable
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// handle processes a frame received from the participant, requests are handled asynchronously
+func (d *dataRPC) handle(p types.LocalParticipant, data []byte) {
+	frame := &dataRPCFrame{}
+	if err := json.Unmarshal(data, frame); err != nil || frame.ID == "" {
+		d.logger.Debugw("could not parse rpc frame", "error", err)
+		return
+	}
+
+	switch frame.Type {
+	case dataRPCRequest:
+		d.lock.Lock()
+		handler := d.handlers[frame.Method]
+		limited := d.activeRequests >= dataRPCMaxActiveRequests
+		if !limited {
+			d.activeRequests++
+		}
+		d.lock.Unlock()
+
+		if limited {
+			d.sendResponse(&dataRPCFrame{Type: dataRPCResponse, ID: frame.ID, Error: ErrDataRPCTooManyRequests.Error()}, frame.Method)
+			return
+		}
+		go func() {
+			d.respond(p, frame, handler)
+
+			d.lock.Lock()
+			d.activeRequests--
+			d.lock.Unlock()
+		}()
+
+	case dataRPCResponse:
+		d.lock.Lock()
+		resCh := d.pending[frame.ID]
+		delete(d.pending, frame.ID)
+		d.lock.Unlock()
+
+		if resCh != nil {
+			resCh <- frame
+		}
+
+	default:
+		d.logger.Debugw("unknown rpc frame type", "type", frame.Type)
+	}
+}
+
+func (d *dataRPC) respond(p types.LocalParticipant, req *dataRPCFrame, handler types.DataRPCHandler) {
+	res := &dataRPCFrame{Type: dataRPCResponse, ID: req.ID}
+	if handler == nil {
+		res.Error = ErrDataRPCMethodNotFound.Error()
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), dataRPCDefaultTimeout)
+		payload, err := handler(ctx, p, req.Payload)
+		cancel()
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Payload = payload
+		}
+	}
+	d.sendResponse(res, req.Method)
+}
+
+func (d *dataRPC) sendResponse(res *dataRPCFrame, method string) {
+	encoded, err := encodeDataRPCFrame(res)
+	if err == nil {
+		err = d.send(encoded)
+	}
+	if err != nil && !errors.Is(err, ErrDataChannelUnavailable) {
+		d.logger.Warnw("could not send rpc response", err, "method", method)
+	}
+}
+
+// --------------------------------------
+
+func (r *Room) forwardDataRPC(ctx context.Context, p types.LocalParticipant, payload string) (string, error) {
+	if !p.CanPublishData() {
+		return "", ErrPermissionDenied
+	}
+
+	req := &DataRPCForwardRequest{}
+	if err := json.Unmarshal([]byte(payload), req); err != nil {
+		return "", err
+	}
+	destination := r.GetParticipant(livekit.ParticipantIdentity(req.Destination))
+	if destination == nil || destination.Hidden() {
+		return "", ErrDataRPCNoDestination
+	}
+
+	return destination.PerformDataRPC(ctx, p.Identity(), req.Method, req.Payload)
+}
+
+// --------------------------------------
+
+func (p *ParticipantImpl) RegisterDataRPCHandler(method string, handler types.DataRPCHandler) {
+	p.dataRPC.register(method, handler)
+}
+
+func (p *ParticipantImpl) PerformDataRPC(ctx context.Context, caller livekit.ParticipantIdentity, method string, payload string) (string, error) {
+	if !p.HasClientCapability(types.ClientCapabilityDataRPC) {
+		return "", ErrDataRPCUnsupported
+	}
+	return p.dataRPC.perform(ctx, caller, method, payload, p.disconnected)
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func decodeDataRPCFrame(t *testing.T, encoded []byte) *dataRPCFrame {
+	dp := &livekit.DataPacket{}
+	require.NoError(t, proto.Unmarshal(encoded, dp))
+	require.Equal(t, DataRPCTopic, dp.GetUser().GetTopic())
+
+	frame := &dataRPCFrame{}
+	require.NoError(t, json.Unmarshal(dp.GetUser().GetPayload(), frame))
+	return frame
+}
+
+func TestDataRPC(t *testing.T) {
+	sent := make(chan []byte, 1)
+	d := newDataRPC(func(encoded []byte) error {
+		sent <- encoded
+		return nil
+	}, logger.GetLogger())
+	p := &typesfakes.FakeLocalParticipant{}
+
+	t.Run("server request", func(t *testing.T) {
+		go func() {
+			req := decodeDataRPCFrame(t, <-sent)
+			require.Equal(t, dataRPCRequest, req.Type)
+			require.Equal(t, "ping", req.Method)
+			require.Empty(t, req.Caller)
+			b, _ := json.Marshal(&dataRPCFrame{Type: dataRPCResponse, ID: req.ID, Payload: req.Payload + "-pong"})
+			d.handle(p, b)
+		}()
+
+		res, err := d.perform(context.Background(), "", "ping", "hello", nil)
+		require.NoError(t, err)
+		require.Equal(t, "hello-pong", res)
+	})
+
+	t.Run("forwarded request", func(t *testing.T) {
+		go func() {
+			req := decodeDataRPCFrame(t, <-sent)
+			require.Equal(t, "p0", req.Caller)
+			b, _ := json.Marshal(&dataRPCFrame{Type: dataRPCResponse, ID: req.ID})
+			d.handle(p, b)
+		}()
+
+		_, err := d.perform(context.Background(), "p0", "ping", "", nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("server request error", func(t *testing.T) {
+		go func() {
+			req := decodeDataRPCFrame(t, <-sent)
+			b, _ := json.Marshal(&dataRPCFrame{Type: dataRPCResponse, ID: req.ID, Error: "nope"})
+			d.handle(p, b)
+		}()
+
+		_, err := d.perform(context.Background(), "", "ping", "", nil)
+		var rpcErr *DataRPCError
+		require.True(t, errors.As(err, &rpcErr))
+		require.Equal(t, "nope", rpcErr.Message)
+	})
+
+	t.Run("server request timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := d.perform(ctx, "", "ping", "", nil)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		<-sent
+		require.Empty(t, d.pending)
+	})
+
+	t.Run("client request", func(t *testing.T) {
+		d.register("echo", func(_ context.Context, lp types.LocalParticipant, payload string) (string, error) {
+			require.Equal(t, p, lp)
+			return payload, nil
+		})
+
+		b, _ := json.Marshal(&dataRPCFrame{Type: dataRPCRequest, ID: "RPC_1", Method: "echo", Payload: "hi"})
+		d.handle(p, b)
+		res := decodeDataRPCFrame(t, <-sent)
+		require.Equal(t, &dataRPCFrame{Type: dataRPCResponse, ID: "RPC_1", Payload: "hi"}, res)
+
+		// removed handler
+		d.register("echo", nil)
+		d.handle(p, b)
+		res = decodeDataRPCFrame(t, <-sent)
+		require.Equal(t, ErrDataRPCMethodNotFound.Error(), res.Error)
+	})
+
+	t.Run("client request limit", func(t *testing.T) {
+		release := make(chan struct{})
+		d.register("wait", func(_ context.Context, _ types.LocalParticipant, _ string) (string, error) {
+			<-release
+			return "", nil
+		})
+
+		b, _ := json.Marshal(&dataRPCFrame{Type: dataRPCRequest, ID: "RPC_wait", Method: "wait"})
+		for i := 0; i < dataRPCMaxActiveRequests; i++ {
+			d.handle(p, b)
+		}
+		d.handle(p, b)
+		res := decodeDataRPCFrame(t, <-sent)
+		require.Equal(t, ErrDataRPCTooManyRequests.Error(), res.Error)
+
+		close(release)
+		for i := 0; i < dataRPCMaxActiveRequests; i++ {
+			res = decodeDataRPCFrame(t, <-sent)
+			require.Empty(t, res.Error)
+		}
+		require.Eventually(t, func() bool {
+			d.lock.Lock()
+			defer d.lock.Unlock()
+			return d.activeRequests == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestForwardDataRPC(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 2, numHidden: 1})
+	source := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+	destination := rm.GetParticipant("p1").(*typesfakes.FakeLocalParticipant)
+	destination.PerformDataRPCReturns("pong", nil)
+
+	forward := func(to string) (string, error) {
+		b, _ := json.Marshal(&DataRPCForwardRequest{Destination: to, Method: "ping", Payload: "hi"})
+		return rm.forwardDataRPC(context.Background(), source, string(b))
+	}
+
+	source.CanPublishDataReturns(false)
+	_, err := forward("p1")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+
+	source.CanPublishDataReturns(true)
+	res, err := forward("p1")
+	require.NoError(t, err)
+	require.Equal(t, "pong", res)
+	_, caller, method, payload := destination.PerformDataRPCArgsForCall(0)
+	require.Equal(t, source.Identity(), caller)
+	require.Equal(t, "ping", method)
+	require.Equal(t, "hi", payload)
+
+	// hidden participants are not reachable
+	_, err = forward("p2")
+	require.ErrorIs(t, err, ErrDataRPCNoDestination)
+	_, err = forward("unknown")
+	require.ErrorIs(t, err, ErrDataRPCNoDestination)
+}
+
+func TestDataRPCCannotPoseAsServer(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 2, numHidden: 1})
+	defer rm.Close(types.ParticipantCloseReasonNone)
+	source := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+	destination := rm.GetParticipant("p1").(*typesfakes.FakeLocalParticipant)
+	destination.HasClientCapabilityStub = func(capability types.ClientCapability) bool {
+		return capability == types.ClientCapabilityDataRPC
+	}
+	hidden := rm.GetParticipant("p2").(*typesfakes.FakeLocalParticipant)
+	hidden.HasClientCapabilityReturns(false)
+
+	t.Run("forwarded requests carry the caller", func(t *testing.T) {
+		// a caller in the forwarded payload is not part of the forward request, the server sets it
+		payload := `{"destination": "p1", "method": "admin", "caller": ""}`
+		_, err := rm.forwardDataRPC(context.Background(), source, payload)
+		require.NoError(t, err)
+		_, caller, _, _ := destination.PerformDataRPCArgsForCall(destination.PerformDataRPCCallCount() - 1)
+		require.Equal(t, source.Identity(), caller)
+	})
+
+	t.Run("frames of participants are not delivered to clients with the capability", func(t *testing.T) {
+		source.IsInterestedInDataTopicReturns(true)
+		destination.IsInterestedInDataTopicReturns(true)
+		// hidden participants send packets without identity, as the server does
+		frame, _ := json.Marshal(&dataRPCFrame{Type: dataRPCRequest, ID: "RPC_1", Method: "admin"})
+		topic := DataRPCTopic
+		hidden.OnDataPacketArgsForCall(0)(hidden, livekit.DataPacket_RELIABLE, &livekit.DataPacket{
+			Value: &livekit.DataPacket_User{
+				User: &livekit.UserPacket{Payload: frame, Topic: &topic},
+			},
+		})
+
+		require.Zero(t, destination.SendDataPacketCallCount())
+		// clients without the capability get it as an ordinary topic
+		require.Equal(t, 1, source.SendDataPacketCallCount())
+	})
+}
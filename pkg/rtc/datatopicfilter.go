@@ -0,0 +1,70 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DataRPCMethodSetDataTopics is handled by the participant. A participant declares the data topics it is
+// interested in with it, the payload is a comma separated list, an empty payload removes the filter.
+// When set, user data packets with a topic outside the list are not forwarded to the participant.
+// Packets without a topic are always forwarded. It needs the data_rpc client capability.
+const DataRPCMethodSetDataTopics = "lk.set_data_topics"
+
+type dataTopicFilter struct {
+	topics map[string]struct{}
+}
+
+func newDataTopicFilter(raw string) *dataTopicFilter {
+	f := &dataTopicFilter{}
+	for _, topic := range strings.Split(raw, ",") {
+		topic = strings.TrimSpace(topic)
+		if topic == "" {
+			continue
+		}
+		if f.topics == nil {
+			f.topics = make(map[string]struct{})
+		}
+		f.topics[topic] = struct{}{}
+	}
+	return f
+}
+
+func (f *dataTopicFilter) isInterested(topic string) bool {
+	if topic == "" || len(f.topics) == 0 {
+		return true
+	}
+
+	_, ok := f.topics[topic]
+	return ok
+}
+
+// --------------------------------------
+
+func (p *ParticipantImpl) handleSetDataTopicsRPC(_ context.Context, _ types.LocalParticipant, payload string) (string, error) {
+	p.dataTopicFilter.Store(newDataTopicFilter(payload))
+	return "", nil
+}
+
+// IsInterestedInDataTopic returns true if participant has not limited data topics
+// using DataRPCMethodSetDataTopics or has included the given topic
+func (p *ParticipantImpl) IsInterestedInDataTopic(topic string) bool {
+	filter := p.dataTopicFilter.Load()
+	return filter == nil || filter.isInterested(topic)
+}
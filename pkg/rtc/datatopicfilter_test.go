@@ -0,0 +1,60 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataTopicFilter(t *testing.T) {
+	t.Run("no topics", func(t *testing.T) {
+		for _, raw := range []string{"", " , ,"} {
+			f := newDataTopicFilter(raw)
+			require.True(t, f.isInterested(""))
+			require.True(t, f.isInterested("chat"))
+		}
+	})
+
+	t.Run("topics", func(t *testing.T) {
+		f := newDataTopicFilter(" chat, cursor ,,")
+		require.True(t, f.isInterested("chat"))
+		require.True(t, f.isInterested("cursor"))
+		require.False(t, f.isInterested("telemetry"))
+		require.False(t, f.isInterested("chat,cursor"))
+
+		// packets without topic are always forwarded
+		require.True(t, f.isInterested(""))
+	})
+}
+
+func TestSetDataTopicsRPC(t *testing.T) {
+	p := newParticipantForTest("test")
+	require.NotNil(t, p.dataRPC.handlers[DataRPCMethodSetDataTopics])
+	require.True(t, p.IsInterestedInDataTopic("telemetry"))
+
+	_, err := p.handleSetDataTopicsRPC(context.Background(), p, "chat,cursor")
+	require.NoError(t, err)
+	require.True(t, p.IsInterestedInDataTopic("chat"))
+	require.False(t, p.IsInterestedInDataTopic("telemetry"))
+	require.True(t, p.IsInterestedInDataTopic(""))
+
+	// empty payload removes the filter
+	_, err = p.handleSetDataTopicsRPC(context.Background(), p, "")
+	require.NoError(t, err)
+	require.True(t, p.IsInterestedInDataTopic("telemetry"))
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"net"
+
+	"github.com/pion/transport/v2"
+	"github.com/pion/webrtc/v3"
+
+	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/utils"
+)
+
+// mediaDSCPNet marks packets of ICE sockets with the video DSCP code point,
+// overriding it per packet for audio RTP where supported
+type mediaDSCPNet struct {
+	*utils.DSCPNet
+	audio uint8
+}
+
+func newMediaDSCPNet(n transport.Net, conf config.DSCPConfig) *mediaDSCPNet {
+	return &mediaDSCPNet{
+		DSCPNet: &utils.DSCPNet{Net: n, DSCP: conf.Video},
+		audio:   conf.Audio,
+	}
+}
+
+func (n *mediaDSCPNet) ListenUDP(network string, laddr *net.UDPAddr) (transport.UDPConn, error) {
+	conn, err := n.DSCPNet.ListenUDP(network, laddr)
+	if err != nil || n.audio == 0 {
+		return conn, err
+	}
+
+	oob := utils.DSCPControlMessage(utils.IsIPv6Addr(conn.LocalAddr()), n.audio)
+	if oob == nil {
+		return conn, nil
+	}
+	return &audioDSCPUDPConn{UDPConn: conn, oob: oob}, nil
+}
+
+// --------------------------------------
+
+type audioDSCPUDPConn struct {
+	transport.UDPConn
+	oob []byte
+}
+
+func (c *audioDSCPUDPConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if udpAddr, ok := addr.(*net.UDPAddr); ok && isAudioRTP(b) {
+		n, _, err := c.UDPConn.WriteMsgUDP(b, c.oob, udpAddr)
+		return n, err
+	}
+	return c.UDPConn.WriteTo(b, addr)
+}
+
+// isAudioRTP checks for RTP (and SRTP, header is not encrypted) packets with audio payload types of the media engine
+func isAudioRTP(b []byte) bool {
+	if len(b) < 12 || b[0]&0xc0 != 0x80 {
+		return false
+	}
+
+	pt := webrtc.PayloadType(b[1] & 0x7f)
+	return pt == opusPayloadType || pt == redPayloadType
+}
+
+// configureDSCP returns the net ICE sockets are created with, marking packets when enabled
+func configureDSCP(webRTCConfig *rtcconfig.WebRTCConfig, conf config.DSCPConfig, n transport.Net) transport.Net {
+	if conf.Audio == 0 && conf.Video == 0 {
+		return n
+	}
+
+	if webRTCConfig.TCPMuxListener != nil && conf.Video != 0 {
+		// accepted connections inherit the traffic class of the listener
+		l := webRTCConfig.TCPMuxListener
+		if err := utils.SetDSCP(l, utils.IsIPv6Addr(l.Addr()), conf.Video); err != nil {
+			logger.Warnw("could not set DSCP on ICE/TCP listener", err)
+		}
+	}
+	if webRTCConfig.UDPMux != nil {
+		logger.Infow("DSCP marking is not applied to packets of the shared UDP port")
+	}
+	return newMediaDSCPNet(n, conf)
+}
@@ -0,0 +1,45 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAudioRTP(t *testing.T) {
+	marshal := func(pt uint8) []byte {
+		b, err := (&rtp.Packet{
+			Header:  rtp.Header{Version: 2, PayloadType: pt, Marker: true, SequenceNumber: 1, SSRC: 1234},
+			Payload: []byte{1, 2, 3},
+		}).Marshal()
+		require.NoError(t, err)
+		return b
+	}
+
+	require.True(t, isAudioRTP(marshal(uint8(opusPayloadType))))
+	require.True(t, isAudioRTP(marshal(uint8(redPayloadType))))
+	require.False(t, isAudioRTP(marshal(96)))
+
+	// RTCP sender report
+	require.False(t, isAudioRTP([]byte{0x80, 200, 0, 6, 0, 0, 0, 1, 0, 0, 0, 0}))
+	// STUN binding request
+	require.False(t, isAudioRTP([]byte{0x00, 0x01, 0x00, 0x00, 0x21, 0x12, 0xa4, 0x42, 0, 0, 0, 0}))
+	// DTLS handshake
+	require.False(t, isAudioRTP([]byte{22, 0xfe, 0xfd, 0, 0, 0, 0, 0, 0, 0, 0, 0}))
+	require.False(t, isAudioRTP([]byte{0x80, 111}))
+}
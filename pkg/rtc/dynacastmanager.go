@@ -39,6 +39,15 @@ type DynacastManager struct {
 	dynacastQuality               map[string]*DynacastQuality // mime type => DynacastQuality
 	maxSubscribedQuality          map[string]livekit.VideoQuality
 	committedMaxSubscribedQuality map[string]livekit.VideoQuality
+	// cap set by server on quality the publisher sends, regardless of subscriptions
+	maxPublishedQuality livekit.VideoQuality
+	// cap applied by uplink bitrate limiter, kept separate so that it does not clobber the one above
+	uplinkLimitMaxQuality livekit.VideoQuality
+	// floor set by apps on quality the publisher sends, regardless of subscriptions, OFF for none.
+	// e. g. to keep a screen share at full quality for recording, even without subscribers
+	minPublishedQuality livekit.VideoQuality
+
+	numSubscribers int
 
 	maxSubscribedQualityDebounce        func(func())
 	maxSubscribedQualityDebouncePending bool
@@ -48,6 +57,7 @@ type DynacastManager struct {
 	isClosed bool
 
 	onSubscribedMaxQualityChange func(subscribedQualities []*livekit.SubscribedCodec, maxSubscribedQualities []types.SubscribedCodecQuality)
+	onSubscriberCountChange      func(numSubscribers int)
 }
 
 func NewDynacastManager(params DynacastManagerParams) *DynacastManager {
@@ -59,6 +69,9 @@ func NewDynacastManager(params DynacastManagerParams) *DynacastManager {
 		dynacastQuality:               make(map[string]*DynacastQuality),
 		maxSubscribedQuality:          make(map[string]livekit.VideoQuality),
 		committedMaxSubscribedQuality: make(map[string]livekit.VideoQuality),
+		maxPublishedQuality:           livekit.VideoQuality_HIGH,
+		uplinkLimitMaxQuality:         livekit.VideoQuality_HIGH,
+		minPublishedQuality:           livekit.VideoQuality_OFF,
 		qualityNotifyOpQueue: utils.NewOpsQueue(utils.OpsQueueParams{
 			Name:        "quality-notify",
 			MinSize:     64,
@@ -79,6 +92,13 @@ func (d *DynacastManager) OnSubscribedMaxQualityChange(f func(subscribedQualitie
 	d.lock.Unlock()
 }
 
+// OnSubscriberCountChange is called when the number of local subscribers to any codec of the track changes
+func (d *DynacastManager) OnSubscriberCountChange(f func(numSubscribers int)) {
+	d.lock.Lock()
+	d.onSubscriberCountChange = f
+	d.lock.Unlock()
+}
+
 func (d *DynacastManager) AddCodec(mime string) {
 	d.getOrCreateDynacastQuality(mime)
 }
@@ -133,10 +153,117 @@ func (d *DynacastManager) ForceQuality(quality livekit.VideoQuality) {
 	d.enqueueSubscribedQualityChange()
 }
 
+// SetMaxPublishedQuality caps the quality requested from the publisher,
+// layers above it are disabled even when subscribers want them.
+func (d *DynacastManager) SetMaxPublishedQuality(quality livekit.VideoQuality) {
+	d.lock.Lock()
+	if d.maxPublishedQuality == quality {
+		d.lock.Unlock()
+		return
+	}
+	d.maxPublishedQuality = quality
+	d.lock.Unlock()
+
+	d.update(true)
+}
+
+// MaxPublishedQuality returns the effective cap, i. e. lower of caps set by server and uplink bitrate limiter
+func (d *DynacastManager) MaxPublishedQuality() livekit.VideoQuality {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return d.getMaxPublishedQualityLocked()
+}
+
+func (d *DynacastManager) getMaxPublishedQualityLocked() livekit.VideoQuality {
+	if d.uplinkLimitMaxQuality < d.maxPublishedQuality {
+		return d.uplinkLimitMaxQuality
+	}
+	return d.maxPublishedQuality
+}
+
+// SetUplinkLimitMaxQuality caps the quality requested from the publisher to keep it under its uplink bitrate limit,
+// the lower of this and the cap set with SetMaxPublishedQuality applies.
+func (d *DynacastManager) SetUplinkLimitMaxQuality(quality livekit.VideoQuality) {
+	d.lock.Lock()
+	if d.uplinkLimitMaxQuality == quality {
+		d.lock.Unlock()
+		return
+	}
+	d.uplinkLimitMaxQuality = quality
+	d.lock.Unlock()
+
+	d.update(true)
+}
+
+// SetMinPublishedQuality keeps the publisher sending layers up to quality even when subscribers want less,
+// or nothing at all, i. e. it overrides pausing of those layers. OFF removes the floor.
+// The cap set with SetMaxPublishedQuality still applies.
+func (d *DynacastManager) SetMinPublishedQuality(quality livekit.VideoQuality) {
+	d.lock.Lock()
+	if d.minPublishedQuality == quality {
+		d.lock.Unlock()
+		return
+	}
+	d.minPublishedQuality = quality
+	d.lock.Unlock()
+
+	d.update(true)
+}
+
+func (d *DynacastManager) MinPublishedQuality() livekit.VideoQuality {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return d.minPublishedQuality
+}
+
+// MaxCommittedQuality returns the highest quality requested from the publisher across codecs, OFF when paused
+func (d *DynacastManager) MaxCommittedQuality() livekit.VideoQuality {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	maxQuality := livekit.VideoQuality_OFF
+	for _, quality := range d.committedMaxSubscribedQuality {
+		if quality != livekit.VideoQuality_OFF && (maxQuality == livekit.VideoQuality_OFF || quality > maxQuality) {
+			maxQuality = quality
+		}
+	}
+	return maxQuality
+}
+
+func (d *DynacastManager) NumSubscribers() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return d.numSubscribers
+}
+
 func (d *DynacastManager) NotifySubscriberMaxQuality(subscriberID livekit.ParticipantID, mime string, quality livekit.VideoQuality) {
 	dq := d.getOrCreateDynacastQuality(mime)
 	if dq != nil {
 		dq.NotifySubscriberMaxQuality(subscriberID, quality)
+		d.updateNumSubscribers()
+	}
+}
+
+func (d *DynacastManager) updateNumSubscribers() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	numSubscribers := 0
+	for _, dq := range d.dynacastQuality {
+		numSubscribers += dq.NumSubscribers()
+	}
+	if d.isClosed || numSubscribers == d.numSubscribers {
+		return
+	}
+
+	d.numSubscribers = numSubscribers
+	if onSubscriberCountChange := d.onSubscriberCountChange; onSubscriberCountChange != nil {
+		d.qualityNotifyOpQueue.Enqueue(func() {
+			onSubscriberCountChange(numSubscribers)
+		})
 	}
 }
 
@@ -195,23 +322,24 @@ func (d *DynacastManager) updateMaxQualityForMime(mime string, maxQuality liveki
 func (d *DynacastManager) update(force bool) {
 	d.lock.Lock()
 
+	maxSubscribedQuality := d.getCappedMaxSubscribedQualityLocked()
 	d.params.Logger.Debugw("processing quality change",
 		"force", force,
 		"committedMaxSubscribedQuality", d.committedMaxSubscribedQuality,
-		"maxSubscribedQuality", d.maxSubscribedQuality,
+		"maxSubscribedQuality", maxSubscribedQuality,
 	)
 
-	if len(d.maxSubscribedQuality) == 0 {
+	if len(maxSubscribedQuality) == 0 {
 		// no mime has been added, nothing to update
 		d.lock.Unlock()
 		return
 	}
 
 	// add or remove of a mime triggers an update
-	changed := len(d.maxSubscribedQuality) != len(d.committedMaxSubscribedQuality)
+	changed := len(maxSubscribedQuality) != len(d.committedMaxSubscribedQuality)
 	downgradesOnly := !changed
 	if !changed {
-		for mime, quality := range d.maxSubscribedQuality {
+		for mime, quality := range maxSubscribedQuality {
 			if cq, ok := d.committedMaxSubscribedQuality[mime]; ok {
 				if cq != quality {
 					changed = true
@@ -234,7 +362,7 @@ func (d *DynacastManager) update(force bool) {
 			if !d.maxSubscribedQualityDebouncePending {
 				d.params.Logger.Debugw("debouncing quality downgrade",
 					"committedMaxSubscribedQuality", d.committedMaxSubscribedQuality,
-					"maxSubscribedQuality", d.maxSubscribedQuality,
+					"maxSubscribedQuality", maxSubscribedQuality,
 				)
 				d.maxSubscribedQualityDebounce(func() {
 					d.update(true)
@@ -243,7 +371,7 @@ func (d *DynacastManager) update(force bool) {
 			} else {
 				d.params.Logger.Debugw("quality downgrade waiting for debounce",
 					"committedMaxSubscribedQuality", d.committedMaxSubscribedQuality,
-					"maxSubscribedQuality", d.maxSubscribedQuality,
+					"maxSubscribedQuality", maxSubscribedQuality,
 				)
 			}
 			d.lock.Unlock()
@@ -260,12 +388,12 @@ func (d *DynacastManager) update(force bool) {
 	d.params.Logger.Debugw("committing quality change",
 		"force", force,
 		"committedMaxSubscribedQuality", d.committedMaxSubscribedQuality,
-		"maxSubscribedQuality", d.maxSubscribedQuality,
+		"maxSubscribedQuality", maxSubscribedQuality,
 	)
 
 	// commit change
-	d.committedMaxSubscribedQuality = make(map[string]livekit.VideoQuality, len(d.maxSubscribedQuality))
-	for mime, quality := range d.maxSubscribedQuality {
+	d.committedMaxSubscribedQuality = make(map[string]livekit.VideoQuality, len(maxSubscribedQuality))
+	for mime, quality := range maxSubscribedQuality {
 		d.committedMaxSubscribedQuality[mime] = quality
 	}
 
@@ -273,6 +401,22 @@ func (d *DynacastManager) update(force bool) {
 	d.lock.Unlock()
 }
 
+func (d *DynacastManager) getCappedMaxSubscribedQualityLocked() map[string]livekit.VideoQuality {
+	maxQuality := d.getMaxPublishedQualityLocked()
+
+	maxSubscribedQuality := make(map[string]livekit.VideoQuality, len(d.maxSubscribedQuality))
+	for mime, quality := range d.maxSubscribedQuality {
+		if d.minPublishedQuality != livekit.VideoQuality_OFF && (quality == livekit.VideoQuality_OFF || quality < d.minPublishedQuality) {
+			quality = d.minPublishedQuality
+		}
+		if quality != livekit.VideoQuality_OFF && quality > maxQuality {
+			quality = maxQuality
+		}
+		maxSubscribedQuality[mime] = quality
+	}
+	return maxSubscribedQuality
+}
+
 func (d *DynacastManager) enqueueSubscribedQualityChange() {
 	if d.isClosed || d.onSubscribedMaxQualityChange == nil {
 		return
@@ -283,4 +283,134 @@ func TestSubscribedMaxQuality(t *testing.T) {
 			return subscribedCodecsAsString(expectedSubscribedQualities) == subscribedCodecsAsString(actualSubscribedQualities)
 		}, 10*time.Second, 100*time.Millisecond)
 	})
+
+	t.Run("max published quality", func(t *testing.T) {
+		dm := NewDynacastManager(DynacastManagerParams{})
+		var lock sync.Mutex
+		actualSubscribedQualities := make([]*livekit.SubscribedCodec, 0)
+		dm.OnSubscribedMaxQualityChange(func(subscribedQualities []*livekit.SubscribedCodec, _maxSubscribedQualities []types.SubscribedCodecQuality) {
+			lock.Lock()
+			actualSubscribedQualities = subscribedQualities
+			lock.Unlock()
+		})
+
+		dm.NotifySubscriberMaxQuality("s1", webrtc.MimeTypeVP8, livekit.VideoQuality_HIGH)
+		dm.SetMaxPublishedQuality(livekit.VideoQuality_MEDIUM)
+
+		expectedSubscribedQualities := []*livekit.SubscribedCodec{
+			{
+				Codec: webrtc.MimeTypeVP8,
+				Qualities: []*livekit.SubscribedQuality{
+					{Quality: livekit.VideoQuality_LOW, Enabled: true},
+					{Quality: livekit.VideoQuality_MEDIUM, Enabled: true},
+					{Quality: livekit.VideoQuality_HIGH, Enabled: false},
+				},
+			},
+		}
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+
+			return subscribedCodecsAsString(expectedSubscribedQualities) == subscribedCodecsAsString(actualSubscribedQualities)
+		}, 10*time.Second, 100*time.Millisecond)
+
+		// lifting the cap enables all layers subscribers want
+		dm.SetMaxPublishedQuality(livekit.VideoQuality_HIGH)
+
+		expectedSubscribedQualities[0].Qualities[2].Enabled = true
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+
+			return subscribedCodecsAsString(expectedSubscribedQualities) == subscribedCodecsAsString(actualSubscribedQualities)
+		}, 10*time.Second, 100*time.Millisecond)
+
+		// lower of server and uplink limit caps applies
+		dm.SetUplinkLimitMaxQuality(livekit.VideoQuality_LOW)
+		dm.SetMaxPublishedQuality(livekit.VideoQuality_MEDIUM)
+		require.Equal(t, livekit.VideoQuality_LOW, dm.MaxPublishedQuality())
+
+		expectedSubscribedQualities[0].Qualities[1].Enabled = false
+		expectedSubscribedQualities[0].Qualities[2].Enabled = false
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+
+			return subscribedCodecsAsString(expectedSubscribedQualities) == subscribedCodecsAsString(actualSubscribedQualities)
+		}, 10*time.Second, 100*time.Millisecond)
+
+		dm.SetUplinkLimitMaxQuality(livekit.VideoQuality_HIGH)
+		require.Equal(t, livekit.VideoQuality_MEDIUM, dm.MaxPublishedQuality())
+	})
+
+	t.Run("min published quality", func(t *testing.T) {
+		dm := NewDynacastManager(DynacastManagerParams{})
+		var lock sync.Mutex
+		actualSubscribedQualities := make([]*livekit.SubscribedCodec, 0)
+		dm.OnSubscribedMaxQualityChange(func(subscribedQualities []*livekit.SubscribedCodec, _maxSubscribedQualities []types.SubscribedCodecQuality) {
+			lock.Lock()
+			actualSubscribedQualities = subscribedQualities
+			lock.Unlock()
+		})
+		var numSubscribers int
+		dm.OnSubscriberCountChange(func(n int) {
+			lock.Lock()
+			numSubscribers = n
+			lock.Unlock()
+		})
+
+		dm.NotifySubscriberMaxQuality("s1", webrtc.MimeTypeVP8, livekit.VideoQuality_LOW)
+		dm.NotifySubscriberMaxQuality("s2", webrtc.MimeTypeVP8, livekit.VideoQuality_LOW)
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+
+			return numSubscribers == 2
+		}, 10*time.Second, 100*time.Millisecond)
+
+		// floor keeps layers enabled even after all subscribers leave
+		dm.SetMinPublishedQuality(livekit.VideoQuality_HIGH)
+		dm.NotifySubscriberMaxQuality("s1", webrtc.MimeTypeVP8, livekit.VideoQuality_OFF)
+		dm.NotifySubscriberMaxQuality("s2", webrtc.MimeTypeVP8, livekit.VideoQuality_OFF)
+
+		expectedSubscribedQualities := []*livekit.SubscribedCodec{
+			{
+				Codec: webrtc.MimeTypeVP8,
+				Qualities: []*livekit.SubscribedQuality{
+					{Quality: livekit.VideoQuality_LOW, Enabled: true},
+					{Quality: livekit.VideoQuality_MEDIUM, Enabled: true},
+					{Quality: livekit.VideoQuality_HIGH, Enabled: true},
+				},
+			},
+		}
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+
+			return numSubscribers == 0 && subscribedCodecsAsString(expectedSubscribedQualities) == subscribedCodecsAsString(actualSubscribedQualities)
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, livekit.VideoQuality_HIGH, dm.MaxCommittedQuality())
+
+		// max published quality still caps the floor
+		dm.SetMaxPublishedQuality(livekit.VideoQuality_MEDIUM)
+		expectedSubscribedQualities[0].Qualities[2].Enabled = false
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+
+			return subscribedCodecsAsString(expectedSubscribedQualities) == subscribedCodecsAsString(actualSubscribedQualities)
+		}, 10*time.Second, 100*time.Millisecond)
+
+		// removing the floor pauses the track
+		dm.SetMinPublishedQuality(livekit.VideoQuality_OFF)
+		expectedSubscribedQualities[0].Qualities[0].Enabled = false
+		expectedSubscribedQualities[0].Qualities[1].Enabled = false
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+
+			return subscribedCodecsAsString(expectedSubscribedQualities) == subscribedCodecsAsString(actualSubscribedQualities)
+		}, 10*time.Second, 100*time.Millisecond)
+		require.Equal(t, livekit.VideoQuality_OFF, dm.MaxCommittedQuality())
+	})
 }
@@ -108,6 +108,14 @@ func (d *DynacastQuality) NotifySubscriberNodeMaxQuality(nodeID livekit.NodeID,
 	d.updateQualityChange(false)
 }
 
+// NumSubscribers returns number of local subscribers with a quality other than OFF
+func (d *DynacastQuality) NumSubscribers() int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return len(d.maxSubscriberQuality)
+}
+
 func (d *DynacastQuality) reset() {
 	d.lock.Lock()
 	d.initialized = false
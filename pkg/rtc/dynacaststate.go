@@ -0,0 +1,89 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DynacastStateTopic is the data topic on which publishers are told how their video tracks are consumed.
+// SubscribedQualityUpdate only carries enabled layers, so number of subscribers and the resolution
+// of the highest quality requested are sent as a user data packet to the publisher.
+const DynacastStateTopic = "lk.dynacast_state"
+
+type dynacastState struct {
+	TrackSid             string `json:"track_sid"`
+	SubscriberCount      int    `json:"subscriber_count"`
+	MaxSubscribedQuality string `json:"max_subscribed_quality"`
+	MaxSubscribedWidth   uint32 `json:"max_subscribed_width,omitempty"`
+	MaxSubscribedHeight  uint32 `json:"max_subscribed_height,omitempty"`
+}
+
+func (p *ParticipantImpl) sendDynacastState(trackID livekit.TrackID) {
+	if p.params.DisableDynacast || !p.HasClientCapability(types.ClientCapabilityDynacastState) || !p.IsInterestedInDataTopic(DynacastStateTopic) {
+		return
+	}
+
+	mt, ok := p.GetPublishedTrack(trackID).(*MediaTrack)
+	if !ok {
+		return
+	}
+
+	numSubscribers, maxQuality := mt.DynacastState()
+	state := &dynacastState{
+		TrackSid:             string(trackID),
+		SubscriberCount:      numSubscribers,
+		MaxSubscribedQuality: maxQuality.String(),
+	}
+	if maxQuality != livekit.VideoQuality_OFF {
+		for _, layer := range mt.ToProto().Layers {
+			if layer.Quality == maxQuality {
+				state.MaxSubscribedWidth = layer.Width
+				state.MaxSubscribedHeight = layer.Height
+				break
+			}
+		}
+	}
+
+	payload, err := json.Marshal(state)
+	if err != nil {
+		p.pubLogger.Errorw("could not marshal dynacast state", err, "trackID", trackID)
+		return
+	}
+
+	topic := DynacastStateTopic
+	encoded, err := proto.Marshal(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   &topic,
+			},
+		},
+	})
+	if err != nil {
+		p.pubLogger.Errorw("could not marshal dynacast state packet", err, "trackID", trackID)
+		return
+	}
+
+	if err := p.SendDataPacket(livekit.DataPacket_RELIABLE, encoded); err != nil {
+		p.pubLogger.Debugw("could not send dynacast state", "error", err, "trackID", trackID)
+	}
+}
@@ -20,12 +20,18 @@ import (
 
 var (
 	ErrRoomClosed              = errors.New("room has already closed")
+	ErrRoomLocked              = errors.New("room is locked")
 	ErrPermissionDenied        = errors.New("no permissions to access the room")
 	ErrMaxParticipantsExceeded = errors.New("room has exceeded its max participants")
 	ErrLimitExceeded           = errors.New("node has exceeded its configured limit")
 	ErrAlreadyJoined           = errors.New("a participant with the same identity is already in the room")
+	ErrParticipantRejected     = errors.New("participant rejected by admission controller")
+	ErrAdmissionFailed         = errors.New("participant admission failed")
+	ErrJoinRateLimited         = errors.New("room join rate exceeded")
 	ErrDataChannelUnavailable  = errors.New("data channel is not available")
 	ErrDataChannelBufferFull   = errors.New("data channel buffer is full")
+	ErrInvalidDataFragment     = errors.New("invalid data packet fragment")
+	ErrDataMessageTooLarge     = errors.New("data message exceeds size limit")
 	ErrTransportFailure        = errors.New("transport failure")
 	ErrEmptyIdentity           = errors.New("participant identity cannot be empty")
 	ErrEmptyParticipantID      = errors.New("participant ID cannot be empty")
@@ -34,6 +40,18 @@ var (
 	ErrNameExceedsLimits       = errors.New("name length exceeds limits")
 	ErrMetadataExceedsLimits   = errors.New("metadata size exceeds limits")
 	ErrAttributesExceedsLimits = errors.New("attributes size exceeds limits")
+	ErrHLSNotRunning           = errors.New("HLS output is not running")
+	ErrHLSAlreadyRunning       = errors.New("HLS output is already running")
+	ErrHLSUnsupportedTracks    = errors.New("HLS output needs at most one H.264 video and one Opus audio track")
+	ErrPacketCaptureRunning    = errors.New("packet capture is already running")
+	ErrPacketCaptureNotRunning = errors.New("packet capture is not running")
+	ErrDataRPCMethodNotFound   = errors.New("rpc method not found")
+	ErrDataRPCTooManyRequests  = errors.New("too many rpc requests in progress")
+	ErrDataRPCUnsupported      = errors.New("participant does not support rpc")
+	ErrDataRPCNoDestination    = errors.New("rpc destination is not in the room")
+	ErrDataRPCInvalidPayload   = errors.New("invalid rpc payload")
+	ErrLoadTestRunning         = errors.New("load test is already running")
+	ErrLoadTestNotRunning      = errors.New("load test is not running")
 
 	// Track subscription related
 	ErrNoTrackPermission         = errors.New("participant is not allowed to subscribe to this track")
@@ -0,0 +1,177 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"github.com/livekit/protocol/livekit"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// A hidden participant is not announced to others. When a hidden participant sets a subscription
+// permission that does not allow all participants, the participants (or permission groups) listed
+// in it form a whitelist: only those participants learn about the hidden participant, see the tracks
+// they are granted and get subscribed to them. Other participants do not learn the tracks exist.
+//
+// Without such a subscription permission, tracks of hidden participants are handled like before,
+// i. e. they are available to everyone while the participant itself stays hidden.
+
+// hasRestrictedVisibility returns true when p is hidden and limits its tracks to a whitelist
+func hasRestrictedVisibility(p types.LocalParticipant) bool {
+	if !p.Hidden() {
+		return false
+	}
+
+	subscriptionPermission, _ := p.SubscriptionPermission()
+	return subscriptionPermission != nil && !subscriptionPermission.AllParticipants
+}
+
+// visibleTrackPermissions returns track permissions of hidden participant p granted to sub,
+// nil when p is not visible to sub.
+// It only looks at participants passed in, so it is safe to call with room lock held.
+func visibleTrackPermissions(p types.LocalParticipant, sub types.LocalParticipant) []*livekit.TrackPermission {
+	if p.ID() == sub.ID() || !hasRestrictedVisibility(p) {
+		return nil
+	}
+
+	subscriptionPermission, _ := p.SubscriptionPermission()
+	subGroups := sub.PermissionGroups()
+	var perms []*livekit.TrackPermission
+	for _, trackPerms := range subscriptionPermission.TrackPermissions {
+		if group, ok := permissionGroupFromTrackPermission(trackPerms); ok {
+			if slices.Contains(subGroups, group) {
+				perms = append(perms, trackPerms)
+			}
+			continue
+		}
+
+		if livekit.ParticipantIdentity(trackPerms.ParticipantIdentity) == sub.Identity() ||
+			(trackPerms.ParticipantIdentity == "" && livekit.ParticipantID(trackPerms.ParticipantSid) == sub.ID()) {
+			perms = append(perms, trackPerms)
+		}
+	}
+	return perms
+}
+
+// visibleParticipantInfo returns a copy of pi limited to tracks permitted by perms
+func visibleParticipantInfo(pi *livekit.ParticipantInfo, perms []*livekit.TrackPermission) *livekit.ParticipantInfo {
+	visible := proto.Clone(pi).(*livekit.ParticipantInfo)
+	if visible.Permission != nil {
+		// not hidden from the whitelist, updates of hidden participants are not delivered otherwise
+		visible.Permission.Hidden = false
+	}
+	visible.Tracks = nil
+	for _, ti := range pi.Tracks {
+		for _, trackPerms := range perms {
+			if isTrackPermitted(trackPerms, livekit.TrackID(ti.Sid)) {
+				visible.Tracks = append(visible.Tracks, proto.Clone(ti).(*livekit.TrackInfo))
+				break
+			}
+		}
+	}
+	return visible
+}
+
+// getVisibleHiddenParticipantInfoLocked returns info of hidden participants visible to sub, assumes lock is already acquired
+func (r *Room) getVisibleHiddenParticipantInfoLocked(sub types.LocalParticipant) []*livekit.ParticipantInfo {
+	var pis []*livekit.ParticipantInfo
+	for _, p := range r.participants {
+		if perms := visibleTrackPermissions(p, sub); len(perms) != 0 {
+			pis = append(pis, visibleParticipantInfo(p.ToProto(), perms))
+		}
+	}
+	return pis
+}
+
+// broadcastHiddenParticipantState sends state of hidden participant p to the participants it is visible to
+func (r *Room) broadcastHiddenParticipantState(p types.LocalParticipant, pi *livekit.ParticipantInfo) {
+	if !hasRestrictedVisibility(p) {
+		return
+	}
+
+	for _, op := range r.GetParticipants() {
+		perms := visibleTrackPermissions(p, op)
+		if len(perms) == 0 {
+			continue
+		}
+
+		if err := op.SendParticipantUpdate([]*livekit.ParticipantInfo{visibleParticipantInfo(pi, perms)}); err != nil {
+			op.GetLogger().Errorw("could not send update to participant", err)
+		}
+	}
+}
+
+// getHiddenParticipantViewers returns participants hidden participant p is visible to
+func (r *Room) getHiddenParticipantViewers(p types.LocalParticipant) map[livekit.ParticipantID]types.LocalParticipant {
+	viewers := make(map[livekit.ParticipantID]types.LocalParticipant)
+	if !hasRestrictedVisibility(p) {
+		return viewers
+	}
+
+	for _, op := range r.GetParticipants() {
+		if len(visibleTrackPermissions(p, op)) != 0 {
+			viewers[op.ID()] = op
+		}
+	}
+	return viewers
+}
+
+// sendHiddenParticipantRemoved tells participants that were dropped from the whitelist of hidden participant p
+// that it is gone, as it is no longer visible to them
+func (r *Room) sendHiddenParticipantRemoved(p types.LocalParticipant, dropped map[livekit.ParticipantID]types.LocalParticipant) {
+	if len(dropped) == 0 {
+		return
+	}
+
+	pi := visibleParticipantInfo(p.ToProto(), nil)
+	pi.State = livekit.ParticipantInfo_DISCONNECTED
+	for _, op := range dropped {
+		if err := op.SendParticipantUpdate([]*livekit.ParticipantInfo{pi}); err != nil {
+			op.GetLogger().Errorw("could not send update to participant", err)
+		}
+	}
+}
+
+// isTrackHiddenFrom checks if track of publisher pub should not be revealed to sub
+func isTrackHiddenFrom(pub types.LocalParticipant, trackID livekit.TrackID, sub types.LocalParticipant) bool {
+	if !hasRestrictedVisibility(pub) {
+		return false
+	}
+
+	for _, trackPerms := range visibleTrackPermissions(pub, sub) {
+		if isTrackPermitted(trackPerms, trackID) {
+			return false
+		}
+	}
+	return true
+}
+
+// subscribeToVisibleTracks subscribes sub to tracks of hidden participant p visible to it, when sub auto subscribes
+func (r *Room) subscribeToVisibleTracks(p types.LocalParticipant, sub types.LocalParticipant) {
+	r.lock.RLock()
+	shouldSubscribe := r.autoSubscribe(sub)
+	r.lock.RUnlock()
+	if !shouldSubscribe {
+		return
+	}
+
+	for _, track := range p.GetPublishedTracks() {
+		if !isTrackHiddenFrom(p, track.ID(), sub) {
+			sub.SubscribeToTrack(track.ID())
+		}
+	}
+}
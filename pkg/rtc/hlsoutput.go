@@ -0,0 +1,358 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/hls"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+// minimum interval between key frame requests of an HLS video sink
+const hlsKeyFrameRequestInterval = time.Second
+
+// HLS output packages one video and one audio track of a room into a low latency HLS playlist on local disk,
+// a lightweight alternative to egress. Tracks are tapped internally, i. e. the output is not a participant.
+// Only the lowest spatial layer of video is used, the publisher is kept sending it while HLS output runs.
+type roomHLSOutput struct {
+	subscriberID livekit.ParticipantID
+	params       hls.MuxerParams
+	startedAt    time.Time
+	muxer        *hls.Muxer
+	sinks        map[livekit.TrackID]*hlsSink
+}
+
+// StartHLS starts HLS output of given tracks to params.Dir, at most one video (H.264) and one audio (Opus) track
+func (r *Room) StartHLS(trackIDs []livekit.TrackID, params hls.MuxerParams) error {
+	var video, audio sfu.TrackReceiver
+	var videoTrack types.MediaTrack
+	for _, trackID := range trackIDs {
+		info := r.trackManager.GetTrackInfo(trackID)
+		if info == nil {
+			return ErrTrackNotFound
+		}
+		receivers := info.Track.Receivers()
+		if len(receivers) == 0 {
+			return ErrTrackNotFound
+		}
+
+		receiver := receivers[0]
+		switch mime := receiver.Codec().MimeType; {
+		case video == nil && strings.EqualFold(mime, webrtc.MimeTypeH264):
+			video = receiver
+			videoTrack = info.Track
+		case audio == nil && strings.EqualFold(mime, webrtc.MimeTypeOpus):
+			audio = receiver
+		case audio == nil && strings.EqualFold(mime, sfu.MimeTypeAudioRed):
+			audio = receiver.GetPrimaryReceiverForRed()
+		default:
+			return ErrHLSUnsupportedTracks
+		}
+	}
+	if video == nil && audio == nil {
+		return ErrHLSUnsupportedTracks
+	}
+
+	r.lock.Lock()
+	if r.IsClosed() {
+		r.lock.Unlock()
+		return ErrRoomClosed
+	}
+	if r.hlsOutput != nil {
+		r.lock.Unlock()
+		return ErrHLSAlreadyRunning
+	}
+
+	muxer, err := hls.NewMuxer(params)
+	if err != nil {
+		r.lock.Unlock()
+		return err
+	}
+	ho := &roomHLSOutput{
+		subscriberID: livekit.ParticipantID(guid.New(utils.ParticipantPrefix)),
+		params:       params,
+		startedAt:    time.Now(),
+		muxer:        muxer,
+		sinks:        make(map[livekit.TrackID]*hlsSink),
+	}
+	// all tracks are added to muxer before attaching sinks, so that the muxer waits for all of them
+	var sinks []*hlsSink
+	if video != nil {
+		sinks = append(sinks, newHLSSink(ho.subscriberID, video, muxer.AddVideoTrack(0, 0), true, r.Logger))
+	}
+	if audio != nil {
+		sinks = append(sinks, newHLSSink(ho.subscriberID, audio, muxer.AddAudioTrack(), false, r.Logger))
+	}
+	for _, sink := range sinks {
+		ho.sinks[sink.trackID] = sink
+	}
+	r.hlsOutput = ho
+	r.lock.Unlock()
+
+	if lmt, ok := videoTrack.(types.LocalMediaTrack); ok {
+		lmt.SetMinPublishedQuality(livekit.VideoQuality_LOW)
+	}
+	for _, sink := range sinks {
+		if err := sink.receiver.AddDownTrack(sink); err != nil {
+			r.Logger.Warnw("could not attach HLS output to track", err, "trackID", sink.trackID)
+			_ = r.StopHLS()
+			return err
+		}
+	}
+	if video != nil {
+		video.SendPLI(0, true)
+	}
+
+	r.Logger.Infow("HLS output started", "dir", params.Dir, "trackIDs", trackIDs)
+	return nil
+}
+
+// StopHLS ends the playlist and detaches from tracks, media stays on disk
+func (r *Room) StopHLS() error {
+	r.lock.Lock()
+	ho := r.hlsOutput
+	r.hlsOutput = nil
+	r.lock.Unlock()
+	if ho == nil {
+		return ErrHLSNotRunning
+	}
+
+	for _, sink := range ho.sinks {
+		r.detachHLSSink(ho, sink)
+	}
+	ho.muxer.Close()
+
+	status := ho.muxer.Status()
+	r.Logger.Infow(
+		"HLS output stopped",
+		"dir", ho.params.Dir,
+		"segments", status.SegmentsWritten,
+		"duration", status.Duration,
+		"error", status.LastError,
+	)
+	return nil
+}
+
+func (r *Room) detachHLSSink(ho *roomHLSOutput, sink *hlsSink) {
+	sink.receiver.DeleteDownTrack(ho.subscriberID)
+	sink.Close()
+
+	if !sink.isVideo {
+		return
+	}
+	if info := r.trackManager.GetTrackInfo(sink.trackID); info != nil {
+		if lmt, ok := info.Track.(types.LocalMediaTrack); ok {
+			lmt.SetMinPublishedQuality(livekit.VideoQuality_OFF)
+		}
+	}
+}
+
+// GetHLSStatus returns status of HLS output, nil when not running
+func (r *Room) GetHLSStatus() *hls.Status {
+	r.lock.RLock()
+	ho := r.hlsOutput
+	r.lock.RUnlock()
+	if ho == nil {
+		return nil
+	}
+
+	status := ho.muxer.Status()
+	return &status
+}
+
+func (r *Room) onHLSTrackUnpublished(track types.MediaTrack) {
+	r.lock.RLock()
+	ho := r.hlsOutput
+	r.lock.RUnlock()
+	if ho == nil || ho.sinks[track.ID()] == nil {
+		return
+	}
+
+	// segments are cut on the video track and audio is interleaved with it, end the playlist when either goes away
+	r.Logger.Infow("HLS output track unpublished, stopping", "trackID", track.ID())
+	_ = r.StopHLS()
+}
+
+func (r *Room) hlsDebugInfo() map[string]interface{} {
+	r.lock.RLock()
+	ho := r.hlsOutput
+	r.lock.RUnlock()
+	if ho == nil {
+		return nil
+	}
+
+	status := ho.muxer.Status()
+	trackIDs := make([]livekit.TrackID, 0, len(ho.sinks))
+	for trackID := range ho.sinks {
+		trackIDs = append(trackIDs, trackID)
+	}
+	return map[string]interface{}{
+		"Dir":             ho.params.Dir,
+		"StartedAt":       ho.startedAt,
+		"TrackIDs":        trackIDs,
+		"SegmentsWritten": status.SegmentsWritten,
+		"PartsWritten":    status.PartsWritten,
+		"Duration":        status.Duration.String(),
+		"PendingWrites":   status.PendingWrites,
+		"LastError":       status.LastError,
+	}
+}
+
+// ------------------------------------------------------------
+
+// hlsSink is attached to a track receiver as a down track and writes samples of the track to the HLS muxer
+var _ sfu.TrackSender = (*hlsSink)(nil)
+
+type hlsSink struct {
+	subscriberID livekit.ParticipantID
+	trackID      livekit.TrackID
+	track        *hls.Track
+	isVideo      bool
+	receiver     sfu.TrackReceiver
+	logger       logger.Logger
+
+	lock                sync.Mutex
+	depacketizer        *hls.H264Depacketizer
+	hasParameterSets    bool
+	lastKeyFrameRequest time.Time
+	lastSN              uint64
+	initialized         bool
+	writeFailures       int
+
+	closed atomic.Bool
+}
+
+func newHLSSink(
+	subscriberID livekit.ParticipantID,
+	receiver sfu.TrackReceiver,
+	track *hls.Track,
+	isVideo bool,
+	logger logger.Logger,
+) *hlsSink {
+	s := &hlsSink{
+		subscriberID: subscriberID,
+		trackID:      receiver.TrackID(),
+		receiver:     receiver,
+		track:        track,
+		isVideo:      isVideo,
+		logger:       logger,
+	}
+	if isVideo {
+		s.depacketizer = hls.NewH264Depacketizer()
+	}
+	return s
+}
+
+func (s *hlsSink) WriteRTP(p *buffer.ExtPacket, layer int32) error {
+	if s.closed.Load() || layer != 0 || len(p.Packet.Payload) == 0 {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	arrival := time.Unix(0, p.Arrival)
+	if !s.isVideo {
+		if s.initialized && p.ExtSequenceNumber <= s.lastSN {
+			return nil
+		}
+		s.initialized = true
+		s.lastSN = p.ExtSequenceNumber
+
+		s.handleWriteError(s.track.WriteSample(p.ExtTimestamp, arrival, append([]byte(nil), p.Packet.Payload...), true))
+		return nil
+	}
+
+	for _, au := range s.depacketizer.Push(p.ExtSequenceNumber, p.ExtTimestamp, p.Packet.Marker, p.Packet.Payload) {
+		if !s.hasParameterSets {
+			sps, pps := s.depacketizer.ParameterSets()
+			if len(sps) == 0 || len(pps) == 0 {
+				continue
+			}
+			s.track.SetParameterSets(sps, pps)
+			s.hasParameterSets = true
+		}
+		s.handleWriteError(s.track.WriteSample(au.Timestamp, arrival, au.Data, au.KeyFrame))
+	}
+
+	if (s.depacketizer.NeedKeyFrame() || !s.hasParameterSets) && time.Since(s.lastKeyFrameRequest) > hlsKeyFrameRequestInterval {
+		s.lastKeyFrameRequest = time.Now()
+		s.receiver.SendPLI(0, false)
+	}
+	return nil
+}
+
+func (s *hlsSink) handleWriteError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.writeFailures++
+	if s.writeFailures%100 == 1 {
+		s.logger.Warnw("could not write HLS sample", err, "trackID", s.trackID, "failures", s.writeFailures)
+	}
+}
+
+func (s *hlsSink) Close() {
+	s.closed.Store(true)
+}
+
+func (s *hlsSink) IsClosed() bool {
+	return s.closed.Load()
+}
+
+func (s *hlsSink) ID() string {
+	return string(s.subscriberID)
+}
+
+func (s *hlsSink) SubscriberID() livekit.ParticipantID {
+	return s.subscriberID
+}
+
+func (s *hlsSink) UpTrackLayersChange() {}
+
+func (s *hlsSink) UpTrackBitrateAvailabilityChange() {}
+
+func (s *hlsSink) UpTrackMaxPublishedLayerChange(_maxPublishedLayer int32) {}
+
+func (s *hlsSink) UpTrackMaxTemporalLayerSeenChange(_maxTemporalLayerSeen int32) {}
+
+func (s *hlsSink) UpTrackBitrateReport(_availableLayers []int32, _bitrates sfu.Bitrates) {}
+
+func (s *hlsSink) TrackInfoAvailable() {}
+
+func (s *hlsSink) Resync() {}
+
+func (s *hlsSink) HandleRTCPSenderReportData(
+	_payloadType webrtc.PayloadType,
+	_isSVC bool,
+	_layer int32,
+	_publisherSRData *buffer.RTCPSenderReportData,
+) error {
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"net"
+	"sync"
+
+	"github.com/pion/ice/v2"
+
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+// udpMuxWithMetrics records ICE sessions demultiplexed on the shared UDP port(s) and their traffic
+type udpMuxWithMetrics struct {
+	ice.UDPMux
+
+	lock   sync.Mutex
+	ufrags map[string]struct{}
+}
+
+func newUDPMuxWithMetrics(mux ice.UDPMux) *udpMuxWithMetrics {
+	return &udpMuxWithMetrics{
+		UDPMux: mux,
+		ufrags: make(map[string]struct{}),
+	}
+}
+
+func (m *udpMuxWithMetrics) GetConn(ufrag string, addr net.Addr) (net.PacketConn, error) {
+	conn, err := m.UDPMux.GetConn(ufrag, addr)
+	if err != nil {
+		prometheus.IncrementICEMuxError("udp")
+		return nil, err
+	}
+
+	m.lock.Lock()
+	if _, ok := m.ufrags[ufrag]; !ok {
+		m.ufrags[ufrag] = struct{}{}
+		prometheus.AddICEMuxConnection("udp")
+	}
+	m.lock.Unlock()
+
+	return &muxedPacketConnWithMetrics{PacketConn: conn}, nil
+}
+
+func (m *udpMuxWithMetrics) RemoveConnByUfrag(ufrag string) {
+	m.lock.Lock()
+	if _, ok := m.ufrags[ufrag]; ok {
+		delete(m.ufrags, ufrag)
+		prometheus.SubICEMuxConnection("udp")
+	}
+	m.lock.Unlock()
+
+	m.UDPMux.RemoveConnByUfrag(ufrag)
+}
+
+type muxedPacketConnWithMetrics struct {
+	net.PacketConn
+}
+
+func (c *muxedPacketConnWithMetrics) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(p)
+	if n > 0 {
+		prometheus.IncrementICEMuxUDPPacket(prometheus.Incoming, n)
+	}
+	return n, addr, err
+}
+
+func (c *muxedPacketConnWithMetrics) WriteTo(p []byte, addr net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(p, addr)
+	if n > 0 {
+		prometheus.IncrementICEMuxUDPPacket(prometheus.Outgoing, n)
+	}
+	return n, err
+}
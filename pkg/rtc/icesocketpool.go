@@ -0,0 +1,135 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"net"
+	"sync"
+
+	"github.com/pion/transport/v2"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+// ICESocketPool keeps UDP sockets bound ahead of time for the host and srflx candidates of new peer connections,
+// so gathering draws a ready socket instead of binding one. Sockets are pooled per local address, the pool for an
+// address fills up in the background once a peer connection first gathers on it, and is topped up after each draw.
+// Only ephemeral port requests are served, sockets on a specific port are passed through.
+type ICESocketPool struct {
+	transport.Net
+	size int
+
+	lock    sync.Mutex
+	idle    map[string][]transport.UDPConn
+	filling map[string]bool
+	closed  bool
+}
+
+func NewICESocketPool(n transport.Net, size int) *ICESocketPool {
+	return &ICESocketPool{
+		Net:     n,
+		size:    size,
+		idle:    make(map[string][]transport.UDPConn),
+		filling: make(map[string]bool),
+	}
+}
+
+func (p *ICESocketPool) ListenUDP(network string, laddr *net.UDPAddr) (transport.UDPConn, error) {
+	if laddr == nil || laddr.Port != 0 {
+		return p.Net.ListenUDP(network, laddr)
+	}
+
+	key := network + "/" + laddr.IP.String()
+
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return p.Net.ListenUDP(network, laddr)
+	}
+	var conn transport.UDPConn
+	if conns := p.idle[key]; len(conns) != 0 {
+		conn = conns[len(conns)-1]
+		p.idle[key] = conns[:len(conns)-1]
+	}
+	fill := !p.filling[key]
+	if fill {
+		p.filling[key] = true
+	}
+	p.lock.Unlock()
+
+	if fill {
+		go p.fill(key, network, &net.UDPAddr{IP: laddr.IP, Zone: laddr.Zone})
+	}
+
+	prometheus.RecordICESocketPoolDraw(conn != nil)
+	if conn != nil {
+		prometheus.AddICESocketPoolIdle(-1)
+		return conn, nil
+	}
+	return p.Net.ListenUDP(network, laddr)
+}
+
+func (p *ICESocketPool) fill(key string, network string, laddr *net.UDPAddr) {
+	for {
+		p.lock.Lock()
+		if p.closed || len(p.idle[key]) >= p.size {
+			delete(p.filling, key)
+			p.lock.Unlock()
+			return
+		}
+		p.lock.Unlock()
+
+		conn, err := p.Net.ListenUDP(network, laddr)
+		if err != nil {
+			logger.Warnw("could not bind pooled ICE socket", err, "network", network, "addr", laddr)
+			p.lock.Lock()
+			delete(p.filling, key)
+			p.lock.Unlock()
+			return
+		}
+
+		p.lock.Lock()
+		if p.closed {
+			p.lock.Unlock()
+			_ = conn.Close()
+			return
+		}
+		p.idle[key] = append(p.idle[key], conn)
+		p.lock.Unlock()
+		prometheus.AddICESocketPoolIdle(1)
+	}
+}
+
+// Close closes idle sockets, sockets drawn by peer connections are closed with them
+func (p *ICESocketPool) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	for key, conns := range p.idle {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		prometheus.AddICESocketPoolIdle(-len(conns))
+		delete(p.idle, key)
+	}
+	return nil
+}
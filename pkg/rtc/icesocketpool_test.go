@@ -0,0 +1,79 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pion/transport/v2/stdnet"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/testutils"
+)
+
+func TestICESocketPool(t *testing.T) {
+	n, err := stdnet.NewNet()
+	require.NoError(t, err)
+
+	pool := NewICESocketPool(n, 2)
+	laddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+	idle := func() int {
+		pool.lock.Lock()
+		defer pool.lock.Unlock()
+		return len(pool.idle["udp4/127.0.0.1"])
+	}
+
+	// first use of an address binds directly and starts filling its pool
+	conn, err := pool.ListenUDP("udp4", laddr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	testutils.WithTimeout(t, func() string {
+		if idle() != 2 {
+			return "pool did not fill"
+		}
+		return ""
+	}, 5*time.Second)
+
+	pool.lock.Lock()
+	pooled := pool.idle["udp4/127.0.0.1"][1]
+	pool.lock.Unlock()
+
+	drawn, err := pool.ListenUDP("udp4", laddr)
+	require.NoError(t, err)
+	require.Equal(t, pooled, drawn)
+
+	// topped up after the draw
+	testutils.WithTimeout(t, func() string {
+		if idle() != 2 {
+			return "pool was not topped up"
+		}
+		return ""
+	}, 5*time.Second)
+
+	// specific ports are not pooled
+	port := drawn.LocalAddr().(*net.UDPAddr).Port
+	require.NoError(t, drawn.Close())
+	conn, err = pool.ListenUDP("udp4", &net.UDPAddr{IP: laddr.IP, Port: port})
+	require.NoError(t, err)
+	require.Equal(t, port, conn.LocalAddr().(*net.UDPAddr).Port)
+	require.NoError(t, conn.Close())
+
+	require.NoError(t, pool.Close())
+	require.Equal(t, 0, idle())
+}
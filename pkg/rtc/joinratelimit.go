@@ -0,0 +1,95 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// retry after in milliseconds appended to ErrJoinRateLimited in error messages,
+// shared by encoding and decoding as ErrorResponse has no field for it
+const joinRateLimitedRetryAfterFormat = ", retry after %dms"
+
+// JoinRateLimitedError is returned when a participant joins a room above its join rate
+type JoinRateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *JoinRateLimitedError) Error() string {
+	return ErrJoinRateLimited.Error() + fmt.Sprintf(joinRateLimitedRetryAfterFormat, e.RetryAfter.Milliseconds())
+}
+
+func (e *JoinRateLimitedError) Unwrap() error {
+	return ErrJoinRateLimited
+}
+
+// ToErrorResponse encodes the error for the signal node handling the participant's connection
+func (e *JoinRateLimitedError) ToErrorResponse() *livekit.ErrorResponse {
+	return &livekit.ErrorResponse{
+		Reason:  livekit.ErrorResponse_LIMIT_EXCEEDED,
+		Message: e.Error(),
+	}
+}
+
+// JoinRateLimitedErrorFromErrorResponse decodes an error response created with ToErrorResponse
+func JoinRateLimitedErrorFromErrorResponse(res *livekit.ErrorResponse) (*JoinRateLimitedError, bool) {
+	if res.GetReason() != livekit.ErrorResponse_LIMIT_EXCEEDED {
+		return nil, false
+	}
+
+	retryAfter, ok := strings.CutPrefix(res.GetMessage(), ErrJoinRateLimited.Error())
+	if !ok {
+		return nil, false
+	}
+	var retryAfterMs int64
+	if n, err := fmt.Sscanf(retryAfter, joinRateLimitedRetryAfterFormat, &retryAfterMs); err != nil || n != 1 {
+		return nil, false
+	}
+
+	e := &JoinRateLimitedError{RetryAfter: time.Duration(retryAfterMs) * time.Millisecond}
+	if e.Error() != res.GetMessage() {
+		// trailing data, not created with ToErrorResponse
+		return nil, false
+	}
+	return e, true
+}
+
+func (r *Room) waitJoinRate(participant types.LocalParticipant) error {
+	// dependent participants are dispatched by the server, not part of reconnect storms
+	if r.joinRateLimiter == nil || participant.IsDependent() {
+		return nil
+	}
+
+	wait, ok := r.joinRateLimiter.Reserve(r.joinMaxWait)
+	if !ok {
+		participant.GetLogger().Infow("rejecting participant, join rate exceeded", "retryAfter", wait)
+		return &JoinRateLimitedError{RetryAfter: wait}
+	}
+	if wait > 0 {
+		participant.GetLogger().Debugw("queueing participant join", "wait", wait)
+		select {
+		case <-time.After(wait):
+		case <-r.closed:
+			return ErrRoomClosed
+		}
+	}
+	return nil
+}
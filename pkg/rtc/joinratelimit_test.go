@@ -0,0 +1,66 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestJoinRateLimitedErrorResponse(t *testing.T) {
+	err := &JoinRateLimitedError{RetryAfter: 1500 * time.Millisecond}
+	require.True(t, errors.Is(err, ErrJoinRateLimited))
+
+	for _, retryAfter := range []time.Duration{0, time.Millisecond, 1500 * time.Millisecond, time.Minute} {
+		res := (&JoinRateLimitedError{RetryAfter: retryAfter}).ToErrorResponse()
+		decoded, ok := JoinRateLimitedErrorFromErrorResponse(res)
+		require.True(t, ok, retryAfter)
+		require.Equal(t, retryAfter, decoded.RetryAfter)
+		require.Equal(t, res.Message, decoded.Error())
+	}
+
+	// retry after is carried in milliseconds
+	decoded, ok := JoinRateLimitedErrorFromErrorResponse((&JoinRateLimitedError{RetryAfter: 1500*time.Millisecond + 300*time.Microsecond}).ToErrorResponse())
+	require.True(t, ok)
+	require.Equal(t, 1500*time.Millisecond, decoded.RetryAfter)
+
+	for _, message := range []string{
+		"subscription limit exceeded",
+		ErrJoinRateLimited.Error(),
+		ErrJoinRateLimited.Error() + ", retry after soon",
+		ErrJoinRateLimited.Error() + ", retry after 1500ms, or never",
+	} {
+		_, ok = JoinRateLimitedErrorFromErrorResponse(&livekit.ErrorResponse{
+			Reason:  livekit.ErrorResponse_LIMIT_EXCEEDED,
+			Message: message,
+		})
+		require.False(t, ok, message)
+	}
+
+	// not a limit exceeded response
+	_, ok = JoinRateLimitedErrorFromErrorResponse(&livekit.ErrorResponse{
+		Reason:  livekit.ErrorResponse_UNKNOWN,
+		Message: err.Error(),
+	})
+	require.False(t, ok)
+
+	_, ok = JoinRateLimitedErrorFromErrorResponse(nil)
+	require.False(t, ok)
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DataRPCMethodSetLatencyBudgets is handled by the participant. A subscriber declares the latency it tolerates
+// on subscribed tracks with it, the payload is a comma separated list of <track sid>=<duration>,
+// for example "TR_abc=150ms,*=2s". "*" applies to tracks not listed, invalid entries are ignored,
+// an empty payload clears budgets. A tight budget (e.g. gaming) limits retransmissions to those which
+// can still arrive in time, a loose budget (e.g. broadcast) allows more retransmission attempts.
+// It needs the data_rpc client capability.
+const DataRPCMethodSetLatencyBudgets = "lk.set_latency_budgets"
+
+const latencyBudgetAllTracks = "*"
+
+type latencyBudgets struct {
+	budgets  map[livekit.TrackID]time.Duration
+	fallback time.Duration
+}
+
+func newLatencyBudgets(raw string) *latencyBudgets {
+	l := &latencyBudgets{}
+	for _, entry := range strings.Split(raw, ",") {
+		trackID, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		trackID = strings.TrimSpace(trackID)
+		budget, err := time.ParseDuration(strings.TrimSpace(value))
+		if trackID == "" || err != nil || budget < 0 {
+			continue
+		}
+
+		if trackID == latencyBudgetAllTracks {
+			l.fallback = budget
+			continue
+		}
+		if l.budgets == nil {
+			l.budgets = make(map[livekit.TrackID]time.Duration)
+		}
+		l.budgets[livekit.TrackID(trackID)] = budget
+	}
+	return l
+}
+
+func (l *latencyBudgets) budgetFor(trackID livekit.TrackID) time.Duration {
+	if budget, ok := l.budgets[trackID]; ok {
+		return budget
+	}
+	return l.fallback
+}
+
+// --------------------------------------
+
+func (p *ParticipantImpl) handleSetLatencyBudgetsRPC(_ context.Context, _ types.LocalParticipant, payload string) (string, error) {
+	p.clientSettingsLock.Lock()
+	defer p.clientSettingsLock.Unlock()
+
+	p.latencyBudgets.Store(newLatencyBudgets(payload))
+	p.applyLatencyBudgets()
+	return "", nil
+}
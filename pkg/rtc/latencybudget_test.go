@@ -0,0 +1,61 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyBudgets(t *testing.T) {
+	t.Run("no budgets", func(t *testing.T) {
+		for _, raw := range []string{"", " , ,", "TR_a", "TR_a=fast", "=1s", "TR_a=-1s"} {
+			l := newLatencyBudgets(raw)
+			require.Zero(t, l.budgetFor("TR_a"))
+		}
+	})
+
+	t.Run("budgets", func(t *testing.T) {
+		l := newLatencyBudgets(" TR_a = 150ms, TR_b=2s")
+		require.Equal(t, 150*time.Millisecond, l.budgetFor("TR_a"))
+		require.Equal(t, 2*time.Second, l.budgetFor("TR_b"))
+		require.Zero(t, l.budgetFor("TR_c"))
+	})
+
+	t.Run("all tracks", func(t *testing.T) {
+		l := newLatencyBudgets("*=2s,TR_a=150ms")
+		require.Equal(t, 150*time.Millisecond, l.budgetFor("TR_a"))
+		require.Equal(t, 2*time.Second, l.budgetFor("TR_c"))
+	})
+}
+
+func TestSetLatencyBudgetsRPC(t *testing.T) {
+	p := newParticipantForTest("test")
+	require.NotNil(t, p.dataRPC.handlers[DataRPCMethodSetLatencyBudgets])
+	require.Zero(t, p.getLatencyBudgets().budgetFor("TR_a"))
+
+	_, err := p.handleSetLatencyBudgetsRPC(context.Background(), p, "TR_a=150ms,*=2s")
+	require.NoError(t, err)
+	require.Equal(t, 150*time.Millisecond, p.getLatencyBudgets().budgetFor("TR_a"))
+	require.Equal(t, 2*time.Second, p.getLatencyBudgets().budgetFor("TR_b"))
+
+	// empty payload clears budgets
+	_, err = p.handleSetLatencyBudgetsRPC(context.Background(), p, "")
+	require.NoError(t, err)
+	require.Zero(t, p.getLatencyBudgets().budgetFor("TR_a"))
+}
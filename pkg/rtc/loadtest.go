@@ -0,0 +1,378 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/loadtest"
+)
+
+// LoadTestIdentityPrefix prefixes identities of synthetic publishers of a load test
+const LoadTestIdentityPrefix = "lk.loadtest_"
+
+const defaultLoadTestVideoBitrate = 500_000
+
+// A load test adds synthetic participants to a room, to test capacity of a node without external clients.
+// Synthetic publishers are virtual participants publishing a VP8 video and an Opus audio track each, fed by
+// looped frames. Their tracks are visible to everyone and can be subscribed to like the mixed audio track, they
+// are not subscribed to automatically. Synthetic subscribers are not visible, each one takes the tracks of all
+// publishers of the room, synthetic or not, on a down track that counts forwarded packets instead of sending them.
+// Subscribers take the lowest layer of simulcast tracks.
+type LoadTestParams struct {
+	Publishers  int
+	Subscribers int
+	// bitrate of synthetic video tracks in bps, defaults to 500 kbps
+	VideoBitrate int
+}
+
+type LoadTestStatus struct {
+	StartedAt        time.Time `json:"started_at"`
+	Publishers       int       `json:"publishers"`
+	Subscribers      int       `json:"subscribers"`
+	Subscriptions    int       `json:"subscriptions"`
+	PacketsForwarded uint64    `json:"packets_forwarded"`
+	BytesForwarded   uint64    `json:"bytes_forwarded"`
+}
+
+type loadTestPublisher struct {
+	participantID livekit.ParticipantID
+	identity      livekit.ParticipantIdentity
+	joinedAt      int64
+	version       atomic.Uint32
+	tracks        []*MediaTrack
+	receivers     []*loadtest.Receiver
+}
+
+func (p *loadTestPublisher) toProto(state livekit.ParticipantInfo_State) *livekit.ParticipantInfo {
+	pi := &livekit.ParticipantInfo{
+		Sid:      string(p.participantID),
+		Identity: string(p.identity),
+		Name:     string(p.identity),
+		State:    state,
+		JoinedAt: p.joinedAt,
+		Version:  p.version.Load(),
+		Kind:     livekit.ParticipantInfo_STANDARD,
+	}
+	if state != livekit.ParticipantInfo_DISCONNECTED {
+		for _, track := range p.tracks {
+			pi.Tracks = append(pi.Tracks, track.ToProto())
+		}
+	}
+	return pi
+}
+
+type loadTestSink struct {
+	sink     *loadtest.Sink
+	receiver sfu.TrackReceiver
+}
+
+type roomLoadTest struct {
+	params        LoadTestParams
+	startedAt     time.Time
+	publishers    []*loadTestPublisher
+	subscriberIDs []livekit.ParticipantID
+	// guarded by Room.lock
+	sinks map[livekit.TrackID][]loadTestSink
+}
+
+func IsLoadTestIdentity(identity livekit.ParticipantIdentity) bool {
+	return strings.HasPrefix(string(identity), LoadTestIdentityPrefix)
+}
+
+// StartLoadTest adds synthetic publishers and subscribers to the room
+func (r *Room) StartLoadTest(params LoadTestParams) (*LoadTestStatus, error) {
+	if params.VideoBitrate <= 0 {
+		params.VideoBitrate = defaultLoadTestVideoBitrate
+	}
+
+	r.lock.Lock()
+	if r.IsClosed() {
+		r.lock.Unlock()
+		return nil, ErrRoomClosed
+	}
+	if r.loadTest != nil {
+		r.lock.Unlock()
+		return nil, ErrLoadTestRunning
+	}
+
+	lt := &roomLoadTest{
+		params:    params,
+		startedAt: time.Now(),
+		sinks:     make(map[livekit.TrackID][]loadTestSink),
+	}
+	for i := 0; i < params.Publishers; i++ {
+		lt.publishers = append(lt.publishers, r.newLoadTestPublisher(i, params))
+	}
+	for i := 0; i < params.Subscribers; i++ {
+		lt.subscriberIDs = append(lt.subscriberIDs, livekit.ParticipantID(guid.New(utils.ParticipantPrefix)))
+	}
+	r.loadTest = lt
+
+	updates := make([]*participantUpdate, 0, len(lt.publishers))
+	for _, pub := range lt.publishers {
+		updates = append(updates, &participantUpdate{pi: pub.toProto(livekit.ParticipantInfo_ACTIVE)})
+	}
+	r.lock.Unlock()
+
+	for _, pub := range lt.publishers {
+		for i, track := range pub.tracks {
+			r.trackManager.AddTrack(track, pub.identity, pub.participantID)
+			pub.receivers[i].Start()
+		}
+	}
+
+	var tracks []types.MediaTrack
+	for _, p := range r.GetParticipants() {
+		tracks = append(tracks, p.GetPublishedTracks()...)
+	}
+	for _, pub := range lt.publishers {
+		for _, track := range pub.tracks {
+			tracks = append(tracks, track)
+		}
+	}
+	for _, track := range tracks {
+		r.addLoadTestSinks(lt, track)
+	}
+
+	r.Logger.Infow("load test started",
+		"publishers", params.Publishers,
+		"subscribers", params.Subscribers,
+		"videoBitrate", params.VideoBitrate,
+	)
+	r.sendParticipantUpdates(updates)
+	return r.GetLoadTestStatus()
+}
+
+func (r *Room) newLoadTestPublisher(index int, params LoadTestParams) *loadTestPublisher {
+	pub := &loadTestPublisher{
+		participantID: livekit.ParticipantID(guid.New(utils.ParticipantPrefix)),
+		identity:      livekit.ParticipantIdentity(fmt.Sprintf("%spub_%d", LoadTestIdentityPrefix, index)),
+		joinedAt:      time.Now().Unix(),
+	}
+
+	for _, ti := range []*livekit.TrackInfo{
+		{
+			Type:     livekit.TrackType_VIDEO,
+			Name:     "video",
+			Source:   livekit.TrackSource_CAMERA,
+			MimeType: "video/VP8",
+			Width:    640,
+			Height:   360,
+		},
+		{
+			Type:     livekit.TrackType_AUDIO,
+			Name:     "audio",
+			Source:   livekit.TrackSource_MICROPHONE,
+			MimeType: "audio/opus",
+		},
+	} {
+		trackID := livekit.TrackID(guid.New(utils.TrackPrefix))
+		ti.Sid = string(trackID)
+		logger := LoggerWithTrack(LoggerWithParticipant(r.Logger, pub.identity, pub.participantID, false), trackID, false)
+
+		track := NewMediaTrack(MediaTrackParams{
+			ParticipantID:       pub.participantID,
+			ParticipantIdentity: pub.identity,
+			ReceiverConfig:      r.config.Receiver,
+			SubscriberConfig:    r.config.Subscriber,
+			Telemetry:           r.telemetry,
+			Logger:              logger,
+		}, ti)
+		receiver := loadtest.NewReceiver(loadtest.ReceiverParams{
+			TrackID:      trackID,
+			StreamID:     string(pub.participantID),
+			Kind:         ti.Type,
+			VideoBitrate: params.VideoBitrate,
+			Logger:       logger,
+		}, ti)
+		track.SetupReceiver(receiver, 0, "")
+
+		pub.tracks = append(pub.tracks, track)
+		pub.receivers = append(pub.receivers, receiver)
+	}
+	return pub
+}
+
+// StopLoadTest removes synthetic publishers and subscribers from the room
+func (r *Room) StopLoadTest() error {
+	r.lock.Lock()
+	lt := r.loadTest
+	r.loadTest = nil
+	if lt == nil {
+		r.lock.Unlock()
+		return ErrLoadTestNotRunning
+	}
+
+	updates := make([]*participantUpdate, 0, len(lt.publishers))
+	for _, pub := range lt.publishers {
+		pub.version.Inc()
+		updates = append(updates, &participantUpdate{pi: pub.toProto(livekit.ParticipantInfo_DISCONNECTED)})
+	}
+	sinks := lt.sinks
+	lt.sinks = nil
+	r.lock.Unlock()
+
+	for _, trackSinks := range sinks {
+		for _, s := range trackSinks {
+			s.receiver.DeleteDownTrack(s.sink.SubscriberID())
+			s.sink.Close()
+		}
+	}
+	for _, pub := range lt.publishers {
+		for i, track := range pub.tracks {
+			pub.receivers[i].Close()
+			track.Close(false)
+			r.trackManager.RemoveTrack(track)
+		}
+	}
+	r.Logger.Infow("load test stopped", "duration", time.Since(lt.startedAt))
+
+	r.sendParticipantUpdates(updates)
+	return nil
+}
+
+// GetLoadTestStatus returns the status of the running load test
+func (r *Room) GetLoadTestStatus() (*LoadTestStatus, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	lt := r.loadTest
+	if lt == nil {
+		return nil, ErrLoadTestNotRunning
+	}
+
+	status := &LoadTestStatus{
+		StartedAt:   lt.startedAt,
+		Publishers:  len(lt.publishers),
+		Subscribers: len(lt.subscriberIDs),
+	}
+	for _, trackSinks := range lt.sinks {
+		for _, s := range trackSinks {
+			packets, bytes := s.sink.Stats()
+			status.Subscriptions++
+			status.PacketsForwarded += packets
+			status.BytesForwarded += bytes
+		}
+	}
+	return status, nil
+}
+
+// getLoadTestInfosLocked returns info of synthetic publishers, nil when no load test is running
+func (r *Room) getLoadTestInfosLocked() []*livekit.ParticipantInfo {
+	if r.loadTest == nil {
+		return nil
+	}
+
+	infos := make([]*livekit.ParticipantInfo, 0, len(r.loadTest.publishers))
+	for _, pub := range r.loadTest.publishers {
+		infos = append(infos, pub.toProto(livekit.ParticipantInfo_ACTIVE))
+	}
+	return infos
+}
+
+func (r *Room) isLoadTestTrack(publisherID livekit.ParticipantID) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.loadTest == nil {
+		return false
+	}
+	for _, pub := range r.loadTest.publishers {
+		if pub.participantID == publisherID {
+			return true
+		}
+	}
+	return false
+}
+
+// addLoadTestSinks subscribes synthetic subscribers to a track
+func (r *Room) addLoadTestSinks(lt *roomLoadTest, track types.MediaTrack) {
+	receivers := track.Receivers()
+	if len(receivers) == 0 || len(lt.subscriberIDs) == 0 {
+		return
+	}
+
+	receiver := receivers[0]
+	trackSinks := make([]loadTestSink, 0, len(lt.subscriberIDs))
+	for _, subscriberID := range lt.subscriberIDs {
+		sink := loadtest.NewSink(subscriberID, track.ID())
+		if err := receiver.AddDownTrack(&loadTestLayerFilter{Sink: sink}); err != nil {
+			r.Logger.Warnw("could not add load test subscriber", err, "trackID", track.ID())
+			return
+		}
+		trackSinks = append(trackSinks, loadTestSink{sink: sink, receiver: receiver})
+	}
+
+	r.lock.Lock()
+	if r.loadTest == lt {
+		lt.sinks[track.ID()] = trackSinks
+		trackSinks = nil
+	}
+	r.lock.Unlock()
+
+	// load test stopped meanwhile
+	for _, s := range trackSinks {
+		s.receiver.DeleteDownTrack(s.sink.SubscriberID())
+	}
+}
+
+func (r *Room) onLoadTestTrackPublished(track types.MediaTrack) {
+	r.lock.RLock()
+	lt := r.loadTest
+	r.lock.RUnlock()
+	if lt != nil {
+		r.addLoadTestSinks(lt, track)
+	}
+}
+
+func (r *Room) onLoadTestTrackUnpublished(track types.MediaTrack) {
+	r.lock.Lock()
+	var trackSinks []loadTestSink
+	if r.loadTest != nil {
+		trackSinks = r.loadTest.sinks[track.ID()]
+		delete(r.loadTest.sinks, track.ID())
+	}
+	r.lock.Unlock()
+
+	for _, s := range trackSinks {
+		s.sink.Close()
+	}
+}
+
+// --------------------------------------
+
+// loadTestLayerFilter passes the lowest layer to a sink, like a subscriber of a simulcast track forwarded one layer
+type loadTestLayerFilter struct {
+	*loadtest.Sink
+}
+
+func (f *loadTestLayerFilter) WriteRTP(p *buffer.ExtPacket, layer int32) error {
+	if layer > 0 {
+		return nil
+	}
+	return f.Sink.WriteRTP(p, layer)
+}
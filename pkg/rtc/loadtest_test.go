@@ -0,0 +1,63 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+func TestRoomLoadTest(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+	defer rm.Close(types.ParticipantCloseReasonNone)
+
+	_, err := rm.GetLoadTestStatus()
+	require.ErrorIs(t, err, ErrLoadTestNotRunning)
+
+	status, err := rm.StartLoadTest(LoadTestParams{Publishers: 2, Subscribers: 3, VideoBitrate: 200_000})
+	require.NoError(t, err)
+	require.Equal(t, 2, status.Publishers)
+	require.Equal(t, 3, status.Subscribers)
+	// video and audio of each synthetic publisher to each subscriber
+	require.Equal(t, 12, status.Subscriptions)
+
+	_, err = rm.StartLoadTest(LoadTestParams{Publishers: 1})
+	require.ErrorIs(t, err, ErrLoadTestRunning)
+
+	rm.lock.RLock()
+	infos := rm.getLoadTestInfosLocked()
+	rm.lock.RUnlock()
+	require.Len(t, infos, 2)
+	for _, pi := range infos {
+		require.True(t, IsLoadTestIdentity(livekit.ParticipantIdentity(pi.Identity)))
+		require.Len(t, pi.Tracks, 2)
+		require.True(t, rm.isLoadTestTrack(livekit.ParticipantID(pi.Sid)))
+	}
+
+	require.Eventually(t, func() bool {
+		status, err := rm.GetLoadTestStatus()
+		return err == nil && status.PacketsForwarded > 0 && status.BytesForwarded > 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, rm.StopLoadTest())
+	require.ErrorIs(t, rm.StopLoadTest(), ErrLoadTestNotRunning)
+	require.False(t, rm.isLoadTestTrack(livekit.ParticipantID(infos[0].Sid)))
+}
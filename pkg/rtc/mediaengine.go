@@ -35,6 +35,12 @@ var opusCodecCapability = webrtc.RTPCodecCapability{
 	Channels:    2,
 	SDPFmtpLine: "minptime=10;useinbandfec=1",
 }
+
+const (
+	opusPayloadType webrtc.PayloadType = 111
+	redPayloadType  webrtc.PayloadType = 63
+)
+
 var redCodecCapability = webrtc.RTPCodecCapability{
 	MimeType:    sfu.MimeTypeAudioRed,
 	ClockRate:   48000,
@@ -46,12 +52,12 @@ var videoRTX = webrtc.RTPCodecCapability{
 	ClockRate: 90000,
 }
 
-func registerCodecs(me *webrtc.MediaEngine, codecs []*livekit.Codec, rtcpFeedback RTCPFeedbackConfig, filterOutH264HighProfile bool) error {
+func registerCodecs(me *webrtc.MediaEngine, codecs []*livekit.Codec, rtcpFeedback RTCPFeedbackConfig, filterOutH264HighProfile bool, preferenceOrder bool) error {
 	opusCodec := opusCodecCapability
 	opusCodec.RTCPFeedback = rtcpFeedback.Audio
 	var opusPayload webrtc.PayloadType
 	if IsCodecEnabled(codecs, opusCodec) {
-		opusPayload = 111
+		opusPayload = opusPayloadType
 		if err := me.RegisterCodec(webrtc.RTPCodecParameters{
 			RTPCodecCapability: opusCodec,
 			PayloadType:        opusPayload,
@@ -62,7 +68,7 @@ func registerCodecs(me *webrtc.MediaEngine, codecs []*livekit.Codec, rtcpFeedbac
 		if IsCodecEnabled(codecs, redCodecCapability) {
 			if err := me.RegisterCodec(webrtc.RTPCodecParameters{
 				RTPCodecCapability: redCodecCapability,
-				PayloadType:        63,
+				PayloadType:        redPayloadType,
 			}, webrtc.RTPCodecTypeAudio); err != nil {
 				return err
 			}
@@ -72,7 +78,7 @@ func registerCodecs(me *webrtc.MediaEngine, codecs []*livekit.Codec, rtcpFeedbac
 	rtxEnabled := IsCodecEnabled(codecs, videoRTX)
 
 	h264HighProfileFmtp := "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=640032"
-	for _, codec := range []webrtc.RTPCodecParameters{
+	videoCodecs := []webrtc.RTPCodecParameters{
 		{
 			RTPCodecCapability: webrtc.RTPCodecCapability{
 				MimeType:     webrtc.MimeTypeVP8,
@@ -134,7 +140,14 @@ func registerCodecs(me *webrtc.MediaEngine, codecs []*livekit.Codec, rtcpFeedbac
 			},
 			PayloadType: 35,
 		},
-	} {
+	}
+	if preferenceOrder {
+		// payload types stay fixed, only the order of registration, i. e. the order codecs are offered in, follows preference
+		slices.SortStableFunc(videoCodecs, func(a, b webrtc.RTPCodecParameters) int {
+			return codecPreference(codecs, a.RTPCodecCapability) - codecPreference(codecs, b.RTPCodecCapability)
+		})
+	}
+	for _, codec := range videoCodecs {
 		if filterOutH264HighProfile && codec.RTPCodecCapability.SDPFmtpLine == h264HighProfileFmtp {
 			continue
 		}
@@ -178,9 +191,9 @@ func registerHeaderExtensions(me *webrtc.MediaEngine, rtpHeaderExtension RTPHead
 	return nil
 }
 
-func createMediaEngine(codecs []*livekit.Codec, config DirectionConfig, filterOutH264HighProfile bool) (*webrtc.MediaEngine, error) {
+func createMediaEngine(codecs []*livekit.Codec, config DirectionConfig, filterOutH264HighProfile bool, preferenceOrder bool) (*webrtc.MediaEngine, error) {
 	me := &webrtc.MediaEngine{}
-	if err := registerCodecs(me, codecs, config.RTCPFeedback, filterOutH264HighProfile); err != nil {
+	if err := registerCodecs(me, codecs, config.RTCPFeedback, filterOutH264HighProfile, preferenceOrder); err != nil {
 		return nil, err
 	}
 
@@ -203,6 +216,16 @@ func IsCodecEnabled(codecs []*livekit.Codec, cap webrtc.RTPCodecCapability) bool
 	return false
 }
 
+// codecPreference returns position of the first enabled codec matching cap, codecs are in order of preference
+func codecPreference(codecs []*livekit.Codec, cap webrtc.RTPCodecCapability) int {
+	for i, codec := range codecs {
+		if strings.EqualFold(codec.Mime, cap.MimeType) && (codec.FmtpLine == "" || strings.EqualFold(codec.FmtpLine, cap.SDPFmtpLine)) {
+			return i
+		}
+	}
+	return len(codecs)
+}
+
 func selectAlternativeVideoCodec(enabledCodecs []*livekit.Codec) string {
 	// sort these by compatibility, since we are looking for backups
 	if slices.ContainsFunc(enabledCodecs, func(c *livekit.Codec) bool {
@@ -38,3 +38,30 @@ func TestIsCodecEnabled(t *testing.T) {
 		require.False(t, IsCodecEnabled(enabledCodecs, webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8}))
 	})
 }
+
+func TestCodecPreference(t *testing.T) {
+	codecs := []*livekit.Codec{{Mime: "audio/opus"}, {Mime: "video/av1"}, {Mime: "video/vp8"}}
+	videoFormats := func(preferenceOrder bool) []string {
+		me, err := createMediaEngine(codecs, DirectionConfig{}, false, preferenceOrder)
+		require.NoError(t, err)
+
+		pc, err := webrtc.NewAPI(webrtc.WithMediaEngine(me)).NewPeerConnection(webrtc.Configuration{})
+		require.NoError(t, err)
+		defer pc.Close()
+
+		_, err = pc.AddTransceiverFromKind(webrtc.RTPCodecTypeVideo)
+		require.NoError(t, err)
+		offer, err := pc.CreateOffer(nil)
+		require.NoError(t, err)
+		parsed, err := offer.Unmarshal()
+		require.NoError(t, err)
+
+		require.Len(t, parsed.MediaDescriptions, 1)
+		return parsed.MediaDescriptions[0].MediaName.Formats
+	}
+
+	// AV1 is offered ahead of VP8 with payload types unchanged
+	require.Equal(t, []string{"35", "96"}, videoFormats(true))
+	// without preferences, codecs keep their default order
+	require.Equal(t, []string{"96", "35"}, videoFormats(false))
+}
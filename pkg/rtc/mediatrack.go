@@ -53,6 +53,9 @@ type MediaTrack struct {
 	lock sync.RWMutex
 
 	rttFromXR atomic.Bool
+
+	// stats handed over on migration, final stats are reported by the node the participant migrates to
+	rtpStatsMigrated atomic.Bool
 }
 
 type MediaTrackParams struct {
@@ -73,6 +76,10 @@ type MediaTrackParams struct {
 	OnRTCP                func([]rtcp.Packet)
 	ForwardStats          *sfu.ForwardStats
 	OnTrackEverSubscribed func(livekit.TrackID)
+	// stats at the node the participant migrated from, seeded into stats of matching layers
+	MigratedRTPStats []*types.MigrationPublishedRTPStats
+	// accumulates usage of receivers of this track, optional
+	Usage *usageTracker
 }
 
 func NewMediaTrack(params MediaTrackParams, ti *livekit.TrackInfo) *MediaTrack {
@@ -161,6 +168,73 @@ func (t *MediaTrack) NotifySubscriberNodeMaxQuality(nodeID livekit.NodeID, quali
 	}
 }
 
+// SetMaxPublishedQuality stops publisher from sending layers above given quality, no-op for non-video tracks.
+// Layers above the cap are also dropped by the receivers, so that the cap holds even when
+// dynacast is disabled or the publisher does not honour the subscribed quality update.
+func (t *MediaTrack) SetMaxPublishedQuality(quality livekit.VideoQuality) {
+	if t.dynacastManager != nil {
+		t.dynacastManager.SetMaxPublishedQuality(quality)
+		t.applyMaxPublishedQuality()
+	}
+}
+
+// SetMinPublishedQuality keeps publisher sending layers up to given quality even when no subscriber wants them,
+// e. g. to keep a screen share at full quality for recording. OFF removes the override. No-op for non-video tracks.
+func (t *MediaTrack) SetMinPublishedQuality(quality livekit.VideoQuality) {
+	if t.dynacastManager != nil {
+		t.dynacastManager.SetMinPublishedQuality(quality)
+	}
+}
+
+// OnSubscriberCountChange is called with number of local subscribers when it changes, no-op for non-video tracks
+func (t *MediaTrack) OnSubscriberCountChange(f func(trackID livekit.TrackID, numSubscribers int)) {
+	if t.dynacastManager == nil {
+		return
+	}
+
+	t.dynacastManager.OnSubscriberCountChange(func(numSubscribers int) {
+		if f != nil {
+			f(t.ID(), numSubscribers)
+		}
+	})
+}
+
+// DynacastState returns number of local subscribers and highest quality requested from publisher
+func (t *MediaTrack) DynacastState() (int, livekit.VideoQuality) {
+	if t.dynacastManager == nil {
+		return 0, livekit.VideoQuality_OFF
+	}
+
+	return t.dynacastManager.NumSubscribers(), t.dynacastManager.MaxCommittedQuality()
+}
+
+// SetUplinkLimitMaxQuality is used by uplink bitrate limiter to stop publisher from sending layers above given quality
+func (t *MediaTrack) SetUplinkLimitMaxQuality(quality livekit.VideoQuality) {
+	if t.dynacastManager != nil {
+		t.dynacastManager.SetUplinkLimitMaxQuality(quality)
+		t.applyMaxPublishedQuality()
+	}
+}
+
+func (t *MediaTrack) applyMaxPublishedQuality() {
+	for _, receiver := range t.MediaTrackReceiver.Receivers() {
+		if dr, ok := receiver.(*DummyReceiver); ok {
+			receiver = dr.Receiver()
+		}
+		if wr, ok := receiver.(*sfu.WebRTCReceiver); ok {
+			wr.SetMaxForwardedSpatialLayer(t.maxForwardedSpatialLayer())
+		}
+	}
+}
+
+func (t *MediaTrack) maxForwardedSpatialLayer() int32 {
+	quality := t.dynacastManager.MaxPublishedQuality()
+	if quality == livekit.VideoQuality_HIGH {
+		return buffer.DefaultMaxLayerSpatial
+	}
+	return buffer.VideoQualityToSpatialLayer(quality, t.MediaTrackReceiver.TrackInfo())
+}
+
 func (t *MediaTrack) SignalCid() string {
 	return t.params.SignalCid
 }
@@ -224,7 +298,7 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track *webrtc.Tra
 							nowNTP32 := uint32(nowNTP >> 16)
 							ntpDiff := nowNTP32 - dlrrReport.LastRR - dlrrReport.DLRR
 							rtt := uint32(math.Ceil(float64(ntpDiff) * 1000.0 / 65536.0))
-							buff.SetRTT(rtt)
+							buff.SetRTTFromXR(rtt)
 							t.rttFromXR.Store(true)
 							lastRR = dlrrReport.LastRR
 							break rttFromXR
@@ -286,8 +360,23 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track *webrtc.Tra
 			sfu.WithStreamTrackers(),
 			sfu.WithForwardStats(t.params.ForwardStats),
 			sfu.WithEverHasDownTrackAdded(t.handleReceiverEverAddDowntrack),
+			sfu.WithReplayBuffer(replayDurationForSource(t.params.VideoConfig.ReplayBuffer, ti.Source)),
+			sfu.WithConnectionQualityConfig(t.params.ReceiverConfig.ConnectionQuality),
+			sfu.WithLayerBitrate(t.params.VideoConfig.LayerBitrate),
 		)
+		if t.params.Usage != nil {
+			t.params.Usage.Add(
+				newWR,
+				t.ID(),
+				types.UsageDirectionUp,
+				func() string { return newWR.Codec().MimeType },
+				newWR.GetTrackStats,
+			)
+		}
 		newWR.OnCloseHandler(func() {
+			if t.params.Usage != nil {
+				t.params.Usage.Finalize(newWR)
+			}
 			t.MediaTrackReceiver.SetClosing()
 			t.MediaTrackReceiver.ClearReceiver(mime, false)
 			if t.MediaTrackReceiver.TryClose() {
@@ -305,6 +394,9 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track *webrtc.Tra
 
 			newWR.OnMaxLayerChange(t.onMaxLayerChange)
 		}
+		if t.dynacastManager != nil {
+			newWR.SetMaxForwardedSpatialLayer(t.maxForwardedSpatialLayer())
+		}
 		if t.PrimaryReceiver() == nil {
 			// primary codec published, set potential codecs
 			potentialCodecs := make([]webrtc.RTPCodecParameters, 0, len(ti.Codecs))
@@ -364,6 +456,12 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track *webrtc.Tra
 	t.MediaTrackReceiver.SetLayerSsrc(mime, track.RID(), uint32(track.SSRC()))
 
 	buff.Bind(receiver.GetParameters(), track.Codec().RTPCodecCapability, bitrates)
+	for _, ms := range t.params.MigratedRTPStats {
+		if ms.Layer == layer && strings.EqualFold(ms.MimeType, mime) {
+			buff.SeedRTPStats(ms.Stats)
+			break
+		}
+	}
 
 	// if subscriber request fps before fps calculated, update them after fps updated.
 	buff.OnFpsChanged(func() {
@@ -371,6 +469,9 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track *webrtc.Tra
 	})
 
 	buff.OnFinalRtpStats(func(stats *livekit.RTPStats) {
+		if t.rtpStatsMigrated.Load() {
+			return
+		}
 		t.params.Telemetry.TrackPublishRTPStats(
 			context.Background(),
 			t.params.ParticipantID,
@@ -383,6 +484,30 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track *webrtc.Tra
 	return newCodec
 }
 
+// GetMigrationRTPStats returns stats of each published layer to be handed over on migration
+func (t *MediaTrack) GetMigrationRTPStats() []*types.MigrationPublishedRTPStats {
+	t.rtpStatsMigrated.Store(true)
+
+	var migrationStats []*types.MigrationPublishedRTPStats
+	for _, r := range t.MediaTrackReceiver.loadReceivers() {
+		wr, ok := r.TrackReceiver.(*sfu.WebRTCReceiver)
+		if !ok {
+			continue
+		}
+
+		mime := wr.Codec().MimeType
+		for layer, stats := range wr.GetLayerTrackStats() {
+			migrationStats = append(migrationStats, &types.MigrationPublishedRTPStats{
+				TrackID:  t.ID(),
+				MimeType: mime,
+				Layer:    layer,
+				Stats:    stats,
+			})
+		}
+	}
+	return migrationStats
+}
+
 func (t *MediaTrack) GetConnectionScoreAndQuality() (float32, livekit.ConnectionQuality) {
 	receiver := t.PrimaryReceiver()
 	if rtcReceiver, ok := receiver.(*sfu.WebRTCReceiver); ok {
@@ -439,3 +564,14 @@ func (t *MediaTrack) handleReceiverEverAddDowntrack() {
 		go t.params.OnTrackEverSubscribed(t.ID())
 	}
 }
+
+func replayDurationForSource(replayBufferConfig config.ReplayBufferConfig, source livekit.TrackSource) time.Duration {
+	switch source {
+	case livekit.TrackSource_CAMERA:
+		return replayBufferConfig.Camera
+	case livekit.TrackSource_SCREEN_SHARE:
+		return replayBufferConfig.ScreenShare
+	default:
+		return 0
+	}
+}
@@ -718,6 +718,24 @@ func (t *MediaTrackReceiver) UpdateVideoTrack(update *livekit.UpdateLocalVideoTr
 	t.params.Telemetry.TrackPublishedUpdate(context.Background(), t.PublisherID(), clonedInfo)
 }
 
+func (t *MediaTrackReceiver) UpdateTrackName(name string) {
+	t.lock.Lock()
+	trackInfo := t.TrackInfo()
+	if trackInfo.Name == name {
+		t.lock.Unlock()
+		return
+	}
+
+	clonedInfo := proto.Clone(trackInfo).(*livekit.TrackInfo)
+	clonedInfo.Name = name
+	t.trackInfo.Store(clonedInfo)
+	t.lock.Unlock()
+
+	t.updateTrackInfoOfReceivers()
+
+	t.params.Telemetry.TrackPublishedUpdate(context.Background(), t.PublisherID(), clonedInfo)
+}
+
 func (t *MediaTrackReceiver) TrackInfo() *livekit.TrackInfo {
 	return t.trackInfo.Load()
 }
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pion/rtcp"
 	"github.com/pion/webrtc/v3"
@@ -105,15 +106,31 @@ func (t *MediaTrackSubscriptions) AddSubscriber(sub types.LocalParticipant, wr *
 
 	var rtcpFeedback []webrtc.RTCPFeedback
 	var maxTrack int
+	var blankFramesOnStall, freezeRecovery time.Duration
+	var frameIntegrity, blankFramesOnMute bool
+	var layerSelectionPolicy sfu.LayerSelectionPolicy
+	var packetMetadataCacheSize int
 	switch t.params.MediaTrack.Kind() {
 	case livekit.TrackType_AUDIO:
 		rtcpFeedback = t.params.SubscriberConfig.RTCPFeedback.Audio
 		maxTrack = t.params.ReceiverConfig.PacketBufferSizeAudio
+		blankFramesOnStall = t.params.ReceiverConfig.BlankFramesOnStall.Audio
+		packetMetadataCacheSize = t.params.ReceiverConfig.PacketMetadataCache.Audio
 	case livekit.TrackType_VIDEO:
 		rtcpFeedback = t.params.SubscriberConfig.RTCPFeedback.Video
 		maxTrack = t.params.ReceiverConfig.PacketBufferSizeVideo
+		blankFramesOnStall = t.params.ReceiverConfig.BlankFramesOnStall.Video
+		packetMetadataCacheSize = t.params.ReceiverConfig.PacketMetadataCache.Video
+		freezeRecovery = t.params.ReceiverConfig.FreezeRecovery
+		frameIntegrity = t.params.ReceiverConfig.FrameIntegrity
+		blankFramesOnMute = t.params.ReceiverConfig.BlankFramesOnMute
+		if t.params.MediaTrack.Source() == livekit.TrackSource_SCREEN_SHARE {
+			layerSelectionPolicy = sfu.LayerSelectionPolicyFromConfig(t.params.ReceiverConfig.LayerSelection.ScreenShare)
+		} else {
+			layerSelectionPolicy = sfu.LayerSelectionPolicyFromConfig(t.params.ReceiverConfig.LayerSelection.Camera)
+		}
 	}
-	codecs := wr.Codecs()
+	codecs := sortCodecsByPreference(wr.Codecs(), sub.GetPreferredCodecs())
 	for _, c := range codecs {
 		c.RTCPFeedback = rtcpFeedback
 	}
@@ -138,10 +155,21 @@ func (t *MediaTrackSubscriptions) AddSubscriber(sub types.LocalParticipant, wr *
 		MaxTrack:                       maxTrack,
 		PlayoutDelayLimit:              sub.GetPlayoutDelayConfig(),
 		Pacer:                          sub.GetPacer(),
+		RTXSSRCForPrimary:              sub.GetRTXSSRC,
 		Trailer:                        trailer,
 		Logger:                         LoggerWithTrack(sub.GetLogger().WithComponent(sutils.ComponentSub), trackID, t.params.IsRelayed),
 		RTCPWriter:                     sub.WriteSubscriberRTCP,
 		DisableSenderReportPassThrough: sub.GetDisableSenderReportPassThrough(),
+		RetransmitBufferSize:           t.params.ReceiverConfig.NackResponder.BufferSize,
+		RetransmitBudgetKbps:           t.params.ReceiverConfig.NackResponder.BudgetKbps,
+		ConnectionQualityConfig:        t.params.ReceiverConfig.ConnectionQuality,
+		BlankFramesOnStall:             blankFramesOnStall,
+		FreezeRecovery:                 freezeRecovery,
+		FrameIntegrity:                 frameIntegrity,
+		BlankFramesOnMute:              blankFramesOnMute,
+		LayerSelectionPolicy:           layerSelectionPolicy,
+		PacketMetadataCacheSize:        packetMetadataCacheSize,
+		PacketMetadataCacheMaxSize:     t.params.ReceiverConfig.PacketMetadataCache.MaxSize,
 	})
 	if err != nil {
 		return nil, err
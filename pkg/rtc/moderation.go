@@ -0,0 +1,69 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"github.com/livekit/protocol/livekit"
+)
+
+// MuteAllParticipants server mutes published tracks of given kinds of all participants,
+// except exempted identities. Publishers are signaled to mute like with MutePublishedTrack.
+// Returns the tracks which were muted.
+func (r *Room) MuteAllParticipants(kinds []livekit.TrackType, exemptions []livekit.ParticipantIdentity) []*livekit.TrackInfo {
+	exempt := make(map[livekit.ParticipantIdentity]bool, len(exemptions))
+	for _, identity := range exemptions {
+		exempt[identity] = true
+	}
+	matchesKind := func(kind livekit.TrackType) bool {
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	var muted []*livekit.TrackInfo
+	for _, p := range r.GetParticipants() {
+		if exempt[p.Identity()] || p.IsDependent() || p.IsClosed() {
+			continue
+		}
+
+		for _, track := range p.GetPublishedTracks() {
+			if track.IsMuted() || !matchesKind(track.Kind()) {
+				continue
+			}
+
+			if ti := p.SetTrackMuted(track.ID(), true, true); ti != nil {
+				muted = append(muted, ti)
+			}
+		}
+	}
+
+	r.Logger.Infow("muted all participants", "kinds", kinds, "exemptions", exemptions, "numMuted", len(muted))
+	return muted
+}
+
+// SetLocked locks the room, rejecting new participants. Participants already in the room,
+// including those resuming their session, are not affected.
+func (r *Room) SetLocked(locked bool) {
+	if r.locked.Swap(locked) != locked {
+		r.Logger.Infow("setting room locked", "locked", locked)
+	}
+}
+
+func (r *Room) IsLocked() bool {
+	return r.locked.Load()
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+const negotiationTraceSize = 64
+
+// NegotiationTraceEntry is a signaling event of a transport, as seen by its event loop
+type NegotiationTraceEntry struct {
+	At    time.Time
+	Event string
+	// negotiation state after the event
+	State string
+	// type and digest of the session description involved, if any
+	SDP    string `json:",omitempty"`
+	Detail string `json:",omitempty"`
+	Error  string `json:",omitempty"`
+}
+
+// negotiationTrace keeps the last signaling events of a transport, to debug glare and stuck negotiations
+type negotiationTrace struct {
+	lock    sync.Mutex
+	entries []NegotiationTraceEntry
+	next    int
+	full    bool
+}
+
+func newNegotiationTrace(size int) *negotiationTrace {
+	return &negotiationTrace{
+		entries: make([]NegotiationTraceEntry, size),
+	}
+}
+
+func (n *negotiationTrace) add(entry NegotiationTraceEntry) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.entries[n.next] = entry
+	n.next++
+	if n.next == len(n.entries) {
+		n.next = 0
+		n.full = true
+	}
+}
+
+// Entries returns traced events, oldest first
+func (n *negotiationTrace) Entries() []NegotiationTraceEntry {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if !n.full {
+		return append([]NegotiationTraceEntry(nil), n.entries[:n.next]...)
+	}
+	entries := make([]NegotiationTraceEntry, 0, len(n.entries))
+	entries = append(entries, n.entries[n.next:]...)
+	return append(entries, n.entries[:n.next]...)
+}
+
+// sdpDigest identifies a session description in traces without logging all of it
+func sdpDigest(sd *webrtc.SessionDescription) string {
+	if sd == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(sd.SDP))
+	return fmt.Sprintf("%s:%x", sd.Type, sum[:6])
+}
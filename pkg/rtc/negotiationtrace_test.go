@@ -0,0 +1,48 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiationTrace(t *testing.T) {
+	trace := newNegotiationTrace(3)
+	require.Empty(t, trace.Entries())
+
+	trace.add(NegotiationTraceEntry{Event: "0"})
+	trace.add(NegotiationTraceEntry{Event: "1"})
+	require.Equal(t, []NegotiationTraceEntry{{Event: "0"}, {Event: "1"}}, trace.Entries())
+
+	// oldest entries are overwritten, order is kept
+	for i := 2; i < 5; i++ {
+		trace.add(NegotiationTraceEntry{Event: fmt.Sprintf("%d", i)})
+	}
+	require.Equal(t, []NegotiationTraceEntry{{Event: "2"}, {Event: "3"}, {Event: "4"}}, trace.Entries())
+}
+
+func TestSDPDigest(t *testing.T) {
+	offer := &webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: "v=0\r\n"}
+	answer := &webrtc.SessionDescription{Type: webrtc.SDPTypeAnswer, SDP: "v=0\r\n"}
+
+	require.Empty(t, sdpDigest(nil))
+	require.Regexp(t, "^offer:[0-9a-f]{12}$", sdpDigest(offer))
+	require.Equal(t, sdpDigest(offer)[len("offer:"):], sdpDigest(answer)[len("answer:"):])
+	require.NotEqual(t, sdpDigest(offer), sdpDigest(&webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: "v=1\r\n"}))
+}
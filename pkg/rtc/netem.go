@@ -0,0 +1,72 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"io"
+	"slices"
+	"time"
+
+	"github.com/pion/transport/v2/packetio"
+
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
+)
+
+// wrapNetEmBufferFactory impairs packets received by a transport where SRTP writes them into
+// receive buffers, so that the SFU sees them as if they had crossed a degraded network
+func wrapNetEmBufferFactory(
+	emulator *netem.Emulator,
+	bufferFactory func(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser,
+) func(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser {
+	if bufferFactory == nil {
+		return nil
+	}
+
+	return func(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser {
+		rwc := bufferFactory(packetType, ssrc)
+		if rwc == nil || packetType != packetio.RTPBufferPacket {
+			return rwc
+		}
+		return &netEmBuffer{ReadWriteCloser: rwc, emulator: emulator}
+	}
+}
+
+// ------------------------------------------------------------
+
+type netEmBuffer struct {
+	io.ReadWriteCloser
+	emulator *netem.Emulator
+}
+
+func (b *netEmBuffer) Write(pkt []byte) (int, error) {
+	if !b.emulator.IsActive() {
+		return b.ReadWriteCloser.Write(pkt)
+	}
+
+	delay, ok := b.emulator.Admit(len(pkt))
+	if !ok {
+		return len(pkt), nil
+	}
+	if delay <= 0 {
+		return b.ReadWriteCloser.Write(pkt)
+	}
+
+	// SRTP reuses its decrypt buffer once Write returns
+	delayed := slices.Clone(pkt)
+	time.AfterFunc(delay, func() {
+		_, _ = b.ReadWriteCloser.Write(delayed)
+	})
+	return len(pkt), nil
+}
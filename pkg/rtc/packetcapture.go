@@ -0,0 +1,132 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"io"
+	"net/netip"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/transport/v2/packetio"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/pcap"
+)
+
+// packets of a transport are captured as UDP between fixed private addresses, RTCP is muxed on the same ports
+var packetCaptureFlows = map[livekit.SignalTarget]pcap.Flow{
+	livekit.SignalTarget_PUBLISHER: {
+		Local:  netip.MustParseAddrPort("10.0.0.1:50000"),
+		Remote: netip.MustParseAddrPort("10.0.0.2:50000"),
+	},
+	livekit.SignalTarget_SUBSCRIBER: {
+		Local:  netip.MustParseAddrPort("10.0.0.1:50001"),
+		Remote: netip.MustParseAddrPort("10.0.0.2:50001"),
+	},
+}
+
+// packetCaptureTap taps decrypted RTP/RTCP of a transport into a packet capture, when one is set.
+// Incoming packets are tapped where SRTP writes them into receive buffers, as they do not pass
+// through interceptors, outgoing packets are tapped by the innermost interceptor.
+type packetCaptureTap struct {
+	flow    pcap.Flow
+	capture atomic.Pointer[pcap.Capture]
+}
+
+func newPacketCaptureTap(target livekit.SignalTarget) *packetCaptureTap {
+	return &packetCaptureTap{
+		flow: packetCaptureFlows[target],
+	}
+}
+
+func (t *packetCaptureTap) setCapture(c *pcap.Capture) {
+	t.capture.Store(c)
+}
+
+func (t *packetCaptureTap) write(inbound bool, pkt []byte) {
+	if c := t.capture.Load(); c != nil {
+		c.Write(t.flow, inbound, pkt)
+	}
+}
+
+func (t *packetCaptureTap) isCapturing() bool {
+	c := t.capture.Load()
+	return c != nil && !c.IsClosed()
+}
+
+func (t *packetCaptureTap) wrapBufferFactory(
+	bufferFactory func(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser,
+) func(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser {
+	if bufferFactory == nil {
+		return nil
+	}
+
+	return func(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser {
+		rwc := bufferFactory(packetType, ssrc)
+		if rwc == nil {
+			return nil
+		}
+		return &packetCaptureBuffer{ReadWriteCloser: rwc, tap: t}
+	}
+}
+
+func (t *packetCaptureTap) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &packetCaptureInterceptor{tap: t}, nil
+}
+
+// ------------------------------------------------------------
+
+type packetCaptureBuffer struct {
+	io.ReadWriteCloser
+	tap *packetCaptureTap
+}
+
+func (b *packetCaptureBuffer) Write(pkt []byte) (int, error) {
+	b.tap.write(true, pkt)
+	return b.ReadWriteCloser.Write(pkt)
+}
+
+// ------------------------------------------------------------
+
+type packetCaptureInterceptor struct {
+	interceptor.NoOp
+	tap *packetCaptureTap
+}
+
+func (i *packetCaptureInterceptor) BindLocalStream(_ *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		if i.tap.isCapturing() {
+			if b, err := header.Marshal(); err == nil {
+				i.tap.write(false, append(b, payload...))
+			}
+		}
+		return writer.Write(header, payload, attributes)
+	})
+}
+
+func (i *packetCaptureInterceptor) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	return interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, attributes interceptor.Attributes) (int, error) {
+		if i.tap.isCapturing() {
+			if b, err := rtcp.Marshal(pkts); err == nil {
+				i.tap.write(false, b)
+			}
+		}
+		return writer.Write(pkts, attributes)
+	})
+}
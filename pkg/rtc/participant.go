@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -106,6 +108,7 @@ type ParticipantParams struct {
 	VideoConfig             config.VideoConfig
 	LimitConfig             config.LimitConfig
 	ProtocolVersion         types.ProtocolVersion
+	ClientCapabilities      types.ClientCapabilities
 	SessionStartTime        time.Time
 	Telemetry               telemetry.TelemetryService
 	Trailer                 []byte
@@ -114,6 +117,7 @@ type ParticipantParams struct {
 	// codecs that are enabled for this room
 	PublishEnabledCodecs           []*livekit.Codec
 	SubscribeEnabledCodecs         []*livekit.Codec
+	CodecPreferenceOrder           bool // enabled codecs are the room's codec preferences, video codecs are offered in their order
 	Logger                         logger.Logger
 	SimTracks                      map[uint32]SimulcastTrackInfo
 	Grants                         *auth.ClaimGrants
@@ -124,10 +128,12 @@ type ParticipantParams struct {
 	Migration                      bool
 	AdaptiveStream                 bool
 	AllowTCPFallback               bool
+	ICEConfig                      *livekit.ICEConfig
 	TCPFallbackRTTThreshold        int
 	AllowUDPUnstableFallback       bool
 	TURNSEnabled                   bool
 	GetParticipantInfo             func(pID livekit.ParticipantID) *livekit.ParticipantInfo
+	GetPermissionGroups            func(identity livekit.ParticipantIdentity) []string
 	GetRegionSettings              func(ip string) *livekit.RegionSettings
 	DisableSupervisor              bool
 	ReconnectOnPublicationError    bool
@@ -144,6 +150,11 @@ type ParticipantParams struct {
 	SyncStreams                    bool
 	ForwardStats                   *sfu.ForwardStats
 	DisableSenderReportPassThrough bool
+	MaxUplinkBitrate               int64
+	// state of a phantom participant of a restored room, taken over by this participant
+	Restore *ParticipantSnapshot
+	// span of the session start, parent of the transports' negotiation spans
+	TraceContext context.Context
 }
 
 type ParticipantImpl struct {
@@ -164,11 +175,28 @@ type ParticipantImpl struct {
 
 	grants      atomic.Pointer[auth.ClaimGrants]
 	isPublisher atomic.Bool
+	// permission groups from join token or server side updates, guarded by lock
+	permissionGroups []string
+
+	// set using DataRPCMethodSetDataTopics
+	dataTopicFilter atomic.Pointer[dataTopicFilter]
+	// set using DataRPCMethodSetLatencyBudgets
+	latencyBudgets atomic.Pointer[latencyBudgets]
+	// serializes updates of settings clients make using data RPC
+	clientSettingsLock sync.Mutex
+	// set using DataRPCMethodSetAudioOnly
+	audioOnly atomic.Bool
+	// set using DataRPCMethodSetPreferredCodecs
+	preferredCodecs atomic.Pointer[[]string]
 
 	sessionStartRecorded atomic.Bool
 	lastActiveAt         time.Time
 	// when first connected
 	connectedAt time.Time
+	// cumulative usage of published and subscribed tracks
+	usage *usageTracker
+	// load of the session on the node, sampled periodically
+	resources resourceSampler
 	// timer that's set when disconnect is detected on primary PC
 	disconnectTimer *time.Timer
 	migrationTimer  *time.Timer
@@ -182,6 +210,12 @@ type ParticipantImpl struct {
 	pendingTracksLock       utils.RWMutex
 	pendingTracks           map[string]*pendingTrackInfo
 	pendingPublishingTracks map[livekit.TrackID]*pendingTrackInfo
+	// tracks declared using DataRPCMethodSetPublishIntent, keeping their reserved track ID
+	publishIntents map[livekit.TrackSource]types.PublishIntent
+
+	// publisher -> tracks reserved on subscriber transport ahead of publishing
+	placeholderTracksLock sync.Mutex
+	placeholderTracks     map[livekit.ParticipantID][]livekit.TrackID
 
 	// supported codecs
 	enabledPublishCodecs   []*livekit.Codec
@@ -207,6 +241,8 @@ type ParticipantImpl struct {
 
 	dataChannelStats *telemetry.BytesTrackStats
 
+	uplinkBitrateLimiter *UplinkBitrateLimiter
+
 	rttUpdatedAt time.Time
 	lastRTT      uint32
 
@@ -224,14 +260,28 @@ type ParticipantImpl struct {
 	onParticipantUpdate  func(types.LocalParticipant)
 	onDataPacket         func(types.LocalParticipant, livekit.DataPacket_Kind, *livekit.DataPacket)
 
+	onDataSendQueueDrained func(types.LocalParticipant, livekit.DataPacket_Kind)
+	// id of the last data packet sent in fragments
+	dataMessageID atomic.Uint32
+	// fragmented data packets received, per channel
+	reliableDataReassembler *dataReassembler
+	lossyDataReassembler    *dataReassembler
+	// requests/responses on the RPC data topic
+	dataRPC *dataRPC
+
 	migrateState atomic.Value // types.MigrateState
 
-	onClose            func(types.LocalParticipant)
-	onClaimsChanged    func(participant types.LocalParticipant)
-	onICEConfigChanged func(participant types.LocalParticipant, iceConfig *livekit.ICEConfig)
+	onClose                 func(types.LocalParticipant)
+	onClaimsChanged         func(participant types.LocalParticipant)
+	onPublishIntentsChanged func(participant types.LocalParticipant)
+	onICEConfigChanged      func(participant types.LocalParticipant, iceConfig *livekit.ICEConfig)
 
 	cachedDownTracks map[livekit.TrackID]*downTrackState
 
+	// stats carried over from the node the participant migrated from, consumed as tracks are set up
+	migratedRTPStatsLock sync.Mutex
+	migratedRTPStats     *types.MigrationRTPStats
+
 	supervisor *supervisor.ParticipantSupervisor
 
 	tracksQuality map[livekit.TrackID]livekit.ConnectionQuality
@@ -261,32 +311,49 @@ func NewParticipant(params ParticipantParams) (*ParticipantImpl, error) {
 		}),
 		pendingTracks:           make(map[string]*pendingTrackInfo),
 		pendingPublishingTracks: make(map[livekit.TrackID]*pendingTrackInfo),
+		publishIntents:          make(map[livekit.TrackSource]types.PublishIntent),
+		placeholderTracks:       make(map[livekit.ParticipantID][]livekit.TrackID),
 		connectedAt:             time.Now(),
+		usage:                   newUsageTracker(),
 		rttUpdatedAt:            time.Now(),
 		cachedDownTracks:        make(map[livekit.TrackID]*downTrackState),
 		dataChannelStats: telemetry.NewBytesTrackStats(
 			telemetry.BytesTrackIDForParticipantID(telemetry.BytesTrackTypeData, params.SID),
 			params.SID,
 			params.Telemetry),
-		tracksQuality: make(map[livekit.TrackID]livekit.ConnectionQuality),
-		pubLogger:     params.Logger.WithComponent(sutils.ComponentPub),
-		subLogger:     params.Logger.WithComponent(sutils.ComponentSub),
+		tracksQuality:           make(map[livekit.TrackID]livekit.ConnectionQuality),
+		reliableDataReassembler: newDataReassembler(),
+		lossyDataReassembler:    newDataReassembler(),
+		pubLogger:               params.Logger.WithComponent(sutils.ComponentPub),
+		subLogger:               params.Logger.WithComponent(sutils.ComponentSub),
 	}
 	if !params.DisableSupervisor {
 		p.supervisor = supervisor.NewParticipantSupervisor(supervisor.ParticipantSupervisorParams{Logger: params.Logger})
 	}
+	p.dataRPC = newDataRPC(func(encoded []byte) error {
+		return p.SendDataPacket(livekit.DataPacket_RELIABLE, encoded)
+	}, params.Logger)
+	p.dataRPC.register(DataRPCMethodSetAudioOnly, p.handleSetAudioOnlyRPC)
+	p.dataRPC.register(DataRPCMethodSetLatencyBudgets, p.handleSetLatencyBudgetsRPC)
+	p.dataRPC.register(DataRPCMethodSetPublishIntent, p.handleSetPublishIntentRPC)
+	p.dataRPC.register(DataRPCMethodSetPreferredCodecs, p.handleSetPreferredCodecsRPC)
+	p.dataRPC.register(DataRPCMethodSetDataTopics, p.handleSetDataTopicsRPC)
 	p.closeReason.Store(types.ParticipantCloseReasonNone)
 	p.version.Store(params.InitialVersion)
 	p.timedVersion.Update(params.VersionGenerator.Next())
 	p.migrateState.Store(types.MigrateStateInit)
 	p.state.Store(livekit.ParticipantInfo_JOINING)
 	p.grants.Store(params.Grants)
+	p.permissionGroups = ParsePermissionGroups(params.Grants.Attributes)
 	p.SetResponseSink(params.Sink)
 	p.setupEnabledCodecs(params.PublishEnabledCodecs, params.SubscribeEnabledCodecs, params.ClientConf.GetDisabledCodecs())
 
 	if p.supervisor != nil {
 		p.supervisor.OnPublicationError(p.onPublicationError)
 	}
+	if params.Config != nil && params.Config.BufferFactory != nil {
+		params.Config.BufferFactory.OnSSRCCollision(p.onSSRCCollision)
+	}
 
 	var err error
 	// keep last participants and when updates were sent
@@ -302,6 +369,16 @@ func NewParticipant(params ParticipantParams) (*ParticipantImpl, error) {
 	p.setupUpTrackManager()
 	p.setupSubscriptionManager()
 
+	p.uplinkBitrateLimiter = NewUplinkBitrateLimiter(UplinkBitrateLimiterParams{
+		GetPublishedTracks: p.GetPublishedTracks,
+		WriteRTCP:          p.postRtcp,
+		Logger:             p.pubLogger,
+	})
+	p.uplinkBitrateLimiter.SetLimit(params.MaxUplinkBitrate)
+	if params.Restore != nil {
+		p.restore(params.Restore)
+	}
+
 	return p, nil
 }
 
@@ -323,6 +400,10 @@ func (p *ParticipantImpl) GetPacer() pacer.Pacer {
 	return p.TransportManager.GetSubscriberPacer()
 }
 
+func (p *ParticipantImpl) GetRTXSSRC(primarySSRC uint32) uint32 {
+	return p.TransportManager.GetSubscriberRTXSSRC(primarySSRC)
+}
+
 func (p *ParticipantImpl) GetDisableSenderReportPassThrough() bool {
 	return p.params.DisableSenderReportPassThrough
 }
@@ -362,6 +443,10 @@ func (p *ParticipantImpl) ProtocolVersion() types.ProtocolVersion {
 	return p.params.ProtocolVersion
 }
 
+func (p *ParticipantImpl) HasClientCapability(capability types.ClientCapability) bool {
+	return p.params.ClientCapabilities.Has(capability)
+}
+
 func (p *ParticipantImpl) IsReady() bool {
 	state := p.State()
 
@@ -410,6 +495,11 @@ func (p *ParticipantImpl) GetClientConfiguration() *livekit.ClientConfiguration
 	return p.params.ClientConf
 }
 
+func (p *ParticipantImpl) onSSRCCollision(ssrc uint32) {
+	p.pubLogger.Warnw("ssrc collision, remapping to a new buffer", nil, "ssrc", ssrc)
+	prometheus.IncrementSSRCCollision()
+}
+
 func (p *ParticipantImpl) GetBufferFactory() *buffer.Factory {
 	return p.params.Config.BufferFactory
 }
@@ -428,8 +518,12 @@ func (p *ParticipantImpl) CheckMetadataLimits(
 		return ErrMetadataExceedsLimits
 	}
 
-	if !p.params.LimitConfig.CheckAttributesSize(attributes) {
-		return ErrAttributesExceedsLimits
+	// attributes are partial updates, limits apply to the result of merging them into current attributes
+	if len(attributes) != 0 {
+		merged, _ := mergeAttributes(p.grants.Load().Attributes, attributes)
+		if !p.params.LimitConfig.CheckAttributesSize(merged) {
+			return ErrAttributesExceedsLimits
+		}
 	}
 
 	return nil
@@ -488,27 +582,22 @@ func (p *ParticipantImpl) SetMetadata(metadata string) {
 	}
 }
 
+// SetAttributes merges attrs into participant attributes, keys with an empty value are deleted.
+// Updates not changing any attribute are dropped, others broadcast the full participant info.
 func (p *ParticipantImpl) SetAttributes(attrs map[string]string) {
 	if len(attrs) == 0 {
 		return
 	}
 	p.lock.Lock()
-	grants := p.grants.Load().Clone()
-	if grants.Attributes == nil {
-		grants.Attributes = make(map[string]string)
-	}
-	var keysToDelete []string
-	for k, v := range attrs {
-		if v == "" {
-			keysToDelete = append(keysToDelete, k)
-		} else {
-			grants.Attributes[k] = v
-		}
-	}
-	for _, k := range keysToDelete {
-		delete(grants.Attributes, k)
+	grants := p.grants.Load()
+	merged, changed := mergeAttributes(grants.Attributes, attrs)
+	if !changed {
+		p.lock.Unlock()
+		return
 	}
 
+	grants = grants.Clone()
+	grants.Attributes = merged
 	p.grants.Store(grants)
 	p.requireBroadcast = true // already checked above
 	p.dirty.Store(true)
@@ -525,10 +614,207 @@ func (p *ParticipantImpl) SetAttributes(attrs map[string]string) {
 	}
 }
 
+// mergeAttributes returns a copy of current with updates applied, an empty value deletes the key
+func mergeAttributes(current, updates map[string]string) (map[string]string, bool) {
+	merged := make(map[string]string, len(current)+len(updates))
+	for k, v := range current {
+		merged[k] = v
+	}
+
+	changed := false
+	for k, v := range updates {
+		existing, ok := merged[k]
+		if v == "" {
+			if ok {
+				delete(merged, k)
+				changed = true
+			}
+			continue
+		}
+		if !ok || existing != v {
+			merged[k] = v
+			changed = true
+		}
+	}
+	return merged, changed
+}
+
 func (p *ParticipantImpl) ClaimGrants() *auth.ClaimGrants {
 	return p.grants.Load()
 }
 
+// PermissionGroups returns permission groups the participant is a member of
+func (p *ParticipantImpl) PermissionGroups() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.permissionGroups
+}
+
+// SetPermissionGroups replaces permission group membership, it should only be called
+// for server side updates. Returns true if membership changed.
+func (p *ParticipantImpl) SetPermissionGroups(groups []string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if slices.Equal(p.permissionGroups, groups) {
+		return false
+	}
+
+	p.permissionGroups = groups
+	return true
+}
+
+func (p *ParticipantImpl) getLatencyBudgets() *latencyBudgets {
+	if budgets := p.latencyBudgets.Load(); budgets != nil {
+		return budgets
+	}
+	return &latencyBudgets{}
+}
+
+// applyLatencyBudgets sets latency budgets declared using DataRPCMethodSetLatencyBudgets on subscribed tracks
+func (p *ParticipantImpl) applyLatencyBudgets() {
+	budgets := p.getLatencyBudgets()
+	for _, subTrack := range p.SubscriptionManager.GetSubscribedTracks() {
+		subTrack.DownTrack().SetLatencyBudget(budgets.budgetFor(subTrack.ID()))
+	}
+}
+
+// applyAudioOnly pauses or resumes subscribed video according to audio only mode
+func (p *ParticipantImpl) applyAudioOnly() {
+	audioOnly := p.audioOnly.Load()
+	p.params.Logger.Infow("updating audio only mode", "audioOnly", audioOnly)
+	for _, subTrack := range p.SubscriptionManager.GetSubscribedTracks() {
+		subTrack.SetAudioOnly(audioOnly)
+	}
+}
+
+// updatePublishIntents reserves track IDs for declared sources that are not published yet
+// and drops the ones no longer declared
+func (p *ParticipantImpl) updatePublishIntents(sources []livekit.TrackSource) {
+	published := make(map[livekit.TrackSource]bool)
+	for _, track := range p.GetPublishedTracks() {
+		published[track.Source()] = true
+	}
+
+	changed := false
+	declared := make(map[livekit.TrackSource]bool, len(sources))
+	p.pendingTracksLock.Lock()
+	for _, pti := range p.pendingTracks {
+		published[pti.trackInfos[0].Source] = true
+	}
+	for _, source := range sources {
+		if published[source] {
+			continue
+		}
+
+		declared[source] = true
+		if _, ok := p.publishIntents[source]; ok {
+			continue
+		}
+
+		trackType := trackTypeForSource(source)
+		p.publishIntents[source] = types.PublishIntent{
+			TrackID: livekit.TrackID(generateTrackID(trackType, source)),
+			Type:    trackType,
+			Source:  source,
+		}
+		changed = true
+	}
+	for source := range p.publishIntents {
+		if !declared[source] {
+			delete(p.publishIntents, source)
+			changed = true
+		}
+	}
+	p.pendingTracksLock.Unlock()
+
+	if !changed {
+		return
+	}
+
+	p.pubLogger.Debugw("updating publish intents", "intents", p.GetPublishIntents())
+	p.lock.RLock()
+	onPublishIntentsChanged := p.onPublishIntentsChanged
+	p.lock.RUnlock()
+	if onPublishIntentsChanged != nil {
+		onPublishIntentsChanged(p)
+	}
+}
+
+func (p *ParticipantImpl) GetPublishIntents() []types.PublishIntent {
+	p.pendingTracksLock.RLock()
+	defer p.pendingTracksLock.RUnlock()
+
+	intents := make([]types.PublishIntent, 0, len(p.publishIntents))
+	for _, intent := range p.publishIntents {
+		intents = append(intents, intent)
+	}
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].Source < intents[j].Source
+	})
+	return intents
+}
+
+// SetPlaceholderTracks reserves subscriber transceivers for tracks the publisher intends to publish,
+// so that negotiation is done before media flows, and releases reservations not declared anymore
+func (p *ParticipantImpl) SetPlaceholderTracks(publisherID livekit.ParticipantID, intents []types.PublishIntent) {
+	// stream ID of sync stream clients depends on the published track
+	if !p.CanSubscribe() || p.SupportsSyncStreamID() {
+		return
+	}
+
+	p.placeholderTracksLock.Lock()
+	existing := p.placeholderTracks[publisherID]
+	wanted := make(map[livekit.TrackID]bool, len(intents))
+	for _, intent := range intents {
+		wanted[intent.TrackID] = true
+	}
+
+	changed := false
+	reserved := make(map[livekit.TrackID]bool, len(existing))
+	var trackIDs []livekit.TrackID
+	for _, trackID := range existing {
+		if !wanted[trackID] {
+			if p.TransportManager.RemovePlaceholderTrackFromSubscriber(trackID) {
+				changed = true
+			}
+			continue
+		}
+		reserved[trackID] = true
+		trackIDs = append(trackIDs, trackID)
+	}
+
+	for _, intent := range intents {
+		if reserved[intent.TrackID] {
+			continue
+		}
+
+		streamID := string(publisherID)
+		if p.ProtocolVersion().SupportsPackedStreamId() {
+			streamID = PackStreamID(publisherID, intent.TrackID)
+		}
+		if err := p.TransportManager.AddPlaceholderTrackToSubscriber(newPlaceholderTrack(intent.TrackID, streamID, intent.Type)); err != nil {
+			p.subLogger.Warnw("could not add placeholder track", err, "publisherID", publisherID, "trackID", intent.TrackID)
+			continue
+		}
+		trackIDs = append(trackIDs, intent.TrackID)
+		changed = true
+	}
+
+	if len(trackIDs) == 0 {
+		delete(p.placeholderTracks, publisherID)
+	} else {
+		p.placeholderTracks[publisherID] = trackIDs
+	}
+	p.placeholderTracksLock.Unlock()
+
+	if changed {
+		p.subLogger.Debugw("updated placeholder tracks", "publisherID", publisherID, "trackIDs", trackIDs)
+		p.Negotiate(false)
+	}
+}
+
 func (p *ParticipantImpl) SetPermission(permission *livekit.ParticipantPermission) bool {
 	if permission == nil {
 		return false
@@ -558,12 +844,13 @@ func (p *ParticipantImpl) SetPermission(permission *livekit.ParticipantPermissio
 	p.requireBroadcast = p.requireBroadcast || isPublisher
 	p.lock.Unlock()
 
-	// publish permission has been revoked then remove offending tracks
+	// publish permission has been revoked then remove offending tracks, including ones yet to be published
 	for _, track := range p.GetPublishedTracks() {
 		if !grants.Video.GetCanPublishSource(track.Source()) {
 			p.removePublishedTrack(track)
 		}
 	}
+	p.removeDisallowedPendingTracks(grants.Video)
 
 	if canSubscribe {
 		// reconcile everything
@@ -736,6 +1023,14 @@ func (p *ParticipantImpl) OnDataPacket(callback func(types.LocalParticipant, liv
 	p.lock.Unlock()
 }
 
+// OnDataSendQueueDrained is called when data held due to a full data channel buffer has been sent,
+// senders can use it to resume after SendDataPacket fails with ErrDataChannelBufferFull
+func (p *ParticipantImpl) OnDataSendQueueDrained(callback func(types.LocalParticipant, livekit.DataPacket_Kind)) {
+	p.lock.Lock()
+	p.onDataSendQueueDrained = callback
+	p.lock.Unlock()
+}
+
 func (p *ParticipantImpl) OnClose(callback func(types.LocalParticipant)) {
 	p.lock.Lock()
 	p.onClose = callback
@@ -748,6 +1043,12 @@ func (p *ParticipantImpl) OnClaimsChanged(callback func(types.LocalParticipant))
 	p.lock.Unlock()
 }
 
+func (p *ParticipantImpl) OnPublishIntentsChanged(callback func(types.LocalParticipant)) {
+	p.lock.Lock()
+	p.onPublishIntentsChanged = callback
+	p.lock.Unlock()
+}
+
 func (p *ParticipantImpl) HandleSignalSourceClose() {
 	p.TransportManager.SetSignalSourceValid(false)
 
@@ -860,6 +1161,7 @@ func (p *ParticipantImpl) AddTrack(req *livekit.AddTrackRequest) {
 
 func (p *ParticipantImpl) SetMigrateInfo(
 	previousOffer, previousAnswer *webrtc.SessionDescription,
+	trackMappings []*types.TrackMapping,
 	mediaTracks []*livekit.TrackPublishedResponse,
 	dataChannels []*livekit.DataChannelInfo,
 ) {
@@ -881,7 +1183,64 @@ func (p *ParticipantImpl) SetMigrateInfo(
 		p.setIsPublisher(true)
 	}
 
-	p.TransportManager.SetMigrateInfo(previousOffer, previousAnswer, dataChannels)
+	p.TransportManager.SetMigrateInfo(previousOffer, previousAnswer, trackMappings, dataChannels)
+}
+
+// GetMigrationRTPStats returns stats of published and subscribed tracks to be handed over on migration,
+// final stats of those tracks are then reported by the node the participant migrates to
+func (p *ParticipantImpl) GetMigrationRTPStats() *types.MigrationRTPStats {
+	stats := &types.MigrationRTPStats{
+		Subscribed: p.SubscriptionManager.GetMigrationRTPStats(),
+	}
+	for _, t := range p.GetPublishedTracks() {
+		if mt, ok := t.(*MediaTrack); ok {
+			stats.Published = append(stats.Published, mt.GetMigrationRTPStats()...)
+		}
+	}
+	return stats
+}
+
+// SetMigrationRTPStats sets stats at the node the participant migrated from, to be seeded into
+// stats of tracks as they are published and subscribed again, must be called before SetMigrateInfo
+func (p *ParticipantImpl) SetMigrationRTPStats(stats *types.MigrationRTPStats) {
+	p.migratedRTPStatsLock.Lock()
+	defer p.migratedRTPStatsLock.Unlock()
+
+	p.migratedRTPStats = stats
+}
+
+func (p *ParticipantImpl) takeMigratedPublishedRTPStats(trackID livekit.TrackID) []*types.MigrationPublishedRTPStats {
+	p.migratedRTPStatsLock.Lock()
+	defer p.migratedRTPStatsLock.Unlock()
+
+	if p.migratedRTPStats == nil {
+		return nil
+	}
+
+	var taken []*types.MigrationPublishedRTPStats
+	remaining := p.migratedRTPStats.Published[:0]
+	for _, ms := range p.migratedRTPStats.Published {
+		if ms.TrackID == trackID {
+			taken = append(taken, ms)
+		} else {
+			remaining = append(remaining, ms)
+		}
+	}
+	p.migratedRTPStats.Published = remaining
+	return taken
+}
+
+func (p *ParticipantImpl) takeMigratedSubscribedRTPStats(trackID livekit.TrackID) *livekit.RTPStats {
+	p.migratedRTPStatsLock.Lock()
+	defer p.migratedRTPStatsLock.Unlock()
+
+	if p.migratedRTPStats == nil {
+		return nil
+	}
+
+	stats := p.migratedRTPStats.Subscribed[trackID]
+	delete(p.migratedRTPStats.Subscribed, trackID)
+	return stats
 }
 
 func (p *ParticipantImpl) Close(sendLeave bool, reason types.ParticipantCloseReason, isExpectedToResume bool) error {
@@ -935,9 +1294,15 @@ func (p *ParticipantImpl) Close(sendLeave bool, reason types.ParticipantCloseRea
 	}()
 
 	p.dataChannelStats.Stop()
+	p.uplinkBitrateLimiter.Stop()
 	return nil
 }
 
+// SetMaxUplinkBitrate limits total bitrate published by participant, 0 for no limit
+func (p *ParticipantImpl) SetMaxUplinkBitrate(bps int64) {
+	p.uplinkBitrateLimiter.SetLimit(bps)
+}
+
 func (p *ParticipantImpl) IsClosed() bool {
 	return p.isClosed.Load()
 }
@@ -1203,6 +1568,22 @@ func (p *ParticipantImpl) onTrackSubscribed(subTrack types.SubscribedTrack) {
 	if p.params.ClientInfo.FireTrackByRTPPacket() {
 		subTrack.DownTrack().SetActivePaddingOnMuteUpTrack()
 	}
+	subTrack.DownTrack().SetLatencyBudget(p.getLatencyBudgets().budgetFor(subTrack.ID()))
+	subTrack.SetAudioOnly(p.audioOnly.Load())
+	if stats := p.takeMigratedSubscribedRTPStats(subTrack.ID()); stats != nil {
+		subTrack.DownTrack().SeedRTPStats(stats)
+	}
+	subTrack.OnPriorityChange(func() {
+		p.TransportManager.UpdateSubscribedTrackPriority(subTrack)
+	})
+	dt := subTrack.DownTrack()
+	p.usage.Add(
+		dt,
+		subTrack.ID(),
+		types.UsageDirectionDown,
+		func() string { return dt.Codec().MimeType },
+		dt.GetTrackStats,
+	)
 
 	subTrack.AddOnBind(func(err error) {
 		if err != nil {
@@ -1218,6 +1599,27 @@ func (p *ParticipantImpl) onTrackSubscribed(subTrack types.SubscribedTrack) {
 // onTrackUnsubscribed handles post-processing after a track is unsubscribed
 func (p *ParticipantImpl) onTrackUnsubscribed(subTrack types.SubscribedTrack) {
 	p.TransportManager.RemoveSubscribedTrack(subTrack)
+	p.usage.Finalize(subTrack.DownTrack())
+}
+
+// GetUsage returns cumulative bandwidth usage of the participant on this node
+func (p *ParticipantImpl) GetUsage() *types.ParticipantUsage {
+	usage := &types.ParticipantUsage{
+		ParticipantID: p.ID(),
+		Identity:      p.Identity(),
+		JoinedAt:      p.ConnectedAt(),
+		Duration:      time.Since(p.ConnectedAt()).Seconds(),
+		Tracks:        p.usage.Tracks(),
+	}
+	for _, tu := range usage.Tracks {
+		switch tu.Direction {
+		case types.UsageDirectionUp:
+			usage.BytesUp += tu.Bytes
+		case types.UsageDirectionDown:
+			usage.BytesDown += tu.Bytes
+		}
+	}
+	return usage
 }
 
 func (p *ParticipantImpl) SubscriptionPermissionUpdate(publisherID livekit.ParticipantID, trackID livekit.TrackID, allowed bool) {
@@ -1272,6 +1674,10 @@ func (h AnyTransportHandler) OnICECandidate(c *webrtc.ICECandidate, target livek
 	return h.p.onICECandidate(c, target)
 }
 
+func (h AnyTransportHandler) OnDataSendQueueDrained(kind livekit.DataPacket_Kind, dropped int) {
+	h.p.handleDataSendQueueDrained(kind, dropped)
+}
+
 // ----------------------------------------------------------
 
 type PublisherTransportHandler struct {
@@ -1358,10 +1764,12 @@ func (p *ParticipantImpl) setupTransportManager() error {
 		CongestionControlConfig:      p.params.CongestionControlConfig,
 		EnabledPublishCodecs:         p.enabledPublishCodecs,
 		EnabledSubscribeCodecs:       p.enabledSubscribeCodecs,
+		CodecPreferenceOrder:         p.params.CodecPreferenceOrder,
 		SimTracks:                    p.params.SimTracks,
 		ClientInfo:                   p.params.ClientInfo,
 		Migration:                    p.params.Migration,
 		AllowTCPFallback:             p.params.AllowTCPFallback,
+		ICEConfig:                    p.params.ICEConfig,
 		TCPFallbackRTTThreshold:      p.params.TCPFallbackRTTThreshold,
 		AllowUDPUnstableFallback:     p.params.AllowUDPUnstableFallback,
 		TURNSEnabled:                 p.params.TURNSEnabled,
@@ -1370,6 +1778,7 @@ func (p *ParticipantImpl) setupTransportManager() error {
 		Logger:                       p.params.Logger.WithComponent(sutils.ComponentTransport),
 		PublisherHandler:             pth,
 		SubscriberHandler:            sth,
+		TraceContext:                 p.params.TraceContext,
 	}
 	if p.params.SyncStreams && p.params.PlayoutDelay.GetEnabled() && p.params.ClientInfo.isFirefox() {
 		// we will disable playout delay for Firefox if the user is expecting
@@ -1381,6 +1790,11 @@ func (p *ParticipantImpl) setupTransportManager() error {
 		return err
 	}
 
+	tm.OnNetworkTransition(func() {
+		// subscriber is restarted by the server, publisher restarts with the ICE restarting offer of the client
+		p.ICERestart(nil)
+	})
+
 	tm.OnICEConfigChanged(func(iceConfig *livekit.ICEConfig) {
 		p.lock.Lock()
 		onICEConfigChanged := p.onICEConfigChanged
@@ -1408,9 +1822,10 @@ func (p *ParticipantImpl) setupTransportManager() error {
 
 func (p *ParticipantImpl) setupUpTrackManager() {
 	p.UpTrackManager = NewUpTrackManager(UpTrackManagerParams{
-		SID:              p.params.SID,
-		Logger:           p.pubLogger,
-		VersionGenerator: p.params.VersionGenerator,
+		SID:                 p.params.SID,
+		Logger:              p.pubLogger,
+		VersionGenerator:    p.params.VersionGenerator,
+		GetPermissionGroups: p.params.GetPermissionGroups,
 	})
 
 	p.UpTrackManager.OnPublishedTrackUpdated(func(track types.MediaTrack) {
@@ -1499,6 +1914,39 @@ func (p *ParticipantImpl) removePublishedTrack(track types.MediaTrack) {
 	}
 }
 
+// removes pending tracks of sources the participant is not allowed to publish anymore,
+// client renegotiates the publisher transport on receiving unpublish
+func (p *ParticipantImpl) removeDisallowedPendingTracks(video *auth.VideoGrant) {
+	var removed []*livekit.TrackInfo
+	p.pendingTracksLock.Lock()
+	for cid, pti := range p.pendingTracks {
+		allowed := make([]*livekit.TrackInfo, 0, len(pti.trackInfos))
+		for _, ti := range pti.trackInfos {
+			if video.GetCanPublishSource(ti.Source) {
+				allowed = append(allowed, ti)
+			} else {
+				removed = append(removed, ti)
+			}
+		}
+		if len(allowed) == 0 {
+			delete(p.pendingTracks, cid)
+		} else {
+			pti.trackInfos = allowed
+		}
+	}
+	p.pendingTracksLock.Unlock()
+
+	for _, ti := range removed {
+		p.pubLogger.Infow("removing pending track, publish permission revoked", "trackID", ti.Sid, "source", ti.Source)
+		if p.supervisor != nil {
+			p.supervisor.RemovePublication(livekit.TrackID(ti.Sid))
+		}
+		if p.ProtocolVersion().SupportsUnpublish() {
+			p.sendTrackUnpublished(livekit.TrackID(ti.Sid))
+		}
+	}
+}
+
 // when a new remoteTrack is created, creates a Track and adds it to room
 func (p *ParticipantImpl) onMediaTrack(track *webrtc.TrackRemote, rtpReceiver *webrtc.RTPReceiver) {
 	if p.IsDisconnected() {
@@ -1546,12 +1994,28 @@ func (p *ParticipantImpl) onMediaTrack(track *webrtc.TrackRemote, rtpReceiver *w
 }
 
 func (p *ParticipantImpl) onDataMessage(kind livekit.DataPacket_Kind, data []byte) {
-	if p.IsDisconnected() || !p.CanPublishData() {
+	if p.IsDisconnected() {
 		return
 	}
 
 	p.dataChannelStats.AddBytes(uint64(len(data)), false)
 
+	if isDataFragment(data) {
+		reassembler := p.lossyDataReassembler
+		if kind == livekit.DataPacket_RELIABLE {
+			reassembler = p.reliableDataReassembler
+		}
+		encoded, err := reassembler.add(data)
+		if err != nil {
+			p.pubLogger.Warnw("could not reassemble data packet", err, "kind", kind)
+			return
+		}
+		if encoded == nil {
+			return
+		}
+		data = encoded
+	}
+
 	dp := &livekit.DataPacket{}
 	if err := proto.Unmarshal(data, dp); err != nil {
 		p.pubLogger.Warnw("could not parse data packet", err)
@@ -1561,6 +2025,18 @@ func (p *ParticipantImpl) onDataMessage(kind livekit.DataPacket_Kind, data []byt
 	// trust the channel that it came in as the source of truth
 	dp.Kind = kind
 
+	// RPC frames of clients supporting them are exchanged with the server only, they do not need data publish permission
+	if u := dp.GetUser(); u != nil && u.GetTopic() == DataRPCTopic && p.HasClientCapability(types.ClientCapabilityDataRPC) {
+		if kind == livekit.DataPacket_RELIABLE {
+			p.dataRPC.handle(p, u.Payload)
+		}
+		return
+	}
+
+	if !p.CanPublishData() {
+		return
+	}
+
 	if p.Hidden() {
 		dp.ParticipantIdentity = ""
 	} else {
@@ -1831,11 +2307,13 @@ func (p *ParticipantImpl) onSubscribedMaxQualityChange(
 		"qualities", subscribedQualities,
 		"max", maxSubscribedQualities,
 	)
-	return p.writeMessage(&livekit.SignalResponse{
+	err := p.writeMessage(&livekit.SignalResponse{
 		Message: &livekit.SignalResponse_SubscribedQualityUpdate{
 			SubscribedQualityUpdate: subscribedQualityUpdate,
 		},
 	})
+	p.sendDynacastState(trackID)
+	return err
 }
 
 func (p *ParticipantImpl) addPendingTrackLocked(req *livekit.AddTrackRequest) *livekit.TrackInfo {
@@ -2170,9 +2648,14 @@ func (p *ParticipantImpl) addMediaTrack(signalCid string, sdpCid string, ti *liv
 		OnRTCP:                p.postRtcp,
 		ForwardStats:          p.params.ForwardStats,
 		OnTrackEverSubscribed: p.sendTrackHasBeenSubscribed,
+		MigratedRTPStats:      p.takeMigratedPublishedRTPStats(livekit.TrackID(ti.Sid)),
+		Usage:                 p.usage,
 	}, ti)
 
 	mt.OnSubscribedMaxQualityChange(p.onSubscribedMaxQualityChange)
+	mt.OnSubscriberCountChange(func(trackID livekit.TrackID, _numSubscribers int) {
+		p.sendDynacastState(trackID)
+	})
 
 	// add to published and clean up pending
 	if p.supervisor != nil {
@@ -2350,29 +2833,41 @@ func (p *ParticipantImpl) setStableTrackID(cid string, info *livekit.TrackInfo)
 		}
 	}
 
+	// use the ID reserved by a declared publish intent
+	if trackID == "" {
+		if intent, ok := p.publishIntents[info.Source]; ok && intent.Type == info.Type {
+			trackID = string(intent.TrackID)
+			delete(p.publishIntents, info.Source)
+		}
+	}
+
 	// otherwise generate
 	if trackID == "" {
-		trackPrefix := utils.TrackPrefix
-		if info.Type == livekit.TrackType_VIDEO {
-			trackPrefix += "V"
-		} else if info.Type == livekit.TrackType_AUDIO {
-			trackPrefix += "A"
-		}
-		switch info.Source {
-		case livekit.TrackSource_CAMERA:
-			trackPrefix += "C"
-		case livekit.TrackSource_MICROPHONE:
-			trackPrefix += "M"
-		case livekit.TrackSource_SCREEN_SHARE:
-			trackPrefix += "S"
-		case livekit.TrackSource_SCREEN_SHARE_AUDIO:
-			trackPrefix += "s"
-		}
-		trackID = guid.New(trackPrefix)
+		trackID = generateTrackID(info.Type, info.Source)
 	}
 	info.Sid = trackID
 }
 
+func generateTrackID(trackType livekit.TrackType, source livekit.TrackSource) string {
+	trackPrefix := utils.TrackPrefix
+	if trackType == livekit.TrackType_VIDEO {
+		trackPrefix += "V"
+	} else if trackType == livekit.TrackType_AUDIO {
+		trackPrefix += "A"
+	}
+	switch source {
+	case livekit.TrackSource_CAMERA:
+		trackPrefix += "C"
+	case livekit.TrackSource_MICROPHONE:
+		trackPrefix += "M"
+	case livekit.TrackSource_SCREEN_SHARE:
+		trackPrefix += "S"
+	case livekit.TrackSource_SCREEN_SHARE_AUDIO:
+		trackPrefix += "s"
+	}
+	return guid.New(trackPrefix)
+}
+
 func (p *ParticipantImpl) getPublishedTrackBySignalCid(clientId string) types.MediaTrack {
 	for _, publishedTrack := range p.GetPublishedTracks() {
 		if publishedTrack.(types.LocalMediaTrack).SignalCid() == clientId {
@@ -2417,6 +2912,9 @@ func (p *ParticipantImpl) DebugInfo() map[string]interface{} {
 	info["PendingTracks"] = pendingTrackInfo
 
 	info["UpTrackManager"] = p.UpTrackManager.DebugInfo()
+	info["ICEStats"] = p.GetICEStats()
+	info["NegotiationTrace"] = p.GetNegotiationTraces()
+	info["Resources"] = p.resourcesDebugInfo()
 
 	return info
 }
@@ -2613,18 +3111,43 @@ func (p *ParticipantImpl) SendDataPacket(kind livekit.DataPacket_Kind, encoded [
 		return ErrDataChannelUnavailable
 	}
 
-	err := p.TransportManager.SendDataPacket(kind, encoded)
+	messages := [][]byte{encoded}
+	if len(encoded) > maxDataFragmentSize && p.HasClientCapability(types.ClientCapabilityDataFragmentation) {
+		messages = fragmentDataPacket(p.dataMessageID.Inc(), encoded)
+	}
+
+	var err error
+	sentBytes := 0
+	for _, msg := range messages {
+		if err = p.TransportManager.SendDataPacket(kind, msg); err != nil {
+			break
+		}
+		sentBytes += len(msg)
+	}
 	if err != nil {
 		if (errors.Is(err, sctp.ErrStreamClosed) || errors.Is(err, io.ErrClosedPipe)) && p.params.ReconnectOnDataChannelError {
 			p.params.Logger.Infow("issuing full reconnect on data channel error", "error", err)
 			p.IssueFullReconnect(types.ParticipantCloseReasonDataChannelError)
 		}
 	} else {
-		p.dataChannelStats.AddBytes(uint64(len(encoded)), true)
+		p.dataChannelStats.AddBytes(uint64(sentBytes), true)
 	}
 	return err
 }
 
+func (p *ParticipantImpl) handleDataSendQueueDrained(kind livekit.DataPacket_Kind, dropped int) {
+	if dropped != 0 {
+		p.subLogger.Infow("data send queue drained after dropping packets", "kind", kind, "dropped", dropped)
+	}
+
+	p.lock.RLock()
+	onDataSendQueueDrained := p.onDataSendQueueDrained
+	p.lock.RUnlock()
+	if onDataSendQueueDrained != nil {
+		onDataSendQueueDrained(p, kind)
+	}
+}
+
 func (p *ParticipantImpl) setupEnabledCodecs(publishEnabledCodecs []*livekit.Codec, subscribeEnabledCodecs []*livekit.Codec, disabledCodecs *livekit.DisabledCodecs) {
 	shouldDisable := func(c *livekit.Codec, disabled []*livekit.Codec) bool {
 		for _, disableCodec := range disabled {
@@ -2690,6 +3213,36 @@ func (p *ParticipantImpl) UpdateAudioTrack(update *livekit.UpdateLocalAudioTrack
 	return errors.New("could not find track")
 }
 
+// UpdateTrackName renames a published or pending track, others are notified through participant update
+func (p *ParticipantImpl) UpdateTrackName(trackID livekit.TrackID, name string) error {
+	if !p.params.LimitConfig.CheckParticipantNameLength(name) {
+		return ErrNameExceedsLimits
+	}
+
+	if track := p.UpTrackManager.UpdatePublishedTrackName(trackID, name); track != nil {
+		return nil
+	}
+
+	isPending := false
+	p.pendingTracksLock.RLock()
+	for _, pti := range p.pendingTracks {
+		for _, ti := range pti.trackInfos {
+			if livekit.TrackID(ti.Sid) == trackID {
+				isPending = true
+
+				ti.Name = name
+			}
+		}
+	}
+	p.pendingTracksLock.RUnlock()
+	if isPending {
+		return nil
+	}
+
+	p.pubLogger.Debugw("could not locate track", "trackID", trackID)
+	return errors.New("could not find track")
+}
+
 func (p *ParticipantImpl) UpdateVideoTrack(update *livekit.UpdateLocalVideoTrack) error {
 	if track := p.UpTrackManager.UpdatePublishedVideoTrack(update); track != nil {
 		return nil
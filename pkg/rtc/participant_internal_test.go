@@ -15,6 +15,7 @@
 package rtc
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -218,6 +219,43 @@ func TestTrackPublishing(t *testing.T) {
 		})
 		require.Equal(t, 1, sink.WriteMessageCallCount())
 	})
+
+	t.Run("should unpublish pending tracks when permission is revoked", func(t *testing.T) {
+		p := newParticipantForTestWithOpts("test", &participantOpts{protocolVersion: 15})
+		sink := p.params.Sink.(*routingfakes.FakeMessageSink)
+		p.AddTrack(&livekit.AddTrackRequest{
+			Cid:    "cid",
+			Name:   "webcam",
+			Source: livekit.TrackSource_CAMERA,
+			Type:   livekit.TrackType_VIDEO,
+		})
+		p.AddTrack(&livekit.AddTrackRequest{
+			Cid:    "cid2",
+			Name:   "mic",
+			Source: livekit.TrackSource_MICROPHONE,
+			Type:   livekit.TrackType_AUDIO,
+		})
+		require.Len(t, p.pendingTracks, 2)
+		cameraSid := p.pendingTracks["cid"].trackInfos[0].Sid
+
+		p.SetPermission(&livekit.ParticipantPermission{
+			CanPublish: true,
+			CanPublishSources: []livekit.TrackSource{
+				livekit.TrackSource_MICROPHONE,
+			},
+		})
+		require.Len(t, p.pendingTracks, 1)
+		require.NotNil(t, p.pendingTracks["cid2"])
+
+		var unpublished []string
+		for i := 0; i < sink.WriteMessageCallCount(); i++ {
+			res := sink.WriteMessageArgsForCall(i).(*livekit.SignalResponse)
+			if u, ok := res.Message.(*livekit.SignalResponse_TrackUnpublished); ok {
+				unpublished = append(unpublished, u.TrackUnpublished.TrackSid)
+			}
+		}
+		require.Equal(t, []string{cameraSid}, unpublished)
+	})
 }
 
 func TestOutOfOrderUpdates(t *testing.T) {
@@ -241,6 +279,34 @@ func TestOutOfOrderUpdates(t *testing.T) {
 	require.Equal(t, "second update", sent.GetUpdate().Participants[0].Metadata)
 }
 
+func TestSetAttributes(t *testing.T) {
+	t.Run("merges and deletes keys", func(t *testing.T) {
+		p := newParticipantForTest("test")
+		updates := 0
+		p.OnParticipantUpdate(func(types.LocalParticipant) { updates++ })
+
+		p.SetAttributes(map[string]string{"a": "1", "b": "2"})
+		p.SetAttributes(map[string]string{"b": "", "c": "3"})
+		require.Equal(t, map[string]string{"a": "1", "c": "3"}, p.ClaimGrants().Attributes)
+		require.Equal(t, 2, updates)
+
+		// no change, no update
+		p.SetAttributes(map[string]string{"a": "1", "d": ""})
+		require.Equal(t, 2, updates)
+	})
+
+	t.Run("limits apply to merged attributes", func(t *testing.T) {
+		p := newParticipantForTest("test")
+		p.params.LimitConfig.MaxAttributesSize = 10
+
+		p.SetAttributes(map[string]string{"a": "1234"})
+		require.NoError(t, p.CheckMetadataLimits("", "", map[string]string{"b": "1234"}))
+		require.ErrorIs(t, p.CheckMetadataLimits("", "", map[string]string{"b": "12345"}), ErrAttributesExceedsLimits)
+		// replacing a key only counts the new value
+		require.NoError(t, p.CheckMetadataLimits("", "", map[string]string{"a": "123456789"}))
+	})
+}
+
 // after disconnection, things should continue to function and not panic
 func TestDisconnectTiming(t *testing.T) {
 	t.Run("Negotiate doesn't panic after channel closed", func(t *testing.T) {
@@ -292,6 +358,38 @@ func TestMuteSetting(t *testing.T) {
 	})
 }
 
+func TestUpdateTrackName(t *testing.T) {
+	t.Run("renames pending track", func(t *testing.T) {
+		p := newParticipantForTest("test")
+		ti := &livekit.TrackInfo{Sid: "testTrack", Name: "webcam"}
+		p.pendingTracks["cid"] = &pendingTrackInfo{trackInfos: []*livekit.TrackInfo{ti}}
+
+		require.NoError(t, p.UpdateTrackName(livekit.TrackID(ti.Sid), "presenter"))
+		require.Equal(t, "presenter", ti.Name)
+	})
+
+	t.Run("renames published track", func(t *testing.T) {
+		p := newParticipantForTest("test")
+		track := &typesfakes.FakeMediaTrack{}
+		track.IDReturns("testTrack")
+		updated := false
+		p.OnTrackUpdated(func(_ types.LocalParticipant, _ types.MediaTrack) {
+			updated = true
+		})
+		p.UpTrackManager.AddPublishedTrack(track)
+
+		require.NoError(t, p.UpdateTrackName("testTrack", "presenter"))
+		require.Equal(t, 1, track.UpdateTrackNameCallCount())
+		require.Equal(t, "presenter", track.UpdateTrackNameArgsForCall(0))
+		require.True(t, updated)
+	})
+
+	t.Run("unknown track", func(t *testing.T) {
+		p := newParticipantForTest("test")
+		require.Error(t, p.UpdateTrackName("unknown", "presenter"))
+	})
+}
+
 func TestSubscriberAsPrimary(t *testing.T) {
 	t.Run("protocol 4 uses subs as primary", func(t *testing.T) {
 		p := newParticipantForTestWithOpts("test", &participantOpts{
@@ -403,6 +501,48 @@ func TestSetStableTrackID(t *testing.T) {
 	}
 }
 
+func TestPublishIntents(t *testing.T) {
+	p := newParticipantForTest("test")
+	numChanges := 0
+	p.OnPublishIntentsChanged(func(_ types.LocalParticipant) {
+		numChanges++
+	})
+
+	require.NotNil(t, p.dataRPC.handlers[DataRPCMethodSetPublishIntent])
+
+	_, err := p.handleSetPublishIntentRPC(context.Background(), p, "camera,microphone")
+	require.NoError(t, err)
+	intents := p.GetPublishIntents()
+	require.Len(t, intents, 2)
+	require.Equal(t, livekit.TrackSource_CAMERA, intents[0].Source)
+	require.Equal(t, livekit.TrackType_VIDEO, intents[0].Type)
+	require.Contains(t, string(intents[0].TrackID), "TR_VC")
+	require.Equal(t, livekit.TrackSource_MICROPHONE, intents[1].Source)
+	require.Equal(t, livekit.TrackType_AUDIO, intents[1].Type)
+	require.Equal(t, 1, numChanges)
+
+	// publishing a declared source uses the reserved track ID
+	ti := &livekit.TrackInfo{
+		Type:   livekit.TrackType_VIDEO,
+		Source: livekit.TrackSource_CAMERA,
+	}
+	p.setStableTrackID("cid", ti)
+	require.Equal(t, string(intents[0].TrackID), ti.Sid)
+	require.Equal(t, []types.PublishIntent{intents[1]}, p.GetPublishIntents())
+
+	// an empty declaration drops remaining intents
+	_, err = p.handleSetPublishIntentRPC(context.Background(), p, "")
+	require.NoError(t, err)
+	require.Empty(t, p.GetPublishIntents())
+	require.Equal(t, 2, numChanges)
+
+	// sources the participant cannot publish are rejected
+	p.grants.Load().Video.SetCanPublish(false)
+	_, err = p.handleSetPublishIntentRPC(context.Background(), p, "camera")
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	require.Empty(t, p.GetPublishIntents())
+}
+
 func TestDisableCodecs(t *testing.T) {
 	participant := newParticipantForTestWithOpts("123", &participantOpts{
 		publisher: false,
@@ -732,7 +872,7 @@ func newParticipantForTestWithOpts(identity livekit.ParticipantIdentity, opts *p
 	if err != nil {
 		panic(err)
 	}
-	ff := buffer.NewFactoryOfBufferFactory(500, 200)
+	ff := buffer.NewFactoryOfBufferFactory(500, 200, nil)
 	rtcConf.SetBufferFactory(ff.CreateBufferFactory())
 	grants := &auth.ClaimGrants{
 		Video: &auth.VideoGrant{},
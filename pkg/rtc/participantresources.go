@@ -0,0 +1,156 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// goroutines are accounted to a participant with a profiler label, it is inherited by goroutines started
+// from a labeled goroutine, i. e. transports, tracks and their workers started on behalf of the session
+const participantGoroutineLabel = "participant"
+
+// DoWithParticipantLabel runs fn with the goroutine labeled with the participant,
+// goroutines started from fn are accounted to the participant.
+func DoWithParticipantLabel(ctx context.Context, participantID livekit.ParticipantID, fn func(ctx context.Context)) {
+	pprof.Do(ctx, pprof.Labels(participantGoroutineLabel, string(participantID)), fn)
+}
+
+// LabelParticipantGoroutine labels the calling goroutine with the participant until the returned func is called,
+// for setting up a session on a goroutine not owned by the participant.
+func LabelParticipantGoroutine(ctx context.Context, participantID livekit.ParticipantID) func() {
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(participantGoroutineLabel, string(participantID))))
+	return func() {
+		pprof.SetGoroutineLabels(ctx)
+	}
+}
+
+// CountParticipantGoroutines counts live goroutines by participant from a goroutine profile of the process.
+func CountParticipantGoroutines() map[livekit.ParticipantID]int {
+	var b bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&b, 1); err != nil {
+		return nil
+	}
+	return parseParticipantGoroutines(&b)
+}
+
+// parseParticipantGoroutines parses a goroutine profile in text format, in which a stack is preceded
+// by a "<count> @ <pcs>" line and followed by a "# labels: {...}" line when it has labels.
+func parseParticipantGoroutines(r io.Reader) map[livekit.ParticipantID]int {
+	counts := make(map[livekit.ParticipantID]int)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	count := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "#") {
+			if n, _, ok := strings.Cut(line, " @ "); ok {
+				count, _ = strconv.Atoi(n)
+			}
+			continue
+		}
+
+		labels, ok := strings.CutPrefix(line, "# labels: ")
+		if !ok || count == 0 {
+			continue
+		}
+		var m map[string]string
+		if err := json.Unmarshal([]byte(labels), &m); err == nil {
+			if pID := m[participantGoroutineLabel]; pID != "" {
+				counts[livekit.ParticipantID(pID)] += count
+			}
+		}
+		count = 0
+	}
+	return counts
+}
+
+// ---------------------------------------------------------------
+
+type resourceSampler struct {
+	lock      sync.Mutex
+	packets   uint64
+	last      types.ParticipantResourceUsage
+	isSampled bool
+}
+
+func (r *resourceSampler) sample(usage types.ParticipantResourceUsage, packets uint64) types.ParticipantResourceUsage {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.isSampled && packets >= r.packets {
+		if elapsed := usage.SampledAt.Sub(r.last.SampledAt); elapsed > 0 {
+			usage.PacketsPerSec = float64(packets-r.packets) / elapsed.Seconds()
+		}
+	}
+	r.packets = packets
+	r.last = usage
+	r.isSampled = true
+	return usage
+}
+
+func (r *resourceSampler) getLast() (types.ParticipantResourceUsage, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.last, r.isSampled
+}
+
+// SampleResourceUsage samples load the session puts on the node: goroutines counted by the caller,
+// ops queued by the participant and its transports, and packet rate since the previous sample.
+func (p *ParticipantImpl) SampleResourceUsage(goroutines int) types.ParticipantResourceUsage {
+	var packets uint64
+	for _, tu := range p.usage.Tracks() {
+		packets += tu.Packets
+	}
+
+	return p.resources.sample(types.ParticipantResourceUsage{
+		ParticipantID: p.ID(),
+		Identity:      p.Identity(),
+		Goroutines:    goroutines,
+		QueuedOps:     p.queuedOps(),
+		SampledAt:     time.Now(),
+	}, packets)
+}
+
+func (p *ParticipantImpl) queuedOps() int {
+	return p.pubRTCPQueue.Len() + p.TransportManager.QueuedEvents()
+}
+
+func (p *ParticipantImpl) resourcesDebugInfo() map[string]interface{} {
+	info := map[string]interface{}{
+		"QueuedOps": p.queuedOps(),
+	}
+	if last, ok := p.resources.getLast(); ok {
+		info["Goroutines"] = last.Goroutines
+		info["PacketsPerSec"] = last.PacketsPerSec
+		info["SampledAt"] = last.SampledAt
+	}
+	return info
+}
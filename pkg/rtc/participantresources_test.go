@@ -0,0 +1,91 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+func TestParseParticipantGoroutines(t *testing.T) {
+	profile := `goroutine profile: total 9
+3 @ 0x43a1d6 0x44a2c5 0x4b5e2e
+# labels: {"participant":"PA_a"}
+#	0x4b5e2d	main.worker+0x2d	/src/main.go:10
+
+2 @ 0x43a1d6 0x44a2c5
+# labels: {"participant":"PA_b", "other":"x"}
+#	0x44a2c4	main.other+0x24	/src/main.go:20
+
+1 @ 0x43a1d6 0x44a2c5
+# labels: {"participant":"PA_a"}
+#	0x44a2c4	main.other+0x24	/src/main.go:20
+
+2 @ 0x43a1d6
+# labels: {"other":"x"}
+#	0x43a1d5	runtime.gopark+0xd5	/go/src/runtime/proc.go:398
+
+1 @ 0x43a1d6
+#	0x43a1d5	runtime.gopark+0xd5	/go/src/runtime/proc.go:398
+`
+	counts := parseParticipantGoroutines(strings.NewReader(profile))
+	require.Equal(t, map[livekit.ParticipantID]int{"PA_a": 4, "PA_b": 2}, counts)
+}
+
+func TestCountParticipantGoroutines(t *testing.T) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	DoWithParticipantLabel(context.Background(), "PA_test", func(context.Context) {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-done
+			}()
+		}
+	})
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	require.Equal(t, 3, CountParticipantGoroutines()["PA_test"])
+}
+
+func TestResourceSampler(t *testing.T) {
+	var r resourceSampler
+	_, ok := r.getLast()
+	require.False(t, ok)
+
+	now := time.Now()
+	usage := r.sample(types.ParticipantResourceUsage{Goroutines: 5, SampledAt: now}, 100)
+	require.Zero(t, usage.PacketsPerSec)
+
+	usage = r.sample(types.ParticipantResourceUsage{Goroutines: 6, SampledAt: now.Add(2 * time.Second)}, 500)
+	require.Equal(t, 200.0, usage.PacketsPerSec)
+
+	last, ok := r.getLast()
+	require.True(t, ok)
+	require.Equal(t, usage, last)
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// usage of at most this many participants that have left is kept per room,
+// the usage of those that left first is dropped beyond that
+const maxDepartedUsage = 1000
+
+type usageSource struct {
+	trackID   livekit.TrackID
+	direction string
+	getCodec  func() string
+	getStats  func() *livekit.RTPStats
+
+	// set once the source is closed, stats do not change after that
+	final *types.TrackUsage
+}
+
+func (s *usageSource) usage() *types.TrackUsage {
+	if s.final != nil {
+		return s.final
+	}
+
+	u := &types.TrackUsage{
+		TrackID:   s.trackID,
+		Direction: s.direction,
+		Codec:     strings.ToLower(s.getCodec()),
+	}
+	if stats := s.getStats(); stats != nil {
+		u.Bytes = stats.Bytes + stats.BytesDuplicate + stats.BytesPadding
+		u.Packets = uint64(stats.Packets) + uint64(stats.PacketsDuplicate) + uint64(stats.PacketsPadding)
+		u.Duration = stats.Duration
+	}
+	return u
+}
+
+// usageTracker accumulates usage of the receivers and down tracks of a participant.
+// Sources are keyed by the receiver/down track instance so that a track
+// re-published or re-subscribed adds up instead of replacing earlier usage.
+type usageTracker struct {
+	lock    sync.Mutex
+	sources map[any]*usageSource
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{
+		sources: make(map[any]*usageSource),
+	}
+}
+
+func (u *usageTracker) Add(key any, trackID livekit.TrackID, direction string, getCodec func() string, getStats func() *livekit.RTPStats) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if _, ok := u.sources[key]; ok {
+		return
+	}
+	u.sources[key] = &usageSource{
+		trackID:   trackID,
+		direction: direction,
+		getCodec:  getCodec,
+		getStats:  getStats,
+	}
+}
+
+// Finalize records final usage of a closed source and releases it
+func (u *usageTracker) Finalize(key any) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	s, ok := u.sources[key]
+	if !ok || s.final != nil {
+		return
+	}
+	s.final = s.usage()
+	s.getCodec = nil
+	s.getStats = nil
+}
+
+// Tracks returns usage aggregated by track, direction and codec
+func (u *usageTracker) Tracks() []*types.TrackUsage {
+	type usageKey struct {
+		trackID   livekit.TrackID
+		direction string
+		codec     string
+	}
+
+	u.lock.Lock()
+	aggregated := make(map[usageKey]*types.TrackUsage, len(u.sources))
+	for _, s := range u.sources {
+		su := s.usage()
+		key := usageKey{su.TrackID, su.Direction, su.Codec}
+		if existing := aggregated[key]; existing != nil {
+			existing.Bytes += su.Bytes
+			existing.Packets += su.Packets
+			existing.Duration += su.Duration
+		} else {
+			c := *su
+			aggregated[key] = &c
+		}
+	}
+	u.lock.Unlock()
+
+	tracks := make([]*types.TrackUsage, 0, len(aggregated))
+	for _, tu := range aggregated {
+		tracks = append(tracks, tu)
+	}
+	slices.SortFunc(tracks, func(a, b *types.TrackUsage) int {
+		if c := strings.Compare(string(a.TrackID), string(b.TrackID)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Direction, b.Direction); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Codec, b.Codec)
+	})
+	return tracks
+}
+
+// ---------------------------------------------------------------
+
+func (r *Room) recordDepartedUsage(p types.LocalParticipant) {
+	usage := p.GetUsage()
+	if usage == nil {
+		return
+	}
+
+	r.lock.Lock()
+	if _, ok := r.departedUsage[p.ID()]; !ok {
+		r.departedUsageOrder.PushBack(p.ID())
+	}
+	r.departedUsage[p.ID()] = usage
+	for r.departedUsageOrder.Len() > maxDepartedUsage {
+		delete(r.departedUsage, r.departedUsageOrder.PopFront())
+	}
+	r.lock.Unlock()
+}
+
+// ParticipantUsage returns usage of participants in the room and of the last maxDepartedUsage
+// participants that have left, ordered by join time.
+func (r *Room) ParticipantUsage() []*types.ParticipantUsage {
+	r.lock.RLock()
+	usageByID := make(map[livekit.ParticipantID]*types.ParticipantUsage, len(r.departedUsage)+len(r.participants))
+	for pID, usage := range r.departedUsage {
+		usageByID[pID] = usage
+	}
+	r.lock.RUnlock()
+
+	for _, p := range r.GetParticipants() {
+		if usage := p.GetUsage(); usage != nil {
+			usageByID[p.ID()] = usage
+		}
+	}
+
+	usages := make([]*types.ParticipantUsage, 0, len(usageByID))
+	for _, usage := range usageByID {
+		usages = append(usages, usage)
+	}
+	slices.SortFunc(usages, func(a, b *types.ParticipantUsage) int {
+		return a.JoinedAt.Compare(b.JoinedAt)
+	})
+	return usages
+}
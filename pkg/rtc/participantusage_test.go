@@ -0,0 +1,94 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestUsageTracker(t *testing.T) {
+	u := newUsageTracker()
+
+	stats := func(bytes uint64, packets uint32) func() *livekit.RTPStats {
+		return func() *livekit.RTPStats {
+			return &livekit.RTPStats{
+				Bytes:            bytes,
+				BytesDuplicate:   10,
+				BytesPadding:     5,
+				Packets:          packets,
+				PacketsDuplicate: 1,
+				Duration:         2,
+			}
+		}
+	}
+	opus := func() string { return "audio/opus" }
+	vp8 := func() string { return "video/VP8" }
+
+	// two receiver instances of the same track and codec add up, e.g. after a re-publish
+	first, second, third := &struct{ int }{1}, &struct{ int }{2}, &struct{ int }{3}
+	u.Add(first, "TR_a", types.UsageDirectionUp, opus, stats(100, 10))
+	u.Add(second, "TR_a", types.UsageDirectionUp, opus, stats(200, 20))
+	u.Add(third, "TR_v", types.UsageDirectionDown, vp8, stats(1000, 100))
+
+	tracks := u.Tracks()
+	require.Equal(t, []*types.TrackUsage{
+		{TrackID: "TR_a", Direction: types.UsageDirectionUp, Codec: "audio/opus", Bytes: 330, Packets: 32, Duration: 4},
+		{TrackID: "TR_v", Direction: types.UsageDirectionDown, Codec: "video/vp8", Bytes: 1015, Packets: 101, Duration: 2},
+	}, tracks)
+
+	// finalized sources keep final usage and release stats
+	u.Finalize(third)
+	require.Nil(t, u.sources[third].getStats)
+	require.Equal(t, uint64(1015), u.Tracks()[1].Bytes)
+}
+
+func TestDepartedUsageLimit(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 0})
+	defer rm.Close(types.ParticipantCloseReasonNone)
+
+	depart := func(i int) livekit.ParticipantID {
+		pID := livekit.ParticipantID(fmt.Sprintf("PA_%d", i))
+		p := &typesfakes.FakeLocalParticipant{}
+		p.IDReturns(pID)
+		p.GetUsageReturns(&types.ParticipantUsage{ParticipantID: pID, JoinedAt: time.Unix(int64(i), 0)})
+		rm.recordDepartedUsage(p)
+		return pID
+	}
+
+	first := depart(0)
+	second := depart(1)
+	for i := 2; i < maxDepartedUsage+2; i++ {
+		depart(i)
+	}
+
+	// usage of those that left first is dropped
+	require.Len(t, rm.departedUsage, maxDepartedUsage)
+	require.Equal(t, maxDepartedUsage, rm.departedUsageOrder.Len())
+	require.NotContains(t, rm.departedUsage, first)
+	require.NotContains(t, rm.departedUsage, second)
+
+	usages := rm.ParticipantUsage()
+	require.Len(t, usages, maxDepartedUsage)
+	require.Equal(t, livekit.ParticipantID("PA_2"), usages[0].ParticipantID)
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// PermissionGroupsAttribute is the participant attribute listing the permission groups
+// a participant belongs to, as a comma separated list.
+//
+// Membership is only taken from the attribute in the join token and from server side
+// participant updates (RoomService.UpdateParticipant). Participants cannot change it
+// with their own metadata updates, the attribute is dropped from those.
+const PermissionGroupsAttribute = "lk.permission_groups"
+
+// PermissionGroupSIDPrefix marks a TrackPermission as granted to a named group instead of a
+// single participant. A TrackPermission with an empty participant_identity and a participant_sid
+// of "PG_presenters" grants every participant in the "presenters" group.
+// Participant SIDs are assigned by the server with a different prefix, so a group reference
+// cannot match a participant. Membership is resolved when permission is checked, so changing
+// membership does not require updating subscription permissions of publishers.
+const PermissionGroupSIDPrefix = "PG_"
+
+// ParsePermissionGroups returns the permission groups declared in participant attributes
+func ParsePermissionGroups(attributes map[string]string) []string {
+	raw := attributes[PermissionGroupsAttribute]
+	if raw == "" {
+		return nil
+	}
+
+	var groups []string
+	for _, group := range strings.Split(raw, ",") {
+		group = strings.TrimSpace(group)
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// withoutPermissionGroups returns attributes without PermissionGroupsAttribute,
+// used to drop it from updates sent by participants
+func withoutPermissionGroups(attributes map[string]string) map[string]string {
+	if _, ok := attributes[PermissionGroupsAttribute]; !ok {
+		return attributes
+	}
+
+	filtered := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		if k != PermissionGroupsAttribute {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+func permissionGroupFromTrackPermission(trackPerms *livekit.TrackPermission) (string, bool) {
+	if trackPerms.ParticipantIdentity != "" || !strings.HasPrefix(trackPerms.ParticipantSid, PermissionGroupSIDPrefix) {
+		return "", false
+	}
+
+	group := strings.TrimPrefix(trackPerms.ParticipantSid, PermissionGroupSIDPrefix)
+	return group, group != ""
+}
+
+func hasPermissionGroups(subscriptionPermission *livekit.SubscriptionPermission) bool {
+	if subscriptionPermission == nil || subscriptionPermission.AllParticipants {
+		return false
+	}
+
+	for _, trackPerms := range subscriptionPermission.TrackPermissions {
+		if _, ok := permissionGroupFromTrackPermission(trackPerms); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPermissionGroups returns permission groups of a participant in the room
+func (r *Room) GetPermissionGroups(identity livekit.ParticipantIdentity) []string {
+	p := r.GetParticipant(identity)
+	if p == nil {
+		return nil
+	}
+
+	return p.PermissionGroups()
+}
+
+// RefreshPermissionGroups re-applies subscription permissions granted to groups after
+// group membership of participant p changed, granting or revoking its subscriptions
+func (r *Room) RefreshPermissionGroups(p types.LocalParticipant) {
+	for _, op := range r.GetParticipants() {
+		if op.ID() == p.ID() {
+			continue
+		}
+
+		subscriptionPermission, _ := op.SubscriptionPermission()
+		if !hasPermissionGroups(subscriptionPermission) {
+			continue
+		}
+
+		if err := r.UpdateSubscriptionPermission(op, subscriptionPermission); err != nil {
+			op.GetLogger().Warnw("could not refresh permission groups", err, "member", p.Identity())
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DataRPCMethodSetPreferredCodecs is handled by the participant. A subscriber orders the codecs it would
+// rather receive among those a track is published in with it, the payload is a comma separated list,
+// e.g. "vp8,h264" on a device which decodes AV1 in software only, an empty payload clears the order.
+// Codecs of multi-codec simulcast tracks are otherwise offered in the publisher's order and the subscriber
+// receives the first one it is able to decode. The order applies to subscriptions made after it is set.
+// It needs the data_rpc client capability.
+const DataRPCMethodSetPreferredCodecs = "lk.set_preferred_codecs"
+
+// parsePreferredCodecs returns lower case codec names, i.e. mime types without the "video/" or "audio/" prefix
+func parsePreferredCodecs(raw string) []string {
+	var codecs []string
+	for _, c := range strings.Split(raw, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if i := strings.IndexByte(c, '/'); i >= 0 {
+			c = c[i+1:]
+		}
+		if c != "" && !slices.Contains(codecs, c) {
+			codecs = append(codecs, c)
+		}
+	}
+	return codecs
+}
+
+// sortCodecsByPreference moves preferred codecs to the front in order of preference,
+// keeping the relative order of the others
+func sortCodecsByPreference(codecs []webrtc.RTPCodecParameters, preferred []string) []webrtc.RTPCodecParameters {
+	if len(preferred) == 0 || len(codecs) < 2 {
+		return codecs
+	}
+
+	rank := func(c webrtc.RTPCodecParameters) int {
+		_, name, _ := strings.Cut(strings.ToLower(c.MimeType), "/")
+		if i := slices.Index(preferred, name); i >= 0 {
+			return i
+		}
+		return len(preferred)
+	}
+	sorted := slices.Clone(codecs)
+	slices.SortStableFunc(sorted, func(a, b webrtc.RTPCodecParameters) int {
+		return rank(a) - rank(b)
+	})
+	return sorted
+}
+
+// --------------------------------------
+
+func (p *ParticipantImpl) handleSetPreferredCodecsRPC(_ context.Context, _ types.LocalParticipant, payload string) (string, error) {
+	codecs := parsePreferredCodecs(payload)
+	p.preferredCodecs.Store(&codecs)
+	return "", nil
+}
+
+func (p *ParticipantImpl) GetPreferredCodecs() []string {
+	if codecs := p.preferredCodecs.Load(); codecs != nil {
+		return *codecs
+	}
+	return nil
+}
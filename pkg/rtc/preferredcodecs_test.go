@@ -0,0 +1,72 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreferredCodecs(t *testing.T) {
+	require.Empty(t, parsePreferredCodecs(""))
+	require.Equal(t, []string{"vp8", "h264"}, parsePreferredCodecs(" VP8, video/h264 ,,vp8"))
+}
+
+func TestSortCodecsByPreference(t *testing.T) {
+	codec := func(mime string) webrtc.RTPCodecParameters {
+		return webrtc.RTPCodecParameters{RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: mime}}
+	}
+	mimes := func(codecs []webrtc.RTPCodecParameters) []string {
+		var m []string
+		for _, c := range codecs {
+			m = append(m, c.MimeType)
+		}
+		return m
+	}
+	codecs := []webrtc.RTPCodecParameters{
+		codec(webrtc.MimeTypeAV1),
+		codec(webrtc.MimeTypeVP9),
+		codec(webrtc.MimeTypeVP8),
+	}
+
+	t.Run("no preference keeps publisher order", func(t *testing.T) {
+		require.Equal(t, mimes(codecs), mimes(sortCodecsByPreference(codecs, nil)))
+	})
+
+	t.Run("preferred codecs first", func(t *testing.T) {
+		sorted := sortCodecsByPreference(codecs, []string{"vp8", "h264"})
+		require.Equal(t, []string{webrtc.MimeTypeVP8, webrtc.MimeTypeAV1, webrtc.MimeTypeVP9}, mimes(sorted))
+		// input is not modified
+		require.Equal(t, webrtc.MimeTypeAV1, codecs[0].MimeType)
+	})
+}
+
+func TestSetPreferredCodecsRPC(t *testing.T) {
+	p := newParticipantForTest("test")
+	require.NotNil(t, p.dataRPC.handlers[DataRPCMethodSetPreferredCodecs])
+	require.Empty(t, p.GetPreferredCodecs())
+
+	_, err := p.handleSetPreferredCodecsRPC(context.Background(), p, "video/VP8, h264")
+	require.NoError(t, err)
+	require.Equal(t, []string{"vp8", "h264"}, p.GetPreferredCodecs())
+
+	// empty payload clears the order
+	_, err = p.handleSetPreferredCodecsRPC(context.Background(), p, "")
+	require.NoError(t, err)
+	require.Empty(t, p.GetPreferredCodecs())
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pion/webrtc/v3"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DataRPCMethodSetPublishIntent is handled by the participant. A participant declares the sources it is
+// about to publish with it, the payload is a comma separated list, e.g. "camera,microphone", an empty
+// payload drops earlier declarations. Track IDs are reserved for declared sources and subscribers set up
+// placeholder transceivers for them, so that negotiation is complete by the time the publisher starts
+// sending media. Declaring a source needs permission to publish it and the data_rpc client capability.
+const DataRPCMethodSetPublishIntent = "lk.set_publish_intent"
+
+func parsePublishIntentSources(raw string) []livekit.TrackSource {
+	var sources []livekit.TrackSource
+	for _, s := range strings.Split(raw, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		source, ok := livekit.TrackSource_value[strings.ToUpper(s)]
+		if !ok || livekit.TrackSource(source) == livekit.TrackSource_UNKNOWN {
+			continue
+		}
+		sources = append(sources, livekit.TrackSource(source))
+	}
+	return sources
+}
+
+func trackTypeForSource(source livekit.TrackSource) livekit.TrackType {
+	switch source {
+	case livekit.TrackSource_MICROPHONE, livekit.TrackSource_SCREEN_SHARE_AUDIO:
+		return livekit.TrackType_AUDIO
+	default:
+		return livekit.TrackType_VIDEO
+	}
+}
+
+func (p *ParticipantImpl) handleSetPublishIntentRPC(_ context.Context, _ types.LocalParticipant, payload string) (string, error) {
+	sources := parsePublishIntentSources(payload)
+	for _, source := range sources {
+		if !p.CanPublishSource(source) {
+			return "", ErrPermissionDenied
+		}
+	}
+
+	p.clientSettingsLock.Lock()
+	defer p.clientSettingsLock.Unlock()
+
+	p.updatePublishIntents(sources)
+	return "", nil
+}
+
+// --------------------------------------------
+
+// placeholderTrack holds a subscriber transceiver for a track that has not been published yet,
+// it never sends any media and is replaced by the down track once the track is subscribed
+type placeholderTrack struct {
+	trackID  livekit.TrackID
+	streamID string
+	kind     webrtc.RTPCodecType
+}
+
+func newPlaceholderTrack(trackID livekit.TrackID, streamID string, trackType livekit.TrackType) *placeholderTrack {
+	kind := webrtc.RTPCodecTypeVideo
+	if trackType == livekit.TrackType_AUDIO {
+		kind = webrtc.RTPCodecTypeAudio
+	}
+	return &placeholderTrack{
+		trackID:  trackID,
+		streamID: streamID,
+		kind:     kind,
+	}
+}
+
+func (t *placeholderTrack) Bind(ctx webrtc.TrackLocalContext) (webrtc.RTPCodecParameters, error) {
+	codecs := ctx.CodecParameters()
+	if len(codecs) == 0 {
+		return webrtc.RTPCodecParameters{}, webrtc.ErrUnsupportedCodec
+	}
+	return codecs[0], nil
+}
+
+func (t *placeholderTrack) Unbind(_ webrtc.TrackLocalContext) error {
+	return nil
+}
+
+func (t *placeholderTrack) ID() string {
+	return string(t.trackID)
+}
+
+func (t *placeholderTrack) RID() string {
+	return ""
+}
+
+func (t *placeholderTrack) StreamID() string {
+	return t.streamID
+}
+
+func (t *placeholderTrack) Kind() webrtc.RTPCodecType {
+	return t.kind
+}
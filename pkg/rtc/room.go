@@ -26,6 +26,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gammazero/deque"
 	"go.uber.org/atomic"
 	"golang.org/x/exp/maps"
 	"google.golang.org/protobuf/proto"
@@ -34,6 +35,7 @@ import (
 
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/tracer"
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/utils/guid"
 
@@ -43,8 +45,11 @@ import (
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
+	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/tracing"
 	sutils "github.com/livekit/livekit-server/pkg/utils"
 )
 
@@ -56,6 +61,8 @@ const (
 	dataForwardLoadBalanceThreshold = 20
 
 	simulateDisconnectSignalTimeout = 5 * time.Second
+
+	egressBitrateShareInterval = 5 * time.Second
 )
 
 var (
@@ -106,13 +113,19 @@ type Room struct {
 	protoProxy *utils.ProtoProxy[*livekit.Room]
 	Logger     logger.Logger
 
-	config          WebRTCConfig
-	audioConfig     *config.AudioConfig
-	serverInfo      *livekit.ServerInfo
-	telemetry       telemetry.TelemetryService
-	egressLauncher  EgressLauncher
-	trackManager    *RoomTrackManager
-	agentDispatches []*livekit.AgentDispatch
+	config      WebRTCConfig
+	audioConfig *config.AudioConfig
+	// aggregate bitrate forwarded to subscribers, shared among their stream allocators
+	maxEgressBitrate          atomic.Int64
+	egressBitrateShareTrigger chan struct{}
+	dataAudit                 config.DataAuditConfig
+	// nil when dominant speaker boost is disabled
+	dominantSpeakerPolicy *streamallocator.DominantSpeakerPolicy
+	serverInfo            *livekit.ServerInfo
+	telemetry             telemetry.TelemetryService
+	egressLauncher        EgressLauncher
+	trackManager          *RoomTrackManager
+	agentDispatches       []*livekit.AgentDispatch
 
 	// agents
 	agentClient agent.Client
@@ -125,6 +138,14 @@ type Room struct {
 	hasPublished              map[livekit.ParticipantIdentity]bool
 	bufferFactory             *buffer.FactoryOfBufferFactory
 
+	// final usage of participants that have left, in the order they left
+	departedUsage      map[livekit.ParticipantID]*types.ParticipantUsage
+	departedUsageOrder deque.Deque[livekit.ParticipantID]
+
+	loadTest *roomLoadTest
+	// nil when HLS output is not running
+	hlsOutput *roomHLSOutput
+
 	// batch update participant info for non-publishers
 	batchedUpdates   map[livekit.ParticipantIdentity]*participantUpdate
 	batchedUpdatesMu sync.Mutex
@@ -137,9 +158,26 @@ type Room struct {
 	onRoomUpdated        func()
 	onClose              func()
 
+	events     *utils.EventObserverList[*RoomEvent]
+	eventsDone chan struct{}
+
+	admissionController AdmissionController
+	// nil when joins are not rate limited
+	joinRateLimiter *sutils.TokenBucket
+	joinMaxWait     time.Duration
+	// new participants are rejected while locked
+	locked atomic.Bool
+
 	simulationLock                                 sync.Mutex
 	disconnectSignalOnResumeParticipants           map[livekit.ParticipantIdentity]time.Time
 	disconnectSignalOnResumeNoMessagesParticipants map[livekit.ParticipantIdentity]*disconnectSignalOnResumeNoMessages
+
+	// participants of a restored room, until their clients reconnect
+	phantoms map[livekit.ParticipantIdentity]*phantomParticipant
+
+	// unix nanos at which the room closes, 0 when it does not have a max duration
+	expiresAt      atomic.Int64
+	expiryWarnings []time.Duration
 }
 
 type ParticipantOptions struct {
@@ -168,6 +206,8 @@ func NewRoom(
 		),
 		config:                               config,
 		audioConfig:                          audioConfig,
+		egressBitrateShareTrigger:            make(chan struct{}, 1),
+		dataAudit:                            roomConfig.DataAudit,
 		telemetry:                            telemetry,
 		egressLauncher:                       egressLauncher,
 		agentClient:                          agentClient,
@@ -178,12 +218,28 @@ func NewRoom(
 		participantOpts:                      make(map[livekit.ParticipantIdentity]*ParticipantOptions),
 		participantRequestSources:            make(map[livekit.ParticipantIdentity]routing.MessageSource),
 		hasPublished:                         make(map[livekit.ParticipantIdentity]bool),
-		bufferFactory:                        buffer.NewFactoryOfBufferFactory(config.Receiver.PacketBufferSizeVideo, config.Receiver.PacketBufferSizeAudio),
+		departedUsage:                        make(map[livekit.ParticipantID]*types.ParticipantUsage),
+		bufferFactory:                        buffer.NewFactoryOfBufferFactory(config.Receiver.PacketBufferSizeVideo, config.Receiver.PacketBufferSizeAudio, config.Receiver.PacketBufferPool),
 		batchedUpdates:                       make(map[livekit.ParticipantIdentity]*participantUpdate),
 		closed:                               make(chan struct{}),
+		eventsDone:                           make(chan struct{}),
 		trailer:                              []byte(utils.RandomSecret()),
 		disconnectSignalOnResumeParticipants: make(map[livekit.ParticipantIdentity]time.Time),
 		disconnectSignalOnResumeNoMessagesParticipants: make(map[livekit.ParticipantIdentity]*disconnectSignalOnResumeNoMessages),
+		phantoms:       make(map[livekit.ParticipantIdentity]*phantomParticipant),
+		expiryWarnings: roomConfig.ExpiryWarnings,
+	}
+
+	if roomConfig.DominantSpeakerVideoPriority > 0 {
+		r.dominantSpeakerPolicy = streamallocator.NewDominantSpeakerPolicy(
+			roomConfig.DominantSpeakerVideoPriority,
+			roomConfig.DominantSpeakerMinHold,
+		)
+	}
+
+	if roomConfig.JoinRateLimit.Rate > 0 {
+		r.joinRateLimiter = sutils.NewTokenBucket(roomConfig.JoinRateLimit.Rate, roomConfig.JoinRateLimit.Burst)
+		r.joinMaxWait = roomConfig.JoinRateLimit.MaxWait
 	}
 
 	if r.protoRoom.EmptyTimeout == 0 {
@@ -196,6 +252,10 @@ func NewRoom(
 		r.protoRoom.CreationTime = time.Now().Unix()
 	}
 	r.protoProxy = utils.NewProtoProxy[*livekit.Room](roomUpdateInterval, r.updateProto)
+	r.events = utils.NewEventObserverList[*RoomEvent](utils.EventEmitterParams{
+		QueueSize: roomEventQueueSize,
+		Logger:    r.Logger,
+	})
 
 	r.createAgentDispatchesFromRoomAgent()
 
@@ -205,6 +265,10 @@ func NewRoom(
 	go r.connectionQualityWorker()
 	go r.changeUpdateWorker()
 	go r.simulationCleanupWorker()
+	r.maxEgressBitrate.Store(roomConfig.MaxEgressBitrate)
+	go r.egressBitrateWorker()
+	go r.syncAlignmentWorker()
+	go r.roomExpiryWorker()
 
 	return r
 }
@@ -326,7 +390,38 @@ func (r *Room) Release() {
 	r.holds.Dec()
 }
 
-func (r *Room) Join(participant types.LocalParticipant, requestSource routing.MessageSource, opts *ParticipantOptions, iceServers []*livekit.ICEServer) error {
+func (r *Room) Join(
+	ctx context.Context,
+	participant types.LocalParticipant,
+	requestSource routing.MessageSource,
+	opts *ParticipantOptions,
+	iceServers []*livekit.ICEServer,
+) (err error) {
+	_, span := tracer.Start(ctx, "Room.Join",
+		tracing.Attr("room", string(r.Name())),
+		tracing.Attr("participant", string(participant.Identity())),
+		tracing.Attr("pID", string(participant.ID())),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	if r.IsClosed() {
+		return ErrRoomClosed
+	}
+
+	// queued joins wait here, done before locking the room
+	if err := r.waitJoinRate(participant); err != nil {
+		return err
+	}
+
+	// admission could call out to external services, done before locking the room
+	decision, err := r.admit(participant)
+	if err != nil {
+		return err
+	}
+
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
@@ -337,6 +432,9 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 	if r.participants[participant.Identity()] != nil {
 		return ErrAlreadyJoined
 	}
+	if r.IsLocked() && !participant.IsDependent() {
+		return ErrRoomLocked
+	}
 	if r.protoRoom.MaxParticipants > 0 && !participant.IsDependent() {
 		numParticipants := uint32(0)
 		for _, p := range r.participants {
@@ -348,6 +446,7 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 			return ErrMaxParticipantsExceeded
 		}
 	}
+	applyAdmissionDecision(participant, decision)
 
 	if r.FirstJoinedAt() == 0 {
 		r.joinedAt.Store(time.Now().Unix())
@@ -362,6 +461,8 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 		if state == livekit.ParticipantInfo_ACTIVE {
 			// subscribe participant to existing published tracks
 			r.subscribeToExistingTracks(p)
+			r.onPublishIntentsChanged(p)
+			r.triggerEgressBitrateShare()
 
 			meta := &livekit.AnalyticsClientMeta{
 				ClientConnectTime: uint32(time.Since(p.ConnectedAt()).Milliseconds()),
@@ -381,6 +482,9 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 			)
 
 			p.GetLogger().Infow("participant active", connectionDetailsFields(cds)...)
+			r.emitEvent(RoomEventParticipantJoined, func(e *RoomEvent) {
+				e.Participant = p.ToProto()
+			})
 		} else if state == livekit.ParticipantInfo_DISCONNECTED {
 			// remove participant from room
 			// participant should already be closed and have a close reason, so NONE is fine here
@@ -393,7 +497,14 @@ func (r *Room) Join(participant types.LocalParticipant, requestSource routing.Me
 	participant.OnTrackUnpublished(r.onTrackUnpublished)
 	participant.OnParticipantUpdate(r.onParticipantUpdate)
 	participant.OnDataPacket(r.onDataPacket)
+	participant.RegisterDataRPCHandler(DataRPCMethodForward, r.forwardDataRPC)
+	participant.OnPublishIntentsChanged(r.onPublishIntentsChanged)
+	if r.dominantSpeakerPolicy != nil {
+		participant.SetSubscriberAllocationPolicy(r.dominantSpeakerPolicy)
+	}
 	participant.OnSubscribeStatusChanged(func(publisherID livekit.ParticipantID, subscribed bool) {
+		r.triggerEgressBitrateShare()
+
 		if subscribed {
 			pub := r.GetParticipantByID(publisherID)
 			if pub != nil && pub.State() == livekit.ParticipantInfo_ACTIVE {
@@ -542,6 +653,10 @@ func (r *Room) ResumeParticipant(
 
 	// include the local participant's info as well, since metadata could have been changed
 	updates := r.getOtherParticipantInfo("")
+	r.lock.RLock()
+	updates = append(updates, r.getVisibleHiddenParticipantInfoLocked(p)...)
+	updates = append(updates, r.getLoadTestInfosLocked()...)
+	r.lock.RUnlock()
 	if err := p.SendParticipantUpdate(updates); err != nil {
 		return err
 	}
@@ -602,6 +717,8 @@ func (r *Room) RemoveParticipant(identity livekit.ParticipantIdentity, pID livek
 	r.lock.Unlock()
 	r.protoProxy.MarkDirty(immediateChange)
 
+	r.recordDepartedUsage(p)
+
 	if !p.HasConnected() {
 		fields := append(connectionDetailsFields(p.GetICEConnectionDetails()),
 			"reason", reason.String(),
@@ -624,11 +741,21 @@ func (r *Room) RemoveParticipant(identity livekit.ParticipantIdentity, pID livek
 	p.OnParticipantUpdate(nil)
 	p.OnDataPacket(nil)
 	p.OnSubscribeStatusChanged(nil)
+	p.OnPublishIntentsChanged(nil)
+
+	// release transceivers reserved for tracks the participant did not get to publish
+	for _, op := range r.GetParticipants() {
+		op.SetPlaceholderTracks(p.ID(), nil)
+	}
 
 	// close participant as well
 	_ = p.Close(true, reason, false)
+	r.emitEvent(RoomEventParticipantLeft, func(e *RoomEvent) {
+		e.Participant = p.ToProto()
+	})
 
 	r.leftAt.Store(time.Now().Unix())
+	r.triggerEgressBitrateShare()
 
 	if sendUpdates {
 		if r.onParticipantChanged != nil {
@@ -720,12 +847,29 @@ func (r *Room) SyncState(participant types.LocalParticipant, state *livekit.Sync
 }
 
 func (r *Room) UpdateSubscriptionPermission(participant types.LocalParticipant, subscriptionPermission *livekit.SubscriptionPermission) error {
+	viewers := r.getHiddenParticipantViewers(participant)
 	if err := participant.UpdateSubscriptionPermission(subscriptionPermission, utils.TimedVersion(0), r.GetParticipantByID); err != nil {
 		return err
 	}
 	for _, track := range participant.GetPublishedTracks() {
 		r.trackManager.NotifyTrackChanged(track.ID())
 	}
+	if len(viewers) != 0 {
+		// whitelist could have shrunk, remove the participant from those that cannot see it anymore
+		for pID := range r.getHiddenParticipantViewers(participant) {
+			delete(viewers, pID)
+		}
+		r.sendHiddenParticipantRemoved(participant, viewers)
+	}
+	if hasRestrictedVisibility(participant) {
+		// whitelist could have changed, announce to participants it is visible to and subscribe them
+		r.broadcastHiddenParticipantState(participant, participant.ToProto())
+		for _, op := range r.GetParticipants() {
+			if op.ID() != participant.ID() && op.State() == livekit.ParticipantInfo_ACTIVE {
+				r.subscribeToVisibleTracks(participant, op)
+			}
+		}
+	}
 	return nil
 }
 
@@ -747,7 +891,18 @@ func (r *Room) ResolveMediaTrackForSubscriber(subIdentity livekit.ParticipantIde
 	pub := r.GetParticipantByID(info.PublisherID)
 	// when publisher is not found, we will assume it doesn't have permission to access
 	if pub != nil {
+		if hasRestrictedVisibility(pub) {
+			// tracks of hidden publishers are not revealed to subscribers outside the whitelist
+			if sub := r.GetParticipant(subIdentity); sub == nil || isTrackHiddenFrom(pub, trackID, sub) {
+				return types.MediaResolverResult{
+					TrackChangedNotifier: res.TrackChangedNotifier,
+				}
+			}
+		}
 		res.HasPermission = pub.HasPermission(trackID, subIdentity)
+	} else if r.isLoadTestTrack(info.PublisherID) {
+		// synthetic tracks are available to everyone
+		res.HasPermission = true
 	}
 
 	return res
@@ -777,6 +932,11 @@ func (r *Room) CloseIfEmpty() {
 			return
 		}
 	}
+	if r.expirePhantomsLocked() {
+		// waiting for participants of a restored room to reconnect
+		r.lock.Unlock()
+		return
+	}
 
 	var timeout uint32
 	var elapsed int64
@@ -811,8 +971,14 @@ func (r *Room) Close(reason types.ParticipantCloseReason) {
 	for _, p := range r.GetParticipants() {
 		_ = p.Close(true, reason, false)
 	}
+	_ = r.StopHLS()
+	_ = r.StopLoadTest()
 
 	r.protoProxy.Stop()
+	r.emitEvent(RoomEventRoomFinished, func(e *RoomEvent) {
+		e.Usage = r.ParticipantUsage()
+	})
+	close(r.eventsDone)
 
 	if r.onClose != nil {
 		r.onClose()
@@ -930,6 +1096,18 @@ func (r *Room) SimulateScenario(participant types.LocalParticipant, simulateScen
 	return nil
 }
 
+// SimulateNetworkConditions impairs media between a participant and the SFU with programmable loss,
+// latency, jitter and bandwidth clamps. SimulateScenario has no room in its protocol for these,
+// so they are set through here instead, nil conditions restore a direction.
+func (r *Room) SimulateNetworkConditions(participant types.LocalParticipant, uplink *netem.Conditions, downlink *netem.Conditions) {
+	if uplink.IsNoop() && downlink.IsNoop() {
+		r.Logger.Infow("simulating network conditions end", "participant", participant.Identity())
+	} else {
+		r.Logger.Infow("simulating network conditions start", "participant", participant.Identity(), "uplink", uplink, "downlink", downlink)
+	}
+	participant.SetNetworkConditions(uplink, downlink)
+}
+
 func (r *Room) getOtherParticipantInfo(identity livekit.ParticipantIdentity) []*livekit.ParticipantInfo {
 	participants := r.GetParticipants()
 	pi := make([]*livekit.ParticipantInfo, 0, len(participants))
@@ -960,6 +1138,8 @@ func (r *Room) createJoinResponseLocked(participant types.LocalParticipant, iceS
 			otherParticipants = append(otherParticipants, p.ToProto())
 		}
 	}
+	otherParticipants = append(otherParticipants, r.getVisibleHiddenParticipantInfoLocked(participant)...)
+	otherParticipants = append(otherParticipants, r.getLoadTestInfosLocked()...)
 
 	return &livekit.JoinResponse{
 		Room:              r.ToProto(),
@@ -998,6 +1178,9 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 		if !r.autoSubscribe(existingParticipant) {
 			continue
 		}
+		if isTrackHiddenFrom(participant, track.ID(), existingParticipant) {
+			continue
+		}
 
 		r.Logger.Debugw("subscribing to new track",
 			"participant", existingParticipant.Identity(),
@@ -1015,6 +1198,11 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 	}
 
 	r.trackManager.AddTrack(track, participant.Identity(), participant.ID())
+	r.onLoadTestTrackPublished(track)
+	r.emitEvent(RoomEventTrackPublished, func(e *RoomEvent) {
+		e.Participant = participant.ToProto()
+		e.Track = track.ToProto()
+	})
 
 	// launch jobs
 	r.lock.Lock()
@@ -1067,6 +1255,12 @@ func (r *Room) onTrackUpdated(p types.LocalParticipant, _ types.MediaTrack) {
 
 func (r *Room) onTrackUnpublished(p types.LocalParticipant, track types.MediaTrack) {
 	r.trackManager.RemoveTrack(track)
+	r.onHLSTrackUnpublished(track)
+	r.onLoadTestTrackUnpublished(track)
+	r.emitEvent(RoomEventTrackUnpublished, func(e *RoomEvent) {
+		e.Participant = p.ToProto()
+		e.Track = track.ToProto()
+	})
 	if !p.IsClosed() {
 		r.broadcastParticipantState(p, broadcastOptions{skipSource: true})
 	}
@@ -1085,9 +1279,49 @@ func (r *Room) onParticipantUpdate(p types.LocalParticipant) {
 }
 
 func (r *Room) onDataPacket(source types.LocalParticipant, kind livekit.DataPacket_Kind, dp *livekit.DataPacket) {
+	normalizeDataPacket(kind, dp)
+	if r.dataAudit.Enabled && dp.GetUser() != nil {
+		r.auditDataPacket(source, kind, dp)
+	}
+	if source != nil && dp.GetUser() != nil {
+		r.emitEvent(RoomEventDataReceived, func(e *RoomEvent) {
+			e.Participant = source.ToProto()
+			e.DataKind = kind.String()
+			e.DataTopic = dp.GetUser().GetTopic()
+			e.DataSize = len(dp.GetUser().GetPayload())
+		})
+	}
 	BroadcastDataPacketForRoom(r, source, kind, dp, r.Logger)
 }
 
+// auditDataPacket reports a user packet with its resolved destinations to telemetry. telemetry gets a copy,
+// as the packet is forwarded while telemetry processes it. packets sent by the server have no source
+func (r *Room) auditDataPacket(source types.LocalParticipant, kind livekit.DataPacket_Kind, dp *livekit.DataPacket) {
+	auditPacket := proto.Clone(dp).(*livekit.DataPacket)
+	if !r.dataAudit.IncludePayload {
+		auditPacket.GetUser().Payload = nil
+	}
+
+	var (
+		participantID livekit.ParticipantID
+		identity      livekit.ParticipantIdentity
+	)
+	if source != nil {
+		participantID = source.ID()
+		identity = source.Identity()
+	}
+	r.telemetry.DataPacketReceived(
+		context.Background(),
+		r.ID(),
+		r.Name(),
+		participantID,
+		identity,
+		kind,
+		auditPacket,
+		len(dp.GetUser().GetPayload()),
+	)
+}
+
 func (r *Room) subscribeToExistingTracks(p types.LocalParticipant) {
 	r.lock.RLock()
 	shouldSubscribe := r.autoSubscribe(p)
@@ -1105,15 +1339,58 @@ func (r *Room) subscribeToExistingTracks(p types.LocalParticipant) {
 
 		// subscribe to all
 		for _, track := range op.GetPublishedTracks() {
+			if isTrackHiddenFrom(op, track.ID(), p) {
+				continue
+			}
 			trackIDs = append(trackIDs, track.ID())
 			p.SubscribeToTrack(track.ID())
 		}
+
+		// and prepare for the ones about to be published
+		if intents := visiblePublishIntents(op, p); len(intents) > 0 {
+			p.SetPlaceholderTracks(op.ID(), intents)
+		}
 	}
 	if len(trackIDs) > 0 {
 		r.Logger.Debugw("subscribed participant to existing tracks", "trackID", trackIDs)
 	}
 }
 
+// a participant declared the tracks it is about to publish,
+// have auto subscribing participants reserve transceivers for those tracks
+func (r *Room) onPublishIntentsChanged(participant types.LocalParticipant) {
+	r.lock.RLock()
+	subscribers := make([]types.LocalParticipant, 0, len(r.participants))
+	for _, existingParticipant := range r.participants {
+		if existingParticipant == participant {
+			continue
+		}
+		if existingParticipant.State() != livekit.ParticipantInfo_ACTIVE {
+			// placeholders are set up when subscribing to existing tracks
+			continue
+		}
+		if !r.autoSubscribe(existingParticipant) {
+			continue
+		}
+		subscribers = append(subscribers, existingParticipant)
+	}
+	r.lock.RUnlock()
+
+	for _, sub := range subscribers {
+		sub.SetPlaceholderTracks(participant.ID(), visiblePublishIntents(participant, sub))
+	}
+}
+
+func visiblePublishIntents(pub types.LocalParticipant, sub types.LocalParticipant) []types.PublishIntent {
+	var intents []types.PublishIntent
+	for _, intent := range pub.GetPublishIntents() {
+		if !isTrackHiddenFrom(pub, intent.TrackID, sub) {
+			intents = append(intents, intent)
+		}
+	}
+	return intents
+}
+
 // broadcast an update about participant p
 func (r *Room) broadcastParticipantState(p types.LocalParticipant, opts broadcastOptions) {
 	pi := p.ToProto()
@@ -1126,6 +1403,8 @@ func (r *Room) broadcastParticipantState(p types.LocalParticipant, opts broadcas
 				p.GetLogger().Errorw("could not send update to participant", err)
 			}
 		}
+		// and to participants it is visible to, if any
+		r.broadcastHiddenParticipantState(p, pi)
 		return
 	}
 
@@ -1296,6 +1575,8 @@ func (r *Room) audioUpdateWorker() {
 		}
 
 		activeSpeakers := r.GetActiveSpeakers()
+		r.updateDominantSpeaker(activeSpeakers)
+
 		changedSpeakers := make([]*livekit.SpeakerInfo, 0, len(activeSpeakers))
 		nextActiveMap := make(map[livekit.ParticipantID]*livekit.SpeakerInfo, len(activeSpeakers))
 		for _, speaker := range activeSpeakers {
@@ -1327,6 +1608,37 @@ func (r *Room) audioUpdateWorker() {
 	}
 }
 
+// speakers are sorted by level, loudest first
+func (r *Room) updateDominantSpeaker(speakers []*livekit.SpeakerInfo) {
+	if r.dominantSpeakerPolicy == nil {
+		return
+	}
+
+	var dominantSpeaker livekit.ParticipantID
+	if len(speakers) != 0 {
+		dominantSpeaker = livekit.ParticipantID(speakers[0].Sid)
+
+		// stick with current dominant speaker while tied with the loudest to avoid re-allocations
+		current := r.dominantSpeakerPolicy.DominantSpeaker()
+		for _, speaker := range speakers {
+			if speaker.Level < speakers[0].Level {
+				break
+			}
+			if livekit.ParticipantID(speaker.Sid) == current {
+				dominantSpeaker = current
+				break
+			}
+		}
+	}
+	if !r.dominantSpeakerPolicy.SetDominantSpeaker(dominantSpeaker) {
+		return
+	}
+
+	for _, p := range r.GetParticipants() {
+		p.SetSubscriberAllocationPolicy(r.dominantSpeakerPolicy)
+	}
+}
+
 func (r *Room) connectionQualityWorker() {
 	ticker := time.NewTicker(connectionquality.UpdateInterval)
 	defer ticker.Stop()
@@ -1349,6 +1661,19 @@ func (r *Room) connectionQualityWorker() {
 			}
 		}
 
+		for _, p := range participants {
+			nowInfo, nowOk := nowConnectionInfos[p.ID()]
+			if !nowOk {
+				continue
+			}
+			if prevInfo, prevOk := prevConnectionInfos[p.ID()]; !prevOk || nowInfo.Quality != prevInfo.Quality {
+				r.emitEvent(RoomEventQualityChanged, func(e *RoomEvent) {
+					e.Participant = p.ToProto()
+					e.Quality = nowInfo.Quality.String()
+				})
+			}
+		}
+
 		// send an update if there is a change
 		//   - new participant
 		//   - quality change
@@ -1407,6 +1732,83 @@ func (r *Room) connectionQualityWorker() {
 	}
 }
 
+// SetMaxEgressBitrate sets aggregate bitrate (bps) forwarded to subscribers of the room, 0 for no limit
+func (r *Room) SetMaxEgressBitrate(bps int64) {
+	if bps < 0 {
+		bps = 0
+	}
+	if r.maxEgressBitrate.Swap(bps) != bps {
+		r.Logger.Infow("setting max egress bitrate", "bitrate", bps)
+		r.triggerEgressBitrateShare()
+	}
+}
+
+func (r *Room) triggerEgressBitrateShare() {
+	select {
+	case r.egressBitrateShareTrigger <- struct{}{}:
+	default:
+	}
+}
+
+// egressBitrateWorker enforces room level egress bitrate ceiling by splitting it among
+// stream allocators of subscribers, weighted by number of subscribed video tracks.
+// Share is updated periodically and when participants join, leave or change subscriptions.
+func (r *Room) egressBitrateWorker() {
+	ticker := time.NewTicker(egressBitrateShareInterval)
+	defer ticker.Stop()
+
+	isLimited := false
+	for {
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		case <-r.egressBitrateShareTrigger:
+		}
+
+		maxEgressBitrate := r.maxEgressBitrate.Load()
+		if maxEgressBitrate == 0 && !isLimited {
+			continue
+		}
+
+		r.shareEgressBitrate(maxEgressBitrate)
+		isLimited = maxEgressBitrate > 0
+	}
+}
+
+func (r *Room) shareEgressBitrate(maxEgressBitrate int64) {
+	participants := r.GetParticipants()
+	weights := make(map[livekit.ParticipantIdentity]int64, len(participants))
+	totalWeight := int64(0)
+	for _, p := range participants {
+		if maxEgressBitrate == 0 || p.State() != livekit.ParticipantInfo_ACTIVE {
+			continue
+		}
+
+		weight := int64(0)
+		for _, st := range p.GetSubscribedTracks() {
+			if st.MediaTrack().Kind() == livekit.TrackType_VIDEO {
+				weight++
+			}
+		}
+		if weight == 0 {
+			continue
+		}
+
+		weights[p.Identity()] = weight
+		totalWeight += weight
+	}
+
+	for _, p := range participants {
+		ceiling := int64(0)
+		if weight, ok := weights[p.Identity()]; ok {
+			ceiling = maxEgressBitrate * weight / totalWeight
+		}
+		// participants not subscribed to any video are not limited
+		p.SetSubscriberChannelCapacityCeiling(ceiling)
+	}
+}
+
 func (r *Room) simulationCleanupWorker() {
 	for {
 		if r.IsClosed() {
@@ -1480,6 +1882,11 @@ func (r *Room) DebugInfo() map[string]interface{} {
 		"Name":      r.protoRoom.Name,
 		"Sid":       r.protoRoom.Sid,
 		"CreatedAt": r.protoRoom.CreationTime,
+		"Locked":    r.IsLocked(),
+	}
+	if expiresAt, ok := r.ExpiresAt(); ok {
+		info["ExpiresAt"] = expiresAt.Unix()
+		info["RemainingSeconds"] = int64(time.Until(expiresAt) / time.Second)
 	}
 
 	participants := r.GetParticipants()
@@ -1489,6 +1896,14 @@ func (r *Room) DebugInfo() map[string]interface{} {
 	}
 	info["Participants"] = participantInfo
 
+	if status, err := r.GetLoadTestStatus(); err == nil {
+		info["LoadTest"] = status
+	}
+
+	if hlsInfo := r.hlsDebugInfo(); hlsInfo != nil {
+		info["HLS"] = hlsInfo
+	}
+
 	return info
 }
 
@@ -1528,9 +1943,10 @@ func (r *Room) createAgentDispatchesFromRoomAgent() {
 
 // ------------------------------------------------------------
 
-func BroadcastDataPacketForRoom(r types.Room, source types.LocalParticipant, kind livekit.DataPacket_Kind, dp *livekit.DataPacket, logger logger.Logger) {
+// normalizeDataPacket fills fields of the packet and its user packet from each other,
+// so that receivers on current and legacy protocols find source and destinations
+func normalizeDataPacket(kind livekit.DataPacket_Kind, dp *livekit.DataPacket) {
 	dp.Kind = kind // backward compatibility
-	dest := dp.GetUser().GetDestinationSids()
 	if u := dp.GetUser(); u != nil {
 		if len(dp.DestinationIdentities) == 0 {
 			dp.DestinationIdentities = u.DestinationIdentities
@@ -1543,9 +1959,31 @@ func BroadcastDataPacketForRoom(r types.Room, source types.LocalParticipant, kin
 			dp.ParticipantIdentity = u.ParticipantIdentity
 		}
 	}
+}
+
+func BroadcastDataPacketForRoom(r types.Room, source types.LocalParticipant, kind livekit.DataPacket_Kind, dp *livekit.DataPacket, logger logger.Logger) {
+	normalizeDataPacket(kind, dp)
+	dest := dp.GetUser().GetDestinationSids()
 	destIdentities := dp.DestinationIdentities
+	topic := dp.GetUser().GetTopic()
+
+	var participants []types.LocalParticipant
+	if len(dest) == 0 && len(destIdentities) != 0 {
+		// identities are stable across reconnects, look up targets directly instead of scanning the room
+		seen := make(map[string]struct{}, len(destIdentities))
+		for _, identity := range destIdentities {
+			if _, ok := seen[identity]; ok {
+				continue
+			}
+			seen[identity] = struct{}{}
 
-	participants := r.GetLocalParticipants()
+			if op := r.GetParticipant(livekit.ParticipantIdentity(identity)); op != nil {
+				participants = append(participants, op)
+			}
+		}
+	} else {
+		participants = r.GetLocalParticipants()
+	}
 	capacity := len(destIdentities)
 	if capacity == 0 {
 		capacity = len(dest)
@@ -1568,6 +2006,13 @@ func BroadcastDataPacketForRoom(r types.Room, source types.LocalParticipant, kin
 				continue
 			}
 		}
+		if topic != "" && !op.IsInterestedInDataTopic(topic) {
+			continue
+		}
+		if topic == DataRPCTopic && source != nil && op.HasClientCapability(types.ClientCapabilityDataRPC) {
+			// the topic of these clients carries frames of the server only
+			continue
+		}
 		if dpData == nil {
 			var err error
 			dpData, err = proto.Marshal(dp)
@@ -1595,6 +2040,7 @@ func IsCloseNotifySkippable(closeReason types.ParticipantCloseReason) bool {
 func connectionDetailsFields(cds []*types.ICEConnectionDetails) []interface{} {
 	var fields []interface{}
 	connectionType := types.ICEConnectionTypeUnknown
+	connectionFamily := types.ICEIPFamilyUnknown
 	for _, cd := range cds {
 		candidates := make([]string, 0, len(cd.Remote)+len(cd.Local))
 		for _, c := range cd.Local {
@@ -1624,12 +2070,17 @@ func connectionDetailsFields(cds []*types.ICEConnectionDetails) []interface{} {
 			candidates = append(candidates, cStr)
 		}
 		if len(candidates) > 0 {
-			fields = append(fields, fmt.Sprintf("%sCandidates", strings.ToLower(cd.Transport.String())), candidates)
+			transport := strings.ToLower(cd.Transport.String())
+			fields = append(fields,
+				fmt.Sprintf("%sCandidates", transport), candidates,
+				fmt.Sprintf("%sCandidateFamilies", transport), cd.CandidateStatsByFamily(),
+			)
 		}
 		if cd.Type != types.ICEConnectionTypeUnknown {
 			connectionType = cd.Type
+			connectionFamily = cd.Family
 		}
 	}
-	fields = append(fields, "connectionType", connectionType)
+	fields = append(fields, "connectionType", connectionType, "connectionFamily", connectionFamily)
 	return fields
 }
@@ -15,6 +15,8 @@
 package rtc
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -23,6 +25,8 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/webhook"
 
 	"github.com/livekit/livekit-server/version"
@@ -38,7 +42,7 @@ import (
 )
 
 func init() {
-	prometheus.Init("test", livekit.NodeType_SERVER)
+	prometheus.Init("test", livekit.NodeType_SERVER, config.PrometheusConfig{})
 }
 
 const (
@@ -89,7 +93,7 @@ func TestRoomJoin(t *testing.T) {
 		rm := newRoomWithParticipants(t, testRoomOpts{num: numParticipants})
 		pNew := NewMockParticipant("new", types.CurrentProtocol, false, false)
 
-		_ = rm.Join(pNew, nil, nil, iceServersForRoom)
+		_ = rm.Join(context.Background(), pNew, nil, nil, iceServersForRoom)
 
 		// expect new participant to get a JoinReply
 		res := pNew.SendJoinResponseArgsForCall(0)
@@ -104,7 +108,7 @@ func TestRoomJoin(t *testing.T) {
 		rm := newRoomWithParticipants(t, testRoomOpts{num: numExisting})
 		p := NewMockParticipant("new", types.CurrentProtocol, false, false)
 
-		err := rm.Join(p, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom)
+		err := rm.Join(context.Background(), p, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom)
 		require.NoError(t, err)
 
 		stateChangeCB := p.OnStateChangeArgsForCall(0)
@@ -159,9 +163,77 @@ func TestRoomJoin(t *testing.T) {
 		rm.lock.Unlock()
 		p := NewMockParticipant("second", types.ProtocolVersion(0), false, false)
 
-		err := rm.Join(p, nil, nil, iceServersForRoom)
+		err := rm.Join(context.Background(), p, nil, nil, iceServersForRoom)
 		require.Equal(t, ErrMaxParticipantsExceeded, err)
 	})
+
+	t.Run("admission controller can reject", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+		var req *AdmissionRequest
+		rm.SetAdmissionController(AdmissionControllerFunc(func(_ context.Context, r *AdmissionRequest) (*AdmissionDecision, error) {
+			req = r
+			return &AdmissionDecision{Reject: true, Reason: "banned"}, nil
+		}))
+		p := NewMockParticipant("banned", types.CurrentProtocol, false, false)
+
+		err := rm.Join(context.Background(), p, nil, nil, iceServersForRoom)
+		require.ErrorIs(t, err, ErrParticipantRejected)
+		require.Equal(t, "banned", req.Participant.Identity)
+		require.Equal(t, uint32(2), req.NumParticipants)
+		require.Nil(t, rm.GetParticipant("banned"))
+	})
+
+	t.Run("admission controller can cap participant", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		name := "guest"
+		rm.SetAdmissionController(AdmissionControllerFunc(func(_ context.Context, _ *AdmissionRequest) (*AdmissionDecision, error) {
+			return &AdmissionDecision{Hidden: true, Name: &name, MaxUplinkBitrate: 500_000}, nil
+		}))
+		p := NewMockParticipant("capped", types.CurrentProtocol, false, false)
+
+		require.NoError(t, rm.Join(context.Background(), p, nil, nil, iceServersForRoom))
+		require.Equal(t, 1, p.SetPermissionCallCount())
+		require.True(t, p.SetPermissionArgsForCall(0).Hidden)
+		require.Equal(t, 1, p.SetNameCallCount())
+		require.Equal(t, name, p.SetNameArgsForCall(0))
+		require.Equal(t, 1, p.SetMaxUplinkBitrateCallCount())
+		require.Equal(t, int64(500_000), p.SetMaxUplinkBitrateArgsForCall(0))
+		require.Zero(t, p.SetMetadataCallCount())
+	})
+}
+
+func TestModeration(t *testing.T) {
+	t.Run("locked room rejects new participants", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		rm.SetLocked(true)
+		p := NewMockParticipant("late", types.CurrentProtocol, false, false)
+		require.Equal(t, ErrRoomLocked, rm.Join(context.Background(), p, nil, nil, iceServersForRoom))
+
+		rm.SetLocked(false)
+		require.NoError(t, rm.Join(context.Background(), p, nil, nil, iceServersForRoom))
+	})
+
+	t.Run("mute all participants except exempted", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+		host := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+		guest := rm.GetParticipant("p1").(*typesfakes.FakeLocalParticipant)
+		for _, p := range []*typesfakes.FakeLocalParticipant{host, guest} {
+			p.GetPublishedTracksReturns([]types.MediaTrack{
+				NewMockTrack(livekit.TrackType_AUDIO, "mic"),
+				NewMockTrack(livekit.TrackType_VIDEO, "cam"),
+			})
+			p.SetTrackMutedReturns(&livekit.TrackInfo{})
+		}
+
+		muted := rm.MuteAllParticipants([]livekit.TrackType{livekit.TrackType_AUDIO}, []livekit.ParticipantIdentity{"p0"})
+		require.Len(t, muted, 1)
+		require.Zero(t, host.SetTrackMutedCallCount())
+		require.Equal(t, 1, guest.SetTrackMutedCallCount())
+		trackID, isMuted, fromAdmin := guest.SetTrackMutedArgsForCall(0)
+		require.Equal(t, guest.GetPublishedTracks()[0].ID(), trackID)
+		require.True(t, isMuted)
+		require.True(t, fromAdmin)
+	})
 }
 
 // various state changes to participant and that others are receiving update
@@ -382,7 +454,7 @@ func TestRoomClosure(t *testing.T) {
 		require.Len(t, rm.GetParticipants(), 0)
 		require.True(t, isClosed)
 
-		require.Equal(t, ErrRoomClosed, rm.Join(p, nil, nil, iceServersForRoom))
+		require.Equal(t, ErrRoomClosed, rm.Join(context.Background(), p, nil, nil, iceServersForRoom))
 	})
 
 	t.Run("room does not close before empty timeout", func(t *testing.T) {
@@ -434,6 +506,63 @@ func TestNewTrack(t *testing.T) {
 	})
 }
 
+func TestRoomEvents(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+	observer, done := rm.ObserveEvents()
+	defer observer.Stop()
+
+	nextEvent := func() *RoomEvent {
+		select {
+		case e := <-observer.Events():
+			return e
+		case <-time.After(time.Second):
+			require.Fail(t, "no event")
+			return nil
+		}
+	}
+
+	participants := rm.GetParticipants()
+	pub := participants[0].(*typesfakes.FakeLocalParticipant)
+	track := NewMockTrack(livekit.TrackType_VIDEO, "webcam")
+	pub.OnTrackPublishedArgsForCall(0)(pub, track)
+
+	e := nextEvent()
+	require.Equal(t, RoomEventTrackPublished, e.Event)
+	require.Equal(t, rm.Name(), e.Room)
+	require.NotNil(t, e.Track)
+
+	rm.onDataPacket(pub, livekit.DataPacket_RELIABLE, &livekit.DataPacket{
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{Payload: []byte("message"), Topic: proto.String("chat")},
+		},
+	})
+	e = nextEvent()
+	require.Equal(t, RoomEventDataReceived, e.Event)
+	require.Equal(t, "chat", e.DataTopic)
+	require.Equal(t, len("message"), e.DataSize)
+
+	other := participants[1]
+	rm.RemoveParticipant(other.Identity(), other.ID(), types.ParticipantCloseReasonClientRequestLeave)
+	e = nextEvent()
+	require.Equal(t, RoomEventParticipantLeft, e.Event)
+
+	rm.Close(types.ParticipantCloseReasonNone)
+	e = nextEvent()
+	require.Equal(t, RoomEventRoomFinished, e.Event)
+	select {
+	case <-done:
+	default:
+		require.Fail(t, "events not done after room closed")
+	}
+
+	b, err := json.Marshal(&RoomEvent{
+		Event:       RoomEventParticipantJoined,
+		Participant: &livekit.ParticipantInfo{Sid: "PA_1", State: livekit.ParticipantInfo_ACTIVE},
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"participant":{"sid":"PA_1","state":"ACTIVE"}`)
+}
+
 func TestActiveSpeakers(t *testing.T) {
 	t.Parallel()
 	getActiveSpeakerUpdates := func(p *typesfakes.FakeLocalParticipant) [][]*livekit.SpeakerInfo {
@@ -719,13 +848,43 @@ func TestDataChannel(t *testing.T) {
 	})
 }
 
+func TestDataPacketToIdentities(t *testing.T) {
+	p1 := NewMockParticipant("p1", types.CurrentProtocol, false, false)
+	p1.StateReturns(livekit.ParticipantInfo_ACTIVE)
+	p2 := NewMockParticipant("p2", types.CurrentProtocol, false, false)
+	p2.StateReturns(livekit.ParticipantInfo_ACTIVE)
+	participants := map[livekit.ParticipantIdentity]types.LocalParticipant{"p1": p1, "p2": p2}
+
+	room := &typesfakes.FakeRoom{}
+	room.GetParticipantCalls(func(identity livekit.ParticipantIdentity) types.LocalParticipant {
+		if p, ok := participants[identity]; ok {
+			return p
+		}
+		return nil
+	})
+
+	dp := &livekit.DataPacket{
+		DestinationIdentities: []string{"p1", "unknown", "p1"},
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{Payload: []byte("message")},
+		},
+	}
+	BroadcastDataPacketForRoom(room, nil, livekit.DataPacket_RELIABLE, dp, logger.GetLogger())
+
+	// targets are looked up once each, without scanning the room
+	require.Equal(t, 0, room.GetLocalParticipantsCallCount())
+	require.Equal(t, 2, room.GetParticipantCallCount())
+	require.Eventually(t, func() bool { return p1.SendDataPacketCallCount() == 1 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, 0, p2.SendDataPacketCallCount())
+}
+
 func TestHiddenParticipants(t *testing.T) {
 	t.Run("other participants don't receive hidden updates", func(t *testing.T) {
 		rm := newRoomWithParticipants(t, testRoomOpts{num: 2, numHidden: 1})
 		defer rm.Close(types.ParticipantCloseReasonNone)
 
 		pNew := NewMockParticipant("new", types.CurrentProtocol, false, false)
-		rm.Join(pNew, nil, nil, iceServersForRoom)
+		rm.Join(context.Background(), pNew, nil, nil, iceServersForRoom)
 
 		// expect new participant to get a JoinReply
 		res := pNew.SendJoinResponseArgsForCall(0)
@@ -740,7 +899,7 @@ func TestHiddenParticipants(t *testing.T) {
 		rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
 		hidden := NewMockParticipant("hidden", types.CurrentProtocol, true, false)
 
-		err := rm.Join(hidden, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom)
+		err := rm.Join(context.Background(), hidden, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom)
 		require.NoError(t, err)
 
 		stateChangeCB := hidden.OnStateChangeArgsForCall(0)
@@ -749,6 +908,83 @@ func TestHiddenParticipants(t *testing.T) {
 
 		require.Eventually(t, func() bool { return hidden.SubscribeToTrackCallCount() == 2 }, 5*time.Second, 10*time.Millisecond)
 	})
+
+	t.Run("hidden participant tracks are visible to whitelist only", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+
+		hidden := NewMockParticipant("hidden", types.CurrentProtocol, true, true)
+		hidden.ToProtoReturns(&livekit.ParticipantInfo{
+			Sid:        string(hidden.ID()),
+			Identity:   "hidden",
+			Permission: &livekit.ParticipantPermission{Hidden: true},
+			Tracks: []*livekit.TrackInfo{
+				{Sid: "audio"},
+				{Sid: "video"},
+			},
+		})
+		hidden.SubscriptionPermissionReturns(&livekit.SubscriptionPermission{
+			TrackPermissions: []*livekit.TrackPermission{
+				{
+					ParticipantIdentity: "host",
+					TrackSids:           []string{"audio"},
+				},
+			},
+		}, utils.TimedVersion(0))
+		require.NoError(t, rm.Join(context.Background(), hidden, nil, nil, iceServersForRoom))
+
+		host := NewMockParticipant("host", types.CurrentProtocol, false, false)
+		require.NoError(t, rm.Join(context.Background(), host, nil, nil, iceServersForRoom))
+		res := host.SendJoinResponseArgsForCall(0)
+		require.Len(t, res.OtherParticipants, 3)
+		visible := res.OtherParticipants[2]
+		require.Equal(t, "hidden", visible.Identity)
+		require.Len(t, visible.Tracks, 1)
+		require.Equal(t, "audio", visible.Tracks[0].Sid)
+		require.False(t, visible.Permission.Hidden)
+		require.False(t, isTrackHiddenFrom(hidden, "audio", host))
+		require.True(t, isTrackHiddenFrom(hidden, "video", host))
+
+		guest := NewMockParticipant("guest", types.CurrentProtocol, false, false)
+		require.NoError(t, rm.Join(context.Background(), guest, nil, nil, iceServersForRoom))
+		res = guest.SendJoinResponseArgsForCall(0)
+		require.Len(t, res.OtherParticipants, 3)
+		for _, pi := range res.OtherParticipants {
+			require.NotEqual(t, "hidden", pi.Identity)
+		}
+		require.True(t, isTrackHiddenFrom(hidden, "audio", guest))
+
+		// moving the whitelist to guest removes the hidden participant from host
+		hidden.UpdateSubscriptionPermissionStub = func(
+			sp *livekit.SubscriptionPermission,
+			_ utils.TimedVersion,
+			_ func(participantID livekit.ParticipantID) types.LocalParticipant,
+		) error {
+			hidden.SubscriptionPermissionReturns(sp, utils.TimedVersion(0))
+			return nil
+		}
+		require.NoError(t, rm.UpdateSubscriptionPermission(hidden, &livekit.SubscriptionPermission{
+			TrackPermissions: []*livekit.TrackPermission{
+				{
+					ParticipantIdentity: "guest",
+					AllTracks:           true,
+				},
+			},
+		}))
+
+		require.Equal(t, 1, host.SendParticipantUpdateCallCount())
+		removed := host.SendParticipantUpdateArgsForCall(0)
+		require.Len(t, removed, 1)
+		require.Equal(t, "hidden", removed[0].Identity)
+		require.Equal(t, livekit.ParticipantInfo_DISCONNECTED, removed[0].State)
+		require.Empty(t, removed[0].Tracks)
+
+		require.Equal(t, 1, guest.SendParticipantUpdateCallCount())
+		added := guest.SendParticipantUpdateArgsForCall(0)
+		require.Len(t, added, 1)
+		require.Equal(t, "hidden", added[0].Identity)
+		require.Len(t, added[0].Tracks, 2)
+	})
 }
 
 func TestRoomUpdate(t *testing.T) {
@@ -760,7 +996,7 @@ func TestRoomUpdate(t *testing.T) {
 		require.Equal(t, 0, p1.SendRoomUpdateCallCount())
 
 		p2 := NewMockParticipant("p2", types.CurrentProtocol, false, false)
-		require.NoError(t, rm.Join(p2, nil, nil, iceServersForRoom))
+		require.NoError(t, rm.Join(context.Background(), p2, nil, nil, iceServersForRoom))
 
 		// p1 should have received an update
 		time.Sleep(2 * defaultDelay)
@@ -785,6 +1021,140 @@ func TestRoomUpdate(t *testing.T) {
 	})
 }
 
+func TestEgressBitrateShare(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 3})
+	defer rm.Close(types.ParticipantCloseReasonNone)
+
+	subscribedTracks := func(kinds ...livekit.TrackType) []types.SubscribedTrack {
+		var sts []types.SubscribedTrack
+		for _, kind := range kinds {
+			mt := &typesfakes.FakeMediaTrack{}
+			mt.KindReturns(kind)
+			st := &typesfakes.FakeSubscribedTrack{}
+			st.MediaTrackReturns(mt)
+			sts = append(sts, st)
+		}
+		return sts
+	}
+
+	p0 := rm.GetParticipant("p0").(*typesfakes.FakeLocalParticipant)
+	p0.StateReturns(livekit.ParticipantInfo_ACTIVE)
+	p0.GetSubscribedTracksReturns(subscribedTracks(livekit.TrackType_VIDEO, livekit.TrackType_AUDIO))
+	p1 := rm.GetParticipant("p1").(*typesfakes.FakeLocalParticipant)
+	p1.StateReturns(livekit.ParticipantInfo_ACTIVE)
+	p1.GetSubscribedTracksReturns(subscribedTracks(livekit.TrackType_VIDEO, livekit.TrackType_VIDEO, livekit.TrackType_VIDEO))
+	// not subscribed to any video
+	p2 := rm.GetParticipant("p2").(*typesfakes.FakeLocalParticipant)
+	p2.StateReturns(livekit.ParticipantInfo_ACTIVE)
+	p2.GetSubscribedTracksReturns(subscribedTracks(livekit.TrackType_AUDIO))
+
+	lastCeiling := func(p *typesfakes.FakeLocalParticipant) int64 {
+		return p.SetSubscriberChannelCapacityCeilingArgsForCall(p.SetSubscriberChannelCapacityCeilingCallCount() - 1)
+	}
+
+	rm.shareEgressBitrate(4_000_000)
+	require.EqualValues(t, 1_000_000, lastCeiling(p0))
+	require.EqualValues(t, 3_000_000, lastCeiling(p1))
+	require.EqualValues(t, 0, lastCeiling(p2))
+
+	// participant no longer subscribed to video is not limited anymore
+	p1.GetSubscribedTracksReturns(nil)
+	rm.shareEgressBitrate(4_000_000)
+	require.EqualValues(t, 4_000_000, lastCeiling(p0))
+	require.EqualValues(t, 0, lastCeiling(p1))
+
+	// removing limit resets all ceilings
+	rm.shareEgressBitrate(0)
+	require.EqualValues(t, 0, lastCeiling(p0))
+	require.EqualValues(t, 0, lastCeiling(p1))
+	require.EqualValues(t, 0, lastCeiling(p2))
+}
+
+func TestEgressBitrateWorkerStopsOnClose(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+
+	done := make(chan struct{})
+	go func() {
+		rm.egressBitrateWorker()
+		close(done)
+	}()
+
+	rm.Close(types.ParticipantCloseReasonNone)
+	select {
+	case <-done:
+	case <-time.After(egressBitrateShareInterval / 2):
+		require.Fail(t, "egress bitrate worker did not stop on close")
+	}
+}
+
+func TestDataPacketAudit(t *testing.T) {
+	newAuditedRoom := func(t *testing.T) (*Room, *telemetryfakes.FakeTelemetryService) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 3})
+		t.Cleanup(func() { rm.Close(types.ParticipantCloseReasonNone) })
+		telemetryService := &telemetryfakes.FakeTelemetryService{}
+		rm.telemetry = telemetryService
+		rm.dataAudit = config.DataAuditConfig{Enabled: true}
+		return rm, telemetryService
+	}
+
+	t.Run("packets sent by the server are audited without a source", func(t *testing.T) {
+		rm, telemetryService := newAuditedRoom(t)
+
+		rm.SendDataPacket(&livekit.DataPacket{
+			Value: &livekit.DataPacket_User{
+				User: &livekit.UserPacket{Payload: []byte("from server")},
+			},
+		}, livekit.DataPacket_RELIABLE)
+
+		require.Equal(t, 1, telemetryService.DataPacketReceivedCallCount())
+		_, _, _, participantID, identity, _, audited, size := telemetryService.DataPacketReceivedArgsForCall(0)
+		require.Empty(t, participantID)
+		require.Empty(t, identity)
+		require.Nil(t, audited.GetUser().GetPayload())
+		require.Equal(t, len("from server"), size)
+		for _, op := range rm.GetParticipants() {
+			require.Equal(t, 1, op.(*typesfakes.FakeLocalParticipant).SendDataPacketCallCount())
+		}
+	})
+
+	t.Run("audited packet is a copy with resolved destinations", func(t *testing.T) {
+		rm, telemetryService := newAuditedRoom(t)
+		rm.dataAudit.IncludePayload = true
+		participants := rm.GetParticipants()
+		p := participants[0].(*typesfakes.FakeLocalParticipant)
+
+		// legacy clients set source and destinations on the user packet only
+		packet := &livekit.DataPacket{
+			Value: &livekit.DataPacket_User{
+				User: &livekit.UserPacket{
+					ParticipantIdentity:   string(p.Identity()),
+					DestinationIdentities: []string{string(participants[1].Identity())},
+					Payload:               []byte("message"),
+				},
+			},
+		}
+		p.OnDataPacketArgsForCall(0)(p, livekit.DataPacket_LOSSY, packet)
+
+		require.Equal(t, 1, telemetryService.DataPacketReceivedCallCount())
+		_, _, _, participantID, identity, kind, audited, _ := telemetryService.DataPacketReceivedArgsForCall(0)
+		require.Equal(t, p.ID(), participantID)
+		require.Equal(t, p.Identity(), identity)
+		require.Equal(t, livekit.DataPacket_LOSSY, kind)
+		require.Equal(t, livekit.DataPacket_LOSSY, audited.Kind)
+		require.Equal(t, string(p.Identity()), audited.ParticipantIdentity)
+		require.Equal(t, []string{string(participants[1].Identity())}, audited.DestinationIdentities)
+		require.Equal(t, []byte("message"), audited.GetUser().GetPayload())
+
+		// forwarding owns the packet, changes to it do not reach the audited copy
+		require.NotSame(t, packet, audited)
+		require.NotSame(t, packet.GetUser(), audited.GetUser())
+		packet.GetUser().Payload[0] = 'M'
+		packet.DestinationIdentities[0] = "changed"
+		require.Equal(t, []byte("message"), audited.GetUser().GetPayload())
+		require.Equal(t, []string{string(participants[1].Identity())}, audited.DestinationIdentities)
+	})
+}
+
 type testRoomOpts struct {
 	num                  int
 	numHidden            int
@@ -818,7 +1188,7 @@ func newRoomWithParticipants(t *testing.T, opts testRoomOpts) *Room {
 	for i := 0; i < opts.num+opts.numHidden; i++ {
 		identity := livekit.ParticipantIdentity(fmt.Sprintf("p%d", i))
 		participant := NewMockParticipant(identity, opts.protocol, i >= opts.num, true)
-		err := rm.Join(participant, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom)
+		err := rm.Join(context.Background(), participant, nil, &ParticipantOptions{AutoSubscribe: true}, iceServersForRoom)
 		require.NoError(t, err)
 		participant.StateReturns(livekit.ParticipantInfo_ACTIVE)
 		participant.IsReadyReturns(true)
@@ -0,0 +1,119 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/protocol/webhook"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	RoomEventParticipantJoined = webhook.EventParticipantJoined
+	RoomEventParticipantLeft   = webhook.EventParticipantLeft
+	RoomEventTrackPublished    = webhook.EventTrackPublished
+	RoomEventTrackUnpublished  = webhook.EventTrackUnpublished
+	RoomEventRoomFinished      = webhook.EventRoomFinished
+	RoomEventDataReceived      = "data_received"
+	RoomEventQualityChanged    = "connection_quality_changed"
+
+	roomEventQueueSize = 256
+)
+
+// RoomEvent describes activity in a room, delivered to server side observers of the room.
+// Only the fields relevant to the event are set.
+type RoomEvent struct {
+	Event       string                   `json:"event"`
+	Room        livekit.RoomName         `json:"room"`
+	Participant *livekit.ParticipantInfo `json:"participant,omitempty"`
+	Track       *livekit.TrackInfo       `json:"track,omitempty"`
+	DataKind    string                   `json:"data_kind,omitempty"`
+	DataTopic   string                   `json:"data_topic,omitempty"`
+	DataSize    int                      `json:"data_size,omitempty"`
+	Quality     string                   `json:"quality,omitempty"`
+	// usage of all participants of the room, set on room finished
+	Usage     []*types.ParticipantUsage `json:"usage,omitempty"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+type roomEventJSON struct {
+	Event       string                    `json:"event"`
+	Room        livekit.RoomName          `json:"room"`
+	Participant json.RawMessage           `json:"participant,omitempty"`
+	Track       json.RawMessage           `json:"track,omitempty"`
+	DataKind    string                    `json:"data_kind,omitempty"`
+	DataTopic   string                    `json:"data_topic,omitempty"`
+	DataSize    int                       `json:"data_size,omitempty"`
+	Quality     string                    `json:"quality,omitempty"`
+	Usage       []*types.ParticipantUsage `json:"usage,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+}
+
+// MarshalJSON encodes protobuf fields with protojson, keeping their names and enums as in the rest of the API
+func (e *RoomEvent) MarshalJSON() ([]byte, error) {
+	var err error
+	out := roomEventJSON{
+		Event:     e.Event,
+		Room:      e.Room,
+		DataKind:  e.DataKind,
+		DataTopic: e.DataTopic,
+		DataSize:  e.DataSize,
+		Quality:   e.Quality,
+		Usage:     e.Usage,
+		CreatedAt: e.CreatedAt,
+	}
+	if e.Participant != nil {
+		if out.Participant, err = protojson.Marshal(e.Participant); err != nil {
+			return nil, err
+		}
+	}
+	if e.Track != nil {
+		if out.Track, err = protojson.Marshal(e.Track); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(out)
+}
+
+// ObserveEvents returns an observer receiving events of this room, Stop must be called when done.
+// Events are dropped for observers that fall behind. The returned channel is closed once the room
+// has closed and emitted its last event.
+func (r *Room) ObserveEvents() (*utils.EventObserver[*RoomEvent], <-chan struct{}) {
+	return r.events.Observe(), r.eventsDone
+}
+
+// emitEvent builds and delivers an event only when the room is being observed
+func (r *Room) emitEvent(event string, build func(e *RoomEvent)) {
+	if r.events.Len() == 0 {
+		return
+	}
+
+	e := &RoomEvent{
+		Event:     event,
+		Room:      r.Name(),
+		CreatedAt: time.Now(),
+	}
+	if build != nil {
+		build(e)
+	}
+	r.events.Emit(e)
+}
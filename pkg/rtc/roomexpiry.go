@@ -0,0 +1,165 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// RoomExpiryTopic is the data topic on which participants of a room with a max duration are sent when the room
+// closes, once they join and again at each configured warning before it closes
+const RoomExpiryTopic = "lk.room_expiry"
+
+const roomExpiryCheckInterval = time.Second
+
+type roomExpiry struct {
+	ExpiresAt        int64 `json:"expires_at"`
+	RemainingSeconds int64 `json:"remaining_seconds"`
+}
+
+func encodeRoomExpiry(expiresAt time.Time, now time.Time) ([]byte, error) {
+	payload, err := json.Marshal(&roomExpiry{
+		ExpiresAt:        expiresAt.Unix(),
+		RemainingSeconds: int64(expiresAt.Sub(now).Round(time.Second) / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	topic := RoomExpiryTopic
+	return proto.Marshal(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   &topic,
+			},
+		},
+	})
+}
+
+// SetExpiry closes the room at expiresAt, a zero time removes the expiry
+func (r *Room) SetExpiry(expiresAt time.Time) {
+	if expiresAt.IsZero() {
+		r.expiresAt.Store(0)
+	} else {
+		r.expiresAt.Store(expiresAt.UnixNano())
+	}
+}
+
+// ExpiresAt returns when the room closes, false when the room does not have a max duration
+func (r *Room) ExpiresAt() (time.Time, bool) {
+	expiresAt := r.expiresAt.Load()
+	if expiresAt == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, expiresAt), true
+}
+
+// pendingExpiryWarnings returns warnings still ahead with the remaining time, in the order they are due
+func pendingExpiryWarnings(warnings []time.Duration, remaining time.Duration) []time.Duration {
+	pending := make([]time.Duration, 0, len(warnings))
+	for _, w := range warnings {
+		if w > 0 && w < remaining {
+			pending = append(pending, w)
+		}
+	}
+	slices.SortFunc(pending, func(a, b time.Duration) int {
+		switch {
+		case a > b:
+			return -1
+		case a < b:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return pending
+}
+
+// roomExpiryWorker closes the room once it expires, participants are sent the expiry when they join and
+// again at each warning
+func (r *Room) roomExpiryWorker() {
+	ticker := time.NewTicker(roomExpiryCheckInterval)
+	defer ticker.Stop()
+
+	var (
+		lastExpiresAt time.Time
+		pending       []time.Duration
+		sentTo        = make(map[livekit.ParticipantID]bool)
+	)
+	for !r.IsClosed() {
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		}
+
+		expiresAt, ok := r.ExpiresAt()
+		if !ok {
+			lastExpiresAt = time.Time{}
+			continue
+		}
+
+		now := time.Now()
+		remaining := expiresAt.Sub(now)
+		if remaining <= 0 {
+			r.Logger.Infow("closing room, max duration reached", "expiresAt", expiresAt)
+			r.Close(types.ParticipantCloseReasonRoomExpired)
+			return
+		}
+
+		if !expiresAt.Equal(lastExpiresAt) {
+			lastExpiresAt = expiresAt
+			pending = pendingExpiryWarnings(r.expiryWarnings, remaining)
+			clear(sentTo)
+		}
+		if len(pending) != 0 && remaining <= pending[0] {
+			for len(pending) != 0 && remaining <= pending[0] {
+				pending = pending[1:]
+			}
+			r.Logger.Infow("warning participants of room expiry", "remaining", remaining)
+			clear(sentTo)
+		}
+
+		var encoded []byte
+		for _, p := range r.GetParticipants() {
+			if sentTo[p.ID()] || p.State() != livekit.ParticipantInfo_ACTIVE || !p.IsInterestedInDataTopic(RoomExpiryTopic) {
+				continue
+			}
+
+			if encoded == nil {
+				var err error
+				if encoded, err = encodeRoomExpiry(expiresAt, now); err != nil {
+					r.Logger.Errorw("could not encode room expiry", err)
+					break
+				}
+			}
+			if err := p.SendDataPacket(livekit.DataPacket_RELIABLE, encoded); err != nil {
+				p.GetLogger().Debugw("could not send room expiry", "error", err)
+				continue
+			}
+			sentTo[p.ID()] = true
+		}
+	}
+}
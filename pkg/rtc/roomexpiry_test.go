@@ -0,0 +1,73 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/testutils"
+)
+
+func TestPendingExpiryWarnings(t *testing.T) {
+	warnings := []time.Duration{10 * time.Second, 5 * time.Minute, time.Minute}
+
+	require.Equal(t, []time.Duration{5 * time.Minute, time.Minute, 10 * time.Second}, pendingExpiryWarnings(warnings, time.Hour))
+	// warnings already passed are dropped
+	require.Equal(t, []time.Duration{10 * time.Second}, pendingExpiryWarnings(warnings, 30*time.Second))
+	require.Empty(t, pendingExpiryWarnings(warnings, 5*time.Second))
+	require.Empty(t, pendingExpiryWarnings(nil, time.Hour))
+}
+
+func TestEncodeRoomExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	encoded, err := encodeRoomExpiry(now.Add(90*time.Second), now)
+	require.NoError(t, err)
+
+	dp := &livekit.DataPacket{}
+	require.NoError(t, proto.Unmarshal(encoded, dp))
+	require.Equal(t, RoomExpiryTopic, dp.GetUser().GetTopic())
+
+	var expiry roomExpiry
+	require.NoError(t, json.Unmarshal(dp.GetUser().GetPayload(), &expiry))
+	require.Equal(t, int64(1700000090), expiry.ExpiresAt)
+	require.Equal(t, int64(90), expiry.RemainingSeconds)
+}
+
+func TestRoomExpiry(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+	_, ok := rm.ExpiresAt()
+	require.False(t, ok)
+
+	expiresAt := time.Now().Add(time.Second)
+	rm.SetExpiry(expiresAt)
+	at, ok := rm.ExpiresAt()
+	require.True(t, ok)
+	require.True(t, expiresAt.Equal(at))
+	require.Contains(t, rm.DebugInfo(), "RemainingSeconds")
+
+	testutils.WithTimeout(t, func() string {
+		if !rm.IsClosed() {
+			return "room did not close at expiry"
+		}
+		return ""
+	}, 5*time.Second)
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	// keep compound packets within a typical MTU after SRTCP overhead
+	rtcpSchedulerMaxCompoundSize = 1200
+)
+
+type RTCPSchedulerParams struct {
+	Interval time.Duration
+	Writer   func(pkts []rtcp.Packet) error
+	Logger   logger.Logger
+}
+
+// RTCPScheduler aggregates RTCP packets written by many tracks of a peer connection
+// (receiver reports, REMB, NACKs, ...) and writes them as compound packets on a timer.
+// Packets of one WriteRTCP call are kept together, so compound packets built by callers
+// (e. g. sender report + SDES) stay intact. Calls with key frame requests or sender reports
+// (used by receivers for synchronisation and RTT) are latency sensitive and are written immediately.
+// Errors of batches written on the timer are logged, they are not returned to later callers.
+type RTCPScheduler struct {
+	params RTCPSchedulerParams
+
+	lock    sync.Mutex
+	pending []rtcp.Packet
+	size    int
+	stopped bool
+
+	stop chan struct{}
+}
+
+func NewRTCPScheduler(params RTCPSchedulerParams) *RTCPScheduler {
+	r := &RTCPScheduler{
+		params: params,
+		stop:   make(chan struct{}),
+	}
+
+	go r.worker()
+	return r
+}
+
+func (r *RTCPScheduler) Stop() {
+	r.lock.Lock()
+	if r.stopped {
+		r.lock.Unlock()
+		return
+	}
+	r.stopped = true
+	r.lock.Unlock()
+
+	close(r.stop)
+	r.flush()
+}
+
+func (r *RTCPScheduler) WriteRTCP(pkts []rtcp.Packet) error {
+	if len(pkts) == 0 {
+		return nil
+	}
+
+	size := 0
+	for _, pkt := range pkts {
+		switch pkt.(type) {
+		case *rtcp.PictureLossIndication, *rtcp.FullIntraRequest, *rtcp.SenderReport:
+			return r.params.Writer(pkts)
+		}
+		size += pkt.MarshalSize()
+	}
+	if size > rtcpSchedulerMaxCompoundSize {
+		return r.params.Writer(pkts)
+	}
+
+	r.lock.Lock()
+	if r.stopped {
+		r.lock.Unlock()
+		return r.params.Writer(pkts)
+	}
+
+	var batch []rtcp.Packet
+	if r.size+size > rtcpSchedulerMaxCompoundSize && len(r.pending) != 0 {
+		batch = r.takePendingLocked()
+	}
+	r.pending = append(r.pending, pkts...)
+	r.size += size
+	r.lock.Unlock()
+
+	if len(batch) != 0 {
+		return r.params.Writer(batch)
+	}
+	return nil
+}
+
+func (r *RTCPScheduler) worker() {
+	ticker := time.NewTicker(r.params.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+
+		case <-ticker.C:
+			r.flush()
+		}
+	}
+}
+
+func (r *RTCPScheduler) flush() {
+	r.lock.Lock()
+	if len(r.pending) == 0 {
+		r.lock.Unlock()
+		return
+	}
+	pkts := r.takePendingLocked()
+	r.lock.Unlock()
+
+	if err := r.params.Writer(pkts); err != nil && !IsEOF(err) {
+		r.params.Logger.Debugw("could not write batched RTCP", "error", err, "numPackets", len(pkts))
+	}
+}
+
+// takePendingLocked returns pending packets ordered as a valid compound packet, i. e. reports first
+func (r *RTCPScheduler) takePendingLocked() []rtcp.Packet {
+	pkts := make([]rtcp.Packet, 0, len(r.pending))
+	for _, pkt := range r.pending {
+		switch pkt.(type) {
+		case *rtcp.SenderReport, *rtcp.ReceiverReport:
+			pkts = append(pkts, pkt)
+		}
+	}
+	for _, pkt := range r.pending {
+		switch pkt.(type) {
+		case *rtcp.SenderReport, *rtcp.ReceiverReport:
+		default:
+			pkts = append(pkts, pkt)
+		}
+	}
+
+	r.pending = r.pending[:0]
+	r.size = 0
+	return pkts
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+)
+
+func TestRTCPScheduler(t *testing.T) {
+	var lock sync.Mutex
+	var written [][]rtcp.Packet
+	s := NewRTCPScheduler(RTCPSchedulerParams{
+		Interval: time.Hour,
+		Writer: func(pkts []rtcp.Packet) error {
+			lock.Lock()
+			defer lock.Unlock()
+			written = append(written, pkts)
+			return nil
+		},
+		Logger: logger.GetLogger(),
+	})
+
+	nack := &rtcp.TransportLayerNack{MediaSSRC: 1, Nacks: []rtcp.NackPair{{PacketID: 10}}}
+	rr := &rtcp.ReceiverReport{SSRC: 2}
+	pli := &rtcp.PictureLossIndication{MediaSSRC: 1}
+
+	require.NoError(t, s.WriteRTCP([]rtcp.Packet{nack}))
+	require.NoError(t, s.WriteRTCP([]rtcp.Packet{rr}))
+	require.NoError(t, s.WriteRTCP([]rtcp.Packet{pli}))
+
+	// key frame request goes out immediately
+	lock.Lock()
+	require.Equal(t, [][]rtcp.Packet{{pli}}, written)
+	written = nil
+	lock.Unlock()
+
+	// rest are written as one compound packet, reports first
+	s.Stop()
+	lock.Lock()
+	require.Equal(t, [][]rtcp.Packet{{rr, nack}}, written)
+	lock.Unlock()
+}
+
+func TestRTCPSchedulerImmediateAndErrors(t *testing.T) {
+	var lock sync.Mutex
+	var written [][]rtcp.Packet
+	var writeErr error
+	s := NewRTCPScheduler(RTCPSchedulerParams{
+		Interval: time.Hour,
+		Writer: func(pkts []rtcp.Packet) error {
+			lock.Lock()
+			defer lock.Unlock()
+			written = append(written, pkts)
+			return writeErr
+		},
+		Logger: logger.GetLogger(),
+	})
+	defer s.Stop()
+
+	// compound packets with a sender report are written immediately and intact
+	sr := &rtcp.SenderReport{SSRC: 1}
+	sdes := &rtcp.SourceDescription{Chunks: []rtcp.SourceDescriptionChunk{{Source: 1}}}
+	require.NoError(t, s.WriteRTCP([]rtcp.Packet{sr, sdes}))
+	lock.Lock()
+	require.Equal(t, [][]rtcp.Packet{{sr, sdes}}, written)
+	written = nil
+	lock.Unlock()
+
+	// packets of a call are batched together
+	rr := &rtcp.ReceiverReport{SSRC: 2}
+	remb := &rtcp.ReceiverEstimatedMaximumBitrate{Bitrate: 1000, SSRCs: []uint32{2}}
+	require.NoError(t, s.WriteRTCP([]rtcp.Packet{rr, remb}))
+
+	// error writing a batch on the timer is not returned to later callers
+	lock.Lock()
+	writeErr = io.EOF
+	lock.Unlock()
+	s.flush()
+
+	lock.Lock()
+	require.Equal(t, [][]rtcp.Packet{{rr, remb}}, written)
+	writeErr = nil
+	lock.Unlock()
+	require.NoError(t, s.WriteRTCP([]rtcp.Packet{&rtcp.ReceiverReport{SSRC: 3}}))
+
+	// errors of immediate writes go to the caller
+	lock.Lock()
+	writeErr = io.EOF
+	lock.Unlock()
+	require.ErrorIs(t, s.WriteRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{MediaSSRC: 1}}), io.EOF)
+}
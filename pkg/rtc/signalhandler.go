@@ -106,7 +106,8 @@ func HandleParticipantSignal(room types.Room, participant types.LocalParticipant
 					participant.SetMetadata(msg.UpdateMetadata.Metadata)
 				}
 				if msg.UpdateMetadata.Attributes != nil {
-					participant.SetAttributes(msg.UpdateMetadata.Attributes)
+					// permission group membership can only be changed server side
+					participant.SetAttributes(withoutPermissionGroups(msg.UpdateMetadata.Attributes))
 				}
 			} else {
 				pLogger.Warnw("could not update metadata", err)
@@ -0,0 +1,252 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+const (
+	roomSnapshotVersion = 1
+
+	// phantom participants of a restored room are dropped when their clients do not reconnect in time
+	phantomParticipantTimeout = 2 * time.Minute
+)
+
+var ErrInvalidRoomSnapshot = errors.New("invalid room snapshot")
+
+// RoomSnapshot is the state of a room, serialized to restore the room on another node
+type RoomSnapshot struct {
+	CreatedAt    time.Time
+	Room         *livekit.Room
+	Internal     *livekit.RoomInternal
+	Participants []*ParticipantSnapshot
+}
+
+// ParticipantSnapshot is the state of a participant, including its published tracks
+type ParticipantSnapshot struct {
+	Info               *livekit.ParticipantInfo
+	SubscribedTrackIDs []livekit.TrackID
+}
+
+type roomSnapshotJSON struct {
+	Version      int                       `json:"version"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	Room         json.RawMessage           `json:"room"`
+	Internal     json.RawMessage           `json:"internal,omitempty"`
+	Participants []participantSnapshotJSON `json:"participants"`
+}
+
+type participantSnapshotJSON struct {
+	Info               json.RawMessage   `json:"info"`
+	SubscribedTrackIDs []livekit.TrackID `json:"subscribed_track_ids,omitempty"`
+}
+
+func (s *RoomSnapshot) Marshal() ([]byte, error) {
+	var err error
+	out := roomSnapshotJSON{
+		Version:      roomSnapshotVersion,
+		CreatedAt:    s.CreatedAt,
+		Participants: make([]participantSnapshotJSON, 0, len(s.Participants)),
+	}
+	if out.Room, err = protojson.Marshal(s.Room); err != nil {
+		return nil, err
+	}
+	if s.Internal != nil {
+		if out.Internal, err = protojson.Marshal(s.Internal); err != nil {
+			return nil, err
+		}
+	}
+	for _, ps := range s.Participants {
+		info, err := protojson.Marshal(ps.Info)
+		if err != nil {
+			return nil, err
+		}
+		out.Participants = append(out.Participants, participantSnapshotJSON{
+			Info:               info,
+			SubscribedTrackIDs: ps.SubscribedTrackIDs,
+		})
+	}
+	return json.Marshal(out)
+}
+
+func UnmarshalRoomSnapshot(b []byte) (*RoomSnapshot, error) {
+	var in roomSnapshotJSON
+	if err := json.Unmarshal(b, &in); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRoomSnapshot, err)
+	}
+	if in.Version != roomSnapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidRoomSnapshot, in.Version)
+	}
+
+	s := &RoomSnapshot{
+		CreatedAt: in.CreatedAt,
+		Room:      &livekit.Room{},
+	}
+	if err := protojson.Unmarshal(in.Room, s.Room); err != nil || s.Room.Name == "" {
+		return nil, fmt.Errorf("%w: invalid room", ErrInvalidRoomSnapshot)
+	}
+	if len(in.Internal) != 0 {
+		s.Internal = &livekit.RoomInternal{}
+		if err := protojson.Unmarshal(in.Internal, s.Internal); err != nil {
+			return nil, fmt.Errorf("%w: invalid room internal", ErrInvalidRoomSnapshot)
+		}
+	}
+	for _, ps := range in.Participants {
+		info := &livekit.ParticipantInfo{}
+		if err := protojson.Unmarshal(ps.Info, info); err != nil || info.Identity == "" || info.Sid == "" {
+			return nil, fmt.Errorf("%w: invalid participant", ErrInvalidRoomSnapshot)
+		}
+		s.Participants = append(s.Participants, &ParticipantSnapshot{
+			Info:               info,
+			SubscribedTrackIDs: ps.SubscribedTrackIDs,
+		})
+	}
+	return s, nil
+}
+
+// phantomParticipant holds the state of a participant of a restored room until its client reconnects
+type phantomParticipant struct {
+	snapshot  *ParticipantSnapshot
+	expiresAt time.Time
+}
+
+// Snapshot captures the room, its participants and their subscriptions,
+// participants restored earlier that have not reconnected yet are included
+func (r *Room) Snapshot() *RoomSnapshot {
+	snapshot := &RoomSnapshot{
+		CreatedAt: time.Now(),
+		Room:      r.ToProto(),
+	}
+	if internal := r.Internal(); internal != nil {
+		snapshot.Internal = proto.Clone(internal).(*livekit.RoomInternal)
+	}
+
+	for _, p := range r.GetParticipants() {
+		if p.IsClosed() {
+			continue
+		}
+
+		ps := &ParticipantSnapshot{
+			Info: p.ToProto(),
+		}
+		for _, st := range p.GetSubscribedTracks() {
+			ps.SubscribedTrackIDs = append(ps.SubscribedTrackIDs, st.ID())
+		}
+		snapshot.Participants = append(snapshot.Participants, ps)
+	}
+
+	r.lock.Lock()
+	r.expirePhantomsLocked()
+	for identity, phantom := range r.phantoms {
+		if _, ok := r.participants[identity]; !ok {
+			snapshot.Participants = append(snapshot.Participants, phantom.snapshot)
+		}
+	}
+	r.lock.Unlock()
+
+	sort.Slice(snapshot.Participants, func(i, j int) bool {
+		return snapshot.Participants[i].Info.Identity < snapshot.Participants[j].Info.Identity
+	})
+	return snapshot
+}
+
+// RestorePhantoms adds participants of a snapshot as phantoms, keeping the room open for them.
+// A client joining with the identity of a phantom takes over its state.
+func (r *Room) RestorePhantoms(participants []*ParticipantSnapshot) {
+	expiresAt := time.Now().Add(phantomParticipantTimeout)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, ps := range participants {
+		identity := livekit.ParticipantIdentity(ps.Info.Identity)
+		if _, ok := r.participants[identity]; ok {
+			continue
+		}
+		r.phantoms[identity] = &phantomParticipant{
+			snapshot:  ps,
+			expiresAt: expiresAt,
+		}
+	}
+	r.Logger.Infow("restored phantom participants", "count", len(r.phantoms))
+}
+
+// TakePhantom returns state of the phantom participant with the identity, nil if there is none
+func (r *Room) TakePhantom(identity livekit.ParticipantIdentity) *ParticipantSnapshot {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.expirePhantomsLocked()
+	phantom, ok := r.phantoms[identity]
+	if !ok {
+		return nil
+	}
+	delete(r.phantoms, identity)
+	return phantom.snapshot
+}
+
+// expirePhantomsLocked drops phantoms past their timeout, returns true if any phantom remains
+func (r *Room) expirePhantomsLocked() bool {
+	now := time.Now()
+	for identity, phantom := range r.phantoms {
+		if now.After(phantom.expiresAt) {
+			r.Logger.Infow("phantom participant did not reconnect", "participant", identity)
+			delete(r.phantoms, identity)
+		}
+	}
+	return len(r.phantoms) != 0
+}
+
+// restore takes over state of a phantom participant, its client joining after the room was restored.
+// Tracks published again get their previous IDs, so subscribers resume their subscriptions.
+// Grants of the join token take precedence, only name, metadata and attributes not set by the token are restored.
+// Permissions always come from the token.
+func (p *ParticipantImpl) restore(snapshot *ParticipantSnapshot) {
+	grants := p.grants.Load().Clone()
+	if grants.Name == "" {
+		grants.Name = snapshot.Info.Name
+	}
+	if grants.Metadata == "" {
+		grants.Metadata = snapshot.Info.Metadata
+	}
+	for k, v := range snapshot.Info.Attributes {
+		if _, ok := grants.Attributes[k]; ok {
+			continue
+		}
+		if grants.Attributes == nil {
+			grants.Attributes = make(map[string]string, len(snapshot.Info.Attributes))
+		}
+		grants.Attributes[k] = v
+	}
+	p.grants.Store(grants)
+
+	for _, ti := range snapshot.Info.Tracks {
+		p.unpublishedTracks = append(p.unpublishedTracks, proto.Clone(ti).(*livekit.TrackInfo))
+	}
+
+	for _, trackID := range snapshot.SubscribedTrackIDs {
+		p.SubscribeToTrack(trackID)
+	}
+}
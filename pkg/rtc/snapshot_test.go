@@ -0,0 +1,152 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestRoomSnapshotMarshal(t *testing.T) {
+	snapshot := &RoomSnapshot{
+		CreatedAt: time.Unix(1700000000, 0).UTC(),
+		Room:      &livekit.Room{Sid: "RM_1", Name: "room", Metadata: "meta"},
+		Internal:  &livekit.RoomInternal{SyncStreams: true},
+		Participants: []*ParticipantSnapshot{
+			{
+				Info: &livekit.ParticipantInfo{
+					Sid:      "PA_1",
+					Identity: "alice",
+					Tracks:   []*livekit.TrackInfo{{Sid: "TR_1", Type: livekit.TrackType_AUDIO}},
+				},
+				SubscribedTrackIDs: []livekit.TrackID{"TR_2"},
+			},
+		},
+	}
+
+	b, err := snapshot.Marshal()
+	require.NoError(t, err)
+
+	restored, err := UnmarshalRoomSnapshot(b)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.CreatedAt, restored.CreatedAt)
+	require.True(t, proto.Equal(snapshot.Room, restored.Room))
+	require.True(t, proto.Equal(snapshot.Internal, restored.Internal))
+	require.Len(t, restored.Participants, 1)
+	require.True(t, proto.Equal(snapshot.Participants[0].Info, restored.Participants[0].Info))
+	require.Equal(t, snapshot.Participants[0].SubscribedTrackIDs, restored.Participants[0].SubscribedTrackIDs)
+
+	for _, invalid := range []string{
+		`not json`,
+		`{"version":2,"room":{"name":"room"}}`,
+		`{"version":1,"room":{}}`,
+		`{"version":1,"room":{"name":"room"},"participants":[{"info":{"sid":"PA_1"}}]}`,
+	} {
+		_, err = UnmarshalRoomSnapshot([]byte(invalid))
+		require.True(t, errors.Is(err, ErrInvalidRoomSnapshot), invalid)
+	}
+}
+
+func TestRoomPhantoms(t *testing.T) {
+	t.Run("snapshot includes participants and phantoms", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+		rm.RestorePhantoms([]*ParticipantSnapshot{
+			{Info: &livekit.ParticipantInfo{Sid: "PA_phantom", Identity: "phantom"}},
+		})
+
+		snapshot := rm.Snapshot()
+		require.Equal(t, "room", snapshot.Room.Name)
+		require.Len(t, snapshot.Participants, 3)
+		for i := 1; i < len(snapshot.Participants); i++ {
+			require.Less(t, snapshot.Participants[i-1].Info.Identity, snapshot.Participants[i].Info.Identity)
+		}
+	})
+
+	t.Run("phantom is taken over once", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+		existing := rm.GetParticipants()[0]
+		rm.RestorePhantoms([]*ParticipantSnapshot{
+			{Info: &livekit.ParticipantInfo{Sid: "PA_phantom", Identity: "phantom"}},
+			{Info: &livekit.ParticipantInfo{Sid: "PA_existing", Identity: string(existing.Identity())}},
+		})
+
+		// participants already in the room are not restored
+		require.Nil(t, rm.TakePhantom(existing.Identity()))
+
+		restore := rm.TakePhantom("phantom")
+		require.NotNil(t, restore)
+		require.Equal(t, "PA_phantom", restore.Info.Sid)
+		require.Nil(t, rm.TakePhantom("phantom"))
+	})
+
+	t.Run("room stays open for phantoms until they expire", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 0})
+		isClosed := false
+		rm.OnClose(func() {
+			isClosed = true
+		})
+		rm.lock.Lock()
+		rm.protoRoom.EmptyTimeout = 0
+		rm.lock.Unlock()
+
+		rm.RestorePhantoms([]*ParticipantSnapshot{
+			{Info: &livekit.ParticipantInfo{Sid: "PA_phantom", Identity: "phantom"}},
+		})
+		rm.CloseIfEmpty()
+		require.False(t, isClosed)
+
+		rm.lock.Lock()
+		rm.phantoms["phantom"].expiresAt = time.Now().Add(-time.Second)
+		rm.lock.Unlock()
+		rm.CloseIfEmpty()
+		require.True(t, isClosed)
+	})
+}
+
+func TestParticipantRestore(t *testing.T) {
+	p := newParticipantForTestWithOpts("alice", &participantOpts{
+		permissions: &livekit.ParticipantPermission{CanSubscribe: true},
+	})
+	grants := p.grants.Load().Clone()
+	grants.Metadata = "token metadata"
+	grants.Attributes = map[string]string{"role": "viewer"}
+	p.grants.Store(grants)
+
+	p.restore(&ParticipantSnapshot{
+		Info: &livekit.ParticipantInfo{
+			Identity:   "alice",
+			Name:       "Alice",
+			Metadata:   "restored metadata",
+			Attributes: map[string]string{"role": "host", "hand": "raised"},
+			Permission: &livekit.ParticipantPermission{CanPublish: true, CanSubscribe: true},
+		},
+	})
+
+	restored := p.ClaimGrants()
+	// not set by token
+	require.Equal(t, "Alice", restored.Name)
+	require.Equal(t, "raised", restored.Attributes["hand"])
+	// token takes precedence
+	require.Equal(t, "token metadata", restored.Metadata)
+	require.Equal(t, "viewer", restored.Attributes["role"])
+	require.False(t, restored.Video.GetCanPublish())
+	require.True(t, restored.Video.GetCanSubscribe())
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 )
 
 const (
@@ -57,12 +58,14 @@ type SubscribedTrack struct {
 	settingsLock     sync.Mutex
 	settings         *livekit.UpdateTrackSettings
 	settingsVersion  utils.TimedVersion
+	isAudioOnly      bool
 
 	bindLock        sync.Mutex
 	bound           bool
 	onBindCallbacks []func(error)
 
-	onClose atomic.Value // func(bool)
+	onClose          atomic.Value // func(bool)
+	onPriorityChange atomic.Value // func()
 
 	debouncer func(func())
 }
@@ -201,6 +204,9 @@ func (t *SubscribedTrack) IsMuted() bool {
 }
 
 func (t *SubscribedTrack) isMutedLocked() bool {
+	if t.isAudioOnly {
+		return true
+	}
 	if t.settings == nil {
 		return false
 	}
@@ -212,6 +218,25 @@ func (t *SubscribedTrack) SetPublisherMuted(muted bool) {
 	t.DownTrack().PubMute(muted)
 }
 
+// SetAudioOnly pauses video while the subscriber is in audio only mode,
+// on exit video resumes according to subscriber settings
+func (t *SubscribedTrack) SetAudioOnly(audioOnly bool) {
+	if t.MediaTrack().Kind() != livekit.TrackType_VIDEO {
+		return
+	}
+
+	t.settingsLock.Lock()
+	if t.isAudioOnly == audioOnly {
+		t.settingsLock.Unlock()
+		return
+	}
+	t.isAudioOnly = audioOnly
+	t.settingsLock.Unlock()
+
+	t.logger.Debugw("setting audio only", "audioOnly", audioOnly)
+	t.applySettings()
+}
+
 func (t *SubscribedTrack) UpdateSubscriberSettings(settings *livekit.UpdateTrackSettings, isImmediate bool) {
 	t.settingsLock.Lock()
 	if proto.Equal(t.settings, settings) {
@@ -220,9 +245,17 @@ func (t *SubscribedTrack) UpdateSubscriberSettings(settings *livekit.UpdateTrack
 	}
 
 	isImmediate = isImmediate || (!settings.Disabled && settings.Disabled != t.isMutedLocked())
+	priorityChanged := t.settings.GetPriority() != settings.GetPriority()
 	t.settings = proto.Clone(settings).(*livekit.UpdateTrackSettings)
 	t.settingsLock.Unlock()
 
+	if priorityChanged {
+		// not debounced, bandwidth should go to a track marked important right away
+		if onPriorityChange := t.onPriorityChange.Load(); onPriorityChange != nil {
+			onPriorityChange.(func())()
+		}
+	}
+
 	if isImmediate {
 		t.applySettings()
 	} else {
@@ -231,6 +264,27 @@ func (t *SubscribedTrack) UpdateSubscriberSettings(settings *livekit.UpdateTrack
 	}
 }
 
+// Priority maps subscription priority set by subscriber (1 being the highest, 0 unset) to
+// stream allocator priority (higher is more important, 0 to use the default of the track source)
+func (t *SubscribedTrack) Priority() uint8 {
+	t.settingsLock.Lock()
+	priority := t.settings.GetPriority()
+	t.settingsLock.Unlock()
+
+	switch {
+	case priority == 0:
+		return 0
+	case priority >= uint32(streamallocator.PriorityMax):
+		return streamallocator.PriorityMin
+	default:
+		return uint8(uint32(streamallocator.PriorityMax) + 1 - priority)
+	}
+}
+
+func (t *SubscribedTrack) OnPriorityChange(f func()) {
+	t.onPriorityChange.Store(f)
+}
+
 func (t *SubscribedTrack) UpdateVideoLayer() {
 	t.applySettings()
 }
@@ -270,7 +324,7 @@ func (t *SubscribedTrack) applySettings() {
 		return
 	}
 
-	if t.settings.Disabled {
+	if t.isMutedLocked() {
 		dt.Mute(true)
 		t.settingsLock.Unlock()
 		return
@@ -19,6 +19,7 @@ package rtc
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -75,6 +76,9 @@ type SubscriptionManager struct {
 	doneCh       chan struct{}
 
 	onSubscribeStatusChanged func(publisherID livekit.ParticipantID, subscribed bool)
+
+	// stats handed over on migration, final stats are reported by the node the participant migrates to
+	rtpStatsMigrated atomic.Bool
 }
 
 func NewSubscriptionManager(params SubscriptionManagerParams) *SubscriptionManager {
@@ -180,6 +184,21 @@ func (m *SubscriptionManager) GetSubscribedTracks() []types.SubscribedTrack {
 	return tracks
 }
 
+// GetMigrationRTPStats returns stats of subscribed tracks to be handed over on migration
+func (m *SubscriptionManager) GetMigrationRTPStats() map[livekit.TrackID]*livekit.RTPStats {
+	m.rtpStatsMigrated.Store(true)
+
+	stats := make(map[livekit.TrackID]*livekit.RTPStats)
+	for _, st := range m.GetSubscribedTracks() {
+		if dt := st.DownTrack(); dt != nil {
+			if s := dt.GetTrackStats(); s != nil {
+				stats[st.ID()] = s
+			}
+		}
+	}
+	return stats
+}
+
 func (m *SubscriptionManager) HasSubscriptions() bool {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
@@ -289,11 +308,29 @@ func (m *SubscriptionManager) reconcileSubscriptions() {
 	}
 	m.lock.RUnlock()
 
+	// higher priority subscriptions first, so that they get the slots when subscription limits are hit
+	sort.SliceStable(needsToReconcile, func(i, j int) bool {
+		return subscriptionPriorityLess(needsToReconcile[j].getPriority(), needsToReconcile[i].getPriority())
+	})
 	for _, s := range needsToReconcile {
 		m.reconcileSubscription(s)
 	}
 }
 
+// subscription priority is 1 being the highest, 0 is unset and lower than any set priority
+func subscriptionPriorityLess(a, b uint32) bool {
+	switch {
+	case a == b:
+		return false
+	case a == 0:
+		return true
+	case b == 0:
+		return false
+	default:
+		return a > b
+	}
+}
+
 func (m *SubscriptionManager) reconcileSubscription(s *trackSubscription) {
 	if !m.canReconcile() {
 		return
@@ -670,7 +707,7 @@ func (m *SubscriptionManager) handleSubscribedTrackClose(s *trackSubscription, i
 		)
 
 		dt := subTrack.DownTrack()
-		if dt != nil {
+		if dt != nil && !(isExpectedToResume && m.rtpStatsMigrated.Load()) {
 			stats := dt.GetTrackStats()
 			if stats != nil {
 				m.params.Telemetry.TrackSubscribeRTPStats(
@@ -982,6 +1019,12 @@ func (s *trackSubscription) maybeRecordSuccess(ts telemetry.TelemetryService, pI
 	ts.TrackSubscribed(context.Background(), pID, mediaTrack.ToProto(), pi, !eventSent)
 }
 
+func (s *trackSubscription) getPriority() uint32 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.settings.GetPriority()
+}
+
 func (s *trackSubscription) durationSinceStart() time.Duration {
 	t := s.subStartedAt.Load()
 	if t == nil {
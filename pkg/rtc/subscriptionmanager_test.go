@@ -17,6 +17,7 @@
 package rtc
 
 import (
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -447,6 +448,14 @@ func TestSubscriptionLimits(t *testing.T) {
 	require.Len(t, sm.GetSubscribedTracks(), 1)
 }
 
+func TestSubscriptionPriorityOrder(t *testing.T) {
+	priorities := []uint32{0, 3, 1, 0, 2}
+	sort.SliceStable(priorities, func(i, j int) bool {
+		return subscriptionPriorityLess(priorities[j], priorities[i])
+	})
+	require.Equal(t, []uint32{1, 2, 3, 0, 0}, priorities)
+}
+
 type testSubscriptionParams struct {
 	SubscriptionLimitAudio int32
 	SubscriptionLimitVideo int32
@@ -0,0 +1,224 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+// SyncAlignmentTopic is the data topic on which subscribers are sent capture time alignment of published tracks.
+//
+// Sender reports forwarded to subscribers map RTP time to the server clock at arrival, so tracks that take
+// different paths (audio and video of a publisher, or cameras of different publishers) are offset by
+// their delay from capture. Capture offset of a track is the difference between arrival (server clock)
+// and capture (publisher clock) of its latest sender report. Subtracting it from the NTP time of sender
+// reports received from the server gives capture time of media, comparable across tracks of the room.
+const SyncAlignmentTopic = "lk.sync_alignment"
+
+const (
+	syncAlignmentInterval = 5 * time.Second
+	// offsets moving less than this are not sent again
+	syncAlignmentMinChange = 5 * time.Millisecond
+	// tracks without a sender report within this are left out
+	syncAlignmentMaxSenderReportAge = 30 * time.Second
+)
+
+type trackSyncAlignment struct {
+	TrackSid        string  `json:"track_sid"`
+	CaptureOffsetMs float64 `json:"capture_offset_ms"`
+}
+
+type participantSyncAlignment struct {
+	ParticipantSid string `json:"participant_sid"`
+	Identity       string `json:"identity"`
+	// smallest capture offset of the tracks of a participant, an estimate of the offset of its clock
+	NtpOffsetMs float64               `json:"ntp_offset_ms"`
+	Tracks      []*trackSyncAlignment `json:"tracks"`
+}
+
+type syncAlignment struct {
+	Participants []*participantSyncAlignment `json:"participants"`
+}
+
+type senderReportProvider interface {
+	GetSenderReportData() *buffer.RTCPSenderReportData
+}
+
+func trackCaptureOffset(track types.MediaTrack, now time.Time) (time.Duration, bool) {
+	for _, receiver := range track.Receivers() {
+		srp, ok := receiver.(senderReportProvider)
+		if !ok {
+			continue
+		}
+
+		srData := srp.GetSenderReportData()
+		if srData == nil || now.Sub(srData.At) > syncAlignmentMaxSenderReportAge {
+			continue
+		}
+		return srData.PropagationDelay(false), true
+	}
+	return 0, false
+}
+
+func getParticipantSyncAlignment(p types.Participant, now time.Time) *participantSyncAlignment {
+	var (
+		tracks    []*trackSyncAlignment
+		ntpOffset = time.Duration(math.MaxInt64)
+	)
+	for _, track := range p.GetPublishedTracks() {
+		offset, ok := trackCaptureOffset(track, now)
+		if !ok {
+			continue
+		}
+
+		tracks = append(tracks, &trackSyncAlignment{
+			TrackSid:        string(track.ID()),
+			CaptureOffsetMs: durationToMs(offset),
+		})
+		ntpOffset = min(ntpOffset, offset)
+	}
+	if len(tracks) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(tracks, func(a, b *trackSyncAlignment) int {
+		switch {
+		case a.TrackSid < b.TrackSid:
+			return -1
+		case a.TrackSid > b.TrackSid:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return &participantSyncAlignment{
+		ParticipantSid: string(p.ID()),
+		Identity:       string(p.Identity()),
+		NtpOffsetMs:    durationToMs(ntpOffset),
+		Tracks:         tracks,
+	}
+}
+
+func durationToMs(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000.0
+}
+
+// isSyncAlignmentChanged returns true when tracks have been added/removed or an offset has moved by at least the minimum change
+func isSyncAlignmentChanged(prev *syncAlignment, curr *syncAlignment) bool {
+	if prev == nil {
+		return curr != nil && len(curr.Participants) != 0
+	}
+
+	prevOffsets := make(map[string]float64)
+	for _, p := range prev.Participants {
+		for _, t := range p.Tracks {
+			prevOffsets[t.TrackSid] = t.CaptureOffsetMs
+		}
+	}
+
+	numTracks := 0
+	for _, p := range curr.Participants {
+		for _, t := range p.Tracks {
+			numTracks++
+			prevOffset, ok := prevOffsets[t.TrackSid]
+			if !ok || math.Abs(t.CaptureOffsetMs-prevOffset) >= durationToMs(syncAlignmentMinChange) {
+				return true
+			}
+		}
+	}
+	return numTracks != len(prevOffsets)
+}
+
+func encodeSyncAlignment(alignment *syncAlignment) ([]byte, error) {
+	payload, err := json.Marshal(alignment)
+	if err != nil {
+		return nil, err
+	}
+
+	topic := SyncAlignmentTopic
+	return proto.Marshal(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   &topic,
+			},
+		},
+	})
+}
+
+// syncAlignmentWorker sends alignment to subscribers when it changes, and to participants that have not received it yet
+func (r *Room) syncAlignmentWorker() {
+	ticker := time.NewTicker(syncAlignmentInterval)
+	defer ticker.Stop()
+
+	var (
+		lastAlignment *syncAlignment
+		lastEncoded   []byte
+		sentTo        = make(map[livekit.ParticipantID]bool)
+	)
+	for !r.IsClosed() {
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		}
+
+		participants := r.GetParticipants()
+		now := time.Now()
+		alignment := &syncAlignment{}
+		for _, p := range participants {
+			if pa := getParticipantSyncAlignment(p, now); pa != nil {
+				alignment.Participants = append(alignment.Participants, pa)
+			}
+		}
+
+		if isSyncAlignmentChanged(lastAlignment, alignment) {
+			encoded, err := encodeSyncAlignment(alignment)
+			if err != nil {
+				r.Logger.Errorw("could not encode sync alignment", err)
+				continue
+			}
+			lastAlignment = alignment
+			lastEncoded = encoded
+			clear(sentTo)
+		}
+		if lastEncoded == nil {
+			continue
+		}
+
+		for _, p := range participants {
+			if sentTo[p.ID()] || p.State() != livekit.ParticipantInfo_ACTIVE ||
+				!p.HasClientCapability(types.ClientCapabilitySyncAlignment) || !p.IsInterestedInDataTopic(SyncAlignmentTopic) {
+				continue
+			}
+
+			if err := p.SendDataPacket(livekit.DataPacket_RELIABLE, lastEncoded); err != nil {
+				p.GetLogger().Debugw("could not send sync alignment", "error", err)
+				continue
+			}
+			sentTo[p.ID()] = true
+		}
+	}
+}
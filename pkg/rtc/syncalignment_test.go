@@ -0,0 +1,97 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/mediatransportutil"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+type senderReportReceiver struct {
+	sfu.TrackReceiver
+	srData *buffer.RTCPSenderReportData
+}
+
+func (r *senderReportReceiver) GetSenderReportData() *buffer.RTCPSenderReportData {
+	return r.srData
+}
+
+func newSyncAlignmentTrack(trackID livekit.TrackID, at time.Time, captureOffset time.Duration) *typesfakes.FakeMediaTrack {
+	track := &typesfakes.FakeMediaTrack{}
+	track.IDReturns(trackID)
+	track.ReceiversReturns([]sfu.TrackReceiver{&senderReportReceiver{
+		srData: &buffer.RTCPSenderReportData{
+			NTPTimestamp: mediatransportutil.ToNtpTime(at.Add(-captureOffset)),
+			At:           at,
+			AtAdjusted:   at,
+		},
+	}})
+	return track
+}
+
+func TestSyncAlignment(t *testing.T) {
+	now := time.Now()
+
+	p := &typesfakes.FakeLocalParticipant{}
+	p.IDReturns("PA_pub")
+	p.IdentityReturns("pub")
+	p.GetPublishedTracksReturns([]types.MediaTrack{
+		newSyncAlignmentTrack("TR_video", now, 120*time.Millisecond),
+		newSyncAlignmentTrack("TR_audio", now, 80*time.Millisecond),
+		// stale sender report
+		newSyncAlignmentTrack("TR_stale", now.Add(-time.Minute), 10*time.Millisecond),
+	})
+
+	pa := getParticipantSyncAlignment(p, now)
+	require.NotNil(t, pa)
+	require.Equal(t, "PA_pub", pa.ParticipantSid)
+	require.Len(t, pa.Tracks, 2)
+	require.Equal(t, "TR_audio", pa.Tracks[0].TrackSid)
+	require.InDelta(t, 80.0, pa.Tracks[0].CaptureOffsetMs, 0.01)
+	require.Equal(t, "TR_video", pa.Tracks[1].TrackSid)
+	require.InDelta(t, 120.0, pa.Tracks[1].CaptureOffsetMs, 0.01)
+	require.InDelta(t, 80.0, pa.NtpOffsetMs, 0.01)
+
+	// no tracks with sender reports
+	require.Nil(t, getParticipantSyncAlignment(&typesfakes.FakeLocalParticipant{}, now))
+
+	t.Run("changes", func(t *testing.T) {
+		alignment := func(offsets map[string]float64) *syncAlignment {
+			pa := &participantSyncAlignment{ParticipantSid: "PA_pub"}
+			for trackSid, offset := range offsets {
+				pa.Tracks = append(pa.Tracks, &trackSyncAlignment{TrackSid: trackSid, CaptureOffsetMs: offset})
+			}
+			return &syncAlignment{Participants: []*participantSyncAlignment{pa}}
+		}
+
+		prev := alignment(map[string]float64{"TR_audio": 80, "TR_video": 120})
+		require.True(t, isSyncAlignmentChanged(nil, prev))
+		require.False(t, isSyncAlignmentChanged(nil, &syncAlignment{}))
+		require.False(t, isSyncAlignmentChanged(prev, alignment(map[string]float64{"TR_audio": 82, "TR_video": 118})))
+		require.True(t, isSyncAlignmentChanged(prev, alignment(map[string]float64{"TR_audio": 80, "TR_video": 126})))
+		require.True(t, isSyncAlignmentChanged(prev, alignment(map[string]float64{"TR_audio": 80})))
+		require.True(t, isSyncAlignmentChanged(prev, alignment(map[string]float64{"TR_audio": 80, "TR_video": 120, "TR_screen": 100})))
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3"
+	"golang.org/x/exp/slices"
+
+	"github.com/livekit/protocol/livekit"
+	lksdp "github.com/livekit/protocol/sdp"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const sdpAttrKeyRid = "rid"
+
+// trackMappingsFromSDP extracts track mappings from m-lines of a session description.
+// Track id is taken from media level msid or, for clients/servers signalling the legacy format, from ssrc msid.
+func trackMappingsFromSDP(sd webrtc.SessionDescription) ([]*types.TrackMapping, error) {
+	parsed, err := sd.Unmarshal()
+	if err != nil {
+		return nil, err
+	}
+
+	var mappings []*types.TrackMapping
+	for _, m := range parsed.MediaDescriptions {
+		tm := &types.TrackMapping{}
+		ssrcTrackID := ""
+		for _, a := range m.Attributes {
+			switch a.Key {
+			case sdp.AttrKeyMsid:
+				tm.TrackID = livekit.TrackID(trackIDFromMsid(a.Value))
+
+			case sdpAttrKeyRid:
+				// a=rid:<rid> send
+				if rid, direction, _ := strings.Cut(a.Value, " "); strings.HasPrefix(direction, "send") {
+					tm.Rids = append(tm.Rids, rid)
+				}
+
+			case sdp.AttrKeySSRC:
+				// a=ssrc:<ssrc> <attribute>:<value>
+				ssrcStr, attr, _ := strings.Cut(a.Value, " ")
+				ssrc, err := strconv.ParseUint(ssrcStr, 10, 32)
+				if err != nil {
+					continue
+				}
+				if !slices.Contains(tm.SSRCs, uint32(ssrc)) {
+					tm.SSRCs = append(tm.SSRCs, uint32(ssrc))
+				}
+				if name, value, _ := strings.Cut(attr, ":"); name == sdp.AttrKeyMsid && ssrcTrackID == "" {
+					ssrcTrackID = trackIDFromMsid(value)
+				}
+			}
+		}
+		if tm.TrackID == "" {
+			tm.TrackID = livekit.TrackID(ssrcTrackID)
+		}
+		if tm.TrackID == "" {
+			continue
+		}
+
+		tm.Mid = lksdp.GetMidValue(m)
+		if tm.Mid == "" {
+			return nil, ErrMidNotFound
+		}
+		mappings = append(mappings, tm)
+	}
+	return mappings, nil
+}
+
+// trackIDFromMsid returns the track id of "<stream id> <track id>"
+func trackIDFromMsid(msid string) string {
+	split := strings.Fields(msid)
+	if len(split) != 2 {
+		return ""
+	}
+	return split[1]
+}
+
+// resolveTrackMappingMid returns the mid of the restored transceiver a track should be bound to.
+// The mapped mid is used if it was restored from previous answer, otherwise the track is
+// located in previous offer by its SSRCs, which do not change across migration.
+func resolveTrackMappingMid(tm *types.TrackMapping, offerMappings []*types.TrackMapping, senders map[string]*webrtc.RTPSender) string {
+	if _, ok := senders[tm.Mid]; ok && tm.Mid != "" {
+		return tm.Mid
+	}
+
+	for _, om := range offerMappings {
+		if _, ok := senders[om.Mid]; !ok {
+			continue
+		}
+		for _, ssrc := range tm.SSRCs {
+			if slices.Contains(om.SSRCs, ssrc) {
+				return om.Mid
+			}
+		}
+	}
+	return ""
+}
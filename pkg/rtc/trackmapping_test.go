@@ -0,0 +1,99 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const trackMappingOffer = "v=0\r\n" +
+	"o=- 0 0 IN IP4 127.0.0.1\r\n" +
+	"s=-\r\n" +
+	"t=0 0\r\n" +
+	"a=group:BUNDLE 0 1 2 3\r\n" +
+	"m=audio 9 UDP/TLS/RTP/SAVPF 111\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=mid:0\r\n" +
+	"a=sendonly\r\n" +
+	"a=msid:PA_audio TR_audio\r\n" +
+	"a=ssrc:1001 cname:PA_audio\r\n" +
+	"a=rtpmap:111 opus/48000/2\r\n" +
+	"m=video 9 UDP/TLS/RTP/SAVPF 96 97\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=mid:1\r\n" +
+	"a=sendonly\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n" +
+	"a=rtpmap:97 rtx/90000\r\n" +
+	"a=ssrc-group:FID 2001 2002\r\n" +
+	"a=ssrc:2001 cname:PA_video\r\n" +
+	"a=ssrc:2001 msid:PA_video TR_video\r\n" +
+	"a=ssrc:2002 cname:PA_video\r\n" +
+	"a=ssrc:2002 msid:PA_video TR_video\r\n" +
+	"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=mid:2\r\n" +
+	"a=sendonly\r\n" +
+	"a=msid:PA_screen TR_screen\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n" +
+	"a=rid:q send\r\n" +
+	"a=rid:h send\r\n" +
+	"a=rid:f recv\r\n" +
+	"m=video 9 UDP/TLS/RTP/SAVPF 96\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=mid:3\r\n" +
+	"a=inactive\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n"
+
+func TestTrackMappingsFromSDP(t *testing.T) {
+	mappings, err := trackMappingsFromSDP(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: trackMappingOffer})
+	require.NoError(t, err)
+	require.Equal(t, []*types.TrackMapping{
+		{TrackID: "TR_audio", Mid: "0", SSRCs: []uint32{1001}},
+		{TrackID: "TR_video", Mid: "1", SSRCs: []uint32{2001, 2002}},
+		{TrackID: "TR_screen", Mid: "2", Rids: []string{"q", "h"}},
+	}, mappings)
+}
+
+func TestResolveTrackMappingMid(t *testing.T) {
+	offerMappings, err := trackMappingsFromSDP(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: trackMappingOffer})
+	require.NoError(t, err)
+
+	// mids restored from previous answer
+	senders := map[string]*webrtc.RTPSender{"0": nil, "1": nil, "2": nil, "3": nil}
+
+	t.Run("mapped mid", func(t *testing.T) {
+		// mapping takes precedence over previous offer
+		tm := &types.TrackMapping{TrackID: "TR_video", Mid: "3", SSRCs: []uint32{2001}}
+		require.Equal(t, "3", resolveTrackMappingMid(tm, offerMappings, senders))
+	})
+
+	t.Run("mid not restored", func(t *testing.T) {
+		tm := &types.TrackMapping{TrackID: "TR_video", Mid: "5", SSRCs: []uint32{2002}}
+		require.Equal(t, "1", resolveTrackMappingMid(tm, offerMappings, senders))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		tm := &types.TrackMapping{TrackID: "TR_other", Mid: "5", SSRCs: []uint32{3001}}
+		require.Empty(t, resolveTrackMappingMid(tm, offerMappings, senders))
+
+		tm = &types.TrackMapping{TrackID: "TR_other"}
+		require.Empty(t, resolveTrackMappingMid(tm, offerMappings, senders))
+	})
+}
@@ -15,8 +15,10 @@
 package rtc
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,7 +29,6 @@ import (
 	"github.com/pion/ice/v2"
 	"github.com/pion/interceptor"
 	"github.com/pion/interceptor/pkg/cc"
-	"github.com/pion/interceptor/pkg/gcc"
 	"github.com/pion/interceptor/pkg/twcc"
 	"github.com/pion/rtcp"
 	"github.com/pion/sctp"
@@ -42,16 +43,21 @@ import (
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/logger/pionlogger"
 	lksdp "github.com/livekit/protocol/sdp"
+	"github.com/livekit/protocol/tracer"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/pcap"
 	"github.com/livekit/livekit-server/pkg/rtc/transport"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
 	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
 	pd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/playoutdelay"
 	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 	sfuutils "github.com/livekit/livekit-server/pkg/sfu/utils"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/tracing"
 	"github.com/livekit/livekit-server/pkg/utils"
 )
 
@@ -83,6 +89,7 @@ var (
 	ErrNoTransceiver                    = errors.New("no transceiver")
 	ErrNoSender                         = errors.New("no sender")
 	ErrMidNotFound                      = errors.New("mid not found")
+	ErrClosedBeforeConnected            = errors.New("transport closed before connected")
 )
 
 // -------------------------------------------------------------------------
@@ -96,6 +103,7 @@ const (
 	signalSendOffer
 	signalRemoteDescriptionReceived
 	signalICERestart
+	signalICEIPFamilyHeadStartElapsed
 )
 
 func (s signal) String() string {
@@ -112,6 +120,8 @@ func (s signal) String() string {
 		return "REMOTE_DESCRIPTION_RECEIVED"
 	case signalICERestart:
 		return "ICE_RESTART"
+	case signalICEIPFamilyHeadStartElapsed:
+		return "ICE_IP_FAMILY_HEAD_START_ELAPSED"
 	default:
 		return fmt.Sprintf("%d", int(s))
 	}
@@ -153,13 +163,16 @@ type PCTransport struct {
 	firstOfferNoDataChannel bool
 	reliableDC              *webrtc.DataChannel
 	reliableDCOpened        bool
+	reliableDCSendQueue     *dataChannelSendQueue
 	lossyDC                 *webrtc.DataChannel
 	lossyDCOpened           bool
+	lossyDCSendQueue        *dataChannelSendQueue
 
 	iceStartedAt               time.Time
 	iceConnectedAt             time.Time
 	firstConnectedAt           time.Time
 	connectedAt                time.Time
+	connectSpan                tracer.Span // from creation until initial connection
 	tcpICETimer                *time.Timer
 	connectAfterICETimer       *time.Timer // timer to wait for pc to connect after ice connected
 	resetShortConnOnICERestart atomic.Bool
@@ -176,13 +189,27 @@ type PCTransport struct {
 	// only for subscriber PC
 	pacer pacer.Pacer
 
+	// batches RTCP into compound packets when enabled
+	rtcpScheduler *RTCPScheduler
+
+	// primary SSRC -> RTX repair SSRC signalled in the last offer
+	rtxSSRCs map[uint32]uint32
+
 	previousAnswer *webrtc.SessionDescription
 	// track id -> description map in previous offer sdp
 	previousTrackDescription map[string]*trackDescription
 	canReuseTransceiver      bool
+	// track id -> transceiver reserved for a track that is yet to be published
+	placeholderTransceivers map[string]*webrtc.RTPTransceiver
+	// transceivers left dormant by removed tracks since the last offer
+	numPendingDormantTransceivers int
+	// stopped transceivers signalled inactive in the outstanding offer, recycled once answered
+	offeredStoppedTransceivers []*webrtc.RTPTransceiver
 
 	preferTCP atomic.Bool
 	isClosed  atomic.Bool
+	// IP family of the first connected pair, remote candidates of the other family are pruned when enabled
+	connectedICEIPFamily atomic.String
 
 	eventsQueue *utils.TypedOpsQueue[event]
 
@@ -197,8 +224,22 @@ type PCTransport struct {
 	signalStateCheckTimer     *time.Timer
 	currentOfferIceCredential string // ice user:pwd, for publish side ice restart checking
 	pendingRestartIceOffer    *webrtc.SessionDescription
+	lossBasedEstimation       bool
+	// remote candidates of the non-preferred IP family, held back until the preferred family's head start elapses
+	heldRemoteCandidates        []*webrtc.ICECandidateInit
+	iceIPFamilyHeadStartTimer   *time.Timer
+	iceIPFamilyHeadStartElapsed bool
+	// incremented on every head start, timer events of a previous ICE generation are ignored
+	iceIPFamilyHeadStartGeneration uint32
 
 	connectionDetails *types.ICEConnectionDetails
+
+	packetCaptureTap *packetCaptureTap
+
+	// impairs media received on and sent from this transport when network conditions are simulated
+	netEmulator *netem.Emulator
+
+	negotiationTrace *negotiationTrace
 }
 
 type TransportParams struct {
@@ -211,6 +252,7 @@ type TransportParams struct {
 	DirectionConfig              DirectionConfig
 	CongestionControlConfig      config.CongestionControlConfig
 	EnabledCodecs                []*livekit.Codec
+	CodecPreferenceOrder         bool // video codecs are registered in order of EnabledCodecs
 	Logger                       logger.Logger
 	Transport                    livekit.SignalTarget
 	SimTracks                    map[uint32]SimulcastTrackInfo
@@ -219,24 +261,40 @@ type TransportParams struct {
 	IsSendSide                   bool
 	AllowPlayoutDelay            bool
 	DataChannelMaxBufferedAmount uint64
+	PreferTCP                    bool
+	// negotiation spans are children of the span in this context, the participant's join
+	TraceContext context.Context
 }
 
-func newPeerConnection(params TransportParams, onBandwidthEstimator func(estimator cc.BandwidthEstimator)) (*webrtc.PeerConnection, *webrtc.MediaEngine, error) {
+func newPeerConnection(
+	params TransportParams,
+	captureTap *packetCaptureTap,
+	netEmulator *netem.Emulator,
+	onBandwidthEstimator func(estimator cc.BandwidthEstimator),
+) (*webrtc.PeerConnection, *webrtc.MediaEngine, error) {
 	directionConfig := params.DirectionConfig
-	if params.AllowPlayoutDelay {
-		directionConfig.RTPHeaderExtension.Video = append(directionConfig.RTPHeaderExtension.Video, pd.PlayoutDelayURI)
+	if params.AllowPlayoutDelay && !directionConfig.RTPHeaderExtension.IsDisabled(pd.PlayoutDelayURI) &&
+		!slices.Contains(directionConfig.RTPHeaderExtension.Video, pd.PlayoutDelayURI) {
+		directionConfig.RTPHeaderExtension.Video = append(slices.Clone(directionConfig.RTPHeaderExtension.Video), pd.PlayoutDelayURI)
 	}
 
 	// Some of the browser clients do not handle H.264 High Profile in signalling properly.
 	// They still decode if the actual stream is H.264 High Profile, but do not handle it well in signalling.
 	// So, disable H.264 High Profile for SUBSCRIBER peer connection to ensure it is not offered.
-	me, err := createMediaEngine(params.EnabledCodecs, directionConfig, params.IsOfferer)
+	me, err := createMediaEngine(params.EnabledCodecs, directionConfig, params.IsOfferer, params.CodecPreferenceOrder)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	se := params.Config.SettingEngine
 	se.DisableMediaEngineCopy(true)
+	se.BufferFactory = captureTap.wrapBufferFactory(wrapNetEmBufferFactory(netEmulator, se.BufferFactory))
+
+	// dial out to passive TCP candidates of clients that are known to prefer TCP,
+	// they may be behind a firewall which blocks inbound connections on the server's TCP port
+	if params.Config.EnableActiveTCP && params.PreferTCP {
+		se.DisableActiveTCP(false)
+	}
 
 	// Change elliptic curve to improve connectivity
 	// https://github.com/pion/dtls/pull/474
@@ -259,8 +317,13 @@ func newPeerConnection(params TransportParams, onBandwidthEstimator func(estimat
 	//
 	// NOTE: It is not required to disable RTCP replay protection, but doing it to be symmetric.
 	//
-	se.DisableSRTPReplayProtection(true)
-	se.DisableSRTCPReplayProtection(true)
+	// When RTX is negotiated on the subscriber peer connection, retransmissions are sent on
+	// the repair stream and replay protection can stay enabled.
+	//
+	if !params.IsSendSide || !IsCodecEnabled(params.EnabledCodecs, videoRTX) {
+		se.DisableSRTPReplayProtection(true)
+		se.DisableSRTCPReplayProtection(true)
+	}
 	if !params.ProtocolVersion.SupportsICELite() {
 		se.SetLite(false)
 	}
@@ -303,14 +366,19 @@ func newPeerConnection(params TransportParams, onBandwidthEstimator func(estimat
 	}
 
 	ir := &interceptor.Registry{}
+	// first in registry is innermost, sees packets as they go out
+	ir.Add(captureTap)
 	if params.IsSendSide {
 		se.DetachDataChannels()
 		if params.CongestionControlConfig.UseSendSideBWE {
+			bweFactory, err := streamallocator.GetBandwidthEstimatorFactory(params.CongestionControlConfig.BandwidthEstimator)
+			if err != nil {
+				return nil, nil, err
+			}
 			gf, err := cc.NewInterceptor(func() (cc.BandwidthEstimator, error) {
-				return gcc.NewSendSideBWE(
-					gcc.SendSideBWEInitialBitrate(1*1000*1000),
-					gcc.SendSideBWEPacer(gcc.NewNoOpPacer()),
-				)
+				return bweFactory(streamallocator.BandwidthEstimatorParams{
+					Logger: params.Logger.WithComponent(utils.ComponentCongestionControl),
+				})
 			})
 			if err == nil {
 				gf.OnNewPeerConnection(func(id string, estimator cc.BandwidthEstimator) {
@@ -340,36 +408,22 @@ func newPeerConnection(params TransportParams, onBandwidthEstimator func(estimat
 		}
 	}
 
-	setTWCCForVideo := func(info *interceptor.StreamInfo) {
-		if !strings.HasPrefix(info.MimeType, "video") {
-			return
-		}
-		// rtx stream don't have rtcp feedback, always set twcc for rtx stream
-		twccFb := strings.HasSuffix(info.MimeType, "rtx")
-		if !twccFb {
-			for _, fb := range info.RTCPFeedback {
-				if fb.Type == webrtc.TypeRTCPFBTransportCC {
-					twccFb = true
-					break
-				}
-			}
-		}
-		if !twccFb {
+	// feed transport-wide sequence numbers of incoming streams to responder which generates feedback
+	setTWCC := func(info *interceptor.StreamInfo) {
+		twccExtID := twccExtIDForStream(info)
+		if twccExtID == 0 {
 			return
 		}
 
-		twccExtID := sfuutils.GetHeaderExtensionID(info.RTPHeaderExtensions, webrtc.RTPHeaderExtensionCapability{URI: sdp.TransportCCURI})
-		if twccExtID != 0 {
-			if buffer := params.Config.BufferFactory.GetBuffer(info.SSRC); buffer != nil {
-				params.Logger.Debugw("set rtx twcc and ext id", "ssrc", info.SSRC, "twccExtID", twccExtID)
-				buffer.SetTWCCAndExtID(params.Twcc, uint8(twccExtID))
-			} else {
-				params.Logger.Warnw("failed to get buffer for rtx stream", nil, "ssrc", info.SSRC)
-			}
+		if buffer := params.Config.BufferFactory.GetBuffer(info.SSRC); buffer != nil {
+			params.Logger.Debugw("set twcc and ext id", "ssrc", info.SSRC, "twccExtID", twccExtID)
+			buffer.SetTWCCAndExtID(params.Twcc, twccExtID)
+		} else {
+			params.Logger.Warnw("failed to get buffer for stream", nil, "ssrc", info.SSRC)
 		}
 	}
 	// put rtx interceptor behind unhandle simulcast interceptor so it can get the correct mid & rid
-	ir.Add(sfuinterceptor.NewRTXInfoExtractorFactory(setTWCCForVideo, func(repair, base uint32) {
+	ir.Add(sfuinterceptor.NewRTXInfoExtractorFactory(setTWCC, func(repair, base uint32) {
 		params.Logger.Debugw("rtx pair found from extension", "repair", repair, "base", base)
 		params.Config.BufferFactory.SetRTXPair(repair, base)
 	}, params.Logger))
@@ -397,8 +451,13 @@ func NewPCTransport(params TransportParams) (*PCTransport, error) {
 		}),
 		previousTrackDescription: make(map[string]*trackDescription),
 		canReuseTransceiver:      true,
+		placeholderTransceivers:  make(map[string]*webrtc.RTPTransceiver),
 		connectionDetails:        types.NewICEConnectionDetails(params.Transport, params.Logger),
+		packetCaptureTap:         newPacketCaptureTap(params.Transport),
+		netEmulator:              netem.NewEmulator(),
+		negotiationTrace:         newNegotiationTrace(negotiationTraceSize),
 	}
+	t.preferTCP.Store(params.PreferTCP)
 	if params.IsSendSide {
 		t.streamAllocator = streamallocator.NewStreamAllocator(streamallocator.StreamAllocatorParams{
 			Config: params.CongestionControlConfig,
@@ -406,12 +465,21 @@ func NewPCTransport(params TransportParams) (*PCTransport, error) {
 		})
 		t.streamAllocator.OnStreamStateChange(params.Handler.OnStreamStateChange)
 		t.streamAllocator.Start()
-		t.pacer = pacer.NewPassThrough(params.Logger)
+		t.pacer = pacer.NewNetEm(pacer.NewPassThrough(params.Logger), t.netEmulator)
 	}
 
 	if err := t.createPeerConnection(); err != nil {
 		return nil, err
 	}
+	t.connectSpan = t.startSpan("PCTransport.connect")
+
+	if params.Config != nil && params.Config.RTCPBatchInterval > 0 {
+		t.rtcpScheduler = NewRTCPScheduler(RTCPSchedulerParams{
+			Interval: params.Config.RTCPBatchInterval,
+			Writer:   t.pc.WriteRTCP,
+			Logger:   params.Logger,
+		})
+	}
 
 	t.eventsQueue.Start()
 
@@ -420,7 +488,7 @@ func NewPCTransport(params TransportParams) (*PCTransport, error) {
 
 func (t *PCTransport) createPeerConnection() error {
 	var bwe cc.BandwidthEstimator
-	pc, me, err := newPeerConnection(t.params, func(estimator cc.BandwidthEstimator) {
+	pc, me, err := newPeerConnection(t.params, t.packetCaptureTap, t.netEmulator, func(estimator cc.BandwidthEstimator) {
 		bwe = estimator
 	})
 	if err != nil {
@@ -450,6 +518,14 @@ func (t *PCTransport) GetPacer() pacer.Pacer {
 	return t.pacer
 }
 
+// GetRTXSSRC returns the SSRC of the RTX repair stream signalled for the primary SSRC, 0 if none was signalled
+func (t *PCTransport) GetRTXSSRC(primarySSRC uint32) uint32 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.rtxSSRCs[primarySSRC]
+}
+
 func (t *PCTransport) SetSignalingRTT(rtt uint32) {
 	t.signalingRTT.Store(rtt)
 }
@@ -634,6 +710,11 @@ func (t *PCTransport) onICEConnectionStateChange(state webrtc.ICEConnectionState
 				return
 			}
 			t.connectionDetails.SetSelectedPair(pair)
+
+			family := types.ICEIPFamilyOfAddress(pair.Remote.Address)
+			if family != types.ICEIPFamilyUnknown && t.connectedICEIPFamily.CompareAndSwap("", string(family)) && t.params.Config.ICEIPFamily.Prune {
+				t.params.Logger.Debugw("pruning remote candidates of other IP family", "family", family)
+			}
 		}()
 
 	case webrtc.ICEConnectionStateChecking:
@@ -648,6 +729,7 @@ func (t *PCTransport) onPeerConnectionStateChange(state webrtc.PeerConnectionSta
 		t.clearConnTimer()
 		isInitialConnection := t.setConnectedAt(time.Now())
 		if isInitialConnection {
+			t.connectSpan.End()
 			t.params.Handler.OnInitialConnected()
 
 			t.maybeNotifyFullyEstablished()
@@ -665,6 +747,7 @@ func (t *PCTransport) onDataChannel(dc *webrtc.DataChannel) {
 		t.lock.Lock()
 		t.reliableDC = dc
 		t.reliableDCOpened = true
+		t.reliableDCSendQueue = t.newDataChannelSendQueue(livekit.DataPacket_RELIABLE, dc)
 		t.lock.Unlock()
 		dc.OnMessage(func(msg webrtc.DataChannelMessage) {
 			t.params.Handler.OnDataPacket(livekit.DataPacket_RELIABLE, msg.Data)
@@ -675,6 +758,7 @@ func (t *PCTransport) onDataChannel(dc *webrtc.DataChannel) {
 		t.lock.Lock()
 		t.lossyDC = dc
 		t.lossyDCOpened = true
+		t.lossyDCSendQueue = t.newDataChannelSendQueue(livekit.DataPacket_LOSSY, dc)
 		t.lock.Unlock()
 		dc.OnMessage(func(msg webrtc.DataChannelMessage) {
 			t.params.Handler.OnDataPacket(livekit.DataPacket_LOSSY, msg.Data)
@@ -726,6 +810,10 @@ func (t *PCTransport) AddICECandidate(candidate webrtc.ICECandidateInit) {
 }
 
 func (t *PCTransport) AddTrack(trackLocal webrtc.TrackLocal, params types.AddTrackParams) (sender *webrtc.RTPSender, transceiver *webrtc.RTPTransceiver, err error) {
+	if sender, transceiver = t.takePlaceholderTrack(trackLocal, params); transceiver != nil {
+		return
+	}
+
 	t.lock.Lock()
 	canReuse := t.canReuseTransceiver
 	td, ok := t.previousTrackDescription[trackLocal.ID()]
@@ -771,6 +859,10 @@ func (t *PCTransport) AddTrack(trackLocal webrtc.TrackLocal, params types.AddTra
 }
 
 func (t *PCTransport) AddTransceiverFromTrack(trackLocal webrtc.TrackLocal, params types.AddTrackParams) (sender *webrtc.RTPSender, transceiver *webrtc.RTPTransceiver, err error) {
+	if sender, transceiver = t.takePlaceholderTrack(trackLocal, params); transceiver != nil {
+		return
+	}
+
 	transceiver, err = t.pc.AddTransceiverFromTrack(trackLocal)
 	if err != nil {
 		return
@@ -788,7 +880,121 @@ func (t *PCTransport) AddTransceiverFromTrack(trackLocal webrtc.TrackLocal, para
 }
 
 func (t *PCTransport) RemoveTrack(sender *webrtc.RTPSender) error {
-	return t.pc.RemoveTrack(sender)
+	if err := t.pc.RemoveTrack(sender); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	t.numPendingDormantTransceivers++
+	numPending := t.numPendingDormantTransceivers
+	t.lock.Unlock()
+
+	// a removed track's transceiver can be re-used by AddTrack only after remote has seen it go inactive,
+	// when tracks churn faster than negotiation, new transceivers are added instead and SDP keeps growing,
+	// renegotiate right away to get dormant transceivers ready for re-use
+	if maxDormant := t.params.Config.MaxDormantTransceivers; maxDormant > 0 && numPending >= maxDormant {
+		t.params.Logger.Infow("dormant transceivers at limit, renegotiating", "numDormant", numPending)
+		t.Negotiate(true)
+	}
+	return nil
+}
+
+// stoppedTransceivers returns transceivers stopped while attached to a sender,
+// pion does not re-use those for new tracks as the stopped sender stays attached
+func (t *PCTransport) stoppedTransceivers() []*webrtc.RTPTransceiver {
+	var stopped []*webrtc.RTPTransceiver
+	for _, tr := range t.pc.GetTransceivers() {
+		// stopping is the only way for a transceiver with a sender to become inactive
+		if tr.Sender() != nil && tr.Direction() == webrtc.RTPTransceiverDirectionInactive {
+			stopped = append(stopped, tr)
+		}
+	}
+	return stopped
+}
+
+// recycleStoppedTransceivers detaches stopped senders after remote has answered an offer with their transceivers
+// inactive, making the transceivers (and their mids) available to AddTrack
+func (t *PCTransport) recycleStoppedTransceivers(transceivers []*webrtc.RTPTransceiver) {
+	for _, tr := range transceivers {
+		sender := tr.Sender()
+		if sender == nil {
+			continue
+		}
+
+		// removing stopped sender errors on direction change as it is already inactive, sender is detached nevertheless
+		_ = t.pc.RemoveTrack(sender)
+		if tr.Sender() == nil {
+			t.params.Logger.Debugw("recycled stopped transceiver", "mid", tr.Mid(), "kind", tr.Kind())
+		}
+	}
+}
+
+// AddPlaceholderTrack reserves a transceiver for a track that is yet to be published,
+// the transceiver is negotiated ahead of time and taken over when the track is added
+func (t *PCTransport) AddPlaceholderTrack(trackLocal webrtc.TrackLocal) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.placeholderTransceivers[trackLocal.ID()]; ok {
+		return nil
+	}
+
+	transceiver, err := t.pc.AddTransceiverFromTrack(trackLocal)
+	if err != nil {
+		return err
+	}
+	t.placeholderTransceivers[trackLocal.ID()] = transceiver
+	return nil
+}
+
+// RemovePlaceholderTrack releases the transceiver reserved for a track that did not get published,
+// returns true if a transceiver was released
+func (t *PCTransport) RemovePlaceholderTrack(trackID string) bool {
+	t.lock.Lock()
+	transceiver, ok := t.placeholderTransceivers[trackID]
+	delete(t.placeholderTransceivers, trackID)
+	t.lock.Unlock()
+
+	if !ok {
+		return false
+	}
+	if sender := transceiver.Sender(); sender != nil {
+		if err := t.pc.RemoveTrack(sender); err != nil {
+			t.params.Logger.Warnw("could not remove placeholder track", err, "trackID", trackID)
+		}
+	}
+	return true
+}
+
+func (t *PCTransport) takePlaceholderTrack(trackLocal webrtc.TrackLocal, params types.AddTrackParams) (*webrtc.RTPSender, *webrtc.RTPTransceiver) {
+	t.lock.Lock()
+	transceiver, ok := t.placeholderTransceivers[trackLocal.ID()]
+	delete(t.placeholderTransceivers, trackLocal.ID())
+	t.lock.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	sender := transceiver.Sender()
+	if sender != nil {
+		// replaced track will bind immediately if already negotiated, SetTransceiver first before bind
+		if ts, ok := trackLocal.(interface{ SetTransceiver(*webrtc.RTPTransceiver) }); ok {
+			ts.SetTransceiver(transceiver)
+		}
+		err := sender.ReplaceTrack(trackLocal)
+		if err == nil {
+			configureAudioTransceiver(transceiver, params.Stereo, !params.Red || !t.params.ClientInfo.SupportsAudioRED())
+			t.params.Logger.Debugw("placeholder track replaced", "trackID", trackLocal.ID(), "mid", transceiver.Mid())
+			return sender, transceiver
+		}
+
+		t.params.Logger.Infow("could not replace placeholder track", "error", err, "trackID", trackLocal.ID())
+		if err := t.pc.RemoveTrack(sender); err != nil {
+			t.params.Logger.Warnw("could not remove placeholder track", err, "trackID", trackLocal.ID())
+		}
+	}
+	return nil, nil
 }
 
 func (t *PCTransport) GetMid(rtpReceiver *webrtc.RTPReceiver) string {
@@ -817,8 +1023,10 @@ func (t *PCTransport) CreateDataChannel(label string, dci *webrtc.DataChannelIni
 		return err
 	}
 	var (
-		dcPtr   **webrtc.DataChannel
-		dcReady *bool
+		dcPtr       **webrtc.DataChannel
+		dcReady     *bool
+		dcSendQueue **dataChannelSendQueue
+		kind        livekit.DataPacket_Kind
 	)
 	switch dc.Label() {
 	default:
@@ -829,9 +1037,13 @@ func (t *PCTransport) CreateDataChannel(label string, dci *webrtc.DataChannelIni
 	case ReliableDataChannel:
 		dcPtr = &t.reliableDC
 		dcReady = &t.reliableDCOpened
+		dcSendQueue = &t.reliableDCSendQueue
+		kind = livekit.DataPacket_RELIABLE
 	case LossyDataChannel:
 		dcPtr = &t.lossyDC
 		dcReady = &t.lossyDCOpened
+		dcSendQueue = &t.lossyDCSendQueue
+		kind = livekit.DataPacket_LOSSY
 	}
 
 	dcReadyHandler := func() {
@@ -856,6 +1068,7 @@ func (t *PCTransport) CreateDataChannel(label string, dci *webrtc.DataChannelIni
 	t.lock.Lock()
 	defer t.lock.Unlock()
 	*dcPtr = dc
+	*dcSendQueue = t.newDataChannelSendQueue(kind, dc)
 	if t.params.DirectionConfig.StrictACKs {
 		dc.OnOpen(func() {
 			if t.params.IsSendSide {
@@ -926,17 +1139,101 @@ func (t *PCTransport) GetICEConnectionDetails() *types.ICEConnectionDetails {
 	return t.connectionDetails
 }
 
+// SetPacketCapture starts tapping decrypted RTP/RTCP of the transport into c, nil to stop
+func (t *PCTransport) SetPacketCapture(c *pcap.Capture) {
+	t.packetCaptureTap.setCapture(c)
+}
+
+// SetNetworkConditions impairs media received on and sent from the transport, nil to stop
+func (t *PCTransport) SetNetworkConditions(c *netem.Conditions) {
+	t.netEmulator.SetConditions(c)
+}
+
+func (t *PCTransport) GetNetworkConditions() *netem.Conditions {
+	return t.netEmulator.Conditions()
+}
+
+// GetICEStats returns all candidate pairs known to the ICE agent along with the selected one,
+// useful to see which path (host/srflx/relay) has been picked without needing packet captures
+func (t *PCTransport) GetICEStats() *types.ICEStats {
+	iceStats := &types.ICEStats{
+		Transport: t.params.Transport,
+	}
+
+	report := t.pc.GetStats()
+	candidates := make(map[string]webrtc.ICECandidateStats)
+	for _, stats := range report {
+		if cs, ok := stats.(webrtc.ICECandidateStats); ok {
+			candidates[cs.ID] = cs
+		}
+	}
+
+	selectedPair, _ := t.getSelectedPair()
+	isSelected := func(local, remote webrtc.ICECandidateStats) bool {
+		if selectedPair == nil || selectedPair.Local == nil || selectedPair.Remote == nil {
+			return false
+		}
+
+		return selectedPair.Local.Address == local.IP && int32(selectedPair.Local.Port) == local.Port &&
+			selectedPair.Remote.Address == remote.IP && int32(selectedPair.Remote.Port) == remote.Port
+	}
+
+	for _, stats := range report {
+		ps, ok := stats.(webrtc.ICECandidatePairStats)
+		if !ok {
+			continue
+		}
+
+		local := candidates[ps.LocalCandidateID]
+		remote := candidates[ps.RemoteCandidateID]
+		pair := &types.ICECandidatePairStats{
+			LocalAddress:         net.JoinHostPort(local.IP, strconv.Itoa(int(local.Port))),
+			LocalType:            local.CandidateType.String(),
+			LocalProtocol:        local.Protocol,
+			LocalRelayProtocol:   local.RelayProtocol,
+			RemoteAddress:        net.JoinHostPort(remote.IP, strconv.Itoa(int(remote.Port))),
+			RemoteType:           remote.CandidateType.String(),
+			RemoteProtocol:       remote.Protocol,
+			State:                string(ps.State),
+			Nominated:            ps.Nominated,
+			Selected:             isSelected(local, remote),
+			CurrentRoundTripTime: ps.CurrentRoundTripTime,
+			BytesSent:            ps.BytesSent,
+			BytesReceived:        ps.BytesReceived,
+		}
+		if pair.Selected {
+			iceStats.Pairs = append([]*types.ICECandidatePairStats{pair}, iceStats.Pairs...)
+		} else {
+			iceStats.Pairs = append(iceStats.Pairs, pair)
+		}
+	}
+
+	return iceStats
+}
+
+// QueuedEvents returns the number of signalling and negotiation events waiting to be processed
+func (t *PCTransport) QueuedEvents() int {
+	return t.eventsQueue.Len()
+}
+
 func (t *PCTransport) WriteRTCP(pkts []rtcp.Packet) error {
+	if t.rtcpScheduler != nil {
+		return t.rtcpScheduler.WriteRTCP(pkts)
+	}
+
 	return t.pc.WriteRTCP(pkts)
 }
 
 func (t *PCTransport) SendDataPacket(kind livekit.DataPacket_Kind, encoded []byte) error {
 	var dc *webrtc.DataChannel
+	var sendQueue *dataChannelSendQueue
 	t.lock.RLock()
 	if kind == livekit.DataPacket_RELIABLE {
 		dc = t.reliableDC
+		sendQueue = t.reliableDCSendQueue
 	} else {
 		dc = t.lossyDC
+		sendQueue = t.lossyDCSendQueue
 	}
 	t.lock.RUnlock()
 
@@ -948,13 +1245,32 @@ func (t *PCTransport) SendDataPacket(kind livekit.DataPacket_Kind, encoded []byt
 		return ErrTransportFailure
 	}
 
-	if t.params.DataChannelMaxBufferedAmount > 0 && dc.BufferedAmount() > t.params.DataChannelMaxBufferedAmount {
-		return ErrDataChannelBufferFull
+	if sendQueue != nil {
+		return sendQueue.Send(encoded)
 	}
 
 	return dc.Send(encoded)
 }
 
+// newDataChannelSendQueue sets up pacing of sends when data channel buffer is limited,
+// the handler is notified when messages held due to a full buffer have all been sent.
+// Lossy data drops the oldest queued messages when the queue is full, newer data is more useful.
+func (t *PCTransport) newDataChannelSendQueue(kind livekit.DataPacket_Kind, dc *webrtc.DataChannel) *dataChannelSendQueue {
+	if t.params.DataChannelMaxBufferedAmount == 0 {
+		return nil
+	}
+
+	return newDataChannelSendQueue(
+		dc,
+		t.params.DataChannelMaxBufferedAmount,
+		kind == livekit.DataPacket_LOSSY,
+		func(dropped int) {
+			t.params.Handler.OnDataSendQueueDrained(kind, dropped)
+		},
+		t.params.Logger,
+	)
+}
+
 func (t *PCTransport) Close() {
 	if t.isClosed.Swap(true) {
 		return
@@ -969,10 +1285,35 @@ func (t *PCTransport) Close() {
 	if t.pacer != nil {
 		t.pacer.Stop()
 	}
+	if t.rtcpScheduler != nil {
+		t.rtcpScheduler.Stop()
+	}
 
 	_ = t.pc.Close()
 
 	t.clearConnTimer()
+
+	t.lock.RLock()
+	connected := !t.firstConnectedAt.IsZero()
+	t.lock.RUnlock()
+	if !connected {
+		t.connectSpan.RecordError(ErrClosedBeforeConnected)
+		t.connectSpan.End()
+	}
+}
+
+func (t *PCTransport) startSpan(name string, attrs ...tracing.Attribute) tracer.Span {
+	ctx := t.params.TraceContext
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracer.Start(ctx, name,
+		tracing.Attr("participant", string(t.params.ParticipantIdentity)),
+		tracing.Attr("pID", string(t.params.ParticipantID)),
+		tracing.Attr("transport", t.params.Transport.String()),
+		attrs,
+	)
+	return span
 }
 
 func (t *PCTransport) clearConnTimer() {
@@ -1067,9 +1408,18 @@ func (t *PCTransport) AddTrackToStreamAllocator(subTrack types.SubscribedTrack)
 		Source:      subTrack.MediaTrack().Source(),
 		IsSimulcast: subTrack.MediaTrack().IsSimulcast(),
 		PublisherID: subTrack.MediaTrack().PublisherID(),
+		Priority:    subTrack.Priority(),
 	})
 }
 
+func (t *PCTransport) SetTrackPriorityOfStreamAllocator(subTrack types.SubscribedTrack) {
+	if t.streamAllocator == nil {
+		return
+	}
+
+	t.streamAllocator.SetTrackPriority(subTrack.DownTrack(), subTrack.Priority())
+}
+
 func (t *PCTransport) RemoveTrackFromStreamAllocator(subTrack types.SubscribedTrack) {
 	if t.streamAllocator == nil {
 		return
@@ -1094,6 +1444,30 @@ func (t *PCTransport) SetChannelCapacityOfStreamAllocator(channelCapacity int64)
 	t.streamAllocator.SetChannelCapacity(channelCapacity)
 }
 
+func (t *PCTransport) HandleReceiverReportOfStreamAllocator(report *rtcp.ReceiverReport) {
+	if t.streamAllocator == nil {
+		return
+	}
+
+	t.streamAllocator.OnReceiverReport(report)
+}
+
+func (t *PCTransport) SetChannelCapacityCeilingOfStreamAllocator(ceiling int64) {
+	if t.streamAllocator == nil {
+		return
+	}
+
+	t.streamAllocator.SetChannelCapacityCeiling(ceiling)
+}
+
+func (t *PCTransport) SetAllocationPolicyOfStreamAllocator(policy streamallocator.AllocationPolicy) {
+	if t.streamAllocator == nil {
+		return
+	}
+
+	t.streamAllocator.SetAllocationPolicy(policy)
+}
+
 func (t *PCTransport) preparePC(previousAnswer webrtc.SessionDescription) error {
 	// sticky data channel to first m-lines, if someday we don't send sdp without media streams to
 	// client's subscribe pc after joining, should change this step
@@ -1216,10 +1590,10 @@ func (t *PCTransport) initPCWithPreviousAnswer(previousAnswer webrtc.SessionDesc
 	return senders, nil
 }
 
-func (t *PCTransport) SetPreviousSdp(offer, answer *webrtc.SessionDescription) {
+func (t *PCTransport) SetPreviousSdp(offer, answer *webrtc.SessionDescription, trackMappings []*types.TrackMapping) {
 	// when there is no previous answer, cannot migrate, force a full reconnect
 	if answer == nil {
-		t.params.Handler.OnNegotiationFailed()
+		t.negotiationFailed("no previous answer", nil)
 		return
 	}
 
@@ -1230,50 +1604,95 @@ func (t *PCTransport) SetPreviousSdp(offer, answer *webrtc.SessionDescription) {
 			t.params.Logger.Warnw("initPCWithPreviousAnswer failed", err)
 			t.lock.Unlock()
 
-			t.params.Handler.OnNegotiationFailed()
+			t.negotiationFailed("init with previous answer", err)
 			return
-		} else if offer != nil {
+		} else if offer != nil || len(trackMappings) != 0 {
 			// in migration case, can't reuse transceiver before negotiated except track subscribed at previous node
 			t.canReuseTransceiver = false
-			if err := t.parseTrackMid(*offer, senders); err != nil {
-				t.params.Logger.Warnw("parse previous offer failed", err, "offer", offer.SDP)
-			}
+			t.restoreTrackDescriptions(offer, trackMappings, senders)
 		}
 	}
 	t.lock.Unlock()
 }
 
-func (t *PCTransport) parseTrackMid(offer webrtc.SessionDescription, senders map[string]*webrtc.RTPSender) error {
-	parsed, err := offer.Unmarshal()
-	if err != nil {
-		return err
+// restoreTrackDescriptions binds tracks subscribed at previous node to transceivers restored from previous answer.
+// Explicit track mappings take precedence, previous offer is used for tracks without a mapping.
+func (t *PCTransport) restoreTrackDescriptions(offer *webrtc.SessionDescription, trackMappings []*types.TrackMapping, senders map[string]*webrtc.RTPSender) {
+	var offerMappings []*types.TrackMapping
+	if offer != nil {
+		var err error
+		if offerMappings, err = trackMappingsFromSDP(*offer); err != nil {
+			t.params.Logger.Warnw("parse previous offer failed", err, "offer", offer.SDP)
+		}
 	}
 
 	t.previousTrackDescription = make(map[string]*trackDescription)
-	for _, m := range parsed.MediaDescriptions {
-		msid, ok := m.Attribute(sdp.AttrKeyMsid)
-		if !ok {
+	for _, tm := range trackMappings {
+		mid := resolveTrackMappingMid(tm, offerMappings, senders)
+		if mid == "" {
+			t.params.Logger.Warnw(
+				"could not restore track mapping", nil,
+				"trackID", tm.TrackID,
+				"mid", tm.Mid,
+				"rids", tm.Rids,
+				"ssrcs", tm.SSRCs,
+			)
+			continue
+		}
+		if mid != tm.Mid {
+			t.params.Logger.Infow("track mapping restored by ssrc", "trackID", tm.TrackID, "mid", tm.Mid, "restoredMid", mid)
+		}
+		t.previousTrackDescription[string(tm.TrackID)] = &trackDescription{
+			mid:    mid,
+			sender: senders[mid],
+		}
+	}
+
+	for _, om := range offerMappings {
+		if _, ok := t.previousTrackDescription[string(om.TrackID)]; ok {
+			continue
+		}
+		t.previousTrackDescription[string(om.TrackID)] = &trackDescription{
+			mid:    om.Mid,
+			sender: senders[om.Mid],
+		}
+	}
+}
+
+// GetTrackMappings returns the transceivers tracks are sent on, to be handed over on migration
+func (t *PCTransport) GetTrackMappings() []*types.TrackMapping {
+	var mappings []*types.TrackMapping
+	for _, tr := range t.pc.GetTransceivers() {
+		sender := tr.Sender()
+		if sender == nil || sender.Track() == nil || tr.Mid() == "" {
 			continue
 		}
 
-		if split := strings.Split(msid, " "); len(split) == 2 {
-			trackID := split[1]
-			mid := lksdp.GetMidValue(m)
-			if mid == "" {
-				return ErrMidNotFound
+		tm := &types.TrackMapping{
+			TrackID: livekit.TrackID(sender.Track().ID()),
+			Mid:     tr.Mid(),
+		}
+		for _, encoding := range sender.GetParameters().Encodings {
+			if encoding.RID != "" {
+				tm.Rids = append(tm.Rids, encoding.RID)
+			}
+			if encoding.SSRC != 0 {
+				tm.SSRCs = append(tm.SSRCs, uint32(encoding.SSRC))
 			}
-			t.previousTrackDescription[trackID] = &trackDescription{
-				mid:    mid,
-				sender: senders[mid],
+			if encoding.RTX.SSRC != 0 {
+				tm.SSRCs = append(tm.SSRCs, uint32(encoding.RTX.SSRC))
 			}
 		}
+		mappings = append(mappings, tm)
 	}
-	return nil
+	return mappings
 }
 
 func (t *PCTransport) postEvent(e event) {
 	e.PCTransport = t
 	t.eventsQueue.Enqueue(func(e event) {
+		e.traceEvent(e)
+
 		var err error
 		switch e.signal {
 		case signalICEGatheringComplete:
@@ -1288,16 +1707,55 @@ func (t *PCTransport) postEvent(e event) {
 			err = e.handleRemoteDescriptionReceived(e)
 		case signalICERestart:
 			err = e.handleICERestart(e)
+		case signalICEIPFamilyHeadStartElapsed:
+			err = e.handleICEIPFamilyHeadStartElapsed(e)
 		}
 		if err != nil {
 			if !e.isClosed.Load() {
 				e.params.Logger.Warnw("error handling event", err, "event", e.String())
-				e.params.Handler.OnNegotiationFailed()
+				e.negotiationFailed(e.signal.String(), err)
 			}
 		}
 	}, e)
 }
 
+func (t *PCTransport) traceEvent(e event) {
+	entry := NegotiationTraceEntry{
+		Event: e.signal.String(),
+	}
+	switch e.signal {
+	case signalLocalICECandidate, signalRemoteICECandidate:
+		// candidates would crowd out negotiation events, they are in ICE connection details
+		return
+	case signalRemoteDescriptionReceived:
+		entry.SDP = sdpDigest(e.data.(*webrtc.SessionDescription))
+	}
+	t.traceNegotiation(entry)
+}
+
+func (t *PCTransport) traceNegotiation(entry NegotiationTraceEntry) {
+	entry.At = time.Now()
+	entry.State = t.negotiationState.String()
+	t.negotiationTrace.add(entry)
+}
+
+// GetNegotiationTrace returns the last signaling events of the transport, oldest first
+func (t *PCTransport) GetNegotiationTrace() []NegotiationTraceEntry {
+	return t.negotiationTrace.Entries()
+}
+
+// negotiationFailed reports failure with the trace of events leading to it
+func (t *PCTransport) negotiationFailed(reason string, err error) {
+	entry := NegotiationTraceEntry{Event: "NEGOTIATION_FAILED", Detail: reason}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	t.traceNegotiation(entry)
+
+	t.params.Logger.Infow("negotiation failed", "reason", reason, "trace", t.negotiationTrace.Entries())
+	t.params.Handler.OnNegotiationFailed()
+}
+
 func (t *PCTransport) handleICEGatheringComplete(_ event) error {
 	if t.params.IsOfferer {
 		return t.handleICEGatheringCompleteOfferer()
@@ -1355,6 +1813,15 @@ func (t *PCTransport) clearLocalDescriptionSent() {
 	t.cacheLocalCandidates = true
 	t.cachedLocalCandidates = nil
 	t.connectionDetails.Clear()
+
+	// ICE restart, both families get a chance again
+	t.connectedICEIPFamily.Store("")
+	t.heldRemoteCandidates = nil
+	if t.iceIPFamilyHeadStartTimer != nil {
+		t.iceIPFamilyHeadStartTimer.Stop()
+		t.iceIPFamilyHeadStartTimer = nil
+	}
+	t.iceIPFamilyHeadStartElapsed = false
 }
 
 func (t *PCTransport) handleLocalICECandidate(e event) error {
@@ -1389,10 +1856,16 @@ func (t *PCTransport) handleLocalICECandidate(e event) error {
 func (t *PCTransport) handleRemoteICECandidate(e event) error {
 	c := e.data.(*webrtc.ICECandidateInit)
 
+	family := remoteICECandidateIPFamily(c)
 	filtered := false
 	if t.preferTCP.Load() && !strings.Contains(c.Candidate, "tcp") {
 		t.params.Logger.Debugw("filtering out remote candidate", "candidate", c.Candidate)
 		filtered = true
+	} else if t.isICEIPFamilyPruned(family) {
+		t.params.Logger.Debugw("pruning remote candidate", "candidate", c.Candidate, "family", family)
+		filtered = true
+	} else if t.holdRemoteICECandidate(c, family) {
+		return nil
 	}
 
 	t.connectionDetails.AddRemoteCandidate(*c, filtered, true)
@@ -1415,7 +1888,75 @@ func (t *PCTransport) handleRemoteICECandidate(e event) error {
 	return nil
 }
 
+func (t *PCTransport) isICEIPFamilyPruned(family types.ICEIPFamily) bool {
+	if !t.params.Config.ICEIPFamily.Prune || family == types.ICEIPFamilyUnknown {
+		return false
+	}
+
+	connected := types.ICEIPFamily(t.connectedICEIPFamily.Load())
+	return connected != "" && connected != family
+}
+
+// holdRemoteICECandidate holds back a remote candidate of the non-preferred IP family,
+// giving pairs of the preferred family a head start
+func (t *PCTransport) holdRemoteICECandidate(c *webrtc.ICECandidateInit, family types.ICEIPFamily) bool {
+	preferred := t.params.Config.ICEIPFamily.Prefer
+	if preferred == types.ICEIPFamilyUnknown || family == types.ICEIPFamilyUnknown || family == preferred || t.iceIPFamilyHeadStartElapsed {
+		return false
+	}
+
+	t.heldRemoteCandidates = append(t.heldRemoteCandidates, c)
+	if t.iceIPFamilyHeadStartTimer == nil {
+		t.iceIPFamilyHeadStartGeneration++
+		generation := t.iceIPFamilyHeadStartGeneration
+		t.iceIPFamilyHeadStartTimer = time.AfterFunc(t.params.Config.ICEIPFamily.HeadStart, func() {
+			t.postEvent(event{
+				signal: signalICEIPFamilyHeadStartElapsed,
+				data:   generation,
+			})
+		})
+	}
+	return true
+}
+
+func (t *PCTransport) handleICEIPFamilyHeadStartElapsed(e event) error {
+	generation := e.data.(uint32)
+	if t.iceIPFamilyHeadStartTimer == nil || generation != t.iceIPFamilyHeadStartGeneration {
+		// cleared by ICE restart, timer may have fired before it was stopped
+		t.params.Logger.Debugw("ignoring stale ICE IP family head start", "generation", generation, "currentGeneration", t.iceIPFamilyHeadStartGeneration)
+		return nil
+	}
+	t.iceIPFamilyHeadStartTimer = nil
+	t.iceIPFamilyHeadStartElapsed = true
+
+	held := t.heldRemoteCandidates
+	t.heldRemoteCandidates = nil
+	t.params.Logger.Debugw("ICE IP family head start elapsed", "numHeldCandidates", len(held), "connectedFamily", t.connectedICEIPFamily.Load())
+	for _, c := range held {
+		if err := t.handleRemoteICECandidate(event{PCTransport: t, signal: signalRemoteICECandidate, data: c}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func remoteICECandidateIPFamily(c *webrtc.ICECandidateInit) types.ICEIPFamily {
+	candidate, err := ice.UnmarshalCandidate(strings.TrimPrefix(c.Candidate, "candidate:"))
+	if err != nil {
+		return types.ICEIPFamilyUnknown
+	}
+	return types.ICEIPFamilyOfAddress(candidate.Address())
+}
+
 func (t *PCTransport) setNegotiationState(state transport.NegotiationState) {
+	if state != t.negotiationState {
+		t.negotiationTrace.add(NegotiationTraceEntry{
+			At:     time.Now(),
+			Event:  "NEGOTIATION_STATE_CHANGED",
+			Detail: "from " + t.negotiationState.String(),
+			State:  state.String(),
+		})
+	}
 	t.negotiationState = state
 	if onNegotiationStateChanged := t.getOnNegotiationStateChanged(); onNegotiationStateChanged != nil {
 		onNegotiationStateChanged(t.negotiationState)
@@ -1471,6 +2012,69 @@ func (t *PCTransport) filterCandidates(sd webrtc.SessionDescription, preferTCP,
 	return sd
 }
 
+// Pion does not signal RTX repair streams for senders.
+// Add them so that subscribers can associate retransmissions with the primary stream.
+func (t *PCTransport) maybeAddRTXRepairStreams(sd webrtc.SessionDescription) webrtc.SessionDescription {
+	if !t.params.IsSendSide || !IsCodecEnabled(t.params.EnabledCodecs, videoRTX) {
+		return sd
+	}
+
+	parsed, err := sd.Unmarshal()
+	if err != nil {
+		t.params.Logger.Warnw("could not unmarshal SDP to add rtx repair streams", err)
+		return sd
+	}
+
+	t.lock.Lock()
+	rtxSSRCs, modified := addRTXRepairStreamsToSDP(parsed, t.rtxSSRCs)
+	t.rtxSSRCs = rtxSSRCs
+	t.lock.Unlock()
+	if !modified {
+		return sd
+	}
+
+	bytes, err := parsed.Marshal()
+	if err != nil {
+		t.params.Logger.Warnw("could not marshal SDP to add rtx repair streams", err)
+		return sd
+	}
+	sd.SDP = string(bytes)
+	return sd
+}
+
+// pruneAnswerCodecs limits codec preferences of receiving transceivers to the codecs tracks are already received with,
+// the remote keeps sending the codec it started with, so other codecs only add to SDP size and parse time.
+// Preferences are set before the answer is created so that the answer sent matches the local description.
+func (t *PCTransport) pruneAnswerCodecs() {
+	if !t.params.Config.PruneAnswerCodecs {
+		return
+	}
+
+	for _, tr := range t.pc.GetTransceivers() {
+		receiver := tr.Receiver()
+		if tr.Mid() == "" || receiver == nil {
+			continue
+		}
+
+		var payloadTypes []webrtc.PayloadType
+		for _, track := range receiver.Tracks() {
+			// codec is known only after track has started
+			if codec := track.Codec(); codec.MimeType != "" {
+				payloadTypes = append(payloadTypes, codec.PayloadType)
+			}
+		}
+		if len(payloadTypes) == 0 {
+			continue
+		}
+
+		if codecs := pruneCodecs(receiver.GetParameters().Codecs, payloadTypes); codecs != nil {
+			if err := tr.SetCodecPreferences(codecs); err != nil {
+				t.params.Logger.Warnw("could not prune codecs", err, "mid", tr.Mid())
+			}
+		}
+	}
+}
+
 func (t *PCTransport) clearSignalStateCheckTimer() {
 	if t.signalStateCheckTimer != nil {
 		t.signalStateCheckTimer.Stop()
@@ -1495,7 +2099,7 @@ func (t *PCTransport) setupSignalStateCheckTimer() {
 				"remoteCurrent", t.pc.CurrentRemoteDescription(),
 				"remotePending", t.pc.PendingRemoteDescription(),
 			)
-			t.params.Handler.OnNegotiationFailed()
+			t.negotiationFailed("timed out", nil)
 		}
 	})
 }
@@ -1542,6 +2146,7 @@ func (t *PCTransport) createAndSendOffer(options *webrtc.OfferOptions) error {
 		t.clearLocalDescriptionSent()
 	}
 
+	stoppedTransceivers := t.stoppedTransceivers()
 	offer, err := t.pc.CreateOffer(options)
 	if err != nil {
 		if errors.Is(err, webrtc.ErrConnectionClosed) {
@@ -1569,6 +2174,11 @@ func (t *PCTransport) createAndSendOffer(options *webrtc.OfferOptions) error {
 		return errors.Wrap(err, "setting local description failed")
 	}
 
+	t.lock.Lock()
+	t.numPendingDormantTransceivers = 0
+	t.offeredStoppedTransceivers = stoppedTransceivers
+	t.lock.Unlock()
+
 	//
 	// Filter after setting local description as pion expects the offer
 	// to match between CreateOffer and SetLocalDescription.
@@ -1579,12 +2189,14 @@ func (t *PCTransport) createAndSendOffer(options *webrtc.OfferOptions) error {
 	if preferTCP {
 		t.params.Logger.Debugw("local offer (filtered)", "sdp", offer.SDP)
 	}
+	offer = t.maybeAddRTXRepairStreams(offer)
 
 	// indicate waiting for remote
 	t.setNegotiationState(transport.NegotiationStateRemote)
 
 	t.setupSignalStateCheckTimer()
 
+	t.traceNegotiation(NegotiationTraceEntry{Event: "SEND_LOCAL_OFFER", SDP: sdpDigest(&offer)})
 	if err := t.params.Handler.OnOffer(offer); err != nil {
 		prometheus.ServiceOperationCounter.WithLabelValues("offer", "error", "write_message").Add(1)
 		return errors.Wrap(err, "could not send offer")
@@ -1595,11 +2207,23 @@ func (t *PCTransport) createAndSendOffer(options *webrtc.OfferOptions) error {
 }
 
 func (t *PCTransport) handleSendOffer(_ event) error {
-	return t.createAndSendOffer(nil)
+	span := t.startSpan("PCTransport.sendOffer")
+	defer span.End()
+
+	err := t.createAndSendOffer(nil)
+	span.RecordError(err)
+	return err
 }
 
-func (t *PCTransport) handleRemoteDescriptionReceived(e event) error {
+func (t *PCTransport) handleRemoteDescriptionReceived(e event) (err error) {
 	sd := e.data.(*webrtc.SessionDescription)
+
+	span := t.startSpan("PCTransport.handleRemoteDescription", tracing.Attr("type", sd.Type.String()))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	if sd.Type == webrtc.SDPTypeOffer {
 		return t.handleRemoteOfferReceived(sd)
 	} else {
@@ -1648,7 +2272,11 @@ func (t *PCTransport) setRemoteDescription(sd webrtc.SessionDescription) error {
 			t.canReuseTransceiver = true
 			t.previousTrackDescription = make(map[string]*trackDescription)
 		}
+		stoppedTransceivers := t.offeredStoppedTransceivers
+		t.offeredStoppedTransceivers = nil
 		t.lock.Unlock()
+
+		t.recycleStoppedTransceivers(stoppedTransceivers)
 	}
 
 	for _, c := range t.pendingRemoteCandidates {
@@ -1665,6 +2293,7 @@ func (t *PCTransport) setRemoteDescription(sd webrtc.SessionDescription) error {
 }
 
 func (t *PCTransport) createAndSendAnswer() error {
+	t.pruneAnswerCodecs()
 	answer, err := t.pc.CreateAnswer(nil)
 	if err != nil {
 		if errors.Is(err, webrtc.ErrConnectionClosed) {
@@ -1697,6 +2326,7 @@ func (t *PCTransport) createAndSendAnswer() error {
 		t.params.Logger.Debugw("local answer (filtered)", "sdp", answer.SDP)
 	}
 
+	t.traceNegotiation(NegotiationTraceEntry{Event: "SEND_LOCAL_ANSWER", SDP: sdpDigest(&answer)})
 	if err := t.params.Handler.OnAnswer(answer); err != nil {
 		prometheus.ServiceOperationCounter.WithLabelValues("answer", "error", "write_message").Add(1)
 		return errors.Wrap(err, "could not send answer")
@@ -1712,6 +2342,11 @@ func (t *PCTransport) handleRemoteOfferReceived(sd *webrtc.SessionDescription) e
 		return nil
 	}
 
+	if t.pc.SignalingState() == webrtc.SignalingStateHaveLocalOffer {
+		t.handleOfferCollision(sd)
+		return nil
+	}
+
 	t.lock.Lock()
 	if !t.firstOfferReceived {
 		t.firstOfferReceived = true
@@ -1763,6 +2398,20 @@ func (t *PCTransport) handleRemoteOfferReceived(sd *webrtc.SessionDescription) e
 	return t.createAndSendAnswer()
 }
 
+// handleOfferCollision resolves glare, i. e. a remote offer received while a local offer is pending.
+// The server side is always the impolite peer of perfect negotiation, it ignores the remote offer and
+// keeps waiting for an answer to its own offer, remote is expected to roll back and answer.
+// Rolling back a local offer to be polite is not supported by the pion version in use.
+func (t *PCTransport) handleOfferCollision(sd *webrtc.SessionDescription) {
+	localOffer := t.pc.PendingLocalDescription()
+	t.params.Logger.Infow(
+		"ignoring colliding remote offer",
+		"localOffer", sdpDigest(localOffer),
+		"remoteOffer", sdpDigest(sd),
+	)
+	t.traceNegotiation(NegotiationTraceEntry{Event: "IGNORE_COLLIDING_OFFER", SDP: sdpDigest(sd)})
+}
+
 func (t *PCTransport) handleRemoteAnswerReceived(sd *webrtc.SessionDescription) error {
 	t.clearSignalStateCheckTimer()
 
@@ -1776,6 +2425,8 @@ func (t *PCTransport) handleRemoteAnswerReceived(sd *webrtc.SessionDescription)
 		}
 	}
 
+	t.maybeEnableLossBasedEstimation(sd)
+
 	if t.negotiationState == transport.NegotiationStateRetry {
 		t.setNegotiationState(transport.NegotiationStateNone)
 
@@ -1787,6 +2438,27 @@ func (t *PCTransport) handleRemoteAnswerReceived(sd *webrtc.SessionDescription)
 	return nil
 }
 
+// subscribers that negotiate video without congestion control feedback fall back to loss based estimation
+func (t *PCTransport) maybeEnableLossBasedEstimation(answer *webrtc.SessionDescription) {
+	if t.streamAllocator == nil || t.lossBasedEstimation || !t.params.CongestionControlConfig.Enabled {
+		return
+	}
+
+	parsed, err := answer.Unmarshal()
+	if err != nil {
+		return
+	}
+
+	hasVideo, hasFeedback := hasBWEFeedback(parsed, t.params.CongestionControlConfig.UseSendSideBWE)
+	if !hasVideo || hasFeedback {
+		return
+	}
+
+	t.params.Logger.Infow("no congestion control feedback negotiated, using loss based estimation")
+	t.lossBasedEstimation = true
+	t.streamAllocator.EnableLossBasedEstimation()
+}
+
 func (t *PCTransport) doICERestart() error {
 	if t.pc.ConnectionState() == webrtc.PeerConnectionStateClosed {
 		t.params.Logger.Warnw("trying to restart ICE on closed peer connection", nil)
@@ -1820,6 +2492,7 @@ func (t *PCTransport) doICERestart() error {
 			t.params.Logger.Infow("deferring ice restart to next offer")
 			t.setNegotiationState(transport.NegotiationStateRetry)
 			t.restartAtNextOffer = true
+			t.traceNegotiation(NegotiationTraceEntry{Event: "RESEND_LOCAL_OFFER", SDP: sdpDigest(offer)})
 			err := t.params.Handler.OnOffer(*offer)
 			if err != nil {
 				prometheus.ServiceOperationCounter.WithLabelValues("offer", "error", "write_message").Add(1)
@@ -1879,6 +2552,27 @@ func configureAudioTransceiver(tr *webrtc.RTPTransceiver, stereo bool, nack bool
 	tr.SetCodecPreferences(configCodecs)
 }
 
+// hasBWEFeedback checks negotiated video sections for the feedback bandwidth estimation relies on,
+// transport-cc extension with send side estimation, REMB otherwise
+func hasBWEFeedback(s *sdp.SessionDescription, sendSideBWE bool) (hasVideo bool, hasFeedback bool) {
+	for _, media := range s.MediaDescriptions {
+		if media.MediaName.Media != "video" || media.MediaName.Port.Value == 0 {
+			continue
+		}
+
+		hasVideo = true
+		for _, attr := range media.Attributes {
+			switch {
+			case sendSideBWE && attr.Key == sdp.AttrKeyExtMap && strings.Contains(attr.Value, sdp.TransportCCURI):
+				return true, true
+			case !sendSideBWE && attr.Key == "rtcp-fb" && strings.Contains(attr.Value, webrtc.TypeRTCPFBGoogREMB):
+				return true, true
+			}
+		}
+	}
+	return
+}
+
 func nonSimulcastRTXRepairsFromSDP(s *sdp.SessionDescription, logger logger.Logger) map[uint32]uint32 {
 	rtxRepairFlows := map[uint32]uint32{}
 	for _, media := range s.MediaDescriptions {
@@ -1923,3 +2617,186 @@ func nonSimulcastRTXRepairsFromSDP(s *sdp.SessionDescription, logger logger.Logg
 
 	return rtxRepairFlows
 }
+
+// twccExtIDForStream returns the transport-wide sequence number extension id if the incoming stream
+// negotiated TWCC feedback, 0 otherwise. Video streams always negotiate it, audio streams only when
+// publisher_audio_twcc is enabled in congestion control config.
+func twccExtIDForStream(info *interceptor.StreamInfo) uint8 {
+	if !strings.HasPrefix(info.MimeType, "video") && !strings.HasPrefix(info.MimeType, "audio") {
+		return 0
+	}
+	// rtx stream don't have rtcp feedback, always set twcc for rtx stream
+	twccFb := strings.HasSuffix(info.MimeType, "rtx")
+	if !twccFb {
+		for _, fb := range info.RTCPFeedback {
+			if fb.Type == webrtc.TypeRTCPFBTransportCC {
+				twccFb = true
+				break
+			}
+		}
+	}
+	if !twccFb {
+		return 0
+	}
+
+	return uint8(sfuutils.GetHeaderExtensionID(info.RTPHeaderExtensions, webrtc.RTPHeaderExtensionCapability{URI: sdp.TransportCCURI}))
+}
+
+// addRTXRepairStreamsToSDP signals a repair stream for video sections which have RTX negotiated.
+// Repair SSRCs are chosen to not collide with any SSRC in the session and are kept stable for primary
+// SSRCs present in prevRTXSSRCs. Returns the primary -> repair SSRC map of the session.
+func addRTXRepairStreamsToSDP(s *sdp.SessionDescription, prevRTXSSRCs map[uint32]uint32) (map[uint32]uint32, bool) {
+	usedSSRCs := make(map[uint32]bool)
+	for _, media := range s.MediaDescriptions {
+		for _, attr := range media.Attributes {
+			if attr.Key != sdp.AttrKeySSRC {
+				continue
+			}
+			if ssrc, err := strconv.ParseUint(strings.Split(attr.Value, " ")[0], 10, 32); err == nil {
+				usedSSRCs[uint32(ssrc)] = true
+			}
+		}
+	}
+
+	rtxSSRCs := make(map[uint32]uint32)
+	modified := false
+	for _, media := range s.MediaDescriptions {
+		if !strings.EqualFold(media.MediaName.Media, "video") {
+			continue
+		}
+
+		rtxFound := false
+		var fidSSRCs []string
+		var primarySSRC uint32
+		for _, attr := range media.Attributes {
+			switch attr.Key {
+			case "rtpmap":
+				if strings.Contains(strings.ToLower(attr.Value), "rtx/") {
+					rtxFound = true
+				}
+			case sdp.AttrKeySSRCGroup:
+				if strings.HasPrefix(attr.Value, sdp.SemanticTokenFlowIdentification) {
+					fidSSRCs = strings.Split(attr.Value, " ")
+				}
+			case sdp.AttrKeySSRC:
+				if primarySSRC == 0 {
+					if ssrc, err := strconv.ParseUint(strings.Split(attr.Value, " ")[0], 10, 32); err == nil {
+						primarySSRC = uint32(ssrc)
+					}
+				}
+			}
+		}
+		if len(fidSSRCs) == 3 {
+			// already signalled
+			primary, err1 := strconv.ParseUint(fidSSRCs[1], 10, 32)
+			rtx, err2 := strconv.ParseUint(fidSSRCs[2], 10, 32)
+			if err1 == nil && err2 == nil {
+				rtxSSRCs[uint32(primary)] = uint32(rtx)
+			}
+			continue
+		}
+		if !rtxFound || len(fidSSRCs) != 0 || primarySSRC == 0 {
+			continue
+		}
+
+		rtxSSRC := prevRTXSSRCs[primarySSRC]
+		if rtxSSRC == 0 || usedSSRCs[rtxSSRC] {
+			rtxSSRC = sfu.RTXSSRCForPrimary(primarySSRC)
+			for rtxSSRC == 0 || usedSSRCs[rtxSSRC] {
+				rtxSSRC++
+			}
+		}
+		usedSSRCs[rtxSSRC] = true
+		rtxSSRCs[primarySSRC] = rtxSSRC
+
+		primaryPrefix := fmt.Sprintf("%d ", primarySSRC)
+		attrs := make([]sdp.Attribute, 0, len(media.Attributes)+5)
+		var rtxAttrs []sdp.Attribute
+		for _, attr := range media.Attributes {
+			if attr.Key == sdp.AttrKeySSRC && strings.HasPrefix(attr.Value, primaryPrefix) {
+				if len(rtxAttrs) == 0 {
+					attrs = append(attrs, sdp.Attribute{
+						Key:   sdp.AttrKeySSRCGroup,
+						Value: fmt.Sprintf("%s %d %d", sdp.SemanticTokenFlowIdentification, primarySSRC, rtxSSRC),
+					})
+				}
+				rtxAttrs = append(rtxAttrs, sdp.Attribute{
+					Key:   sdp.AttrKeySSRC,
+					Value: fmt.Sprintf("%d %s", rtxSSRC, strings.TrimPrefix(attr.Value, primaryPrefix)),
+				})
+			}
+			attrs = append(attrs, attr)
+		}
+		media.Attributes = append(attrs, rtxAttrs...)
+		modified = true
+	}
+
+	return rtxSSRCs, modified
+}
+
+// pruneCodecs returns codecs limited to the ones of payloadTypes, along with red carrying them, codecs carried by them
+// and rtx repairing them. nil is returned when a payload type is not in codecs or when there is nothing to prune
+func pruneCodecs(codecs []webrtc.RTPCodecParameters, payloadTypes []webrtc.PayloadType) []webrtc.RTPCodecParameters {
+	known := make(map[webrtc.PayloadType]bool, len(codecs))
+	for _, c := range codecs {
+		known[c.PayloadType] = true
+	}
+
+	keep := make(map[webrtc.PayloadType]bool)
+	for _, pt := range payloadTypes {
+		if !known[pt] {
+			return nil
+		}
+		keep[pt] = true
+	}
+
+	redundantPayloadTypes := func(c webrtc.RTPCodecParameters) []webrtc.PayloadType {
+		var pts []webrtc.PayloadType
+		for _, format := range strings.Split(c.SDPFmtpLine, "/") {
+			if pt, err := strconv.Atoi(format); err == nil && known[webrtc.PayloadType(pt)] {
+				pts = append(pts, webrtc.PayloadType(pt))
+			}
+		}
+		return pts
+	}
+	// codecs carried by received red
+	for _, c := range codecs {
+		if keep[c.PayloadType] && strings.EqualFold(c.MimeType, sfu.MimeTypeAudioRed) {
+			for _, pt := range redundantPayloadTypes(c) {
+				keep[pt] = true
+			}
+		}
+	}
+	// red offered for received codecs, so that the remote can keep sending redundancy
+	for _, c := range codecs {
+		if keep[c.PayloadType] || !strings.EqualFold(c.MimeType, sfu.MimeTypeAudioRed) {
+			continue
+		}
+		pts := redundantPayloadTypes(c)
+		if len(pts) != 0 && !slices.ContainsFunc(pts, func(pt webrtc.PayloadType) bool { return !keep[pt] }) {
+			keep[c.PayloadType] = true
+		}
+	}
+	// rtx repairing kept codecs
+	for _, c := range codecs {
+		for _, param := range strings.Split(c.SDPFmtpLine, ";") {
+			if apt, ok := strings.CutPrefix(strings.TrimSpace(param), "apt="); ok {
+				if pt, err := strconv.Atoi(apt); err == nil && keep[webrtc.PayloadType(pt)] {
+					keep[c.PayloadType] = true
+				}
+			}
+		}
+	}
+
+	if len(keep) == len(codecs) {
+		return nil
+	}
+
+	pruned := make([]webrtc.RTPCodecParameters, 0, len(keep))
+	for _, c := range codecs {
+		if keep[c.PayloadType] {
+			pruned = append(pruned, c)
+		}
+	}
+	return pruned
+}
@@ -28,6 +28,12 @@ type FakeHandler struct {
 		arg1 livekit.DataPacket_Kind
 		arg2 []byte
 	}
+	OnDataSendQueueDrainedStub        func(livekit.DataPacket_Kind, int)
+	onDataSendQueueDrainedMutex       sync.RWMutex
+	onDataSendQueueDrainedArgsForCall []struct {
+		arg1 livekit.DataPacket_Kind
+		arg2 int
+	}
 	OnFailedStub        func(bool)
 	onFailedMutex       sync.RWMutex
 	onFailedArgsForCall []struct {
@@ -193,6 +199,39 @@ func (fake *FakeHandler) OnDataPacketArgsForCall(i int) (livekit.DataPacket_Kind
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *FakeHandler) OnDataSendQueueDrained(arg1 livekit.DataPacket_Kind, arg2 int) {
+	fake.onDataSendQueueDrainedMutex.Lock()
+	fake.onDataSendQueueDrainedArgsForCall = append(fake.onDataSendQueueDrainedArgsForCall, struct {
+		arg1 livekit.DataPacket_Kind
+		arg2 int
+	}{arg1, arg2})
+	stub := fake.OnDataSendQueueDrainedStub
+	fake.recordInvocation("OnDataSendQueueDrained", []interface{}{arg1, arg2})
+	fake.onDataSendQueueDrainedMutex.Unlock()
+	if stub != nil {
+		fake.OnDataSendQueueDrainedStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeHandler) OnDataSendQueueDrainedCallCount() int {
+	fake.onDataSendQueueDrainedMutex.RLock()
+	defer fake.onDataSendQueueDrainedMutex.RUnlock()
+	return len(fake.onDataSendQueueDrainedArgsForCall)
+}
+
+func (fake *FakeHandler) OnDataSendQueueDrainedCalls(stub func(livekit.DataPacket_Kind, int)) {
+	fake.onDataSendQueueDrainedMutex.Lock()
+	defer fake.onDataSendQueueDrainedMutex.Unlock()
+	fake.OnDataSendQueueDrainedStub = stub
+}
+
+func (fake *FakeHandler) OnDataSendQueueDrainedArgsForCall(i int) (livekit.DataPacket_Kind, int) {
+	fake.onDataSendQueueDrainedMutex.RLock()
+	defer fake.onDataSendQueueDrainedMutex.RUnlock()
+	argsForCall := fake.onDataSendQueueDrainedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *FakeHandler) OnFailed(arg1 bool) {
 	fake.onFailedMutex.Lock()
 	fake.onFailedArgsForCall = append(fake.onFailedArgsForCall, struct {
@@ -553,6 +592,8 @@ func (fake *FakeHandler) Invocations() map[string][][]interface{} {
 	defer fake.onAnswerMutex.RUnlock()
 	fake.onDataPacketMutex.RLock()
 	defer fake.onDataPacketMutex.RUnlock()
+	fake.onDataSendQueueDrainedMutex.RLock()
+	defer fake.onDataSendQueueDrainedMutex.RUnlock()
 	fake.onFailedMutex.RLock()
 	defer fake.onFailedMutex.RUnlock()
 	fake.onFullyEstablishedMutex.RLock()
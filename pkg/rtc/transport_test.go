@@ -16,11 +16,14 @@ package rtc
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
 	"github.com/pion/sdp/v3"
 	"github.com/pion/webrtc/v3"
 	"github.com/stretchr/testify/require"
@@ -28,6 +31,8 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/rtc/transport"
 	"github.com/livekit/livekit-server/pkg/rtc/transport/transportfakes"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/testutils"
 	"github.com/livekit/protocol/livekit"
 )
@@ -383,6 +388,90 @@ func TestNegotiationFailed(t *testing.T) {
 	transportA.Close()
 }
 
+func TestOfferCollision(t *testing.T) {
+	params := TransportParams{
+		ParticipantID:       "id",
+		ParticipantIdentity: "identity",
+		Config:              &WebRTCConfig{},
+		IsOfferer:           true,
+	}
+
+	paramsA := params
+	handlerA := &transportfakes.FakeHandler{}
+	paramsA.Handler = handlerA
+	transportA, err := NewPCTransport(paramsA)
+	require.NoError(t, err)
+	_, err = transportA.pc.CreateDataChannel(ReliableDataChannel, nil)
+	require.NoError(t, err)
+
+	paramsB := params
+	handlerB := &transportfakes.FakeHandler{}
+	paramsB.Handler = handlerB
+	paramsB.IsOfferer = false
+	transportB, err := NewPCTransport(paramsB)
+	require.NoError(t, err)
+
+	handleICEExchange(t, transportA, transportB, handlerA, handlerB)
+	connectTransports(t, transportA, transportB, handlerA, handlerB, false, 1, 1)
+
+	// hold back offer of A while B offers at the same time
+	var offer atomic.Value
+	handlerA.OnOfferCalls(func(sd webrtc.SessionDescription) error {
+		offer.Store(sd)
+		return nil
+	})
+	transportA.Negotiate(true)
+	require.Eventually(t, func() bool {
+		return offer.Load() != nil
+	}, 10*time.Second, 10*time.Millisecond, "transportA offer not sent")
+
+	remoteOffer, err := transportB.pc.CreateOffer(nil)
+	require.NoError(t, err)
+	transportA.HandleRemoteDescription(remoteOffer)
+
+	// offerer is impolite, keeps its offer and waits for an answer
+	require.Eventually(t, func() bool {
+		for _, entry := range transportA.GetNegotiationTrace() {
+			if entry.Event == "IGNORE_COLLIDING_OFFER" {
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 10*time.Millisecond, "colliding offer not ignored")
+	require.Equal(t, webrtc.SignalingStateHaveLocalOffer, transportA.pc.SignalingState())
+
+	handlerB.OnAnswerCalls(func(answer webrtc.SessionDescription) error {
+		transportA.HandleRemoteDescription(answer)
+		return nil
+	})
+	transportB.HandleRemoteDescription(offer.Load().(webrtc.SessionDescription))
+	require.Eventually(t, func() bool {
+		return transportA.pc.SignalingState() == webrtc.SignalingStateStable
+	}, 10*time.Second, 10*time.Millisecond, "negotiation did not complete")
+	require.Zero(t, handlerA.OnNegotiationFailedCallCount())
+
+	// answerer is impolite too, it does not roll back a pending local offer
+	localOffer, err := transportB.pc.CreateOffer(nil)
+	require.NoError(t, err)
+	require.NoError(t, transportB.pc.SetLocalDescription(localOffer))
+	remoteOffer, err = transportA.pc.CreateOffer(nil)
+	require.NoError(t, err)
+	transportB.HandleRemoteDescription(remoteOffer)
+	require.Eventually(t, func() bool {
+		for _, entry := range transportB.GetNegotiationTrace() {
+			if entry.Event == "IGNORE_COLLIDING_OFFER" {
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 10*time.Millisecond, "colliding offer not ignored by answerer")
+	require.Equal(t, webrtc.SignalingStateHaveLocalOffer, transportB.pc.SignalingState())
+	require.Zero(t, handlerB.OnNegotiationFailedCallCount())
+
+	transportA.Close()
+	transportB.Close()
+}
+
 func TestFilteringCandidates(t *testing.T) {
 	params := TransportParams{
 		ParticipantID:       "id",
@@ -504,6 +593,161 @@ func TestFilteringCandidates(t *testing.T) {
 	transport.Close()
 }
 
+func TestICEIPFamilyHeadStart(t *testing.T) {
+	newTransport := func(t *testing.T) *PCTransport {
+		transport, err := NewPCTransport(TransportParams{
+			ParticipantID:       "id",
+			ParticipantIdentity: "identity",
+			Config: &WebRTCConfig{
+				// long enough to not fire, elapsing is driven by the test
+				ICEIPFamily: ICEIPFamilyConfig{Prefer: types.ICEIPFamilyIPv4, HeadStart: time.Hour},
+			},
+			Handler: &transportfakes.FakeHandler{},
+		})
+		require.NoError(t, err)
+		t.Cleanup(transport.Close)
+		return transport
+	}
+	remoteCandidate := func(transport *PCTransport, candidate string) {
+		require.NoError(t, transport.handleRemoteICECandidate(event{
+			PCTransport: transport,
+			signal:      signalRemoteICECandidate,
+			data:        &webrtc.ICECandidateInit{Candidate: candidate},
+		}))
+	}
+	headStartElapsed := func(transport *PCTransport, generation uint32) {
+		require.NoError(t, transport.handleICEIPFamilyHeadStartElapsed(event{
+			PCTransport: transport,
+			signal:      signalICEIPFamilyHeadStartElapsed,
+			data:        generation,
+		}))
+	}
+	const (
+		ipv4Candidate = "candidate:1 1 udp 2130706431 192.168.1.1 5000 typ host"
+		ipv6Candidate = "candidate:2 1 udp 2130706431 2001:db8::1 5000 typ host"
+	)
+
+	t.Run("non-preferred family is held until head start elapses", func(t *testing.T) {
+		transport := newTransport(t)
+
+		remoteCandidate(transport, ipv6Candidate)
+		require.Len(t, transport.heldRemoteCandidates, 1)
+		require.NotNil(t, transport.iceIPFamilyHeadStartTimer)
+
+		// preferred family is not held
+		remoteCandidate(transport, ipv4Candidate)
+		require.Len(t, transport.heldRemoteCandidates, 1)
+		require.Len(t, transport.pendingRemoteCandidates, 1)
+
+		headStartElapsed(transport, transport.iceIPFamilyHeadStartGeneration)
+		require.Empty(t, transport.heldRemoteCandidates)
+		require.Len(t, transport.pendingRemoteCandidates, 2)
+
+		// not held after head start
+		remoteCandidate(transport, ipv6Candidate)
+		require.Empty(t, transport.heldRemoteCandidates)
+		require.Len(t, transport.pendingRemoteCandidates, 3)
+	})
+
+	t.Run("head start of previous ICE generation is ignored", func(t *testing.T) {
+		transport := newTransport(t)
+
+		remoteCandidate(transport, ipv6Candidate)
+		staleGeneration := transport.iceIPFamilyHeadStartGeneration
+
+		// ICE restart, head start starts again with the next held candidate
+		transport.clearLocalDescriptionSent()
+		require.Empty(t, transport.heldRemoteCandidates)
+		remoteCandidate(transport, ipv6Candidate)
+		require.Len(t, transport.heldRemoteCandidates, 1)
+
+		headStartElapsed(transport, staleGeneration)
+		require.Len(t, transport.heldRemoteCandidates, 1)
+		require.False(t, transport.iceIPFamilyHeadStartElapsed)
+
+		headStartElapsed(transport, transport.iceIPFamilyHeadStartGeneration)
+		require.Empty(t, transport.heldRemoteCandidates)
+		require.True(t, transport.iceIPFamilyHeadStartElapsed)
+	})
+}
+
+func TestActiveTCP(t *testing.T) {
+	loopbackOnly := func(se *webrtc.SettingEngine) {
+		se.SetNetworkTypes([]webrtc.NetworkType{webrtc.NetworkTypeTCP4})
+		se.SetIncludeLoopbackCandidate(true)
+		se.SetIPFilter(func(ip net.IP) bool { return ip.IsLoopback() })
+	}
+
+	newTransports := func(t *testing.T, enableActiveTCP bool) (*PCTransport, *PCTransport, *transportfakes.FakeHandler, *transportfakes.FakeHandler) {
+		// client accepting ICE-TCP connections only, e. g. server's TCP port blocked by a firewall
+		listener, err := net.Listen("tcp4", "127.0.0.1:0")
+		require.NoError(t, err)
+		clientConfig := &WebRTCConfig{}
+		loopbackOnly(&clientConfig.SettingEngine)
+		clientConfig.SettingEngine.SetICETCPMux(webrtc.NewICETCPMux(nil, listener, 8))
+		t.Cleanup(func() { listener.Close() })
+
+		// server without ICE-TCP port, can connect only by dialing out
+		serverConfig := &WebRTCConfig{EnableActiveTCP: enableActiveTCP}
+		loopbackOnly(&serverConfig.SettingEngine)
+		serverConfig.SettingEngine.DisableActiveTCP(true)
+
+		clientHandler := &transportfakes.FakeHandler{}
+		client, err := NewPCTransport(TransportParams{
+			ParticipantID:       "client",
+			ParticipantIdentity: "client",
+			Config:              clientConfig,
+			IsOfferer:           true,
+			Handler:             clientHandler,
+		})
+		require.NoError(t, err)
+		t.Cleanup(client.Close)
+		_, err = client.pc.CreateDataChannel(ReliableDataChannel, nil)
+		require.NoError(t, err)
+
+		serverHandler := &transportfakes.FakeHandler{}
+		server, err := NewPCTransport(TransportParams{
+			ParticipantID:       "server",
+			ParticipantIdentity: "server",
+			Config:              serverConfig,
+			PreferTCP:           true,
+			Handler:             serverHandler,
+		})
+		require.NoError(t, err)
+		t.Cleanup(server.Close)
+
+		handleICEExchange(t, client, server, clientHandler, serverHandler)
+		return client, server, clientHandler, serverHandler
+	}
+
+	t.Run("server dials out to client preferring TCP", func(t *testing.T) {
+		client, server, clientHandler, serverHandler := newTransports(t, true)
+		connectTransports(t, client, server, clientHandler, serverHandler, false, 1, 1)
+
+		pair, err := server.pc.SCTP().Transport().ICETransport().GetSelectedCandidatePair()
+		require.NoError(t, err)
+		require.Equal(t, webrtc.ICEProtocolTCP, pair.Local.Protocol)
+		require.Equal(t, "active", pair.Local.TCPType)
+	})
+
+	t.Run("server does not dial out when disabled", func(t *testing.T) {
+		client, server, clientHandler, serverHandler := newTransports(t, false)
+		serverHandler.OnAnswerCalls(func(answer webrtc.SessionDescription) error {
+			client.HandleRemoteDescription(answer)
+			return nil
+		})
+		clientHandler.OnOfferCalls(func(offer webrtc.SessionDescription) error {
+			server.HandleRemoteDescription(offer)
+			return nil
+		})
+		client.Negotiate(true)
+
+		require.Never(t, func() bool {
+			return server.pc.ICEConnectionState() == webrtc.ICEConnectionStateConnected
+		}, 3*time.Second, 100*time.Millisecond)
+	})
+}
+
 func handleICEExchange(t *testing.T, a, b *PCTransport, ah, bh *transportfakes.FakeHandler) {
 	ah.OnICECandidateCalls(func(candidate *webrtc.ICECandidate, target livekit.SignalTarget) error {
 		if candidate == nil {
@@ -586,6 +830,100 @@ func untilTransportsConnected(transports ...*transportfakes.FakeHandler) *sync.W
 	return &triggered
 }
 
+func TestPlaceholderTrack(t *testing.T) {
+	transport, err := NewPCTransport(TransportParams{
+		ParticipantID:       "id",
+		ParticipantIdentity: "identity",
+		Config:              &WebRTCConfig{},
+		IsOfferer:           true,
+		Handler:             &transportfakes.FakeHandler{},
+	})
+	require.NoError(t, err)
+	defer transport.Close()
+
+	require.NoError(t, transport.AddPlaceholderTrack(newPlaceholderTrack("TR_VC1", "PA_1", livekit.TrackType_VIDEO)))
+	require.NoError(t, transport.AddPlaceholderTrack(newPlaceholderTrack("TR_AM1", "PA_1", livekit.TrackType_AUDIO)))
+	require.Len(t, transport.pc.GetTransceivers(), 2)
+
+	// track added later takes over the reserved transceiver
+	track, err := webrtc.NewTrackLocalStaticRTP(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8}, "TR_VC1", "PA_1")
+	require.NoError(t, err)
+	sender, transceiver, err := transport.AddTransceiverFromTrack(track, types.AddTrackParams{})
+	require.NoError(t, err)
+	require.Equal(t, transport.pc.GetTransceivers()[0], transceiver)
+	require.Equal(t, webrtc.TrackLocal(track), sender.Track())
+	require.Len(t, transport.pc.GetTransceivers(), 2)
+
+	// taken over placeholder cannot be removed, unused one can
+	require.False(t, transport.RemovePlaceholderTrack("TR_VC1"))
+	require.True(t, transport.RemovePlaceholderTrack("TR_AM1"))
+	require.Nil(t, transport.pc.GetTransceivers()[1].Sender())
+}
+
+func TestRecycleStoppedTransceiver(t *testing.T) {
+	params := TransportParams{
+		ParticipantID:       "id",
+		ParticipantIdentity: "identity",
+		Config:              &WebRTCConfig{},
+		EnabledCodecs:       []*livekit.Codec{{Mime: webrtc.MimeTypeOpus}},
+		IsOfferer:           true,
+	}
+
+	paramsA := params
+	handlerA := &transportfakes.FakeHandler{}
+	paramsA.Handler = handlerA
+	transportA, err := NewPCTransport(paramsA)
+	require.NoError(t, err)
+	defer transportA.Close()
+	_, err = transportA.pc.CreateDataChannel(ReliableDataChannel, nil)
+	require.NoError(t, err)
+
+	paramsB := params
+	handlerB := &transportfakes.FakeHandler{}
+	paramsB.Handler = handlerB
+	paramsB.IsOfferer = false
+	transportB, err := NewPCTransport(paramsB)
+	require.NoError(t, err)
+	defer transportB.Close()
+
+	handleICEExchange(t, transportA, transportB, handlerA, handlerB)
+	connectTransports(t, transportA, transportB, handlerA, handlerB, false, 1, 1)
+
+	negotiate := func() {
+		answerCount := handlerB.OnAnswerCallCount()
+		transportA.Negotiate(true)
+		require.Eventually(t, func() bool {
+			return handlerB.OnAnswerCallCount() > answerCount && transportA.pc.SignalingState() == webrtc.SignalingStateStable
+		}, 10*time.Second, 10*time.Millisecond)
+	}
+
+	track1, err := webrtc.NewTrackLocalStaticRTP(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus}, "TR_A1", "PA_1")
+	require.NoError(t, err)
+	_, transceiver, err := transportA.AddTrack(track1, types.AddTrackParams{})
+	require.NoError(t, err)
+	negotiate()
+	mid := transceiver.Mid()
+	require.NotEmpty(t, mid)
+
+	// stopped transceiver keeps its sender, and is not re-used till remote has answered it inactive
+	require.NoError(t, transceiver.Stop())
+	require.NotNil(t, transceiver.Sender())
+	negotiate()
+	require.Eventually(t, func() bool {
+		return transceiver.Sender() == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// new track takes over the stopped transceiver instead of adding one
+	numTransceivers := len(transportA.pc.GetTransceivers())
+	track2, err := webrtc.NewTrackLocalStaticRTP(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus}, "TR_A2", "PA_1")
+	require.NoError(t, err)
+	_, recycled, err := transportA.AddTrack(track2, types.AddTrackParams{})
+	require.NoError(t, err)
+	require.Equal(t, transceiver, recycled)
+	require.Equal(t, mid, recycled.Mid())
+	require.Len(t, transportA.pc.GetTransceivers(), numTransceivers)
+}
+
 func TestConfigureAudioTransceiver(t *testing.T) {
 	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
 	require.NoError(t, err)
@@ -622,3 +960,297 @@ func TestConfigureAudioTransceiver(t *testing.T) {
 		})
 	}
 }
+
+func TestAddRTXRepairStreamsToSDP(t *testing.T) {
+	s := &sdp.SessionDescription{
+		MediaDescriptions: []*sdp.MediaDescription{
+			{
+				MediaName: sdp.MediaName{Media: "audio"},
+				Attributes: []sdp.Attribute{
+					{Key: "rtpmap", Value: "111 opus/48000/2"},
+					{Key: sdp.AttrKeySSRC, Value: "1111 cname:stream"},
+				},
+			},
+			{
+				MediaName: sdp.MediaName{Media: "video"},
+				Attributes: []sdp.Attribute{
+					{Key: "rtpmap", Value: "96 VP8/90000"},
+					{Key: "rtpmap", Value: "97 rtx/90000"},
+					{Key: sdp.AttrKeySSRC, Value: "2222 cname:stream"},
+					{Key: sdp.AttrKeySSRC, Value: "2222 msid:stream track"},
+				},
+			},
+		},
+	}
+	rtxSSRCs, modified := addRTXRepairStreamsToSDP(s, nil)
+	require.True(t, modified)
+
+	// audio section is untouched
+	require.Len(t, s.MediaDescriptions[0].Attributes, 2)
+
+	rtxSSRC := sfu.RTXSSRCForPrimary(2222)
+	require.Equal(t, []sdp.Attribute{
+		{Key: "rtpmap", Value: "96 VP8/90000"},
+		{Key: "rtpmap", Value: "97 rtx/90000"},
+		{Key: sdp.AttrKeySSRCGroup, Value: fmt.Sprintf("FID 2222 %d", rtxSSRC)},
+		{Key: sdp.AttrKeySSRC, Value: "2222 cname:stream"},
+		{Key: sdp.AttrKeySSRC, Value: "2222 msid:stream track"},
+		{Key: sdp.AttrKeySSRC, Value: fmt.Sprintf("%d cname:stream", rtxSSRC)},
+		{Key: sdp.AttrKeySSRC, Value: fmt.Sprintf("%d msid:stream track", rtxSSRC)},
+	}, s.MediaDescriptions[1].Attributes)
+	require.Equal(t, map[uint32]uint32{2222: rtxSSRC}, rtxSSRCs)
+
+	// already signalled repair streams are not added again
+	rtxSSRCs, modified = addRTXRepairStreamsToSDP(s, nil)
+	require.False(t, modified)
+	require.Equal(t, map[uint32]uint32{2222: rtxSSRC}, rtxSSRCs)
+}
+
+func TestPruneCodecs(t *testing.T) {
+	codec := func(pt webrtc.PayloadType, mimeType string, fmtp string) webrtc.RTPCodecParameters {
+		return webrtc.RTPCodecParameters{
+			PayloadType:        pt,
+			RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: mimeType, SDPFmtpLine: fmtp},
+		}
+	}
+	payloadTypes := func(codecs []webrtc.RTPCodecParameters) []webrtc.PayloadType {
+		var pts []webrtc.PayloadType
+		for _, c := range codecs {
+			pts = append(pts, c.PayloadType)
+		}
+		return pts
+	}
+
+	t.Run("video with rtx", func(t *testing.T) {
+		codecs := []webrtc.RTPCodecParameters{
+			codec(96, webrtc.MimeTypeVP8, ""),
+			codec(97, "video/rtx", "apt=96"),
+			codec(98, webrtc.MimeTypeH264, "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f"),
+			codec(99, "video/rtx", "apt=98"),
+		}
+		pruned := pruneCodecs(codecs, []webrtc.PayloadType{96})
+		require.Equal(t, []webrtc.PayloadType{96, 97}, payloadTypes(pruned))
+
+		// nothing left to prune
+		require.Nil(t, pruneCodecs(pruned, []webrtc.PayloadType{96}))
+	})
+
+	t.Run("audio received with red", func(t *testing.T) {
+		codecs := []webrtc.RTPCodecParameters{
+			codec(63, sfu.MimeTypeAudioRed, "111/111"),
+			codec(111, webrtc.MimeTypeOpus, "minptime=10;useinbandfec=1"),
+			codec(0, webrtc.MimeTypePCMU, ""),
+		}
+		require.Equal(t, []webrtc.PayloadType{63, 111}, payloadTypes(pruneCodecs(codecs, []webrtc.PayloadType{63})))
+	})
+
+	t.Run("audio keeps offered red", func(t *testing.T) {
+		codecs := []webrtc.RTPCodecParameters{
+			codec(111, webrtc.MimeTypeOpus, "minptime=10;useinbandfec=1"),
+			codec(63, sfu.MimeTypeAudioRed, "111/111"),
+			codec(0, webrtc.MimeTypePCMU, ""),
+		}
+		require.Equal(t, []webrtc.PayloadType{111, 63}, payloadTypes(pruneCodecs(codecs, []webrtc.PayloadType{111})))
+
+		// red is dropped along with the codec it carries
+		require.Equal(t, []webrtc.PayloadType{0}, payloadTypes(pruneCodecs(codecs, []webrtc.PayloadType{0})))
+	})
+
+	t.Run("unknown payload type", func(t *testing.T) {
+		codecs := []webrtc.RTPCodecParameters{
+			codec(96, webrtc.MimeTypeVP8, ""),
+			codec(98, webrtc.MimeTypeH264, ""),
+		}
+		require.Nil(t, pruneCodecs(codecs, []webrtc.PayloadType{100}))
+	})
+}
+
+func TestPruneAnswerCodecs(t *testing.T) {
+	params := TransportParams{
+		ParticipantID:       "id",
+		ParticipantIdentity: "identity",
+		Config:              &WebRTCConfig{},
+		EnabledCodecs:       []*livekit.Codec{{Mime: webrtc.MimeTypeVP8}, {Mime: webrtc.MimeTypeH264}},
+		IsOfferer:           true,
+	}
+
+	paramsA := params
+	handlerA := &transportfakes.FakeHandler{}
+	paramsA.Handler = handlerA
+	transportA, err := NewPCTransport(paramsA)
+	require.NoError(t, err)
+	defer transportA.Close()
+	_, err = transportA.pc.CreateDataChannel(ReliableDataChannel, nil)
+	require.NoError(t, err)
+
+	paramsB := params
+	paramsB.Config = &WebRTCConfig{PruneAnswerCodecs: true}
+	handlerB := &transportfakes.FakeHandler{}
+	paramsB.Handler = handlerB
+	paramsB.IsOfferer = false
+	transportB, err := NewPCTransport(paramsB)
+	require.NoError(t, err)
+	defer transportB.Close()
+
+	handleICEExchange(t, transportA, transportB, handlerA, handlerB)
+	connectTransports(t, transportA, transportB, handlerA, handlerB, false, 1, 1)
+
+	negotiate := func() webrtc.SessionDescription {
+		answerCount := handlerB.OnAnswerCallCount()
+		transportA.Negotiate(true)
+		require.Eventually(t, func() bool {
+			return handlerB.OnAnswerCallCount() > answerCount && transportA.pc.SignalingState() == webrtc.SignalingStateStable
+		}, 10*time.Second, 10*time.Millisecond)
+		return handlerB.OnAnswerArgsForCall(handlerB.OnAnswerCallCount() - 1)
+	}
+	videoFormats := func(sd webrtc.SessionDescription) []string {
+		parsed, err := sd.Unmarshal()
+		require.NoError(t, err)
+		for _, media := range parsed.MediaDescriptions {
+			if media.MediaName.Media == "video" {
+				return media.MediaName.Formats
+			}
+		}
+		return nil
+	}
+
+	track, err := webrtc.NewTrackLocalStaticRTP(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8}, "TR_V1", "PA_1")
+	require.NoError(t, err)
+	_, _, err = transportA.AddTrack(track, types.AddTrackParams{})
+	require.NoError(t, err)
+	numFormats := len(videoFormats(negotiate()))
+
+	// codecs are pruned once the track is received
+	require.Eventually(t, func() bool {
+		_ = track.WriteRTP(&rtp.Packet{Header: rtp.Header{Version: 2, PayloadType: 96}, Payload: []byte{0x10, 0x00}})
+		return handlerB.OnTrackCallCount() > 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	answer := negotiate()
+	formats := videoFormats(answer)
+	require.Less(t, len(formats), numFormats)
+
+	remote := transportA.pc.RemoteDescription()
+	require.NotNil(t, remote)
+	require.Equal(t, answer.SDP, remote.SDP)
+	local := transportB.pc.LocalDescription()
+	require.NotNil(t, local)
+	require.Equal(t, formats, videoFormats(*local))
+}
+
+func TestAddRTXRepairStreamsToSDPCollision(t *testing.T) {
+	videoMedia := func(ssrc uint32) *sdp.MediaDescription {
+		return &sdp.MediaDescription{
+			MediaName: sdp.MediaName{Media: "video"},
+			Attributes: []sdp.Attribute{
+				{Key: "rtpmap", Value: "96 VP8/90000"},
+				{Key: "rtpmap", Value: "97 rtx/90000"},
+				{Key: sdp.AttrKeySSRC, Value: fmt.Sprintf("%d cname:stream", ssrc)},
+			},
+		}
+	}
+
+	// second track's primary SSRC is what the first track's repair SSRC would be derived as
+	derived := sfu.RTXSSRCForPrimary(2222)
+	s := &sdp.SessionDescription{
+		MediaDescriptions: []*sdp.MediaDescription{videoMedia(2222), videoMedia(derived)},
+	}
+	rtxSSRCs, modified := addRTXRepairStreamsToSDP(s, nil)
+	require.True(t, modified)
+	require.Len(t, rtxSSRCs, 2)
+	require.NotEqual(t, derived, rtxSSRCs[2222])
+	require.NotEqual(t, uint32(2222), rtxSSRCs[derived])
+	require.NotEqual(t, rtxSSRCs[2222], rtxSSRCs[derived])
+	require.Equal(t, sdp.Attribute{
+		Key:   sdp.AttrKeySSRCGroup,
+		Value: fmt.Sprintf("FID 2222 %d", rtxSSRCs[2222]),
+	}, s.MediaDescriptions[0].Attributes[2])
+
+	// repair SSRCs are kept stable across offers
+	prev := rtxSSRCs
+	s = &sdp.SessionDescription{
+		MediaDescriptions: []*sdp.MediaDescription{videoMedia(2222), videoMedia(derived)},
+	}
+	rtxSSRCs, modified = addRTXRepairStreamsToSDP(s, prev)
+	require.True(t, modified)
+	require.Equal(t, prev, rtxSSRCs)
+}
+
+func TestTWCCExtIDForStream(t *testing.T) {
+	twccFeedback := []interceptor.RTCPFeedback{{Type: webrtc.TypeRTCPFBTransportCC}}
+	twccExtension := []interceptor.RTPHeaderExtension{{URI: sdp.TransportCCURI, ID: 3}}
+
+	for _, testcase := range []struct {
+		name     string
+		info     *interceptor.StreamInfo
+		expected uint8
+	}{
+		{
+			name:     "video",
+			info:     &interceptor.StreamInfo{MimeType: "video/VP8", RTCPFeedback: twccFeedback, RTPHeaderExtensions: twccExtension},
+			expected: 3,
+		},
+		{
+			name:     "audio",
+			info:     &interceptor.StreamInfo{MimeType: "audio/opus", RTCPFeedback: twccFeedback, RTPHeaderExtensions: twccExtension},
+			expected: 3,
+		},
+		{
+			name:     "audio without feedback",
+			info:     &interceptor.StreamInfo{MimeType: "audio/opus", RTPHeaderExtensions: twccExtension},
+			expected: 0,
+		},
+		{
+			name:     "rtx without feedback",
+			info:     &interceptor.StreamInfo{MimeType: "video/rtx", RTPHeaderExtensions: twccExtension},
+			expected: 3,
+		},
+		{
+			name:     "without extension",
+			info:     &interceptor.StreamInfo{MimeType: "video/VP8", RTCPFeedback: twccFeedback},
+			expected: 0,
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			require.Equal(t, testcase.expected, twccExtIDForStream(testcase.info))
+		})
+	}
+}
+
+func TestHasBWEFeedback(t *testing.T) {
+	videoMedia := func(port int, attrs ...sdp.Attribute) *sdp.MediaDescription {
+		return &sdp.MediaDescription{
+			MediaName:  sdp.MediaName{Media: "video", Port: sdp.RangedPort{Value: port}},
+			Attributes: append([]sdp.Attribute{{Key: "rtpmap", Value: "96 VP8/90000"}}, attrs...),
+		}
+	}
+	transportCC := sdp.Attribute{Key: sdp.AttrKeyExtMap, Value: "3 " + sdp.TransportCCURI}
+	remb := sdp.Attribute{Key: "rtcp-fb", Value: "96 goog-remb"}
+
+	// no video
+	hasVideo, _ := hasBWEFeedback(&sdp.SessionDescription{
+		MediaDescriptions: []*sdp.MediaDescription{{MediaName: sdp.MediaName{Media: "audio", Port: sdp.RangedPort{Value: 9}}}},
+	}, true)
+	require.False(t, hasVideo)
+
+	// rejected video section
+	hasVideo, _ = hasBWEFeedback(&sdp.SessionDescription{MediaDescriptions: []*sdp.MediaDescription{videoMedia(0, transportCC)}}, true)
+	require.False(t, hasVideo)
+
+	hasVideo, hasFeedback := hasBWEFeedback(&sdp.SessionDescription{MediaDescriptions: []*sdp.MediaDescription{videoMedia(9, transportCC)}}, true)
+	require.True(t, hasVideo)
+	require.True(t, hasFeedback)
+
+	// REMB does not count with send side estimation
+	hasVideo, hasFeedback = hasBWEFeedback(&sdp.SessionDescription{MediaDescriptions: []*sdp.MediaDescription{videoMedia(9, remb)}}, true)
+	require.True(t, hasVideo)
+	require.False(t, hasFeedback)
+
+	hasVideo, hasFeedback = hasBWEFeedback(&sdp.SessionDescription{MediaDescriptions: []*sdp.MediaDescription{videoMedia(9, remb)}}, false)
+	require.True(t, hasVideo)
+	require.True(t, hasFeedback)
+
+	hasVideo, hasFeedback = hasBWEFeedback(&sdp.SessionDescription{MediaDescriptions: []*sdp.MediaDescription{videoMedia(9)}}, false)
+	require.True(t, hasVideo)
+	require.False(t, hasFeedback)
+}
@@ -15,6 +15,7 @@
 package rtc
 
 import (
+	"context"
 	"math/bits"
 	"sync"
 	"time"
@@ -31,10 +32,13 @@ import (
 	"github.com/livekit/protocol/logger"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/pcap"
 	"github.com/livekit/livekit-server/pkg/rtc/transport"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
+	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 )
 
 const (
@@ -45,6 +49,14 @@ const (
 	udpLossFracUnstable = 25
 	// if in last 32 times RR, the unstable report count over this threshold, the connection is unstable
 	udpLossUnstableCountThreshold = 20
+
+	// no receiver report from subscriber for this long (a few consecutive RTCP intervals) means media path loss
+	networkTransitionRRSilence = 3 * time.Second
+	// together with media path loss, missing at least one signal keepalive points to the client switching networks
+	networkTransitionSignalSilence = PingIntervalSeconds*time.Second + time.Second
+	networkTransitionCheckInterval = time.Second
+	// minimum time between ICE restarts triggered by network transition
+	networkTransitionMinInterval = 30 * time.Second
 )
 
 type TransportManagerTransportHandler struct {
@@ -76,10 +88,12 @@ type TransportManagerParams struct {
 	CongestionControlConfig      config.CongestionControlConfig
 	EnabledSubscribeCodecs       []*livekit.Codec
 	EnabledPublishCodecs         []*livekit.Codec
+	CodecPreferenceOrder         bool
 	SimTracks                    map[uint32]SimulcastTrackInfo
 	ClientInfo                   ClientInfo
 	Migration                    bool
 	AllowTCPFallback             bool
+	ICEConfig                    *livekit.ICEConfig
 	TCPFallbackRTTThreshold      int
 	AllowUDPUnstableFallback     bool
 	TURNSEnabled                 bool
@@ -88,6 +102,7 @@ type TransportManagerParams struct {
 	Logger                       logger.Logger
 	PublisherHandler             transport.Handler
 	SubscriberHandler            transport.Handler
+	TraceContext                 context.Context
 }
 
 type TransportManager struct {
@@ -113,7 +128,15 @@ type TransportManager struct {
 	udpLossUnstableCount uint32
 	signalingRTT, udpRTT uint32
 
-	onICEConfigChanged func(iceConfig *livekit.ICEConfig)
+	lastReceiverReportAt    time.Time
+	networkTransitionTimer  *time.Timer
+	lastNetworkTransitionAt time.Time
+	isClosed                bool
+
+	onICEConfigChanged  func(iceConfig *livekit.ICEConfig)
+	onNetworkTransition func()
+
+	packetCapture *pcap.Capture
 }
 
 func NewTransportManager(params TransportManagerParams) (*TransportManager, error) {
@@ -136,11 +159,14 @@ func NewTransportManager(params TransportManagerParams) (*TransportManager, erro
 		DirectionConfig:         params.Config.Publisher,
 		CongestionControlConfig: params.CongestionControlConfig,
 		EnabledCodecs:           params.EnabledPublishCodecs,
+		CodecPreferenceOrder:    params.CodecPreferenceOrder,
 		Logger:                  LoggerWithPCTarget(params.Logger, livekit.SignalTarget_PUBLISHER),
 		SimTracks:               params.SimTracks,
 		ClientInfo:              params.ClientInfo,
 		Transport:               livekit.SignalTarget_PUBLISHER,
+		PreferTCP:               params.ICEConfig.GetPreferencePublisher() == livekit.ICECandidateType_ICT_TCP,
 		Handler:                 TransportManagerPublisherTransportHandler{TransportManagerTransportHandler{params.PublisherHandler, t}},
+		TraceContext:            params.TraceContext,
 	})
 	if err != nil {
 		return nil, err
@@ -155,6 +181,7 @@ func NewTransportManager(params TransportManagerParams) (*TransportManager, erro
 		DirectionConfig:              params.Config.Subscriber,
 		CongestionControlConfig:      params.CongestionControlConfig,
 		EnabledCodecs:                params.EnabledSubscribeCodecs,
+		CodecPreferenceOrder:         params.CodecPreferenceOrder,
 		Logger:                       LoggerWithPCTarget(params.Logger, livekit.SignalTarget_SUBSCRIBER),
 		ClientInfo:                   params.ClientInfo,
 		IsOfferer:                    true,
@@ -162,7 +189,9 @@ func NewTransportManager(params TransportManagerParams) (*TransportManager, erro
 		AllowPlayoutDelay:            params.AllowPlayoutDelay,
 		DataChannelMaxBufferedAmount: params.DataChannelMaxBufferedAmount,
 		Transport:                    livekit.SignalTarget_SUBSCRIBER,
+		PreferTCP:                    params.ICEConfig.GetPreferenceSubscriber() == livekit.ICECandidateType_ICT_TCP,
 		Handler:                      TransportManagerTransportHandler{params.SubscriberHandler, t},
+		TraceContext:                 params.TraceContext,
 	})
 	if err != nil {
 		return nil, err
@@ -181,6 +210,23 @@ func NewTransportManager(params TransportManagerParams) (*TransportManager, erro
 func (t *TransportManager) Close() {
 	t.publisher.Close()
 	t.subscriber.Close()
+
+	t.lock.Lock()
+	t.isClosed = true
+	if t.networkTransitionTimer != nil {
+		t.networkTransitionTimer.Stop()
+		t.networkTransitionTimer = nil
+	}
+	packetCapture := t.packetCapture
+	t.lock.Unlock()
+	if packetCapture != nil {
+		packetCapture.Close()
+	}
+}
+
+// QueuedEvents returns the number of events waiting to be processed by publisher and subscriber transports
+func (t *TransportManager) QueuedEvents() int {
+	return t.publisher.QueuedEvents() + t.subscriber.QueuedEvents()
 }
 
 func (t *TransportManager) SubscriberClose() {
@@ -219,6 +265,14 @@ func (t *TransportManager) AddTransceiverFromTrackToSubscriber(trackLocal webrtc
 	return t.subscriber.AddTransceiverFromTrack(trackLocal, params)
 }
 
+func (t *TransportManager) AddPlaceholderTrackToSubscriber(trackLocal webrtc.TrackLocal) error {
+	return t.subscriber.AddPlaceholderTrack(trackLocal)
+}
+
+func (t *TransportManager) RemovePlaceholderTrackFromSubscriber(trackID livekit.TrackID) bool {
+	return t.subscriber.RemovePlaceholderTrack(string(trackID))
+}
+
 func (t *TransportManager) RemoveTrackFromSubscriber(sender *webrtc.RTPSender) error {
 	return t.subscriber.RemoveTrack(sender)
 }
@@ -231,6 +285,10 @@ func (t *TransportManager) GetSubscriberPacer() pacer.Pacer {
 	return t.subscriber.GetPacer()
 }
 
+func (t *TransportManager) GetSubscriberRTXSSRC(primarySSRC uint32) uint32 {
+	return t.subscriber.GetRTXSSRC(primarySSRC)
+}
+
 func (t *TransportManager) AddSubscribedTrack(subTrack types.SubscribedTrack) {
 	t.subscriber.AddTrackToStreamAllocator(subTrack)
 }
@@ -239,6 +297,10 @@ func (t *TransportManager) RemoveSubscribedTrack(subTrack types.SubscribedTrack)
 	t.subscriber.RemoveTrackFromStreamAllocator(subTrack)
 }
 
+func (t *TransportManager) UpdateSubscribedTrackPriority(subTrack types.SubscribedTrack) {
+	t.subscriber.SetTrackPriorityOfStreamAllocator(subTrack)
+}
+
 func (t *TransportManager) SendDataPacket(kind livekit.DataPacket_Kind, encoded []byte) error {
 	// downstream data is sent via primary peer connection
 	return t.getTransport(true).SendDataPacket(kind, encoded)
@@ -484,6 +546,86 @@ func (t *TransportManager) GetICEConnectionDetails() []*types.ICEConnectionDetai
 	return details
 }
 
+func (t *TransportManager) GetICEStats() []*types.ICEStats {
+	return []*types.ICEStats{t.publisher.GetICEStats(), t.subscriber.GetICEStats()}
+}
+
+func (t *TransportManager) GetNegotiationTraces() map[string][]NegotiationTraceEntry {
+	return map[string][]NegotiationTraceEntry{
+		"Publisher":  t.publisher.GetNegotiationTrace(),
+		"Subscriber": t.subscriber.GetNegotiationTrace(),
+	}
+}
+
+// StartPacketCapture captures decrypted RTP/RTCP of both transports until the capture
+// reaches its duration or is stopped, only one capture runs at a time
+func (t *TransportManager) StartPacketCapture(params pcap.CaptureParams) (*pcap.Capture, error) {
+	t.lock.Lock()
+	if t.packetCapture != nil && !t.packetCapture.IsClosed() {
+		t.lock.Unlock()
+		return nil, ErrPacketCaptureRunning
+	}
+
+	c, err := pcap.NewCapture(params)
+	if err != nil {
+		t.lock.Unlock()
+		return nil, err
+	}
+	t.packetCapture = c
+	t.lock.Unlock()
+
+	t.params.Logger.Infow("packet capture started", "file", c.FirstFile(), "duration", params.Duration)
+	t.publisher.SetPacketCapture(c)
+	t.subscriber.SetPacketCapture(c)
+
+	go func() {
+		<-c.Done()
+
+		t.lock.Lock()
+		if t.packetCapture == c {
+			t.packetCapture = nil
+			t.publisher.SetPacketCapture(nil)
+			t.subscriber.SetPacketCapture(nil)
+		}
+		t.lock.Unlock()
+
+		status := c.Status()
+		t.params.Logger.Infow(
+			"packet capture finished",
+			"files", status.Files,
+			"packetsWritten", status.PacketsWritten,
+			"bytesWritten", status.BytesWritten,
+			"packetsDropped", status.PacketsDropped,
+			"error", status.LastError,
+		)
+	}()
+	return c, nil
+}
+
+func (t *TransportManager) StopPacketCapture() error {
+	t.lock.RLock()
+	c := t.packetCapture
+	t.lock.RUnlock()
+	if c == nil || c.IsClosed() {
+		return ErrPacketCaptureNotRunning
+	}
+
+	c.Close()
+	return nil
+}
+
+// SetNetworkConditions simulates a degraded network between the participant and the SFU, uplink conditions
+// impair media published by the participant as it is received, downlink conditions impair media sent to it,
+// nil for either direction restores it
+func (t *TransportManager) SetNetworkConditions(uplink *netem.Conditions, downlink *netem.Conditions) {
+	t.publisher.SetNetworkConditions(uplink)
+	t.subscriber.SetNetworkConditions(downlink)
+}
+
+func (t *TransportManager) GetNetworkConditions() (*netem.Conditions, *netem.Conditions) {
+	return t.publisher.GetNetworkConditions(), t.subscriber.GetNetworkConditions()
+}
+
 func (t *TransportManager) getTransport(isPrimary bool) *PCTransport {
 	pcTransport := t.publisher
 	if (isPrimary && t.params.SubscriberAsPrimary) || (!isPrimary && !t.params.SubscriberAsPrimary) {
@@ -578,7 +720,11 @@ func (t *TransportManager) handleConnectionFailed(isShortLived bool) {
 	}, false)
 }
 
-func (t *TransportManager) SetMigrateInfo(previousOffer, previousAnswer *webrtc.SessionDescription, dataChannels []*livekit.DataChannelInfo) {
+func (t *TransportManager) SetMigrateInfo(
+	previousOffer, previousAnswer *webrtc.SessionDescription,
+	trackMappings []*types.TrackMapping,
+	dataChannels []*livekit.DataChannelInfo,
+) {
 	t.lock.Lock()
 	t.pendingDataChannelsPublisher = make([]*livekit.DataChannelInfo, 0, len(dataChannels))
 	pendingDataChannelsSubscriber := make([]*livekit.DataChannelInfo, 0, len(dataChannels))
@@ -597,7 +743,12 @@ func (t *TransportManager) SetMigrateInfo(previousOffer, previousAnswer *webrtc.
 		}
 	}
 
-	t.subscriber.SetPreviousSdp(previousOffer, previousAnswer)
+	t.subscriber.SetPreviousSdp(previousOffer, previousAnswer, trackMappings)
+}
+
+// GetTrackMappings returns the transceivers subscribed tracks are sent on, to be handed over on migration
+func (t *TransportManager) GetTrackMappings() []*types.TrackMapping {
+	return t.subscriber.GetTrackMappings()
 }
 
 func (t *TransportManager) ProcessPendingPublisherDataChannels() {
@@ -645,6 +796,69 @@ func (t *TransportManager) ProcessPendingPublisherDataChannels() {
 
 func (t *TransportManager) HandleReceiverReport(dt *sfu.DownTrack, report *rtcp.ReceiverReport) {
 	t.mediaLossProxy.HandleMaxLossFeedback(dt, report)
+	t.subscriber.HandleReceiverReportOfStreamAllocator(report)
+
+	t.lock.Lock()
+	t.lastReceiverReportAt = time.Now()
+	if !t.isClosed {
+		if t.networkTransitionTimer == nil {
+			t.networkTransitionTimer = time.AfterFunc(networkTransitionRRSilence, t.checkNetworkTransition)
+		} else {
+			t.networkTransitionTimer.Reset(networkTransitionRRSilence)
+		}
+	}
+	t.lock.Unlock()
+}
+
+func (t *TransportManager) OnNetworkTransition(f func()) {
+	t.lock.Lock()
+	t.onNetworkTransition = f
+	t.lock.Unlock()
+}
+
+// checkNetworkTransition looks for receiver reports stopping along with signal keepalives,
+// the pattern of a client switching networks (e.g. WiFi to cellular), and restarts ICE right away
+// instead of waiting for ICE to fail on the stale path
+func (t *TransportManager) checkNetworkTransition() {
+	t.lock.Lock()
+	if t.isClosed {
+		t.lock.Unlock()
+		return
+	}
+
+	rrSilence := time.Since(t.lastReceiverReportAt)
+	if rrSilence < networkTransitionRRSilence || rrSilence >= iceFailedTimeoutTotal {
+		// either reports resumed (and timer re-armed) or it is too late, ICE failure handling takes over
+		t.lock.Unlock()
+		return
+	}
+
+	signalSilence := time.Since(t.lastSignalAt)
+	if signalSilence < networkTransitionSignalSilence {
+		// signal still alive, keep watching till either reports resume or signal goes silent too
+		t.networkTransitionTimer.Reset(networkTransitionCheckInterval)
+		t.lock.Unlock()
+		return
+	}
+
+	if time.Since(t.lastNetworkTransitionAt) < networkTransitionMinInterval {
+		t.lock.Unlock()
+		return
+	}
+	t.lastNetworkTransitionAt = time.Now()
+	onNetworkTransition := t.onNetworkTransition
+	t.lock.Unlock()
+
+	t.params.Logger.Infow(
+		"network transition suspected, restarting ICE",
+		"rrSilence", rrSilence,
+		"signalSilence", signalSilence,
+	)
+	t.publisher.ResetShortConnOnICERestart()
+	t.subscriber.ResetShortConnOnICERestart()
+	if onNetworkTransition != nil {
+		onNetworkTransition()
+	}
 }
 
 func (t *TransportManager) onMediaLossUpdate(loss uint8) {
@@ -726,6 +940,14 @@ func (t *TransportManager) SetSubscriberChannelCapacity(channelCapacity int64) {
 	t.subscriber.SetChannelCapacityOfStreamAllocator(channelCapacity)
 }
 
+func (t *TransportManager) SetSubscriberChannelCapacityCeiling(ceiling int64) {
+	t.subscriber.SetChannelCapacityCeilingOfStreamAllocator(ceiling)
+}
+
+func (t *TransportManager) SetSubscriberAllocationPolicy(policy streamallocator.AllocationPolicy) {
+	t.subscriber.SetAllocationPolicyOfStreamAllocator(policy)
+}
+
 func (t *TransportManager) hasRecentSignalLocked() bool {
 	return time.Since(t.lastSignalAt) < PingTimeoutSeconds*time.Second
 }
@@ -0,0 +1,86 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/rtc/transport/transportfakes"
+)
+
+func TestNetworkTransition(t *testing.T) {
+	newTransportManager := func(t *testing.T) (*TransportManager, *int) {
+		tm, err := NewTransportManager(TransportManagerParams{
+			Identity:          "identity",
+			SID:               "id",
+			Config:            &WebRTCConfig{},
+			PublisherHandler:  &transportfakes.FakeHandler{},
+			SubscriberHandler: &transportfakes.FakeHandler{},
+		})
+		require.NoError(t, err)
+		t.Cleanup(tm.Close)
+
+		numTransitions := 0
+		tm.OnNetworkTransition(func() {
+			numTransitions++
+		})
+
+		// receiver reports stopped
+		tm.lastReceiverReportAt = time.Now().Add(-networkTransitionRRSilence - time.Second)
+		tm.networkTransitionTimer = time.AfterFunc(time.Hour, func() {})
+		return tm, &numTransitions
+	}
+
+	t.Run("network changed", func(t *testing.T) {
+		tm, numTransitions := newTransportManager(t)
+		tm.lastSignalAt = time.Now().Add(-networkTransitionSignalSilence - time.Second)
+
+		tm.checkNetworkTransition()
+		require.Equal(t, 1, *numTransitions)
+
+		// not restarted again within min interval
+		tm.checkNetworkTransition()
+		require.Equal(t, 1, *numTransitions)
+	})
+
+	t.Run("network unchanged, signal alive", func(t *testing.T) {
+		tm, numTransitions := newTransportManager(t)
+		tm.lastSignalAt = time.Now()
+
+		tm.checkNetworkTransition()
+		require.Zero(t, *numTransitions)
+	})
+
+	t.Run("network unchanged, receiver reports resumed", func(t *testing.T) {
+		tm, numTransitions := newTransportManager(t)
+		tm.lastSignalAt = time.Now().Add(-networkTransitionSignalSilence - time.Second)
+		tm.lastReceiverReportAt = time.Now()
+
+		tm.checkNetworkTransition()
+		require.Zero(t, *numTransitions)
+	})
+
+	t.Run("too late, ICE failure handling takes over", func(t *testing.T) {
+		tm, numTransitions := newTransportManager(t)
+		tm.lastSignalAt = time.Now().Add(-networkTransitionSignalSilence - time.Second)
+		tm.lastReceiverReportAt = time.Now().Add(-iceFailedTimeoutTotal)
+
+		tm.checkNetworkTransition()
+		require.Zero(t, *numTransitions)
+	})
+}
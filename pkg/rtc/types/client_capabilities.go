@@ -0,0 +1,56 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"slices"
+	"strings"
+)
+
+// ClientCapability is a feature a client opts into with the capabilities connect parameter. It is used for
+// wire formats the signal protocol does not define, as gating those on a protocol version would also enable
+// them for SDKs implementing that version without them.
+type ClientCapability string
+
+const (
+	// client handles dynacast state of its published tracks, sent as user data packets on the lk.dynacast_state topic
+	ClientCapabilityDynacastState ClientCapability = "dynacast_state"
+	// client reassembles data packets larger than a data channel message sent in fragments
+	ClientCapabilityDataFragmentation ClientCapability = "data_fragmentation"
+	// client handles capture time alignment of published tracks, sent as user data packets on the lk.sync_alignment topic
+	ClientCapabilitySyncAlignment ClientCapability = "sync_alignment"
+	// client exchanges RPC frames with the server, sent as user data packets on the lk.rpc topic
+	ClientCapabilityDataRPC ClientCapability = "data_rpc"
+)
+
+type ClientCapabilities []ClientCapability
+
+// ParseClientCapabilities parses a comma separated list of capabilities, unknown ones are ignored
+func ParseClientCapabilities(s string) ClientCapabilities {
+	var caps ClientCapabilities
+	for _, c := range strings.Split(s, ",") {
+		switch capability := ClientCapability(strings.TrimSpace(c)); capability {
+		case ClientCapabilityDynacastState, ClientCapabilityDataFragmentation, ClientCapabilitySyncAlignment, ClientCapabilityDataRPC:
+			if !slices.Contains(caps, capability) {
+				caps = append(caps, capability)
+			}
+		}
+	}
+	return caps
+}
+
+func (c ClientCapabilities) Has(capability ClientCapability) bool {
+	return slices.Contains(c, capability)
+}
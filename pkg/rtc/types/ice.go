@@ -18,6 +18,7 @@ package types
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 
@@ -38,6 +39,36 @@ const (
 	ICEConnectionTypeUnknown ICEConnectionType = "unknown"
 )
 
+type ICEIPFamily string
+
+const (
+	ICEIPFamilyIPv4 ICEIPFamily = "ipv4"
+	ICEIPFamilyIPv6 ICEIPFamily = "ipv6"
+	// e. g. mDNS host names
+	ICEIPFamilyUnknown ICEIPFamily = "unknown"
+)
+
+// ICEIPFamilyOfAddress returns the IP family of a candidate address
+func ICEIPFamilyOfAddress(address string) ICEIPFamily {
+	ip := net.ParseIP(address)
+	switch {
+	case ip == nil:
+		return ICEIPFamilyUnknown
+	case ip.To4() != nil:
+		return ICEIPFamilyIPv4
+	default:
+		return ICEIPFamilyIPv6
+	}
+}
+
+// ICECandidateFamilyStats counts candidates of an IP family
+type ICECandidateFamilyStats struct {
+	Local          int
+	Remote         int
+	LocalFiltered  int
+	RemoteFiltered int
+}
+
 type ICECandidateExtended struct {
 	// only one of local or remote is set. This is due to type foo in Pion
 	Local    *webrtc.ICECandidate
@@ -52,14 +83,17 @@ type ICEConnectionDetails struct {
 	Remote    []*ICECandidateExtended
 	Transport livekit.SignalTarget
 	Type      ICEConnectionType
-	lock      sync.Mutex
-	logger    logger.Logger
+	// IP family of the selected pair
+	Family ICEIPFamily
+	lock   sync.Mutex
+	logger logger.Logger
 }
 
 func NewICEConnectionDetails(transport livekit.SignalTarget, l logger.Logger) *ICEConnectionDetails {
 	d := &ICEConnectionDetails{
 		Transport: transport,
 		Type:      ICEConnectionTypeUnknown,
+		Family:    ICEIPFamilyUnknown,
 		logger:    l,
 	}
 	return d
@@ -78,6 +112,7 @@ func (d *ICEConnectionDetails) Clone() *ICEConnectionDetails {
 	clone := &ICEConnectionDetails{
 		Transport: d.Transport,
 		Type:      d.Type,
+		Family:    d.Family,
 		logger:    d.logger,
 		Local:     make([]*ICECandidateExtended, 0, len(d.Local)),
 		Remote:    make([]*ICECandidateExtended, 0, len(d.Remote)),
@@ -163,6 +198,38 @@ func (d *ICEConnectionDetails) Clear() {
 	d.Local = nil
 	d.Remote = nil
 	d.Type = ICEConnectionTypeUnknown
+	d.Family = ICEIPFamilyUnknown
+}
+
+// CandidateStatsByFamily counts local and remote candidates per IP family
+func (d *ICEConnectionDetails) CandidateStatsByFamily() map[ICEIPFamily]*ICECandidateFamilyStats {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	stats := make(map[ICEIPFamily]*ICECandidateFamilyStats)
+	getStats := func(family ICEIPFamily) *ICECandidateFamilyStats {
+		s := stats[family]
+		if s == nil {
+			s = &ICECandidateFamilyStats{}
+			stats[family] = s
+		}
+		return s
+	}
+	for _, c := range d.Local {
+		s := getStats(ICEIPFamilyOfAddress(c.Local.Address))
+		s.Local++
+		if c.Filtered {
+			s.LocalFiltered++
+		}
+	}
+	for _, c := range d.Remote {
+		s := getStats(ICEIPFamilyOfAddress(c.Remote.Address()))
+		s.Remote++
+		if c.Filtered {
+			s.RemoteFiltered++
+		}
+	}
+	return stats
 }
 
 func (d *ICEConnectionDetails) SetSelectedPair(pair *webrtc.ICECandidatePair) {
@@ -202,6 +269,7 @@ func (d *ICEConnectionDetails) SetSelectedPair(pair *webrtc.ICECandidatePair) {
 	local := d.Local[localIdx]
 	local.Selected = true
 
+	d.Family = ICEIPFamilyOfAddress(pair.Remote.Address)
 	d.Type = ICEConnectionTypeUDP
 	if pair.Remote.Protocol == webrtc.ICEProtocolTCP {
 		d.Type = ICEConnectionTypeTCP
@@ -224,6 +292,38 @@ func (d *ICEConnectionDetails) SetSelectedPair(pair *webrtc.ICECandidatePair) {
 	}
 }
 
+// ICECandidatePairStats is a point in time snapshot of an ICE candidate pair
+type ICECandidatePairStats struct {
+	LocalAddress         string
+	LocalType            string
+	LocalProtocol        string
+	LocalRelayProtocol   string `json:",omitempty"`
+	RemoteAddress        string
+	RemoteType           string
+	RemoteProtocol       string
+	State                string
+	Nominated            bool
+	Selected             bool
+	CurrentRoundTripTime float64 // in seconds
+	BytesSent            uint64
+	BytesReceived        uint64
+}
+
+// ICEStats holds candidate pairs of a peer connection, selected pair first
+type ICEStats struct {
+	Transport livekit.SignalTarget
+	Pairs     []*ICECandidatePairStats
+}
+
+func (s *ICEStats) SelectedPair() *ICECandidatePairStats {
+	for _, pair := range s.Pairs {
+		if pair.Selected {
+			return pair
+		}
+	}
+	return nil
+}
+
 func isCandidateEqualTo(c1, c2 *webrtc.ICECandidate) bool {
 	if c1 == nil && c2 == nil {
 		return true
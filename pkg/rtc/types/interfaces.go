@@ -15,6 +15,7 @@
 package types
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -26,10 +27,13 @@ import (
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/utils"
 
+	"github.com/livekit/livekit-server/pkg/pcap"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
+	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -106,6 +110,7 @@ const (
 	ParticipantCloseReasonDataChannelError
 	ParticipantCloseReasonMigrateCodecMismatch
 	ParticipantCloseReasonSignalSourceClose
+	ParticipantCloseReasonRoomExpired
 )
 
 func (p ParticipantCloseReason) String() string {
@@ -158,6 +163,8 @@ func (p ParticipantCloseReason) String() string {
 		return "MIGRATE_CODEC_MISMATCH"
 	case ParticipantCloseReasonSignalSourceClose:
 		return "SIGNAL_SOURCE_CLOSE"
+	case ParticipantCloseReasonRoomExpired:
+		return "ROOM_EXPIRED"
 	default:
 		return fmt.Sprintf("%d", int(p))
 	}
@@ -180,7 +187,7 @@ func (p ParticipantCloseReason) ToDisconnectReason() livekit.DisconnectReason {
 		return livekit.DisconnectReason_MIGRATION
 	case ParticipantCloseReasonServiceRequestRemoveParticipant:
 		return livekit.DisconnectReason_PARTICIPANT_REMOVED
-	case ParticipantCloseReasonServiceRequestDeleteRoom:
+	case ParticipantCloseReasonServiceRequestDeleteRoom, ParticipantCloseReasonRoomExpired:
 		return livekit.DisconnectReason_ROOM_DELETED
 	case ParticipantCloseReasonSimulateNodeFailure, ParticipantCloseReasonSimulateServerLeave:
 		return livekit.DisconnectReason_SERVER_SHUTDOWN
@@ -291,6 +298,18 @@ type AddTrackParams struct {
 	Red    bool
 }
 
+// PublishIntent is a track a participant has declared it is about to publish,
+// the track ID is reserved for the track
+type PublishIntent struct {
+	TrackID livekit.TrackID
+	Type    livekit.TrackType
+	Source  livekit.TrackSource
+}
+
+// DataRPCHandler handles a request a client sent on the reliable data channel,
+// the returned payload or error is sent back in the response
+type DataRPCHandler func(ctx context.Context, p LocalParticipant, payload string) (string, error)
+
 //counterfeiter:generate . LocalParticipant
 type LocalParticipant interface {
 	Participant
@@ -302,7 +321,9 @@ type LocalParticipant interface {
 	GetLogger() logger.Logger
 	GetAdaptiveStream() bool
 	ProtocolVersion() ProtocolVersion
+	HasClientCapability(capability ClientCapability) bool
 	SupportsSyncStreamID() bool
+	GetPreferredCodecs() []string
 	SupportsTransceiverReuse() bool
 	ConnectedAt() time.Time
 	IsClosed() bool
@@ -317,6 +338,15 @@ type LocalParticipant interface {
 	GetPlayoutDelayConfig() *livekit.PlayoutDelay
 	GetPendingTrack(trackID livekit.TrackID) *livekit.TrackInfo
 	GetICEConnectionDetails() []*ICEConnectionDetails
+	GetICEStats() []*ICEStats
+	StartPacketCapture(params pcap.CaptureParams) (*pcap.Capture, error)
+	StopPacketCapture() error
+	SetNetworkConditions(uplink *netem.Conditions, downlink *netem.Conditions)
+	GetNetworkConditions() (*netem.Conditions, *netem.Conditions)
+	GetUsage() *ParticipantUsage
+	// SampleResourceUsage samples load of the session, goroutines are counted by the caller for all participants at once
+	SampleResourceUsage(goroutines int) ParticipantResourceUsage
+	IsInterestedInDataTopic(topic string) bool
 	HasConnected() bool
 
 	SetResponseSink(sink routing.MessageSink)
@@ -332,9 +362,12 @@ type LocalParticipant interface {
 	SetAttributes(attributes map[string]string)
 	UpdateAudioTrack(update *livekit.UpdateLocalAudioTrack) error
 	UpdateVideoTrack(update *livekit.UpdateLocalVideoTrack) error
+	UpdateTrackName(trackID livekit.TrackID, name string) error
 
 	// permissions
 	ClaimGrants() *auth.ClaimGrants
+	PermissionGroups() []string
+	SetPermissionGroups(groups []string) bool
 	SetPermission(permission *livekit.ParticipantPermission) bool
 	CanPublishSource(source livekit.TrackSource) bool
 	CanSubscribe() bool
@@ -376,6 +409,9 @@ type LocalParticipant interface {
 	SendParticipantUpdate(participants []*livekit.ParticipantInfo) error
 	SendSpeakerUpdate(speakers []*livekit.SpeakerInfo, force bool) error
 	SendDataPacket(kind livekit.DataPacket_Kind, encoded []byte) error
+	// PerformDataRPC sends a request to the client on the reliable data channel and waits for its response,
+	// caller is the participant a request is forwarded from, empty for requests of the server
+	PerformDataRPC(ctx context.Context, caller livekit.ParticipantIdentity, method string, payload string) (string, error)
 	SendRoomUpdate(room *livekit.Room) error
 	SendConnectionQualityUpdate(update *livekit.ConnectionQualityUpdate) error
 	SubscriptionPermissionUpdate(publisherID livekit.ParticipantID, trackID livekit.TrackID, allowed bool)
@@ -396,9 +432,19 @@ type LocalParticipant interface {
 	// OnParticipantUpdate - metadata or permission is updated
 	OnParticipantUpdate(callback func(LocalParticipant))
 	OnDataPacket(callback func(LocalParticipant, livekit.DataPacket_Kind, *livekit.DataPacket))
+	// RegisterDataRPCHandler sets the handler of client requests for a method, a nil handler removes it
+	RegisterDataRPCHandler(method string, handler DataRPCHandler)
+	OnDataSendQueueDrained(callback func(LocalParticipant, livekit.DataPacket_Kind))
 	OnSubscribeStatusChanged(fn func(publisherID livekit.ParticipantID, subscribed bool))
 	OnClose(callback func(LocalParticipant))
 	OnClaimsChanged(callback func(LocalParticipant))
+	OnPublishIntentsChanged(callback func(LocalParticipant))
+
+	// tracks declared to be published, but not published yet
+	GetPublishIntents() []PublishIntent
+	// negotiates placeholders of tracks a publisher intends to publish, so that subscribing
+	// to them does not need another negotiation, nil intents remove placeholders of the publisher
+	SetPlaceholderTracks(publisherID livekit.ParticipantID, intents []PublishIntent)
 
 	HandleReceiverReport(dt *sfu.DownTrack, report *rtcp.ReceiverReport)
 
@@ -407,7 +453,15 @@ type LocalParticipant interface {
 	NotifyMigration()
 	SetMigrateState(s MigrateState)
 	MigrateState() MigrateState
-	SetMigrateInfo(previousOffer, previousAnswer *webrtc.SessionDescription, mediaTracks []*livekit.TrackPublishedResponse, dataChannels []*livekit.DataChannelInfo)
+	SetMigrateInfo(
+		previousOffer, previousAnswer *webrtc.SessionDescription,
+		trackMappings []*TrackMapping,
+		mediaTracks []*livekit.TrackPublishedResponse,
+		dataChannels []*livekit.DataChannelInfo,
+	)
+	GetTrackMappings() []*TrackMapping
+	GetMigrationRTPStats() *MigrationRTPStats
+	SetMigrationRTPStats(stats *MigrationRTPStats)
 
 	UpdateMediaRTT(rtt uint32)
 	UpdateSignalingRTT(rtt uint32)
@@ -425,8 +479,12 @@ type LocalParticipant interface {
 	// down stream bandwidth management
 	SetSubscriberAllowPause(allowPause bool)
 	SetSubscriberChannelCapacity(channelCapacity int64)
+	SetSubscriberChannelCapacityCeiling(ceiling int64)
+	SetSubscriberAllocationPolicy(policy streamallocator.AllocationPolicy)
+	SetMaxUplinkBitrate(bps int64)
 
 	GetPacer() pacer.Pacer
+	GetRTXSSRC(primarySSRC uint32) uint32
 
 	GetDisableSenderReportPassThrough() bool
 }
@@ -444,6 +502,7 @@ type Room interface {
 	SimulateScenario(participant LocalParticipant, scenario *livekit.SimulateScenario) error
 	ResolveMediaTrackForSubscriber(subIdentity livekit.ParticipantIdentity, trackID livekit.TrackID) MediaResolverResult
 	GetLocalParticipants() []LocalParticipant
+	GetParticipant(identity livekit.ParticipantIdentity) LocalParticipant
 }
 
 // MediaTrack represents a media track
@@ -459,6 +518,7 @@ type MediaTrack interface {
 	UpdateTrackInfo(ti *livekit.TrackInfo)
 	UpdateAudioTrack(update *livekit.UpdateLocalAudioTrack)
 	UpdateVideoTrack(update *livekit.UpdateLocalVideoTrack)
+	UpdateTrackName(name string)
 	ToProto() *livekit.TrackInfo
 
 	PublisherID() livekit.ParticipantID
@@ -514,6 +574,10 @@ type LocalMediaTrack interface {
 
 	NotifySubscriberNodeMaxQuality(nodeID livekit.NodeID, qualities []SubscribedCodecQuality)
 	NotifySubscriberNodeMediaLoss(nodeID livekit.NodeID, fractionalLoss uint8)
+
+	SetMaxPublishedQuality(quality livekit.VideoQuality)
+	SetMinPublishedQuality(quality livekit.VideoQuality)
+	SetUplinkLimitMaxQuality(quality livekit.VideoQuality)
 }
 
 //counterfeiter:generate . SubscribedTrack
@@ -534,7 +598,12 @@ type SubscribedTrack interface {
 	RTPSender() *webrtc.RTPSender
 	IsMuted() bool
 	SetPublisherMuted(muted bool)
+	// pauses video while subscriber is in audio only mode, independent of subscriber settings
+	SetAudioOnly(audioOnly bool)
 	UpdateSubscriberSettings(settings *livekit.UpdateTrackSettings, isImmediate bool)
+	// priority for bandwidth allocation, higher is more important, 0 when subscriber has not set one
+	Priority() uint8
+	OnPriorityChange(f func())
 	// selects appropriate video layer according to subscriber preferences
 	UpdateVideoLayer()
 	NeedsNegotiation() bool
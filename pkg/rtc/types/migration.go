@@ -0,0 +1,33 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import "github.com/livekit/protocol/livekit"
+
+// MigrationRTPStats are RTP stats of a participant's streams at the node it migrates from.
+// They are handed over with migration state and seed stats at the new node,
+// so that session metrics do not restart from zero.
+type MigrationRTPStats struct {
+	Published  []*MigrationPublishedRTPStats         `json:"published,omitempty"`
+	Subscribed map[livekit.TrackID]*livekit.RTPStats `json:"subscribed,omitempty"`
+}
+
+// MigrationPublishedRTPStats are stats of a spatial layer of a published codec
+type MigrationPublishedRTPStats struct {
+	TrackID  livekit.TrackID   `json:"trackId"`
+	MimeType string            `json:"mimeType"`
+	Layer    int32             `json:"layer"`
+	Stats    *livekit.RTPStats `json:"stats"`
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import "github.com/livekit/protocol/livekit"
+
+// TrackMapping binds a subscribed track to the transceiver it is sent on.
+// It is handed over with migration state, so that the new node can restore transceivers
+// without relying on the SDP format of the previous node.
+type TrackMapping struct {
+	TrackID livekit.TrackID `json:"trackId"`
+	Mid     string          `json:"mid"`
+	Rids    []string        `json:"rids,omitempty"`
+	// media and repair SSRCs
+	SSRCs []uint32 `json:"ssrcs,omitempty"`
+}
@@ -282,6 +282,16 @@ type FakeLocalMediaTrack struct {
 	revokeDisallowedSubscribersReturnsOnCall map[int]struct {
 		result1 []livekit.ParticipantIdentity
 	}
+	SetMaxPublishedQualityStub        func(livekit.VideoQuality)
+	setMaxPublishedQualityMutex       sync.RWMutex
+	setMaxPublishedQualityArgsForCall []struct {
+		arg1 livekit.VideoQuality
+	}
+	SetMinPublishedQualityStub        func(livekit.VideoQuality)
+	setMinPublishedQualityMutex       sync.RWMutex
+	setMinPublishedQualityArgsForCall []struct {
+		arg1 livekit.VideoQuality
+	}
 	SetMutedStub        func(bool)
 	setMutedMutex       sync.RWMutex
 	setMutedArgsForCall []struct {
@@ -292,6 +302,11 @@ type FakeLocalMediaTrack struct {
 	setRTTArgsForCall []struct {
 		arg1 uint32
 	}
+	SetUplinkLimitMaxQualityStub        func(livekit.VideoQuality)
+	setUplinkLimitMaxQualityMutex       sync.RWMutex
+	setUplinkLimitMaxQualityArgsForCall []struct {
+		arg1 livekit.VideoQuality
+	}
 	SignalCidStub        func() string
 	signalCidMutex       sync.RWMutex
 	signalCidArgsForCall []struct {
@@ -342,6 +357,11 @@ type FakeLocalMediaTrack struct {
 	updateTrackInfoArgsForCall []struct {
 		arg1 *livekit.TrackInfo
 	}
+	UpdateTrackNameStub        func(string)
+	updateTrackNameMutex       sync.RWMutex
+	updateTrackNameArgsForCall []struct {
+		arg1 string
+	}
 	UpdateVideoTrackStub        func(*livekit.UpdateLocalVideoTrack)
 	updateVideoTrackMutex       sync.RWMutex
 	updateVideoTrackArgsForCall []struct {
@@ -1806,6 +1826,70 @@ func (fake *FakeLocalMediaTrack) RevokeDisallowedSubscribersReturnsOnCall(i int,
 	}{result1}
 }
 
+func (fake *FakeLocalMediaTrack) SetMaxPublishedQuality(arg1 livekit.VideoQuality) {
+	fake.setMaxPublishedQualityMutex.Lock()
+	fake.setMaxPublishedQualityArgsForCall = append(fake.setMaxPublishedQualityArgsForCall, struct {
+		arg1 livekit.VideoQuality
+	}{arg1})
+	stub := fake.SetMaxPublishedQualityStub
+	fake.recordInvocation("SetMaxPublishedQuality", []interface{}{arg1})
+	fake.setMaxPublishedQualityMutex.Unlock()
+	if stub != nil {
+		fake.SetMaxPublishedQualityStub(arg1)
+	}
+}
+
+func (fake *FakeLocalMediaTrack) SetMaxPublishedQualityCallCount() int {
+	fake.setMaxPublishedQualityMutex.RLock()
+	defer fake.setMaxPublishedQualityMutex.RUnlock()
+	return len(fake.setMaxPublishedQualityArgsForCall)
+}
+
+func (fake *FakeLocalMediaTrack) SetMaxPublishedQualityCalls(stub func(livekit.VideoQuality)) {
+	fake.setMaxPublishedQualityMutex.Lock()
+	defer fake.setMaxPublishedQualityMutex.Unlock()
+	fake.SetMaxPublishedQualityStub = stub
+}
+
+func (fake *FakeLocalMediaTrack) SetMaxPublishedQualityArgsForCall(i int) livekit.VideoQuality {
+	fake.setMaxPublishedQualityMutex.RLock()
+	defer fake.setMaxPublishedQualityMutex.RUnlock()
+	argsForCall := fake.setMaxPublishedQualityArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLocalMediaTrack) SetMinPublishedQuality(arg1 livekit.VideoQuality) {
+	fake.setMinPublishedQualityMutex.Lock()
+	fake.setMinPublishedQualityArgsForCall = append(fake.setMinPublishedQualityArgsForCall, struct {
+		arg1 livekit.VideoQuality
+	}{arg1})
+	stub := fake.SetMinPublishedQualityStub
+	fake.recordInvocation("SetMinPublishedQuality", []interface{}{arg1})
+	fake.setMinPublishedQualityMutex.Unlock()
+	if stub != nil {
+		fake.SetMinPublishedQualityStub(arg1)
+	}
+}
+
+func (fake *FakeLocalMediaTrack) SetMinPublishedQualityCallCount() int {
+	fake.setMinPublishedQualityMutex.RLock()
+	defer fake.setMinPublishedQualityMutex.RUnlock()
+	return len(fake.setMinPublishedQualityArgsForCall)
+}
+
+func (fake *FakeLocalMediaTrack) SetMinPublishedQualityCalls(stub func(livekit.VideoQuality)) {
+	fake.setMinPublishedQualityMutex.Lock()
+	defer fake.setMinPublishedQualityMutex.Unlock()
+	fake.SetMinPublishedQualityStub = stub
+}
+
+func (fake *FakeLocalMediaTrack) SetMinPublishedQualityArgsForCall(i int) livekit.VideoQuality {
+	fake.setMinPublishedQualityMutex.RLock()
+	defer fake.setMinPublishedQualityMutex.RUnlock()
+	argsForCall := fake.setMinPublishedQualityArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalMediaTrack) SetMuted(arg1 bool) {
 	fake.setMutedMutex.Lock()
 	fake.setMutedArgsForCall = append(fake.setMutedArgsForCall, struct {
@@ -1870,6 +1954,38 @@ func (fake *FakeLocalMediaTrack) SetRTTArgsForCall(i int) uint32 {
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalMediaTrack) SetUplinkLimitMaxQuality(arg1 livekit.VideoQuality) {
+	fake.setUplinkLimitMaxQualityMutex.Lock()
+	fake.setUplinkLimitMaxQualityArgsForCall = append(fake.setUplinkLimitMaxQualityArgsForCall, struct {
+		arg1 livekit.VideoQuality
+	}{arg1})
+	stub := fake.SetUplinkLimitMaxQualityStub
+	fake.recordInvocation("SetUplinkLimitMaxQuality", []interface{}{arg1})
+	fake.setUplinkLimitMaxQualityMutex.Unlock()
+	if stub != nil {
+		fake.SetUplinkLimitMaxQualityStub(arg1)
+	}
+}
+
+func (fake *FakeLocalMediaTrack) SetUplinkLimitMaxQualityCallCount() int {
+	fake.setUplinkLimitMaxQualityMutex.RLock()
+	defer fake.setUplinkLimitMaxQualityMutex.RUnlock()
+	return len(fake.setUplinkLimitMaxQualityArgsForCall)
+}
+
+func (fake *FakeLocalMediaTrack) SetUplinkLimitMaxQualityCalls(stub func(livekit.VideoQuality)) {
+	fake.setUplinkLimitMaxQualityMutex.Lock()
+	defer fake.setUplinkLimitMaxQualityMutex.Unlock()
+	fake.SetUplinkLimitMaxQualityStub = stub
+}
+
+func (fake *FakeLocalMediaTrack) SetUplinkLimitMaxQualityArgsForCall(i int) livekit.VideoQuality {
+	fake.setUplinkLimitMaxQualityMutex.RLock()
+	defer fake.setUplinkLimitMaxQualityMutex.RUnlock()
+	argsForCall := fake.setUplinkLimitMaxQualityArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalMediaTrack) SignalCid() string {
 	fake.signalCidMutex.Lock()
 	ret, specificReturn := fake.signalCidReturnsOnCall[len(fake.signalCidArgsForCall)]
@@ -2146,6 +2262,38 @@ func (fake *FakeLocalMediaTrack) UpdateTrackInfoArgsForCall(i int) *livekit.Trac
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalMediaTrack) UpdateTrackName(arg1 string) {
+	fake.updateTrackNameMutex.Lock()
+	fake.updateTrackNameArgsForCall = append(fake.updateTrackNameArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.UpdateTrackNameStub
+	fake.recordInvocation("UpdateTrackName", []interface{}{arg1})
+	fake.updateTrackNameMutex.Unlock()
+	if stub != nil {
+		fake.UpdateTrackNameStub(arg1)
+	}
+}
+
+func (fake *FakeLocalMediaTrack) UpdateTrackNameCallCount() int {
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
+	return len(fake.updateTrackNameArgsForCall)
+}
+
+func (fake *FakeLocalMediaTrack) UpdateTrackNameCalls(stub func(string)) {
+	fake.updateTrackNameMutex.Lock()
+	defer fake.updateTrackNameMutex.Unlock()
+	fake.UpdateTrackNameStub = stub
+}
+
+func (fake *FakeLocalMediaTrack) UpdateTrackNameArgsForCall(i int) string {
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
+	argsForCall := fake.updateTrackNameArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalMediaTrack) UpdateVideoTrack(arg1 *livekit.UpdateLocalVideoTrack) {
 	fake.updateVideoTrackMutex.Lock()
 	fake.updateVideoTrackArgsForCall = append(fake.updateVideoTrackArgsForCall, struct {
@@ -2239,10 +2387,16 @@ func (fake *FakeLocalMediaTrack) Invocations() map[string][][]interface{} {
 	defer fake.restartMutex.RUnlock()
 	fake.revokeDisallowedSubscribersMutex.RLock()
 	defer fake.revokeDisallowedSubscribersMutex.RUnlock()
+	fake.setMaxPublishedQualityMutex.RLock()
+	defer fake.setMaxPublishedQualityMutex.RUnlock()
+	fake.setMinPublishedQualityMutex.RLock()
+	defer fake.setMinPublishedQualityMutex.RUnlock()
 	fake.setMutedMutex.RLock()
 	defer fake.setMutedMutex.RUnlock()
 	fake.setRTTMutex.RLock()
 	defer fake.setRTTMutex.RUnlock()
+	fake.setUplinkLimitMaxQualityMutex.RLock()
+	defer fake.setUplinkLimitMaxQualityMutex.RUnlock()
 	fake.signalCidMutex.RLock()
 	defer fake.signalCidMutex.RUnlock()
 	fake.sourceMutex.RLock()
@@ -2255,6 +2409,8 @@ func (fake *FakeLocalMediaTrack) Invocations() map[string][][]interface{} {
 	defer fake.updateAudioTrackMutex.RUnlock()
 	fake.updateTrackInfoMutex.RLock()
 	defer fake.updateTrackInfoMutex.RUnlock()
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
 	fake.updateVideoTrackMutex.RLock()
 	defer fake.updateVideoTrackMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -2,14 +2,18 @@
 package typesfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 
+	"github.com/livekit/livekit-server/pkg/pcap"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
+	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
 	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
@@ -286,6 +290,16 @@ type FakeLocalParticipant struct {
 	getICEConnectionDetailsReturnsOnCall map[int]struct {
 		result1 []*types.ICEConnectionDetails
 	}
+	GetICEStatsStub        func() []*types.ICEStats
+	getICEStatsMutex       sync.RWMutex
+	getICEStatsArgsForCall []struct {
+	}
+	getICEStatsReturns struct {
+		result1 []*types.ICEStats
+	}
+	getICEStatsReturnsOnCall map[int]struct {
+		result1 []*types.ICEStats
+	}
 	GetLoggerStub        func() logger.Logger
 	getLoggerMutex       sync.RWMutex
 	getLoggerArgsForCall []struct {
@@ -296,6 +310,28 @@ type FakeLocalParticipant struct {
 	getLoggerReturnsOnCall map[int]struct {
 		result1 logger.Logger
 	}
+	GetMigrationRTPStatsStub        func() *types.MigrationRTPStats
+	getMigrationRTPStatsMutex       sync.RWMutex
+	getMigrationRTPStatsArgsForCall []struct {
+	}
+	getMigrationRTPStatsReturns struct {
+		result1 *types.MigrationRTPStats
+	}
+	getMigrationRTPStatsReturnsOnCall map[int]struct {
+		result1 *types.MigrationRTPStats
+	}
+	GetNetworkConditionsStub        func() (*netem.Conditions, *netem.Conditions)
+	getNetworkConditionsMutex       sync.RWMutex
+	getNetworkConditionsArgsForCall []struct {
+	}
+	getNetworkConditionsReturns struct {
+		result1 *netem.Conditions
+		result2 *netem.Conditions
+	}
+	getNetworkConditionsReturnsOnCall map[int]struct {
+		result1 *netem.Conditions
+		result2 *netem.Conditions
+	}
 	GetPacerStub        func() pacer.Pacer
 	getPacerMutex       sync.RWMutex
 	getPacerArgsForCall []struct {
@@ -327,6 +363,26 @@ type FakeLocalParticipant struct {
 	getPlayoutDelayConfigReturnsOnCall map[int]struct {
 		result1 *livekit.PlayoutDelay
 	}
+	GetPreferredCodecsStub        func() []string
+	getPreferredCodecsMutex       sync.RWMutex
+	getPreferredCodecsArgsForCall []struct {
+	}
+	getPreferredCodecsReturns struct {
+		result1 []string
+	}
+	getPreferredCodecsReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	GetPublishIntentsStub        func() []types.PublishIntent
+	getPublishIntentsMutex       sync.RWMutex
+	getPublishIntentsArgsForCall []struct {
+	}
+	getPublishIntentsReturns struct {
+		result1 []types.PublishIntent
+	}
+	getPublishIntentsReturnsOnCall map[int]struct {
+		result1 []types.PublishIntent
+	}
 	GetPublishedTrackStub        func(livekit.TrackID) types.MediaTrack
 	getPublishedTrackMutex       sync.RWMutex
 	getPublishedTrackArgsForCall []struct {
@@ -348,6 +404,17 @@ type FakeLocalParticipant struct {
 	getPublishedTracksReturnsOnCall map[int]struct {
 		result1 []types.MediaTrack
 	}
+	GetRTXSSRCStub        func(uint32) uint32
+	getRTXSSRCMutex       sync.RWMutex
+	getRTXSSRCArgsForCall []struct {
+		arg1 uint32
+	}
+	getRTXSSRCReturns struct {
+		result1 uint32
+	}
+	getRTXSSRCReturnsOnCall map[int]struct {
+		result1 uint32
+	}
 	GetSubscribedParticipantsStub        func() []livekit.ParticipantID
 	getSubscribedParticipantsMutex       sync.RWMutex
 	getSubscribedParticipantsArgsForCall []struct {
@@ -368,6 +435,16 @@ type FakeLocalParticipant struct {
 	getSubscribedTracksReturnsOnCall map[int]struct {
 		result1 []types.SubscribedTrack
 	}
+	GetTrackMappingsStub        func() []*types.TrackMapping
+	getTrackMappingsMutex       sync.RWMutex
+	getTrackMappingsArgsForCall []struct {
+	}
+	getTrackMappingsReturns struct {
+		result1 []*types.TrackMapping
+	}
+	getTrackMappingsReturnsOnCall map[int]struct {
+		result1 []*types.TrackMapping
+	}
 	GetTrailerStub        func() []byte
 	getTrailerMutex       sync.RWMutex
 	getTrailerArgsForCall []struct {
@@ -378,6 +455,16 @@ type FakeLocalParticipant struct {
 	getTrailerReturnsOnCall map[int]struct {
 		result1 []byte
 	}
+	GetUsageStub        func() *types.ParticipantUsage
+	getUsageMutex       sync.RWMutex
+	getUsageArgsForCall []struct {
+	}
+	getUsageReturns struct {
+		result1 *types.ParticipantUsage
+	}
+	getUsageReturnsOnCall map[int]struct {
+		result1 *types.ParticipantUsage
+	}
 	HandleAnswerStub        func(webrtc.SessionDescription)
 	handleAnswerMutex       sync.RWMutex
 	handleAnswerArgsForCall []struct {
@@ -410,6 +497,17 @@ type FakeLocalParticipant struct {
 	handleSignalSourceCloseMutex       sync.RWMutex
 	handleSignalSourceCloseArgsForCall []struct {
 	}
+	HasClientCapabilityStub        func(types.ClientCapability) bool
+	hasClientCapabilityMutex       sync.RWMutex
+	hasClientCapabilityArgsForCall []struct {
+		arg1 types.ClientCapability
+	}
+	hasClientCapabilityReturns struct {
+		result1 bool
+	}
+	hasClientCapabilityReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	HasConnectedStub        func() bool
 	hasConnectedMutex       sync.RWMutex
 	hasConnectedArgsForCall []struct {
@@ -507,6 +605,17 @@ type FakeLocalParticipant struct {
 	isIdleReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	IsInterestedInDataTopicStub        func(string) bool
+	isInterestedInDataTopicMutex       sync.RWMutex
+	isInterestedInDataTopicArgsForCall []struct {
+		arg1 string
+	}
+	isInterestedInDataTopicReturns struct {
+		result1 bool
+	}
+	isInterestedInDataTopicReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	IsPublisherStub        func() bool
 	isPublisherMutex       sync.RWMutex
 	isPublisherArgsForCall []struct {
@@ -609,6 +718,11 @@ type FakeLocalParticipant struct {
 	onDataPacketArgsForCall []struct {
 		arg1 func(types.LocalParticipant, livekit.DataPacket_Kind, *livekit.DataPacket)
 	}
+	OnDataSendQueueDrainedStub        func(func(types.LocalParticipant, livekit.DataPacket_Kind))
+	onDataSendQueueDrainedMutex       sync.RWMutex
+	onDataSendQueueDrainedArgsForCall []struct {
+		arg1 func(types.LocalParticipant, livekit.DataPacket_Kind)
+	}
 	OnICEConfigChangedStub        func(func(participant types.LocalParticipant, iceConfig *livekit.ICEConfig))
 	onICEConfigChangedMutex       sync.RWMutex
 	onICEConfigChangedArgsForCall []struct {
@@ -624,6 +738,11 @@ type FakeLocalParticipant struct {
 	onParticipantUpdateArgsForCall []struct {
 		arg1 func(types.LocalParticipant)
 	}
+	OnPublishIntentsChangedStub        func(func(types.LocalParticipant))
+	onPublishIntentsChangedMutex       sync.RWMutex
+	onPublishIntentsChangedArgsForCall []struct {
+		arg1 func(types.LocalParticipant)
+	}
 	OnStateChangeStub        func(func(p types.LocalParticipant, state livekit.ParticipantInfo_State))
 	onStateChangeMutex       sync.RWMutex
 	onStateChangeArgsForCall []struct {
@@ -649,6 +768,32 @@ type FakeLocalParticipant struct {
 	onTrackUpdatedArgsForCall []struct {
 		arg1 func(types.LocalParticipant, types.MediaTrack)
 	}
+	PerformDataRPCStub        func(context.Context, livekit.ParticipantIdentity, string, string) (string, error)
+	performDataRPCMutex       sync.RWMutex
+	performDataRPCArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.ParticipantIdentity
+		arg3 string
+		arg4 string
+	}
+	performDataRPCReturns struct {
+		result1 string
+		result2 error
+	}
+	performDataRPCReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	PermissionGroupsStub        func() []string
+	permissionGroupsMutex       sync.RWMutex
+	permissionGroupsArgsForCall []struct {
+	}
+	permissionGroupsReturns struct {
+		result1 []string
+	}
+	permissionGroupsReturnsOnCall map[int]struct {
+		result1 []string
+	}
 	ProtocolVersionStub        func() types.ProtocolVersion
 	protocolVersionMutex       sync.RWMutex
 	protocolVersionArgsForCall []struct {
@@ -659,6 +804,12 @@ type FakeLocalParticipant struct {
 	protocolVersionReturnsOnCall map[int]struct {
 		result1 types.ProtocolVersion
 	}
+	RegisterDataRPCHandlerStub        func(string, types.DataRPCHandler)
+	registerDataRPCHandlerMutex       sync.RWMutex
+	registerDataRPCHandlerArgsForCall []struct {
+		arg1 string
+		arg2 types.DataRPCHandler
+	}
 	RemovePublishedTrackStub        func(types.MediaTrack, bool, bool)
 	removePublishedTrackMutex       sync.RWMutex
 	removePublishedTrackArgsForCall []struct {
@@ -677,6 +828,17 @@ type FakeLocalParticipant struct {
 	removeTrackFromSubscriberReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SampleResourceUsageStub        func(int) types.ParticipantResourceUsage
+	sampleResourceUsageMutex       sync.RWMutex
+	sampleResourceUsageArgsForCall []struct {
+		arg1 int
+	}
+	sampleResourceUsageReturns struct {
+		result1 types.ParticipantResourceUsage
+	}
+	sampleResourceUsageReturnsOnCall map[int]struct {
+		result1 types.ParticipantResourceUsage
+	}
 	SendConnectionQualityUpdateStub        func(*livekit.ConnectionQualityUpdate) error
 	sendConnectionQualityUpdateMutex       sync.RWMutex
 	sendConnectionQualityUpdateArgsForCall []struct {
@@ -777,29 +939,46 @@ type FakeLocalParticipant struct {
 	setICEConfigArgsForCall []struct {
 		arg1 *livekit.ICEConfig
 	}
+	SetMaxUplinkBitrateStub        func(int64)
+	setMaxUplinkBitrateMutex       sync.RWMutex
+	setMaxUplinkBitrateArgsForCall []struct {
+		arg1 int64
+	}
 	SetMetadataStub        func(string)
 	setMetadataMutex       sync.RWMutex
 	setMetadataArgsForCall []struct {
 		arg1 string
 	}
-	SetMigrateInfoStub        func(*webrtc.SessionDescription, *webrtc.SessionDescription, []*livekit.TrackPublishedResponse, []*livekit.DataChannelInfo)
+	SetMigrateInfoStub        func(*webrtc.SessionDescription, *webrtc.SessionDescription, []*types.TrackMapping, []*livekit.TrackPublishedResponse, []*livekit.DataChannelInfo)
 	setMigrateInfoMutex       sync.RWMutex
 	setMigrateInfoArgsForCall []struct {
 		arg1 *webrtc.SessionDescription
 		arg2 *webrtc.SessionDescription
-		arg3 []*livekit.TrackPublishedResponse
-		arg4 []*livekit.DataChannelInfo
+		arg3 []*types.TrackMapping
+		arg4 []*livekit.TrackPublishedResponse
+		arg5 []*livekit.DataChannelInfo
 	}
 	SetMigrateStateStub        func(types.MigrateState)
 	setMigrateStateMutex       sync.RWMutex
 	setMigrateStateArgsForCall []struct {
 		arg1 types.MigrateState
 	}
+	SetMigrationRTPStatsStub        func(*types.MigrationRTPStats)
+	setMigrationRTPStatsMutex       sync.RWMutex
+	setMigrationRTPStatsArgsForCall []struct {
+		arg1 *types.MigrationRTPStats
+	}
 	SetNameStub        func(string)
 	setNameMutex       sync.RWMutex
 	setNameArgsForCall []struct {
 		arg1 string
 	}
+	SetNetworkConditionsStub        func(*netem.Conditions, *netem.Conditions)
+	setNetworkConditionsMutex       sync.RWMutex
+	setNetworkConditionsArgsForCall []struct {
+		arg1 *netem.Conditions
+		arg2 *netem.Conditions
+	}
 	SetPermissionStub        func(*livekit.ParticipantPermission) bool
 	setPermissionMutex       sync.RWMutex
 	setPermissionArgsForCall []struct {
@@ -811,6 +990,23 @@ type FakeLocalParticipant struct {
 	setPermissionReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	SetPermissionGroupsStub        func([]string) bool
+	setPermissionGroupsMutex       sync.RWMutex
+	setPermissionGroupsArgsForCall []struct {
+		arg1 []string
+	}
+	setPermissionGroupsReturns struct {
+		result1 bool
+	}
+	setPermissionGroupsReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	SetPlaceholderTracksStub        func(livekit.ParticipantID, []types.PublishIntent)
+	setPlaceholderTracksMutex       sync.RWMutex
+	setPlaceholderTracksArgsForCall []struct {
+		arg1 livekit.ParticipantID
+		arg2 []types.PublishIntent
+	}
 	SetResponseSinkStub        func(routing.MessageSink)
 	setResponseSinkMutex       sync.RWMutex
 	setResponseSinkArgsForCall []struct {
@@ -821,6 +1017,11 @@ type FakeLocalParticipant struct {
 	setSignalSourceValidArgsForCall []struct {
 		arg1 bool
 	}
+	SetSubscriberAllocationPolicyStub        func(streamallocator.AllocationPolicy)
+	setSubscriberAllocationPolicyMutex       sync.RWMutex
+	setSubscriberAllocationPolicyArgsForCall []struct {
+		arg1 streamallocator.AllocationPolicy
+	}
 	SetSubscriberAllowPauseStub        func(bool)
 	setSubscriberAllowPauseMutex       sync.RWMutex
 	setSubscriberAllowPauseArgsForCall []struct {
@@ -831,6 +1032,11 @@ type FakeLocalParticipant struct {
 	setSubscriberChannelCapacityArgsForCall []struct {
 		arg1 int64
 	}
+	SetSubscriberChannelCapacityCeilingStub        func(int64)
+	setSubscriberChannelCapacityCeilingMutex       sync.RWMutex
+	setSubscriberChannelCapacityCeilingArgsForCall []struct {
+		arg1 int64
+	}
 	SetTrackMutedStub        func(livekit.TrackID, bool, bool) *livekit.TrackInfo
 	setTrackMutedMutex       sync.RWMutex
 	setTrackMutedArgsForCall []struct {
@@ -844,6 +1050,19 @@ type FakeLocalParticipant struct {
 	setTrackMutedReturnsOnCall map[int]struct {
 		result1 *livekit.TrackInfo
 	}
+	StartPacketCaptureStub        func(pcap.CaptureParams) (*pcap.Capture, error)
+	startPacketCaptureMutex       sync.RWMutex
+	startPacketCaptureArgsForCall []struct {
+		arg1 pcap.CaptureParams
+	}
+	startPacketCaptureReturns struct {
+		result1 *pcap.Capture
+		result2 error
+	}
+	startPacketCaptureReturnsOnCall map[int]struct {
+		result1 *pcap.Capture
+		result2 error
+	}
 	StateStub        func() livekit.ParticipantInfo_State
 	stateMutex       sync.RWMutex
 	stateArgsForCall []struct {
@@ -854,6 +1073,16 @@ type FakeLocalParticipant struct {
 	stateReturnsOnCall map[int]struct {
 		result1 livekit.ParticipantInfo_State
 	}
+	StopPacketCaptureStub        func() error
+	stopPacketCaptureMutex       sync.RWMutex
+	stopPacketCaptureArgsForCall []struct {
+	}
+	stopPacketCaptureReturns struct {
+		result1 error
+	}
+	stopPacketCaptureReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SubscribeToTrackStub        func(livekit.TrackID)
 	subscribeToTrackMutex       sync.RWMutex
 	subscribeToTrackArgsForCall []struct {
@@ -1010,6 +1239,18 @@ type FakeLocalParticipant struct {
 	updateSubscriptionPermissionReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UpdateTrackNameStub        func(livekit.TrackID, string) error
+	updateTrackNameMutex       sync.RWMutex
+	updateTrackNameArgsForCall []struct {
+		arg1 livekit.TrackID
+		arg2 string
+	}
+	updateTrackNameReturns struct {
+		result1 error
+	}
+	updateTrackNameReturnsOnCall map[int]struct {
+		result1 error
+	}
 	UpdateVideoTrackStub        func(*livekit.UpdateLocalVideoTrack) error
 	updateVideoTrackMutex       sync.RWMutex
 	updateVideoTrackArgsForCall []struct {
@@ -2422,6 +2663,59 @@ func (fake *FakeLocalParticipant) GetICEConnectionDetailsReturnsOnCall(i int, re
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) GetICEStats() []*types.ICEStats {
+	fake.getICEStatsMutex.Lock()
+	ret, specificReturn := fake.getICEStatsReturnsOnCall[len(fake.getICEStatsArgsForCall)]
+	fake.getICEStatsArgsForCall = append(fake.getICEStatsArgsForCall, struct {
+	}{})
+	stub := fake.GetICEStatsStub
+	fakeReturns := fake.getICEStatsReturns
+	fake.recordInvocation("GetICEStats", []interface{}{})
+	fake.getICEStatsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) GetICEStatsCallCount() int {
+	fake.getICEStatsMutex.RLock()
+	defer fake.getICEStatsMutex.RUnlock()
+	return len(fake.getICEStatsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetICEStatsCalls(stub func() []*types.ICEStats) {
+	fake.getICEStatsMutex.Lock()
+	defer fake.getICEStatsMutex.Unlock()
+	fake.GetICEStatsStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetICEStatsReturns(result1 []*types.ICEStats) {
+	fake.getICEStatsMutex.Lock()
+	defer fake.getICEStatsMutex.Unlock()
+	fake.GetICEStatsStub = nil
+	fake.getICEStatsReturns = struct {
+		result1 []*types.ICEStats
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetICEStatsReturnsOnCall(i int, result1 []*types.ICEStats) {
+	fake.getICEStatsMutex.Lock()
+	defer fake.getICEStatsMutex.Unlock()
+	fake.GetICEStatsStub = nil
+	if fake.getICEStatsReturnsOnCall == nil {
+		fake.getICEStatsReturnsOnCall = make(map[int]struct {
+			result1 []*types.ICEStats
+		})
+	}
+	fake.getICEStatsReturnsOnCall[i] = struct {
+		result1 []*types.ICEStats
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) GetLogger() logger.Logger {
 	fake.getLoggerMutex.Lock()
 	ret, specificReturn := fake.getLoggerReturnsOnCall[len(fake.getLoggerArgsForCall)]
@@ -2475,6 +2769,115 @@ func (fake *FakeLocalParticipant) GetLoggerReturnsOnCall(i int, result1 logger.L
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) GetMigrationRTPStats() *types.MigrationRTPStats {
+	fake.getMigrationRTPStatsMutex.Lock()
+	ret, specificReturn := fake.getMigrationRTPStatsReturnsOnCall[len(fake.getMigrationRTPStatsArgsForCall)]
+	fake.getMigrationRTPStatsArgsForCall = append(fake.getMigrationRTPStatsArgsForCall, struct {
+	}{})
+	stub := fake.GetMigrationRTPStatsStub
+	fakeReturns := fake.getMigrationRTPStatsReturns
+	fake.recordInvocation("GetMigrationRTPStats", []interface{}{})
+	fake.getMigrationRTPStatsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) GetMigrationRTPStatsCallCount() int {
+	fake.getMigrationRTPStatsMutex.RLock()
+	defer fake.getMigrationRTPStatsMutex.RUnlock()
+	return len(fake.getMigrationRTPStatsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetMigrationRTPStatsCalls(stub func() *types.MigrationRTPStats) {
+	fake.getMigrationRTPStatsMutex.Lock()
+	defer fake.getMigrationRTPStatsMutex.Unlock()
+	fake.GetMigrationRTPStatsStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetMigrationRTPStatsReturns(result1 *types.MigrationRTPStats) {
+	fake.getMigrationRTPStatsMutex.Lock()
+	defer fake.getMigrationRTPStatsMutex.Unlock()
+	fake.GetMigrationRTPStatsStub = nil
+	fake.getMigrationRTPStatsReturns = struct {
+		result1 *types.MigrationRTPStats
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetMigrationRTPStatsReturnsOnCall(i int, result1 *types.MigrationRTPStats) {
+	fake.getMigrationRTPStatsMutex.Lock()
+	defer fake.getMigrationRTPStatsMutex.Unlock()
+	fake.GetMigrationRTPStatsStub = nil
+	if fake.getMigrationRTPStatsReturnsOnCall == nil {
+		fake.getMigrationRTPStatsReturnsOnCall = make(map[int]struct {
+			result1 *types.MigrationRTPStats
+		})
+	}
+	fake.getMigrationRTPStatsReturnsOnCall[i] = struct {
+		result1 *types.MigrationRTPStats
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetNetworkConditions() (*netem.Conditions, *netem.Conditions) {
+	fake.getNetworkConditionsMutex.Lock()
+	ret, specificReturn := fake.getNetworkConditionsReturnsOnCall[len(fake.getNetworkConditionsArgsForCall)]
+	fake.getNetworkConditionsArgsForCall = append(fake.getNetworkConditionsArgsForCall, struct {
+	}{})
+	stub := fake.GetNetworkConditionsStub
+	fakeReturns := fake.getNetworkConditionsReturns
+	fake.recordInvocation("GetNetworkConditions", []interface{}{})
+	fake.getNetworkConditionsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLocalParticipant) GetNetworkConditionsCallCount() int {
+	fake.getNetworkConditionsMutex.RLock()
+	defer fake.getNetworkConditionsMutex.RUnlock()
+	return len(fake.getNetworkConditionsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetNetworkConditionsCalls(stub func() (*netem.Conditions, *netem.Conditions)) {
+	fake.getNetworkConditionsMutex.Lock()
+	defer fake.getNetworkConditionsMutex.Unlock()
+	fake.GetNetworkConditionsStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetNetworkConditionsReturns(result1 *netem.Conditions, result2 *netem.Conditions) {
+	fake.getNetworkConditionsMutex.Lock()
+	defer fake.getNetworkConditionsMutex.Unlock()
+	fake.GetNetworkConditionsStub = nil
+	fake.getNetworkConditionsReturns = struct {
+		result1 *netem.Conditions
+		result2 *netem.Conditions
+	}{result1, result2}
+}
+
+func (fake *FakeLocalParticipant) GetNetworkConditionsReturnsOnCall(i int, result1 *netem.Conditions, result2 *netem.Conditions) {
+	fake.getNetworkConditionsMutex.Lock()
+	defer fake.getNetworkConditionsMutex.Unlock()
+	fake.GetNetworkConditionsStub = nil
+	if fake.getNetworkConditionsReturnsOnCall == nil {
+		fake.getNetworkConditionsReturnsOnCall = make(map[int]struct {
+			result1 *netem.Conditions
+			result2 *netem.Conditions
+		})
+	}
+	fake.getNetworkConditionsReturnsOnCall[i] = struct {
+		result1 *netem.Conditions
+		result2 *netem.Conditions
+	}{result1, result2}
+}
+
 func (fake *FakeLocalParticipant) GetPacer() pacer.Pacer {
 	fake.getPacerMutex.Lock()
 	ret, specificReturn := fake.getPacerReturnsOnCall[len(fake.getPacerArgsForCall)]
@@ -2642,6 +3045,112 @@ func (fake *FakeLocalParticipant) GetPlayoutDelayConfigReturnsOnCall(i int, resu
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) GetPreferredCodecs() []string {
+	fake.getPreferredCodecsMutex.Lock()
+	ret, specificReturn := fake.getPreferredCodecsReturnsOnCall[len(fake.getPreferredCodecsArgsForCall)]
+	fake.getPreferredCodecsArgsForCall = append(fake.getPreferredCodecsArgsForCall, struct {
+	}{})
+	stub := fake.GetPreferredCodecsStub
+	fakeReturns := fake.getPreferredCodecsReturns
+	fake.recordInvocation("GetPreferredCodecs", []interface{}{})
+	fake.getPreferredCodecsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) GetPreferredCodecsCallCount() int {
+	fake.getPreferredCodecsMutex.RLock()
+	defer fake.getPreferredCodecsMutex.RUnlock()
+	return len(fake.getPreferredCodecsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetPreferredCodecsCalls(stub func() []string) {
+	fake.getPreferredCodecsMutex.Lock()
+	defer fake.getPreferredCodecsMutex.Unlock()
+	fake.GetPreferredCodecsStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetPreferredCodecsReturns(result1 []string) {
+	fake.getPreferredCodecsMutex.Lock()
+	defer fake.getPreferredCodecsMutex.Unlock()
+	fake.GetPreferredCodecsStub = nil
+	fake.getPreferredCodecsReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetPreferredCodecsReturnsOnCall(i int, result1 []string) {
+	fake.getPreferredCodecsMutex.Lock()
+	defer fake.getPreferredCodecsMutex.Unlock()
+	fake.GetPreferredCodecsStub = nil
+	if fake.getPreferredCodecsReturnsOnCall == nil {
+		fake.getPreferredCodecsReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.getPreferredCodecsReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetPublishIntents() []types.PublishIntent {
+	fake.getPublishIntentsMutex.Lock()
+	ret, specificReturn := fake.getPublishIntentsReturnsOnCall[len(fake.getPublishIntentsArgsForCall)]
+	fake.getPublishIntentsArgsForCall = append(fake.getPublishIntentsArgsForCall, struct {
+	}{})
+	stub := fake.GetPublishIntentsStub
+	fakeReturns := fake.getPublishIntentsReturns
+	fake.recordInvocation("GetPublishIntents", []interface{}{})
+	fake.getPublishIntentsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) GetPublishIntentsCallCount() int {
+	fake.getPublishIntentsMutex.RLock()
+	defer fake.getPublishIntentsMutex.RUnlock()
+	return len(fake.getPublishIntentsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetPublishIntentsCalls(stub func() []types.PublishIntent) {
+	fake.getPublishIntentsMutex.Lock()
+	defer fake.getPublishIntentsMutex.Unlock()
+	fake.GetPublishIntentsStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetPublishIntentsReturns(result1 []types.PublishIntent) {
+	fake.getPublishIntentsMutex.Lock()
+	defer fake.getPublishIntentsMutex.Unlock()
+	fake.GetPublishIntentsStub = nil
+	fake.getPublishIntentsReturns = struct {
+		result1 []types.PublishIntent
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetPublishIntentsReturnsOnCall(i int, result1 []types.PublishIntent) {
+	fake.getPublishIntentsMutex.Lock()
+	defer fake.getPublishIntentsMutex.Unlock()
+	fake.GetPublishIntentsStub = nil
+	if fake.getPublishIntentsReturnsOnCall == nil {
+		fake.getPublishIntentsReturnsOnCall = make(map[int]struct {
+			result1 []types.PublishIntent
+		})
+	}
+	fake.getPublishIntentsReturnsOnCall[i] = struct {
+		result1 []types.PublishIntent
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) GetPublishedTrack(arg1 livekit.TrackID) types.MediaTrack {
 	fake.getPublishedTrackMutex.Lock()
 	ret, specificReturn := fake.getPublishedTrackReturnsOnCall[len(fake.getPublishedTrackArgsForCall)]
@@ -2756,6 +3265,67 @@ func (fake *FakeLocalParticipant) GetPublishedTracksReturnsOnCall(i int, result1
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) GetRTXSSRC(arg1 uint32) uint32 {
+	fake.getRTXSSRCMutex.Lock()
+	ret, specificReturn := fake.getRTXSSRCReturnsOnCall[len(fake.getRTXSSRCArgsForCall)]
+	fake.getRTXSSRCArgsForCall = append(fake.getRTXSSRCArgsForCall, struct {
+		arg1 uint32
+	}{arg1})
+	stub := fake.GetRTXSSRCStub
+	fakeReturns := fake.getRTXSSRCReturns
+	fake.recordInvocation("GetRTXSSRC", []interface{}{arg1})
+	fake.getRTXSSRCMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) GetRTXSSRCCallCount() int {
+	fake.getRTXSSRCMutex.RLock()
+	defer fake.getRTXSSRCMutex.RUnlock()
+	return len(fake.getRTXSSRCArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetRTXSSRCCalls(stub func(uint32) uint32) {
+	fake.getRTXSSRCMutex.Lock()
+	defer fake.getRTXSSRCMutex.Unlock()
+	fake.GetRTXSSRCStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetRTXSSRCArgsForCall(i int) uint32 {
+	fake.getRTXSSRCMutex.RLock()
+	defer fake.getRTXSSRCMutex.RUnlock()
+	argsForCall := fake.getRTXSSRCArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLocalParticipant) GetRTXSSRCReturns(result1 uint32) {
+	fake.getRTXSSRCMutex.Lock()
+	defer fake.getRTXSSRCMutex.Unlock()
+	fake.GetRTXSSRCStub = nil
+	fake.getRTXSSRCReturns = struct {
+		result1 uint32
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetRTXSSRCReturnsOnCall(i int, result1 uint32) {
+	fake.getRTXSSRCMutex.Lock()
+	defer fake.getRTXSSRCMutex.Unlock()
+	fake.GetRTXSSRCStub = nil
+	if fake.getRTXSSRCReturnsOnCall == nil {
+		fake.getRTXSSRCReturnsOnCall = make(map[int]struct {
+			result1 uint32
+		})
+	}
+	fake.getRTXSSRCReturnsOnCall[i] = struct {
+		result1 uint32
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) GetSubscribedParticipants() []livekit.ParticipantID {
 	fake.getSubscribedParticipantsMutex.Lock()
 	ret, specificReturn := fake.getSubscribedParticipantsReturnsOnCall[len(fake.getSubscribedParticipantsArgsForCall)]
@@ -2862,6 +3432,59 @@ func (fake *FakeLocalParticipant) GetSubscribedTracksReturnsOnCall(i int, result
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) GetTrackMappings() []*types.TrackMapping {
+	fake.getTrackMappingsMutex.Lock()
+	ret, specificReturn := fake.getTrackMappingsReturnsOnCall[len(fake.getTrackMappingsArgsForCall)]
+	fake.getTrackMappingsArgsForCall = append(fake.getTrackMappingsArgsForCall, struct {
+	}{})
+	stub := fake.GetTrackMappingsStub
+	fakeReturns := fake.getTrackMappingsReturns
+	fake.recordInvocation("GetTrackMappings", []interface{}{})
+	fake.getTrackMappingsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) GetTrackMappingsCallCount() int {
+	fake.getTrackMappingsMutex.RLock()
+	defer fake.getTrackMappingsMutex.RUnlock()
+	return len(fake.getTrackMappingsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetTrackMappingsCalls(stub func() []*types.TrackMapping) {
+	fake.getTrackMappingsMutex.Lock()
+	defer fake.getTrackMappingsMutex.Unlock()
+	fake.GetTrackMappingsStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetTrackMappingsReturns(result1 []*types.TrackMapping) {
+	fake.getTrackMappingsMutex.Lock()
+	defer fake.getTrackMappingsMutex.Unlock()
+	fake.GetTrackMappingsStub = nil
+	fake.getTrackMappingsReturns = struct {
+		result1 []*types.TrackMapping
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetTrackMappingsReturnsOnCall(i int, result1 []*types.TrackMapping) {
+	fake.getTrackMappingsMutex.Lock()
+	defer fake.getTrackMappingsMutex.Unlock()
+	fake.GetTrackMappingsStub = nil
+	if fake.getTrackMappingsReturnsOnCall == nil {
+		fake.getTrackMappingsReturnsOnCall = make(map[int]struct {
+			result1 []*types.TrackMapping
+		})
+	}
+	fake.getTrackMappingsReturnsOnCall[i] = struct {
+		result1 []*types.TrackMapping
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) GetTrailer() []byte {
 	fake.getTrailerMutex.Lock()
 	ret, specificReturn := fake.getTrailerReturnsOnCall[len(fake.getTrailerArgsForCall)]
@@ -2915,22 +3538,75 @@ func (fake *FakeLocalParticipant) GetTrailerReturnsOnCall(i int, result1 []byte)
 	}{result1}
 }
 
-func (fake *FakeLocalParticipant) HandleAnswer(arg1 webrtc.SessionDescription) {
-	fake.handleAnswerMutex.Lock()
-	fake.handleAnswerArgsForCall = append(fake.handleAnswerArgsForCall, struct {
-		arg1 webrtc.SessionDescription
-	}{arg1})
-	stub := fake.HandleAnswerStub
-	fake.recordInvocation("HandleAnswer", []interface{}{arg1})
-	fake.handleAnswerMutex.Unlock()
+func (fake *FakeLocalParticipant) GetUsage() *types.ParticipantUsage {
+	fake.getUsageMutex.Lock()
+	ret, specificReturn := fake.getUsageReturnsOnCall[len(fake.getUsageArgsForCall)]
+	fake.getUsageArgsForCall = append(fake.getUsageArgsForCall, struct {
+	}{})
+	stub := fake.GetUsageStub
+	fakeReturns := fake.getUsageReturns
+	fake.recordInvocation("GetUsage", []interface{}{})
+	fake.getUsageMutex.Unlock()
 	if stub != nil {
-		fake.HandleAnswerStub(arg1)
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
 	}
+	return fakeReturns.result1
 }
 
-func (fake *FakeLocalParticipant) HandleAnswerCallCount() int {
-	fake.handleAnswerMutex.RLock()
-	defer fake.handleAnswerMutex.RUnlock()
+func (fake *FakeLocalParticipant) GetUsageCallCount() int {
+	fake.getUsageMutex.RLock()
+	defer fake.getUsageMutex.RUnlock()
+	return len(fake.getUsageArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetUsageCalls(stub func() *types.ParticipantUsage) {
+	fake.getUsageMutex.Lock()
+	defer fake.getUsageMutex.Unlock()
+	fake.GetUsageStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetUsageReturns(result1 *types.ParticipantUsage) {
+	fake.getUsageMutex.Lock()
+	defer fake.getUsageMutex.Unlock()
+	fake.GetUsageStub = nil
+	fake.getUsageReturns = struct {
+		result1 *types.ParticipantUsage
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetUsageReturnsOnCall(i int, result1 *types.ParticipantUsage) {
+	fake.getUsageMutex.Lock()
+	defer fake.getUsageMutex.Unlock()
+	fake.GetUsageStub = nil
+	if fake.getUsageReturnsOnCall == nil {
+		fake.getUsageReturnsOnCall = make(map[int]struct {
+			result1 *types.ParticipantUsage
+		})
+	}
+	fake.getUsageReturnsOnCall[i] = struct {
+		result1 *types.ParticipantUsage
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) HandleAnswer(arg1 webrtc.SessionDescription) {
+	fake.handleAnswerMutex.Lock()
+	fake.handleAnswerArgsForCall = append(fake.handleAnswerArgsForCall, struct {
+		arg1 webrtc.SessionDescription
+	}{arg1})
+	stub := fake.HandleAnswerStub
+	fake.recordInvocation("HandleAnswer", []interface{}{arg1})
+	fake.handleAnswerMutex.Unlock()
+	if stub != nil {
+		fake.HandleAnswerStub(arg1)
+	}
+}
+
+func (fake *FakeLocalParticipant) HandleAnswerCallCount() int {
+	fake.handleAnswerMutex.RLock()
+	defer fake.handleAnswerMutex.RUnlock()
 	return len(fake.handleAnswerArgsForCall)
 }
 
@@ -3098,6 +3774,67 @@ func (fake *FakeLocalParticipant) HandleSignalSourceCloseCalls(stub func()) {
 	fake.HandleSignalSourceCloseStub = stub
 }
 
+func (fake *FakeLocalParticipant) HasClientCapability(arg1 types.ClientCapability) bool {
+	fake.hasClientCapabilityMutex.Lock()
+	ret, specificReturn := fake.hasClientCapabilityReturnsOnCall[len(fake.hasClientCapabilityArgsForCall)]
+	fake.hasClientCapabilityArgsForCall = append(fake.hasClientCapabilityArgsForCall, struct {
+		arg1 types.ClientCapability
+	}{arg1})
+	stub := fake.HasClientCapabilityStub
+	fakeReturns := fake.hasClientCapabilityReturns
+	fake.recordInvocation("HasClientCapability", []interface{}{arg1})
+	fake.hasClientCapabilityMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) HasClientCapabilityCallCount() int {
+	fake.hasClientCapabilityMutex.RLock()
+	defer fake.hasClientCapabilityMutex.RUnlock()
+	return len(fake.hasClientCapabilityArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) HasClientCapabilityCalls(stub func(types.ClientCapability) bool) {
+	fake.hasClientCapabilityMutex.Lock()
+	defer fake.hasClientCapabilityMutex.Unlock()
+	fake.HasClientCapabilityStub = stub
+}
+
+func (fake *FakeLocalParticipant) HasClientCapabilityArgsForCall(i int) types.ClientCapability {
+	fake.hasClientCapabilityMutex.RLock()
+	defer fake.hasClientCapabilityMutex.RUnlock()
+	argsForCall := fake.hasClientCapabilityArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLocalParticipant) HasClientCapabilityReturns(result1 bool) {
+	fake.hasClientCapabilityMutex.Lock()
+	defer fake.hasClientCapabilityMutex.Unlock()
+	fake.HasClientCapabilityStub = nil
+	fake.hasClientCapabilityReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) HasClientCapabilityReturnsOnCall(i int, result1 bool) {
+	fake.hasClientCapabilityMutex.Lock()
+	defer fake.hasClientCapabilityMutex.Unlock()
+	fake.HasClientCapabilityStub = nil
+	if fake.hasClientCapabilityReturnsOnCall == nil {
+		fake.hasClientCapabilityReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.hasClientCapabilityReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) HasConnected() bool {
 	fake.hasConnectedMutex.Lock()
 	ret, specificReturn := fake.hasConnectedReturnsOnCall[len(fake.hasConnectedArgsForCall)]
@@ -3616,6 +4353,67 @@ func (fake *FakeLocalParticipant) IsIdleReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) IsInterestedInDataTopic(arg1 string) bool {
+	fake.isInterestedInDataTopicMutex.Lock()
+	ret, specificReturn := fake.isInterestedInDataTopicReturnsOnCall[len(fake.isInterestedInDataTopicArgsForCall)]
+	fake.isInterestedInDataTopicArgsForCall = append(fake.isInterestedInDataTopicArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.IsInterestedInDataTopicStub
+	fakeReturns := fake.isInterestedInDataTopicReturns
+	fake.recordInvocation("IsInterestedInDataTopic", []interface{}{arg1})
+	fake.isInterestedInDataTopicMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) IsInterestedInDataTopicCallCount() int {
+	fake.isInterestedInDataTopicMutex.RLock()
+	defer fake.isInterestedInDataTopicMutex.RUnlock()
+	return len(fake.isInterestedInDataTopicArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) IsInterestedInDataTopicCalls(stub func(string) bool) {
+	fake.isInterestedInDataTopicMutex.Lock()
+	defer fake.isInterestedInDataTopicMutex.Unlock()
+	fake.IsInterestedInDataTopicStub = stub
+}
+
+func (fake *FakeLocalParticipant) IsInterestedInDataTopicArgsForCall(i int) string {
+	fake.isInterestedInDataTopicMutex.RLock()
+	defer fake.isInterestedInDataTopicMutex.RUnlock()
+	argsForCall := fake.isInterestedInDataTopicArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLocalParticipant) IsInterestedInDataTopicReturns(result1 bool) {
+	fake.isInterestedInDataTopicMutex.Lock()
+	defer fake.isInterestedInDataTopicMutex.Unlock()
+	fake.IsInterestedInDataTopicStub = nil
+	fake.isInterestedInDataTopicReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) IsInterestedInDataTopicReturnsOnCall(i int, result1 bool) {
+	fake.isInterestedInDataTopicMutex.Lock()
+	defer fake.isInterestedInDataTopicMutex.Unlock()
+	fake.IsInterestedInDataTopicStub = nil
+	if fake.isInterestedInDataTopicReturnsOnCall == nil {
+		fake.isInterestedInDataTopicReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.isInterestedInDataTopicReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) IsPublisher() bool {
 	fake.isPublisherMutex.Lock()
 	ret, specificReturn := fake.isPublisherReturnsOnCall[len(fake.isPublisherArgsForCall)]
@@ -4188,6 +4986,38 @@ func (fake *FakeLocalParticipant) OnDataPacketArgsForCall(i int) func(types.Loca
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) OnDataSendQueueDrained(arg1 func(types.LocalParticipant, livekit.DataPacket_Kind)) {
+	fake.onDataSendQueueDrainedMutex.Lock()
+	fake.onDataSendQueueDrainedArgsForCall = append(fake.onDataSendQueueDrainedArgsForCall, struct {
+		arg1 func(types.LocalParticipant, livekit.DataPacket_Kind)
+	}{arg1})
+	stub := fake.OnDataSendQueueDrainedStub
+	fake.recordInvocation("OnDataSendQueueDrained", []interface{}{arg1})
+	fake.onDataSendQueueDrainedMutex.Unlock()
+	if stub != nil {
+		fake.OnDataSendQueueDrainedStub(arg1)
+	}
+}
+
+func (fake *FakeLocalParticipant) OnDataSendQueueDrainedCallCount() int {
+	fake.onDataSendQueueDrainedMutex.RLock()
+	defer fake.onDataSendQueueDrainedMutex.RUnlock()
+	return len(fake.onDataSendQueueDrainedArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) OnDataSendQueueDrainedCalls(stub func(func(types.LocalParticipant, livekit.DataPacket_Kind))) {
+	fake.onDataSendQueueDrainedMutex.Lock()
+	defer fake.onDataSendQueueDrainedMutex.Unlock()
+	fake.OnDataSendQueueDrainedStub = stub
+}
+
+func (fake *FakeLocalParticipant) OnDataSendQueueDrainedArgsForCall(i int) func(types.LocalParticipant, livekit.DataPacket_Kind) {
+	fake.onDataSendQueueDrainedMutex.RLock()
+	defer fake.onDataSendQueueDrainedMutex.RUnlock()
+	argsForCall := fake.onDataSendQueueDrainedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalParticipant) OnICEConfigChanged(arg1 func(participant types.LocalParticipant, iceConfig *livekit.ICEConfig)) {
 	fake.onICEConfigChangedMutex.Lock()
 	fake.onICEConfigChangedArgsForCall = append(fake.onICEConfigChangedArgsForCall, struct {
@@ -4284,6 +5114,38 @@ func (fake *FakeLocalParticipant) OnParticipantUpdateArgsForCall(i int) func(typ
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) OnPublishIntentsChanged(arg1 func(types.LocalParticipant)) {
+	fake.onPublishIntentsChangedMutex.Lock()
+	fake.onPublishIntentsChangedArgsForCall = append(fake.onPublishIntentsChangedArgsForCall, struct {
+		arg1 func(types.LocalParticipant)
+	}{arg1})
+	stub := fake.OnPublishIntentsChangedStub
+	fake.recordInvocation("OnPublishIntentsChanged", []interface{}{arg1})
+	fake.onPublishIntentsChangedMutex.Unlock()
+	if stub != nil {
+		fake.OnPublishIntentsChangedStub(arg1)
+	}
+}
+
+func (fake *FakeLocalParticipant) OnPublishIntentsChangedCallCount() int {
+	fake.onPublishIntentsChangedMutex.RLock()
+	defer fake.onPublishIntentsChangedMutex.RUnlock()
+	return len(fake.onPublishIntentsChangedArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) OnPublishIntentsChangedCalls(stub func(func(types.LocalParticipant))) {
+	fake.onPublishIntentsChangedMutex.Lock()
+	defer fake.onPublishIntentsChangedMutex.Unlock()
+	fake.OnPublishIntentsChangedStub = stub
+}
+
+func (fake *FakeLocalParticipant) OnPublishIntentsChangedArgsForCall(i int) func(types.LocalParticipant) {
+	fake.onPublishIntentsChangedMutex.RLock()
+	defer fake.onPublishIntentsChangedMutex.RUnlock()
+	argsForCall := fake.onPublishIntentsChangedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalParticipant) OnStateChange(arg1 func(p types.LocalParticipant, state livekit.ParticipantInfo_State)) {
 	fake.onStateChangeMutex.Lock()
 	fake.onStateChangeArgsForCall = append(fake.onStateChangeArgsForCall, struct {
@@ -4444,6 +5306,126 @@ func (fake *FakeLocalParticipant) OnTrackUpdatedArgsForCall(i int) func(types.Lo
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) PerformDataRPC(arg1 context.Context, arg2 livekit.ParticipantIdentity, arg3 string, arg4 string) (string, error) {
+	fake.performDataRPCMutex.Lock()
+	ret, specificReturn := fake.performDataRPCReturnsOnCall[len(fake.performDataRPCArgsForCall)]
+	fake.performDataRPCArgsForCall = append(fake.performDataRPCArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.ParticipantIdentity
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.PerformDataRPCStub
+	fakeReturns := fake.performDataRPCReturns
+	fake.recordInvocation("PerformDataRPC", []interface{}{arg1, arg2, arg3, arg4})
+	fake.performDataRPCMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLocalParticipant) PerformDataRPCCallCount() int {
+	fake.performDataRPCMutex.RLock()
+	defer fake.performDataRPCMutex.RUnlock()
+	return len(fake.performDataRPCArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) PerformDataRPCCalls(stub func(context.Context, livekit.ParticipantIdentity, string, string) (string, error)) {
+	fake.performDataRPCMutex.Lock()
+	defer fake.performDataRPCMutex.Unlock()
+	fake.PerformDataRPCStub = stub
+}
+
+func (fake *FakeLocalParticipant) PerformDataRPCArgsForCall(i int) (context.Context, livekit.ParticipantIdentity, string, string) {
+	fake.performDataRPCMutex.RLock()
+	defer fake.performDataRPCMutex.RUnlock()
+	argsForCall := fake.performDataRPCArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeLocalParticipant) PerformDataRPCReturns(result1 string, result2 error) {
+	fake.performDataRPCMutex.Lock()
+	defer fake.performDataRPCMutex.Unlock()
+	fake.PerformDataRPCStub = nil
+	fake.performDataRPCReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocalParticipant) PerformDataRPCReturnsOnCall(i int, result1 string, result2 error) {
+	fake.performDataRPCMutex.Lock()
+	defer fake.performDataRPCMutex.Unlock()
+	fake.PerformDataRPCStub = nil
+	if fake.performDataRPCReturnsOnCall == nil {
+		fake.performDataRPCReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.performDataRPCReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocalParticipant) PermissionGroups() []string {
+	fake.permissionGroupsMutex.Lock()
+	ret, specificReturn := fake.permissionGroupsReturnsOnCall[len(fake.permissionGroupsArgsForCall)]
+	fake.permissionGroupsArgsForCall = append(fake.permissionGroupsArgsForCall, struct {
+	}{})
+	stub := fake.PermissionGroupsStub
+	fakeReturns := fake.permissionGroupsReturns
+	fake.recordInvocation("PermissionGroups", []interface{}{})
+	fake.permissionGroupsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) PermissionGroupsCallCount() int {
+	fake.permissionGroupsMutex.RLock()
+	defer fake.permissionGroupsMutex.RUnlock()
+	return len(fake.permissionGroupsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) PermissionGroupsCalls(stub func() []string) {
+	fake.permissionGroupsMutex.Lock()
+	defer fake.permissionGroupsMutex.Unlock()
+	fake.PermissionGroupsStub = stub
+}
+
+func (fake *FakeLocalParticipant) PermissionGroupsReturns(result1 []string) {
+	fake.permissionGroupsMutex.Lock()
+	defer fake.permissionGroupsMutex.Unlock()
+	fake.PermissionGroupsStub = nil
+	fake.permissionGroupsReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) PermissionGroupsReturnsOnCall(i int, result1 []string) {
+	fake.permissionGroupsMutex.Lock()
+	defer fake.permissionGroupsMutex.Unlock()
+	fake.PermissionGroupsStub = nil
+	if fake.permissionGroupsReturnsOnCall == nil {
+		fake.permissionGroupsReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.permissionGroupsReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) ProtocolVersion() types.ProtocolVersion {
 	fake.protocolVersionMutex.Lock()
 	ret, specificReturn := fake.protocolVersionReturnsOnCall[len(fake.protocolVersionArgsForCall)]
@@ -4497,6 +5479,39 @@ func (fake *FakeLocalParticipant) ProtocolVersionReturnsOnCall(i int, result1 ty
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) RegisterDataRPCHandler(arg1 string, arg2 types.DataRPCHandler) {
+	fake.registerDataRPCHandlerMutex.Lock()
+	fake.registerDataRPCHandlerArgsForCall = append(fake.registerDataRPCHandlerArgsForCall, struct {
+		arg1 string
+		arg2 types.DataRPCHandler
+	}{arg1, arg2})
+	stub := fake.RegisterDataRPCHandlerStub
+	fake.recordInvocation("RegisterDataRPCHandler", []interface{}{arg1, arg2})
+	fake.registerDataRPCHandlerMutex.Unlock()
+	if stub != nil {
+		fake.RegisterDataRPCHandlerStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeLocalParticipant) RegisterDataRPCHandlerCallCount() int {
+	fake.registerDataRPCHandlerMutex.RLock()
+	defer fake.registerDataRPCHandlerMutex.RUnlock()
+	return len(fake.registerDataRPCHandlerArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) RegisterDataRPCHandlerCalls(stub func(string, types.DataRPCHandler)) {
+	fake.registerDataRPCHandlerMutex.Lock()
+	defer fake.registerDataRPCHandlerMutex.Unlock()
+	fake.RegisterDataRPCHandlerStub = stub
+}
+
+func (fake *FakeLocalParticipant) RegisterDataRPCHandlerArgsForCall(i int) (string, types.DataRPCHandler) {
+	fake.registerDataRPCHandlerMutex.RLock()
+	defer fake.registerDataRPCHandlerMutex.RUnlock()
+	argsForCall := fake.registerDataRPCHandlerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *FakeLocalParticipant) RemovePublishedTrack(arg1 types.MediaTrack, arg2 bool, arg3 bool) {
 	fake.removePublishedTrackMutex.Lock()
 	fake.removePublishedTrackArgsForCall = append(fake.removePublishedTrackArgsForCall, struct {
@@ -4592,6 +5607,67 @@ func (fake *FakeLocalParticipant) RemoveTrackFromSubscriberReturnsOnCall(i int,
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) SampleResourceUsage(arg1 int) types.ParticipantResourceUsage {
+	fake.sampleResourceUsageMutex.Lock()
+	ret, specificReturn := fake.sampleResourceUsageReturnsOnCall[len(fake.sampleResourceUsageArgsForCall)]
+	fake.sampleResourceUsageArgsForCall = append(fake.sampleResourceUsageArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.SampleResourceUsageStub
+	fakeReturns := fake.sampleResourceUsageReturns
+	fake.recordInvocation("SampleResourceUsage", []interface{}{arg1})
+	fake.sampleResourceUsageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) SampleResourceUsageCallCount() int {
+	fake.sampleResourceUsageMutex.RLock()
+	defer fake.sampleResourceUsageMutex.RUnlock()
+	return len(fake.sampleResourceUsageArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SampleResourceUsageCalls(stub func(int) types.ParticipantResourceUsage) {
+	fake.sampleResourceUsageMutex.Lock()
+	defer fake.sampleResourceUsageMutex.Unlock()
+	fake.SampleResourceUsageStub = stub
+}
+
+func (fake *FakeLocalParticipant) SampleResourceUsageArgsForCall(i int) int {
+	fake.sampleResourceUsageMutex.RLock()
+	defer fake.sampleResourceUsageMutex.RUnlock()
+	argsForCall := fake.sampleResourceUsageArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLocalParticipant) SampleResourceUsageReturns(result1 types.ParticipantResourceUsage) {
+	fake.sampleResourceUsageMutex.Lock()
+	defer fake.sampleResourceUsageMutex.Unlock()
+	fake.SampleResourceUsageStub = nil
+	fake.sampleResourceUsageReturns = struct {
+		result1 types.ParticipantResourceUsage
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) SampleResourceUsageReturnsOnCall(i int, result1 types.ParticipantResourceUsage) {
+	fake.sampleResourceUsageMutex.Lock()
+	defer fake.sampleResourceUsageMutex.Unlock()
+	fake.SampleResourceUsageStub = nil
+	if fake.sampleResourceUsageReturnsOnCall == nil {
+		fake.sampleResourceUsageReturnsOnCall = make(map[int]struct {
+			result1 types.ParticipantResourceUsage
+		})
+	}
+	fake.sampleResourceUsageReturnsOnCall[i] = struct {
+		result1 types.ParticipantResourceUsage
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) SendConnectionQualityUpdate(arg1 *livekit.ConnectionQualityUpdate) error {
 	fake.sendConnectionQualityUpdateMutex.Lock()
 	ret, specificReturn := fake.sendConnectionQualityUpdateReturnsOnCall[len(fake.sendConnectionQualityUpdateArgsForCall)]
@@ -5161,6 +6237,38 @@ func (fake *FakeLocalParticipant) SetICEConfigArgsForCall(i int) *livekit.ICECon
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) SetMaxUplinkBitrate(arg1 int64) {
+	fake.setMaxUplinkBitrateMutex.Lock()
+	fake.setMaxUplinkBitrateArgsForCall = append(fake.setMaxUplinkBitrateArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.SetMaxUplinkBitrateStub
+	fake.recordInvocation("SetMaxUplinkBitrate", []interface{}{arg1})
+	fake.setMaxUplinkBitrateMutex.Unlock()
+	if stub != nil {
+		fake.SetMaxUplinkBitrateStub(arg1)
+	}
+}
+
+func (fake *FakeLocalParticipant) SetMaxUplinkBitrateCallCount() int {
+	fake.setMaxUplinkBitrateMutex.RLock()
+	defer fake.setMaxUplinkBitrateMutex.RUnlock()
+	return len(fake.setMaxUplinkBitrateArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SetMaxUplinkBitrateCalls(stub func(int64)) {
+	fake.setMaxUplinkBitrateMutex.Lock()
+	defer fake.setMaxUplinkBitrateMutex.Unlock()
+	fake.SetMaxUplinkBitrateStub = stub
+}
+
+func (fake *FakeLocalParticipant) SetMaxUplinkBitrateArgsForCall(i int) int64 {
+	fake.setMaxUplinkBitrateMutex.RLock()
+	defer fake.setMaxUplinkBitrateMutex.RUnlock()
+	argsForCall := fake.setMaxUplinkBitrateArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalParticipant) SetMetadata(arg1 string) {
 	fake.setMetadataMutex.Lock()
 	fake.setMetadataArgsForCall = append(fake.setMetadataArgsForCall, struct {
@@ -5193,29 +6301,35 @@ func (fake *FakeLocalParticipant) SetMetadataArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
-func (fake *FakeLocalParticipant) SetMigrateInfo(arg1 *webrtc.SessionDescription, arg2 *webrtc.SessionDescription, arg3 []*livekit.TrackPublishedResponse, arg4 []*livekit.DataChannelInfo) {
-	var arg3Copy []*livekit.TrackPublishedResponse
+func (fake *FakeLocalParticipant) SetMigrateInfo(arg1 *webrtc.SessionDescription, arg2 *webrtc.SessionDescription, arg3 []*types.TrackMapping, arg4 []*livekit.TrackPublishedResponse, arg5 []*livekit.DataChannelInfo) {
+	var arg3Copy []*types.TrackMapping
 	if arg3 != nil {
-		arg3Copy = make([]*livekit.TrackPublishedResponse, len(arg3))
+		arg3Copy = make([]*types.TrackMapping, len(arg3))
 		copy(arg3Copy, arg3)
 	}
-	var arg4Copy []*livekit.DataChannelInfo
+	var arg4Copy []*livekit.TrackPublishedResponse
 	if arg4 != nil {
-		arg4Copy = make([]*livekit.DataChannelInfo, len(arg4))
+		arg4Copy = make([]*livekit.TrackPublishedResponse, len(arg4))
 		copy(arg4Copy, arg4)
 	}
+	var arg5Copy []*livekit.DataChannelInfo
+	if arg5 != nil {
+		arg5Copy = make([]*livekit.DataChannelInfo, len(arg5))
+		copy(arg5Copy, arg5)
+	}
 	fake.setMigrateInfoMutex.Lock()
 	fake.setMigrateInfoArgsForCall = append(fake.setMigrateInfoArgsForCall, struct {
 		arg1 *webrtc.SessionDescription
 		arg2 *webrtc.SessionDescription
-		arg3 []*livekit.TrackPublishedResponse
-		arg4 []*livekit.DataChannelInfo
-	}{arg1, arg2, arg3Copy, arg4Copy})
+		arg3 []*types.TrackMapping
+		arg4 []*livekit.TrackPublishedResponse
+		arg5 []*livekit.DataChannelInfo
+	}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
 	stub := fake.SetMigrateInfoStub
-	fake.recordInvocation("SetMigrateInfo", []interface{}{arg1, arg2, arg3Copy, arg4Copy})
+	fake.recordInvocation("SetMigrateInfo", []interface{}{arg1, arg2, arg3Copy, arg4Copy, arg5Copy})
 	fake.setMigrateInfoMutex.Unlock()
 	if stub != nil {
-		fake.SetMigrateInfoStub(arg1, arg2, arg3, arg4)
+		fake.SetMigrateInfoStub(arg1, arg2, arg3, arg4, arg5)
 	}
 }
 
@@ -5225,17 +6339,17 @@ func (fake *FakeLocalParticipant) SetMigrateInfoCallCount() int {
 	return len(fake.setMigrateInfoArgsForCall)
 }
 
-func (fake *FakeLocalParticipant) SetMigrateInfoCalls(stub func(*webrtc.SessionDescription, *webrtc.SessionDescription, []*livekit.TrackPublishedResponse, []*livekit.DataChannelInfo)) {
+func (fake *FakeLocalParticipant) SetMigrateInfoCalls(stub func(*webrtc.SessionDescription, *webrtc.SessionDescription, []*types.TrackMapping, []*livekit.TrackPublishedResponse, []*livekit.DataChannelInfo)) {
 	fake.setMigrateInfoMutex.Lock()
 	defer fake.setMigrateInfoMutex.Unlock()
 	fake.SetMigrateInfoStub = stub
 }
 
-func (fake *FakeLocalParticipant) SetMigrateInfoArgsForCall(i int) (*webrtc.SessionDescription, *webrtc.SessionDescription, []*livekit.TrackPublishedResponse, []*livekit.DataChannelInfo) {
+func (fake *FakeLocalParticipant) SetMigrateInfoArgsForCall(i int) (*webrtc.SessionDescription, *webrtc.SessionDescription, []*types.TrackMapping, []*livekit.TrackPublishedResponse, []*livekit.DataChannelInfo) {
 	fake.setMigrateInfoMutex.RLock()
 	defer fake.setMigrateInfoMutex.RUnlock()
 	argsForCall := fake.setMigrateInfoArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeLocalParticipant) SetMigrateState(arg1 types.MigrateState) {
@@ -5270,6 +6384,38 @@ func (fake *FakeLocalParticipant) SetMigrateStateArgsForCall(i int) types.Migrat
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) SetMigrationRTPStats(arg1 *types.MigrationRTPStats) {
+	fake.setMigrationRTPStatsMutex.Lock()
+	fake.setMigrationRTPStatsArgsForCall = append(fake.setMigrationRTPStatsArgsForCall, struct {
+		arg1 *types.MigrationRTPStats
+	}{arg1})
+	stub := fake.SetMigrationRTPStatsStub
+	fake.recordInvocation("SetMigrationRTPStats", []interface{}{arg1})
+	fake.setMigrationRTPStatsMutex.Unlock()
+	if stub != nil {
+		fake.SetMigrationRTPStatsStub(arg1)
+	}
+}
+
+func (fake *FakeLocalParticipant) SetMigrationRTPStatsCallCount() int {
+	fake.setMigrationRTPStatsMutex.RLock()
+	defer fake.setMigrationRTPStatsMutex.RUnlock()
+	return len(fake.setMigrationRTPStatsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SetMigrationRTPStatsCalls(stub func(*types.MigrationRTPStats)) {
+	fake.setMigrationRTPStatsMutex.Lock()
+	defer fake.setMigrationRTPStatsMutex.Unlock()
+	fake.SetMigrationRTPStatsStub = stub
+}
+
+func (fake *FakeLocalParticipant) SetMigrationRTPStatsArgsForCall(i int) *types.MigrationRTPStats {
+	fake.setMigrationRTPStatsMutex.RLock()
+	defer fake.setMigrationRTPStatsMutex.RUnlock()
+	argsForCall := fake.setMigrationRTPStatsArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalParticipant) SetName(arg1 string) {
 	fake.setNameMutex.Lock()
 	fake.setNameArgsForCall = append(fake.setNameArgsForCall, struct {
@@ -5302,6 +6448,39 @@ func (fake *FakeLocalParticipant) SetNameArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) SetNetworkConditions(arg1 *netem.Conditions, arg2 *netem.Conditions) {
+	fake.setNetworkConditionsMutex.Lock()
+	fake.setNetworkConditionsArgsForCall = append(fake.setNetworkConditionsArgsForCall, struct {
+		arg1 *netem.Conditions
+		arg2 *netem.Conditions
+	}{arg1, arg2})
+	stub := fake.SetNetworkConditionsStub
+	fake.recordInvocation("SetNetworkConditions", []interface{}{arg1, arg2})
+	fake.setNetworkConditionsMutex.Unlock()
+	if stub != nil {
+		fake.SetNetworkConditionsStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeLocalParticipant) SetNetworkConditionsCallCount() int {
+	fake.setNetworkConditionsMutex.RLock()
+	defer fake.setNetworkConditionsMutex.RUnlock()
+	return len(fake.setNetworkConditionsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SetNetworkConditionsCalls(stub func(*netem.Conditions, *netem.Conditions)) {
+	fake.setNetworkConditionsMutex.Lock()
+	defer fake.setNetworkConditionsMutex.Unlock()
+	fake.SetNetworkConditionsStub = stub
+}
+
+func (fake *FakeLocalParticipant) SetNetworkConditionsArgsForCall(i int) (*netem.Conditions, *netem.Conditions) {
+	fake.setNetworkConditionsMutex.RLock()
+	defer fake.setNetworkConditionsMutex.RUnlock()
+	argsForCall := fake.setNetworkConditionsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *FakeLocalParticipant) SetPermission(arg1 *livekit.ParticipantPermission) bool {
 	fake.setPermissionMutex.Lock()
 	ret, specificReturn := fake.setPermissionReturnsOnCall[len(fake.setPermissionArgsForCall)]
@@ -5363,6 +6542,110 @@ func (fake *FakeLocalParticipant) SetPermissionReturnsOnCall(i int, result1 bool
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) SetPermissionGroups(arg1 []string) bool {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.setPermissionGroupsMutex.Lock()
+	ret, specificReturn := fake.setPermissionGroupsReturnsOnCall[len(fake.setPermissionGroupsArgsForCall)]
+	fake.setPermissionGroupsArgsForCall = append(fake.setPermissionGroupsArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	stub := fake.SetPermissionGroupsStub
+	fakeReturns := fake.setPermissionGroupsReturns
+	fake.recordInvocation("SetPermissionGroups", []interface{}{arg1Copy})
+	fake.setPermissionGroupsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) SetPermissionGroupsCallCount() int {
+	fake.setPermissionGroupsMutex.RLock()
+	defer fake.setPermissionGroupsMutex.RUnlock()
+	return len(fake.setPermissionGroupsArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SetPermissionGroupsCalls(stub func([]string) bool) {
+	fake.setPermissionGroupsMutex.Lock()
+	defer fake.setPermissionGroupsMutex.Unlock()
+	fake.SetPermissionGroupsStub = stub
+}
+
+func (fake *FakeLocalParticipant) SetPermissionGroupsArgsForCall(i int) []string {
+	fake.setPermissionGroupsMutex.RLock()
+	defer fake.setPermissionGroupsMutex.RUnlock()
+	argsForCall := fake.setPermissionGroupsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLocalParticipant) SetPermissionGroupsReturns(result1 bool) {
+	fake.setPermissionGroupsMutex.Lock()
+	defer fake.setPermissionGroupsMutex.Unlock()
+	fake.SetPermissionGroupsStub = nil
+	fake.setPermissionGroupsReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) SetPermissionGroupsReturnsOnCall(i int, result1 bool) {
+	fake.setPermissionGroupsMutex.Lock()
+	defer fake.setPermissionGroupsMutex.Unlock()
+	fake.SetPermissionGroupsStub = nil
+	if fake.setPermissionGroupsReturnsOnCall == nil {
+		fake.setPermissionGroupsReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.setPermissionGroupsReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) SetPlaceholderTracks(arg1 livekit.ParticipantID, arg2 []types.PublishIntent) {
+	var arg2Copy []types.PublishIntent
+	if arg2 != nil {
+		arg2Copy = make([]types.PublishIntent, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.setPlaceholderTracksMutex.Lock()
+	fake.setPlaceholderTracksArgsForCall = append(fake.setPlaceholderTracksArgsForCall, struct {
+		arg1 livekit.ParticipantID
+		arg2 []types.PublishIntent
+	}{arg1, arg2Copy})
+	stub := fake.SetPlaceholderTracksStub
+	fake.recordInvocation("SetPlaceholderTracks", []interface{}{arg1, arg2Copy})
+	fake.setPlaceholderTracksMutex.Unlock()
+	if stub != nil {
+		fake.SetPlaceholderTracksStub(arg1, arg2)
+	}
+}
+
+func (fake *FakeLocalParticipant) SetPlaceholderTracksCallCount() int {
+	fake.setPlaceholderTracksMutex.RLock()
+	defer fake.setPlaceholderTracksMutex.RUnlock()
+	return len(fake.setPlaceholderTracksArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SetPlaceholderTracksCalls(stub func(livekit.ParticipantID, []types.PublishIntent)) {
+	fake.setPlaceholderTracksMutex.Lock()
+	defer fake.setPlaceholderTracksMutex.Unlock()
+	fake.SetPlaceholderTracksStub = stub
+}
+
+func (fake *FakeLocalParticipant) SetPlaceholderTracksArgsForCall(i int) (livekit.ParticipantID, []types.PublishIntent) {
+	fake.setPlaceholderTracksMutex.RLock()
+	defer fake.setPlaceholderTracksMutex.RUnlock()
+	argsForCall := fake.setPlaceholderTracksArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *FakeLocalParticipant) SetResponseSink(arg1 routing.MessageSink) {
 	fake.setResponseSinkMutex.Lock()
 	fake.setResponseSinkArgsForCall = append(fake.setResponseSinkArgsForCall, struct {
@@ -5427,6 +6710,38 @@ func (fake *FakeLocalParticipant) SetSignalSourceValidArgsForCall(i int) bool {
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) SetSubscriberAllocationPolicy(arg1 streamallocator.AllocationPolicy) {
+	fake.setSubscriberAllocationPolicyMutex.Lock()
+	fake.setSubscriberAllocationPolicyArgsForCall = append(fake.setSubscriberAllocationPolicyArgsForCall, struct {
+		arg1 streamallocator.AllocationPolicy
+	}{arg1})
+	stub := fake.SetSubscriberAllocationPolicyStub
+	fake.recordInvocation("SetSubscriberAllocationPolicy", []interface{}{arg1})
+	fake.setSubscriberAllocationPolicyMutex.Unlock()
+	if stub != nil {
+		fake.SetSubscriberAllocationPolicyStub(arg1)
+	}
+}
+
+func (fake *FakeLocalParticipant) SetSubscriberAllocationPolicyCallCount() int {
+	fake.setSubscriberAllocationPolicyMutex.RLock()
+	defer fake.setSubscriberAllocationPolicyMutex.RUnlock()
+	return len(fake.setSubscriberAllocationPolicyArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SetSubscriberAllocationPolicyCalls(stub func(streamallocator.AllocationPolicy)) {
+	fake.setSubscriberAllocationPolicyMutex.Lock()
+	defer fake.setSubscriberAllocationPolicyMutex.Unlock()
+	fake.SetSubscriberAllocationPolicyStub = stub
+}
+
+func (fake *FakeLocalParticipant) SetSubscriberAllocationPolicyArgsForCall(i int) streamallocator.AllocationPolicy {
+	fake.setSubscriberAllocationPolicyMutex.RLock()
+	defer fake.setSubscriberAllocationPolicyMutex.RUnlock()
+	argsForCall := fake.setSubscriberAllocationPolicyArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalParticipant) SetSubscriberAllowPause(arg1 bool) {
 	fake.setSubscriberAllowPauseMutex.Lock()
 	fake.setSubscriberAllowPauseArgsForCall = append(fake.setSubscriberAllowPauseArgsForCall, struct {
@@ -5491,6 +6806,38 @@ func (fake *FakeLocalParticipant) SetSubscriberChannelCapacityArgsForCall(i int)
 	return argsForCall.arg1
 }
 
+func (fake *FakeLocalParticipant) SetSubscriberChannelCapacityCeiling(arg1 int64) {
+	fake.setSubscriberChannelCapacityCeilingMutex.Lock()
+	fake.setSubscriberChannelCapacityCeilingArgsForCall = append(fake.setSubscriberChannelCapacityCeilingArgsForCall, struct {
+		arg1 int64
+	}{arg1})
+	stub := fake.SetSubscriberChannelCapacityCeilingStub
+	fake.recordInvocation("SetSubscriberChannelCapacityCeiling", []interface{}{arg1})
+	fake.setSubscriberChannelCapacityCeilingMutex.Unlock()
+	if stub != nil {
+		fake.SetSubscriberChannelCapacityCeilingStub(arg1)
+	}
+}
+
+func (fake *FakeLocalParticipant) SetSubscriberChannelCapacityCeilingCallCount() int {
+	fake.setSubscriberChannelCapacityCeilingMutex.RLock()
+	defer fake.setSubscriberChannelCapacityCeilingMutex.RUnlock()
+	return len(fake.setSubscriberChannelCapacityCeilingArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) SetSubscriberChannelCapacityCeilingCalls(stub func(int64)) {
+	fake.setSubscriberChannelCapacityCeilingMutex.Lock()
+	defer fake.setSubscriberChannelCapacityCeilingMutex.Unlock()
+	fake.SetSubscriberChannelCapacityCeilingStub = stub
+}
+
+func (fake *FakeLocalParticipant) SetSubscriberChannelCapacityCeilingArgsForCall(i int) int64 {
+	fake.setSubscriberChannelCapacityCeilingMutex.RLock()
+	defer fake.setSubscriberChannelCapacityCeilingMutex.RUnlock()
+	argsForCall := fake.setSubscriberChannelCapacityCeilingArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeLocalParticipant) SetTrackMuted(arg1 livekit.TrackID, arg2 bool, arg3 bool) *livekit.TrackInfo {
 	fake.setTrackMutedMutex.Lock()
 	ret, specificReturn := fake.setTrackMutedReturnsOnCall[len(fake.setTrackMutedArgsForCall)]
@@ -5554,6 +6901,70 @@ func (fake *FakeLocalParticipant) SetTrackMutedReturnsOnCall(i int, result1 *liv
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) StartPacketCapture(arg1 pcap.CaptureParams) (*pcap.Capture, error) {
+	fake.startPacketCaptureMutex.Lock()
+	ret, specificReturn := fake.startPacketCaptureReturnsOnCall[len(fake.startPacketCaptureArgsForCall)]
+	fake.startPacketCaptureArgsForCall = append(fake.startPacketCaptureArgsForCall, struct {
+		arg1 pcap.CaptureParams
+	}{arg1})
+	stub := fake.StartPacketCaptureStub
+	fakeReturns := fake.startPacketCaptureReturns
+	fake.recordInvocation("StartPacketCapture", []interface{}{arg1})
+	fake.startPacketCaptureMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLocalParticipant) StartPacketCaptureCallCount() int {
+	fake.startPacketCaptureMutex.RLock()
+	defer fake.startPacketCaptureMutex.RUnlock()
+	return len(fake.startPacketCaptureArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) StartPacketCaptureCalls(stub func(pcap.CaptureParams) (*pcap.Capture, error)) {
+	fake.startPacketCaptureMutex.Lock()
+	defer fake.startPacketCaptureMutex.Unlock()
+	fake.StartPacketCaptureStub = stub
+}
+
+func (fake *FakeLocalParticipant) StartPacketCaptureArgsForCall(i int) pcap.CaptureParams {
+	fake.startPacketCaptureMutex.RLock()
+	defer fake.startPacketCaptureMutex.RUnlock()
+	argsForCall := fake.startPacketCaptureArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLocalParticipant) StartPacketCaptureReturns(result1 *pcap.Capture, result2 error) {
+	fake.startPacketCaptureMutex.Lock()
+	defer fake.startPacketCaptureMutex.Unlock()
+	fake.StartPacketCaptureStub = nil
+	fake.startPacketCaptureReturns = struct {
+		result1 *pcap.Capture
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLocalParticipant) StartPacketCaptureReturnsOnCall(i int, result1 *pcap.Capture, result2 error) {
+	fake.startPacketCaptureMutex.Lock()
+	defer fake.startPacketCaptureMutex.Unlock()
+	fake.StartPacketCaptureStub = nil
+	if fake.startPacketCaptureReturnsOnCall == nil {
+		fake.startPacketCaptureReturnsOnCall = make(map[int]struct {
+			result1 *pcap.Capture
+			result2 error
+		})
+	}
+	fake.startPacketCaptureReturnsOnCall[i] = struct {
+		result1 *pcap.Capture
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLocalParticipant) State() livekit.ParticipantInfo_State {
 	fake.stateMutex.Lock()
 	ret, specificReturn := fake.stateReturnsOnCall[len(fake.stateArgsForCall)]
@@ -5607,6 +7018,59 @@ func (fake *FakeLocalParticipant) StateReturnsOnCall(i int, result1 livekit.Part
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) StopPacketCapture() error {
+	fake.stopPacketCaptureMutex.Lock()
+	ret, specificReturn := fake.stopPacketCaptureReturnsOnCall[len(fake.stopPacketCaptureArgsForCall)]
+	fake.stopPacketCaptureArgsForCall = append(fake.stopPacketCaptureArgsForCall, struct {
+	}{})
+	stub := fake.StopPacketCaptureStub
+	fakeReturns := fake.stopPacketCaptureReturns
+	fake.recordInvocation("StopPacketCapture", []interface{}{})
+	fake.stopPacketCaptureMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) StopPacketCaptureCallCount() int {
+	fake.stopPacketCaptureMutex.RLock()
+	defer fake.stopPacketCaptureMutex.RUnlock()
+	return len(fake.stopPacketCaptureArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) StopPacketCaptureCalls(stub func() error) {
+	fake.stopPacketCaptureMutex.Lock()
+	defer fake.stopPacketCaptureMutex.Unlock()
+	fake.StopPacketCaptureStub = stub
+}
+
+func (fake *FakeLocalParticipant) StopPacketCaptureReturns(result1 error) {
+	fake.stopPacketCaptureMutex.Lock()
+	defer fake.stopPacketCaptureMutex.Unlock()
+	fake.StopPacketCaptureStub = nil
+	fake.stopPacketCaptureReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) StopPacketCaptureReturnsOnCall(i int, result1 error) {
+	fake.stopPacketCaptureMutex.Lock()
+	defer fake.stopPacketCaptureMutex.Unlock()
+	fake.StopPacketCaptureStub = nil
+	if fake.stopPacketCaptureReturnsOnCall == nil {
+		fake.stopPacketCaptureReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.stopPacketCaptureReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) SubscribeToTrack(arg1 livekit.TrackID) {
 	fake.subscribeToTrackMutex.Lock()
 	fake.subscribeToTrackArgsForCall = append(fake.subscribeToTrackArgsForCall, struct {
@@ -6437,6 +7901,68 @@ func (fake *FakeLocalParticipant) UpdateSubscriptionPermissionReturnsOnCall(i in
 	}{result1}
 }
 
+func (fake *FakeLocalParticipant) UpdateTrackName(arg1 livekit.TrackID, arg2 string) error {
+	fake.updateTrackNameMutex.Lock()
+	ret, specificReturn := fake.updateTrackNameReturnsOnCall[len(fake.updateTrackNameArgsForCall)]
+	fake.updateTrackNameArgsForCall = append(fake.updateTrackNameArgsForCall, struct {
+		arg1 livekit.TrackID
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UpdateTrackNameStub
+	fakeReturns := fake.updateTrackNameReturns
+	fake.recordInvocation("UpdateTrackName", []interface{}{arg1, arg2})
+	fake.updateTrackNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) UpdateTrackNameCallCount() int {
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
+	return len(fake.updateTrackNameArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) UpdateTrackNameCalls(stub func(livekit.TrackID, string) error) {
+	fake.updateTrackNameMutex.Lock()
+	defer fake.updateTrackNameMutex.Unlock()
+	fake.UpdateTrackNameStub = stub
+}
+
+func (fake *FakeLocalParticipant) UpdateTrackNameArgsForCall(i int) (livekit.TrackID, string) {
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
+	argsForCall := fake.updateTrackNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeLocalParticipant) UpdateTrackNameReturns(result1 error) {
+	fake.updateTrackNameMutex.Lock()
+	defer fake.updateTrackNameMutex.Unlock()
+	fake.UpdateTrackNameStub = nil
+	fake.updateTrackNameReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) UpdateTrackNameReturnsOnCall(i int, result1 error) {
+	fake.updateTrackNameMutex.Lock()
+	defer fake.updateTrackNameMutex.Unlock()
+	fake.UpdateTrackNameStub = nil
+	if fake.updateTrackNameReturnsOnCall == nil {
+		fake.updateTrackNameReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateTrackNameReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) UpdateVideoTrack(arg1 *livekit.UpdateLocalVideoTrack) error {
 	fake.updateVideoTrackMutex.Lock()
 	ret, specificReturn := fake.updateVideoTrackReturnsOnCall[len(fake.updateVideoTrackArgsForCall)]
@@ -6713,24 +8239,40 @@ func (fake *FakeLocalParticipant) Invocations() map[string][][]interface{} {
 	defer fake.getDisableSenderReportPassThroughMutex.RUnlock()
 	fake.getICEConnectionDetailsMutex.RLock()
 	defer fake.getICEConnectionDetailsMutex.RUnlock()
+	fake.getICEStatsMutex.RLock()
+	defer fake.getICEStatsMutex.RUnlock()
 	fake.getLoggerMutex.RLock()
 	defer fake.getLoggerMutex.RUnlock()
+	fake.getMigrationRTPStatsMutex.RLock()
+	defer fake.getMigrationRTPStatsMutex.RUnlock()
+	fake.getNetworkConditionsMutex.RLock()
+	defer fake.getNetworkConditionsMutex.RUnlock()
 	fake.getPacerMutex.RLock()
 	defer fake.getPacerMutex.RUnlock()
 	fake.getPendingTrackMutex.RLock()
 	defer fake.getPendingTrackMutex.RUnlock()
 	fake.getPlayoutDelayConfigMutex.RLock()
 	defer fake.getPlayoutDelayConfigMutex.RUnlock()
+	fake.getPreferredCodecsMutex.RLock()
+	defer fake.getPreferredCodecsMutex.RUnlock()
+	fake.getPublishIntentsMutex.RLock()
+	defer fake.getPublishIntentsMutex.RUnlock()
 	fake.getPublishedTrackMutex.RLock()
 	defer fake.getPublishedTrackMutex.RUnlock()
 	fake.getPublishedTracksMutex.RLock()
 	defer fake.getPublishedTracksMutex.RUnlock()
+	fake.getRTXSSRCMutex.RLock()
+	defer fake.getRTXSSRCMutex.RUnlock()
 	fake.getSubscribedParticipantsMutex.RLock()
 	defer fake.getSubscribedParticipantsMutex.RUnlock()
 	fake.getSubscribedTracksMutex.RLock()
 	defer fake.getSubscribedTracksMutex.RUnlock()
+	fake.getTrackMappingsMutex.RLock()
+	defer fake.getTrackMappingsMutex.RUnlock()
 	fake.getTrailerMutex.RLock()
 	defer fake.getTrailerMutex.RUnlock()
+	fake.getUsageMutex.RLock()
+	defer fake.getUsageMutex.RUnlock()
 	fake.handleAnswerMutex.RLock()
 	defer fake.handleAnswerMutex.RUnlock()
 	fake.handleOfferMutex.RLock()
@@ -6741,6 +8283,8 @@ func (fake *FakeLocalParticipant) Invocations() map[string][][]interface{} {
 	defer fake.handleReconnectAndSendResponseMutex.RUnlock()
 	fake.handleSignalSourceCloseMutex.RLock()
 	defer fake.handleSignalSourceCloseMutex.RUnlock()
+	fake.hasClientCapabilityMutex.RLock()
+	defer fake.hasClientCapabilityMutex.RUnlock()
 	fake.hasConnectedMutex.RLock()
 	defer fake.hasConnectedMutex.RUnlock()
 	fake.hasPermissionMutex.RLock()
@@ -6761,6 +8305,8 @@ func (fake *FakeLocalParticipant) Invocations() map[string][][]interface{} {
 	defer fake.isDisconnectedMutex.RUnlock()
 	fake.isIdleMutex.RLock()
 	defer fake.isIdleMutex.RUnlock()
+	fake.isInterestedInDataTopicMutex.RLock()
+	defer fake.isInterestedInDataTopicMutex.RUnlock()
 	fake.isPublisherMutex.RLock()
 	defer fake.isPublisherMutex.RUnlock()
 	fake.isReadyMutex.RLock()
@@ -6787,12 +8333,16 @@ func (fake *FakeLocalParticipant) Invocations() map[string][][]interface{} {
 	defer fake.onCloseMutex.RUnlock()
 	fake.onDataPacketMutex.RLock()
 	defer fake.onDataPacketMutex.RUnlock()
+	fake.onDataSendQueueDrainedMutex.RLock()
+	defer fake.onDataSendQueueDrainedMutex.RUnlock()
 	fake.onICEConfigChangedMutex.RLock()
 	defer fake.onICEConfigChangedMutex.RUnlock()
 	fake.onMigrateStateChangeMutex.RLock()
 	defer fake.onMigrateStateChangeMutex.RUnlock()
 	fake.onParticipantUpdateMutex.RLock()
 	defer fake.onParticipantUpdateMutex.RUnlock()
+	fake.onPublishIntentsChangedMutex.RLock()
+	defer fake.onPublishIntentsChangedMutex.RUnlock()
 	fake.onStateChangeMutex.RLock()
 	defer fake.onStateChangeMutex.RUnlock()
 	fake.onSubscribeStatusChangedMutex.RLock()
@@ -6803,12 +8353,20 @@ func (fake *FakeLocalParticipant) Invocations() map[string][][]interface{} {
 	defer fake.onTrackUnpublishedMutex.RUnlock()
 	fake.onTrackUpdatedMutex.RLock()
 	defer fake.onTrackUpdatedMutex.RUnlock()
+	fake.performDataRPCMutex.RLock()
+	defer fake.performDataRPCMutex.RUnlock()
+	fake.permissionGroupsMutex.RLock()
+	defer fake.permissionGroupsMutex.RUnlock()
 	fake.protocolVersionMutex.RLock()
 	defer fake.protocolVersionMutex.RUnlock()
+	fake.registerDataRPCHandlerMutex.RLock()
+	defer fake.registerDataRPCHandlerMutex.RUnlock()
 	fake.removePublishedTrackMutex.RLock()
 	defer fake.removePublishedTrackMutex.RUnlock()
 	fake.removeTrackFromSubscriberMutex.RLock()
 	defer fake.removeTrackFromSubscriberMutex.RUnlock()
+	fake.sampleResourceUsageMutex.RLock()
+	defer fake.sampleResourceUsageMutex.RUnlock()
 	fake.sendConnectionQualityUpdateMutex.RLock()
 	defer fake.sendConnectionQualityUpdateMutex.RUnlock()
 	fake.sendDataPacketMutex.RLock()
@@ -6829,28 +8387,46 @@ func (fake *FakeLocalParticipant) Invocations() map[string][][]interface{} {
 	defer fake.setAttributesMutex.RUnlock()
 	fake.setICEConfigMutex.RLock()
 	defer fake.setICEConfigMutex.RUnlock()
+	fake.setMaxUplinkBitrateMutex.RLock()
+	defer fake.setMaxUplinkBitrateMutex.RUnlock()
 	fake.setMetadataMutex.RLock()
 	defer fake.setMetadataMutex.RUnlock()
 	fake.setMigrateInfoMutex.RLock()
 	defer fake.setMigrateInfoMutex.RUnlock()
 	fake.setMigrateStateMutex.RLock()
 	defer fake.setMigrateStateMutex.RUnlock()
+	fake.setMigrationRTPStatsMutex.RLock()
+	defer fake.setMigrationRTPStatsMutex.RUnlock()
 	fake.setNameMutex.RLock()
 	defer fake.setNameMutex.RUnlock()
+	fake.setNetworkConditionsMutex.RLock()
+	defer fake.setNetworkConditionsMutex.RUnlock()
 	fake.setPermissionMutex.RLock()
 	defer fake.setPermissionMutex.RUnlock()
+	fake.setPermissionGroupsMutex.RLock()
+	defer fake.setPermissionGroupsMutex.RUnlock()
+	fake.setPlaceholderTracksMutex.RLock()
+	defer fake.setPlaceholderTracksMutex.RUnlock()
 	fake.setResponseSinkMutex.RLock()
 	defer fake.setResponseSinkMutex.RUnlock()
 	fake.setSignalSourceValidMutex.RLock()
 	defer fake.setSignalSourceValidMutex.RUnlock()
+	fake.setSubscriberAllocationPolicyMutex.RLock()
+	defer fake.setSubscriberAllocationPolicyMutex.RUnlock()
 	fake.setSubscriberAllowPauseMutex.RLock()
 	defer fake.setSubscriberAllowPauseMutex.RUnlock()
 	fake.setSubscriberChannelCapacityMutex.RLock()
 	defer fake.setSubscriberChannelCapacityMutex.RUnlock()
+	fake.setSubscriberChannelCapacityCeilingMutex.RLock()
+	defer fake.setSubscriberChannelCapacityCeilingMutex.RUnlock()
 	fake.setTrackMutedMutex.RLock()
 	defer fake.setTrackMutedMutex.RUnlock()
+	fake.startPacketCaptureMutex.RLock()
+	defer fake.startPacketCaptureMutex.RUnlock()
 	fake.stateMutex.RLock()
 	defer fake.stateMutex.RUnlock()
+	fake.stopPacketCaptureMutex.RLock()
+	defer fake.stopPacketCaptureMutex.RUnlock()
 	fake.subscribeToTrackMutex.RLock()
 	defer fake.subscribeToTrackMutex.RUnlock()
 	fake.subscriberAsPrimaryMutex.RLock()
@@ -6887,6 +8463,8 @@ func (fake *FakeLocalParticipant) Invocations() map[string][][]interface{} {
 	defer fake.updateSubscribedTrackSettingsMutex.RUnlock()
 	fake.updateSubscriptionPermissionMutex.RLock()
 	defer fake.updateSubscriptionPermissionMutex.RUnlock()
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
 	fake.updateVideoTrackMutex.RLock()
 	defer fake.updateVideoTrackMutex.RUnlock()
 	fake.verifySubscribeParticipantInfoMutex.RLock()
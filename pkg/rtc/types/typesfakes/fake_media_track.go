@@ -278,6 +278,11 @@ type FakeMediaTrack struct {
 	updateTrackInfoArgsForCall []struct {
 		arg1 *livekit.TrackInfo
 	}
+	UpdateTrackNameStub        func(string)
+	updateTrackNameMutex       sync.RWMutex
+	updateTrackNameArgsForCall []struct {
+		arg1 string
+	}
 	UpdateVideoTrackStub        func(*livekit.UpdateLocalVideoTrack)
 	updateVideoTrackMutex       sync.RWMutex
 	updateVideoTrackArgsForCall []struct {
@@ -1732,6 +1737,38 @@ func (fake *FakeMediaTrack) UpdateTrackInfoArgsForCall(i int) *livekit.TrackInfo
 	return argsForCall.arg1
 }
 
+func (fake *FakeMediaTrack) UpdateTrackName(arg1 string) {
+	fake.updateTrackNameMutex.Lock()
+	fake.updateTrackNameArgsForCall = append(fake.updateTrackNameArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.UpdateTrackNameStub
+	fake.recordInvocation("UpdateTrackName", []interface{}{arg1})
+	fake.updateTrackNameMutex.Unlock()
+	if stub != nil {
+		fake.UpdateTrackNameStub(arg1)
+	}
+}
+
+func (fake *FakeMediaTrack) UpdateTrackNameCallCount() int {
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
+	return len(fake.updateTrackNameArgsForCall)
+}
+
+func (fake *FakeMediaTrack) UpdateTrackNameCalls(stub func(string)) {
+	fake.updateTrackNameMutex.Lock()
+	defer fake.updateTrackNameMutex.Unlock()
+	fake.UpdateTrackNameStub = stub
+}
+
+func (fake *FakeMediaTrack) UpdateTrackNameArgsForCall(i int) string {
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
+	argsForCall := fake.updateTrackNameArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeMediaTrack) UpdateVideoTrack(arg1 *livekit.UpdateLocalVideoTrack) {
 	fake.updateVideoTrackMutex.Lock()
 	fake.updateVideoTrackArgsForCall = append(fake.updateVideoTrackArgsForCall, struct {
@@ -1825,6 +1862,8 @@ func (fake *FakeMediaTrack) Invocations() map[string][][]interface{} {
 	defer fake.updateAudioTrackMutex.RUnlock()
 	fake.updateTrackInfoMutex.RLock()
 	defer fake.updateTrackInfoMutex.RUnlock()
+	fake.updateTrackNameMutex.RLock()
+	defer fake.updateTrackNameMutex.RUnlock()
 	fake.updateVideoTrackMutex.RLock()
 	defer fake.updateVideoTrackMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -19,6 +19,17 @@ type FakeRoom struct {
 	getLocalParticipantsReturnsOnCall map[int]struct {
 		result1 []types.LocalParticipant
 	}
+	GetParticipantStub        func(livekit.ParticipantIdentity) types.LocalParticipant
+	getParticipantMutex       sync.RWMutex
+	getParticipantArgsForCall []struct {
+		arg1 livekit.ParticipantIdentity
+	}
+	getParticipantReturns struct {
+		result1 types.LocalParticipant
+	}
+	getParticipantReturnsOnCall map[int]struct {
+		result1 types.LocalParticipant
+	}
 	IDStub        func() livekit.RoomID
 	iDMutex       sync.RWMutex
 	iDArgsForCall []struct {
@@ -159,6 +170,67 @@ func (fake *FakeRoom) GetLocalParticipantsReturnsOnCall(i int, result1 []types.L
 	}{result1}
 }
 
+func (fake *FakeRoom) GetParticipant(arg1 livekit.ParticipantIdentity) types.LocalParticipant {
+	fake.getParticipantMutex.Lock()
+	ret, specificReturn := fake.getParticipantReturnsOnCall[len(fake.getParticipantArgsForCall)]
+	fake.getParticipantArgsForCall = append(fake.getParticipantArgsForCall, struct {
+		arg1 livekit.ParticipantIdentity
+	}{arg1})
+	stub := fake.GetParticipantStub
+	fakeReturns := fake.getParticipantReturns
+	fake.recordInvocation("GetParticipant", []interface{}{arg1})
+	fake.getParticipantMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRoom) GetParticipantCallCount() int {
+	fake.getParticipantMutex.RLock()
+	defer fake.getParticipantMutex.RUnlock()
+	return len(fake.getParticipantArgsForCall)
+}
+
+func (fake *FakeRoom) GetParticipantCalls(stub func(livekit.ParticipantIdentity) types.LocalParticipant) {
+	fake.getParticipantMutex.Lock()
+	defer fake.getParticipantMutex.Unlock()
+	fake.GetParticipantStub = stub
+}
+
+func (fake *FakeRoom) GetParticipantArgsForCall(i int) livekit.ParticipantIdentity {
+	fake.getParticipantMutex.RLock()
+	defer fake.getParticipantMutex.RUnlock()
+	argsForCall := fake.getParticipantArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRoom) GetParticipantReturns(result1 types.LocalParticipant) {
+	fake.getParticipantMutex.Lock()
+	defer fake.getParticipantMutex.Unlock()
+	fake.GetParticipantStub = nil
+	fake.getParticipantReturns = struct {
+		result1 types.LocalParticipant
+	}{result1}
+}
+
+func (fake *FakeRoom) GetParticipantReturnsOnCall(i int, result1 types.LocalParticipant) {
+	fake.getParticipantMutex.Lock()
+	defer fake.getParticipantMutex.Unlock()
+	fake.GetParticipantStub = nil
+	if fake.getParticipantReturnsOnCall == nil {
+		fake.getParticipantReturnsOnCall = make(map[int]struct {
+			result1 types.LocalParticipant
+		})
+	}
+	fake.getParticipantReturnsOnCall[i] = struct {
+		result1 types.LocalParticipant
+	}{result1}
+}
+
 func (fake *FakeRoom) ID() livekit.RoomID {
 	fake.iDMutex.Lock()
 	ret, specificReturn := fake.iDReturnsOnCall[len(fake.iDArgsForCall)]
@@ -597,6 +669,8 @@ func (fake *FakeRoom) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.getLocalParticipantsMutex.RLock()
 	defer fake.getLocalParticipantsMutex.RUnlock()
+	fake.getParticipantMutex.RLock()
+	defer fake.getParticipantMutex.RUnlock()
 	fake.iDMutex.RLock()
 	defer fake.iDMutex.RUnlock()
 	fake.nameMutex.RLock()
@@ -86,6 +86,21 @@ type FakeSubscribedTrack struct {
 	onCloseArgsForCall []struct {
 		arg1 func(isExpectedToResume bool)
 	}
+	OnPriorityChangeStub        func(func())
+	onPriorityChangeMutex       sync.RWMutex
+	onPriorityChangeArgsForCall []struct {
+		arg1 func()
+	}
+	PriorityStub        func() uint8
+	priorityMutex       sync.RWMutex
+	priorityArgsForCall []struct {
+	}
+	priorityReturns struct {
+		result1 uint8
+	}
+	priorityReturnsOnCall map[int]struct {
+		result1 uint8
+	}
 	PublisherIDStub        func() livekit.ParticipantID
 	publisherIDMutex       sync.RWMutex
 	publisherIDArgsForCall []struct {
@@ -126,6 +141,11 @@ type FakeSubscribedTrack struct {
 	rTPSenderReturnsOnCall map[int]struct {
 		result1 *webrtc.RTPSender
 	}
+	SetAudioOnlyStub        func(bool)
+	setAudioOnlyMutex       sync.RWMutex
+	setAudioOnlyArgsForCall []struct {
+		arg1 bool
+	}
 	SetPublisherMutedStub        func(bool)
 	setPublisherMutedMutex       sync.RWMutex
 	setPublisherMutedArgsForCall []struct {
@@ -589,6 +609,91 @@ func (fake *FakeSubscribedTrack) OnCloseArgsForCall(i int) func(isExpectedToResu
 	return argsForCall.arg1
 }
 
+func (fake *FakeSubscribedTrack) OnPriorityChange(arg1 func()) {
+	fake.onPriorityChangeMutex.Lock()
+	fake.onPriorityChangeArgsForCall = append(fake.onPriorityChangeArgsForCall, struct {
+		arg1 func()
+	}{arg1})
+	stub := fake.OnPriorityChangeStub
+	fake.recordInvocation("OnPriorityChange", []interface{}{arg1})
+	fake.onPriorityChangeMutex.Unlock()
+	if stub != nil {
+		fake.OnPriorityChangeStub(arg1)
+	}
+}
+
+func (fake *FakeSubscribedTrack) OnPriorityChangeCallCount() int {
+	fake.onPriorityChangeMutex.RLock()
+	defer fake.onPriorityChangeMutex.RUnlock()
+	return len(fake.onPriorityChangeArgsForCall)
+}
+
+func (fake *FakeSubscribedTrack) OnPriorityChangeCalls(stub func(func())) {
+	fake.onPriorityChangeMutex.Lock()
+	defer fake.onPriorityChangeMutex.Unlock()
+	fake.OnPriorityChangeStub = stub
+}
+
+func (fake *FakeSubscribedTrack) OnPriorityChangeArgsForCall(i int) func() {
+	fake.onPriorityChangeMutex.RLock()
+	defer fake.onPriorityChangeMutex.RUnlock()
+	argsForCall := fake.onPriorityChangeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSubscribedTrack) Priority() uint8 {
+	fake.priorityMutex.Lock()
+	ret, specificReturn := fake.priorityReturnsOnCall[len(fake.priorityArgsForCall)]
+	fake.priorityArgsForCall = append(fake.priorityArgsForCall, struct {
+	}{})
+	stub := fake.PriorityStub
+	fakeReturns := fake.priorityReturns
+	fake.recordInvocation("Priority", []interface{}{})
+	fake.priorityMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSubscribedTrack) PriorityCallCount() int {
+	fake.priorityMutex.RLock()
+	defer fake.priorityMutex.RUnlock()
+	return len(fake.priorityArgsForCall)
+}
+
+func (fake *FakeSubscribedTrack) PriorityCalls(stub func() uint8) {
+	fake.priorityMutex.Lock()
+	defer fake.priorityMutex.Unlock()
+	fake.PriorityStub = stub
+}
+
+func (fake *FakeSubscribedTrack) PriorityReturns(result1 uint8) {
+	fake.priorityMutex.Lock()
+	defer fake.priorityMutex.Unlock()
+	fake.PriorityStub = nil
+	fake.priorityReturns = struct {
+		result1 uint8
+	}{result1}
+}
+
+func (fake *FakeSubscribedTrack) PriorityReturnsOnCall(i int, result1 uint8) {
+	fake.priorityMutex.Lock()
+	defer fake.priorityMutex.Unlock()
+	fake.PriorityStub = nil
+	if fake.priorityReturnsOnCall == nil {
+		fake.priorityReturnsOnCall = make(map[int]struct {
+			result1 uint8
+		})
+	}
+	fake.priorityReturnsOnCall[i] = struct {
+		result1 uint8
+	}{result1}
+}
+
 func (fake *FakeSubscribedTrack) PublisherID() livekit.ParticipantID {
 	fake.publisherIDMutex.Lock()
 	ret, specificReturn := fake.publisherIDReturnsOnCall[len(fake.publisherIDArgsForCall)]
@@ -801,6 +906,38 @@ func (fake *FakeSubscribedTrack) RTPSenderReturnsOnCall(i int, result1 *webrtc.R
 	}{result1}
 }
 
+func (fake *FakeSubscribedTrack) SetAudioOnly(arg1 bool) {
+	fake.setAudioOnlyMutex.Lock()
+	fake.setAudioOnlyArgsForCall = append(fake.setAudioOnlyArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	stub := fake.SetAudioOnlyStub
+	fake.recordInvocation("SetAudioOnly", []interface{}{arg1})
+	fake.setAudioOnlyMutex.Unlock()
+	if stub != nil {
+		fake.SetAudioOnlyStub(arg1)
+	}
+}
+
+func (fake *FakeSubscribedTrack) SetAudioOnlyCallCount() int {
+	fake.setAudioOnlyMutex.RLock()
+	defer fake.setAudioOnlyMutex.RUnlock()
+	return len(fake.setAudioOnlyArgsForCall)
+}
+
+func (fake *FakeSubscribedTrack) SetAudioOnlyCalls(stub func(bool)) {
+	fake.setAudioOnlyMutex.Lock()
+	defer fake.setAudioOnlyMutex.Unlock()
+	fake.SetAudioOnlyStub = stub
+}
+
+func (fake *FakeSubscribedTrack) SetAudioOnlyArgsForCall(i int) bool {
+	fake.setAudioOnlyMutex.RLock()
+	defer fake.setAudioOnlyMutex.RUnlock()
+	argsForCall := fake.setAudioOnlyArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeSubscribedTrack) SetPublisherMuted(arg1 bool) {
 	fake.setPublisherMutedMutex.Lock()
 	fake.setPublisherMutedArgsForCall = append(fake.setPublisherMutedArgsForCall, struct {
@@ -1070,6 +1207,10 @@ func (fake *FakeSubscribedTrack) Invocations() map[string][][]interface{} {
 	defer fake.needsNegotiationMutex.RUnlock()
 	fake.onCloseMutex.RLock()
 	defer fake.onCloseMutex.RUnlock()
+	fake.onPriorityChangeMutex.RLock()
+	defer fake.onPriorityChangeMutex.RUnlock()
+	fake.priorityMutex.RLock()
+	defer fake.priorityMutex.RUnlock()
 	fake.publisherIDMutex.RLock()
 	defer fake.publisherIDMutex.RUnlock()
 	fake.publisherIdentityMutex.RLock()
@@ -1078,6 +1219,8 @@ func (fake *FakeSubscribedTrack) Invocations() map[string][][]interface{} {
 	defer fake.publisherVersionMutex.RUnlock()
 	fake.rTPSenderMutex.RLock()
 	defer fake.rTPSenderMutex.RUnlock()
+	fake.setAudioOnlyMutex.RLock()
+	defer fake.setAudioOnlyMutex.RUnlock()
 	fake.setPublisherMutedMutex.RLock()
 	defer fake.setPublisherMutedMutex.RUnlock()
 	fake.subscriberMutex.RLock()
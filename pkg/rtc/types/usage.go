@@ -0,0 +1,63 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+const (
+	UsageDirectionUp   = "up"
+	UsageDirectionDown = "down"
+)
+
+// TrackUsage is the cumulative usage of a track in one direction with one codec
+type TrackUsage struct {
+	TrackID   livekit.TrackID `json:"track_id"`
+	Direction string          `json:"direction"`
+	Codec     string          `json:"codec"`
+	// bytes and packets on the wire, including retransmissions and padding
+	Bytes   uint64 `json:"bytes"`
+	Packets uint64 `json:"packets"`
+	// seconds media flowed
+	Duration float64 `json:"duration"`
+}
+
+// ParticipantResourceUsage is the load a participant session puts on this node, as of the last sample
+type ParticipantResourceUsage struct {
+	ParticipantID livekit.ParticipantID       `json:"participant_id"`
+	Identity      livekit.ParticipantIdentity `json:"identity"`
+	// live goroutines started on behalf of the session, including those of its transports and tracks
+	Goroutines int `json:"goroutines"`
+	// operations waiting in queues of the participant and its transports
+	QueuedOps int `json:"queued_ops"`
+	// RTP packets received from and sent to the participant per second, since the previous sample
+	PacketsPerSec float64   `json:"packets_per_sec"`
+	SampledAt     time.Time `json:"sampled_at"`
+}
+
+// ParticipantUsage is the cumulative bandwidth usage of a participant on this node,
+// assembled from RTP stats of tracks it published and subscribed to.
+type ParticipantUsage struct {
+	ParticipantID livekit.ParticipantID       `json:"participant_id"`
+	Identity      livekit.ParticipantIdentity `json:"identity"`
+	JoinedAt      time.Time                   `json:"joined_at"`
+	Duration      float64                     `json:"duration"`
+	BytesUp       uint64                      `json:"bytes_up"`
+	BytesDown     uint64                      `json:"bytes_down"`
+	Tracks        []*TrackUsage               `json:"tracks"`
+}
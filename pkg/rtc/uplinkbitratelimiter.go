@@ -0,0 +1,307 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	uplinkBitrateLimiterInterval = time.Second
+
+	// publisher is considered to ignore REMB when above limit by this factor for a few intervals
+	uplinkOverLimitFactor    = 1.2
+	uplinkOverLimitIntervals = 3
+	// and layers are restored when well below limit for a while
+	uplinkUnderLimitFactor    = 0.6
+	uplinkUnderLimitIntervals = 5
+)
+
+type UplinkBitrateLimiterParams struct {
+	GetPublishedTracks func() []types.MediaTrack
+	WriteRTCP          func(pkts []rtcp.Packet)
+	Logger             logger.Logger
+}
+
+// UplinkBitrateLimiter caps total bitrate of a publisher. Limit is advertised to the publisher
+// using REMB, which clients use as an upper bound of their send side estimate. If the publisher
+// keeps sending above the limit, simulcast layers of its video tracks are disabled one at a time
+// and restored once there is enough headroom. Worker runs only while a limit is set.
+type UplinkBitrateLimiter struct {
+	params UplinkBitrateLimiterParams
+
+	limit atomic.Int64
+
+	lock          sync.Mutex
+	running       bool
+	stopped       bool
+	lastBytes     map[livekit.TrackID]uint64
+	lastAt        time.Time
+	cappedTracks  map[livekit.TrackID]livekit.VideoQuality
+	overLimitRun  int
+	underLimitRun int
+
+	stop chan struct{}
+}
+
+func NewUplinkBitrateLimiter(params UplinkBitrateLimiterParams) *UplinkBitrateLimiter {
+	l := &UplinkBitrateLimiter{
+		params:       params,
+		lastBytes:    make(map[livekit.TrackID]uint64),
+		cappedTracks: make(map[livekit.TrackID]livekit.VideoQuality),
+		stop:         make(chan struct{}),
+	}
+	return l
+}
+
+func (l *UplinkBitrateLimiter) Stop() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.stopped {
+		return
+	}
+	l.stopped = true
+	close(l.stop)
+}
+
+// SetLimit sets max uplink bitrate in bps, 0 for no limit
+func (l *UplinkBitrateLimiter) SetLimit(bps int64) {
+	if bps < 0 {
+		bps = 0
+	}
+	if l.limit.Swap(bps) != bps {
+		l.params.Logger.Infow("setting uplink bitrate limit", "limit", bps)
+	}
+
+	if bps == 0 {
+		// worker restores layers and exits on next tick
+		return
+	}
+
+	l.lock.Lock()
+	if !l.running && !l.stopped {
+		l.running = true
+		go l.worker()
+	}
+	l.lock.Unlock()
+}
+
+func (l *UplinkBitrateLimiter) Limit() int64 {
+	return l.limit.Load()
+}
+
+func (l *UplinkBitrateLimiter) worker() {
+	ticker := time.NewTicker(uplinkBitrateLimiterInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+
+		case <-ticker.C:
+			if !l.update() {
+				return
+			}
+		}
+	}
+}
+
+// returns false when limit is removed and worker should exit
+func (l *UplinkBitrateLimiter) update() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	tracks := make(map[livekit.TrackID]types.LocalMediaTrack)
+	for _, t := range l.params.GetPublishedTracks() {
+		if lt, ok := t.(types.LocalMediaTrack); ok {
+			tracks[t.ID()] = lt
+		}
+	}
+	for trackID := range l.cappedTracks {
+		if tracks[trackID] == nil {
+			delete(l.cappedTracks, trackID)
+		}
+	}
+
+	limit := l.limit.Load()
+	if limit <= 0 {
+		l.restoreAllLocked(tracks)
+		l.lastAt = time.Time{}
+		l.lastBytes = make(map[livekit.TrackID]uint64)
+		l.running = false
+		return false
+	}
+
+	l.sendREMB(tracks, limit)
+
+	bitrate, ok := l.measureLocked(tracks)
+	if !ok {
+		return true
+	}
+
+	switch {
+	case float64(bitrate) > float64(limit)*uplinkOverLimitFactor:
+		l.underLimitRun = 0
+		l.overLimitRun++
+		if l.overLimitRun >= uplinkOverLimitIntervals {
+			l.overLimitRun = 0
+			l.capOneLocked(tracks, bitrate, limit)
+		}
+
+	case float64(bitrate) < float64(limit)*uplinkUnderLimitFactor && len(l.cappedTracks) != 0:
+		l.overLimitRun = 0
+		l.underLimitRun++
+		if l.underLimitRun >= uplinkUnderLimitIntervals {
+			l.underLimitRun = 0
+			l.uncapOneLocked(tracks)
+		}
+
+	default:
+		l.overLimitRun = 0
+		l.underLimitRun = 0
+	}
+	return true
+}
+
+func (l *UplinkBitrateLimiter) sendREMB(tracks map[livekit.TrackID]types.LocalMediaTrack, limit int64) {
+	var ssrcs []uint32
+	for _, t := range tracks {
+		for _, codec := range t.ToProto().Codecs {
+			for _, layer := range codec.Layers {
+				if layer.Ssrc != 0 {
+					ssrcs = append(ssrcs, layer.Ssrc)
+				}
+			}
+		}
+	}
+	if len(ssrcs) == 0 {
+		return
+	}
+
+	l.params.WriteRTCP([]rtcp.Packet{
+		&rtcp.ReceiverEstimatedMaximumBitrate{
+			Bitrate: float32(limit),
+			SSRCs:   ssrcs,
+		},
+	})
+}
+
+func (l *UplinkBitrateLimiter) measureLocked(tracks map[livekit.TrackID]types.LocalMediaTrack) (int64, bool) {
+	now := time.Now()
+	deltaBytes := uint64(0)
+	lastBytes := make(map[livekit.TrackID]uint64, len(tracks))
+	for trackID, t := range tracks {
+		stats := t.GetTrackStats()
+		if stats == nil {
+			continue
+		}
+
+		bytes := stats.Bytes + stats.HeaderBytes + stats.BytesPadding + stats.HeaderBytesPadding
+		if last, ok := l.lastBytes[trackID]; ok && bytes > last {
+			deltaBytes += bytes - last
+		}
+		lastBytes[trackID] = bytes
+	}
+	l.lastBytes = lastBytes
+
+	lastAt := l.lastAt
+	l.lastAt = now
+	if lastAt.IsZero() {
+		return 0, false
+	}
+
+	elapsed := now.Sub(lastAt).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return int64(float64(deltaBytes*8) / elapsed), true
+}
+
+// disables highest layer of the video track currently allowed the highest quality
+func (l *UplinkBitrateLimiter) capOneLocked(tracks map[livekit.TrackID]types.LocalMediaTrack, bitrate int64, limit int64) {
+	var target types.LocalMediaTrack
+	targetQuality := livekit.VideoQuality_LOW
+	for trackID, t := range tracks {
+		if t.Kind() != livekit.TrackType_VIDEO {
+			continue
+		}
+
+		quality, ok := l.cappedTracks[trackID]
+		if !ok {
+			quality = livekit.VideoQuality_HIGH
+		}
+		if quality > targetQuality {
+			target = t
+			targetQuality = quality
+		}
+	}
+	if target == nil {
+		return
+	}
+
+	quality := targetQuality - 1
+	l.params.Logger.Infow(
+		"publisher above uplink bitrate limit, dropping layer",
+		"trackID", target.ID(),
+		"bitrate", bitrate,
+		"limit", limit,
+		"maxQuality", quality,
+	)
+	l.cappedTracks[target.ID()] = quality
+	target.SetUplinkLimitMaxQuality(quality)
+}
+
+func (l *UplinkBitrateLimiter) uncapOneLocked(tracks map[livekit.TrackID]types.LocalMediaTrack) {
+	var target livekit.TrackID
+	targetQuality := livekit.VideoQuality_HIGH
+	for trackID, quality := range l.cappedTracks {
+		if quality < targetQuality {
+			target = trackID
+			targetQuality = quality
+		}
+	}
+	if target == "" {
+		return
+	}
+
+	quality := targetQuality + 1
+	if quality >= livekit.VideoQuality_HIGH {
+		delete(l.cappedTracks, target)
+	} else {
+		l.cappedTracks[target] = quality
+	}
+	l.params.Logger.Debugw("uplink bitrate headroom, restoring layer", "trackID", target, "maxQuality", quality)
+	tracks[target].SetUplinkLimitMaxQuality(quality)
+}
+
+func (l *UplinkBitrateLimiter) restoreAllLocked(tracks map[livekit.TrackID]types.LocalMediaTrack) {
+	for trackID := range l.cappedTracks {
+		tracks[trackID].SetUplinkLimitMaxQuality(livekit.VideoQuality_HIGH)
+	}
+	l.cappedTracks = make(map[livekit.TrackID]livekit.VideoQuality)
+	l.overLimitRun = 0
+	l.underLimitRun = 0
+}
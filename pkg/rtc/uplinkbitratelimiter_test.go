@@ -0,0 +1,101 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestUplinkBitrateLimiter(t *testing.T) {
+	track := &typesfakes.FakeLocalMediaTrack{}
+	track.IDReturns("TR_video")
+	track.KindReturns(livekit.TrackType_VIDEO)
+	track.ToProtoReturns(&livekit.TrackInfo{
+		Codecs: []*livekit.SimulcastCodecInfo{
+			{Layers: []*livekit.VideoLayer{{Quality: livekit.VideoQuality_HIGH, Ssrc: 1234}}},
+		},
+	})
+	bytes := uint64(0)
+	track.GetTrackStatsCalls(func() *livekit.RTPStats {
+		return &livekit.RTPStats{Bytes: bytes}
+	})
+
+	var remb []*rtcp.ReceiverEstimatedMaximumBitrate
+	l := &UplinkBitrateLimiter{
+		params: UplinkBitrateLimiterParams{
+			GetPublishedTracks: func() []types.MediaTrack { return []types.MediaTrack{track} },
+			WriteRTCP: func(pkts []rtcp.Packet) {
+				remb = append(remb, pkts[0].(*rtcp.ReceiverEstimatedMaximumBitrate))
+			},
+			Logger: logger.GetLogger(),
+		},
+		lastBytes:    make(map[livekit.TrackID]uint64),
+		cappedTracks: make(map[livekit.TrackID]livekit.VideoQuality),
+	}
+	// set limit directly to drive updates from the test rather than the worker
+	l.limit.Store(1_000_000)
+
+	// sends at given bitrate for an interval
+	send := func(bps uint64) {
+		bytes += bps / 8
+		l.lastAt = time.Now().Add(-time.Second)
+		l.update()
+	}
+
+	// first update only establishes baseline
+	l.update()
+	require.Len(t, remb, 1)
+	require.Equal(t, float32(1_000_000), remb[0].Bitrate)
+	require.Equal(t, []uint32{1234}, remb[0].SSRCs)
+
+	// ignoring REMB drops a layer after a few intervals
+	for i := 0; i < uplinkOverLimitIntervals-1; i++ {
+		send(2_000_000)
+	}
+	require.Equal(t, 0, track.SetUplinkLimitMaxQualityCallCount())
+	send(2_000_000)
+	require.Equal(t, 1, track.SetUplinkLimitMaxQualityCallCount())
+	require.Equal(t, livekit.VideoQuality_MEDIUM, track.SetUplinkLimitMaxQualityArgsForCall(0))
+
+	// restored with headroom
+	for i := 0; i < uplinkUnderLimitIntervals; i++ {
+		send(100_000)
+	}
+	require.Equal(t, 2, track.SetUplinkLimitMaxQualityCallCount())
+	require.Equal(t, livekit.VideoQuality_HIGH, track.SetUplinkLimitMaxQualityArgsForCall(1))
+	require.Empty(t, l.cappedTracks)
+
+	// removing limit restores layers and stops the worker
+	send(2_000_000)
+	send(2_000_000)
+	send(2_000_000)
+	require.Equal(t, 3, track.SetUplinkLimitMaxQualityCallCount())
+	l.running = true
+	l.limit.Store(0)
+	require.False(t, l.update())
+	require.False(t, l.running)
+	require.Equal(t, 4, track.SetUplinkLimitMaxQualityCallCount())
+	require.Equal(t, livekit.VideoQuality_HIGH, track.SetUplinkLimitMaxQualityArgsForCall(3))
+}
@@ -34,6 +34,8 @@ type UpTrackManagerParams struct {
 	SID              livekit.ParticipantID
 	Logger           logger.Logger
 	VersionGenerator utils.TimedVersionGenerator
+	// resolves permission groups of a subscriber, used when permissions are granted to groups
+	GetPermissionGroups func(identity livekit.ParticipantIdentity) []string
 }
 
 // UpTrackManager manages all uptracks from a participant
@@ -51,6 +53,7 @@ type UpTrackManager struct {
 	subscriptionPermission *livekit.SubscriptionPermission
 	// subscriber permission for published tracks
 	subscriberPermissions map[livekit.ParticipantIdentity]*livekit.TrackPermission // subscriberIdentity => *livekit.TrackPermission
+	groupPermissions      map[string]*livekit.TrackPermission                      // group => *livekit.TrackPermission
 
 	lock sync.RWMutex
 
@@ -210,7 +213,7 @@ func (u *UpTrackManager) UpdateSubscriptionPermission(
 	}
 	u.lock.Unlock()
 
-	u.maybeRevokeSubscriptions()
+	u.maybeRevokeSubscriptions(resolverBySid)
 
 	return nil
 }
@@ -227,10 +230,20 @@ func (u *UpTrackManager) SubscriptionPermission() (*livekit.SubscriptionPermissi
 }
 
 func (u *UpTrackManager) HasPermission(trackID livekit.TrackID, subIdentity livekit.ParticipantIdentity) bool {
+	u.lock.RLock()
+	hasGroups := len(u.groupPermissions) != 0
+	u.lock.RUnlock()
+
+	// resolve groups without holding the lock, resolver looks up participants in the room
+	var subGroups []string
+	if hasGroups && u.params.GetPermissionGroups != nil {
+		subGroups = u.params.GetPermissionGroups(subIdentity)
+	}
+
 	u.lock.RLock()
 	defer u.lock.RUnlock()
 
-	return u.hasPermissionLocked(trackID, subIdentity)
+	return u.hasPermissionLocked(trackID, subIdentity) || u.hasGroupPermissionLocked(trackID, subGroups)
 }
 
 func (u *UpTrackManager) UpdatePublishedAudioTrack(update *livekit.UpdateLocalAudioTrack) types.MediaTrack {
@@ -257,6 +270,18 @@ func (u *UpTrackManager) UpdatePublishedVideoTrack(update *livekit.UpdateLocalVi
 	return track
 }
 
+func (u *UpTrackManager) UpdatePublishedTrackName(trackID livekit.TrackID, name string) types.MediaTrack {
+	track := u.GetPublishedTrack(trackID)
+	if track != nil {
+		track.UpdateTrackName(name)
+		if u.onTrackUpdated != nil {
+			u.onTrackUpdated(track)
+		}
+	}
+
+	return track
+}
+
 func (u *UpTrackManager) AddPublishedTrack(track types.MediaTrack) {
 	u.lock.Lock()
 	if _, ok := u.publishedTracks[track.ID()]; !ok {
@@ -298,12 +323,22 @@ func (u *UpTrackManager) parseSubscriptionPermissionsLocked(
 	if subscriptionPermission.AllParticipants {
 		// everything is allowed, nothing else to do
 		u.subscriberPermissions = nil
+		u.groupPermissions = nil
 		return nil
 	}
 
-	// per participant permissions
+	// per participant and per group permissions
 	subscriberPermissions := make(map[livekit.ParticipantIdentity]*livekit.TrackPermission)
+	var groupPermissions map[string]*livekit.TrackPermission
 	for _, trackPerms := range subscriptionPermission.TrackPermissions {
+		if group, ok := permissionGroupFromTrackPermission(trackPerms); ok {
+			if groupPermissions == nil {
+				groupPermissions = make(map[string]*livekit.TrackPermission)
+			}
+			groupPermissions[group] = trackPerms
+			continue
+		}
+
 		subscriberIdentity := livekit.ParticipantIdentity(trackPerms.ParticipantIdentity)
 		if subscriberIdentity == "" {
 			if trackPerms.ParticipantSid == "" {
@@ -333,6 +368,7 @@ func (u *UpTrackManager) parseSubscriptionPermissionsLocked(
 	}
 
 	u.subscriberPermissions = subscriberPermissions
+	u.groupPermissions = groupPermissions
 
 	return nil
 }
@@ -347,6 +383,20 @@ func (u *UpTrackManager) hasPermissionLocked(trackID livekit.TrackID, subscriber
 		return false
 	}
 
+	return isTrackPermitted(perms, trackID)
+}
+
+func (u *UpTrackManager) hasGroupPermissionLocked(trackID livekit.TrackID, subscriberGroups []string) bool {
+	for _, group := range subscriberGroups {
+		if perms, ok := u.groupPermissions[group]; ok && isTrackPermitted(perms, trackID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTrackPermitted(perms *livekit.TrackPermission, trackID livekit.TrackID) bool {
 	if perms.AllTracks {
 		return true
 	}
@@ -369,23 +419,32 @@ func (u *UpTrackManager) getAllowedSubscribersLocked(trackID livekit.TrackID) []
 
 	allowed := make([]livekit.ParticipantIdentity, 0)
 	for subscriberIdentity, perms := range u.subscriberPermissions {
-		if perms.AllTracks {
+		if isTrackPermitted(perms, trackID) {
 			allowed = append(allowed, subscriberIdentity)
-			continue
-		}
-
-		for _, sid := range perms.TrackSids {
-			if livekit.TrackID(sid) == trackID {
-				allowed = append(allowed, subscriberIdentity)
-				break
-			}
 		}
 	}
 
 	return allowed
 }
 
-func (u *UpTrackManager) maybeRevokeSubscriptions() {
+func (u *UpTrackManager) maybeRevokeSubscriptions(resolverBySid func(participantID livekit.ParticipantID) types.LocalParticipant) {
+	u.lock.RLock()
+	hasGroups := len(u.groupPermissions) != 0
+	publishedTracks := maps.Values(u.publishedTracks)
+	u.lock.RUnlock()
+
+	// when permissions are granted to groups, resolve groups of existing subscribers without holding the lock
+	subscriberGroups := make(map[livekit.ParticipantIdentity][]string)
+	if hasGroups && resolverBySid != nil {
+		for _, track := range publishedTracks {
+			for _, subID := range track.GetAllSubscribers() {
+				if sub := resolverBySid(subID); sub != nil {
+					subscriberGroups[sub.Identity()] = sub.PermissionGroups()
+				}
+			}
+		}
+	}
+
 	u.lock.Lock()
 	defer u.lock.Unlock()
 
@@ -396,6 +455,12 @@ func (u *UpTrackManager) maybeRevokeSubscriptions() {
 			continue
 		}
 
+		for subIdentity, groups := range subscriberGroups {
+			if u.hasGroupPermissionLocked(trackID, groups) {
+				allowed = append(allowed, subIdentity)
+			}
+		}
+
 		track.RevokeDisallowedSubscribers(allowed)
 	}
 }
@@ -326,4 +326,74 @@ func TestSubscriptionPermission(t *testing.T) {
 		require.False(t, um.hasPermissionLocked("screen", "p3"))
 		require.False(t, um.hasPermissionLocked("watch", "p3"))
 	})
+
+	t.Run("checks subscription permission granted to groups", func(t *testing.T) {
+		groups := map[livekit.ParticipantIdentity][]string{
+			"p1": {"presenters"},
+			"p2": {"moderators", "presenters"},
+		}
+		params := defaultUptrackManagerParams
+		params.GetPermissionGroups = func(identity livekit.ParticipantIdentity) []string {
+			return groups[identity]
+		}
+		um := NewUpTrackManager(params)
+		vg := utils.NewDefaultTimedVersionGenerator()
+
+		tra := &typesfakes.FakeMediaTrack{}
+		tra.IDReturns("audio")
+		um.publishedTracks["audio"] = tra
+
+		trv := &typesfakes.FakeMediaTrack{}
+		trv.IDReturns("video")
+		um.publishedTracks["video"] = trv
+
+		subscriptionPermission := &livekit.SubscriptionPermission{
+			TrackPermissions: []*livekit.TrackPermission{
+				{
+					ParticipantSid: PermissionGroupSIDPrefix + "presenters",
+					TrackSids:      []string{"audio"},
+				},
+				{
+					ParticipantSid: PermissionGroupSIDPrefix + "moderators",
+					AllTracks:      true,
+				},
+			},
+		}
+		um.UpdateSubscriptionPermission(subscriptionPermission, vg.Next(), nil)
+		require.True(t, um.HasPermission("audio", "p1"))
+		require.False(t, um.HasPermission("video", "p1"))
+		require.True(t, um.HasPermission("audio", "p2"))
+		require.True(t, um.HasPermission("video", "p2"))
+		require.False(t, um.HasPermission("audio", "p3"))
+
+		// membership changes take effect without updating permissions
+		groups["p3"] = []string{"moderators"}
+		delete(groups, "p1")
+		require.False(t, um.HasPermission("audio", "p1"))
+		require.True(t, um.HasPermission("video", "p3"))
+
+		// identities are never treated as group references
+		subscriptionPermission = &livekit.SubscriptionPermission{
+			TrackPermissions: []*livekit.TrackPermission{
+				{
+					ParticipantIdentity: PermissionGroupSIDPrefix + "moderators",
+					AllTracks:           true,
+				},
+			},
+		}
+		um.UpdateSubscriptionPermission(subscriptionPermission, vg.Next(), nil)
+		require.False(t, um.HasPermission("video", "p3"))
+		require.True(t, um.HasPermission("video", PermissionGroupSIDPrefix+"moderators"))
+	})
+}
+
+func TestParsePermissionGroups(t *testing.T) {
+	require.Nil(t, ParsePermissionGroups(nil))
+	require.Equal(t, []string{"presenters", "moderators"}, ParsePermissionGroups(map[string]string{
+		PermissionGroupsAttribute: " presenters, ,moderators",
+	}))
+
+	attributes := map[string]string{"a": "1", PermissionGroupsAttribute: "presenters"}
+	require.Equal(t, map[string]string{"a": "1"}, withoutPermissionGroups(attributes))
+	require.Equal(t, "presenters", attributes[PermissionGroupsAttribute])
 }
@@ -35,8 +35,9 @@ const (
 type grantsKey struct{}
 
 type grantsValue struct {
-	claims *auth.ClaimGrants
-	apiKey string
+	claims     *auth.ClaimGrants
+	apiKey     string
+	iceServers []*livekit.ICEServer
 }
 
 var (
@@ -96,11 +97,18 @@ func (m *APIKeyAuthMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request,
 			return
 		}
 
+		iceServers, err := iceServersFromToken(authToken)
+		if err != nil {
+			handleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
 		// set grants in context
 		ctx := r.Context()
 		r = r.WithContext(context.WithValue(ctx, grantsKey{}, &grantsValue{
-			claims: grants,
-			apiKey: v.APIKey(),
+			claims:     grants,
+			apiKey:     v.APIKey(),
+			iceServers: iceServers,
 		}))
 	}
 
@@ -125,6 +133,16 @@ func GetAPIKey(ctx context.Context) string {
 	return v.apiKey
 }
 
+// GetICEServers returns ICE servers given to the participant by the access token, nil to use the server's
+func GetICEServers(ctx context.Context) []*livekit.ICEServer {
+	val := ctx.Value(grantsKey{})
+	v, ok := val.(*grantsValue)
+	if !ok {
+		return nil
+	}
+	return v.iceServers
+}
+
 func WithGrants(ctx context.Context, grants *auth.ClaimGrants, apiKey string) context.Context {
 	return context.WithValue(ctx, grantsKey{}, &grantsValue{
 		claims: grants,
@@ -164,6 +182,20 @@ func EnsureAdminPermission(ctx context.Context, room livekit.RoomName) error {
 	return nil
 }
 
+// EnsureNodeAdminPermission checks for grants to create, list and administer rooms,
+// needed for operations affecting every room of a node
+func EnsureNodeAdminPermission(ctx context.Context) error {
+	claims := GetGrants(ctx)
+	if claims == nil || claims.Video == nil || !claims.Video.RoomCreate || !claims.Video.RoomList || !claims.Video.RoomAdmin {
+		return ErrPermissionDenied
+	}
+	if GetTenant(ctx) != "" {
+		// tenants only reach their own rooms
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
 func EnsureCreatePermission(ctx context.Context) error {
 	claims := GetGrants(ctx)
 	if claims == nil || claims.Video == nil || !claims.Video.RoomCreate {
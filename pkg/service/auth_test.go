@@ -15,6 +15,7 @@
 package service_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -72,3 +73,18 @@ func TestAuthMiddleware(t *testing.T) {
 	require.Nil(t, grants)
 	require.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestEnsureNodeAdminPermission(t *testing.T) {
+	ctx := context.Background()
+	require.Error(t, service.EnsureNodeAdminPermission(ctx))
+
+	createOnly := &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true}}
+	require.Error(t, service.EnsureNodeAdminPermission(service.WithGrants(ctx, createOnly, "")))
+
+	admin := &auth.ClaimGrants{Video: &auth.VideoGrant{RoomCreate: true, RoomList: true, RoomAdmin: true}}
+	require.NoError(t, service.EnsureNodeAdminPermission(service.WithGrants(ctx, admin, "")))
+
+	// tenants cannot act on the whole node
+	admin.Attributes = map[string]string{service.TenantAttribute: "t1"}
+	require.Error(t, service.EnsureNodeAdminPermission(service.WithGrants(ctx, admin, "")))
+}
@@ -0,0 +1,195 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/routing/selector"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// DrainStatus reports progress of migrating participants off a draining node
+type DrainStatus struct {
+	Draining              bool       `json:"draining"`
+	StartedAt             time.Time  `json:"started_at"`
+	CompletedAt           *time.Time `json:"completed_at,omitempty"`
+	MigrationRate         float64    `json:"migration_rate"`
+	Rooms                 int        `json:"rooms"`
+	RoomsMigrated         int        `json:"rooms_migrated"`
+	ParticipantsMigrated  int        `json:"participants_migrated"`
+	ParticipantsRemaining int        `json:"participants_remaining"`
+}
+
+type nodeDrain struct {
+	lock   sync.Mutex
+	status *DrainStatus
+}
+
+func (d *nodeDrain) update(fn func(status *DrainStatus)) {
+	d.lock.Lock()
+	fn(d.status)
+	d.lock.Unlock()
+}
+
+// IsDraining returns true once the node has started draining, new participants are not accepted anymore
+func (r *RoomManager) IsDraining() bool {
+	r.drain.lock.Lock()
+	defer r.drain.lock.Unlock()
+
+	return r.drain.status != nil
+}
+
+// GetDrainStatus returns progress of draining, nil when the node is not draining
+func (r *RoomManager) GetDrainStatus() *DrainStatus {
+	r.drain.lock.Lock()
+	defer r.drain.lock.Unlock()
+
+	if r.drain.status == nil {
+		return nil
+	}
+
+	status := *r.drain.status
+	status.ParticipantsRemaining = r.numParticipants()
+	return &status
+}
+
+// DrainNode stops the node from accepting participants and migrates participants of its rooms to other nodes,
+// migrationRate participants per second, 0 for the configured rate. Participants resume on the new node with
+// their previous session descriptions, instead of reconnecting after a hard shutdown.
+// Rooms are migrated one at a time, so that participants of a room end up on the same node: the first
+// participant resuming moves the room off the draining node and the others follow it.
+func (r *RoomManager) DrainNode(migrationRate float64) (*DrainStatus, error) {
+	if migrationRate <= 0 {
+		migrationRate = r.config.Drain.MigrationRate
+	}
+
+	r.drain.lock.Lock()
+	if r.drain.status != nil {
+		// already draining
+		r.drain.lock.Unlock()
+		return r.GetDrainStatus(), nil
+	}
+
+	nodes, err := r.router.ListNodes()
+	if err != nil {
+		r.drain.lock.Unlock()
+		return nil, err
+	}
+	hasTarget := false
+	for _, node := range selector.GetAvailableNodes(nodes) {
+		if node.Id != r.currentNode.Id {
+			hasTarget = true
+			break
+		}
+	}
+	if !hasTarget {
+		r.drain.lock.Unlock()
+		return nil, ErrNoDrainTarget
+	}
+
+	r.lock.RLock()
+	rooms := maps.Values(r.rooms)
+	r.lock.RUnlock()
+
+	r.drain.status = &DrainStatus{
+		Draining:      true,
+		StartedAt:     time.Now(),
+		MigrationRate: migrationRate,
+		Rooms:         len(rooms),
+	}
+	r.drain.lock.Unlock()
+
+	logger.Infow("draining node", "nodeID", r.currentNode.Id, "rooms", len(rooms), "migrationRate", migrationRate)
+	r.router.Drain()
+	drainRooms := make([]drainRoom, 0, len(rooms))
+	for _, room := range rooms {
+		drainRooms = append(drainRooms, room)
+	}
+	go r.drainWorker(drainRooms, migrationRate)
+
+	return r.GetDrainStatus(), nil
+}
+
+// drainRoom is the part of rtc.Room migrated by drainWorker
+type drainRoom interface {
+	Name() livekit.RoomName
+	IsClosed() bool
+	GetParticipants() []types.LocalParticipant
+}
+
+func (r *RoomManager) drainWorker(rooms []drainRoom, migrationRate float64) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / migrationRate))
+	defer ticker.Stop()
+
+	for _, room := range rooms {
+		if room.IsClosed() {
+			r.drain.update(func(status *DrainStatus) { status.RoomsMigrated++ })
+			continue
+		}
+
+		for _, p := range room.GetParticipants() {
+			if p.IsClosed() || p.IsDisconnected() {
+				continue
+			}
+
+			<-ticker.C
+			p.GetLogger().Infow("migrating participant off draining node")
+			p.MaybeStartMigration(true, nil)
+			r.drain.update(func(status *DrainStatus) { status.ParticipantsMigrated++ })
+		}
+
+		// the room stays assigned to this node until its last participant is migrated, clearing it earlier
+		// lets participants not migrated yet and new participants open the room on another node than the
+		// migrated ones
+		r.releaseDrainedRoom(room.Name())
+		r.drain.update(func(status *DrainStatus) { status.RoomsMigrated++ })
+	}
+
+	completedAt := time.Now()
+	r.drain.update(func(status *DrainStatus) { status.CompletedAt = &completedAt })
+	logger.Infow("node drained", "nodeID", r.currentNode.Id, "remaining", r.numParticipants())
+}
+
+// releaseDrainedRoom clears the node of a migrated room, unless a migrated participant has already
+// moved it to another node
+func (r *RoomManager) releaseDrainedRoom(roomName livekit.RoomName) {
+	node, err := r.router.GetNodeForRoom(context.Background(), roomName)
+	if err != nil || node.Id != r.currentNode.Id {
+		return
+	}
+
+	if err := r.router.ClearRoomState(context.Background(), roomName); err != nil {
+		logger.Warnw("could not clear node of drained room", err, "room", roomName)
+	}
+}
+
+func (r *RoomManager) numParticipants() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	n := 0
+	for _, room := range r.rooms {
+		n += room.GetParticipantCount()
+	}
+	return n
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/routing/routingfakes"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+type testDrainRoom struct {
+	name         livekit.RoomName
+	participants []types.LocalParticipant
+}
+
+func (r *testDrainRoom) Name() livekit.RoomName                    { return r.name }
+func (r *testDrainRoom) IsClosed() bool                            { return false }
+func (r *testDrainRoom) GetParticipants() []types.LocalParticipant { return r.participants }
+
+// drainLog records migrations and cleared rooms in order
+type drainLog struct {
+	lock    sync.Mutex
+	entries []string
+	times   []time.Time
+}
+
+func (l *drainLog) add(entry string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = append(l.entries, entry)
+	l.times = append(l.times, time.Now())
+}
+
+func newTestDrainRoom(log *drainLog, name livekit.RoomName, identities ...livekit.ParticipantIdentity) *testDrainRoom {
+	room := &testDrainRoom{name: name}
+	for _, identity := range identities {
+		p := &typesfakes.FakeLocalParticipant{}
+		p.GetLoggerReturns(logger.GetLogger())
+		entry := "migrate " + string(identity)
+		p.MaybeStartMigrationCalls(func(bool, func()) bool {
+			log.add(entry)
+			return true
+		})
+		room.participants = append(room.participants, p)
+	}
+	return room
+}
+
+func newTestDrainRoomManager(log *drainLog, roomNodes map[livekit.RoomName]string) *RoomManager {
+	router := &routingfakes.FakeRouter{}
+	router.GetNodeForRoomCalls(func(_ context.Context, roomName livekit.RoomName) (*livekit.Node, error) {
+		return &livekit.Node{Id: roomNodes[roomName]}, nil
+	})
+	router.ClearRoomStateCalls(func(_ context.Context, roomName livekit.RoomName) error {
+		log.add("clear " + string(roomName))
+		return nil
+	})
+	return &RoomManager{
+		router:      router,
+		currentNode: &livekit.Node{Id: "draining"},
+		drain:       nodeDrain{status: &DrainStatus{}},
+	}
+}
+
+func TestDrainWorker(t *testing.T) {
+	t.Run("room is cleared after its last participant migrated", func(t *testing.T) {
+		log := &drainLog{}
+		r := newTestDrainRoomManager(log, map[livekit.RoomName]string{"a": "draining", "b": "draining"})
+		r.drainWorker([]drainRoom{
+			newTestDrainRoom(log, "a", "a1", "a2"),
+			newTestDrainRoom(log, "b", "b1"),
+		}, 1000)
+
+		require.Equal(t, []string{"migrate a1", "migrate a2", "clear a", "migrate b1", "clear b"}, log.entries)
+		status := r.GetDrainStatus()
+		require.Equal(t, 2, status.RoomsMigrated)
+		require.Equal(t, 3, status.ParticipantsMigrated)
+		require.NotNil(t, status.CompletedAt)
+	})
+
+	t.Run("room moved by a migrated participant is not cleared", func(t *testing.T) {
+		log := &drainLog{}
+		r := newTestDrainRoomManager(log, map[livekit.RoomName]string{"a": "other"})
+		r.drainWorker([]drainRoom{newTestDrainRoom(log, "a", "a1")}, 1000)
+
+		require.Equal(t, []string{"migrate a1"}, log.entries)
+	})
+
+	t.Run("migrations are paced", func(t *testing.T) {
+		log := &drainLog{}
+		r := newTestDrainRoomManager(log, nil)
+		start := time.Now()
+		r.drainWorker([]drainRoom{
+			newTestDrainRoom(log, "a", "a1", "a2"),
+			newTestDrainRoom(log, "b", "b1", "b2"),
+		}, 20)
+
+		// 20 per second, a migration every 50ms across rooms
+		require.Len(t, log.times, 4)
+		prev := start
+		for _, at := range log.times {
+			require.GreaterOrEqual(t, at.Sub(prev), 40*time.Millisecond)
+			prev = at
+		}
+	})
+}
@@ -42,4 +42,17 @@ var (
 	ErrSIPTrunkNotFound                 = psrpc.NewErrorf(psrpc.NotFound, "requested sip trunk does not exist")
 	ErrSIPDispatchRuleNotFound          = psrpc.NewErrorf(psrpc.NotFound, "requested sip dispatch rule does not exist")
 	ErrSIPParticipantNotFound           = psrpc.NewErrorf(psrpc.NotFound, "requested sip participant does not exist")
+	ErrNodeDraining                     = psrpc.NewErrorf(psrpc.Unavailable, "node is draining")
+	ErrNoDrainTarget                    = psrpc.NewErrorf(psrpc.FailedPrecondition, "no other node available to migrate participants to")
+	ErrHLSNotEnabled                    = psrpc.NewErrorf(psrpc.Unavailable, "HLS output is not enabled, hls.output_dir is not set")
+	ErrPacketCaptureNotEnabled          = psrpc.NewErrorf(psrpc.Unavailable, "packet capture is not enabled, rtc.packet_capture.dir is not set")
+	ErrInvalidICEServers                = psrpc.NewErrorf(psrpc.InvalidArgument, "invalid ICE servers in token")
+	ErrCreateRoomRateLimited            = psrpc.NewErrorf(psrpc.ResourceExhausted, "room creation rate exceeded")
+	ErrRoomTenantMismatch               = psrpc.NewErrorf(psrpc.PermissionDenied, "room belongs to another tenant")
+	ErrTenantRoomQuotaExceeded          = psrpc.NewErrorf(psrpc.ResourceExhausted, "tenant room quota exceeded")
+	ErrTenantParticipantQuotaExceeded   = psrpc.NewErrorf(psrpc.ResourceExhausted, "tenant participant quota exceeded")
+	ErrInvalidParticipantListOptions    = psrpc.NewErrorf(psrpc.InvalidArgument, "invalid participant list options")
+	ErrRoomAlreadyHosted                = psrpc.NewErrorf(psrpc.AlreadyExists, "room is already hosted on this node")
+	ErrLoadTestNotEnabled               = psrpc.NewErrorf(psrpc.Unavailable, "load tests are not enabled, load_test.enabled is not set")
+	ErrLoadTestLimitExceeded            = psrpc.NewErrorf(psrpc.InvalidArgument, "load test exceeds max publishers or subscribers")
 )
@@ -0,0 +1,109 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+const (
+	// access tokens are sent on every connection attempt, keep ICE servers claim small
+	maxICEServersClaimSize = 4096
+	maxICEServerURLs       = 8
+)
+
+// iceServersClaim is a custom claim of access tokens with ICE servers for the participant,
+// replacing the ones the server would otherwise hand out, e. g. to force media through own TURN servers.
+//
+//	"iceServers": [{"urls": ["turns:turn.example.com:443?transport=tcp"], "username": "...", "credential": "..."}]
+type iceServersClaim struct {
+	ICEServers []*iceServerClaim `json:"iceServers,omitempty"`
+}
+
+type iceServerClaim struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// iceServersFromToken returns ICE servers carried in the token, token must have been verified
+func iceServersFromToken(token string) ([]*livekit.ICEServer, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidAuthorizationToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidAuthorizationToken
+	}
+
+	claim := iceServersClaim{}
+	if err := json.Unmarshal(payload, &claim); err != nil {
+		return nil, ErrInvalidAuthorizationToken
+	}
+	if len(claim.ICEServers) == 0 {
+		return nil, nil
+	}
+
+	iceServers := make([]*livekit.ICEServer, 0, len(claim.ICEServers))
+	for _, s := range claim.ICEServers {
+		if s == nil {
+			continue
+		}
+		iceServers = append(iceServers, &livekit.ICEServer{
+			Urls:       s.URLs,
+			Username:   s.Username,
+			Credential: s.Credential,
+		})
+	}
+	return iceServers, nil
+}
+
+// validateICEServers checks ICE servers given by a participant token against limits
+func validateICEServers(iceServers []*livekit.ICEServer, maxICEServers int) error {
+	if maxICEServers > 0 && len(iceServers) > maxICEServers {
+		return fmt.Errorf("%w: max %d servers", ErrInvalidICEServers, maxICEServers)
+	}
+
+	size := 0
+	for _, s := range iceServers {
+		if len(s.Urls) == 0 || len(s.Urls) > maxICEServerURLs {
+			return fmt.Errorf("%w: 1 to %d urls per server", ErrInvalidICEServers, maxICEServerURLs)
+		}
+		for _, url := range s.Urls {
+			scheme, _, _ := strings.Cut(url, ":")
+			switch scheme {
+			case "stun", "stuns":
+			case "turn", "turns":
+				if s.Username == "" || s.Credential == "" {
+					return fmt.Errorf("%w: turn server without credentials", ErrInvalidICEServers)
+				}
+			default:
+				return fmt.Errorf("%w: unsupported url %s", ErrInvalidICEServers, url)
+			}
+			size += len(url)
+		}
+		size += len(s.Username) + len(s.Credential)
+	}
+	if size > maxICEServersClaimSize {
+		return fmt.Errorf("%w: max size %d", ErrInvalidICEServers, maxICEServersClaimSize)
+	}
+	return nil
+}
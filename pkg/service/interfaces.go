@@ -50,6 +50,8 @@ type ServiceStore interface {
 	ListRooms(ctx context.Context, roomNames []livekit.RoomName) ([]*livekit.Room, error)
 	LoadParticipant(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) (*livekit.ParticipantInfo, error)
 	ListParticipants(ctx context.Context, roomName livekit.RoomName) ([]*livekit.ParticipantInfo, error)
+	// ListParticipantsPage returns one page of participants of a room matching the options
+	ListParticipantsPage(ctx context.Context, roomName livekit.RoomName, opts ParticipantListOptions) (*ParticipantPage, error)
 }
 
 //counterfeiter:generate . EgressStore
@@ -105,3 +107,35 @@ type AgentStore interface {
 	StoreAgentJob(ctx context.Context, job *livekit.Job) error
 	DeleteAgentJob(ctx context.Context, job *livekit.Job) error
 }
+
+// keeps track of the tenant each room is scoped to
+//
+//counterfeiter:generate . QuotaStore
+type QuotaStore interface {
+	// ReserveTenantRoom scopes a new room to the tenant, it fails with ErrTenantRoomQuotaExceeded
+	// when the tenant already has maxRooms rooms, maxRooms <= 0 is unlimited
+	ReserveTenantRoom(ctx context.Context, roomName livekit.RoomName, tenant string, maxRooms int) error
+	// ReleaseTenantRoom undoes ReserveTenantRoom, deleting a room releases it as well
+	ReleaseTenantRoom(ctx context.Context, roomName livekit.RoomName) error
+	// LoadRoomTenant returns an empty tenant for rooms not scoped to a tenant
+	LoadRoomTenant(ctx context.Context, roomName livekit.RoomName) (string, error)
+	ListTenantRooms(ctx context.Context, tenant string) ([]livekit.RoomName, error)
+}
+
+// keeps track of when rooms with a max duration close
+//
+//counterfeiter:generate . RoomExpiryStore
+type RoomExpiryStore interface {
+	StoreRoomExpiry(ctx context.Context, roomName livekit.RoomName, expiresAt time.Time) error
+	// LoadRoomExpiry returns a zero time for rooms without a max duration
+	LoadRoomExpiry(ctx context.Context, roomName livekit.RoomName) (time.Time, error)
+}
+
+// keeps track of egress bitrate limits of rooms created with a named room configuration overriding the default
+//
+//counterfeiter:generate . RoomEgressBitrateStore
+type RoomEgressBitrateStore interface {
+	StoreRoomMaxEgressBitrate(ctx context.Context, roomName livekit.RoomName, bps int64) error
+	// LoadRoomMaxEgressBitrate returns false for rooms without an override
+	LoadRoomMaxEgressBitrate(ctx context.Context, roomName livekit.RoomName) (int64, bool, error)
+}
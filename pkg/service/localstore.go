@@ -36,18 +36,28 @@ type LocalStore struct {
 	agentDispatches map[livekit.RoomName]map[string]*livekit.AgentDispatch
 	agentJobs       map[livekit.RoomName]map[string]*livekit.Job
 
+	// map of roomName => tenant
+	roomTenants map[livekit.RoomName]string
+	// map of roomName => time the room closes
+	roomExpiries map[livekit.RoomName]time.Time
+	// map of roomName => egress bitrate limit of the room
+	roomMaxEgressBitrates map[livekit.RoomName]int64
+
 	lock       sync.RWMutex
 	globalLock sync.Mutex
 }
 
 func NewLocalStore() *LocalStore {
 	return &LocalStore{
-		rooms:           make(map[livekit.RoomName]*livekit.Room),
-		roomInternal:    make(map[livekit.RoomName]*livekit.RoomInternal),
-		participants:    make(map[livekit.RoomName]map[livekit.ParticipantIdentity]*livekit.ParticipantInfo),
-		agentDispatches: make(map[livekit.RoomName]map[string]*livekit.AgentDispatch),
-		agentJobs:       make(map[livekit.RoomName]map[string]*livekit.Job),
-		lock:            sync.RWMutex{},
+		rooms:                 make(map[livekit.RoomName]*livekit.Room),
+		roomInternal:          make(map[livekit.RoomName]*livekit.RoomInternal),
+		participants:          make(map[livekit.RoomName]map[livekit.ParticipantIdentity]*livekit.ParticipantInfo),
+		agentDispatches:       make(map[livekit.RoomName]map[string]*livekit.AgentDispatch),
+		agentJobs:             make(map[livekit.RoomName]map[string]*livekit.Job),
+		roomTenants:           make(map[livekit.RoomName]string),
+		roomExpiries:          make(map[livekit.RoomName]time.Time),
+		roomMaxEgressBitrates: make(map[livekit.RoomName]int64),
+		lock:                  sync.RWMutex{},
 	}
 }
 
@@ -110,6 +120,9 @@ func (s *LocalStore) DeleteRoom(ctx context.Context, roomName livekit.RoomName)
 	delete(s.roomInternal, livekit.RoomName(room.Name))
 	delete(s.agentDispatches, livekit.RoomName(room.Name))
 	delete(s.agentJobs, livekit.RoomName(room.Name))
+	delete(s.roomTenants, livekit.RoomName(room.Name))
+	delete(s.roomExpiries, livekit.RoomName(room.Name))
+	delete(s.roomMaxEgressBitrates, livekit.RoomName(room.Name))
 	return nil
 }
 
@@ -169,6 +182,14 @@ func (s *LocalStore) ListParticipants(_ context.Context, roomName livekit.RoomNa
 	return items, nil
 }
 
+func (s *LocalStore) ListParticipantsPage(ctx context.Context, roomName livekit.RoomName, opts ParticipantListOptions) (*ParticipantPage, error) {
+	participants, err := s.ListParticipants(ctx, roomName)
+	if err != nil {
+		return nil, err
+	}
+	return pageParticipants(participants, opts)
+}
+
 func (s *LocalStore) DeleteParticipant(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -279,3 +300,82 @@ func (s *LocalStore) DeleteAgentJob(ctx context.Context, job *livekit.Job) error
 
 	return nil
 }
+
+func (s *LocalStore) ReserveTenantRoom(_ context.Context, roomName livekit.RoomName, tenant string, maxRooms int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if maxRooms > 0 {
+		var count int
+		for _, t := range s.roomTenants {
+			if t == tenant {
+				count++
+			}
+		}
+		if count >= maxRooms {
+			return ErrTenantRoomQuotaExceeded
+		}
+	}
+
+	s.roomTenants[roomName] = tenant
+	return nil
+}
+
+func (s *LocalStore) ReleaseTenantRoom(_ context.Context, roomName livekit.RoomName) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.roomTenants, roomName)
+	return nil
+}
+
+func (s *LocalStore) LoadRoomTenant(_ context.Context, roomName livekit.RoomName) (string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.roomTenants[roomName], nil
+}
+
+func (s *LocalStore) ListTenantRooms(_ context.Context, tenant string) ([]livekit.RoomName, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var roomNames []livekit.RoomName
+	for roomName, t := range s.roomTenants {
+		if t == tenant {
+			roomNames = append(roomNames, roomName)
+		}
+	}
+	return roomNames, nil
+}
+
+func (s *LocalStore) StoreRoomExpiry(_ context.Context, roomName livekit.RoomName, expiresAt time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.roomExpiries[roomName] = expiresAt
+	return nil
+}
+
+func (s *LocalStore) LoadRoomExpiry(_ context.Context, roomName livekit.RoomName) (time.Time, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.roomExpiries[roomName], nil
+}
+
+func (s *LocalStore) StoreRoomMaxEgressBitrate(_ context.Context, roomName livekit.RoomName, bps int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.roomMaxEgressBitrates[roomName] = bps
+	return nil
+}
+
+func (s *LocalStore) LoadRoomMaxEgressBitrate(_ context.Context, roomName livekit.RoomName) (int64, bool, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	bps, ok := s.roomMaxEgressBitrates[roomName]
+	return bps, ok, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/pkg/client"
+	"github.com/livekit/psrpc/pkg/info"
+	"github.com/livekit/psrpc/pkg/rand"
+	"github.com/livekit/psrpc/pkg/server"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// ParticipantAdmin is a psrpc service routed by participant topic to the node hosting the participant, like
+// PublishedTrack. It carries the admin operations of this server on participants.
+//
+//   - GetICEStats returns candidate pairs, as their JSON representation
+//   - SetMaxUplinkBitrate takes the bitrate in bps, 0 removes the limit
+//   - StartPacketCapture takes the duration, 0 for the configured maximum, and returns the first capture file
+//   - UpdateTrackName takes the track in sid and the new name in name of TrackInfo
+
+const (
+	participantAdminServiceName             = "ParticipantAdmin"
+	participantAdminGetICEStatsName         = "GetICEStats"
+	participantAdminSetMaxUplinkBitrateName = "SetMaxUplinkBitrate"
+	participantAdminStartPacketCaptureName  = "StartPacketCapture"
+	participantAdminStopPacketCaptureName   = "StopPacketCapture"
+	participantAdminUpdateTrackNameName     = "UpdateTrackName"
+)
+
+var participantAdminMethods = []string{
+	participantAdminGetICEStatsName,
+	participantAdminSetMaxUplinkBitrateName,
+	participantAdminStartPacketCaptureName,
+	participantAdminStopPacketCaptureName,
+	participantAdminUpdateTrackNameName,
+}
+
+func newParticipantAdminServiceDefinition(id string) *info.ServiceDefinition {
+	sd := &info.ServiceDefinition{
+		Name: participantAdminServiceName,
+		ID:   id,
+	}
+	for _, method := range participantAdminMethods {
+		sd.RegisterMethod(method, false, false, true, true)
+	}
+	return sd
+}
+
+type ParticipantAdminClient interface {
+	GetICEStats(ctx context.Context, participant rpc.ParticipantTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*structpb.ListValue, error)
+	SetMaxUplinkBitrate(ctx context.Context, participant rpc.ParticipantTopic, req *wrapperspb.Int64Value, opts ...psrpc.RequestOption) (*emptypb.Empty, error)
+	StartPacketCapture(ctx context.Context, participant rpc.ParticipantTopic, req *durationpb.Duration, opts ...psrpc.RequestOption) (*wrapperspb.StringValue, error)
+	StopPacketCapture(ctx context.Context, participant rpc.ParticipantTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*emptypb.Empty, error)
+	UpdateTrackName(ctx context.Context, participant rpc.ParticipantTopic, req *livekit.TrackInfo, opts ...psrpc.RequestOption) (*emptypb.Empty, error)
+}
+
+type participantAdminClient struct {
+	client *client.RPCClient
+}
+
+func NewParticipantAdminClient(params rpc.ClientParams) (ParticipantAdminClient, error) {
+	rpcClient, err := client.NewRPCClient(newParticipantAdminServiceDefinition(rand.NewClientID()), params.Bus, params.Options()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &participantAdminClient{
+		client: rpcClient,
+	}, nil
+}
+
+func (c *participantAdminClient) GetICEStats(ctx context.Context, participant rpc.ParticipantTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*structpb.ListValue, error) {
+	return client.RequestSingle[*structpb.ListValue](ctx, c.client, participantAdminGetICEStatsName, []string{string(participant)}, req, opts...)
+}
+
+func (c *participantAdminClient) SetMaxUplinkBitrate(ctx context.Context, participant rpc.ParticipantTopic, req *wrapperspb.Int64Value, opts ...psrpc.RequestOption) (*emptypb.Empty, error) {
+	return client.RequestSingle[*emptypb.Empty](ctx, c.client, participantAdminSetMaxUplinkBitrateName, []string{string(participant)}, req, opts...)
+}
+
+func (c *participantAdminClient) StartPacketCapture(ctx context.Context, participant rpc.ParticipantTopic, req *durationpb.Duration, opts ...psrpc.RequestOption) (*wrapperspb.StringValue, error) {
+	return client.RequestSingle[*wrapperspb.StringValue](ctx, c.client, participantAdminStartPacketCaptureName, []string{string(participant)}, req, opts...)
+}
+
+func (c *participantAdminClient) StopPacketCapture(ctx context.Context, participant rpc.ParticipantTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*emptypb.Empty, error) {
+	return client.RequestSingle[*emptypb.Empty](ctx, c.client, participantAdminStopPacketCaptureName, []string{string(participant)}, req, opts...)
+}
+
+func (c *participantAdminClient) UpdateTrackName(ctx context.Context, participant rpc.ParticipantTopic, req *livekit.TrackInfo, opts ...psrpc.RequestOption) (*emptypb.Empty, error) {
+	return client.RequestSingle[*emptypb.Empty](ctx, c.client, participantAdminUpdateTrackNameName, []string{string(participant)}, req, opts...)
+}
+
+// ParticipantAdminHandler performs admin operations on participants hosted on this node, implemented by RoomManager
+type ParticipantAdminHandler interface {
+	GetParticipantICEStats(ctx context.Context, req *livekit.RoomParticipantIdentity) ([]*types.ICEStats, error)
+	SetParticipantMaxUplinkBitrate(ctx context.Context, req *livekit.RoomParticipantIdentity, bps int64) error
+	StartPacketCapture(ctx context.Context, req *livekit.RoomParticipantIdentity, duration time.Duration) (string, error)
+	StopPacketCapture(ctx context.Context, req *livekit.RoomParticipantIdentity) error
+	UpdateParticipantTrackName(ctx context.Context, req *livekit.RoomParticipantIdentity, trackID livekit.TrackID, name string) error
+}
+
+// participantAdminServer answers admin requests for a single participant
+type participantAdminServer struct {
+	participant *livekit.RoomParticipantIdentity
+	handler     ParticipantAdminHandler
+	rpc         *server.RPCServer
+}
+
+func newParticipantAdminServer(
+	participant *livekit.RoomParticipantIdentity,
+	handler ParticipantAdminHandler,
+	bus psrpc.MessageBus,
+	opts ...psrpc.ServerOption,
+) *participantAdminServer {
+	return &participantAdminServer{
+		participant: participant,
+		handler:     handler,
+		rpc:         server.NewRPCServer(newParticipantAdminServiceDefinition(rand.NewServerID()), bus, opts...),
+	}
+}
+
+func (s *participantAdminServer) RegisterParticipantTopic(participant rpc.ParticipantTopic) error {
+	topic := []string{string(participant)}
+	return errors.Join(
+		server.RegisterHandler(s.rpc, participantAdminGetICEStatsName, topic, s.GetICEStats, nil),
+		server.RegisterHandler(s.rpc, participantAdminSetMaxUplinkBitrateName, topic, s.SetMaxUplinkBitrate, nil),
+		server.RegisterHandler(s.rpc, participantAdminStartPacketCaptureName, topic, s.StartPacketCapture, nil),
+		server.RegisterHandler(s.rpc, participantAdminStopPacketCaptureName, topic, s.StopPacketCapture, nil),
+		server.RegisterHandler(s.rpc, participantAdminUpdateTrackNameName, topic, s.UpdateTrackName, nil),
+	)
+}
+
+func (s *participantAdminServer) GetICEStats(ctx context.Context, _ *emptypb.Empty) (*structpb.ListValue, error) {
+	iceStats, err := s.handler.GetParticipantICEStats(ctx, s.participant)
+	if err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return jsonToListValue(iceStats)
+}
+
+func (s *participantAdminServer) SetMaxUplinkBitrate(ctx context.Context, req *wrapperspb.Int64Value) (*emptypb.Empty, error) {
+	if req.Value < 0 {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "invalid bitrate")
+	}
+	if err := s.handler.SetParticipantMaxUplinkBitrate(ctx, s.participant, req.Value); err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *participantAdminServer) StartPacketCapture(ctx context.Context, req *durationpb.Duration) (*wrapperspb.StringValue, error) {
+	file, err := s.handler.StartPacketCapture(ctx, s.participant, req.AsDuration())
+	if err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return wrapperspb.String(file), nil
+}
+
+func (s *participantAdminServer) StopPacketCapture(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.handler.StopPacketCapture(ctx, s.participant); err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *participantAdminServer) UpdateTrackName(ctx context.Context, req *livekit.TrackInfo) (*emptypb.Empty, error) {
+	if err := s.handler.UpdateParticipantTrackName(ctx, s.participant, livekit.TrackID(req.Sid), req.Name); err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *participantAdminServer) Kill() {
+	s.rpc.Close(true)
+}
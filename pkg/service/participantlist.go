@@ -0,0 +1,135 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+type ParticipantSortKey string
+
+const (
+	ParticipantSortIdentity ParticipantSortKey = "identity"
+	ParticipantSortJoinedAt ParticipantSortKey = "joined_at"
+
+	defaultParticipantPageSize = 100
+	maxParticipantPageSize     = 1000
+)
+
+// ParticipantListOptions filters, sorts and paginates participants of a room
+type ParticipantListOptions struct {
+	// participants in any of the states, all states when empty
+	States         []livekit.ParticipantInfo_State
+	IdentityPrefix string
+	// only participants publishing at least one track
+	PublishersOnly bool
+	// sorted by identity when empty
+	SortBy ParticipantSortKey
+	// defaults to 100, up to 1000
+	PageSize int
+	// NextPageToken of the previous page, empty for the first page
+	PageToken string
+}
+
+type ParticipantPage struct {
+	Participants []*livekit.ParticipantInfo
+	// empty on the last page
+	NextPageToken string
+}
+
+// pageParticipants applies list options to participants of a room. pages are keyed by the last participant
+// of the previous page, so participants joining or leaving between pages do not shift the rest of the list
+func pageParticipants(participants []*livekit.ParticipantInfo, opts ParticipantListOptions) (*ParticipantPage, error) {
+	sortBy := opts.SortBy
+	if sortBy == "" {
+		sortBy = ParticipantSortIdentity
+	}
+	if sortBy != ParticipantSortIdentity && sortBy != ParticipantSortJoinedAt {
+		return nil, fmt.Errorf("%w: unknown sort key %s", ErrInvalidParticipantListOptions, sortBy)
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultParticipantPageSize
+	}
+	pageSize = min(pageSize, maxParticipantPageSize)
+
+	filtered := make([]*livekit.ParticipantInfo, 0, len(participants))
+	for _, p := range participants {
+		if matchesParticipantListOptions(p, opts) {
+			filtered = append(filtered, p)
+		}
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		return participantSortValue(filtered[i], sortBy) < participantSortValue(filtered[j], sortBy)
+	})
+
+	start := 0
+	if opts.PageToken != "" {
+		after, err := base64.RawURLEncoding.DecodeString(opts.PageToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid page token", ErrInvalidParticipantListOptions)
+		}
+		start = sort.Search(len(filtered), func(i int) bool {
+			return participantSortValue(filtered[i], sortBy) > string(after)
+		})
+	}
+
+	end := min(start+pageSize, len(filtered))
+	page := &ParticipantPage{
+		Participants: filtered[start:end],
+	}
+	if end < len(filtered) {
+		page.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(participantSortValue(filtered[end-1], sortBy)))
+	}
+	return page, nil
+}
+
+func matchesParticipantListOptions(p *livekit.ParticipantInfo, opts ParticipantListOptions) bool {
+	if len(opts.States) != 0 {
+		found := false
+		for _, state := range opts.States {
+			if p.State == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !strings.HasPrefix(p.Identity, opts.IdentityPrefix) {
+		return false
+	}
+	if opts.PublishersOnly && len(p.Tracks) == 0 {
+		return false
+	}
+	return true
+}
+
+// participantSortValue orders participants by the sort key, identity breaks ties
+func participantSortValue(p *livekit.ParticipantInfo, sortBy ParticipantSortKey) string {
+	if sortBy == ParticipantSortJoinedAt {
+		// zero padded to sort numerically
+		return fmt.Sprintf("%020d\x00%s", p.JoinedAt, p.Identity)
+	}
+	return p.Identity
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func testParticipants() []*livekit.ParticipantInfo {
+	return []*livekit.ParticipantInfo{
+		{Identity: "carol", JoinedAt: 20, State: livekit.ParticipantInfo_ACTIVE, Tracks: []*livekit.TrackInfo{{Sid: "TR_c"}}},
+		{Identity: "alice", JoinedAt: 30, State: livekit.ParticipantInfo_ACTIVE},
+		{Identity: "bob", JoinedAt: 10, State: livekit.ParticipantInfo_JOINING},
+		{Identity: "agent-1", JoinedAt: 20, State: livekit.ParticipantInfo_ACTIVE, Tracks: []*livekit.TrackInfo{{Sid: "TR_a"}}},
+		{Identity: "agent-2", JoinedAt: 40, State: livekit.ParticipantInfo_DISCONNECTED},
+	}
+}
+
+func participantIdentities(participants []*livekit.ParticipantInfo) []string {
+	identities := make([]string, 0, len(participants))
+	for _, p := range participants {
+		identities = append(identities, p.Identity)
+	}
+	return identities
+}
+
+func TestMatchesParticipantListOptions(t *testing.T) {
+	p := &livekit.ParticipantInfo{
+		Identity: "agent-1",
+		State:    livekit.ParticipantInfo_ACTIVE,
+		Tracks:   []*livekit.TrackInfo{{Sid: "TR_a"}},
+	}
+	subscriber := &livekit.ParticipantInfo{Identity: "viewer", State: livekit.ParticipantInfo_JOINED}
+
+	for _, tc := range []struct {
+		name        string
+		participant *livekit.ParticipantInfo
+		opts        ParticipantListOptions
+		matches     bool
+	}{
+		{name: "no options", participant: p, matches: true},
+		{name: "state matches", participant: p, opts: ParticipantListOptions{States: []livekit.ParticipantInfo_State{livekit.ParticipantInfo_JOINED, livekit.ParticipantInfo_ACTIVE}}, matches: true},
+		{name: "state does not match", participant: p, opts: ParticipantListOptions{States: []livekit.ParticipantInfo_State{livekit.ParticipantInfo_JOINING}}},
+		{name: "identity prefix matches", participant: p, opts: ParticipantListOptions{IdentityPrefix: "agent-"}, matches: true},
+		{name: "identity prefix does not match", participant: p, opts: ParticipantListOptions{IdentityPrefix: "user-"}},
+		{name: "publisher", participant: p, opts: ParticipantListOptions{PublishersOnly: true}, matches: true},
+		{name: "not a publisher", participant: subscriber, opts: ParticipantListOptions{PublishersOnly: true}},
+		{name: "all filters", participant: p, opts: ParticipantListOptions{
+			States:         []livekit.ParticipantInfo_State{livekit.ParticipantInfo_ACTIVE},
+			IdentityPrefix: "agent",
+			PublishersOnly: true,
+		}, matches: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.matches, matchesParticipantListOptions(tc.participant, tc.opts))
+		})
+	}
+}
+
+func TestParticipantSortValue(t *testing.T) {
+	early := &livekit.ParticipantInfo{Identity: "zed", JoinedAt: 9}
+	late := &livekit.ParticipantInfo{Identity: "amy", JoinedAt: 10}
+	tied := &livekit.ParticipantInfo{Identity: "bea", JoinedAt: 10}
+
+	for _, tc := range []struct {
+		name   string
+		sortBy ParticipantSortKey
+		first  *livekit.ParticipantInfo
+		second *livekit.ParticipantInfo
+	}{
+		{name: "identity", sortBy: ParticipantSortIdentity, first: late, second: early},
+		// numeric order, 9 sorts before 10 unlike their decimal strings
+		{name: "joined at", sortBy: ParticipantSortJoinedAt, first: early, second: late},
+		{name: "joined at tie broken by identity", sortBy: ParticipantSortJoinedAt, first: late, second: tied},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Less(t, participantSortValue(tc.first, tc.sortBy), participantSortValue(tc.second, tc.sortBy))
+		})
+	}
+}
+
+func TestPageParticipants(t *testing.T) {
+	t.Run("sort and filter", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			opts     ParticipantListOptions
+			expected []string
+		}{
+			{name: "identity by default", expected: []string{"agent-1", "agent-2", "alice", "bob", "carol"}},
+			{name: "joined at", opts: ParticipantListOptions{SortBy: ParticipantSortJoinedAt}, expected: []string{"bob", "agent-1", "carol", "alice", "agent-2"}},
+			{name: "states", opts: ParticipantListOptions{States: []livekit.ParticipantInfo_State{livekit.ParticipantInfo_ACTIVE}}, expected: []string{"agent-1", "alice", "carol"}},
+			{name: "identity prefix", opts: ParticipantListOptions{IdentityPrefix: "agent"}, expected: []string{"agent-1", "agent-2"}},
+			{name: "publishers", opts: ParticipantListOptions{PublishersOnly: true, SortBy: ParticipantSortJoinedAt}, expected: []string{"agent-1", "carol"}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				page, err := pageParticipants(testParticipants(), tc.opts)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, participantIdentities(page.Participants))
+				require.Empty(t, page.NextPageToken)
+			})
+		}
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		for _, sortBy := range []ParticipantSortKey{ParticipantSortIdentity, ParticipantSortJoinedAt} {
+			all, err := pageParticipants(testParticipants(), ParticipantListOptions{SortBy: sortBy})
+			require.NoError(t, err)
+
+			var paged []string
+			opts := ParticipantListOptions{SortBy: sortBy, PageSize: 2}
+			for pages := 1; ; pages++ {
+				page, err := pageParticipants(testParticipants(), opts)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(page.Participants), 2)
+				paged = append(paged, participantIdentities(page.Participants)...)
+				if page.NextPageToken == "" {
+					require.Equal(t, 3, pages)
+					break
+				}
+				opts.PageToken = page.NextPageToken
+			}
+			require.Equal(t, participantIdentities(all.Participants), paged, sortBy)
+		}
+	})
+
+	t.Run("participants changing between pages", func(t *testing.T) {
+		participants := testParticipants()
+		page, err := pageParticipants(participants, ParticipantListOptions{PageSize: 2})
+		require.NoError(t, err)
+		require.Equal(t, []string{"agent-1", "agent-2"}, participantIdentities(page.Participants))
+
+		// the last participant of the page leaves and one sorting before the page token joins,
+		// the next page continues after the token
+		participants = append(participants[:4], &livekit.ParticipantInfo{Identity: "aaron"})
+		page, err = pageParticipants(participants, ParticipantListOptions{PageSize: 2, PageToken: page.NextPageToken})
+		require.NoError(t, err)
+		require.Equal(t, []string{"alice", "bob"}, participantIdentities(page.Participants))
+	})
+
+	t.Run("page size", func(t *testing.T) {
+		participants := make([]*livekit.ParticipantInfo, 0, maxParticipantPageSize+1)
+		for i := 0; i <= maxParticipantPageSize; i++ {
+			participants = append(participants, &livekit.ParticipantInfo{Identity: fmt.Sprintf("p%04d", i)})
+		}
+
+		page, err := pageParticipants(participants, ParticipantListOptions{})
+		require.NoError(t, err)
+		require.Len(t, page.Participants, defaultParticipantPageSize)
+
+		page, err = pageParticipants(participants, ParticipantListOptions{PageSize: maxParticipantPageSize + 1})
+		require.NoError(t, err)
+		require.Len(t, page.Participants, maxParticipantPageSize)
+		require.NotEmpty(t, page.NextPageToken)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := pageParticipants(testParticipants(), ParticipantListOptions{SortBy: "name"})
+		require.ErrorIs(t, err, ErrInvalidParticipantListOptions)
+
+		_, err = pageParticipants(testParticipants(), ParticipantListOptions{PageToken: "not base64!"})
+		require.ErrorIs(t, err, ErrInvalidParticipantListOptions)
+	})
+}
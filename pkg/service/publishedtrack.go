@@ -0,0 +1,123 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/pkg/client"
+	"github.com/livekit/psrpc/pkg/info"
+	"github.com/livekit/psrpc/pkg/rand"
+	"github.com/livekit/psrpc/pkg/server"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// PublishedTrack is a psrpc service routed by participant topic to the node hosting the participant, like the
+// Participant service of protocol. It is defined here as protocol does not have an RPC for track quality floors
+// and caps.
+//
+// SetMinPublishedQuality and SetMaxPublishedQuality take the track in track_sids and the quality in quality
+// of UpdateTrackSettings.
+
+const (
+	publishedTrackServiceName                = "PublishedTrack"
+	publishedTrackSetMinPublishedQualityName = "SetMinPublishedQuality"
+	publishedTrackSetMaxPublishedQualityName = "SetMaxPublishedQuality"
+)
+
+func newPublishedTrackServiceDefinition(id string) *info.ServiceDefinition {
+	sd := &info.ServiceDefinition{
+		Name: publishedTrackServiceName,
+		ID:   id,
+	}
+	sd.RegisterMethod(publishedTrackSetMinPublishedQualityName, false, false, true, true)
+	sd.RegisterMethod(publishedTrackSetMaxPublishedQualityName, false, false, true, true)
+	return sd
+}
+
+type PublishedTrackClient interface {
+	SetMinPublishedQuality(ctx context.Context, participant rpc.ParticipantTopic, req *livekit.UpdateTrackSettings, opts ...psrpc.RequestOption) (*livekit.TrackInfo, error)
+	SetMaxPublishedQuality(ctx context.Context, participant rpc.ParticipantTopic, req *livekit.UpdateTrackSettings, opts ...psrpc.RequestOption) (*livekit.TrackInfo, error)
+}
+
+type publishedTrackClient struct {
+	client *client.RPCClient
+}
+
+func NewPublishedTrackClient(params rpc.ClientParams) (PublishedTrackClient, error) {
+	rpcClient, err := client.NewRPCClient(newPublishedTrackServiceDefinition(rand.NewClientID()), params.Bus, params.Options()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &publishedTrackClient{
+		client: rpcClient,
+	}, nil
+}
+
+func (c *publishedTrackClient) SetMinPublishedQuality(ctx context.Context, participant rpc.ParticipantTopic, req *livekit.UpdateTrackSettings, opts ...psrpc.RequestOption) (*livekit.TrackInfo, error) {
+	return client.RequestSingle[*livekit.TrackInfo](ctx, c.client, publishedTrackSetMinPublishedQualityName, []string{string(participant)}, req, opts...)
+}
+
+func (c *publishedTrackClient) SetMaxPublishedQuality(ctx context.Context, participant rpc.ParticipantTopic, req *livekit.UpdateTrackSettings, opts ...psrpc.RequestOption) (*livekit.TrackInfo, error) {
+	return client.RequestSingle[*livekit.TrackInfo](ctx, c.client, publishedTrackSetMaxPublishedQualityName, []string{string(participant)}, req, opts...)
+}
+
+// publishedTrackServer answers requests for tracks of a single participant
+type publishedTrackServer struct {
+	participant types.LocalParticipant
+	rpc         *server.RPCServer
+}
+
+func newPublishedTrackServer(participant types.LocalParticipant, bus psrpc.MessageBus, opts ...psrpc.ServerOption) *publishedTrackServer {
+	return &publishedTrackServer{
+		participant: participant,
+		rpc:         server.NewRPCServer(newPublishedTrackServiceDefinition(rand.NewServerID()), bus, opts...),
+	}
+}
+
+func (s *publishedTrackServer) RegisterParticipantTopic(participant rpc.ParticipantTopic) error {
+	topic := []string{string(participant)}
+	return errors.Join(
+		server.RegisterHandler(s.rpc, publishedTrackSetMinPublishedQualityName, topic, s.SetMinPublishedQuality, nil),
+		server.RegisterHandler(s.rpc, publishedTrackSetMaxPublishedQualityName, topic, s.SetMaxPublishedQuality, nil),
+	)
+}
+
+func (s *publishedTrackServer) SetMinPublishedQuality(ctx context.Context, req *livekit.UpdateTrackSettings) (*livekit.TrackInfo, error) {
+	if len(req.TrackSids) != 1 {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "expected a single track")
+	}
+	return setPublishedTrackMinQuality(s.participant, livekit.TrackID(req.TrackSids[0]), req.Quality)
+}
+
+func (s *publishedTrackServer) SetMaxPublishedQuality(ctx context.Context, req *livekit.UpdateTrackSettings) (*livekit.TrackInfo, error) {
+	if len(req.TrackSids) != 1 {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "expected a single track")
+	}
+	if req.Quality == livekit.VideoQuality_OFF {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "invalid quality")
+	}
+	return setPublishedTrackMaxQuality(s.participant, livekit.TrackID(req.TrackSids[0]), req.Quality)
+}
+
+func (s *publishedTrackServer) Kill() {
+	s.rpc.Close(true)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestPublishedTrackMaxQuality(t *testing.T) {
+	bus := psrpc.NewLocalMessageBus()
+	track := &typesfakes.FakeLocalMediaTrack{}
+	track.ToProtoReturns(&livekit.TrackInfo{Sid: "TR_1"})
+	participant := &typesfakes.FakeLocalParticipant{}
+	participant.GetLoggerReturns(logger.GetLogger())
+	participant.GetPublishedTrackReturns(track)
+
+	s := newPublishedTrackServer(participant, bus)
+	require.NoError(t, s.RegisterParticipantTopic(rpc.FormatParticipantTopic("room", "publisher")))
+	t.Cleanup(s.Kill)
+
+	c, err := NewPublishedTrackClient(rpc.ClientParams{Bus: bus})
+	require.NoError(t, err)
+	topic := rpc.FormatParticipantTopic("room", "publisher")
+
+	ti, err := c.SetMaxPublishedQuality(context.Background(), topic, &livekit.UpdateTrackSettings{
+		TrackSids: []string{"TR_1"},
+		Quality:   livekit.VideoQuality_MEDIUM,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "TR_1", ti.Sid)
+	require.Equal(t, 1, track.SetMaxPublishedQualityCallCount())
+	require.Equal(t, livekit.VideoQuality_MEDIUM, track.SetMaxPublishedQualityArgsForCall(0))
+
+	// OFF is not a cap, the publisher would stop sending
+	_, err = c.SetMaxPublishedQuality(context.Background(), topic, &livekit.UpdateTrackSettings{
+		TrackSids: []string{"TR_1"},
+		Quality:   livekit.VideoQuality_OFF,
+	})
+	require.Equal(t, http.StatusBadRequest, adminErrorStatus(err))
+
+	participant.GetPublishedTrackReturns(nil)
+	_, err = c.SetMaxPublishedQuality(context.Background(), topic, &livekit.UpdateTrackSettings{
+		TrackSids: []string{"TR_2"},
+		Quality:   livekit.VideoQuality_HIGH,
+	})
+	require.Equal(t, http.StatusNotFound, adminErrorStatus(err))
+	require.Equal(t, 1, track.SetMaxPublishedQualityCallCount())
+}
@@ -42,6 +42,17 @@ const (
 	RoomsKey        = "rooms"
 	RoomInternalKey = "room_internal"
 
+	// RoomTenantKey is hash of room_name => tenant
+	RoomTenantKey = "room_tenant"
+	// TenantRoomCountPrefix is a counter of rooms scoped to the tenant
+	TenantRoomCountPrefix = "tenant_rooms:"
+
+	// RoomExpiryKey is hash of room_name => unix millis at which the room closes
+	RoomExpiryKey = "room_expiry"
+
+	// RoomMaxEgressBitrateKey is hash of room_name => egress bitrate limit (bps) of the room
+	RoomMaxEgressBitrateKey = "room_max_egress_bitrate"
+
 	// EgressKey is a hash of egressID => egress info
 	EgressKey        = "egress"
 	EndedEgressKey   = "ended_egress"
@@ -66,6 +77,8 @@ const (
 	maxRetries = 5
 )
 
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
 type RedisStore struct {
 	rc           redis.UniversalClient
 	unlockScript *redis.Script
@@ -231,6 +244,9 @@ func (s *RedisStore) DeleteRoom(ctx context.Context, roomName livekit.RoomName)
 	if err == ErrRoomNotFound {
 		return nil
 	}
+	if err = s.ReleaseTenantRoom(ctx, roomName); err != nil {
+		return err
+	}
 
 	pp := s.rc.Pipeline()
 	pp.HDel(s.ctx, RoomsKey, string(roomName))
@@ -238,6 +254,8 @@ func (s *RedisStore) DeleteRoom(ctx context.Context, roomName livekit.RoomName)
 	pp.Del(s.ctx, RoomParticipantsPrefix+string(roomName))
 	pp.Del(s.ctx, AgentDispatchPrefix+string(roomName))
 	pp.Del(s.ctx, AgentJobPrefix+string(roomName))
+	pp.HDel(s.ctx, RoomExpiryKey, string(roomName))
+	pp.HDel(s.ctx, RoomMaxEgressBitrateKey, string(roomName))
 
 	_, err = pp.Exec(s.ctx)
 	return err
@@ -330,6 +348,37 @@ func (s *RedisStore) ListParticipants(_ context.Context, roomName livekit.RoomNa
 	return participants, nil
 }
 
+func (s *RedisStore) ListParticipantsPage(ctx context.Context, roomName livekit.RoomName, opts ParticipantListOptions) (*ParticipantPage, error) {
+	if opts.IdentityPrefix == "" {
+		participants, err := s.ListParticipants(ctx, roomName)
+		if err != nil {
+			return nil, err
+		}
+		return pageParticipants(participants, opts)
+	}
+
+	// only load participants matching the prefix
+	key := RoomParticipantsPrefix + string(roomName)
+	match := redisGlobEscaper.Replace(opts.IdentityPrefix) + "*"
+	var participants []*livekit.ParticipantInfo
+	iter := s.rc.HScan(s.ctx, key, 0, match, 0).Iterator()
+	for i := 0; iter.Next(s.ctx); i++ {
+		// keys and values alternate
+		if i%2 == 0 {
+			continue
+		}
+		pi := livekit.ParticipantInfo{}
+		if err := proto.Unmarshal([]byte(iter.Val()), &pi); err != nil {
+			return nil, err
+		}
+		participants = append(participants, &pi)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return pageParticipants(participants, opts)
+}
+
 func (s *RedisStore) DeleteParticipant(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error {
 	key := RoomParticipantsPrefix + string(roomName)
 
@@ -922,6 +971,94 @@ func (s *RedisStore) DeleteAgentJob(_ context.Context, job *livekit.Job) error {
 	return s.rc.HDel(s.ctx, key, job.Id).Err()
 }
 
+func (s *RedisStore) ReserveTenantRoom(_ context.Context, roomName livekit.RoomName, tenant string, maxRooms int) error {
+	// counting first, so that concurrent reservations cannot both take the last room of the quota
+	key := TenantRoomCountPrefix + tenant
+	count, err := s.rc.Incr(s.ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if maxRooms > 0 && count > int64(maxRooms) {
+		s.rc.Decr(s.ctx, key)
+		return ErrTenantRoomQuotaExceeded
+	}
+
+	if err = s.rc.HSet(s.ctx, RoomTenantKey, string(roomName), tenant).Err(); err != nil {
+		s.rc.Decr(s.ctx, key)
+		return err
+	}
+	return nil
+}
+
+func (s *RedisStore) ReleaseTenantRoom(_ context.Context, roomName livekit.RoomName) error {
+	tenant, err := s.rc.HGet(s.ctx, RoomTenantKey, string(roomName)).Result()
+	if err == redis.Nil {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// only the caller removing the tenant of the room uncounts it
+	removed, err := s.rc.HDel(s.ctx, RoomTenantKey, string(roomName)).Result()
+	if err != nil || removed == 0 {
+		return err
+	}
+	return s.rc.Decr(s.ctx, TenantRoomCountPrefix+tenant).Err()
+}
+
+func (s *RedisStore) LoadRoomTenant(_ context.Context, roomName livekit.RoomName) (string, error) {
+	tenant, err := s.rc.HGet(s.ctx, RoomTenantKey, string(roomName)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return tenant, err
+}
+
+func (s *RedisStore) ListTenantRooms(_ context.Context, tenant string) ([]livekit.RoomName, error) {
+	roomTenants, err := s.rc.HGetAll(s.ctx, RoomTenantKey).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var roomNames []livekit.RoomName
+	for roomName, t := range roomTenants {
+		if t == tenant {
+			roomNames = append(roomNames, livekit.RoomName(roomName))
+		}
+	}
+	return roomNames, nil
+}
+
+func (s *RedisStore) StoreRoomExpiry(_ context.Context, roomName livekit.RoomName, expiresAt time.Time) error {
+	return s.rc.HSet(s.ctx, RoomExpiryKey, string(roomName), expiresAt.UnixMilli()).Err()
+}
+
+func (s *RedisStore) LoadRoomExpiry(_ context.Context, roomName livekit.RoomName) (time.Time, error) {
+	expiresAt, err := s.rc.HGet(s.ctx, RoomExpiryKey, string(roomName)).Int64()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(expiresAt), nil
+}
+
+func (s *RedisStore) StoreRoomMaxEgressBitrate(_ context.Context, roomName livekit.RoomName, bps int64) error {
+	return s.rc.HSet(s.ctx, RoomMaxEgressBitrateKey, string(roomName), bps).Err()
+}
+
+func (s *RedisStore) LoadRoomMaxEgressBitrate(_ context.Context, roomName livekit.RoomName) (int64, bool, error) {
+	bps, err := s.rc.HGet(s.ctx, RoomMaxEgressBitrateKey, string(roomName)).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	return bps, true, nil
+}
+
 func redisStoreOne(ctx context.Context, s *RedisStore, key, id string, p proto.Message) error {
 	if id == "" {
 		return errors.New("id is not set")
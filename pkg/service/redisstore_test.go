@@ -16,6 +16,7 @@ package service_test
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -380,6 +381,44 @@ func TestAgentStore(t *testing.T) {
 	require.Equal(t, 0, len(rd))
 }
 
+func TestTenantRoomQuota(t *testing.T) {
+	ctx := context.Background()
+	rs := redisStore(t)
+
+	var wg sync.WaitGroup
+	var reserved atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			roomName := livekit.RoomName(fmt.Sprintf("tenant_room_%d", i))
+			err := rs.ReserveTenantRoom(ctx, roomName, "tenant", 2)
+			if err == nil {
+				reserved.Inc()
+				require.NoError(t, rs.StoreRoom(ctx, &livekit.Room{Name: string(roomName)}, nil))
+			} else {
+				require.ErrorIs(t, err, service.ErrTenantRoomQuotaExceeded)
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, int32(2), reserved.Load())
+
+	roomNames, err := rs.ListTenantRooms(ctx, "tenant")
+	require.NoError(t, err)
+	require.Len(t, roomNames, 2)
+
+	// deleting a room frees up the quota, once
+	require.NoError(t, rs.DeleteRoom(ctx, roomNames[0]))
+	require.NoError(t, rs.ReleaseTenantRoom(ctx, roomNames[0]))
+	require.NoError(t, rs.ReserveTenantRoom(ctx, "tenant_room_new", "tenant", 2))
+	require.ErrorIs(t, rs.ReserveTenantRoom(ctx, "tenant_room_other", "tenant", 2), service.ErrTenantRoomQuotaExceeded)
+
+	// clean up
+	require.NoError(t, rs.ReleaseTenantRoom(ctx, "tenant_room_new"))
+	require.NoError(t, rs.DeleteRoom(ctx, roomNames[1]))
+}
+
 func compareIngressInfo(t *testing.T, expected, v *livekit.IngressInfo) {
 	require.Equal(t, expected.IngressId, v.IngressId)
 	require.Equal(t, expected.StreamKey, v.StreamKey)
@@ -0,0 +1,375 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/pkg/client"
+	"github.com/livekit/psrpc/pkg/info"
+	"github.com/livekit/psrpc/pkg/rand"
+	"github.com/livekit/psrpc/pkg/server"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+// RoomAdmin is a psrpc service routed by room topic to the node hosting the room, like RoomDebug.
+// It carries the admin operations of this server on rooms that protocol does not have an RPC for.
+// Requests reuse protocol and well known messages:
+//
+//   - SetMaxEgressBitrate takes the bitrate in bps, 0 removes the limit
+//   - StartHLS takes the tracks in track_sids of UpdateTrackSettings and returns the playlist path
+//   - MuteAll takes a struct with kinds (track type names) and except (identities),
+//     and returns the sids of muted tracks
+//   - GetParticipantUsage takes an identity, empty for all participants
+//   - ObserveEvents streams room events, as their JSON representation, until the room finishes
+
+const (
+	roomAdminServiceName             = "RoomAdmin"
+	roomAdminSetMaxEgressBitrateName = "SetMaxEgressBitrate"
+	roomAdminStartHLSName            = "StartHLS"
+	roomAdminStopHLSName             = "StopHLS"
+	roomAdminMuteAllName             = "MuteAll"
+	roomAdminSetLockedName           = "SetLocked"
+	roomAdminGetSnapshotName         = "GetSnapshot"
+	roomAdminGetParticipantUsageName = "GetParticipantUsage"
+	roomAdminObserveEventsName       = "ObserveEvents"
+	roomAdminMuteAllKindsField       = "kinds"
+	roomAdminMuteAllExemptionsField  = "except"
+	// room finished is the last event of observers, kill waits for streams to deliver it up to this long
+	roomAdminStreamDrainTimeout = time.Second
+)
+
+var roomAdminUnaryMethods = []string{
+	roomAdminSetMaxEgressBitrateName,
+	roomAdminStartHLSName,
+	roomAdminStopHLSName,
+	roomAdminMuteAllName,
+	roomAdminSetLockedName,
+	roomAdminGetSnapshotName,
+	roomAdminGetParticipantUsageName,
+}
+
+func newRoomAdminServiceDefinition(id string) *info.ServiceDefinition {
+	sd := &info.ServiceDefinition{
+		Name: roomAdminServiceName,
+		ID:   id,
+	}
+	for _, method := range roomAdminUnaryMethods {
+		sd.RegisterMethod(method, false, false, true, true)
+	}
+	sd.RegisterMethod(roomAdminObserveEventsName, false, false, true, true)
+	return sd
+}
+
+type RoomAdminClient interface {
+	SetMaxEgressBitrate(ctx context.Context, room rpc.RoomTopic, req *wrapperspb.Int64Value, opts ...psrpc.RequestOption) (*emptypb.Empty, error)
+	StartHLS(ctx context.Context, room rpc.RoomTopic, req *livekit.UpdateTrackSettings, opts ...psrpc.RequestOption) (*wrapperspb.StringValue, error)
+	StopHLS(ctx context.Context, room rpc.RoomTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*emptypb.Empty, error)
+	MuteAll(ctx context.Context, room rpc.RoomTopic, req *structpb.Struct, opts ...psrpc.RequestOption) (*structpb.ListValue, error)
+	SetLocked(ctx context.Context, room rpc.RoomTopic, req *wrapperspb.BoolValue, opts ...psrpc.RequestOption) (*emptypb.Empty, error)
+	GetSnapshot(ctx context.Context, room rpc.RoomTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*wrapperspb.BytesValue, error)
+	GetParticipantUsage(ctx context.Context, room rpc.RoomTopic, req *wrapperspb.StringValue, opts ...psrpc.RequestOption) (*structpb.ListValue, error)
+	ObserveEvents(ctx context.Context, room rpc.RoomTopic, opts ...psrpc.RequestOption) (psrpc.ClientStream[*emptypb.Empty, *structpb.Struct], error)
+}
+
+type roomAdminClient struct {
+	client *client.RPCClient
+}
+
+func NewRoomAdminClient(params rpc.ClientParams) (RoomAdminClient, error) {
+	rpcClient, err := client.NewRPCClientWithStreams(newRoomAdminServiceDefinition(rand.NewClientID()), params.Bus, params.Options()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roomAdminClient{
+		client: rpcClient,
+	}, nil
+}
+
+func (c *roomAdminClient) SetMaxEgressBitrate(ctx context.Context, room rpc.RoomTopic, req *wrapperspb.Int64Value, opts ...psrpc.RequestOption) (*emptypb.Empty, error) {
+	return client.RequestSingle[*emptypb.Empty](ctx, c.client, roomAdminSetMaxEgressBitrateName, []string{string(room)}, req, opts...)
+}
+
+func (c *roomAdminClient) StartHLS(ctx context.Context, room rpc.RoomTopic, req *livekit.UpdateTrackSettings, opts ...psrpc.RequestOption) (*wrapperspb.StringValue, error) {
+	return client.RequestSingle[*wrapperspb.StringValue](ctx, c.client, roomAdminStartHLSName, []string{string(room)}, req, opts...)
+}
+
+func (c *roomAdminClient) StopHLS(ctx context.Context, room rpc.RoomTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*emptypb.Empty, error) {
+	return client.RequestSingle[*emptypb.Empty](ctx, c.client, roomAdminStopHLSName, []string{string(room)}, req, opts...)
+}
+
+func (c *roomAdminClient) MuteAll(ctx context.Context, room rpc.RoomTopic, req *structpb.Struct, opts ...psrpc.RequestOption) (*structpb.ListValue, error) {
+	return client.RequestSingle[*structpb.ListValue](ctx, c.client, roomAdminMuteAllName, []string{string(room)}, req, opts...)
+}
+
+func (c *roomAdminClient) SetLocked(ctx context.Context, room rpc.RoomTopic, req *wrapperspb.BoolValue, opts ...psrpc.RequestOption) (*emptypb.Empty, error) {
+	return client.RequestSingle[*emptypb.Empty](ctx, c.client, roomAdminSetLockedName, []string{string(room)}, req, opts...)
+}
+
+func (c *roomAdminClient) GetSnapshot(ctx context.Context, room rpc.RoomTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*wrapperspb.BytesValue, error) {
+	return client.RequestSingle[*wrapperspb.BytesValue](ctx, c.client, roomAdminGetSnapshotName, []string{string(room)}, req, opts...)
+}
+
+func (c *roomAdminClient) GetParticipantUsage(ctx context.Context, room rpc.RoomTopic, req *wrapperspb.StringValue, opts ...psrpc.RequestOption) (*structpb.ListValue, error) {
+	return client.RequestSingle[*structpb.ListValue](ctx, c.client, roomAdminGetParticipantUsageName, []string{string(room)}, req, opts...)
+}
+
+func (c *roomAdminClient) ObserveEvents(ctx context.Context, room rpc.RoomTopic, opts ...psrpc.RequestOption) (psrpc.ClientStream[*emptypb.Empty, *structpb.Struct], error) {
+	return client.OpenStream[*emptypb.Empty, *structpb.Struct](ctx, c.client, roomAdminObserveEventsName, []string{string(room)}, opts...)
+}
+
+// RoomAdminHandler performs admin operations on rooms hosted on this node, implemented by RoomManager
+type RoomAdminHandler interface {
+	SetRoomMaxEgressBitrate(ctx context.Context, roomName livekit.RoomName, bps int64) error
+	StartHLS(ctx context.Context, roomName livekit.RoomName, trackIDs []livekit.TrackID) (string, error)
+	StopHLS(ctx context.Context, roomName livekit.RoomName) error
+	MuteAllParticipants(ctx context.Context, roomName livekit.RoomName, kinds []livekit.TrackType, exemptions []livekit.ParticipantIdentity) ([]*livekit.TrackInfo, error)
+	SetRoomLocked(ctx context.Context, roomName livekit.RoomName, locked bool) error
+	SnapshotRoom(ctx context.Context, roomName livekit.RoomName) (*rtc.RoomSnapshot, error)
+	GetParticipantUsage(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) ([]*types.ParticipantUsage, error)
+	ObserveRoomEvents(ctx context.Context, roomName livekit.RoomName) (*utils.EventObserver[*rtc.RoomEvent], <-chan struct{}, error)
+}
+
+// roomAdminServer answers admin requests for a single room
+type roomAdminServer struct {
+	roomName livekit.RoomName
+	handler  RoomAdminHandler
+	rpc      *server.RPCServer
+	streams  sync.WaitGroup
+}
+
+func newRoomAdminServer(roomName livekit.RoomName, handler RoomAdminHandler, bus psrpc.MessageBus, opts ...psrpc.ServerOption) *roomAdminServer {
+	return &roomAdminServer{
+		roomName: roomName,
+		handler:  handler,
+		rpc:      server.NewRPCServer(newRoomAdminServiceDefinition(rand.NewServerID()), bus, opts...),
+	}
+}
+
+func (s *roomAdminServer) RegisterRoomTopic(room rpc.RoomTopic) error {
+	topic := []string{string(room)}
+	return errors.Join(
+		server.RegisterHandler(s.rpc, roomAdminSetMaxEgressBitrateName, topic, s.SetMaxEgressBitrate, nil),
+		server.RegisterHandler(s.rpc, roomAdminStartHLSName, topic, s.StartHLS, nil),
+		server.RegisterHandler(s.rpc, roomAdminStopHLSName, topic, s.StopHLS, nil),
+		server.RegisterHandler(s.rpc, roomAdminMuteAllName, topic, s.MuteAll, nil),
+		server.RegisterHandler(s.rpc, roomAdminSetLockedName, topic, s.SetLocked, nil),
+		server.RegisterHandler(s.rpc, roomAdminGetSnapshotName, topic, s.GetSnapshot, nil),
+		server.RegisterHandler(s.rpc, roomAdminGetParticipantUsageName, topic, s.GetParticipantUsage, nil),
+		server.RegisterStreamHandler(s.rpc, roomAdminObserveEventsName, topic, s.ObserveEvents, nil),
+	)
+}
+
+func (s *roomAdminServer) SetMaxEgressBitrate(ctx context.Context, req *wrapperspb.Int64Value) (*emptypb.Empty, error) {
+	if req.Value < 0 {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "invalid bitrate")
+	}
+	if err := s.handler.SetRoomMaxEgressBitrate(ctx, s.roomName, req.Value); err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *roomAdminServer) StartHLS(ctx context.Context, req *livekit.UpdateTrackSettings) (*wrapperspb.StringValue, error) {
+	if len(req.TrackSids) == 0 {
+		return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "no tracks for HLS output")
+	}
+	trackIDs := make([]livekit.TrackID, 0, len(req.TrackSids))
+	for _, sid := range req.TrackSids {
+		trackIDs = append(trackIDs, livekit.TrackID(sid))
+	}
+
+	playlist, err := s.handler.StartHLS(ctx, s.roomName, trackIDs)
+	if err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return wrapperspb.String(playlist), nil
+}
+
+func (s *roomAdminServer) StopHLS(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := s.handler.StopHLS(ctx, s.roomName); err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *roomAdminServer) MuteAll(ctx context.Context, req *structpb.Struct) (*structpb.ListValue, error) {
+	var kinds []livekit.TrackType
+	for _, v := range req.Fields[roomAdminMuteAllKindsField].GetListValue().GetValues() {
+		kind, ok := livekit.TrackType_value[v.GetStringValue()]
+		if !ok {
+			return nil, psrpc.NewErrorf(psrpc.InvalidArgument, "invalid kind %s", v.GetStringValue())
+		}
+		kinds = append(kinds, livekit.TrackType(kind))
+	}
+	var exemptions []livekit.ParticipantIdentity
+	for _, v := range req.Fields[roomAdminMuteAllExemptionsField].GetListValue().GetValues() {
+		exemptions = append(exemptions, livekit.ParticipantIdentity(v.GetStringValue()))
+	}
+
+	tracks, err := s.handler.MuteAllParticipants(ctx, s.roomName, kinds, exemptions)
+	if err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	mutedTracks := &structpb.ListValue{Values: make([]*structpb.Value, 0, len(tracks))}
+	for _, ti := range tracks {
+		mutedTracks.Values = append(mutedTracks.Values, structpb.NewStringValue(ti.Sid))
+	}
+	return mutedTracks, nil
+}
+
+func (s *roomAdminServer) SetLocked(ctx context.Context, req *wrapperspb.BoolValue) (*emptypb.Empty, error) {
+	if err := s.handler.SetRoomLocked(ctx, s.roomName, req.Value); err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *roomAdminServer) GetSnapshot(ctx context.Context, _ *emptypb.Empty) (*wrapperspb.BytesValue, error) {
+	snapshot, err := s.handler.SnapshotRoom(ctx, s.roomName)
+	if err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	b, err := snapshot.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return wrapperspb.Bytes(b), nil
+}
+
+func (s *roomAdminServer) GetParticipantUsage(ctx context.Context, req *wrapperspb.StringValue) (*structpb.ListValue, error) {
+	usages, err := s.handler.GetParticipantUsage(ctx, s.roomName, livekit.ParticipantIdentity(req.Value))
+	if err != nil {
+		return nil, toAdminRPCError(err)
+	}
+	return jsonToListValue(usages)
+}
+
+func (s *roomAdminServer) ObserveEvents(stream psrpc.ServerStream[*structpb.Struct, *emptypb.Empty]) error {
+	s.streams.Add(1)
+	defer s.streams.Done()
+
+	observer, done, err := s.handler.ObserveRoomEvents(stream.Context(), s.roomName)
+	if err != nil {
+		return toAdminRPCError(err)
+	}
+	defer observer.Stop()
+
+	send := func(event *rtc.RoomEvent) bool {
+		msg, err := jsonToStruct(event)
+		if err != nil || stream.Send(msg) != nil {
+			return false
+		}
+		return event.Event != rtc.RoomEventRoomFinished
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-observer.Events():
+			if !send(event) {
+				return nil
+			}
+		case <-done:
+			// room is closed, deliver what is still queued and end the stream
+			for {
+				select {
+				case event := <-observer.Events():
+					if !send(event) {
+						return nil
+					}
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (s *roomAdminServer) Kill() {
+	// closing the server closes streams, give them a chance to send events of the closing room
+	done := make(chan struct{})
+	go func() {
+		s.streams.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(roomAdminStreamDrainTimeout):
+	}
+	s.rpc.Close(true)
+}
+
+// toAdminRPCError gives errors of admin operations a psrpc code, so that the node sending the request
+// can tell them apart. service errors already have one
+func toAdminRPCError(err error) error {
+	switch {
+	case errors.Is(err, rtc.ErrHLSAlreadyRunning), errors.Is(err, rtc.ErrPacketCaptureRunning):
+		return psrpc.NewError(psrpc.AlreadyExists, err)
+	case errors.Is(err, rtc.ErrHLSNotRunning), errors.Is(err, rtc.ErrPacketCaptureNotRunning):
+		return psrpc.NewError(psrpc.NotFound, err)
+	case errors.Is(err, rtc.ErrHLSUnsupportedTracks), errors.Is(err, rtc.ErrNameExceedsLimits):
+		return psrpc.NewError(psrpc.InvalidArgument, err)
+	default:
+		return err
+	}
+}
+
+// jsonToStruct converts v to a proto struct through its JSON representation
+func jsonToStruct(v any) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &structpb.Struct{}
+	if err = protojson.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// jsonToListValue converts a slice to a proto list through its JSON representation
+func jsonToListValue(v any) (*structpb.ListValue, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &structpb.ListValue{}
+	if string(b) == "null" {
+		return l, nil
+	}
+	if err = protojson.Unmarshal(b, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
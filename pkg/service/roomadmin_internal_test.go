@@ -0,0 +1,148 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/protocol/utils"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+type testAdminHandler struct {
+	RoomAdminHandler
+	ParticipantAdminHandler
+
+	egressBitrates map[livekit.RoomName]int64
+	startHLSErr    error
+	trackNameErr   error
+	observed       chan func(*rtc.RoomEvent)
+	eventsDone     chan struct{}
+}
+
+func (h *testAdminHandler) SetRoomMaxEgressBitrate(_ context.Context, roomName livekit.RoomName, bps int64) error {
+	h.egressBitrates[roomName] = bps
+	return nil
+}
+
+func (h *testAdminHandler) StartHLS(context.Context, livekit.RoomName, []livekit.TrackID) (string, error) {
+	return "", h.startHLSErr
+}
+
+func (h *testAdminHandler) ObserveRoomEvents(context.Context, livekit.RoomName) (*utils.EventObserver[*rtc.RoomEvent], <-chan struct{}, error) {
+	observer, emit := utils.NewEventObserver[*rtc.RoomEvent](func() {})
+	h.observed <- emit
+	return observer, h.eventsDone, nil
+}
+
+func (h *testAdminHandler) GetParticipantICEStats(context.Context, *livekit.RoomParticipantIdentity) ([]*types.ICEStats, error) {
+	return []*types.ICEStats{{
+		Transport: livekit.SignalTarget_SUBSCRIBER,
+		Pairs:     []*types.ICECandidatePairStats{{LocalType: "host", Nominated: true}},
+	}}, nil
+}
+
+func (h *testAdminHandler) UpdateParticipantTrackName(context.Context, *livekit.RoomParticipantIdentity, livekit.TrackID, string) error {
+	return h.trackNameErr
+}
+
+func newTestAdminServers(t *testing.T, bus psrpc.MessageBus, h *testAdminHandler, roomName livekit.RoomName, identity livekit.ParticipantIdentity) {
+	roomServer := newRoomAdminServer(roomName, h, bus)
+	require.NoError(t, roomServer.RegisterRoomTopic(rpc.FormatRoomTopic(roomName)))
+	t.Cleanup(roomServer.Kill)
+
+	participantServer := newParticipantAdminServer(&livekit.RoomParticipantIdentity{Room: string(roomName), Identity: string(identity)}, h, bus)
+	require.NoError(t, participantServer.RegisterParticipantTopic(rpc.FormatParticipantTopic(roomName, identity)))
+	t.Cleanup(participantServer.Kill)
+}
+
+func TestRoomAdminRouting(t *testing.T) {
+	bus := psrpc.NewLocalMessageBus()
+	// each room is hosted on its own node
+	nodeA := &testAdminHandler{egressBitrates: map[livekit.RoomName]int64{}}
+	nodeB := &testAdminHandler{egressBitrates: map[livekit.RoomName]int64{}}
+	newTestAdminServers(t, bus, nodeA, "a", "pa")
+	newTestAdminServers(t, bus, nodeB, "b", "pb")
+
+	roomClient, err := NewRoomAdminClient(rpc.ClientParams{Bus: bus})
+	require.NoError(t, err)
+	participantClient, err := NewParticipantAdminClient(rpc.ClientParams{Bus: bus})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("requests reach the node hosting the room", func(t *testing.T) {
+		_, err := roomClient.SetMaxEgressBitrate(ctx, rpc.FormatRoomTopic("b"), wrapperspb.Int64(1_000_000))
+		require.NoError(t, err)
+		require.Equal(t, map[livekit.RoomName]int64{"b": 1_000_000}, nodeB.egressBitrates)
+		require.Empty(t, nodeA.egressBitrates)
+
+		_, err = roomClient.SetMaxEgressBitrate(ctx, rpc.FormatRoomTopic("c"), wrapperspb.Int64(1), psrpc.WithRequestTimeout(100*time.Millisecond))
+		require.Error(t, err)
+	})
+
+	t.Run("errors keep their code", func(t *testing.T) {
+		nodeA.startHLSErr = rtc.ErrHLSAlreadyRunning
+		_, err := roomClient.StartHLS(ctx, rpc.FormatRoomTopic("a"), &livekit.UpdateTrackSettings{TrackSids: []string{"TR_1"}})
+		require.Equal(t, http.StatusConflict, adminErrorStatus(err))
+
+		nodeA.startHLSErr = ErrHLSNotEnabled
+		_, err = roomClient.StartHLS(ctx, rpc.FormatRoomTopic("a"), &livekit.UpdateTrackSettings{TrackSids: []string{"TR_1"}})
+		require.Equal(t, http.StatusServiceUnavailable, adminErrorStatus(err))
+
+		nodeB.trackNameErr = rtc.ErrNameExceedsLimits
+		_, err = participantClient.UpdateTrackName(ctx, rpc.FormatParticipantTopic("b", "pb"), &livekit.TrackInfo{Sid: "TR_1", Name: "name"})
+		require.Equal(t, http.StatusBadRequest, adminErrorStatus(err))
+	})
+
+	t.Run("participant requests", func(t *testing.T) {
+		stats, err := participantClient.GetICEStats(ctx, rpc.FormatParticipantTopic("a", "pa"), &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Len(t, stats.Values, 1)
+		pairs := stats.Values[0].GetStructValue().Fields["Pairs"].GetListValue().GetValues()
+		require.Len(t, pairs, 1)
+		require.Equal(t, "host", pairs[0].GetStructValue().Fields["LocalType"].GetStringValue())
+	})
+
+	t.Run("events stream until the room finishes", func(t *testing.T) {
+		nodeA.observed = make(chan func(*rtc.RoomEvent), 1)
+		nodeA.eventsDone = make(chan struct{})
+		stream, err := roomClient.ObserveEvents(ctx, rpc.FormatRoomTopic("a"))
+		require.NoError(t, err)
+		defer stream.Close(nil)
+
+		emit := <-nodeA.observed
+		emit(&rtc.RoomEvent{Event: rtc.RoomEventParticipantJoined, Room: "a"})
+		emit(&rtc.RoomEvent{Event: rtc.RoomEventRoomFinished, Room: "a"})
+		close(nodeA.eventsDone)
+
+		var events []string
+		for msg := range stream.Channel() {
+			events = append(events, msg.Fields["event"].GetStringValue())
+		}
+		require.Equal(t, []string{rtc.RoomEventParticipantJoined, rtc.RoomEventRoomFinished}, events)
+	})
+}
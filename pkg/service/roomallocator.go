@@ -17,6 +17,7 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"google.golang.org/protobuf/proto"
@@ -37,6 +38,7 @@ type StandardRoomAllocator struct {
 	router    routing.Router
 	selector  selector.NodeSelector
 	roomStore ObjectStore
+	tenants   *tenantQuotas
 }
 
 func NewRoomAllocator(conf *config.Config, router routing.Router, rs ObjectStore) (RoomAllocator, error) {
@@ -50,6 +52,7 @@ func NewRoomAllocator(conf *config.Config, router routing.Router, rs ObjectStore
 		router:    router,
 		selector:  ns,
 		roomStore: rs,
+		tenants:   newTenantQuotas(conf.Limit, rs),
 	}, nil
 }
 
@@ -92,6 +95,10 @@ func (r *StandardRoomAllocator) CreateRoom(ctx context.Context, req *livekit.Cre
 		logger.Infow("CreateRoom failed 2")
 		return nil, false, err
 	}
+	if codecs, ok := r.config.Room.CodecPreferences[req.ConfigName]; ok && created {
+		// codecs of a room are fixed once participants may have negotiated them
+		rm.EnabledCodecs = codecsFromSpecs(codecs)
+	}
 
 	if req.EmptyTimeout > 0 {
 		rm.EmptyTimeout = req.EmptyTimeout
@@ -127,9 +134,24 @@ func (r *StandardRoomAllocator) CreateRoom(ctx context.Context, req *livekit.Cre
 		internal.SyncStreams = true
 	}
 
+	tenant := GetTenant(ctx)
+	if err = r.tenants.checkCreateRoom(ctx, tenant, livekit.RoomName(rm.Name), created); err != nil {
+		return nil, false, err
+	}
 	if err = r.roomStore.StoreRoom(ctx, rm, internal); err != nil {
+		if created {
+			r.tenants.releaseRoom(ctx, tenant, livekit.RoomName(rm.Name))
+		}
 		return nil, false, err
 	}
+	if created {
+		if err = r.storeRoomExpiry(ctx, rm, req.ConfigName); err != nil {
+			return nil, false, err
+		}
+		if err = r.storeRoomMaxEgressBitrate(ctx, rm, req.ConfigName); err != nil {
+			return nil, false, err
+		}
+	}
 
 	nID := livekit.NodeID(req.NodeId)
         if nID == ""{
@@ -198,12 +220,7 @@ func applyDefaultRoomConfig(room *livekit.Room, internal *livekit.RoomInternal,
 	room.EmptyTimeout = conf.EmptyTimeout
 	room.DepartureTimeout = conf.DepartureTimeout
 	room.MaxParticipants = conf.MaxParticipants
-	for _, codec := range conf.EnabledCodecs {
-		room.EnabledCodecs = append(room.EnabledCodecs, &livekit.Codec{
-			Mime:     codec.Mime,
-			FmtpLine: codec.FmtpLine,
-		})
-	}
+	room.EnabledCodecs = codecsFromSpecs(conf.EnabledCodecs)
 	internal.PlayoutDelay = &livekit.PlayoutDelay{
 		Enabled: conf.PlayoutDelay.Enabled,
 		Min:     uint32(conf.PlayoutDelay.Min),
@@ -212,6 +229,29 @@ func applyDefaultRoomConfig(room *livekit.Room, internal *livekit.RoomInternal,
 	internal.SyncStreams = conf.SyncStreams
 }
 
+func codecsFromSpecs(specs []config.CodecSpec) []*livekit.Codec {
+	codecs := make([]*livekit.Codec, 0, len(specs))
+	for _, codec := range specs {
+		codecs = append(codecs, &livekit.Codec{
+			Mime:     codec.Mime,
+			FmtpLine: codec.FmtpLine,
+		})
+	}
+	return codecs
+}
+
+// isCodecPreference returns whether codecs of a room are one of the configured codec preferences
+func isCodecPreference(conf *config.RoomConfig, codecs []*livekit.Codec) bool {
+	for _, specs := range conf.CodecPreferences {
+		if slices.EqualFunc(specs, codecs, func(spec config.CodecSpec, codec *livekit.Codec) bool {
+			return spec.Mime == codec.Mime && spec.FmtpLine == codec.FmtpLine
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *StandardRoomAllocator) applyNamedRoomConfiguration(req *livekit.CreateRoomRequest) (*livekit.CreateRoomRequest, error) {
 	if req.ConfigName == "" {
 		return req, nil
@@ -252,3 +292,29 @@ func (r *StandardRoomAllocator) applyNamedRoomConfiguration(req *livekit.CreateR
 
 	return clone, nil
 }
+
+// storeRoomExpiry records when a newly created room closes, if its configuration has a max duration
+func (r *StandardRoomAllocator) storeRoomExpiry(ctx context.Context, rm *livekit.Room, configName string) error {
+	maxDuration := r.config.Room.MaxDurationFor(configName)
+	if maxDuration <= 0 {
+		return nil
+	}
+	expiryStore, ok := r.roomStore.(RoomExpiryStore)
+	if !ok {
+		return nil
+	}
+	return expiryStore.StoreRoomExpiry(ctx, livekit.RoomName(rm.Name), time.Unix(rm.CreationTime, 0).Add(maxDuration))
+}
+
+// storeRoomMaxEgressBitrate records the egress bitrate limit of a newly created room, if its configuration overrides the default
+func (r *StandardRoomAllocator) storeRoomMaxEgressBitrate(ctx context.Context, rm *livekit.Room, configName string) error {
+	bps, ok := r.config.Room.MaxEgressBitrateFor(configName)
+	if !ok {
+		return nil
+	}
+	bitrateStore, ok := r.roomStore.(RoomEgressBitrateStore)
+	if !ok {
+		return nil
+	}
+	return bitrateStore.StoreRoomMaxEgressBitrate(ctx, livekit.RoomName(rm.Name), bps)
+}
@@ -77,6 +77,44 @@ func TestCreateRoom(t *testing.T) {
 		_, _, err = ra.CreateRoom(context.Background(), &livekit.CreateRoomRequest{Name: "low-limit-room"})
 		require.ErrorIs(t, err, routing.ErrNodeLimitReached)
 	})
+
+	t.Run("store egress bitrate override of named room configuration", func(t *testing.T) {
+		conf, err := config.NewConfig("", true, nil, nil)
+		require.NoError(t, err)
+		conf.Room.MaxEgressBitrate = 5_000_000
+		conf.Room.MaxEgressBitrates = map[string]int64{"webinar": 20_000_000}
+		conf.Room.RoomConfigurations = map[string]livekit.RoomConfiguration{"webinar": {}}
+
+		node, err := routing.NewLocalNode(conf)
+		require.NoError(t, err)
+
+		store := service.NewLocalStore()
+		router := &routingfakes.FakeRouter{}
+		router.GetNodeForRoomReturns(node, nil)
+		ra, err := service.NewRoomAllocator(conf, router, store)
+		require.NoError(t, err)
+
+		ctx := context.Background()
+		_, _, err = ra.CreateRoom(ctx, &livekit.CreateRoomRequest{Name: "webinar-room", ConfigName: "webinar", NodeId: node.Id})
+		require.NoError(t, err)
+		_, _, err = ra.CreateRoom(ctx, &livekit.CreateRoomRequest{Name: "default-room", NodeId: node.Id})
+		require.NoError(t, err)
+
+		bps, ok, err := store.LoadRoomMaxEgressBitrate(ctx, "webinar-room")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.EqualValues(t, 20_000_000, bps)
+
+		// rooms without an override follow config
+		_, ok, err = store.LoadRoomMaxEgressBitrate(ctx, "default-room")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.NoError(t, store.DeleteRoom(ctx, "webinar-room"))
+		_, ok, err = store.LoadRoomMaxEgressBitrate(ctx, "webinar-room")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
 }
 
 func newTestRoomAllocator(t *testing.T, conf *config.Config, node *livekit.Node) (service.RoomAllocator, *config.Config) {
@@ -0,0 +1,111 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/pkg/client"
+	"github.com/livekit/psrpc/pkg/info"
+	"github.com/livekit/psrpc/pkg/rand"
+	"github.com/livekit/psrpc/pkg/server"
+)
+
+// RoomDebug is a psrpc service routed by room topic to the node hosting the room, like the
+// Room service of protocol. It is defined here as protocol does not have a room debug RPC.
+
+const (
+	roomDebugServiceName          = "RoomDebug"
+	roomDebugGetRoomDebugInfoName = "GetRoomDebugInfo"
+)
+
+func newRoomDebugServiceDefinition(id string) *info.ServiceDefinition {
+	sd := &info.ServiceDefinition{
+		Name: roomDebugServiceName,
+		ID:   id,
+	}
+	sd.RegisterMethod(roomDebugGetRoomDebugInfoName, false, false, true, true)
+	return sd
+}
+
+type RoomDebugClient interface {
+	GetRoomDebugInfo(ctx context.Context, room rpc.RoomTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*structpb.Struct, error)
+}
+
+type roomDebugClient struct {
+	client *client.RPCClient
+}
+
+func NewRoomDebugClient(params rpc.ClientParams) (RoomDebugClient, error) {
+	rpcClient, err := client.NewRPCClient(newRoomDebugServiceDefinition(rand.NewClientID()), params.Bus, params.Options()...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &roomDebugClient{
+		client: rpcClient,
+	}, nil
+}
+
+func (c *roomDebugClient) GetRoomDebugInfo(ctx context.Context, room rpc.RoomTopic, req *emptypb.Empty, opts ...psrpc.RequestOption) (*structpb.Struct, error) {
+	return client.RequestSingle[*structpb.Struct](ctx, c.client, roomDebugGetRoomDebugInfoName, []string{string(room)}, req, opts...)
+}
+
+// roomDebugServer answers debug info requests for a single room
+type roomDebugServer struct {
+	getDebugInfo func() map[string]interface{}
+	rpc          *server.RPCServer
+}
+
+func newRoomDebugServer(getDebugInfo func() map[string]interface{}, bus psrpc.MessageBus, opts ...psrpc.ServerOption) *roomDebugServer {
+	return &roomDebugServer{
+		getDebugInfo: getDebugInfo,
+		rpc:          server.NewRPCServer(newRoomDebugServiceDefinition(rand.NewServerID()), bus, opts...),
+	}
+}
+
+func (s *roomDebugServer) RegisterRoomTopic(room rpc.RoomTopic) error {
+	return server.RegisterHandler(s.rpc, roomDebugGetRoomDebugInfoName, []string{string(room)}, s.GetRoomDebugInfo, nil)
+}
+
+func (s *roomDebugServer) GetRoomDebugInfo(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	return debugInfoToStruct(s.getDebugInfo())
+}
+
+func (s *roomDebugServer) Kill() {
+	s.rpc.Close(true)
+}
+
+// debugInfoToStruct converts debug info to a proto struct through JSON, debug info holds
+// values of many types which are not all accepted by structpb.NewStruct
+func debugInfoToStruct(debugInfo map[string]interface{}) (*structpb.Struct, error) {
+	b, err := json.Marshal(debugInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &structpb.Struct{}
+	if err = protojson.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
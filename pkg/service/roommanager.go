@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -32,6 +34,7 @@ import (
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/protocol/tracer"
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/utils/guid"
 	"github.com/livekit/protocol/utils/must"
@@ -39,11 +42,15 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/clientconfiguration"
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/hls"
+	"github.com/livekit/livekit-server/pkg/pcap"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/tracing"
 	"github.com/livekit/livekit-server/version"
 )
 
@@ -51,6 +58,7 @@ const (
 	roomPurgeSeconds     = 24 * 60 * 60
 	tokenRefreshInterval = 5 * time.Minute
 	tokenDefaultTTL      = 10 * time.Minute
+	hlsSessionPrefix     = "HLS_"
 )
 
 var affinityEpoch = time.Date(2000, 0, 0, 0, 0, 0, 0, time.UTC)
@@ -81,13 +89,25 @@ type RoomManager struct {
 	bus               psrpc.MessageBus
 
 	rooms map[livekit.RoomName]*rtc.Room
-
-	roomServers        utils.MultitonService[rpc.RoomTopic]
-	participantServers utils.MultitonService[rpc.ParticipantTopic]
+	// tenant of rooms scoped to a tenant
+	roomTenants map[livekit.RoomName]string
+	// egress bitrate limit of rooms whose room configuration overrides config.Room.MaxEgressBitrate
+	roomMaxEgressBitrates map[livekit.RoomName]int64
+
+	roomServers             utils.MultitonService[rpc.RoomTopic]
+	roomDebugServers        utils.MultitonService[rpc.RoomTopic]
+	roomAdminServers        utils.MultitonService[rpc.RoomTopic]
+	participantServers      utils.MultitonService[rpc.ParticipantTopic]
+	publishedTrackServers   utils.MultitonService[rpc.ParticipantTopic]
+	participantAdminServers utils.MultitonService[rpc.ParticipantTopic]
 
 	iceConfigCache *sutils.IceConfigCache[iceConfigCacheKey]
 
 	forwardStats *sfu.ForwardStats
+
+	drain nodeDrain
+
+	admissionController rtc.AdmissionController
 }
 
 func NewLocalRoomManager(
@@ -126,7 +146,9 @@ func NewLocalRoomManager(
 		bus:               bus,
 		forwardStats:      forwardStats,
 
-		rooms: make(map[livekit.RoomName]*rtc.Room),
+		rooms:                 make(map[livekit.RoomName]*rtc.Room),
+		roomTenants:           make(map[livekit.RoomName]string),
+		roomMaxEgressBitrates: make(map[livekit.RoomName]int64),
 
 		iceConfigCache: sutils.NewIceConfigCache[iceConfigCacheKey](0),
 
@@ -152,8 +174,16 @@ func (r *RoomManager) deleteRoom(ctx context.Context, roomName livekit.RoomName)
 	logger.Infow("deleting room state", "room", roomName)
 	r.lock.Lock()
 	delete(r.rooms, roomName)
+	tenant, scoped := r.roomTenants[roomName]
+	delete(r.roomTenants, roomName)
+	delete(r.roomMaxEgressBitrates, roomName)
 	r.lock.Unlock()
 
+	if scoped {
+		prometheus.TenantRoomEnded(tenant)
+		r.shareTenantEgressBitrate(tenant)
+	}
+
 	var err, err2 error
 	wg := sync.WaitGroup{}
 	wg.Add(2)
@@ -206,6 +236,30 @@ func (r *RoomManager) CloseIdleRooms() {
 	}
 }
 
+// SampleParticipantResources samples resource usage of participants on this node and exports the hottest ones
+func (r *RoomManager) SampleParticipantResources() {
+	r.lock.RLock()
+	rooms := maps.Values(r.rooms)
+	r.lock.RUnlock()
+
+	goroutines := rtc.CountParticipantGoroutines()
+	var samples []prometheus.ParticipantResources
+	for _, room := range rooms {
+		for _, p := range room.GetParticipants() {
+			usage := p.SampleResourceUsage(goroutines[p.ID()])
+			samples = append(samples, prometheus.ParticipantResources{
+				Room:          room.Name(),
+				ParticipantID: usage.ParticipantID,
+				Identity:      usage.Identity,
+				Goroutines:    usage.Goroutines,
+				QueuedOps:     usage.QueuedOps,
+				PacketsPerSec: usage.PacketsPerSec,
+			})
+		}
+	}
+	prometheus.RecordHottestParticipants(samples, r.config.Prometheus.HottestParticipants)
+}
+
 func (r *RoomManager) HasParticipants() bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
@@ -229,7 +283,11 @@ func (r *RoomManager) Stop() {
 	}
 
 	r.roomServers.Kill()
+	r.roomDebugServers.Kill()
+	r.roomAdminServers.Kill()
 	r.participantServers.Kill()
+	r.publishedTrackServers.Kill()
+	r.participantAdminServers.Kill()
 
 	if r.rtcConfig != nil {
 		if r.rtcConfig.UDPMux != nil {
@@ -238,6 +296,9 @@ func (r *RoomManager) Stop() {
 		if r.rtcConfig.TCPMuxListener != nil {
 			_ = r.rtcConfig.TCPMuxListener.Close()
 		}
+		if r.rtcConfig.ICESocketPool != nil {
+			_ = r.rtcConfig.ICESocketPool.Close()
+		}
 	}
 
 	r.iceConfigCache.Stop()
@@ -254,9 +315,27 @@ func (r *RoomManager) StartSession(
 	pi routing.ParticipantInit,
 	requestSource routing.MessageSource,
 	responseSink routing.MessageSink,
-) error {
+) (err error) {
 	sessionStartTime := time.Now()
 
+	ctx, span := tracer.Start(tracing.FromIncomingContext(ctx), "RoomManager.StartSession",
+		tracing.Attr("room", string(roomName)),
+		tracing.Attr("participant", string(pi.Identity)),
+		tracing.Attr("nodeID", r.currentNode.Id),
+		tracing.Attr("reconnect", pi.Reconnect),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	if pi.Identity != "" && !pi.Reconnect && r.IsDraining() {
+		// full reconnect gets the participant to a node that is not draining
+		logger.Infow("rejecting participant, node is draining", "room", roomName, "participant", pi.Identity)
+		sendLeaveToReconnect(responseSink, types.ProtocolVersion(pi.Client.Protocol), livekit.DisconnectReason_SERVER_SHUTDOWN)
+		return ErrNodeDraining
+	}
+
 	room, err := r.getOrCreateRoom(ctx, roomName)
 	if err != nil {
 		return err
@@ -293,24 +372,7 @@ func (r *RoomManager) StartSession(
 					"reason", pi.ReconnectReason,
 				)
 
-				var leave *livekit.LeaveRequest
-				pv := types.ProtocolVersion(pi.Client.Protocol)
-				if pv.SupportsRegionsInLeaveRequest() {
-					leave = &livekit.LeaveRequest{
-						Reason: livekit.DisconnectReason_STATE_MISMATCH,
-						Action: livekit.LeaveRequest_RECONNECT,
-					}
-				} else {
-					leave = &livekit.LeaveRequest{
-						CanReconnect: true,
-						Reason:       livekit.DisconnectReason_STATE_MISMATCH,
-					}
-				}
-				_ = responseSink.WriteMessage(&livekit.SignalResponse{
-					Message: &livekit.SignalResponse_Leave{
-						Leave: leave,
-					},
-				})
+				sendLeaveToReconnect(responseSink, types.ProtocolVersion(pi.Client.Protocol), livekit.DisconnectReason_STATE_MISMATCH)
 				return errors.New("could not restart closed participant")
 			}
 
@@ -329,6 +391,7 @@ func (r *RoomManager) StartSession(
 					apiKey,
 					participant,
 					iceConfig.PreferenceSubscriber == livekit.ICECandidateType_ICT_TLS,
+					pi.ICEServers,
 				),
 				pi.ReconnectReason,
 			); err != nil {
@@ -336,7 +399,9 @@ func (r *RoomManager) StartSession(
 				return err
 			}
 			r.telemetry.ParticipantResumed(ctx, room.ToProto(), participant.ToProto(), livekit.NodeID(r.currentNode.Id), pi.ReconnectReason)
-			go r.rtcSessionWorker(room, participant, requestSource)
+			go rtc.DoWithParticipantLabel(context.Background(), participant.ID(), func(context.Context) {
+				r.rtcSessionWorker(room, participant, requestSource)
+			})
 			return nil
 		}
 
@@ -347,24 +412,7 @@ func (r *RoomManager) StartSession(
 		logger.Infow("New participant - reconect")
 		// send leave request if participant is trying to reconnect without keep subscribe state
 		// but missing from the room
-		var leave *livekit.LeaveRequest
-		pv := types.ProtocolVersion(pi.Client.Protocol)
-		if pv.SupportsRegionsInLeaveRequest() {
-			leave = &livekit.LeaveRequest{
-				Reason: livekit.DisconnectReason_STATE_MISMATCH,
-				Action: livekit.LeaveRequest_RECONNECT,
-			}
-		} else {
-			leave = &livekit.LeaveRequest{
-				CanReconnect: true,
-				Reason:       livekit.DisconnectReason_STATE_MISMATCH,
-			}
-		}
-		_ = responseSink.WriteMessage(&livekit.SignalResponse{
-			Message: &livekit.SignalResponse_Leave{
-				Leave: leave,
-			},
-		})
+		sendLeaveToReconnect(responseSink, types.ProtocolVersion(pi.Client.Protocol), livekit.DisconnectReason_STATE_MISMATCH)
 		return errors.New("could not restart participant")
 	}
 
@@ -383,11 +431,26 @@ func (r *RoomManager) StartSession(
 
 	pv := types.ProtocolVersion(pi.Client.Protocol)
 	rtcConf := *r.rtcConfig
+	if pi.Grants != nil && pi.Grants.Video != nil {
+		if headerExtensions, ok := r.config.Room.HeaderExtensions[pi.Grants.Video.RoomConfiguration]; ok {
+			// validated at startup
+			if conf, err := r.rtcConfig.WithRTPHeaderExtensions(headerExtensions); err == nil {
+				rtcConf = *conf
+			}
+		}
+	}
 	rtcConf.SetBufferFactory(room.GetBufferFactory())
 	if pi.DisableICELite {
 		rtcConf.SettingEngine.SetLite(false)
 	}
 	sid := livekit.ParticipantID(guid.New(utils.ParticipantPrefix))
+	// participant of a restored room takes over its previous session
+	restore := room.TakePhantom(pi.Identity)
+	if restore != nil {
+		sid = livekit.ParticipantID(restore.Info.Sid)
+	}
+	// goroutines started while setting up the session are accounted to the participant
+	defer rtc.LabelParticipantGoroutine(ctx, sid)()
 	pLogger := rtc.LoggerWithParticipant(
 		rtc.LoggerWithRoom(logger.GetLogger(), room.Name(), room.ID()),
 		pi.Identity,
@@ -427,6 +490,7 @@ func (r *RoomManager) StartSession(
 		VideoConfig:             r.config.Video,
 		LimitConfig:             r.config.Limit,
 		ProtocolVersion:         pv,
+		ClientCapabilities:      types.ParseClientCapabilities(pi.ClientCapabilities),
 		SessionStartTime:        sessionStartTime,
 		Telemetry:               r.telemetry,
 		Trailer:                 room.Trailer(),
@@ -434,6 +498,7 @@ func (r *RoomManager) StartSession(
 		CongestionControlConfig: r.config.RTC.CongestionControl,
 		PublishEnabledCodecs:    protoRoom.EnabledCodecs,
 		SubscribeEnabledCodecs:  protoRoom.EnabledCodecs,
+		CodecPreferenceOrder:    isCodecPreference(&r.config.Room, protoRoom.EnabledCodecs),
 		Grants:                  pi.Grants,
 		Logger:                  pLogger,
 		ClientConf:              clientConf,
@@ -441,7 +506,9 @@ func (r *RoomManager) StartSession(
 		Region:                  pi.Region,
 		AdaptiveStream:          pi.AdaptiveStream,
 		AllowTCPFallback:        allowFallback,
+		ICEConfig:               r.iceConfigCache.Get(iceConfigCacheKey{roomName, pi.Identity}),
 		TURNSEnabled:            r.config.IsTURNSEnabled(),
+		GetPermissionGroups:     room.GetPermissionGroups,
 		GetParticipantInfo: func(pID livekit.ParticipantID) *livekit.ParticipantInfo {
 			if p := room.GetParticipantByID(pID); p != nil {
 				return p.ToProto()
@@ -457,9 +524,12 @@ func (r *RoomManager) StartSession(
 		SubscriberAllowPause:         subscriberAllowPause,
 		SubscriptionLimitAudio:       r.config.Limit.SubscriptionLimitAudio,
 		SubscriptionLimitVideo:       r.config.Limit.SubscriptionLimitVideo,
+		MaxUplinkBitrate:             r.config.Limit.MaxUplinkBitrate,
 		PlayoutDelay:                 roomInternal.GetPlayoutDelay(),
 		SyncStreams:                  roomInternal.GetSyncStreams(),
 		ForwardStats:                 r.forwardStats,
+		Restore:                      restore,
+		TraceContext:                 ctx,
 	})
 	if err != nil {
 		return err
@@ -470,15 +540,24 @@ func (r *RoomManager) StartSession(
 	opts := rtc.ParticipantOptions{
 		AutoSubscribe: pi.AutoSubscribe,
 	}
-	iceServers := r.iceServersForParticipant(apiKey, participant, iceConfig.PreferenceSubscriber == livekit.ICECandidateType_ICT_TLS)
-	if err = room.Join(participant, requestSource, &opts, iceServers); err != nil {
+	iceServers := r.iceServersForParticipant(apiKey, participant, iceConfig.PreferenceSubscriber == livekit.ICECandidateType_ICT_TLS, pi.ICEServers)
+	if err = room.Join(ctx, participant, requestSource, &opts, iceServers); err != nil {
 		pLogger.Errorw("could not join room", err)
+		var rateLimitedErr *rtc.JoinRateLimitedError
+		if errors.As(err, &rateLimitedErr) {
+			// sent ahead of leave, lets the signal node tell the client when to retry
+			_ = responseSink.WriteMessage(&livekit.SignalResponse{
+				Message: &livekit.SignalResponse_ErrorResponse{
+					ErrorResponse: rateLimitedErr.ToErrorResponse(),
+				},
+			})
+		}
 		_ = participant.Close(true, types.ParticipantCloseReasonJoinFailed, false)
 		return err
 	}
 
 	participantTopic := rpc.FormatParticipantTopic(roomName, participant.Identity())
-	participantServer := must.Get(rpc.NewTypedParticipantServer(r, r.bus))
+	participantServer := must.Get(rpc.NewTypedParticipantServer(r, r.bus, psrpc.WithServerRPCInterceptors(tracing.PSRPCServerInterceptor())))
 	killParticipantServer := r.participantServers.Replace(participantTopic, participantServer)
 	if err := participantServer.RegisterAllParticipantTopics(participantTopic); err != nil {
 		killParticipantServer()
@@ -486,6 +565,30 @@ func (r *RoomManager) StartSession(
 		_ = participant.Close(true, types.ParticipantCloseReasonMessageBusFailed, false)
 		return err
 	}
+	publishedTrackServer := newPublishedTrackServer(participant, r.bus, psrpc.WithServerRPCInterceptors(tracing.PSRPCServerInterceptor()))
+	killPublishedTrackServer := r.publishedTrackServers.Replace(participantTopic, publishedTrackServer)
+	if err := publishedTrackServer.RegisterParticipantTopic(participantTopic); err != nil {
+		killPublishedTrackServer()
+		killParticipantServer()
+		pLogger.Errorw("could not join register participant topic", err)
+		_ = participant.Close(true, types.ParticipantCloseReasonMessageBusFailed, false)
+		return err
+	}
+	participantAdminServer := newParticipantAdminServer(
+		&livekit.RoomParticipantIdentity{Room: string(roomName), Identity: string(participant.Identity())},
+		r,
+		r.bus,
+		psrpc.WithServerRPCInterceptors(tracing.PSRPCServerInterceptor()),
+	)
+	killParticipantAdminServer := r.participantAdminServers.Replace(participantTopic, participantAdminServer)
+	if err := participantAdminServer.RegisterParticipantTopic(participantTopic); err != nil {
+		killParticipantAdminServer()
+		killPublishedTrackServer()
+		killParticipantServer()
+		pLogger.Errorw("could not join register participant topic", err)
+		_ = participant.Close(true, types.ParticipantCloseReasonMessageBusFailed, false)
+		return err
+	}
 
 	if err = r.roomStore.StoreParticipant(ctx, roomName, participant.ToProto()); err != nil {
 		pLogger.Errorw("could not store participant", err)
@@ -505,8 +608,18 @@ func (r *RoomManager) StartSession(
 
 	clientMeta := &livekit.AnalyticsClientMeta{Region: r.currentNode.Region, Node: r.currentNode.Id}
 	r.telemetry.ParticipantJoined(ctx, protoRoom, participant.ToProto(), pi.Client, clientMeta, true)
+	tenant := r.roomTenant(roomName)
+	if tenant != "" {
+		prometheus.AddTenantParticipant(tenant)
+	}
 	participant.OnClose(func(p types.LocalParticipant) {
 		killParticipantServer()
+		killPublishedTrackServer()
+		killParticipantAdminServer()
+
+		if tenant != "" {
+			prometheus.SubTenantParticipant(tenant)
+		}
 
 		if err := r.roomStore.DeleteParticipant(ctx, roomName, p.Identity()); err != nil {
 			pLogger.Errorw("could not delete participant", err)
@@ -527,10 +640,32 @@ func (r *RoomManager) StartSession(
 		r.iceConfigCache.Put(iceConfigCacheKey{roomName, participant.Identity()}, iceConfig)
 	})
 
-	go r.rtcSessionWorker(room, participant, requestSource)
+	go rtc.DoWithParticipantLabel(context.Background(), participant.ID(), func(context.Context) {
+		r.rtcSessionWorker(room, participant, requestSource)
+	})
 	return nil
 }
 
+func sendLeaveToReconnect(responseSink routing.MessageSink, pv types.ProtocolVersion, reason livekit.DisconnectReason) {
+	var leave *livekit.LeaveRequest
+	if pv.SupportsRegionsInLeaveRequest() {
+		leave = &livekit.LeaveRequest{
+			Reason: reason,
+			Action: livekit.LeaveRequest_RECONNECT,
+		}
+	} else {
+		leave = &livekit.LeaveRequest{
+			CanReconnect: true,
+			Reason:       reason,
+		}
+	}
+	_ = responseSink.WriteMessage(&livekit.SignalResponse{
+		Message: &livekit.SignalResponse_Leave{
+			Leave: leave,
+		},
+	})
+}
+
 // create the actual room object, to be used on RTC node
 func (r *RoomManager) getOrCreateRoom(ctx context.Context, roomName livekit.RoomName) (*rtc.Room, error) {
 	r.lock.RLock()
@@ -546,6 +681,18 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, roomName livekit.Room
 	if err != nil {
 		return nil, err
 	}
+	tenant, err := r.loadRoomTenant(ctx, roomName)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt, err := r.loadRoomExpiry(ctx, ri)
+	if err != nil {
+		return nil, err
+	}
+	maxEgressBitrate, hasMaxEgressBitrate, err := r.loadRoomMaxEgressBitrate(ctx, roomName)
+	if err != nil {
+		return nil, err
+	}
 
 	r.lock.Lock()
 
@@ -563,18 +710,44 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, roomName livekit.Room
 
 	// construct ice servers
 	newRoom := rtc.NewRoom(ri, internal, *r.rtcConfig, r.config.Room, &r.config.Audio, r.serverInfo, r.telemetry, r.agentClient, r.agentStore, r.egressLauncher)
+	if r.admissionController != nil {
+		newRoom.SetAdmissionController(r.admissionController)
+	}
+	newRoom.SetExpiry(expiresAt)
+	if hasMaxEgressBitrate {
+		newRoom.SetMaxEgressBitrate(maxEgressBitrate)
+	}
 
 	roomTopic := rpc.FormatRoomTopic(roomName)
-	roomServer := must.Get(rpc.NewTypedRoomServer(r, r.bus))
+	roomServer := must.Get(rpc.NewTypedRoomServer(r, r.bus, psrpc.WithServerRPCInterceptors(tracing.PSRPCServerInterceptor())))
 	killRoomServer := r.roomServers.Replace(roomTopic, roomServer)
 	if err := roomServer.RegisterAllRoomTopics(roomTopic); err != nil {
 		killRoomServer()
 		r.lock.Unlock()
 		return nil, err
 	}
+	roomDebugServer := newRoomDebugServer(newRoom.DebugInfo, r.bus, psrpc.WithServerRPCInterceptors(tracing.PSRPCServerInterceptor()))
+	killRoomDebugServer := r.roomDebugServers.Replace(roomTopic, roomDebugServer)
+	if err := roomDebugServer.RegisterRoomTopic(roomTopic); err != nil {
+		killRoomDebugServer()
+		killRoomServer()
+		r.lock.Unlock()
+		return nil, err
+	}
+	roomAdminServer := newRoomAdminServer(roomName, r, r.bus, psrpc.WithServerRPCInterceptors(tracing.PSRPCServerInterceptor()))
+	killRoomAdminServer := r.roomAdminServers.Replace(roomTopic, roomAdminServer)
+	if err := roomAdminServer.RegisterRoomTopic(roomTopic); err != nil {
+		killRoomAdminServer()
+		killRoomDebugServer()
+		killRoomServer()
+		r.lock.Unlock()
+		return nil, err
+	}
 
 	newRoom.OnClose(func() {
 		killRoomServer()
+		killRoomDebugServer()
+		killRoomAdminServer()
 
 		roomInfo := newRoom.ToProto()
 		r.telemetry.RoomEnded(ctx, roomInfo)
@@ -601,6 +774,12 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, roomName livekit.Room
 	})
 
 	r.rooms[roomName] = newRoom
+	if tenant != "" {
+		r.roomTenants[roomName] = tenant
+	}
+	if hasMaxEgressBitrate {
+		r.roomMaxEgressBitrates[roomName] = maxEgressBitrate
+	}
 
 	r.lock.Unlock()
 
@@ -608,10 +787,99 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, roomName livekit.Room
 
 	r.telemetry.RoomStarted(ctx, newRoom.ToProto())
 	prometheus.RoomStarted()
+	if tenant != "" {
+		prometheus.TenantRoomStarted(tenant)
+		r.shareTenantEgressBitrate(tenant)
+	}
 
 	return newRoom, nil
 }
 
+func (r *RoomManager) loadRoomTenant(ctx context.Context, roomName livekit.RoomName) (string, error) {
+	quotaStore, ok := r.roomStore.(QuotaStore)
+	if !ok {
+		return "", nil
+	}
+	return quotaStore.LoadRoomTenant(ctx, roomName)
+}
+
+// loadRoomMaxEgressBitrate returns the egress bitrate limit of a room whose room configuration overrides the default
+func (r *RoomManager) loadRoomMaxEgressBitrate(ctx context.Context, roomName livekit.RoomName) (int64, bool, error) {
+	bitrateStore, ok := r.roomStore.(RoomEgressBitrateStore)
+	if !ok {
+		return 0, false, nil
+	}
+	return bitrateStore.LoadRoomMaxEgressBitrate(ctx, roomName)
+}
+
+// loadRoomExpiry returns when the room closes, rooms created without a stored expiry fall back to the default max duration
+func (r *RoomManager) loadRoomExpiry(ctx context.Context, ri *livekit.Room) (time.Time, error) {
+	if expiryStore, ok := r.roomStore.(RoomExpiryStore); ok {
+		expiresAt, err := expiryStore.LoadRoomExpiry(ctx, livekit.RoomName(ri.Name))
+		if err != nil || !expiresAt.IsZero() {
+			return expiresAt, err
+		}
+	}
+	if r.config.Room.MaxDuration <= 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(ri.CreationTime, 0).Add(r.config.Room.MaxDuration), nil
+}
+
+func (r *RoomManager) roomTenant(roomName livekit.RoomName) string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.roomTenants[roomName]
+}
+
+// shareTenantEgressBitrate splits the egress bitrate quota of a tenant evenly among its rooms on this node
+func (r *RoomManager) shareTenantEgressBitrate(tenant string) {
+	bps := r.config.Limit.Tenants.QuotaFor(tenant).MaxEgressBitrate
+	if bps <= 0 {
+		return
+	}
+
+	var rooms []*rtc.Room
+	var roomMaxes []int64
+	r.lock.RLock()
+	for roomName, t := range r.roomTenants {
+		if room := r.rooms[roomName]; t == tenant && room != nil {
+			rooms = append(rooms, room)
+			roomMax, ok := r.roomMaxEgressBitrates[roomName]
+			if !ok {
+				roomMax = r.config.Room.MaxEgressBitrate
+			}
+			roomMaxes = append(roomMaxes, roomMax)
+		}
+	}
+	r.lock.RUnlock()
+	if len(rooms) == 0 {
+		return
+	}
+
+	share := bps / int64(len(rooms))
+	for i, room := range rooms {
+		if roomMax := roomMaxes[i]; roomMax > 0 && roomMax < share {
+			room.SetMaxEgressBitrate(roomMax)
+		} else {
+			room.SetMaxEgressBitrate(share)
+		}
+	}
+}
+
+// SetAdmissionController plugs in custom admission logic for participants joining rooms on this node,
+// nil admits all participants passing static checks.
+func (r *RoomManager) SetAdmissionController(ac rtc.AdmissionController) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.admissionController = ac
+	for _, room := range r.rooms {
+		room.SetAdmissionController(ac)
+	}
+}
+
 // manages an RTC session for a participant, runs on the RTC node
 func (r *RoomManager) rtcSessionWorker(room *rtc.Room, participant types.LocalParticipant, requestSource routing.MessageSource) {
 	pLogger := rtc.LoggerWithParticipant(
@@ -694,6 +962,346 @@ func (r *RoomManager) RemoveParticipant(ctx context.Context, req *livekit.RoomPa
 	return &livekit.RemoveParticipantResponse{}, nil
 }
 
+// GetParticipantICEStats returns ICE candidate pair stats of a participant connected to this node
+func (r *RoomManager) GetParticipantICEStats(ctx context.Context, req *livekit.RoomParticipantIdentity) ([]*types.ICEStats, error) {
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return participant.GetICEStats(), nil
+}
+
+// ObserveRoomEvents subscribes to activity of a room hosted on this node,
+// the returned channel is closed after the last event of the room
+func (r *RoomManager) ObserveRoomEvents(ctx context.Context, roomName livekit.RoomName) (*utils.EventObserver[*rtc.RoomEvent], <-chan struct{}, error) {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, nil, ErrRoomNotFound
+	}
+
+	observer, done := room.ObserveEvents()
+	return observer, done, nil
+}
+
+// setPublishedTrackMaxQuality caps layers a publisher sends for a simulcast track
+func setPublishedTrackMaxQuality(
+	participant types.LocalParticipant,
+	trackID livekit.TrackID,
+	quality livekit.VideoQuality,
+) (*livekit.TrackInfo, error) {
+	track, ok := participant.GetPublishedTrack(trackID).(types.LocalMediaTrack)
+	if !ok {
+		return nil, ErrTrackNotFound
+	}
+
+	participant.GetLogger().Infow("setting max published quality", "trackID", trackID, "quality", quality)
+	track.SetMaxPublishedQuality(quality)
+	return track.ToProto(), nil
+}
+
+// setPublishedTrackMinQuality keeps publisher sending layers up to quality even without subscribers, OFF removes the override
+func setPublishedTrackMinQuality(
+	participant types.LocalParticipant,
+	trackID livekit.TrackID,
+	quality livekit.VideoQuality,
+) (*livekit.TrackInfo, error) {
+	track, ok := participant.GetPublishedTrack(trackID).(types.LocalMediaTrack)
+	if !ok {
+		return nil, ErrTrackNotFound
+	}
+
+	participant.GetLogger().Infow("setting min published quality", "trackID", trackID, "quality", quality)
+	track.SetMinPublishedQuality(quality)
+	return track.ToProto(), nil
+}
+
+// UpdateParticipantTrackName renames a track of the participant, including tracks that are yet to be published
+func (r *RoomManager) UpdateParticipantTrackName(
+	ctx context.Context,
+	req *livekit.RoomParticipantIdentity,
+	trackID livekit.TrackID,
+	name string,
+) error {
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	participant.GetLogger().Infow("updating track name", "trackID", trackID, "name", name)
+	return participant.UpdateTrackName(trackID, name)
+}
+
+// SetParticipantMaxUplinkBitrate limits total bitrate published by a participant, 0 for no limit
+func (r *RoomManager) SetParticipantMaxUplinkBitrate(ctx context.Context, req *livekit.RoomParticipantIdentity, bps int64) error {
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	participant.SetMaxUplinkBitrate(bps)
+	return nil
+}
+
+// SetRoomMaxEgressBitrate limits aggregate bitrate forwarded to subscribers of a room, 0 for no limit
+func (r *RoomManager) SetRoomMaxEgressBitrate(ctx context.Context, roomName livekit.RoomName, bps int64) error {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return ErrRoomNotFound
+	}
+
+	room.SetMaxEgressBitrate(bps)
+	return nil
+}
+
+// SnapshotRoom captures state of a room hosted on this node, to restore it on another node
+func (r *RoomManager) SnapshotRoom(ctx context.Context, roomName livekit.RoomName) (*rtc.RoomSnapshot, error) {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	return room.Snapshot(), nil
+}
+
+// GetParticipantUsage returns cumulative bandwidth usage of participants of a room hosted on this node,
+// including participants that have left. When identity is given, only sessions of that participant are returned.
+func (r *RoomManager) GetParticipantUsage(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) ([]*types.ParticipantUsage, error) {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	usages := room.ParticipantUsage()
+	if identity == "" {
+		return usages, nil
+	}
+
+	var filtered []*types.ParticipantUsage
+	for _, usage := range usages {
+		if usage.Identity == identity {
+			filtered = append(filtered, usage)
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, ErrParticipantNotFound
+	}
+	return filtered, nil
+}
+
+// RestoreRoom hosts a room captured by SnapshotRoom on this node, e.g. after the node hosting it failed.
+// Participants of the snapshot are kept as phantoms, until their clients reconnect and take over their state.
+func (r *RoomManager) RestoreRoom(ctx context.Context, snapshot *rtc.RoomSnapshot) error {
+	roomName := livekit.RoomName(snapshot.Room.Name)
+	if r.GetRoom(ctx, roomName) != nil {
+		return ErrRoomAlreadyHosted
+	}
+
+	internal := snapshot.Internal
+	if internal == nil {
+		internal = &livekit.RoomInternal{}
+	}
+	if err := r.roomStore.StoreRoom(ctx, snapshot.Room, internal); err != nil {
+		return err
+	}
+	if err := r.router.SetNodeForRoom(ctx, roomName, livekit.NodeID(r.currentNode.Id)); err != nil {
+		return err
+	}
+
+	room, err := r.getOrCreateRoom(ctx, roomName)
+	if err != nil {
+		return err
+	}
+	defer room.Release()
+
+	room.RestorePhantoms(snapshot.Participants)
+	room.Logger.Infow("room restored from snapshot",
+		"snapshotCreatedAt", snapshot.CreatedAt,
+		"participants", len(snapshot.Participants),
+	)
+	return nil
+}
+
+// MuteAllParticipants server mutes published tracks of given kinds in a room hosted on this node,
+// except tracks of exempted participants
+func (r *RoomManager) MuteAllParticipants(
+	ctx context.Context,
+	roomName livekit.RoomName,
+	kinds []livekit.TrackType,
+	exemptions []livekit.ParticipantIdentity,
+) ([]*livekit.TrackInfo, error) {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	return room.MuteAllParticipants(kinds, exemptions), nil
+}
+
+// SetRoomLocked locks a room hosted on this node, rejecting new participants, or unlocks it
+func (r *RoomManager) SetRoomLocked(ctx context.Context, roomName livekit.RoomName, locked bool) error {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return ErrRoomNotFound
+	}
+
+	room.SetLocked(locked)
+	return nil
+}
+
+// GetMediaNode returns the node media of a room is forwarded by. All participants of a room publish to and
+// subscribe from the node hosting it, so that is the node of their media too. Presence of participant
+// with given identity is only checked when the room is hosted on this node.
+func (r *RoomManager) GetMediaNode(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) (*livekit.Node, error) {
+	node, err := r.router.GetNodeForRoom(ctx, roomName)
+	if err != nil {
+		return nil, ErrRoomNotFound
+	}
+	if node.Id != r.currentNode.Id {
+		return node, nil
+	}
+
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if identity != "" && room.GetParticipant(identity) == nil {
+		return nil, ErrParticipantNotFound
+	}
+	return node, nil
+}
+
+// StartLoadTest adds synthetic publishers and subscribers to a room hosted on this node
+func (r *RoomManager) StartLoadTest(ctx context.Context, roomName livekit.RoomName, params rtc.LoadTestParams) (*rtc.LoadTestStatus, error) {
+	conf := r.config.LoadTest
+	if !conf.Enabled {
+		return nil, ErrLoadTestNotEnabled
+	}
+	if params.Publishers < 0 || params.Subscribers < 0 ||
+		params.Publishers > conf.MaxPublishers || params.Subscribers > conf.MaxSubscribers {
+		return nil, ErrLoadTestLimitExceeded
+	}
+
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	return room.StartLoadTest(params)
+}
+
+func (r *RoomManager) StopLoadTest(ctx context.Context, roomName livekit.RoomName) error {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return ErrRoomNotFound
+	}
+
+	return room.StopLoadTest()
+}
+
+func (r *RoomManager) GetLoadTestStatus(ctx context.Context, roomName livekit.RoomName) (*rtc.LoadTestStatus, error) {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	return room.GetLoadTestStatus()
+}
+
+// StartHLS starts HLS output of given tracks, returns path of the playlist relative to hls.output_dir.
+// Each run writes to its own directory, named by room ID and a session ID.
+func (r *RoomManager) StartHLS(ctx context.Context, roomName livekit.RoomName, trackIDs []livekit.TrackID) (string, error) {
+	conf := r.config.HLS
+	if conf.OutputDir == "" {
+		return "", ErrHLSNotEnabled
+	}
+
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return "", ErrRoomNotFound
+	}
+
+	sessionID := guid.New(hlsSessionPrefix)
+	err := room.StartHLS(trackIDs, hls.MuxerParams{
+		Dir:             filepath.Join(conf.OutputDir, string(room.ID()), sessionID),
+		SegmentDuration: conf.SegmentDuration,
+		PartDuration:    conf.PartDuration,
+		PlaylistSize:    conf.PlaylistSize,
+	})
+	if err != nil {
+		return "", err
+	}
+	return path.Join(string(room.ID()), sessionID, hls.PlaylistName), nil
+}
+
+func (r *RoomManager) StopHLS(ctx context.Context, roomName livekit.RoomName) error {
+	room := r.GetRoom(ctx, roomName)
+	if room == nil {
+		return ErrRoomNotFound
+	}
+
+	return room.StopHLS()
+}
+
+// StartPacketCapture captures decrypted RTP/RTCP of a participant into pcapng files, returns path of the first file.
+// Duration is capped to the configured maximum, which is also used when duration is not given.
+func (r *RoomManager) StartPacketCapture(ctx context.Context, req *livekit.RoomParticipantIdentity, duration time.Duration) (string, error) {
+	if r.rtcConfig == nil || r.rtcConfig.PacketCapture.Dir == "" {
+		return "", ErrPacketCaptureNotEnabled
+	}
+	conf := r.rtcConfig.PacketCapture
+
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	if duration <= 0 || duration > conf.MaxDuration {
+		duration = conf.MaxDuration
+	}
+	c, err := participant.StartPacketCapture(pcap.CaptureParams{
+		Dir:         filepath.Join(conf.Dir, string(room.ID())),
+		Name:        fmt.Sprintf("%s-%s", participant.ID(), time.Now().UTC().Format("20060102T150405")),
+		Duration:    duration,
+		MaxFileSize: conf.MaxFileSize,
+		MaxFiles:    conf.MaxFiles,
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.FirstFile(), nil
+}
+
+func (r *RoomManager) StopPacketCapture(ctx context.Context, req *livekit.RoomParticipantIdentity) error {
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return participant.StopPacketCapture()
+}
+
+// SimulateNetworkConditions impairs media between a participant and this node, nil conditions restore a direction
+func (r *RoomManager) SimulateNetworkConditions(ctx context.Context, req *livekit.RoomParticipantIdentity, uplink *netem.Conditions, downlink *netem.Conditions) error {
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	room.SimulateNetworkConditions(participant, uplink, downlink)
+	return nil
+}
+
+func (r *RoomManager) GetNetworkConditions(ctx context.Context, req *livekit.RoomParticipantIdentity) (*netem.Conditions, *netem.Conditions, error) {
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	uplink, downlink := participant.GetNetworkConditions()
+	return uplink, downlink, nil
+}
+
 func (r *RoomManager) MutePublishedTrack(ctx context.Context, req *livekit.MuteRoomTrackRequest) (*livekit.MuteRoomTrackResponse, error) {
 	_, participant, err := r.roomAndParticipantForReq(ctx, req)
 	if err != nil {
@@ -711,7 +1319,7 @@ func (r *RoomManager) MutePublishedTrack(ctx context.Context, req *livekit.MuteR
 }
 
 func (r *RoomManager) UpdateParticipant(ctx context.Context, req *livekit.UpdateParticipantRequest) (*livekit.ParticipantInfo, error) {
-	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	room, participant, err := r.roomAndParticipantForReq(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -734,6 +1342,11 @@ func (r *RoomManager) UpdateParticipant(ctx context.Context, req *livekit.Update
 	if req.Attributes != nil {
 		participant.SetAttributes(req.Attributes)
 	}
+	if _, ok := req.Attributes[rtc.PermissionGroupsAttribute]; ok {
+		if participant.SetPermissionGroups(rtc.ParsePermissionGroups(req.Attributes)) {
+			room.RefreshPermissionGroups(participant)
+		}
+	}
 
 	if req.Permission != nil {
 		participant.SetPermission(req.Permission)
@@ -809,7 +1422,18 @@ func (r *RoomManager) UpdateRoomMetadata(ctx context.Context, req *livekit.Updat
 	return room.ToProto(), nil
 }
 
-func (r *RoomManager) iceServersForParticipant(apiKey string, participant types.LocalParticipant, tlsOnly bool) []*livekit.ICEServer {
+func (r *RoomManager) iceServersForParticipant(
+	apiKey string,
+	participant types.LocalParticipant,
+	tlsOnly bool,
+	tokenICEServers []*livekit.ICEServer,
+) []*livekit.ICEServer {
+	// ICE servers from the access token replace the ones of the server
+	if len(tokenICEServers) > 0 {
+		participant.GetLogger().Debugw("using ICE servers from token", "numICEServers", len(tokenICEServers))
+		return tokenICEServers
+	}
+
 	var iceServers []*livekit.ICEServer
 	rtcConf := r.config.RTC
 
@@ -830,8 +1454,7 @@ func (r *RoomManager) iceServersForParticipant(apiKey string, participant types.
 			urls = append(urls, fmt.Sprintf("turns:%s:443?transport=tcp", r.config.TURN.Domain))
 		}
 		if len(urls) > 0 {
-			username := r.turnAuthHandler.CreateUsername(apiKey, participant.ID())
-			password, err := r.turnAuthHandler.CreatePassword(apiKey, participant.ID())
+			username, password, err := r.turnAuthHandler.CreateCredentials(apiKey, participant.ID())
 			if err != nil {
 				participant.GetLogger().Warnw("could not create turn password", err)
 				hasSTUN = false
@@ -863,6 +1486,13 @@ func (r *RoomManager) iceServersForParticipant(apiKey string, participant types.
 				Username:   s.Username,
 				Credential: s.Credential,
 			}
+			if s.Secret != "" {
+				ttl := s.CredentialTTL
+				if ttl <= 0 {
+					ttl = config.DefaultTURNCredentialTTL
+				}
+				is.Username, is.Credential = createTURNRESTCredentials(s.Secret, ttl, participant.ID())
+			}
 			iceServers = append(iceServers, is)
 		}
 	}
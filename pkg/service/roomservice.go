@@ -18,33 +18,47 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/avast/retry-go/v4"
 	"github.com/pkg/errors"
 	"github.com/twitchtv/twirp"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/livekit/livekit-server/pkg/agent"
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/protocol/egress"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
 )
 
 type RoomService struct {
-	limitConf         config.LimitConfig
-	apiConf           config.APIConfig
-	psrpcConf         rpc.PSRPCConfig
-	router            routing.MessageRouter
-	roomAllocator     RoomAllocator
-	roomStore         ServiceStore
-	agentClient       agent.Client
-	egressLauncher    rtc.EgressLauncher
-	topicFormatter    rpc.TopicFormatter
-	roomClient        rpc.TypedRoomClient
-	participantClient rpc.TypedParticipantClient
+	limitConf              config.LimitConfig
+	apiConf                config.APIConfig
+	psrpcConf              rpc.PSRPCConfig
+	router                 routing.MessageRouter
+	roomAllocator          RoomAllocator
+	roomStore              ServiceStore
+	agentClient            agent.Client
+	egressLauncher         rtc.EgressLauncher
+	topicFormatter         rpc.TopicFormatter
+	roomClient             rpc.TypedRoomClient
+	participantClient      rpc.TypedParticipantClient
+	roomDebugClient        RoomDebugClient
+	publishedTrackClient   PublishedTrackClient
+	roomAdminClient        RoomAdminClient
+	participantAdminClient ParticipantAdminClient
+	tenantQuotas           *tenantQuotas
+	// nil when CreateRoom is not rate limited
+	createRoomRateLimiter *utils.TokenBucket
 }
 
 func NewRoomService(
@@ -59,19 +73,31 @@ func NewRoomService(
 	topicFormatter rpc.TopicFormatter,
 	roomClient rpc.TypedRoomClient,
 	participantClient rpc.TypedParticipantClient,
+	roomDebugClient RoomDebugClient,
+	publishedTrackClient PublishedTrackClient,
+	roomAdminClient RoomAdminClient,
+	participantAdminClient ParticipantAdminClient,
 ) (svc *RoomService, err error) {
 	svc = &RoomService{
-		limitConf:         limitConf,
-		apiConf:           apiConf,
-		psrpcConf:         psrpcConf,
-		router:            router,
-		roomAllocator:     roomAllocator,
-		roomStore:         serviceStore,
-		agentClient:       agentClient,
-		egressLauncher:    egressLauncher,
-		topicFormatter:    topicFormatter,
-		roomClient:        roomClient,
-		participantClient: participantClient,
+		limitConf:              limitConf,
+		apiConf:                apiConf,
+		psrpcConf:              psrpcConf,
+		router:                 router,
+		roomAllocator:          roomAllocator,
+		roomStore:              serviceStore,
+		agentClient:            agentClient,
+		egressLauncher:         egressLauncher,
+		topicFormatter:         topicFormatter,
+		roomClient:             roomClient,
+		participantClient:      participantClient,
+		roomDebugClient:        roomDebugClient,
+		publishedTrackClient:   publishedTrackClient,
+		roomAdminClient:        roomAdminClient,
+		participantAdminClient: participantAdminClient,
+		tenantQuotas:           newTenantQuotas(limitConf, serviceStore),
+	}
+	if rl := limitConf.CreateRoomRateLimit; rl.Rate > 0 {
+		svc.createRoomRateLimiter = utils.NewTokenBucket(rl.Rate, rl.Burst)
 	}
 	return
 }
@@ -90,6 +116,10 @@ func (s *RoomService) CreateRoom(ctx context.Context, req *livekit.CreateRoomReq
 		return nil, fmt.Errorf("%w: max length %d", ErrRoomNameExceedsLimits, s.limitConf.MaxRoomNameLength)
 	}
 
+	if err := s.waitCreateRoomRate(ctx); err != nil {
+		return nil, err
+	}
+
 	rm, created, err := s.roomAllocator.CreateRoom(ctx, req)
 	if err != nil {
 		err = errors.Wrap(err, "could not create room")
@@ -121,6 +151,27 @@ func (s *RoomService) CreateRoom(ctx context.Context, req *livekit.CreateRoomReq
 	return rm, nil
 }
 
+// waitCreateRoomRate queues requests above the rate up to max wait, rejects them with a retry_after (seconds) meta otherwise
+func (s *RoomService) waitCreateRoomRate(ctx context.Context) error {
+	if s.createRoomRateLimiter == nil {
+		return nil
+	}
+
+	wait, ok := s.createRoomRateLimiter.Reserve(s.limitConf.CreateRoomRateLimit.MaxWait)
+	if !ok {
+		return twirp.NewError(twirp.ResourceExhausted, ErrCreateRoomRateLimited.Error()).
+			WithMeta("retry_after", retryAfterSeconds(wait))
+	}
+	if wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 func (s *RoomService) ListRooms(ctx context.Context, req *livekit.ListRoomsRequest) (*livekit.ListRoomsResponse, error) {
 	AppendLogFields(ctx, "room", req.Names)
 	err := EnsureListPermission(ctx)
@@ -137,6 +188,9 @@ func (s *RoomService) ListRooms(ctx context.Context, req *livekit.ListRoomsReque
 		// TODO: translate error codes to Twirp
 		return nil, err
 	}
+	if rooms, err = s.tenantQuotas.filterRooms(ctx, rooms); err != nil {
+		return nil, err
+	}
 
 	res := &livekit.ListRoomsResponse{
 		Rooms: rooms,
@@ -154,6 +208,9 @@ func (s *RoomService) DeleteRoom(ctx context.Context, req *livekit.DeleteRoomReq
 	if err != nil {
 		return nil, err
 	}
+	if err = s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	done, err := s.startRoom(ctx, livekit.RoomName(req.Room))
 	if err != nil {
@@ -175,6 +232,9 @@ func (s *RoomService) ListParticipants(ctx context.Context, req *livekit.ListPar
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	participants, err := s.roomStore.ListParticipants(ctx, livekit.RoomName(req.Room))
 	if err != nil {
@@ -187,11 +247,27 @@ func (s *RoomService) ListParticipants(ctx context.Context, req *livekit.ListPar
 	return res, nil
 }
 
+// ListParticipantsPage lists participants of a room a page at a time, filtered and sorted by opts
+func (s *RoomService) ListParticipantsPage(ctx context.Context, roomName livekit.RoomName, opts ParticipantListOptions) (*ParticipantPage, error) {
+	AppendLogFields(ctx, "room", roomName, "pageToken", opts.PageToken)
+	if err := EnsureAdminPermission(ctx, roomName); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, roomName); err != nil {
+		return nil, err
+	}
+
+	return s.roomStore.ListParticipantsPage(ctx, roomName, opts)
+}
+
 func (s *RoomService) GetParticipant(ctx context.Context, req *livekit.RoomParticipantIdentity) (*livekit.ParticipantInfo, error) {
 	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity)
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	participant, err := s.roomStore.LoadParticipant(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity))
 	if err != nil {
@@ -207,6 +283,9 @@ func (s *RoomService) RemoveParticipant(ctx context.Context, req *livekit.RoomPa
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	if _, err := s.roomStore.LoadParticipant(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity)); err == ErrParticipantNotFound {
 		return nil, twirp.NotFoundError("participant not found")
@@ -220,6 +299,9 @@ func (s *RoomService) MutePublishedTrack(ctx context.Context, req *livekit.MuteR
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	return s.participantClient.MutePublishedTrack(ctx, s.topicFormatter.ParticipantTopic(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity)), req)
 }
@@ -242,6 +324,9 @@ func (s *RoomService) UpdateParticipant(ctx context.Context, req *livekit.Update
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	return s.participantClient.UpdateParticipant(ctx, s.topicFormatter.ParticipantTopic(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity)), req)
 }
@@ -256,6 +341,9 @@ func (s *RoomService) UpdateSubscriptions(ctx context.Context, req *livekit.Upda
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	return s.participantClient.UpdateSubscriptions(ctx, s.topicFormatter.ParticipantTopic(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity)), req)
 }
@@ -266,6 +354,9 @@ func (s *RoomService) SendData(ctx context.Context, req *livekit.SendDataRequest
 	if err := EnsureAdminPermission(ctx, roomName); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, roomName); err != nil {
+		return nil, err
+	}
 
 	return s.roomClient.SendData(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
 }
@@ -280,6 +371,9 @@ func (s *RoomService) UpdateRoomMetadata(ctx context.Context, req *livekit.Updat
 	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
 		return nil, twirpAuthError(err)
 	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
 
 	room, _, err := s.roomStore.LoadRoom(ctx, livekit.RoomName(req.Room), false)
 	if err != nil {
@@ -294,6 +388,278 @@ func (s *RoomService) UpdateRoomMetadata(ctx context.Context, req *livekit.Updat
 	return room, nil
 }
 
+// GetRoomDebugInfo returns debug info of a room from the node hosting it.
+// It is not part of the RoomService API of protocol, the request goes to the room over a psrpc service of this server.
+func (s *RoomService) GetRoomDebugInfo(ctx context.Context, roomName livekit.RoomName) (*structpb.Struct, error) {
+	AppendLogFields(ctx, "room", roomName)
+	if err := EnsureAdminPermission(ctx, roomName); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, roomName); err != nil {
+		return nil, err
+	}
+
+	if _, _, err := s.roomStore.LoadRoom(ctx, roomName, false); err != nil {
+		return nil, err
+	}
+
+	return s.roomDebugClient.GetRoomDebugInfo(ctx, s.topicFormatter.RoomTopic(ctx, roomName), &emptypb.Empty{})
+}
+
+// SetPublishedTrackMinQuality keeps a publisher sending layers up to quality even without subscribers,
+// OFF removes the override. Like GetRoomDebugInfo, it goes to the participant over a psrpc service of this server.
+func (s *RoomService) SetPublishedTrackMinQuality(
+	ctx context.Context,
+	req *livekit.RoomParticipantIdentity,
+	trackID livekit.TrackID,
+	quality livekit.VideoQuality,
+) (*livekit.TrackInfo, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", trackID, "quality", quality)
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, twirpAuthError(err)
+	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, livekit.RoomName(req.Room)); err != nil {
+		return nil, err
+	}
+
+	return s.publishedTrackClient.SetMinPublishedQuality(
+		ctx,
+		s.topicFormatter.ParticipantTopic(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity)),
+		&livekit.UpdateTrackSettings{
+			TrackSids: []string{string(trackID)},
+			Quality:   quality,
+		},
+	)
+}
+
+// SetPublishedTrackMaxQuality caps layers a publisher sends for a simulcast track, like SetPublishedTrackMinQuality
+// it goes to the participant over PublishedTrack
+func (s *RoomService) SetPublishedTrackMaxQuality(
+	ctx context.Context,
+	req *livekit.RoomParticipantIdentity,
+	trackID livekit.TrackID,
+	quality livekit.VideoQuality,
+) (*livekit.TrackInfo, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", trackID, "quality", quality)
+	if err := s.ensureHostedParticipantAdmin(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.publishedTrackClient.SetMaxPublishedQuality(ctx, s.participantTopic(ctx, req), &livekit.UpdateTrackSettings{
+		TrackSids: []string{string(trackID)},
+		Quality:   quality,
+	})
+}
+
+// ensureHostedRoomAdmin checks admin permission of requests going to the node hosting a room,
+// and that the room exists, rather than waiting for a node to answer
+func (s *RoomService) ensureHostedRoomAdmin(ctx context.Context, roomName livekit.RoomName) error {
+	if err := EnsureAdminPermission(ctx, roomName); err != nil {
+		return twirpAuthError(err)
+	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, roomName); err != nil {
+		return err
+	}
+
+	_, _, err := s.roomStore.LoadRoom(ctx, roomName, false)
+	return err
+}
+
+// ensureHostedParticipantAdmin is ensureHostedRoomAdmin for requests going to the node hosting a participant
+func (s *RoomService) ensureHostedParticipantAdmin(ctx context.Context, req *livekit.RoomParticipantIdentity) error {
+	roomName := livekit.RoomName(req.Room)
+	if err := EnsureAdminPermission(ctx, roomName); err != nil {
+		return twirpAuthError(err)
+	}
+	if err := s.tenantQuotas.checkRoomAccess(ctx, roomName); err != nil {
+		return err
+	}
+
+	_, err := s.roomStore.LoadParticipant(ctx, roomName, livekit.ParticipantIdentity(req.Identity))
+	return err
+}
+
+func (s *RoomService) participantTopic(ctx context.Context, req *livekit.RoomParticipantIdentity) rpc.ParticipantTopic {
+	return s.topicFormatter.ParticipantTopic(ctx, livekit.RoomName(req.Room), livekit.ParticipantIdentity(req.Identity))
+}
+
+// SetRoomMaxEgressBitrate limits aggregate bitrate forwarded to subscribers of a room, 0 for no limit.
+// Like the other admin operations below, it goes to the node hosting the room over RoomAdmin or ParticipantAdmin.
+func (s *RoomService) SetRoomMaxEgressBitrate(ctx context.Context, roomName livekit.RoomName, bps int64) error {
+	AppendLogFields(ctx, "room", roomName, "bitrate", bps)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return err
+	}
+
+	_, err := s.roomAdminClient.SetMaxEgressBitrate(ctx, s.topicFormatter.RoomTopic(ctx, roomName), wrapperspb.Int64(bps))
+	return err
+}
+
+// StartHLS starts HLS output of tracks of a room, returns the playlist path under the HLS output directory
+func (s *RoomService) StartHLS(ctx context.Context, roomName livekit.RoomName, trackIDs []livekit.TrackID) (string, error) {
+	AppendLogFields(ctx, "room", roomName, "trackIDs", trackIDs)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return "", err
+	}
+
+	req := &livekit.UpdateTrackSettings{}
+	for _, trackID := range trackIDs {
+		req.TrackSids = append(req.TrackSids, string(trackID))
+	}
+	res, err := s.roomAdminClient.StartHLS(ctx, s.topicFormatter.RoomTopic(ctx, roomName), req)
+	if err != nil {
+		return "", err
+	}
+	return res.Value, nil
+}
+
+func (s *RoomService) StopHLS(ctx context.Context, roomName livekit.RoomName) error {
+	AppendLogFields(ctx, "room", roomName)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return err
+	}
+
+	_, err := s.roomAdminClient.StopHLS(ctx, s.topicFormatter.RoomTopic(ctx, roomName), &emptypb.Empty{})
+	return err
+}
+
+// MuteAllParticipants server mutes published tracks of kinds of all participants but exemptions, returns sids of muted tracks
+func (s *RoomService) MuteAllParticipants(
+	ctx context.Context,
+	roomName livekit.RoomName,
+	kinds []livekit.TrackType,
+	exemptions []livekit.ParticipantIdentity,
+) ([]string, error) {
+	AppendLogFields(ctx, "room", roomName, "kinds", kinds, "exemptions", exemptions)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return nil, err
+	}
+
+	kindValues := make([]*structpb.Value, 0, len(kinds))
+	for _, kind := range kinds {
+		kindValues = append(kindValues, structpb.NewStringValue(kind.String()))
+	}
+	exemptionValues := make([]*structpb.Value, 0, len(exemptions))
+	for _, identity := range exemptions {
+		exemptionValues = append(exemptionValues, structpb.NewStringValue(string(identity)))
+	}
+	res, err := s.roomAdminClient.MuteAll(ctx, s.topicFormatter.RoomTopic(ctx, roomName), &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			roomAdminMuteAllKindsField:      structpb.NewListValue(&structpb.ListValue{Values: kindValues}),
+			roomAdminMuteAllExemptionsField: structpb.NewListValue(&structpb.ListValue{Values: exemptionValues}),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	trackIDs := make([]string, 0, len(res.Values))
+	for _, v := range res.Values {
+		trackIDs = append(trackIDs, v.GetStringValue())
+	}
+	return trackIDs, nil
+}
+
+func (s *RoomService) SetRoomLocked(ctx context.Context, roomName livekit.RoomName, locked bool) error {
+	AppendLogFields(ctx, "room", roomName, "locked", locked)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return err
+	}
+
+	_, err := s.roomAdminClient.SetLocked(ctx, s.topicFormatter.RoomTopic(ctx, roomName), wrapperspb.Bool(locked))
+	return err
+}
+
+// GetRoomSnapshot returns the serialized snapshot of a room, see rtc.RoomSnapshot
+func (s *RoomService) GetRoomSnapshot(ctx context.Context, roomName livekit.RoomName) ([]byte, error) {
+	AppendLogFields(ctx, "room", roomName)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return nil, err
+	}
+
+	res, err := s.roomAdminClient.GetSnapshot(ctx, s.topicFormatter.RoomTopic(ctx, roomName), &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return res.Value, nil
+}
+
+// GetParticipantUsage returns usage of participants of a room, or of a single participant when identity is given
+func (s *RoomService) GetParticipantUsage(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) (*structpb.ListValue, error) {
+	AppendLogFields(ctx, "room", roomName, "participant", identity)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return nil, err
+	}
+
+	return s.roomAdminClient.GetParticipantUsage(ctx, s.topicFormatter.RoomTopic(ctx, roomName), wrapperspb.String(string(identity)))
+}
+
+// ObserveRoomEvents opens a stream of events of a room, the stream ends after the room finished event
+func (s *RoomService) ObserveRoomEvents(ctx context.Context, roomName livekit.RoomName) (psrpc.ClientStream[*emptypb.Empty, *structpb.Struct], error) {
+	AppendLogFields(ctx, "room", roomName)
+	if err := s.ensureHostedRoomAdmin(ctx, roomName); err != nil {
+		return nil, err
+	}
+
+	return s.roomAdminClient.ObserveEvents(ctx, s.topicFormatter.RoomTopic(ctx, roomName))
+}
+
+func (s *RoomService) GetParticipantICEStats(ctx context.Context, req *livekit.RoomParticipantIdentity) (*structpb.ListValue, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity)
+	if err := s.ensureHostedParticipantAdmin(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.participantAdminClient.GetICEStats(ctx, s.participantTopic(ctx, req), &emptypb.Empty{})
+}
+
+func (s *RoomService) SetParticipantMaxUplinkBitrate(ctx context.Context, req *livekit.RoomParticipantIdentity, bps int64) error {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "bitrate", bps)
+	if err := s.ensureHostedParticipantAdmin(ctx, req); err != nil {
+		return err
+	}
+
+	_, err := s.participantAdminClient.SetMaxUplinkBitrate(ctx, s.participantTopic(ctx, req), wrapperspb.Int64(bps))
+	return err
+}
+
+// StartPacketCapture starts capturing media of a participant on the node hosting it, returns path of the first file on that node
+func (s *RoomService) StartPacketCapture(ctx context.Context, req *livekit.RoomParticipantIdentity, duration time.Duration) (string, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "duration", duration)
+	if err := s.ensureHostedParticipantAdmin(ctx, req); err != nil {
+		return "", err
+	}
+
+	res, err := s.participantAdminClient.StartPacketCapture(ctx, s.participantTopic(ctx, req), durationpb.New(duration))
+	if err != nil {
+		return "", err
+	}
+	return res.Value, nil
+}
+
+func (s *RoomService) StopPacketCapture(ctx context.Context, req *livekit.RoomParticipantIdentity) error {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity)
+	if err := s.ensureHostedParticipantAdmin(ctx, req); err != nil {
+		return err
+	}
+
+	_, err := s.participantAdminClient.StopPacketCapture(ctx, s.participantTopic(ctx, req), &emptypb.Empty{})
+	return err
+}
+
+func (s *RoomService) UpdateParticipantTrackName(ctx context.Context, req *livekit.RoomParticipantIdentity, trackID livekit.TrackID, name string) error {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", trackID, "name", name)
+	if err := s.ensureHostedParticipantAdmin(ctx, req); err != nil {
+		return err
+	}
+
+	_, err := s.participantAdminClient.UpdateTrackName(ctx, s.participantTopic(ctx, req), &livekit.TrackInfo{
+		Sid:  string(trackID),
+		Name: name,
+	})
+	return err
+}
+
 func (s *RoomService) confirmExecution(ctx context.Context, f func() error) error {
 	ctx, cancel := context.WithTimeout(ctx, s.apiConf.ExecutionTimeout)
 	defer cancel()
@@ -120,6 +120,10 @@ func newTestRoomService(limitConf config.LimitConfig) *TestRoomService {
 		rpc.NewTopicFormatter(),
 		&rpcfakes.FakeTypedRoomClient{},
 		&rpcfakes.FakeTypedParticipantClient{},
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 	if err != nil {
 		panic(err)
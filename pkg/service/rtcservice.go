@@ -37,8 +37,10 @@ import (
 	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/tracing"
 	"github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/tracer"
 	"github.com/livekit/psrpc"
 )
 
@@ -54,6 +56,7 @@ type RTCService struct {
 	parser        *uaparser.Parser
 	agentClient   agent.Client
 	telemetry     telemetry.TelemetryService
+	tenantQuotas  *tenantQuotas
 
 	mu          sync.Mutex
 	connections map[*websocket.Conn]struct{}
@@ -72,7 +75,7 @@ func NewRTCService(
 		router:        router,
 		roomAllocator: ra,
 		store:         store,
-		upgrader:      websocket.Upgrader{},
+		upgrader:      websocket.Upgrader{EnableCompression: conf.SignalCompression.Enabled},
 		currentNode:   currentNode,
 		config:        conf,
 		isDev:         conf.Development,
@@ -80,6 +83,7 @@ func NewRTCService(
 		parser:        uaparser.NewFromSaved(),
 		agentClient:   agentClient,
 		telemetry:     telemetry,
+		tenantQuotas:  newTenantQuotas(conf.Limit, store),
 		connections:   map[*websocket.Conn]struct{}{},
 	}
 
@@ -131,6 +135,7 @@ func (s *RTCService) validate(r *http.Request) (livekit.RoomName, routing.Partic
 	participantID := r.FormValue("sid")
 	subscriberAllowPauseParam := r.FormValue("subscriber_allow_pause")
 	disableICELite := r.FormValue("disable_ice_lite")
+	capabilitiesParam := r.FormValue("capabilities")
 
 	if onlyName != "" {
 		roomName = onlyName
@@ -170,15 +175,33 @@ func (s *RTCService) validate(r *http.Request) (livekit.RoomName, routing.Partic
 		}
 	}
 
+	iceServers := GetICEServers(r.Context())
+	if err := validateICEServers(iceServers, s.config.Limit.MaxParticipantICEServers); err != nil {
+		return "", pi, http.StatusBadRequest, err
+	}
+
+	if err := s.tenantQuotas.checkJoin(r.Context(), roomName, livekit.ParticipantIdentity(claims.Identity)); err != nil {
+		switch {
+		case errors.Is(err, ErrRoomTenantMismatch):
+			return "", pi, http.StatusForbidden, err
+		case errors.Is(err, ErrTenantParticipantQuotaExceeded):
+			return "", pi, http.StatusTooManyRequests, err
+		default:
+			return "", pi, http.StatusInternalServerError, err
+		}
+	}
+
 	pi = routing.ParticipantInit{
-		Reconnect:       boolValue(reconnectParam),
-		ReconnectReason: livekit.ReconnectReason(reconnectReason),
-		Identity:        livekit.ParticipantIdentity(claims.Identity),
-		Name:            livekit.ParticipantName(claims.Name),
-		AutoSubscribe:   true,
-		Client:          s.ParseClientInfo(r),
-		Grants:          claims,
-		Region:          region,
+		Reconnect:          boolValue(reconnectParam),
+		ReconnectReason:    livekit.ReconnectReason(reconnectReason),
+		Identity:           livekit.ParticipantIdentity(claims.Identity),
+		Name:               livekit.ParticipantName(claims.Name),
+		AutoSubscribe:      true,
+		Client:             s.ParseClientInfo(r),
+		Grants:             claims,
+		Region:             region,
+		ICEServers:         iceServers,
+		ClientCapabilities: capabilitiesParam,
 	}
 	if pi.Reconnect {
 		pi.ID = livekit.ParticipantID(participantID)
@@ -226,11 +249,18 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// give it a few attempts to start session
 	var cr connectionResult
 	var initialResponse *livekit.SignalResponse
+	// relay and join on the media node continue this trace, which may be started by the client
+	traceCtx, span := tracer.Start(tracing.Extract(r.Context(), r.Header.Get(tracing.TraceParentKey)), "RTCService.startConnection",
+		tracing.SpanKindServer,
+		tracing.Attr("room", string(roomName)),
+		tracing.Attr("participant", string(pi.Identity)),
+	)
 	for i := 0; i < 3; i++ {
 		connectionTimeout := 3 * time.Second * time.Duration(i+1)
-		ctx := utils.ContextWithAttempt(r.Context(), i)
+		ctx := utils.ContextWithAttempt(traceCtx, i)
 		cr, initialResponse, err = s.startConnection(ctx, roomName, pi, connectionTimeout)
-		if err == nil || errors.Is(err, context.Canceled) {
+		// retrying a rate limited join would only add to the load
+		if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, rtc.ErrJoinRateLimited) {
 			break
 		}
 		if i < 2 {
@@ -238,9 +268,17 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			l.Warnw("failed to start connection, retrying", err, fieldsWithAttempt...)
 		}
 	}
+	span.RecordError(err)
+	span.End()
 
 	if err != nil {
 		prometheus.IncrementParticipantJoinFail(1)
+		var rateLimitedErr *rtc.JoinRateLimitedError
+		if errors.As(err, &rateLimitedErr) {
+			w.Header().Set("Retry-After", retryAfterSeconds(rateLimitedErr.RetryAfter))
+			handleError(w, r, http.StatusTooManyRequests, err, loggerFields...)
+			return
+		}
 		handleError(w, r, http.StatusInternalServerError, err, loggerFields...)
 		return
 	}
@@ -296,8 +334,17 @@ func (s *RTCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		s.mu.Unlock()
 	}()
 
+	if s.config.SignalCompression.Enabled {
+		if err := conn.SetCompressionLevel(s.config.SignalCompression.Level); err != nil {
+			pLogger.Warnw("could not set signal compression level", err, "level", s.config.SignalCompression.Level)
+		}
+	}
+
 	// websocket established
 	sigConn := NewWSSignalConnection(conn)
+	if s.config.SignalCompression.Enabled {
+		sigConn.SetCompressionMinSize(s.config.SignalCompression.MinSize)
+	}
 	count, err := sigConn.WriteResponse(initialResponse)
 	if err != nil {
 		pLogger.Warnw("could not write initial response", err)
@@ -543,6 +590,12 @@ func (s *RTCService) startConnection(
 		return cr, nil, err
 	}
 
+	if rateLimitedErr, ok := rtc.JoinRateLimitedErrorFromErrorResponse(initialResponse.GetErrorResponse()); ok {
+		cr.RequestSink.Close()
+		cr.ResponseSource.Close()
+		return cr, nil, rateLimitedErr
+	}
+
 	return cr, initialResponse, nil
 }
 
@@ -19,12 +19,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
+	"net/url"
 	"runtime"
 	"runtime/pprof"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pion/turn/v2"
@@ -34,15 +37,23 @@ import (
 	"github.com/urfave/negroni/v3"
 	"go.uber.org/atomic"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
+	"github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/livekit-server/version"
 	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
+	"github.com/livekit/psrpc"
 )
 
+// HLS output is served from hls.output_dir under this path, session IDs in the path keep playlists unguessable
+const hlsPathPrefix = "/hls/"
+
 type LivekitServer struct {
 	config       *config.Config
 	ioService    *IOInfoService
@@ -52,6 +63,7 @@ type LivekitServer struct {
 	promServer   *http.Server
 	router       routing.Router
 	roomManager  *RoomManager
+	roomService  *RoomService
 	signalServer *SignalServer
 	turnServer   *turn.Server
 	currentNode  routing.LocalNode
@@ -61,7 +73,7 @@ type LivekitServer struct {
 }
 
 func NewLivekitServer(conf *config.Config,
-	roomService livekit.RoomService,
+	roomService *RoomService,
 	egressService *EgressService,
 	ingressService *IngressService,
 	sipService *SIPService,
@@ -82,6 +94,7 @@ func NewLivekitServer(conf *config.Config,
 		agentService: agentService,
 		router:       router,
 		roomManager:  roomManager,
+		roomService:  roomService,
 		signalServer: signalServer,
 		// turn server starts automatically
 		turnServer:  turnServer,
@@ -108,7 +121,7 @@ func NewLivekitServer(conf *config.Config,
 
 	twirpLoggingHook := TwirpLogger()
 	twirpRequestStatusHook := TwirpRequestStatusReporter()
-	roomServer := livekit.NewRoomServiceServer(roomService, twirpLoggingHook)
+	roomServer := livekit.NewRoomServiceServer(roomService, twirpLoggingHook, twirp.WithServerInterceptors(TwirpTracing()))
 	egressServer := livekit.NewEgressServer(egressService, twirp.WithServerHooks(
 		twirp.ChainHooks(
 			twirpLoggingHook,
@@ -124,6 +137,10 @@ func NewLivekitServer(conf *config.Config,
 		mux = http.DefaultServeMux
 		mux.HandleFunc("/debug/goroutine", s.debugGoroutines)
 		mux.HandleFunc("/debug/rooms", s.debugInfo)
+
+		// test tools, they generate load and impair media of participants on this node
+		mux.HandleFunc("/admin/loadtest", s.adminLoadTest)
+		mux.HandleFunc("/admin/simulate_network", s.adminSimulateNetwork)
 	}
 
 	mux.Handle(roomServer.PathPrefix(), roomServer)
@@ -140,6 +157,29 @@ func NewLivekitServer(conf *config.Config,
 	logger.Warnw("/agent", nil)
 	mux.HandleFunc("/rtc/validate", rtcService.Validate)
 	logger.Warnw("/rtc/validate", nil)
+	// admin endpoints of rooms and participants go to the node hosting them through RoomService
+	mux.HandleFunc("/debug/room", s.debugRoom)
+	mux.HandleFunc("/debug/ice_stats", s.debugICEStats)
+	mux.HandleFunc("/admin/room_events", s.adminRoomEvents)
+	mux.HandleFunc("/admin/track_min_quality", s.adminTrackMinQuality)
+	mux.HandleFunc("/admin/track_max_quality", s.adminTrackMaxQuality)
+	mux.HandleFunc("/admin/track_name", s.adminTrackName)
+	mux.HandleFunc("/admin/uplink_bitrate_limit", s.adminUplinkBitrateLimit)
+	mux.HandleFunc("/admin/room_egress_bitrate_limit", s.adminRoomEgressBitrateLimit)
+	mux.HandleFunc("/admin/hls", s.adminHLS)
+	mux.HandleFunc("/admin/mute_all", s.adminMuteAll)
+	mux.HandleFunc("/admin/room_lock", s.adminRoomLock)
+	mux.HandleFunc("/admin/packet_capture", s.adminPacketCapture)
+	mux.HandleFunc("/admin/room_snapshot", s.adminRoomSnapshot)
+	mux.HandleFunc("/admin/participant_usage", s.adminParticipantUsage)
+	mux.HandleFunc("/admin/media_node", s.adminMediaNode)
+	// reads participants from the room store, wherever the room is hosted
+	mux.HandleFunc("/admin/participants", s.adminListParticipants)
+	// node operations act on the node receiving the request
+	mux.HandleFunc("/admin/drain", s.adminDrain)
+	if conf.HLS.OutputDir != "" {
+		mux.Handle(hlsPathPrefix, s.hlsHandler(http.StripPrefix(hlsPathPrefix, http.FileServer(http.Dir(conf.HLS.OutputDir)))))
+	}
 	mux.HandleFunc("/", s.defaultHandler)
 
 	s.httpServer = &http.Server{
@@ -215,11 +255,16 @@ func (s *LivekitServer) Start() error {
 		addresses = []string{""}
 	}
 
+	var signalListenConfig net.ListenConfig
+	if dscp := s.config.RTC.DSCP.Signal; dscp != 0 {
+		signalListenConfig.Control = utils.DSCPControl(dscp)
+	}
+
 	// ensure we could listen
 	listeners := make([]net.Listener, 0)
 	promListeners := make([]net.Listener, 0)
 	for _, addr := range addresses {
-		ln, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(int(s.config.Port))))
+		ln, err := signalListenConfig.Listen(context.Background(), "tcp", net.JoinHostPort(addr, strconv.Itoa(int(s.config.Port))))
 		if err != nil {
 			return err
 		}
@@ -360,6 +405,514 @@ func (s *LivekitServer) debugInfo(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// ensureRoomAdmin checks room admin permission of admin endpoints, like RoomService they hide rooms
+// of other tenants from callers scoped to a tenant
+func (s *LivekitServer) ensureRoomAdmin(ctx context.Context, roomName livekit.RoomName) error {
+	if err := EnsureAdminPermission(ctx, roomName); err != nil {
+		return err
+	}
+	return s.roomService.tenantQuotas.checkRoomAccess(ctx, roomName)
+}
+
+// adminErrorStatus is the HTTP status of errors of admin requests going through RoomService,
+// errors of the node hosting a room carry a psrpc code
+func adminErrorStatus(err error) int {
+	var psrpcErr psrpc.Error
+	if errors.As(err, &psrpcErr) {
+		return psrpcErr.ToHttp()
+	}
+	var twirpErr twirp.Error
+	if errors.As(err, &twirpErr) {
+		return twirp.ServerHTTPStatusFromErrorCode(twirpErr.Code())
+	}
+	return http.StatusInternalServerError
+}
+
+// debugICEStats returns candidate pairs of a participant, requires room admin permission
+func (s *LivekitServer) debugICEStats(w http.ResponseWriter, r *http.Request) {
+	req := &livekit.RoomParticipantIdentity{
+		Room:     r.URL.Query().Get("room"),
+		Identity: r.URL.Query().Get("identity"),
+	}
+	if err := s.ensureRoomAdmin(r.Context(), livekit.RoomName(req.Room)); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	iceStats, err := s.roomService.GetParticipantICEStats(r.Context(), req)
+	if err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+
+	b, err := json.Marshal(iceStats.AsSlice())
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// debugRoom returns debug info of a room from the node hosting it, requires room admin permission
+func (s *LivekitServer) debugRoom(w http.ResponseWriter, r *http.Request) {
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	info, err := s.roomService.GetRoomDebugInfo(r.Context(), roomName)
+	if err != nil {
+		handleError(w, r, http.StatusNotFound, err)
+		return
+	}
+
+	b, err := protojson.Marshal(info)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// adminRoomEvents streams activity of a room as newline delimited JSON,
+// until the room finishes or the client disconnects. requires room admin permission
+func (s *LivekitServer) adminRoomEvents(w http.ResponseWriter, r *http.Request) {
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		handleError(w, r, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	stream, err := s.roomService.ObserveRoomEvents(r.Context(), roomName)
+	if err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+	defer func() {
+		_ = stream.Close(nil)
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// the node hosting the room ends the stream after the room finished event
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-stream.Channel():
+			if !ok {
+				return
+			}
+			if err := enc.Encode(msg.AsMap()); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// adminTrackMaxQuality caps simulcast layers sent by a publisher for a track, requires room admin permission,
+// quality is one of LOW, MEDIUM, HIGH
+func (s *LivekitServer) adminTrackMaxQuality(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	req := &livekit.RoomParticipantIdentity{
+		Room:     query.Get("room"),
+		Identity: query.Get("identity"),
+	}
+	if err := s.ensureRoomAdmin(r.Context(), livekit.RoomName(req.Room)); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	quality, ok := livekit.VideoQuality_value[strings.ToUpper(query.Get("quality"))]
+	if !ok || livekit.VideoQuality(quality) == livekit.VideoQuality_OFF {
+		handleError(w, r, http.StatusBadRequest, errors.New("invalid quality"))
+		return
+	}
+
+	trackInfo, err := s.roomService.SetPublishedTrackMaxQuality(
+		r.Context(),
+		req,
+		livekit.TrackID(query.Get("track")),
+		livekit.VideoQuality(quality),
+	)
+	if err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+
+	b, err := protojson.Marshal(trackInfo)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// adminTrackMinQuality keeps simulcast layers up to quality published for a track even without subscribers,
+// e. g. to keep a screen share at full quality for recording, requires room admin permission,
+// quality is one of LOW, MEDIUM, HIGH or OFF to let dynacast pause layers again
+func (s *LivekitServer) adminTrackMinQuality(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	req := &livekit.RoomParticipantIdentity{
+		Room:     query.Get("room"),
+		Identity: query.Get("identity"),
+	}
+	if err := EnsureAdminPermission(r.Context(), livekit.RoomName(req.Room)); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	quality, ok := livekit.VideoQuality_value[strings.ToUpper(query.Get("quality"))]
+	if !ok {
+		handleError(w, r, http.StatusBadRequest, errors.New("invalid quality"))
+		return
+	}
+
+	trackInfo, err := s.roomService.SetPublishedTrackMinQuality(
+		r.Context(),
+		req,
+		livekit.TrackID(query.Get("track")),
+		livekit.VideoQuality(quality),
+	)
+	if err != nil {
+		handleError(w, r, http.StatusNotFound, err)
+		return
+	}
+
+	b, err := protojson.Marshal(trackInfo)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// adminTrackName renames a track published by a participant, requires room admin permission,
+// other participants get the new name with the next participant update
+func (s *LivekitServer) adminTrackName(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	req := &livekit.RoomParticipantIdentity{
+		Room:     query.Get("room"),
+		Identity: query.Get("identity"),
+	}
+	if err := s.ensureRoomAdmin(r.Context(), livekit.RoomName(req.Room)); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	err := s.roomService.UpdateParticipantTrackName(
+		r.Context(),
+		req,
+		livekit.TrackID(query.Get("track")),
+		query.Get("name"),
+	)
+	if err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// adminUplinkBitrateLimit limits total bitrate (bps) published by a participant, requires room admin permission,
+// bitrate of 0 removes the limit
+func (s *LivekitServer) adminUplinkBitrateLimit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	req := &livekit.RoomParticipantIdentity{
+		Room:     query.Get("room"),
+		Identity: query.Get("identity"),
+	}
+	if err := s.ensureRoomAdmin(r.Context(), livekit.RoomName(req.Room)); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	bitrate, err := strconv.ParseInt(query.Get("bitrate"), 10, 64)
+	if err != nil || bitrate < 0 {
+		handleError(w, r, http.StatusBadRequest, errors.New("invalid bitrate"))
+		return
+	}
+
+	if err := s.roomService.SetParticipantMaxUplinkBitrate(r.Context(), req, bitrate); err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// adminRoomEgressBitrateLimit limits aggregate bitrate (bps) forwarded to subscribers of a room,
+// overriding room.max_egress_bitrate, requires room admin permission, bitrate of 0 removes the limit
+func (s *LivekitServer) adminRoomEgressBitrateLimit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	bitrate, err := strconv.ParseInt(r.URL.Query().Get("bitrate"), 10, 64)
+	if err != nil || bitrate < 0 {
+		handleError(w, r, http.StatusBadRequest, errors.New("invalid bitrate"))
+		return
+	}
+
+	if err := s.roomService.SetRoomMaxEgressBitrate(r.Context(), roomName, bitrate); err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// hlsHandler serves HLS output of a room to participants allowed to join the room,
+// players have to send the token with playlist and segment requests. Output is written by the node
+// hosting the room, other nodes serve it when hls.output_dir is shared between nodes
+func (s *LivekitServer) hlsHandler(fileServer http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roomName, err := EnsureJoinPermission(r.Context())
+		if err != nil {
+			handleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		// output of a room is written under its room ID
+		roomID, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, hlsPathPrefix), "/")
+		room, _, err := s.roomService.roomStore.LoadRoom(r.Context(), roomName, false)
+		if err != nil || room.Sid != roomID {
+			handleError(w, r, http.StatusNotFound, ErrRoomNotFound)
+			return
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+func (s *LivekitServer) adminHLS(w http.ResponseWriter, r *http.Request) {
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var trackIDs []livekit.TrackID
+		for _, trackID := range strings.Split(r.URL.Query().Get("tracks"), ",") {
+			if trackID = strings.TrimSpace(trackID); trackID != "" {
+				trackIDs = append(trackIDs, livekit.TrackID(trackID))
+			}
+		}
+		if len(trackIDs) == 0 {
+			handleError(w, r, http.StatusBadRequest, errors.New("no tracks for HLS output"))
+			return
+		}
+
+		playlist, err := s.roomService.StartHLS(r.Context(), roomName, trackIDs)
+		if err != nil {
+			handleError(w, r, adminErrorStatus(err), err)
+			return
+		}
+
+		b, err := json.Marshal(map[string]string{"playlist_url": hlsPathPrefix + playlist})
+		if err != nil {
+			handleError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+
+	case http.MethodDelete:
+		if err := s.roomService.StopHLS(r.Context(), roomName); err != nil {
+			handleError(w, r, adminErrorStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+func (s *LivekitServer) adminLoadTest(w http.ResponseWriter, r *http.Request) {
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	var (
+		status *rtc.LoadTestStatus
+		err    error
+	)
+	switch r.Method {
+	case http.MethodPost:
+		var params rtc.LoadTestParams
+		for name, v := range map[string]*int{
+			"publishers":    &params.Publishers,
+			"subscribers":   &params.Subscribers,
+			"video_bitrate": &params.VideoBitrate,
+		} {
+			value := r.URL.Query().Get(name)
+			if value == "" {
+				continue
+			}
+			if *v, err = strconv.Atoi(value); err != nil {
+				handleError(w, r, http.StatusBadRequest, fmt.Errorf("invalid %s: %w", name, err))
+				return
+			}
+		}
+
+		status, err = s.roomManager.StartLoadTest(r.Context(), roomName, params)
+		if err != nil {
+			code := http.StatusNotFound
+			switch {
+			case errors.Is(err, ErrLoadTestNotEnabled):
+				code = http.StatusNotImplemented
+			case errors.Is(err, ErrLoadTestLimitExceeded):
+				code = http.StatusBadRequest
+			case errors.Is(err, rtc.ErrLoadTestRunning):
+				code = http.StatusConflict
+			}
+			handleError(w, r, code, err)
+			return
+		}
+
+	case http.MethodGet:
+		if status, err = s.roomManager.GetLoadTestStatus(r.Context(), roomName); err != nil {
+			handleError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+	case http.MethodDelete:
+		if err := s.roomManager.StopLoadTest(r.Context(), roomName); err != nil {
+			handleError(w, r, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+
+	default:
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	b, err := json.Marshal(status)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+func (s *LivekitServer) adminMediaNode(w http.ResponseWriter, r *http.Request) {
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	identity := livekit.ParticipantIdentity(r.URL.Query().Get("identity"))
+	node, err := s.roomManager.GetMediaNode(r.Context(), roomName, identity)
+	if err != nil {
+		handleError(w, r, http.StatusNotFound, err)
+		return
+	}
+
+	b, err := protojson.Marshal(node)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// adminDrain starts draining the node with POST and reports progress with GET, requires room create permission
+func (s *LivekitServer) adminDrain(w http.ResponseWriter, r *http.Request) {
+	if err := EnsureNodeAdminPermission(r.Context()); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	var status *DrainStatus
+	switch r.Method {
+	case http.MethodPost:
+		var rate float64
+		if v := r.URL.Query().Get("rate"); v != "" {
+			var err error
+			if rate, err = strconv.ParseFloat(v, 64); err != nil || rate <= 0 {
+				handleError(w, r, http.StatusBadRequest, errors.New("invalid rate"))
+				return
+			}
+		}
+
+		var err error
+		if status, err = s.roomManager.DrainNode(rate); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrNoDrainTarget) {
+				code = http.StatusConflict
+			}
+			handleError(w, r, code, err)
+			return
+		}
+
+	case http.MethodGet:
+		if status = s.roomManager.GetDrainStatus(); status == nil {
+			status = &DrainStatus{}
+		}
+
+	default:
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	b, err := json.Marshal(status)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
 func (s *LivekitServer) defaultHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path == "/" {
 		s.healthCheck(w, r)
@@ -387,12 +940,23 @@ func (s *LivekitServer) healthCheck(w http.ResponseWriter, _ *http.Request) {
 func (s *LivekitServer) backgroundWorker() {
 	roomTicker := time.NewTicker(1 * time.Second)
 	defer roomTicker.Stop()
+
+	// nil channel never fires when participant resources are not exported
+	var resourcesTickerC <-chan time.Time
+	if s.config.Prometheus.HottestParticipants > 0 && s.config.Prometheus.ParticipantResourcesInterval > 0 {
+		resourcesTicker := time.NewTicker(s.config.Prometheus.ParticipantResourcesInterval)
+		defer resourcesTicker.Stop()
+		resourcesTickerC = resourcesTicker.C
+	}
+
 	for {
 		select {
 		case <-s.doneChan:
 			return
 		case <-roomTicker.C:
 			s.roomManager.CloseIdleRooms()
+		case <-resourcesTickerC:
+			s.roomManager.SampleParticipantResources()
 		}
 	}
 }
@@ -405,3 +969,408 @@ func configureMiddlewares(handler http.Handler, middlewares ...negroni.Handler)
 	n.UseHandler(handler)
 	return n
 }
+
+// adminMuteAll server mutes published tracks of all participants in a room, requires room admin permission.
+// kind is audio, video or all (default), except is a comma separated list of identities to leave unmuted
+func (s *LivekitServer) adminMuteAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	roomName := livekit.RoomName(query.Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	var kinds []livekit.TrackType
+	switch query.Get("kind") {
+	case "audio":
+		kinds = []livekit.TrackType{livekit.TrackType_AUDIO}
+	case "video":
+		kinds = []livekit.TrackType{livekit.TrackType_VIDEO}
+	case "", "all":
+		kinds = []livekit.TrackType{livekit.TrackType_AUDIO, livekit.TrackType_VIDEO}
+	default:
+		handleError(w, r, http.StatusBadRequest, errors.New("invalid kind"))
+		return
+	}
+
+	var exemptions []livekit.ParticipantIdentity
+	for _, identity := range strings.Split(query.Get("except"), ",") {
+		if identity = strings.TrimSpace(identity); identity != "" {
+			exemptions = append(exemptions, livekit.ParticipantIdentity(identity))
+		}
+	}
+
+	mutedTracks, err := s.roomService.MuteAllParticipants(r.Context(), roomName, kinds, exemptions)
+	if err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+
+	b, err := json.Marshal(map[string]interface{}{"muted_tracks": mutedTracks})
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// adminRoomLock locks a room with POST, rejecting new participants, and unlocks it with DELETE,
+// requires room admin permission
+func (s *LivekitServer) adminRoomLock(w http.ResponseWriter, r *http.Request) {
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	var locked bool
+	switch r.Method {
+	case http.MethodPost:
+		locked = true
+	case http.MethodDelete:
+		locked = false
+	default:
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	if err := s.roomService.SetRoomLocked(r.Context(), roomName, locked); err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// adminPacketCapture starts (POST) or stops (DELETE) capturing decrypted RTP/RTCP of a participant
+// into pcapng files on the node hosting the participant, requires room admin permission
+func (s *LivekitServer) adminPacketCapture(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &livekit.RoomParticipantIdentity{
+		Room:     query.Get("room"),
+		Identity: query.Get("identity"),
+	}
+	if err := s.ensureRoomAdmin(r.Context(), livekit.RoomName(req.Room)); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var duration time.Duration
+		if v := query.Get("duration"); v != "" {
+			var err error
+			if duration, err = time.ParseDuration(v); err != nil || duration <= 0 {
+				handleError(w, r, http.StatusBadRequest, errors.New("invalid duration"))
+				return
+			}
+		}
+
+		file, err := s.roomService.StartPacketCapture(r.Context(), req, duration)
+		if err != nil {
+			handleError(w, r, adminErrorStatus(err), err)
+			return
+		}
+
+		b, err := json.Marshal(map[string]string{"file": file})
+		if err != nil {
+			handleError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+
+	case http.MethodDelete:
+		if err := s.roomService.StopPacketCapture(r.Context(), req); err != nil {
+			handleError(w, r, adminErrorStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+// adminSimulateNetwork impairs media between a participant connected to this node and the SFU, to reproduce
+// degraded networks deterministically, requires room admin permission. POST sets loss (fraction in [0, 1]),
+// latency and jitter (durations), bandwidth (bps) and seed for direction uplink, downlink or both (default),
+// GET returns the conditions in effect and DELETE restores both directions
+func (s *LivekitServer) adminSimulateNetwork(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &livekit.RoomParticipantIdentity{
+		Room:     query.Get("room"),
+		Identity: query.Get("identity"),
+	}
+	if err := s.ensureRoomAdmin(r.Context(), livekit.RoomName(req.Room)); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		conditions, err := parseNetworkConditions(query)
+		if err != nil {
+			handleError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		uplink, downlink, err := s.roomManager.GetNetworkConditions(r.Context(), req)
+		if err != nil {
+			handleError(w, r, http.StatusNotFound, err)
+			return
+		}
+		switch query.Get("direction") {
+		case "", "both":
+			uplink, downlink = conditions, conditions
+		case "uplink":
+			uplink = conditions
+		case "downlink":
+			downlink = conditions
+		default:
+			handleError(w, r, http.StatusBadRequest, errors.New("invalid direction"))
+			return
+		}
+
+		if err := s.roomManager.SimulateNetworkConditions(r.Context(), req, uplink, downlink); err != nil {
+			handleError(w, r, http.StatusNotFound, err)
+			return
+		}
+
+	case http.MethodGet:
+
+	case http.MethodDelete:
+		if err := s.roomManager.SimulateNetworkConditions(r.Context(), req, nil, nil); err != nil {
+			handleError(w, r, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+
+	default:
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	uplink, downlink, err := s.roomManager.GetNetworkConditions(r.Context(), req)
+	if err != nil {
+		handleError(w, r, http.StatusNotFound, err)
+		return
+	}
+	b, err := json.Marshal(map[string]any{
+		"uplink":   networkConditionsToJSON(uplink),
+		"downlink": networkConditionsToJSON(downlink),
+	})
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+func parseNetworkConditions(query url.Values) (*netem.Conditions, error) {
+	c := &netem.Conditions{}
+	var err error
+	if v := query.Get("loss"); v != "" {
+		if c.Loss, err = strconv.ParseFloat(v, 64); err != nil || c.Loss < 0 || c.Loss > 1 {
+			return nil, errors.New("invalid loss")
+		}
+	}
+	for name, d := range map[string]*time.Duration{
+		"latency": &c.Latency,
+		"jitter":  &c.Jitter,
+	} {
+		if v := query.Get(name); v != "" {
+			if *d, err = time.ParseDuration(v); err != nil || *d < 0 {
+				return nil, fmt.Errorf("invalid %s", name)
+			}
+		}
+	}
+	if v := query.Get("bandwidth"); v != "" {
+		if c.Bandwidth, err = strconv.ParseInt(v, 10, 64); err != nil || c.Bandwidth < 0 {
+			return nil, errors.New("invalid bandwidth")
+		}
+	}
+	if v := query.Get("seed"); v != "" {
+		if c.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, errors.New("invalid seed")
+		}
+	}
+	return c, nil
+}
+
+func networkConditionsToJSON(c *netem.Conditions) map[string]any {
+	if c == nil {
+		return nil
+	}
+	return map[string]any{
+		"loss":      c.Loss,
+		"latency":   c.Latency.String(),
+		"jitter":    c.Jitter.String(),
+		"bandwidth": c.Bandwidth,
+		"seed":      c.Seed,
+	}
+}
+
+// adminParticipantUsage returns cumulative bytes up/down per track and codec of participants of a room,
+// optionally of a single participant identity, requires room admin permission.
+// The same report is included in the room finished event of room observers.
+func (s *LivekitServer) adminParticipantUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	roomName := livekit.RoomName(query.Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	usages, err := s.roomService.GetParticipantUsage(r.Context(), roomName, livekit.ParticipantIdentity(query.Get("identity")))
+	if err != nil {
+		handleError(w, r, adminErrorStatus(err), err)
+		return
+	}
+
+	b, err := json.Marshal(map[string]any{
+		"participants": usages.AsSlice(),
+	})
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// adminListParticipants lists participants of a room a page at a time, requires room admin permission.
+// participants can be filtered by comma separated states, identity prefix and publishing, and sorted by
+// identity or joined_at. next_page_token of a response is passed as page_token to get the next page
+func (s *LivekitServer) adminListParticipants(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	roomName := livekit.RoomName(query.Get("room"))
+	if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+		handleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	opts := ParticipantListOptions{
+		IdentityPrefix: query.Get("identity_prefix"),
+		PublishersOnly: boolValue(query.Get("publishers_only")),
+		SortBy:         ParticipantSortKey(query.Get("sort")),
+		PageToken:      query.Get("page_token"),
+	}
+	if states := query.Get("state"); states != "" {
+		for _, state := range strings.Split(states, ",") {
+			v, ok := livekit.ParticipantInfo_State_value[strings.ToUpper(state)]
+			if !ok {
+				handleError(w, r, http.StatusBadRequest, fmt.Errorf("invalid state %s", state))
+				return
+			}
+			opts.States = append(opts.States, livekit.ParticipantInfo_State(v))
+		}
+	}
+	if pageSize := query.Get("page_size"); pageSize != "" {
+		v, err := strconv.Atoi(pageSize)
+		if err != nil || v < 0 {
+			handleError(w, r, http.StatusBadRequest, errors.New("invalid page size"))
+			return
+		}
+		opts.PageSize = v
+	}
+
+	page, err := s.roomService.ListParticipantsPage(r.Context(), roomName, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrInvalidParticipantListOptions):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrRoomNotFound):
+			status = http.StatusNotFound
+		}
+		handleError(w, r, status, err)
+		return
+	}
+
+	res := struct {
+		Participants  []json.RawMessage `json:"participants"`
+		NextPageToken string            `json:"next_page_token,omitempty"`
+	}{
+		Participants:  make([]json.RawMessage, 0, len(page.Participants)),
+		NextPageToken: page.NextPageToken,
+	}
+	for _, p := range page.Participants {
+		b, err := protojson.Marshal(p)
+		if err != nil {
+			handleError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		res.Participants = append(res.Participants, b)
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		handleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// adminRoomSnapshot serializes state of a room from the node hosting it with GET, and restores a snapshot
+// given as request body onto the node receiving the request with POST, requires room admin permission
+func (s *LivekitServer) adminRoomSnapshot(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		roomName := livekit.RoomName(r.URL.Query().Get("room"))
+		if err := s.ensureRoomAdmin(r.Context(), roomName); err != nil {
+			handleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		b, err := s.roomService.GetRoomSnapshot(r.Context(), roomName)
+		if err != nil {
+			handleError(w, r, adminErrorStatus(err), err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(b)
+
+	case http.MethodPost:
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			handleError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		snapshot, err := rtc.UnmarshalRoomSnapshot(b)
+		if err != nil {
+			handleError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.ensureRoomAdmin(r.Context(), livekit.RoomName(snapshot.Room.Name)); err != nil {
+			handleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+		if err := s.roomManager.RestoreRoom(r.Context(), snapshot); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrRoomAlreadyHosted) {
+				status = http.StatusConflict
+			}
+			handleError(w, r, status, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		handleError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
@@ -50,6 +50,21 @@ type FakeObjectStore struct {
 		result1 []*livekit.ParticipantInfo
 		result2 error
 	}
+	ListParticipantsPageStub        func(context.Context, livekit.RoomName, service.ParticipantListOptions) (*service.ParticipantPage, error)
+	listParticipantsPageMutex       sync.RWMutex
+	listParticipantsPageArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 service.ParticipantListOptions
+	}
+	listParticipantsPageReturns struct {
+		result1 *service.ParticipantPage
+		result2 error
+	}
+	listParticipantsPageReturnsOnCall map[int]struct {
+		result1 *service.ParticipantPage
+		result2 error
+	}
 	ListRoomsStub        func(context.Context, []livekit.RoomName) ([]*livekit.Room, error)
 	listRoomsMutex       sync.RWMutex
 	listRoomsArgsForCall []struct {
@@ -344,6 +359,72 @@ func (fake *FakeObjectStore) ListParticipantsReturnsOnCall(i int, result1 []*liv
 	}{result1, result2}
 }
 
+func (fake *FakeObjectStore) ListParticipantsPage(arg1 context.Context, arg2 livekit.RoomName, arg3 service.ParticipantListOptions) (*service.ParticipantPage, error) {
+	fake.listParticipantsPageMutex.Lock()
+	ret, specificReturn := fake.listParticipantsPageReturnsOnCall[len(fake.listParticipantsPageArgsForCall)]
+	fake.listParticipantsPageArgsForCall = append(fake.listParticipantsPageArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomName
+		arg3 service.ParticipantListOptions
+	}{arg1, arg2, arg3})
+	stub := fake.ListParticipantsPageStub
+	fakeReturns := fake.listParticipantsPageReturns
+	fake.recordInvocation("ListParticipantsPage", []interface{}{arg1, arg2, arg3})
+	fake.listParticipantsPageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeObjectStore) ListParticipantsPageCallCount() int {
+	fake.listParticipantsPageMutex.RLock()
+	defer fake.listParticipantsPageMutex.RUnlock()
+	return len(fake.listParticipantsPageArgsForCall)
+}
+
+func (fake *FakeObjectStore) ListParticipantsPageCalls(stub func(context.Context, livekit.RoomName, service.ParticipantListOptions) (*service.ParticipantPage, error)) {
+	fake.listParticipantsPageMutex.Lock()
+	defer fake.listParticipantsPageMutex.Unlock()
+	fake.ListParticipantsPageStub = stub
+}
+
+func (fake *FakeObjectStore) ListParticipantsPageArgsForCall(i int) (context.Context, livekit.RoomName, service.ParticipantListOptions) {
+	fake.listParticipantsPageMutex.RLock()
+	defer fake.listParticipantsPageMutex.RUnlock()
+	argsForCall := fake.listParticipantsPageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeObjectStore) ListParticipantsPageReturns(result1 *service.ParticipantPage, result2 error) {
+	fake.listParticipantsPageMutex.Lock()
+	defer fake.listParticipantsPageMutex.Unlock()
+	fake.ListParticipantsPageStub = nil
+	fake.listParticipantsPageReturns = struct {
+		result1 *service.ParticipantPage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeObjectStore) ListParticipantsPageReturnsOnCall(i int, result1 *service.ParticipantPage, result2 error) {
+	fake.listParticipantsPageMutex.Lock()
+	defer fake.listParticipantsPageMutex.Unlock()
+	fake.ListParticipantsPageStub = nil
+	if fake.listParticipantsPageReturnsOnCall == nil {
+		fake.listParticipantsPageReturnsOnCall = make(map[int]struct {
+			result1 *service.ParticipantPage
+			result2 error
+		})
+	}
+	fake.listParticipantsPageReturnsOnCall[i] = struct {
+		result1 *service.ParticipantPage
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeObjectStore) ListRooms(arg1 context.Context, arg2 []livekit.RoomName) ([]*livekit.Room, error) {
 	var arg2Copy []livekit.RoomName
 	if arg2 != nil {
@@ -813,6 +894,8 @@ func (fake *FakeObjectStore) Invocations() map[string][][]interface{} {
 	defer fake.deleteRoomMutex.RUnlock()
 	fake.listParticipantsMutex.RLock()
 	defer fake.listParticipantsMutex.RUnlock()
+	fake.listParticipantsPageMutex.RLock()
+	defer fake.listParticipantsPageMutex.RUnlock()
 	fake.listRoomsMutex.RLock()
 	defer fake.listRoomsMutex.RUnlock()
 	fake.loadParticipantMutex.RLock()
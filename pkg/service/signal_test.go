@@ -35,7 +35,7 @@ import (
 )
 
 func init() {
-	prometheus.Init("node", livekit.NodeType_CONTROLLER)
+	prometheus.Init("node", livekit.NodeType_CONTROLLER, config.PrometheusConfig{})
 }
 
 func TestSignal(t *testing.T) {
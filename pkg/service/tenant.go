@@ -0,0 +1,174 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+// TenantAttribute is the access token attribute scoping rooms created and joined with the token to a tenant.
+// Callers without a tenant are not scoped and reach rooms of all tenants.
+const TenantAttribute = "lk.tenant"
+
+func GetTenant(ctx context.Context) string {
+	claims := GetGrants(ctx)
+	if claims == nil {
+		return ""
+	}
+	return claims.Attributes[TenantAttribute]
+}
+
+// tenantQuotas enforces tenant scoping of rooms and quotas of tenants across the cluster,
+// it is disabled when the store does not keep track of room tenants
+type tenantQuotas struct {
+	conf       config.TenantLimitConfig
+	roomStore  ServiceStore
+	quotaStore QuotaStore
+}
+
+func newTenantQuotas(conf config.LimitConfig, roomStore ServiceStore) *tenantQuotas {
+	quotaStore, _ := roomStore.(QuotaStore)
+	return &tenantQuotas{
+		conf:       conf.Tenants,
+		roomStore:  roomStore,
+		quotaStore: quotaStore,
+	}
+}
+
+// checkRoomAccess hides rooms of other tenants from callers scoped to a tenant
+func (t *tenantQuotas) checkRoomAccess(ctx context.Context, roomName livekit.RoomName) error {
+	tenant := GetTenant(ctx)
+	if t.quotaStore == nil || tenant == "" {
+		return nil
+	}
+
+	roomTenant, err := t.quotaStore.LoadRoomTenant(ctx, roomName)
+	if err != nil {
+		return err
+	}
+	if roomTenant != tenant {
+		return ErrRoomNotFound
+	}
+	return nil
+}
+
+// filterRooms drops rooms of other tenants for callers scoped to a tenant
+func (t *tenantQuotas) filterRooms(ctx context.Context, rooms []*livekit.Room) ([]*livekit.Room, error) {
+	tenant := GetTenant(ctx)
+	if t.quotaStore == nil || tenant == "" {
+		return rooms, nil
+	}
+
+	roomNames, err := t.quotaStore.ListTenantRooms(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	tenantRooms := make(map[livekit.RoomName]struct{}, len(roomNames))
+	for _, roomName := range roomNames {
+		tenantRooms[roomName] = struct{}{}
+	}
+
+	filtered := make([]*livekit.Room, 0, len(rooms))
+	for _, rm := range rooms {
+		if _, ok := tenantRooms[livekit.RoomName(rm.Name)]; ok {
+			filtered = append(filtered, rm)
+		}
+	}
+	return filtered, nil
+}
+
+// checkCreateRoom is called with the room lock held, existing rooms have to belong to the tenant,
+// new rooms are scoped to the tenant and count towards its room quota, until released with releaseRoom
+func (t *tenantQuotas) checkCreateRoom(ctx context.Context, tenant string, roomName livekit.RoomName, created bool) error {
+	if t.quotaStore == nil || tenant == "" {
+		return nil
+	}
+
+	if !created {
+		roomTenant, err := t.quotaStore.LoadRoomTenant(ctx, roomName)
+		if err != nil {
+			return err
+		}
+		if roomTenant != tenant {
+			return ErrRoomTenantMismatch
+		}
+		return nil
+	}
+
+	err := t.quotaStore.ReserveTenantRoom(ctx, roomName, tenant, t.conf.QuotaFor(tenant).MaxRooms)
+	if errors.Is(err, ErrTenantRoomQuotaExceeded) {
+		prometheus.RecordTenantQuotaExceeded(tenant, "rooms")
+	}
+	return err
+}
+
+// releaseRoom uncounts a new room that could not be created after checkCreateRoom
+func (t *tenantQuotas) releaseRoom(ctx context.Context, tenant string, roomName livekit.RoomName) {
+	if t.quotaStore == nil || tenant == "" {
+		return
+	}
+	if err := t.quotaStore.ReleaseTenantRoom(ctx, roomName); err != nil {
+		logger.Warnw("could not release room of tenant", err, "room", roomName, "tenant", tenant)
+	}
+}
+
+// checkJoin counts participants across rooms of the tenant, participants already in the room rejoin freely
+func (t *tenantQuotas) checkJoin(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error {
+	tenant := GetTenant(ctx)
+	if t.quotaStore == nil || tenant == "" {
+		return nil
+	}
+
+	roomTenant, err := t.quotaStore.LoadRoomTenant(ctx, roomName)
+	if err != nil {
+		return err
+	}
+	if roomTenant != "" && roomTenant != tenant {
+		return ErrRoomTenantMismatch
+	}
+
+	maxParticipants := t.conf.QuotaFor(tenant).MaxParticipants
+	if maxParticipants <= 0 {
+		return nil
+	}
+	if _, err := t.roomStore.LoadParticipant(ctx, roomName, identity); err == nil {
+		return nil
+	}
+
+	roomNames, err := t.quotaStore.ListTenantRooms(ctx, tenant)
+	if err != nil || len(roomNames) == 0 {
+		return err
+	}
+	rooms, err := t.roomStore.ListRooms(ctx, roomNames)
+	if err != nil {
+		return err
+	}
+	var numParticipants int
+	for _, rm := range rooms {
+		numParticipants += int(rm.NumParticipants)
+	}
+	if numParticipants >= maxParticipants {
+		prometheus.RecordTenantQuotaExceeded(tenant, "participants")
+		return ErrTenantParticipantQuotaExceeded
+	}
+	return nil
+}
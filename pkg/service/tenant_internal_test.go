@@ -0,0 +1,122 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+func init() {
+	prometheus.Init("test", livekit.NodeType_SERVER, config.PrometheusConfig{})
+}
+
+func tenantContext(tenant string) context.Context {
+	grants := &auth.ClaimGrants{Video: &auth.VideoGrant{}}
+	if tenant != "" {
+		grants.Attributes = map[string]string{TenantAttribute: tenant}
+	}
+	return WithGrants(context.Background(), grants, "key")
+}
+
+func newTestTenantQuotas(t *testing.T, quota config.TenantQuota) (*tenantQuotas, *LocalStore) {
+	store := NewLocalStore()
+	tq := newTenantQuotas(config.LimitConfig{Tenants: config.TenantLimitConfig{Default: quota}}, store)
+	ctx := context.Background()
+	for roomName, tenant := range map[livekit.RoomName]string{"a1": "a", "a2": "a", "b1": "b"} {
+		require.NoError(t, store.StoreRoom(ctx, &livekit.Room{Name: string(roomName), NumParticipants: 1}, nil))
+		require.NoError(t, store.ReserveTenantRoom(ctx, roomName, tenant, 0))
+	}
+	require.NoError(t, store.StoreRoom(ctx, &livekit.Room{Name: "shared"}, nil))
+	return tq, store
+}
+
+func TestTenantRoomAccess(t *testing.T) {
+	tq, _ := newTestTenantQuotas(t, config.TenantQuota{})
+
+	require.NoError(t, tq.checkRoomAccess(tenantContext("a"), "a1"))
+	require.ErrorIs(t, tq.checkRoomAccess(tenantContext("a"), "b1"), ErrRoomNotFound)
+	require.ErrorIs(t, tq.checkRoomAccess(tenantContext("a"), "shared"), ErrRoomNotFound)
+	// callers without a tenant reach every room
+	require.NoError(t, tq.checkRoomAccess(tenantContext(""), "b1"))
+}
+
+func TestTenantFilterRooms(t *testing.T) {
+	tq, store := newTestTenantQuotas(t, config.TenantQuota{})
+	rooms, err := store.ListRooms(context.Background(), nil)
+	require.NoError(t, err)
+
+	roomNames := func(tenant string) []string {
+		filtered, err := tq.filterRooms(tenantContext(tenant), rooms)
+		require.NoError(t, err)
+		var names []string
+		for _, rm := range filtered {
+			names = append(names, rm.Name)
+		}
+		return names
+	}
+	require.ElementsMatch(t, []string{"a1", "a2"}, roomNames("a"))
+	require.ElementsMatch(t, []string{"b1"}, roomNames("b"))
+	require.Empty(t, roomNames("c"))
+	require.ElementsMatch(t, []string{"a1", "a2", "b1", "shared"}, roomNames(""))
+}
+
+func TestTenantCheckCreateRoom(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("existing rooms belong to the tenant", func(t *testing.T) {
+		tq, _ := newTestTenantQuotas(t, config.TenantQuota{})
+		require.NoError(t, tq.checkCreateRoom(ctx, "a", "a1", false))
+		require.ErrorIs(t, tq.checkCreateRoom(ctx, "a", "b1", false), ErrRoomTenantMismatch)
+		require.NoError(t, tq.checkCreateRoom(ctx, "", "b1", false))
+	})
+
+	t.Run("new rooms count towards the quota", func(t *testing.T) {
+		tq, store := newTestTenantQuotas(t, config.TenantQuota{MaxRooms: 3})
+		require.NoError(t, tq.checkCreateRoom(ctx, "a", "a3", true))
+		roomTenant, err := store.LoadRoomTenant(ctx, "a3")
+		require.NoError(t, err)
+		require.Equal(t, "a", roomTenant)
+
+		require.ErrorIs(t, tq.checkCreateRoom(ctx, "a", "a4", true), ErrTenantRoomQuotaExceeded)
+		require.NoError(t, tq.checkCreateRoom(ctx, "b", "b2", true))
+
+		// released rooms free up the quota
+		tq.releaseRoom(ctx, "a", "a3")
+		require.NoError(t, tq.checkCreateRoom(ctx, "a", "a4", true))
+	})
+}
+
+func TestTenantCheckJoin(t *testing.T) {
+	tq, store := newTestTenantQuotas(t, config.TenantQuota{MaxParticipants: 2})
+
+	require.ErrorIs(t, tq.checkJoin(tenantContext("a"), "b1", "p"), ErrRoomTenantMismatch)
+	// one participant in each room of tenant a
+	require.ErrorIs(t, tq.checkJoin(tenantContext("a"), "a1", "p"), ErrTenantParticipantQuotaExceeded)
+	require.NoError(t, tq.checkJoin(tenantContext("b"), "b1", "p"))
+	require.NoError(t, tq.checkJoin(tenantContext("b"), "shared", "p"))
+
+	// participants already in the room rejoin
+	require.NoError(t, store.StoreParticipant(context.Background(), "a1", &livekit.ParticipantInfo{Identity: "p"}))
+	require.NoError(t, tq.checkJoin(tenantContext("a"), "a1", "p"))
+}
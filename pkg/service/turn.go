@@ -15,14 +15,20 @@
 package service
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jxskiss/base62"
+	"github.com/pion/transport/v2/stdnet"
 	"github.com/pion/turn/v2"
 	"github.com/pkg/errors"
 
@@ -34,6 +40,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/utils"
 )
 
 const (
@@ -59,13 +66,23 @@ func NewTurnServer(conf *config.Config, authHandler turn.AuthHandler, standalone
 		AuthHandler:   authHandler,
 		LoggerFactory: pionlogger.NewLoggerFactory(logger.GetLogger()),
 	}
-	var relayAddrGen turn.RelayAddressGenerator = &turn.RelayAddressGeneratorPortRange{
+	portRangeRelayAddrGen := &turn.RelayAddressGeneratorPortRange{
 		RelayAddress: net.ParseIP(conf.RTC.NodeIP),
 		Address:      "0.0.0.0",
 		MinPort:      turnConf.RelayPortRangeStart,
 		MaxPort:      turnConf.RelayPortRangeEnd,
 		MaxRetries:   allocateRetries,
 	}
+	var listenConfig net.ListenConfig
+	if dscp := conf.RTC.DSCP.TURN; dscp != 0 {
+		n, err := stdnet.NewNet()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create TURN relay network")
+		}
+		portRangeRelayAddrGen.Net = &utils.DSCPNet{Net: n, DSCP: dscp}
+		listenConfig.Control = utils.DSCPControl(dscp)
+	}
+	var relayAddrGen turn.RelayAddressGenerator = portRangeRelayAddrGen
 	if standalone {
 		relayAddrGen = telemetry.NewRelayAddressGenerator(relayAddrGen)
 	}
@@ -89,14 +106,14 @@ func NewTurnServer(conf *config.Config, authHandler turn.AuthHandler, standalone
 				return nil, errors.Wrap(err, "TURN tls cert required")
 			}
 
-			tlsListener, err := tls.Listen("tcp4", "0.0.0.0:"+strconv.Itoa(turnConf.TLSPort),
-				&tls.Config{
-					MinVersion:   tls.VersionTLS12,
-					Certificates: []tls.Certificate{cert},
-				})
+			tcpListener, err := listenConfig.Listen(context.Background(), "tcp4", "0.0.0.0:"+strconv.Itoa(turnConf.TLSPort))
 			if err != nil {
 				return nil, errors.Wrap(err, "could not listen on TURN TCP port")
 			}
+			tlsListener := tls.NewListener(tcpListener, &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{cert},
+			})
 			if standalone {
 				tlsListener = telemetry.NewListener(tlsListener)
 			}
@@ -107,7 +124,7 @@ func NewTurnServer(conf *config.Config, authHandler turn.AuthHandler, standalone
 			}
 			serverConfig.ListenerConfigs = append(serverConfig.ListenerConfigs, listenerConfig)
 		} else {
-			tcpListener, err := net.Listen("tcp4", "0.0.0.0:"+strconv.Itoa(turnConf.TLSPort))
+			tcpListener, err := listenConfig.Listen(context.Background(), "tcp4", "0.0.0.0:"+strconv.Itoa(turnConf.TLSPort))
 			if err != nil {
 				return nil, errors.Wrap(err, "could not listen on TURN TCP port")
 			}
@@ -125,7 +142,7 @@ func NewTurnServer(conf *config.Config, authHandler turn.AuthHandler, standalone
 	}
 
 	if turnConf.UDPPort > 0 {
-		udpListener, err := net.ListenPacket("udp4", "0.0.0.0:"+strconv.Itoa(turnConf.UDPPort))
+		udpListener, err := listenConfig.ListenPacket(context.Background(), "udp4", "0.0.0.0:"+strconv.Itoa(turnConf.UDPPort))
 		if err != nil {
 			return nil, errors.Wrap(err, "could not listen on TURN UDP port")
 		}
@@ -152,30 +169,50 @@ func getTURNAuthHandlerFunc(handler *TURNAuthHandler) turn.AuthHandler {
 
 type TURNAuthHandler struct {
 	keyProvider auth.KeyProvider
+	// when set, credentials expire after this duration
+	credentialTTL time.Duration
 }
 
-func NewTURNAuthHandler(keyProvider auth.KeyProvider) *TURNAuthHandler {
+func NewTURNAuthHandler(keyProvider auth.KeyProvider, conf *config.Config) *TURNAuthHandler {
 	return &TURNAuthHandler{
-		keyProvider: keyProvider,
+		keyProvider:   keyProvider,
+		credentialTTL: conf.TURN.CredentialTTL,
 	}
 }
 
-func (h *TURNAuthHandler) CreateUsername(apiKey string, pID livekit.ParticipantID) string {
-	return base62.EncodeToString([]byte(fmt.Sprintf("%s|%s", apiKey, pID)))
-}
-
-func (h *TURNAuthHandler) CreatePassword(apiKey string, pID livekit.ParticipantID) (string, error) {
+// CreateCredentials returns username and password for a participant to use the embedded TURN server.
+// With a credential TTL, the expiry is part of the username and the password is an HMAC of the username,
+// similar to the TURN REST API (draft-uberti-behave-turn-rest), so that credentials cannot be used past expiry.
+func (h *TURNAuthHandler) CreateCredentials(apiKey string, pID livekit.ParticipantID) (string, string, error) {
 	secret := h.keyProvider.GetSecret(apiKey)
 	if secret == "" {
-		return "", ErrInvalidAPIKey
+		return "", "", ErrInvalidAPIKey
 	}
-	keyInput := fmt.Sprintf("%s|%s", secret, pID)
-	sum := sha256.Sum256([]byte(keyInput))
-	return base62.EncodeToString(sum[:]), nil
+
+	username := base62.EncodeToString([]byte(fmt.Sprintf("%s|%s", apiKey, pID)))
+	if h.credentialTTL <= 0 {
+		return username, createStaticTURNPassword(secret, pID), nil
+	}
+
+	username = fmt.Sprintf("%d:%s", time.Now().Add(h.credentialTTL).Unix(), username)
+	return username, createEphemeralTURNPassword(secret, username), nil
 }
 
 func (h *TURNAuthHandler) HandleAuth(username, realm string, srcAddr net.Addr) (key []byte, ok bool) {
-	decoded, err := base62.DecodeString(username)
+	encoded := username
+	if h.credentialTTL > 0 {
+		expiry, rest, found := strings.Cut(username, ":")
+		if !found {
+			return nil, false
+		}
+		expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+		if err != nil || time.Now().Unix() > expiresAt {
+			return nil, false
+		}
+		encoded = rest
+	}
+
+	decoded, err := base62.DecodeString(encoded)
 	if err != nil {
 		return nil, false
 	}
@@ -183,10 +220,36 @@ func (h *TURNAuthHandler) HandleAuth(username, realm string, srcAddr net.Addr) (
 	if len(parts) != 2 {
 		return nil, false
 	}
-	password, err := h.CreatePassword(parts[0], livekit.ParticipantID(parts[1]))
-	if err != nil {
-		logger.Warnw("could not create TURN password", err, "username", username)
+	secret := h.keyProvider.GetSecret(parts[0])
+	if secret == "" {
+		logger.Warnw("could not create TURN password", ErrInvalidAPIKey, "username", username)
 		return nil, false
 	}
+
+	var password string
+	if h.credentialTTL > 0 {
+		password = createEphemeralTURNPassword(secret, username)
+	} else {
+		password = createStaticTURNPassword(secret, livekit.ParticipantID(parts[1]))
+	}
 	return turn.GenerateAuthKey(username, LivekitRealm, password), true
 }
+
+func createStaticTURNPassword(secret string, pID livekit.ParticipantID) string {
+	keyInput := fmt.Sprintf("%s|%s", secret, pID)
+	sum := sha256.Sum256([]byte(keyInput))
+	return base62.EncodeToString(sum[:])
+}
+
+// createEphemeralTURNPassword signs username with the shared secret as specified by TURN REST API
+func createEphemeralTURNPassword(secret string, username string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(username))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// createTURNRESTCredentials creates time limited credentials for an external TURN server sharing secret with LiveKit
+func createTURNRESTCredentials(secret string, ttl time.Duration, pID livekit.ParticipantID) (string, string) {
+	username := fmt.Sprintf("%d:%s", time.Now().Add(ttl).Unix(), pID)
+	return username, createEphemeralTURNPassword(secret, username)
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/turn/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth/authfakes"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/service"
+)
+
+func TestTURNAuthHandler(t *testing.T) {
+	provider := &authfakes.FakeKeyProvider{}
+	provider.GetSecretReturns("somesecretencodedinbase62extendto32bytes")
+
+	t.Run("static credentials", func(t *testing.T) {
+		h := service.NewTURNAuthHandler(provider, &config.Config{})
+		username, password, err := h.CreateCredentials("APIabcdefg", "PA_participant")
+		require.NoError(t, err)
+		require.NotContains(t, username, ":")
+
+		key, ok := h.HandleAuth(username, service.LivekitRealm, nil)
+		require.True(t, ok)
+		require.Equal(t, turn.GenerateAuthKey(username, service.LivekitRealm, password), key)
+	})
+
+	t.Run("time limited credentials", func(t *testing.T) {
+		conf := &config.Config{TURN: config.TURNConfig{CredentialTTL: time.Minute}}
+		h := service.NewTURNAuthHandler(provider, conf)
+		username, password, err := h.CreateCredentials("APIabcdefg", "PA_participant")
+		require.NoError(t, err)
+
+		key, ok := h.HandleAuth(username, service.LivekitRealm, nil)
+		require.True(t, ok)
+		require.Equal(t, turn.GenerateAuthKey(username, service.LivekitRealm, password), key)
+
+		// expired
+		_, encoded, _ := strings.Cut(username, ":")
+		expired := fmt.Sprintf("%d:%s", time.Now().Add(-time.Second).Unix(), encoded)
+		_, ok = h.HandleAuth(expired, service.LivekitRealm, nil)
+		require.False(t, ok)
+
+		// allocations are not refreshed past expiry, even by a client that authenticated before
+		srcAddr := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+		expiring := fmt.Sprintf("%d:%s", time.Now().Add(time.Second).Unix(), encoded)
+		_, ok = h.HandleAuth(expiring, service.LivekitRealm, srcAddr)
+		require.True(t, ok)
+		time.Sleep(2 * time.Second)
+		_, ok = h.HandleAuth(expiring, service.LivekitRealm, srcAddr)
+		require.False(t, ok)
+
+		// credentials without expiry are not accepted
+		staticUsername, _, err := service.NewTURNAuthHandler(provider, &config.Config{}).CreateCredentials("APIabcdefg", "PA_participant")
+		require.NoError(t, err)
+		_, ok = h.HandleAuth(staticUsername, service.LivekitRealm, nil)
+		require.False(t, ok)
+	})
+}
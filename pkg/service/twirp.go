@@ -24,7 +24,10 @@ import (
 
 	"github.com/twitchtv/twirp"
 
+	"github.com/livekit/protocol/tracer"
+
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/livekit-server/pkg/telemetry/tracing"
 	"github.com/livekit/livekit-server/pkg/utils"
 )
 
@@ -210,3 +213,31 @@ func statusReporterErrorReceived(ctx context.Context, e twirp.Error) context.Con
 
 	return ctx
 }
+
+// TwirpTracing wraps each RPC in a server span, annotated with the room and participant of the request
+func TwirpTracing() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			svc, _ := twirp.ServiceName(ctx)
+			method, _ := twirp.MethodName(ctx)
+			ctx, span := tracer.Start(ctx, svc+"."+method, tracing.SpanKindServer,
+				tracing.Attr("rpc.system", "twirp"),
+				tracing.Attr("rpc.service", svc),
+				tracing.Attr("rpc.method", method),
+			)
+			defer span.End()
+			ctx = tracing.AppendToOutgoingContext(ctx)
+
+			if r, ok := req.(interface{ GetRoom() string }); ok && r.GetRoom() != "" {
+				tracing.SetAttributes(span, tracing.Attr("room", r.GetRoom()))
+			}
+			if r, ok := req.(interface{ GetIdentity() string }); ok && r.GetIdentity() != "" {
+				tracing.SetAttributes(span, tracing.Attr("participant", r.GetIdentity()))
+			}
+
+			res, err := next(ctx, req)
+			span.RecordError(err)
+			return res, err
+		}
+	}
+}
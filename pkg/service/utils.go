@@ -20,6 +20,8 @@ import (
 	"net"
 	"net/http"
 	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/livekit/protocol/logger"
 )
@@ -36,6 +38,11 @@ func handleError(w http.ResponseWriter, r *http.Request, status int, err error,
 	_, _ = w.Write([]byte(err.Error()))
 }
 
+// retryAfterSeconds formats a Retry-After value, rounding up to whole seconds
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(1, int((d+time.Second-1)/time.Second)))
+}
+
 func boolValue(s string) bool {
 	return s == "1" || s == "true"
 }
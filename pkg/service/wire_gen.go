@@ -96,7 +96,23 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	if err != nil {
 		return nil, err
 	}
-	roomService, err := NewRoomService(limitConfig, apiConfig, psrpcConfig, router, roomAllocator, objectStore, client, rtcEgressLauncher, topicFormatter, roomClient, participantClient)
+	roomDebugClient, err := NewRoomDebugClient(clientParams)
+	if err != nil {
+		return nil, err
+	}
+	publishedTrackClient, err := NewPublishedTrackClient(clientParams)
+	if err != nil {
+		return nil, err
+	}
+	roomAdminClient, err := NewRoomAdminClient(clientParams)
+	if err != nil {
+		return nil, err
+	}
+	participantAdminClient, err := NewParticipantAdminClient(clientParams)
+	if err != nil {
+		return nil, err
+	}
+	roomService, err := NewRoomService(limitConfig, apiConfig, psrpcConfig, router, roomAllocator, objectStore, client, rtcEgressLauncher, topicFormatter, roomClient, participantClient, roomDebugClient, publishedTrackClient, roomAdminClient, participantAdminClient)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +137,7 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 	clientConfigurationManager := createClientConfiguration()
 	agentStore := getAgentStore(objectStore)
 	timedVersionGenerator := utils.NewDefaultTimedVersionGenerator()
-	turnAuthHandler := NewTURNAuthHandler(keyProvider)
+	turnAuthHandler := NewTURNAuthHandler(keyProvider, conf)
 	forwardStats := createForwardStats(conf)
 	roomManager, err := NewLocalRoomManager(conf, objectStore, currentNode, router, telemetryService, clientConfigurationManager, client, agentStore, rtcEgressLauncher, timedVersionGenerator, turnAuthHandler, messageBus, forwardStats)
 	if err != nil {
@@ -36,10 +36,17 @@ const (
 	pingTimeout   = 2 * time.Second
 )
 
+// implemented by *websocket.Conn, toggles permessage-deflate for subsequent writes
+type writeCompressor interface {
+	EnableWriteCompression(enable bool)
+}
+
 type WSSignalConnection struct {
 	conn    types.WebsocketClient
 	mu      sync.Mutex
 	useJSON bool
+
+	compressionMinSize int
 }
 
 func NewWSSignalConnection(conn types.WebsocketClient) *WSSignalConnection {
@@ -52,6 +59,14 @@ func NewWSSignalConnection(conn types.WebsocketClient) *WSSignalConnection {
 	return wsc
 }
 
+// SetCompressionMinSize compresses outgoing messages of at least minSize bytes,
+// provided permessage-deflate was negotiated on the connection. 0 disables compression.
+func (c *WSSignalConnection) SetCompressionMinSize(minSize int) {
+	c.mu.Lock()
+	c.compressionMinSize = minSize
+	c.mu.Unlock()
+}
+
 func (c *WSSignalConnection) Close() error {
 	return c.conn.Close()
 }
@@ -143,7 +158,7 @@ func (c *WSSignalConnection) WriteResponse(msg *livekit.SignalResponse) (int, er
 		return 0, err
 	}
 
-	return len(payload), c.conn.WriteMessage(msgType, payload)
+	return len(payload), c.writeMessageLocked(msgType, payload)
 }
 
 func (c *WSSignalConnection) WriteServerMessage(msg *livekit.ServerMessage) (int, error) {
@@ -165,7 +180,14 @@ func (c *WSSignalConnection) WriteServerMessage(msg *livekit.ServerMessage) (int
 		return 0, err
 	}
 
-	return len(payload), c.conn.WriteMessage(msgType, payload)
+	return len(payload), c.writeMessageLocked(msgType, payload)
+}
+
+func (c *WSSignalConnection) writeMessageLocked(msgType int, payload []byte) error {
+	if wc, ok := c.conn.(writeCompressor); ok {
+		wc.EnableWriteCompression(c.compressionMinSize > 0 && len(payload) >= c.compressionMinSize)
+	}
+	return c.conn.WriteMessage(msgType, payload)
 }
 
 func (c *WSSignalConnection) pingWorker() {
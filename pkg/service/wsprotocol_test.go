@@ -0,0 +1,74 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+	"github.com/livekit/livekit-server/pkg/service"
+)
+
+type compressingWebsocketClient struct {
+	typesfakes.FakeWebsocketClient
+
+	compression []bool
+}
+
+func (c *compressingWebsocketClient) EnableWriteCompression(enable bool) {
+	c.compression = append(c.compression, enable)
+}
+
+func TestWSSignalConnectionCompression(t *testing.T) {
+	small := &livekit.SignalResponse{
+		Message: &livekit.SignalResponse_Pong{Pong: 1},
+	}
+	large := &livekit.SignalResponse{
+		Message: &livekit.SignalResponse_Update{
+			Update: &livekit.ParticipantUpdate{
+				Participants: []*livekit.ParticipantInfo{
+					{Identity: strings.Repeat("a", 2048)},
+				},
+			},
+		},
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := &compressingWebsocketClient{}
+		conn := service.NewWSSignalConnection(client)
+
+		_, err := conn.WriteResponse(large)
+		require.NoError(t, err)
+		require.Equal(t, []bool{false}, client.compression)
+		require.Equal(t, 1, client.WriteMessageCallCount())
+	})
+
+	t.Run("compresses messages above min size", func(t *testing.T) {
+		client := &compressingWebsocketClient{}
+		conn := service.NewWSSignalConnection(client)
+		conn.SetCompressionMinSize(1024)
+
+		_, err := conn.WriteResponse(small)
+		require.NoError(t, err)
+		_, err = conn.WriteResponse(large)
+		require.NoError(t, err)
+		require.Equal(t, []bool{false, true}, client.compression)
+	})
+}
@@ -29,6 +29,11 @@ type AudioLevelParams struct {
 	MinPercentile   uint8
 	ObserveDuration uint32
 	SmoothIntervals uint32
+	// hysteresis, an active speaker stays active till level drops below InactiveLevel,
+	// 0 (or less loud than ActiveLevel) to use ActiveLevel
+	InactiveLevel uint8
+	// number of observe windows an active speaker is held active after going quiet
+	ActiveHoldIntervals uint32
 }
 
 // keeps track of audio level for a participant
@@ -38,9 +43,13 @@ type AudioLevel struct {
 	minActiveDuration uint32
 	smoothFactor      float64
 	activeThreshold   float64
+	inactiveLevel     uint8
+	inactiveThreshold float64
 
 	lock          sync.Mutex
 	smoothedLevel float64
+	active        bool
+	holdRemaining uint32
 
 	loudestObservedLevel uint8
 	activeDuration       uint32 // ms
@@ -55,7 +64,12 @@ func NewAudioLevel(params AudioLevelParams) *AudioLevel {
 		smoothFactor:         1,
 		activeThreshold:      ConvertAudioLevel(float64(params.ActiveLevel)),
 		loudestObservedLevel: silentAudioLevel,
+		inactiveLevel:        params.ActiveLevel,
 	}
+	if params.InactiveLevel > params.ActiveLevel {
+		l.inactiveLevel = params.InactiveLevel
+	}
+	l.inactiveThreshold = ConvertAudioLevel(float64(l.inactiveLevel))
 
 	if l.params.SmoothIntervals > 0 {
 		// exponential moving average (EMA), same center of mass with simple moving average (SMA)
@@ -74,7 +88,12 @@ func (l *AudioLevel) Observe(level uint8, durationMs uint32, arrivalTime int64)
 
 	l.observedDuration += durationMs
 
-	if level <= l.params.ActiveLevel {
+	// once active, frames are counted against the (possibly) lower inactive level
+	activeLevel := l.params.ActiveLevel
+	if l.active {
+		activeLevel = l.inactiveLevel
+	}
+	if level <= activeLevel {
 		l.activeDuration += durationMs
 		if l.loudestObservedLevel > level {
 			l.loudestObservedLevel = level
@@ -97,6 +116,7 @@ func (l *AudioLevel) Observe(level uint8, durationMs uint32, arrivalTime int64)
 			smoothedLevel = l.smoothedLevel + (linearLevel-l.smoothedLevel)*l.smoothFactor
 		}
 		l.resetLocked(smoothedLevel)
+		l.updateActiveLocked()
 	}
 }
 
@@ -107,7 +127,27 @@ func (l *AudioLevel) GetLevel(now int64) (float64, bool) {
 
 	l.resetIfStaleLocked(now)
 
-	return l.smoothedLevel, l.smoothedLevel >= l.activeThreshold
+	return l.smoothedLevel, l.active
+}
+
+func (l *AudioLevel) updateActiveLocked() {
+	if !l.active {
+		l.active = l.smoothedLevel >= l.activeThreshold
+		l.holdRemaining = l.params.ActiveHoldIntervals
+		return
+	}
+
+	if l.smoothedLevel >= l.inactiveThreshold {
+		l.holdRemaining = l.params.ActiveHoldIntervals
+		return
+	}
+
+	if l.holdRemaining > 0 {
+		l.holdRemaining--
+		return
+	}
+
+	l.active = false
 }
 
 func (l *AudioLevel) resetIfStaleLocked(arrivalTime int64) {
@@ -116,6 +156,8 @@ func (l *AudioLevel) resetIfStaleLocked(arrivalTime int64) {
 	}
 
 	l.resetLocked(0.0)
+	l.active = false
+	l.holdRemaining = 0
 }
 
 func (l *AudioLevel) resetLocked(smoothedLevel float64) {
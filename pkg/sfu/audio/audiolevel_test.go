@@ -106,6 +106,65 @@ func TestAudioLevel(t *testing.T) {
 	})
 }
 
+func TestAudioLevelHysteresis(t *testing.T) {
+	t.Run("stays active above inactive level", func(t *testing.T) {
+		clock := time.Now()
+		a := NewAudioLevel(AudioLevelParams{
+			ActiveLevel:     defaultActiveLevel,
+			MinPercentile:   defaultPercentile,
+			ObserveDuration: defaultObserveDuration,
+			InactiveLevel:   40,
+		})
+
+		observeSamples(a, 25, 100, clock)
+		clock = clock.Add(100 * 20 * time.Millisecond)
+		_, noisy := a.GetLevel(clock.UnixNano())
+		require.True(t, noisy)
+
+		// quieter than active level, but louder than inactive level
+		observeSamples(a, 35, samplesPerBatch, clock)
+		clock = clock.Add(samplesPerBatch * 20 * time.Millisecond)
+		_, noisy = a.GetLevel(clock.UnixNano())
+		require.True(t, noisy)
+
+		observeSamples(a, 50, samplesPerBatch, clock)
+		clock = clock.Add(samplesPerBatch * 20 * time.Millisecond)
+		_, noisy = a.GetLevel(clock.UnixNano())
+		require.False(t, noisy)
+
+		// needs to cross active level again
+		observeSamples(a, 35, samplesPerBatch, clock)
+		clock = clock.Add(samplesPerBatch * 20 * time.Millisecond)
+		_, noisy = a.GetLevel(clock.UnixNano())
+		require.False(t, noisy)
+	})
+
+	t.Run("held active after going quiet", func(t *testing.T) {
+		clock := time.Now()
+		a := NewAudioLevel(AudioLevelParams{
+			ActiveLevel:         defaultActiveLevel,
+			MinPercentile:       defaultPercentile,
+			ObserveDuration:     defaultObserveDuration,
+			ActiveHoldIntervals: 1,
+		})
+
+		observeSamples(a, 25, 100, clock)
+		clock = clock.Add(100 * 20 * time.Millisecond)
+		_, noisy := a.GetLevel(clock.UnixNano())
+		require.True(t, noisy)
+
+		observeSamples(a, 50, samplesPerBatch, clock)
+		clock = clock.Add(samplesPerBatch * 20 * time.Millisecond)
+		_, noisy = a.GetLevel(clock.UnixNano())
+		require.True(t, noisy)
+
+		observeSamples(a, 50, samplesPerBatch, clock)
+		clock = clock.Add(samplesPerBatch * 20 * time.Millisecond)
+		_, noisy = a.GetLevel(clock.UnixNano())
+		require.False(t, noisy)
+	})
+}
+
 func createAudioLevel(activeLevel uint8, minPercentile uint8, observeDuration uint32) *AudioLevel {
 	return NewAudioLevel(AudioLevelParams{
 		ActiveLevel:     activeLevel,
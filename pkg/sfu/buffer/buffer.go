@@ -67,6 +67,9 @@ type ExtPacket struct {
 	RawPacket            []byte
 	DependencyDescriptor *ExtDependencyDescriptor
 	AbsCaptureTimeExt    *act.AbsCaptureTime
+	// holds RawPacket and Packet.Payload when read with ReadExtendedRef,
+	// down tracks forwarding the payload unmodified retain it instead of copying
+	PayloadRef *utils.PacketRef
 }
 
 // Buffer contains all packets
@@ -74,6 +77,8 @@ type Buffer struct {
 	sync.RWMutex
 	readCond        *sync.Cond
 	bucket          *bucket.Bucket
+	bucketInitSize  int
+	pool            *MemoryPool
 	nacker          *nack.NackQueue
 	maxVideoPkts    int
 	maxAudioPkts    int
@@ -130,6 +135,8 @@ type Buffer struct {
 	frameRateCalculator [DefaultMaxLayerSpatial + 1]FrameRateCalculator
 	frameRateCalculated bool
 
+	layerBitrate *LayerBitrate
+
 	packetNotFoundCount   atomic.Uint32
 	packetTooOldCount     atomic.Uint32
 	extPacketTooMuchCount atomic.Uint32
@@ -139,6 +146,9 @@ type Buffer struct {
 	rtxPktBuf           []byte
 
 	absCaptureTimeExtID uint8
+
+	// frame marking, temporal layer information of H.264
+	frameMarkingExtID uint8
 }
 
 // NewBuffer constructs a new Buffer
@@ -167,6 +177,15 @@ func (b *Buffer) SetLogger(logger logger.Logger) {
 	}
 }
 
+// SetMemoryPool makes the buffer account its packet memory in pool, pool also enables shrinking
+// the buffer when the packet rate of the track drops
+func (b *Buffer) SetMemoryPool(pool *MemoryPool) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.pool = pool
+}
+
 func (b *Buffer) SetPaused(paused bool) {
 	b.Lock()
 	defer b.Unlock()
@@ -196,6 +215,27 @@ func (b *Buffer) SetAudioLossProxying(enable bool) {
 	b.enableAudioLossProxying = enable
 }
 
+// SetLayerBitrateParams enables measurement of per layer bitrates
+func (b *Buffer) SetLayerBitrateParams(params LayerBitrateParams) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.layerBitrate = NewLayerBitrate(params)
+}
+
+// GetLayerBitrates returns measured bitrates of layers, see LayerBitrate.Bitrates,
+// false if layer bitrates are not measured
+func (b *Buffer) GetLayerBitrates() ([DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]int64, bool) {
+	b.RLock()
+	layerBitrate := b.layerBitrate
+	b.RUnlock()
+
+	if layerBitrate == nil {
+		return [DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]int64{}, false
+	}
+	return layerBitrate.Bitrates(time.Now()), true
+}
+
 func (b *Buffer) Bind(params webrtc.RTPParameters, codec webrtc.RTPCodecCapability, bitrates int) {
 	b.Lock()
 	defer b.Unlock()
@@ -246,16 +286,21 @@ func (b *Buffer) Bind(params webrtc.RTPParameters, codec webrtc.RTPCodecCapabili
 
 		case act.AbsCaptureTimeURI:
 			b.absCaptureTimeExtID = uint8(ext.ID)
+
+		case FrameMarkingURI:
+			if strings.EqualFold(codec.MimeType, webrtc.MimeTypeH264) {
+				b.frameMarkingExtID = uint8(ext.ID)
+			}
 		}
 	}
 
 	switch {
 	case strings.HasPrefix(b.mime, "audio/"):
 		b.codecType = webrtc.RTPCodecTypeAudio
-		b.bucket = bucket.NewBucket(InitPacketBufferSizeAudio)
+		b.newBucketLocked(InitPacketBufferSizeAudio)
 	case strings.HasPrefix(b.mime, "video/"):
 		b.codecType = webrtc.RTPCodecTypeVideo
-		b.bucket = bucket.NewBucket(InitPacketBufferSizeVideo)
+		b.newBucketLocked(InitPacketBufferSizeVideo)
 		if b.frameRateCalculator[0] == nil {
 			if strings.EqualFold(codec.MimeType, webrtc.MimeTypeVP8) {
 				b.frameRateCalculator[0] = NewFrameRateCalculatorVP8(b.clockRate, b.logger)
@@ -271,7 +316,7 @@ func (b *Buffer) Bind(params webrtc.RTPParameters, codec webrtc.RTPCodecCapabili
 		if bitrates > 0 {
 			pps := bitrates / 8 / 1200
 			for pps > b.bucket.Capacity() {
-				if b.bucket.Grow() >= b.maxVideoPkts {
+				if cap, ok := b.growBucketLocked(); !ok || cap >= b.maxVideoPkts {
 					break
 				}
 			}
@@ -469,10 +514,29 @@ func (b *Buffer) ReadExtended(buf []byte) (*ExtPacket, error) {
 	}
 }
 
+// ReadExtendedRef is ReadExtended into a pooled packet buffer referenced by the returned packet,
+// the caller releases ExtPacket.PayloadRef when done with the packet
+func (b *Buffer) ReadExtendedRef() (*ExtPacket, error) {
+	ref := utils.NewPacketRef()
+	ep, err := b.ReadExtended(ref.Bytes())
+	if err != nil {
+		ref.Release()
+		return nil, err
+	}
+	ep.PayloadRef = ref
+	return ep, nil
+}
+
 func (b *Buffer) Close() error {
 	b.closeOnce.Do(func() {
 		b.closed.Store(true)
 
+		b.Lock()
+		if b.pool != nil {
+			b.pool.remove(b)
+		}
+		b.Unlock()
+
 		if b.rtpStats != nil {
 			b.rtpStats.Stop()
 			b.logger.Debugw("rtp stats",
@@ -492,6 +556,14 @@ func (b *Buffer) Close() error {
 	return nil
 }
 
+// IsBound returns whether a track has taken the buffer into use
+func (b *Buffer) IsBound() bool {
+	b.RLock()
+	defer b.RUnlock()
+
+	return b.bound
+}
+
 func (b *Buffer) OnClose(fn func()) {
 	b.Lock()
 	b.onClose = fn
@@ -532,6 +604,29 @@ func (b *Buffer) SendPLI(force bool) {
 	}
 }
 
+// UpdatePliRequest records a key frame request of a down track, coalesced when answered by a PLI sent for another request
+func (b *Buffer) UpdatePliRequest(coalesced bool) {
+	b.RLock()
+	rtpStats := b.rtpStats
+	b.RUnlock()
+
+	if rtpStats != nil {
+		rtpStats.UpdatePliRequest(coalesced)
+	}
+}
+
+// PliRequests returns number of key frame requests of down tracks and how many of those were coalesced
+func (b *Buffer) PliRequests() (uint32, uint32) {
+	b.RLock()
+	rtpStats := b.rtpStats
+	b.RUnlock()
+
+	if rtpStats == nil {
+		return 0, 0
+	}
+	return rtpStats.PliRequests()
+}
+
 func (b *Buffer) SetRTT(rtt uint32) {
 	b.Lock()
 	defer b.Unlock()
@@ -549,6 +644,24 @@ func (b *Buffer) SetRTT(rtt uint32) {
 	}
 }
 
+// SetRTTFromXR sets a round trip time to the sender measured using RTCP XR (RRTR/DLRR).
+func (b *Buffer) SetRTTFromXR(rtt uint32) {
+	b.Lock()
+	defer b.Unlock()
+
+	if rtt == 0 {
+		return
+	}
+
+	if b.nacker != nil {
+		b.nacker.SetRTT(rtt)
+	}
+
+	if b.rtpStats != nil {
+		b.rtpStats.UpdateRttFromXR(rtt)
+	}
+}
+
 func (b *Buffer) calc(rawPkt []byte, rtpPacket *rtp.Packet, arrivalTime int64, isRTX bool) {
 	defer func() {
 		b.doNACKs()
@@ -661,6 +774,10 @@ func (b *Buffer) calc(rawPkt []byte, rtpPacket *rtp.Packet, arrivalTime int64, i
 	}
 
 	b.doFpsCalc(ep)
+
+	if b.layerBitrate != nil && !isRTX && len(ep.Packet.Payload) != 0 {
+		b.layerBitrate.Update(ep.VideoLayer, len(rawPkt), time.Unix(0, arrivalTime))
+	}
 }
 
 func (b *Buffer) patchExtPacket(ep *ExtPacket, buf []byte) *ExtPacket {
@@ -757,6 +874,7 @@ func (b *Buffer) updateStreamState(p *rtp.Packet, arrivalTime int64) RTPFlowStat
 		p.Header.MarshalSize(),
 		len(p.Payload),
 		int(p.PaddingSize),
+		b.mime == "audio/opus" && IsOpusDTXPacket(p.Payload),
 	)
 
 	if b.nacker != nil {
@@ -781,7 +899,7 @@ func (b *Buffer) processHeaderExtensions(p *rtp.Packet, arrivalTime int64, isRTX
 		if e := p.GetExtension(b.audioLevelExtID); e != nil {
 			ext := rtp.AudioLevelExtension{}
 			if err := ext.Unmarshal(e); err == nil {
-				if (p.Timestamp - b.latestTSForAudioLevel) < (1 << 31) {
+				if utils.IsNewer(p.Timestamp, b.latestTSForAudioLevel) {
 					duration := (int64(p.Timestamp) - int64(b.latestTSForAudioLevel)) * 1e3 / int64(b.clockRate)
 					if duration > 0 {
 						b.audioLevel.Observe(ext.Level, uint32(duration), arrivalTime)
@@ -858,6 +976,16 @@ func (b *Buffer) getExtPacket(rtpPacket *rtp.Packet, arrivalTime int64, flowStat
 	case "video/h264":
 		ep.KeyFrame = IsH264KeyFrame(rtpPacket.Payload)
 		ep.Spatial = InvalidLayerSpatial // h.264 don't have spatial scalability, reset to invalid
+		if b.frameMarkingExtID != 0 {
+			if e := rtpPacket.GetExtension(b.frameMarkingExtID); e != nil {
+				fm := FrameMarking{}
+				if err := fm.Unmarshal(e); err == nil {
+					// temporal scalability (SVC) of H.264 is signalled only through frame marking
+					ep.Temporal = int32(fm.TID)
+					ep.Payload = fm
+				}
+			}
+		}
 
 	case "video/av1":
 		ep.KeyFrame = IsAV1KeyFrame(rtpPacket.Payload)
@@ -920,7 +1048,8 @@ func (b *Buffer) mayGrowBucket() {
 	if b.codecType == webrtc.RTPCodecTypeAudio {
 		maxPkts = b.maxAudioPkts
 	}
-	if cap >= maxPkts {
+	// buffers in a memory pool are also checked for shrinking
+	if cap >= maxPkts && b.pool == nil {
 		return
 	}
 	oldCap := cap
@@ -928,8 +1057,22 @@ func (b *Buffer) mayGrowBucket() {
 		duration := deltaInfo.EndTime.Sub(deltaInfo.StartTime)
 		if duration > 500*time.Millisecond {
 			pps := int(time.Duration(deltaInfo.Packets) * time.Second / duration)
+			if b.pool != nil {
+				// a second of packets is enough, give back memory when the packet rate dropped to less
+				// than half of that, the margin avoids resizing back and forth on small rate changes
+				target := max((pps+b.bucketInitSize-1)/b.bucketInitSize*b.bucketInitSize, b.bucketInitSize)
+				if target*2 <= cap {
+					b.replaceBucketLocked(target)
+					b.logger.Debugw("shrink bucket", "from", oldCap, "to", b.bucket.Capacity(), "pps", pps)
+					return
+				}
+			}
 			for pps > cap && cap < maxPkts {
-				cap = b.bucket.Grow()
+				var ok bool
+				if cap, ok = b.growBucketLocked(); !ok {
+					b.logger.Debugw("packet buffer memory limit reached, not growing bucket", "capacity", cap, "pps", pps)
+					break
+				}
 			}
 			if cap > oldCap {
 				b.logger.Debugw("grow bucket", "from", oldCap, "to", cap, "pps", pps)
@@ -938,6 +1081,53 @@ func (b *Buffer) mayGrowBucket() {
 	}
 }
 
+func (b *Buffer) newBucketLocked(initSize int) {
+	b.bucket = bucket.NewBucket(initSize)
+	b.bucketInitSize = initSize
+	if b.pool != nil && !b.closed.Load() {
+		// a track always gets its initial buffer, the pool makes room by shrinking others
+		b.pool.acquire(b, initSize, true)
+	}
+}
+
+// growBucketLocked grows the bucket by its initial size when the memory pool has room,
+// returns the resulting capacity
+func (b *Buffer) growBucketLocked() (int, bool) {
+	if b.pool != nil && (b.closed.Load() || !b.pool.acquire(b, b.bucketInitSize, false)) {
+		return b.bucket.Capacity(), false
+	}
+	return b.bucket.Grow(), true
+}
+
+// replaceBucketLocked swaps the bucket for a smaller one holding size packets, the packet history is lost.
+// Packets handed out before keep referencing the old bucket, which is collected once they are released.
+func (b *Buffer) replaceBucketLocked(size int) {
+	oldCap := b.bucket.Capacity()
+	b.bucket = bucket.NewBucket(b.bucketInitSize)
+	for b.bucket.Capacity() < size {
+		b.bucket.Grow()
+	}
+	if b.pool != nil {
+		b.pool.release(b, oldCap-b.bucket.Capacity())
+	}
+}
+
+// shrinkBucket shrinks the bucket to its initial size to free memory of the pool,
+// returns whether anything was freed
+func (b *Buffer) shrinkBucket() bool {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.closed.Load() || b.bucket == nil || b.bucket.Capacity() <= b.bucketInitSize {
+		return false
+	}
+
+	oldCap := b.bucket.Capacity()
+	b.replaceBucketLocked(b.bucketInitSize)
+	b.logger.Infow("shrink bucket to free packet buffer memory", "from", oldCap, "to", b.bucket.Capacity())
+	return true
+}
+
 func (b *Buffer) buildNACKPacket() ([]rtcp.Packet, int) {
 	if nacks, numSeqNumsNacked := b.nacker.Pairs(); len(nacks) > 0 {
 		pkts := []rtcp.Packet{&rtcp.TransportLayerNack{
@@ -1092,6 +1282,18 @@ func (b *Buffer) GetStats() *livekit.RTPStats {
 	return b.rtpStats.ToProto()
 }
 
+// SeedRTPStats carries over stats of the stream at the node a participant migrated from, buffer must be bound
+func (b *Buffer) SeedRTPStats(stats *livekit.RTPStats) {
+	b.RLock()
+	defer b.RUnlock()
+
+	if b.rtpStats == nil {
+		return
+	}
+
+	b.rtpStats.SeedProto(stats)
+}
+
 func (b *Buffer) GetDeltaStats() *StreamStatsWithLayers {
 	b.RLock()
 	defer b.RUnlock()
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/livekit/mediatransportutil/pkg/nack"
+	"github.com/livekit/mediatransportutil/pkg/twcc"
 )
 
 var vp8Codec = webrtc.RTPCodecParameters{
@@ -324,3 +325,43 @@ func BenchmarkMemcpu(b *testing.B) {
 	}
 
 }
+
+func TestTWCCAudio(t *testing.T) {
+	buff := NewBuffer(123, 1, 1)
+	require.NotNil(t, buff)
+	buff.OnRtcpFeedback(func(_ []rtcp.Packet) {})
+	buff.Bind(webrtc.RTPParameters{
+		HeaderExtensions: nil,
+		Codecs:           []webrtc.RTPCodecParameters{opusCodec},
+	}, opusCodec.RTPCodecCapability, 0)
+
+	var feedback []rtcp.Packet
+	responder := twcc.NewTransportWideCCResponder()
+	responder.OnFeedback(func(pkts []rtcp.Packet) {
+		feedback = append(feedback, pkts...)
+	})
+	buff.SetTWCCAndExtID(responder, 3)
+
+	for sn := uint16(0); sn < 30; sn++ {
+		p := rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         true,
+				PayloadType:    111,
+				SequenceNumber: sn,
+				Timestamp:      uint32(sn) * 960,
+				SSRC:           123,
+			},
+			Payload: []byte{1},
+		}
+		require.NoError(t, p.Header.SetExtension(3, []byte{byte(sn >> 8), byte(sn)}))
+		buf, err := p.Marshal()
+		require.NoError(t, err)
+		_, err = buff.Write(buf)
+		require.NoError(t, err)
+	}
+
+	require.NotEmpty(t, feedback)
+	_, ok := feedback[0].(*rtcp.TransportLayerCC)
+	require.True(t, ok)
+}
@@ -24,12 +24,16 @@ import (
 type FactoryOfBufferFactory struct {
 	trackingPacketsVideo int
 	trackingPacketsAudio int
+	pool                 *MemoryPool
 }
 
-func NewFactoryOfBufferFactory(trackingPacketsVideo int, trackingPacketsAudio int) *FactoryOfBufferFactory {
+// NewFactoryOfBufferFactory creates buffer factories whose buffers hold up to trackingPacketsVideo/Audio packets,
+// when pool is not nil the buffers share its memory limit
+func NewFactoryOfBufferFactory(trackingPacketsVideo int, trackingPacketsAudio int, pool *MemoryPool) *FactoryOfBufferFactory {
 	return &FactoryOfBufferFactory{
 		trackingPacketsVideo: trackingPacketsVideo,
 		trackingPacketsAudio: trackingPacketsAudio,
+		pool:                 pool,
 	}
 }
 
@@ -37,6 +41,7 @@ func (f *FactoryOfBufferFactory) CreateBufferFactory() *Factory {
 	return &Factory{
 		trackingPacketsVideo: f.trackingPacketsVideo,
 		trackingPacketsAudio: f.trackingPacketsAudio,
+		pool:                 f.pool,
 		rtpBuffers:           make(map[uint32]*Buffer),
 		rtcpReaders:          make(map[uint32]*RTCPReader),
 		rtxPair:              make(map[uint32]uint32),
@@ -47,14 +52,31 @@ type Factory struct {
 	sync.RWMutex
 	trackingPacketsVideo int
 	trackingPacketsAudio int
+	pool                 *MemoryPool
 	rtpBuffers           map[uint32]*Buffer
 	rtcpReaders          map[uint32]*RTCPReader
 	rtxPair              map[uint32]uint32 // repair -> base
+	onSSRCCollision      func(ssrc uint32)
+}
+
+// OnSSRCCollision sets a callback for a stream claiming an SSRC while the buffer of another stream
+// with that SSRC is still in use, the new stream gets a buffer of its own
+func (f *Factory) OnSSRCCollision(fn func(ssrc uint32)) {
+	f.Lock()
+	f.onSSRCCollision = fn
+	f.Unlock()
 }
 
 func (f *Factory) GetOrNew(packetType packetio.BufferPacketType, ssrc uint32) io.ReadWriteCloser {
+	var onSSRCCollision func(ssrc uint32)
 	f.Lock()
-	defer f.Unlock()
+	defer func() {
+		f.Unlock()
+		if onSSRCCollision != nil {
+			onSSRCCollision(ssrc)
+		}
+	}()
+
 	switch packetType {
 	case packetio.RTCPBufferPacket:
 		if reader, ok := f.rtcpReaders[ssrc]; ok {
@@ -70,9 +92,20 @@ func (f *Factory) GetOrNew(packetType packetio.BufferPacketType, ssrc uint32) io
 		return reader
 	case packetio.RTPBufferPacket:
 		if reader, ok := f.rtpBuffers[ssrc]; ok {
-			return reader
+			if !reader.IsBound() {
+				return reader
+			}
+
+			// Buffers are created for a stream on its first packet and bound by the track the stream belongs to,
+			// a bound buffer being asked for means another stream uses the same SSRC. Sharing the buffer would
+			// corrupt stats and forwarding state of both, so the SSRC is remapped to a new buffer for the new
+			// stream, the track of the old stream keeps its buffer outside the index.
+			onSSRCCollision = f.onSSRCCollision
 		}
 		buffer := NewBuffer(ssrc, f.trackingPacketsVideo, f.trackingPacketsAudio)
+		if f.pool != nil {
+			buffer.SetMemoryPool(f.pool)
+		}
 		f.rtpBuffers[ssrc] = buffer
 		for repair, base := range f.rtxPair {
 			if repair == ssrc {
@@ -91,8 +124,11 @@ func (f *Factory) GetOrNew(packetType packetio.BufferPacketType, ssrc uint32) io
 		}
 		buffer.OnClose(func() {
 			f.Lock()
-			delete(f.rtpBuffers, ssrc)
-			delete(f.rtxPair, ssrc)
+			// the SSRC may have been remapped to the buffer of a colliding stream
+			if f.rtpBuffers[ssrc] == buffer {
+				delete(f.rtpBuffers, ssrc)
+				delete(f.rtxPair, ssrc)
+			}
 			f.Unlock()
 		})
 		return buffer
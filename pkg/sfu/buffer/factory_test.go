@@ -0,0 +1,50 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"testing"
+
+	"github.com/pion/transport/v2/packetio"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFactorySSRCCollision(t *testing.T) {
+	f := NewFactoryOfBufferFactory(500, 200, nil).CreateBufferFactory()
+	var collisions []uint32
+	f.OnSSRCCollision(func(ssrc uint32) {
+		collisions = append(collisions, ssrc)
+	})
+
+	first := f.GetOrNew(packetio.RTPBufferPacket, 123).(*Buffer)
+	// same stream asking again before its track is bound
+	require.Same(t, first, f.GetOrNew(packetio.RTPBufferPacket, 123))
+	require.Empty(t, collisions)
+
+	first.Bind(webrtc.RTPParameters{Codecs: []webrtc.RTPCodecParameters{opusCodec}}, opusCodec.RTPCodecCapability, 0)
+
+	second := f.GetOrNew(packetio.RTPBufferPacket, 123).(*Buffer)
+	require.NotSame(t, first, second)
+	require.Equal(t, []uint32{123}, collisions)
+	require.Same(t, second, f.GetBuffer(123))
+
+	// closing the old stream does not drop the remapped buffer
+	require.NoError(t, first.Close())
+	require.Same(t, second, f.GetBuffer(123))
+
+	require.NoError(t, second.Close())
+	require.Nil(t, f.GetBuffer(123))
+}
@@ -0,0 +1,87 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+const FrameMarkingURI = "urn:ietf:params:rtp-hdrext:framemarking"
+
+// FrameMarking is a helper to get temporal layer information of codecs without it in their payload descriptor (H.264),
+// from the frame marking RTP header extension (draft-ietf-avtext-framemarking).
+/*
+	Short form, non-scalable streams
+			0 1 2 3 4 5 6 7
+			+-+-+-+-+-+-+-+-+
+			|S|E|I|D|0 0 0 0|
+			+-+-+-+-+-+-+-+-+
+
+	Long form, scalable streams
+			0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7 0 1 2 3 4 5 6 7
+			+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+			|S|E|I|D|B| TID |      LID      |   TL0PICIDX   |
+			+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+	TL0PICIDX is optional, LID is present only with TL0PICIDX.
+*/
+type FrameMarking struct {
+	S bool // start of frame
+	E bool // end of frame
+	I bool // independent frame
+	D bool // discardable frame
+	B bool // base layer sync, frame depends only on base temporal layer, i. e. a switching point
+
+	TID       uint8 /* 3 bits temporal layer idx */
+	LID       uint8 /* 8 bits spatial/quality layer idx */
+	TL0PICIDX uint8 /* 8 bits temporal level zero index */
+
+	// IsScalable is set for the long form, B and TID are valid only then
+	IsScalable bool
+}
+
+// Unmarshal parses the frame marking header extension payload
+func (f *FrameMarking) Unmarshal(payload []byte) error {
+	if payload == nil {
+		return errNilPacket
+	}
+	if len(payload) < 1 {
+		return errShortPacket
+	}
+
+	f.S = payload[0]&0x80 > 0
+	f.E = payload[0]&0x40 > 0
+	f.I = payload[0]&0x20 > 0
+	f.D = payload[0]&0x10 > 0
+	if len(payload) == 1 {
+		// short form, remaining bits must be zero
+		if payload[0]&0x0f != 0 {
+			return errInvalidPacket
+		}
+		f.B = false
+		f.TID = 0
+		f.LID = 0
+		f.TL0PICIDX = 0
+		f.IsScalable = false
+		return nil
+	}
+
+	f.B = payload[0]&0x08 > 0
+	f.TID = payload[0] & 0x07
+	f.LID = payload[1]
+	if len(payload) > 2 {
+		f.TL0PICIDX = payload[2]
+	} else {
+		f.TL0PICIDX = 0
+	}
+	f.IsScalable = true
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameMarking_Unmarshal(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		fm := FrameMarking{}
+		require.Error(t, fm.Unmarshal(nil))
+		require.Error(t, fm.Unmarshal([]byte{}))
+	})
+
+	t.Run("short form", func(t *testing.T) {
+		fm := FrameMarking{}
+		require.NoError(t, fm.Unmarshal([]byte{0xa0}))
+		require.Equal(t, FrameMarking{S: true, I: true}, fm)
+
+		// reserved bits set
+		require.Error(t, fm.Unmarshal([]byte{0xa1}))
+	})
+
+	t.Run("long form", func(t *testing.T) {
+		fm := FrameMarking{}
+		require.NoError(t, fm.Unmarshal([]byte{0x5a, 0x01, 0x07}))
+		require.Equal(t, FrameMarking{E: true, D: true, B: true, TID: 2, LID: 1, TL0PICIDX: 7, IsScalable: true}, fm)
+
+		// without TL0PICIDX
+		require.NoError(t, fm.Unmarshal([]byte{0x81, 0x00}))
+		require.Equal(t, FrameMarking{S: true, TID: 1, IsScalable: true}, fm)
+	})
+}
@@ -245,6 +245,13 @@ func VPxPictureIdSizeDiff(mBit1 bool, mBit2 bool) int {
 
 // -------------------------------------
 
+// IsOpusDTXPacket detects if opus payload is a discontinuous transmission (DTX) packet.
+// During silence, an opus encoder with DTX enabled sends only a TOC byte (and possibly a frame count byte)
+// every 400 ms instead of a packet every frame, the decoder generates comfort noise in the gaps.
+func IsOpusDTXPacket(payload []byte) bool {
+	return len(payload) > 0 && len(payload) <= 2
+}
+
 // IsH264KeyFrame detects if h264 payload is a keyframe
 // this code was taken from https://github.com/jech/galene/blob/codecs/rtpconn/rtpreader.go#L45
 // all credits belongs to Juliusz Chroboczek @jech and the awesome Galene SFU
@@ -108,3 +108,10 @@ func TestVP8Helper_Unmarshal(t *testing.T) {
 }
 
 // ------------------------------------------
+
+func TestIsOpusDTXPacket(t *testing.T) {
+	require.False(t, IsOpusDTXPacket(nil))
+	require.True(t, IsOpusDTXPacket([]byte{0xf8}))
+	require.True(t, IsOpusDTXPacket([]byte{0xfb, 0x01}))
+	require.False(t, IsOpusDTXPacket([]byte{0xf8, 0xff, 0xfe}))
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+var (
+	// 1ms, 10ms, 30ms, 50ms, 70ms, 100ms, 300ms, 600ms, 1s
+	DefaultJitterHistogramBucketsUs = []float64{1000, 10000, 30000, 50000, 70000, 100000, 300000, 600000, 1000000}
+	DefaultRTTHistogramBucketsMs    = []float64{50, 100, 150, 200, 250, 500, 750, 1000, 5000, 10000}
+)
+
+type HistogramBucket struct {
+	UpperBound float64 // inclusive, last bucket is +Inf
+	Count      uint32
+}
+
+// Histogram counts samples in buckets with given upper bounds.
+// Not thread safe, callers are expected to synchronise access.
+type Histogram struct {
+	upperBounds []float64
+	counts      []uint32
+}
+
+func NewHistogram(upperBounds []float64) *Histogram {
+	bounds := append([]float64{}, upperBounds...)
+	sort.Float64s(bounds)
+	return &Histogram{
+		upperBounds: bounds,
+		counts:      make([]uint32, len(bounds)+1),
+	}
+}
+
+func (h *Histogram) Add(val float64) {
+	h.counts[sort.SearchFloat64s(h.upperBounds, val)]++
+}
+
+func (h *Histogram) Buckets() []HistogramBucket {
+	buckets := make([]HistogramBucket, 0, len(h.counts))
+	for i, count := range h.counts {
+		upperBound := math.Inf(1)
+		if i < len(h.upperBounds) {
+			upperBound = h.upperBounds[i]
+		}
+		buckets = append(buckets, HistogramBucket{UpperBound: upperBound, Count: count})
+	}
+	return buckets
+}
+
+func (h *Histogram) clone() *Histogram {
+	return &Histogram{
+		upperBounds: h.upperBounds,
+		counts:      append([]uint32{}, h.counts...),
+	}
+}
+
+func (h *Histogram) String() string {
+	str := "["
+	first := true
+	for _, b := range h.Buckets() {
+		if b.Count == 0 {
+			continue
+		}
+		if !first {
+			str += ", "
+		}
+		first = false
+		str += fmt.Sprintf("%v:%d", b.UpperBound, b.Count)
+	}
+	str += "]"
+	return str
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]float64{100, 10, 50})
+	for _, val := range []float64{0, 10, 11, 50, 99, 100, 101, 1000} {
+		h.Add(val)
+	}
+
+	require.Equal(t, []HistogramBucket{
+		{UpperBound: 10, Count: 2},
+		{UpperBound: 50, Count: 2},
+		{UpperBound: 100, Count: 2},
+		{UpperBound: math.Inf(1), Count: 2},
+	}, h.Buckets())
+	require.Equal(t, "[10:2, 50:2, 100:2, +Inf:2]", h.String())
+
+	// clone does not share counts
+	c := h.clone()
+	c.Add(0)
+	require.Equal(t, uint32(2), h.Buckets()[0].Count)
+	require.Equal(t, uint32(3), c.Buckets()[0].Count)
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// LayerBitrateParams configures the exponentially weighted moving average of per layer bitrates
+type LayerBitrateParams struct {
+	// bytes are accumulated over this interval into a bitrate sample
+	SampleInterval time.Duration
+	// duration after which the weight of a sample halves
+	HalfLife time.Duration
+}
+
+var DefaultLayerBitrateParams = LayerBitrateParams{
+	SampleInterval: 250 * time.Millisecond,
+	HalfLife:       time.Second,
+}
+
+// LayerBitrate measures bitrate of each spatial/temporal layer of a stream
+type LayerBitrate struct {
+	params LayerBitrateParams
+
+	lock         sync.Mutex
+	lastSampleAt time.Time
+	bytes        [DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]int64
+	bitrates     [DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]float64
+	isSampled    [DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]bool
+}
+
+func NewLayerBitrate(params LayerBitrateParams) *LayerBitrate {
+	if params.SampleInterval <= 0 {
+		params.SampleInterval = DefaultLayerBitrateParams.SampleInterval
+	}
+	if params.HalfLife <= 0 {
+		params.HalfLife = DefaultLayerBitrateParams.HalfLife
+	}
+	return &LayerBitrate{
+		params: params,
+	}
+}
+
+// Update adds a packet of given size, streams without spatial layers are accounted as spatial layer 0
+func (l *LayerBitrate) Update(layer VideoLayer, size int, at time.Time) {
+	spatial, temporal := max(layer.Spatial, 0), max(layer.Temporal, 0)
+	if int(spatial) >= len(l.bytes) || int(temporal) >= len(l.bytes[0]) {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.lastSampleAt.IsZero() {
+		l.lastSampleAt = at
+	}
+	l.sampleLocked(at)
+	l.bytes[spatial][temporal] += int64(size)
+}
+
+// Bitrates returns the average bitrate of each layer accumulated with lower temporal layers
+// and lower spatial layers, i. e. the bitrate needed to forward a layer
+func (l *LayerBitrate) Bitrates(at time.Time) [DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]int64 {
+	var brs [DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]int64
+
+	l.lock.Lock()
+	if l.lastSampleAt.IsZero() {
+		l.lock.Unlock()
+		return brs
+	}
+	l.sampleLocked(at)
+	for i := range l.bitrates {
+		for j := range l.bitrates[i] {
+			brs[i][j] = int64(math.Round(l.bitrates[i][j]))
+		}
+	}
+	l.lock.Unlock()
+
+	for i := range brs {
+		for j := len(brs[i]) - 1; j >= 1; j-- {
+			if brs[i][j] != 0 {
+				for k := j - 1; k >= 0; k-- {
+					brs[i][j] += brs[i][k]
+				}
+			}
+		}
+
+		// clear layers above a missing layer
+		for j := range brs[i] {
+			if brs[i][j] == 0 {
+				for k := j + 1; k < len(brs[i]); k++ {
+					brs[i][k] = 0
+				}
+				break
+			}
+		}
+	}
+
+	for i := len(brs) - 1; i >= 1; i-- {
+		for j := range brs[i] {
+			if brs[i][j] != 0 {
+				for k := i - 1; k >= 0; k-- {
+					brs[i][j] += brs[k][j]
+				}
+			}
+		}
+	}
+
+	return brs
+}
+
+func (l *LayerBitrate) sampleLocked(at time.Time) {
+	elapsed := at.Sub(l.lastSampleAt)
+	if elapsed < l.params.SampleInterval {
+		return
+	}
+
+	alpha := 1.0 - math.Exp2(-elapsed.Seconds()/l.params.HalfLife.Seconds())
+	for i := range l.bytes {
+		for j := range l.bytes[i] {
+			bitrate := float64(l.bytes[i][j]*8) / elapsed.Seconds()
+			if !l.isSampled[i][j] {
+				if l.bytes[i][j] == 0 {
+					continue
+				}
+				// seed with first sample to not ramp up from zero
+				l.bitrates[i][j] = bitrate
+				l.isSampled[i][j] = true
+			} else {
+				l.bitrates[i][j] += alpha * (bitrate - l.bitrates[i][j])
+			}
+			l.bytes[i][j] = 0
+		}
+	}
+	l.lastSampleAt = at
+}
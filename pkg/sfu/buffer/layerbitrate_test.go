@@ -0,0 +1,75 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayerBitrate(t *testing.T) {
+	lb := NewLayerBitrate(LayerBitrateParams{
+		SampleInterval: 100 * time.Millisecond,
+		HalfLife:       time.Second,
+	})
+
+	now := time.Now()
+	require.Equal(t, [DefaultMaxLayerSpatial + 1][DefaultMaxLayerTemporal + 1]int64{}, lb.Bitrates(now))
+
+	// 1000 bytes every 10 ms on temporal layer 0, 500 bytes on temporal layer 1 => 800 kbps + 400 kbps
+	for i := 0; i < 100; i++ {
+		at := now.Add(time.Duration(i) * 10 * time.Millisecond)
+		lb.Update(VideoLayer{Spatial: InvalidLayerSpatial, Temporal: 0}, 1000, at)
+		lb.Update(VideoLayer{Spatial: InvalidLayerSpatial, Temporal: 1}, 500, at)
+	}
+	brs := lb.Bitrates(now.Add(time.Second))
+	require.InDelta(t, 800_000, brs[0][0], 1_000)
+	require.InDelta(t, 1_200_000, brs[0][1], 1_000)
+	// layers above a missing layer are not reported
+	require.Zero(t, brs[0][2])
+	require.Zero(t, brs[1][0])
+
+	// rate halves, average moves half way to new rate after half life
+	start := now.Add(time.Second)
+	for i := 0; i < 100; i++ {
+		at := start.Add(time.Duration(i) * 10 * time.Millisecond)
+		lb.Update(VideoLayer{Spatial: 0, Temporal: 0}, 500, at)
+		lb.Update(VideoLayer{Spatial: 0, Temporal: 1}, 250, at)
+	}
+	brs = lb.Bitrates(start.Add(time.Second))
+	require.InDelta(t, 600_000, brs[0][0], 10_000)
+
+	// decays without packets
+	brs = lb.Bitrates(start.Add(10 * time.Second))
+	require.Less(t, brs[0][0], int64(10_000))
+}
+
+func TestLayerBitrateSpatial(t *testing.T) {
+	lb := NewLayerBitrate(DefaultLayerBitrateParams)
+
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		at := now.Add(time.Duration(i) * 10 * time.Millisecond)
+		lb.Update(VideoLayer{Spatial: 0, Temporal: 0}, 250, at)
+		lb.Update(VideoLayer{Spatial: 1, Temporal: 0}, 1000, at)
+	}
+	brs := lb.Bitrates(now.Add(time.Second))
+	require.InDelta(t, 200_000, brs[0][0], 1_000)
+	// spatial layers accumulate lower spatial layers
+	require.InDelta(t, 1_000_000, brs[1][0], 1_000)
+	require.Zero(t, brs[2][0])
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/livekit/mediatransportutil/pkg/bucket"
+	"github.com/livekit/protocol/logger"
+)
+
+// memory taken by one packet slot of a bucket, packet plus size header
+const bucketSlotSize = bucket.MaxPktSize + 2
+
+// MemoryPool accounts the packet buffer memory of all tracks of a node against a limit,
+// shared by the buffer factories of all rooms.
+//
+// Buffers reserve memory before growing. Close to the limit growth is refused, so buffers keep
+// their current size (backpressure). The initial buffer of a new track is always granted, if that
+// takes the pool over its limit, buffers are shrunk back to their initial size, largest first,
+// dropping their packet history (eviction).
+type MemoryPool struct {
+	limit int64
+
+	lock     sync.Mutex
+	used     int64
+	buffers  map[*Buffer]int64
+	evicting bool
+}
+
+func NewMemoryPool(limit int64) *MemoryPool {
+	return &MemoryPool{
+		limit:   limit,
+		buffers: make(map[*Buffer]int64),
+	}
+}
+
+func (p *MemoryPool) Limit() int64 {
+	return p.limit
+}
+
+func (p *MemoryPool) Used() int64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.used
+}
+
+// acquire reserves memory for numSlots bucket slots of a buffer, mandatory reservations are
+// granted over the limit and start an eviction
+func (p *MemoryPool) acquire(b *Buffer, numSlots int, mandatory bool) bool {
+	size := int64(numSlots) * bucketSlotSize
+
+	p.lock.Lock()
+	if !mandatory && p.used+size > p.limit {
+		p.lock.Unlock()
+		return false
+	}
+	p.used += size
+	p.buffers[b] += size
+	overLimit := p.used > p.limit
+	p.lock.Unlock()
+
+	if overLimit {
+		// caller holds the lock of its buffer, which could be picked for eviction
+		go p.evict()
+	}
+	return true
+}
+
+func (p *MemoryPool) release(b *Buffer, numSlots int) {
+	size := int64(numSlots) * bucketSlotSize
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	held, ok := p.buffers[b]
+	if !ok {
+		return
+	}
+	size = min(size, held)
+	p.used -= size
+	p.buffers[b] = held - size
+}
+
+func (p *MemoryPool) remove(b *Buffer) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.used -= p.buffers[b]
+	delete(p.buffers, b)
+}
+
+func (p *MemoryPool) evict() {
+	p.lock.Lock()
+	if p.evicting || p.used <= p.limit {
+		p.lock.Unlock()
+		return
+	}
+	p.evicting = true
+	candidates := make([]*Buffer, 0, len(p.buffers))
+	for b := range p.buffers {
+		candidates = append(candidates, b)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return p.buffers[candidates[i]] > p.buffers[candidates[j]]
+	})
+	usedBefore := p.used
+	p.lock.Unlock()
+
+	shrunk := 0
+	for _, b := range candidates {
+		if p.Used() <= p.limit {
+			break
+		}
+		if b.shrinkBucket() {
+			shrunk++
+		}
+	}
+
+	p.lock.Lock()
+	p.evicting = false
+	usedAfter := p.used
+	p.lock.Unlock()
+
+	logger.Infow(
+		"packet buffer memory over limit, shrunk buffers",
+		"limit", p.limit,
+		"usedBefore", usedBefore,
+		"usedAfter", usedAfter,
+		"shrunk", shrunk,
+	)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/transport/v2/packetio"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPool(t *testing.T) {
+	videoInit := int64(InitPacketBufferSizeVideo * bucketSlotSize)
+	audioInit := int64(InitPacketBufferSizeAudio * bucketSlotSize)
+	pool := NewMemoryPool(3*videoInit + audioInit)
+	f := NewFactoryOfBufferFactory(1500, 200, pool).CreateBufferFactory()
+
+	bindVideo := func(ssrc uint32, bitrate int) *Buffer {
+		b := f.GetOrNew(packetio.RTPBufferPacket, ssrc).(*Buffer)
+		b.Bind(webrtc.RTPParameters{Codecs: []webrtc.RTPCodecParameters{vp8Codec}}, vp8Codec.RTPCodecCapability, bitrate)
+		return b
+	}
+
+	// grows to hold a second of packets at 3 Mbps, within the limit
+	large := bindVideo(1, 3_000_000)
+	require.Equal(t, 2*InitPacketBufferSizeVideo, large.bucket.Capacity())
+	require.Equal(t, 2*videoInit, pool.Used())
+
+	audio := f.GetOrNew(packetio.RTPBufferPacket, 2).(*Buffer)
+	audio.Bind(webrtc.RTPParameters{Codecs: []webrtc.RTPCodecParameters{opusCodec}}, opusCodec.RTPCodecCapability, 0)
+	require.Equal(t, 2*videoInit+audioInit, pool.Used())
+
+	// backpressure, growth is refused at the limit
+	medium := bindVideo(3, 3_000_000)
+	require.Equal(t, InitPacketBufferSizeVideo, medium.bucket.Capacity())
+	require.Equal(t, pool.Limit(), pool.Used())
+
+	// initial buffer of a new track goes over the limit, evicting the largest buffer
+	small := bindVideo(4, 0)
+	require.Equal(t, InitPacketBufferSizeVideo, small.bucket.Capacity())
+	require.Eventually(t, func() bool {
+		return pool.Used() <= pool.Limit()
+	}, time.Second, 10*time.Millisecond)
+	large.RLock()
+	require.Equal(t, InitPacketBufferSizeVideo, large.bucket.Capacity())
+	large.RUnlock()
+	require.Equal(t, InitPacketBufferSizeVideo, medium.bucket.Capacity())
+
+	// closing gives back all memory of a buffer
+	require.NoError(t, large.Close())
+	require.Equal(t, 2*videoInit+audioInit, pool.Used())
+	require.NoError(t, medium.Close())
+	require.NoError(t, small.Close())
+	require.NoError(t, audio.Close())
+	require.Zero(t, pool.Used())
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"github.com/pion/rtcp"
+)
+
+// ForEachLossRLE walks the sequence numbers covered by a RTCP XR Loss RLE report block (RFC 3611, section 4.1)
+// and calls fn with the receive status of each one. Thinned reports (T != 0) do not describe every
+// sequence number and are ignored.
+func ForEachLossRLE(b *rtcp.LossRLEReportBlock, fn func(sn uint16, received bool)) {
+	if b == nil || b.T != 0 {
+		return
+	}
+
+	sn := b.BeginSeq
+	emit := func(received bool) bool {
+		if sn == b.EndSeq {
+			return false
+		}
+		fn(sn, received)
+		sn++
+		return true
+	}
+
+	for _, chunk := range b.Chunks {
+		switch chunk.Type() {
+		case rtcp.RunLengthChunkType:
+			runType, _ := chunk.RunType()
+			for i := uint(0); i < chunk.Value(); i++ {
+				if !emit(runType == 1) {
+					return
+				}
+			}
+
+		case rtcp.BitVectorChunkType:
+			bits := chunk.Value()
+			for i := 14; i >= 0; i-- {
+				if !emit((bits>>i)&0x1 == 1) {
+					return
+				}
+			}
+
+		case rtcp.TerminatingNullChunkType:
+			return
+		}
+	}
+}
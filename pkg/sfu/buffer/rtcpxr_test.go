@@ -0,0 +1,91 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+)
+
+func TestForEachLossRLE(t *testing.T) {
+	b := &rtcp.LossRLEReportBlock{
+		BeginSeq: 65530,
+		EndSeq:   10,
+		Chunks: []rtcp.Chunk{
+			0x4003,                     // 3 received
+			0x0002,                     // 2 lost
+			0x8000 | 0b101100000000000, // bit vector: received, lost, received, received, lost...
+			0,                          // terminating null
+		},
+	}
+
+	var sns []uint16
+	var lost []uint16
+	ForEachLossRLE(b, func(sn uint16, received bool) {
+		sns = append(sns, sn)
+		if !received {
+			lost = append(lost, sn)
+		}
+	})
+	// range is [BeginSeq, EndSeq), i. e. 16 sequence numbers
+	require.Len(t, sns, 16)
+	require.Equal(t, uint16(65530), sns[0])
+	require.Equal(t, uint16(9), sns[15])
+	require.Equal(t, []uint16{65533, 65534, 0, 3, 4, 5, 6, 7, 8, 9}, lost)
+
+	// thinned reports are ignored
+	b.T = 1
+	called := false
+	ForEachLossRLE(b, func(sn uint16, received bool) { called = true })
+	require.False(t, called)
+}
+
+func TestRTPStatsSenderLossRLE(t *testing.T) {
+	r := NewRTPStatsSender(RTPStatsParams{ClockRate: 90000, Logger: logger.GetLogger()})
+	senderSnapshotID := r.NewSenderSnapshotId()
+
+	now := time.Now().UnixNano()
+	for sn := uint64(100); sn < 120; sn++ {
+		r.Update(now, sn, 1000, false, 12, 100, 0)
+	}
+	r.UpdateFromReceiverReport(rtcp.ReceptionReport{LastSequenceNumber: 109, TotalLost: 3})
+
+	// 100-104 received, 105-107 lost, 108-109 received
+	r.UpdateFromLossRLE(&rtcp.LossRLEReportBlock{
+		BeginSeq: 100,
+		EndSeq:   110,
+		Chunks:   []rtcp.Chunk{0x4005, 0x0003, 0x4002},
+	})
+	// overlapping report, 108-109 already accounted, 110 lost, 111 received, 112 lost
+	r.UpdateFromLossRLE(&rtcp.LossRLEReportBlock{
+		BeginSeq: 108,
+		EndSeq:   113,
+		Chunks:   []rtcp.Chunk{0x8000 | 0b110100000000000},
+	})
+
+	require.Equal(t, uint64(5), r.packetsLostFromXR)
+	require.Equal(t, uint64(2), r.lossBurstsFromXR)
+	require.Equal(t, uint32(3), r.maxLossBurstFromXR)
+
+	deltaInfo := r.DeltaInfoSender(senderSnapshotID)
+	require.NotNil(t, deltaInfo)
+	require.Equal(t, uint32(2), deltaInfo.LossBursts)
+	require.Equal(t, uint32(3), deltaInfo.LossBurstMax)
+}
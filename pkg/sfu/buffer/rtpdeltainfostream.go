@@ -0,0 +1,128 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+)
+
+type rtpDeltaInfoSubscription struct {
+	snapshotID  uint32
+	interval    time.Duration
+	onDeltaInfo func(deltaInfo *RTPDeltaInfo)
+	done        core.Fuse
+}
+
+// RTPDeltaInfoStream pushes windowed RTPDeltaInfo of a RTP stats instance to subscribers on a ticker.
+// Every subscriber has a snapshot of its own, i. e. each push covers the window since the previous push
+// to that subscriber, so that consumers do not have to manage snapshot IDs and timers.
+//
+// Meant for consumers of a single RTP stats instance, like the playout delay controller of a down track.
+// Connection stats aggregates snapshots of all buffers and layers of a track at its own cadence and
+// the stream allocator works off receiver reports and TWCC feedback, so they do not use the stream.
+type RTPDeltaInfoStream struct {
+	newSnapshotID func() uint32
+	deltaInfo     func(snapshotID uint32) *RTPDeltaInfo
+
+	lock            sync.Mutex
+	subscriptions   map[*rtpDeltaInfoSubscription]struct{}
+	freeSnapshotIDs []uint32
+	closed          bool
+}
+
+func NewRTPDeltaInfoStream(newSnapshotID func() uint32, deltaInfo func(snapshotID uint32) *RTPDeltaInfo) *RTPDeltaInfoStream {
+	return &RTPDeltaInfoStream{
+		newSnapshotID: newSnapshotID,
+		deltaInfo:     deltaInfo,
+		subscriptions: make(map[*rtpDeltaInfoSubscription]struct{}),
+	}
+}
+
+// Subscribe calls onDeltaInfo with the delta info of every interval from a goroutine of the subscription.
+// Intervals without delta info (e. g. no receiver report for sender delta info) are skipped.
+// The returned function unsubscribes.
+func (s *RTPDeltaInfoStream) Subscribe(interval time.Duration, onDeltaInfo func(deltaInfo *RTPDeltaInfo)) func() {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return func() {}
+	}
+
+	sub := &rtpDeltaInfoSubscription{
+		interval:    interval,
+		onDeltaInfo: onDeltaInfo,
+	}
+	if n := len(s.freeSnapshotIDs); n != 0 {
+		sub.snapshotID = s.freeSnapshotIDs[n-1]
+		s.freeSnapshotIDs = s.freeSnapshotIDs[:n-1]
+
+		// snapshot of a previous subscriber, start the window now
+		s.deltaInfo(sub.snapshotID)
+	} else {
+		sub.snapshotID = s.newSnapshotID()
+	}
+	s.subscriptions[sub] = struct{}{}
+	s.lock.Unlock()
+
+	go s.worker(sub)
+
+	return func() {
+		s.lock.Lock()
+		delete(s.subscriptions, sub)
+		s.lock.Unlock()
+
+		sub.done.Break()
+	}
+}
+
+// Close stops all subscriptions, later subscriptions are not served.
+func (s *RTPDeltaInfoStream) Close() {
+	s.lock.Lock()
+	s.closed = true
+	subscriptions := s.subscriptions
+	s.subscriptions = make(map[*rtpDeltaInfoSubscription]struct{})
+	s.lock.Unlock()
+
+	for sub := range subscriptions {
+		sub.done.Break()
+	}
+}
+
+func (s *RTPDeltaInfoStream) worker(sub *rtpDeltaInfoSubscription) {
+	defer func() {
+		// snapshot is reused only after worker is done with it
+		s.lock.Lock()
+		s.freeSnapshotIDs = append(s.freeSnapshotIDs, sub.snapshotID)
+		s.lock.Unlock()
+	}()
+
+	ticker := time.NewTicker(sub.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sub.done.Watch():
+			return
+
+		case <-ticker.C:
+			if deltaInfo := s.deltaInfo(sub.snapshotID); deltaInfo != nil && !sub.done.IsBroken() {
+				sub.onDeltaInfo(deltaInfo)
+			}
+		}
+	}
+}
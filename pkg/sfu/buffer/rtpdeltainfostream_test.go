@@ -0,0 +1,85 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+)
+
+func TestRTPDeltaInfoStream(t *testing.T) {
+	r := NewRTPStatsSender(RTPStatsParams{ClockRate: 90000, Logger: logger.GetLogger()})
+	s := NewRTPDeltaInfoStream(r.NewSnapshotId, r.DeltaInfo)
+
+	deltaInfos := make(chan *RTPDeltaInfo, 10)
+	unsubscribe := s.Subscribe(20*time.Millisecond, func(deltaInfo *RTPDeltaInfo) {
+		deltaInfos <- deltaInfo
+	})
+
+	now := time.Now().UnixNano()
+	for sn := uint64(100); sn < 110; sn++ {
+		r.Update(now, sn, 1000, false, 12, 100, 0)
+	}
+
+	// every push covers the window since the previous push
+	var packets uint32
+	require.Eventually(t, func() bool {
+		select {
+		case deltaInfo := <-deltaInfos:
+			packets += deltaInfo.Packets
+		default:
+		}
+		return packets == 10
+	}, time.Second, 5*time.Millisecond)
+
+	unsubscribe()
+	require.Eventually(t, func() bool {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return len(s.freeSnapshotIDs) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// snapshot of unsubscribed is reused and starts afresh
+	for sn := uint64(110); sn < 115; sn++ {
+		r.Update(now, sn, 1000, false, 12, 100, 0)
+	}
+	deltaInfos2 := make(chan *RTPDeltaInfo, 10)
+	s.Subscribe(20*time.Millisecond, func(deltaInfo *RTPDeltaInfo) {
+		deltaInfos2 <- deltaInfo
+	})
+	require.Empty(t, s.freeSnapshotIDs)
+	for sn := uint64(115); sn < 118; sn++ {
+		r.Update(now, sn, 1000, false, 12, 100, 0)
+	}
+	select {
+	case deltaInfo := <-deltaInfos2:
+		require.Equal(t, uint32(3), deltaInfo.Packets)
+	case <-time.After(time.Second):
+		require.Fail(t, "no delta info pushed")
+	}
+
+	// no pushes after close
+	s.Close()
+	time.Sleep(50 * time.Millisecond)
+	for len(deltaInfos2) != 0 {
+		<-deltaInfos2
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, deltaInfos2)
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/livekit/mediatransportutil"
@@ -77,6 +78,9 @@ type RTPDeltaInfo struct {
 	Nacks                uint32
 	Plis                 uint32
 	Firs                 uint32
+	RttFromXR            bool   // RttMax measured using RTCP XR (RRTR/DLRR)
+	LossBursts           uint32 // runs of consecutive loss, from RTCP XR Loss RLE
+	LossBurstMax         uint32 // longest run of consecutive loss, from RTCP XR Loss RLE
 }
 
 type snapshot struct {
@@ -166,6 +170,13 @@ func (r *RTCPSenderReportData) MarshalLogObject(e zapcore.ObjectEncoder) error {
 type RTPStatsParams struct {
 	ClockRate uint32
 	Logger    logger.Logger
+
+	// sender only, number of packets remembered to account receiver reports, rounded up to a power of 2,
+	// DefaultSnInfoSize if not set. The window grows with packets sent between receiver reports up to SnInfoMaxSize.
+	SnInfoSize    int
+	SnInfoMaxSize int
+	// sender only, called when packets reported by a receiver report had already left the window
+	OnSnInfoOverflow func(packetsNotFound uint64)
 }
 
 type rtpStatsBase struct {
@@ -201,8 +212,9 @@ type rtpStatsBase struct {
 
 	frames uint32
 
-	jitter    float64
-	maxJitter float64
+	jitter          float64
+	maxJitter       float64
+	jitterHistogram *Histogram
 
 	gapHistogram [cGapHistogramNumBins]uint32
 
@@ -210,9 +222,14 @@ type rtpStatsBase struct {
 	nackAcks     uint32
 	nackMisses   uint32
 	nackRepeated uint32
+	// NACKs not answered as retransmission budget was exhausted
+	nackBudgetExceeded uint32
 
 	plis    uint32
 	lastPli time.Time
+	// key frame requests of down tracks, and those coalesced into a PLI sent for another request
+	pliRequests   uint32
+	plisCoalesced uint32
 
 	layerLockPlis    uint32
 	lastLayerLockPli time.Time
@@ -223,22 +240,28 @@ type rtpStatsBase struct {
 	keyFrames    uint32
 	lastKeyFrame time.Time
 
-	rtt    uint32
-	maxRtt uint32
+	rtt          uint32
+	maxRtt       uint32
+	rttHistogram *Histogram
 
 	srFirst  *RTCPSenderReportData
 	srNewest *RTCPSenderReportData
 
 	nextSnapshotID uint32
 	snapshots      []snapshot
+
+	// stats of the stream at the node a participant migrated from, aggregated into ToProto
+	seededProto *livekit.RTPStats
 }
 
 func newRTPStatsBase(params RTPStatsParams) *rtpStatsBase {
 	return &rtpStatsBase{
-		params:         params,
-		logger:         params.Logger,
-		nextSnapshotID: cFirstSnapshotID,
-		snapshots:      make([]snapshot, 2),
+		params:          params,
+		logger:          params.Logger,
+		jitterHistogram: NewHistogram(DefaultJitterHistogramBucketsUs),
+		rttHistogram:    NewHistogram(DefaultRTTHistogramBucketsMs),
+		nextSnapshotID:  cFirstSnapshotID,
+		snapshots:       make([]snapshot, 2),
 	}
 }
 
@@ -275,6 +298,7 @@ func (r *rtpStatsBase) seed(from *rtpStatsBase) bool {
 
 	r.jitter = from.jitter
 	r.maxJitter = from.maxJitter
+	r.jitterHistogram = from.jitterHistogram.clone()
 
 	r.gapHistogram = from.gapHistogram
 
@@ -282,9 +306,12 @@ func (r *rtpStatsBase) seed(from *rtpStatsBase) bool {
 	r.nackAcks = from.nackAcks
 	r.nackMisses = from.nackMisses
 	r.nackRepeated = from.nackRepeated
+	r.nackBudgetExceeded = from.nackBudgetExceeded
 
 	r.plis = from.plis
 	r.lastPli = from.lastPli
+	r.pliRequests = from.pliRequests
+	r.plisCoalesced = from.plisCoalesced
 
 	r.layerLockPlis = from.layerLockPlis
 	r.lastLayerLockPli = from.lastLayerLockPli
@@ -297,6 +324,7 @@ func (r *rtpStatsBase) seed(from *rtpStatsBase) bool {
 
 	r.rtt = from.rtt
 	r.maxRtt = from.maxRtt
+	r.rttHistogram = from.rttHistogram.clone()
 
 	if from.srFirst != nil {
 		srFirst := *from.srFirst
@@ -314,9 +342,30 @@ func (r *rtpStatsBase) seed(from *rtpStatsBase) bool {
 	r.nextSnapshotID = from.nextSnapshotID
 	r.snapshots = make([]snapshot, cap(from.snapshots))
 	copy(r.snapshots, from.snapshots)
+
+	r.seededProto = from.seededProto
 	return true
 }
 
+// SeedProto carries over stats of the stream at a previous node on migration,
+// they are aggregated into stats reported by ToProto so that session metrics remain continuous
+func (r *rtpStatsBase) SeedProto(stats *livekit.RTPStats) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.seededProto = stats
+}
+
+func (r *rtpStatsBase) withSeededProto(p *livekit.RTPStats) *livekit.RTPStats {
+	if r.seededProto == nil {
+		return p
+	}
+	if p == nil {
+		return proto.Clone(r.seededProto).(*livekit.RTPStats)
+	}
+	return AggregateRTPStats([]*livekit.RTPStats{r.seededProto, p})
+}
+
 func (r *rtpStatsBase) SetLogger(logger logger.Logger) {
 	r.logger = logger
 }
@@ -375,6 +424,24 @@ func (r *rtpStatsBase) UpdateNackProcessed(nackAckCount uint32, nackMissCount ui
 	r.nackRepeated += nackRepeatedCount
 }
 
+func (r *rtpStatsBase) UpdateNackBudgetExceeded(count uint32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.endTime.IsZero() {
+		return
+	}
+
+	r.nackBudgetExceeded += count
+}
+
+func (r *rtpStatsBase) NackBudgetExceeded() uint32 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.nackBudgetExceeded
+}
+
 func (r *rtpStatsBase) CheckAndUpdatePli(throttle int64, force bool) bool {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -436,6 +503,27 @@ func (r *rtpStatsBase) LastPli() time.Time {
 	return r.lastPli
 }
 
+func (r *rtpStatsBase) UpdatePliRequest(coalesced bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.endTime.IsZero() {
+		return
+	}
+
+	r.pliRequests++
+	if coalesced {
+		r.plisCoalesced++
+	}
+}
+
+func (r *rtpStatsBase) PliRequests() (uint32, uint32) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.pliRequests, r.plisCoalesced
+}
+
 func (r *rtpStatsBase) UpdateLayerLockPliAndTime(pliCount uint32) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -486,6 +574,10 @@ func (r *rtpStatsBase) UpdateRtt(rtt uint32) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
+	r.updateRttLocked(rtt)
+}
+
+func (r *rtpStatsBase) updateRttLocked(rtt uint32) {
 	if !r.endTime.IsZero() {
 		return
 	}
@@ -494,6 +586,7 @@ func (r *rtpStatsBase) UpdateRtt(rtt uint32) {
 	if rtt > r.maxRtt {
 		r.maxRtt = rtt
 	}
+	r.rttHistogram.Add(float64(rtt))
 
 	for i := uint32(0); i < r.nextSnapshotID-cFirstSnapshotID; i++ {
 		s := &r.snapshots[i]
@@ -503,6 +596,12 @@ func (r *rtpStatsBase) UpdateRtt(rtt uint32) {
 	}
 }
 
+func (r *rtpStatsBase) addJitterSampleLocked(jitter float64) {
+	if r.params.ClockRate != 0 {
+		r.jitterHistogram.Add(jitter / float64(r.params.ClockRate) * 1e6)
+	}
+}
+
 func (r *rtpStatsBase) GetRtt() uint32 {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
@@ -688,6 +787,7 @@ func (r *rtpStatsBase) MarshalLogObject(e zapcore.ObjectEncoder) error {
 
 	e.AddFloat64("jitter", r.jitter)
 	e.AddFloat64("maxJitter", r.maxJitter)
+	e.AddString("jitterHistogramUs", r.jitterHistogram.String())
 
 	hasLoss := false
 	first := true
@@ -714,9 +814,12 @@ func (r *rtpStatsBase) MarshalLogObject(e zapcore.ObjectEncoder) error {
 	e.AddUint32("nackAcks", r.nackAcks)
 	e.AddUint32("nackMisses", r.nackMisses)
 	e.AddUint32("nackRepeated", r.nackRepeated)
+	e.AddUint32("nackBudgetExceeded", r.nackBudgetExceeded)
 
 	e.AddUint32("plis", r.plis)
 	e.AddTime("lastPli", r.lastPli)
+	e.AddUint32("pliRequests", r.pliRequests)
+	e.AddUint32("plisCoalesced", r.plisCoalesced)
 
 	e.AddUint32("layerLockPlis", r.layerLockPlis)
 	e.AddTime("lastLayerLockPli", r.lastLayerLockPli)
@@ -729,6 +832,7 @@ func (r *rtpStatsBase) MarshalLogObject(e zapcore.ObjectEncoder) error {
 
 	e.AddUint32("rtt", r.rtt)
 	e.AddUint32("maxRtt", r.maxRtt)
+	e.AddString("rttHistogramMs", r.rttHistogram.String())
 
 	e.AddObject("srFirst", r.srFirst)
 	e.AddObject("srNewest", r.srNewest)
@@ -1111,6 +1215,10 @@ func AggregateRTPDeltaInfo(deltaInfoList []*RTPDeltaInfo) *RTPDeltaInfo {
 	plis := uint32(0)
 	firs := uint32(0)
 
+	rttFromXR := false
+	lossBursts := uint32(0)
+	lossBurstMax := uint32(0)
+
 	for _, deltaInfo := range deltaInfoList {
 		if deltaInfo == nil {
 			continue
@@ -1153,6 +1261,12 @@ func AggregateRTPDeltaInfo(deltaInfoList []*RTPDeltaInfo) *RTPDeltaInfo {
 		nacks += deltaInfo.Nacks
 		plis += deltaInfo.Plis
 		firs += deltaInfo.Firs
+
+		rttFromXR = rttFromXR || deltaInfo.RttFromXR
+		lossBursts += deltaInfo.LossBursts
+		if deltaInfo.LossBurstMax > lossBurstMax {
+			lossBurstMax = deltaInfo.LossBurstMax
+		}
 	}
 	if startTime.IsZero() || endTime.IsZero() {
 		return nil
@@ -1179,6 +1293,9 @@ func AggregateRTPDeltaInfo(deltaInfoList []*RTPDeltaInfo) *RTPDeltaInfo {
 		Nacks:                nacks,
 		Plis:                 plis,
 		Firs:                 firs,
+		RttFromXR:            rttFromXR,
+		LossBursts:           lossBursts,
+		LossBurstMax:         lossBurstMax,
 	}
 }
 
@@ -118,6 +118,10 @@ type RTPStatsReceiver struct {
 	largeJumpCount              int
 	largeJumpNegativeCount      int
 	timeReversedCount           int
+
+	packetsDTX uint64
+
+	rttFromXR bool
 }
 
 func NewRTPStatsReceiver(params RTPStatsParams) *RTPStatsReceiver {
@@ -155,6 +159,7 @@ func (r *RTPStatsReceiver) Update(
 	hdrSize int,
 	payloadSize int,
 	paddingSize int,
+	isDTX bool,
 ) (flowState RTPFlowState) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -333,14 +338,22 @@ func (r *RTPStatsReceiver) Update(
 				r.frames++
 			}
 
-			r.updateJitter(resTS.ExtendedVal, packetTime)
+			if isDTX {
+				// DTX packets are sent sparsely during silence, the gaps around them are not jitter.
+				// Restart transit measurement with the first packet after silence.
+				r.packetsDTX++
+				r.lastTransit = 0
+			} else {
+				r.addJitterSampleLocked(r.updateJitter(resTS.ExtendedVal, packetTime))
+			}
 		}
 	}
 	return
 }
 
 func (r *RTPStatsReceiver) getExtendedSenderReport(srData *RTCPSenderReportData) *RTCPSenderReportData {
-	tsCycles := uint64(0)
+	srDataExt := *srData
+	srDataExt.RTPTimestampExt = uint64(srData.RTPTimestamp)
 	if r.srNewest != nil {
 		// use time since last sender report to ensure long gaps where the time stamp might
 		// jump more than half the range
@@ -348,38 +361,16 @@ func (r *RTPStatsReceiver) getExtendedSenderReport(srData *RTCPSenderReportData)
 		expectedRTPTimestampExt := r.srNewest.RTPTimestampExt + uint64(timeSinceLastReport.Nanoseconds()*int64(r.params.ClockRate)/1e9)
 		lbound := expectedRTPTimestampExt - uint64(cReportSlack*float64(r.params.ClockRate))
 		ubound := expectedRTPTimestampExt + uint64(cReportSlack*float64(r.params.ClockRate))
-		isInRange := (srData.RTPTimestamp-uint32(lbound) < (1 << 31)) && (uint32(ubound)-srData.RTPTimestamp < (1 << 31))
+		isInRange := utils.IsNewer(srData.RTPTimestamp, uint32(lbound)) && utils.IsNewer(uint32(ubound), srData.RTPTimestamp)
 		if isInRange {
-			lbTSCycles := lbound & 0xFFFF_FFFF_0000_0000
-			ubTSCycles := ubound & 0xFFFF_FFFF_0000_0000
-			if lbTSCycles == ubTSCycles {
-				tsCycles = lbTSCycles
-			} else {
-				if srData.RTPTimestamp < (1 << 31) {
-					// rolled over
-					tsCycles = ubTSCycles
-				} else {
-					tsCycles = lbTSCycles
-				}
-			}
+			srDataExt.RTPTimestampExt = utils.ExtendFrom(srData.RTPTimestamp, expectedRTPTimestampExt)
 		} else {
 			// ideally this method should not be required, but there are clients
 			// negotiating one clock rate, but actually send media at a different rate.
-			tsCycles = r.srNewest.RTPTimestampExt & 0xFFFF_FFFF_0000_0000
-			if (srData.RTPTimestamp-r.srNewest.RTPTimestamp) < (1<<31) && srData.RTPTimestamp < r.srNewest.RTPTimestamp {
-				tsCycles += (1 << 32)
-			}
-
-			if tsCycles >= (1 << 32) {
-				if (srData.RTPTimestamp-r.srNewest.RTPTimestamp) >= (1<<31) && srData.RTPTimestamp > r.srNewest.RTPTimestamp {
-					tsCycles -= (1 << 32)
-				}
-			}
+			srDataExt.RTPTimestampExt = utils.ExtendFrom(srData.RTPTimestamp, r.srNewest.RTPTimestampExt)
 		}
 	}
 
-	srDataExt := *srData
-	srDataExt.RTPTimestampExt = uint64(srDataExt.RTPTimestamp) + tsCycles
 	return &srDataExt
 }
 
@@ -677,6 +668,16 @@ func (r *RTPStatsReceiver) GetRtcpReceptionReport(ssrc uint32, proxyFracLost uin
 	}
 }
 
+// UpdateRttFromXR records a round trip time measured to the sender using RTCP XR (RRTR/DLRR).
+// Once measured, delta info marks RTT as a true up stream value.
+func (r *RTPStatsReceiver) UpdateRttFromXR(rtt uint32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rttFromXR = true
+	r.updateRttLocked(rtt)
+}
+
 func (r *RTPStatsReceiver) DeltaInfo(snapshotID uint32) *RTPDeltaInfo {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -689,6 +690,9 @@ func (r *RTPStatsReceiver) DeltaInfo(snapshotID uint32) *RTPDeltaInfo {
 	if err != nil {
 		r.logger.Infow(err.Error(), append(loggingFields, "rtpStats", lockedRTPStatsReceiverLogEncoder{r})...)
 	}
+	if deltaInfo != nil {
+		deltaInfo.RttFromXR = r.rttFromXR
+	}
 
 	return deltaInfo
 }
@@ -719,11 +723,11 @@ func (r *RTPStatsReceiver) ToProto() *livekit.RTPStats {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	return r.toProto(
+	return r.withSeededProto(r.toProto(
 		r.sequenceNumber.GetExtendedStart(), r.sequenceNumber.GetExtendedHighest(), r.timestamp.GetExtendedStart(), r.timestamp.GetExtendedHighest(),
 		r.packetsLost,
 		r.jitter, r.maxJitter,
-	)
+	))
 }
 
 func (r *RTPStatsReceiver) isInRange(esn uint64, ehsn uint64) bool {
@@ -751,6 +755,7 @@ func (r lockedRTPStatsReceiverLogEncoder) MarshalLogObject(e zapcore.ObjectEncod
 
 	e.AddDuration("propagationDelay", r.propagationDelay)
 	e.AddDuration("longTermDeltaPropagationDelay", r.longTermDeltaPropagationDelay)
+	e.AddUint64("packetsDTX", r.packetsDTX)
 	return nil
 }
 
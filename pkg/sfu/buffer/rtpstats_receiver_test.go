@@ -22,7 +22,9 @@ import (
 
 	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 )
 
@@ -68,6 +70,7 @@ func Test_RTPStatsReceiver(t *testing.T) {
 				packet.Header.MarshalSize(),
 				len(packet.Payload),
 				0,
+				false,
 			)
 			if (sequenceNumber % 100) == 0 {
 				jump := uint16(rand.Float64() * 120.0)
@@ -104,6 +107,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.False(t, flowState.HasLoss)
 	require.True(t, r.initialized)
@@ -124,6 +128,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.False(t, flowState.HasLoss)
 	require.Equal(t, sequenceNumber, r.sequenceNumber.GetHighest())
@@ -141,6 +146,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.False(t, flowState.HasLoss)
 	require.True(t, flowState.IsNotHandled)
@@ -161,6 +167,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.False(t, flowState.HasLoss)
 	require.True(t, flowState.IsNotHandled)
@@ -183,6 +190,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.True(t, flowState.HasLoss)
 	require.Equal(t, uint64(sequenceNumber-9), flowState.LossStartInclusive)
@@ -199,6 +207,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.False(t, flowState.HasLoss)
 	require.Equal(t, sequenceNumber, r.sequenceNumber.GetHighest())
@@ -222,6 +231,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.True(t, flowState.HasLoss)
 	require.Equal(t, uint64(sequenceNumber-1), flowState.LossStartInclusive)
@@ -241,6 +251,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		0,
+		false,
 	)
 	require.False(t, flowState.HasLoss)
 	require.Equal(t, uint64(8), r.packetsLost)
@@ -258,6 +269,7 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 		packet.Header.MarshalSize(),
 		len(packet.Payload),
 		25,
+		false,
 	)
 	require.False(t, flowState.HasLoss)
 	require.Equal(t, uint64(8), r.packetsLost)
@@ -268,3 +280,111 @@ func Test_RTPStatsReceiver_Update(t *testing.T) {
 
 	r.Stop()
 }
+
+func Test_RTPStatsReceiver_DTX(t *testing.T) {
+	clockRate := uint32(48000)
+	r := NewRTPStatsReceiver(RTPStatsParams{
+		ClockRate: clockRate,
+		Logger:    logger.GetLogger(),
+	})
+
+	sequenceNumber := uint16(1000)
+	startTime := time.Now()
+	update := func(at time.Duration, mediaTime time.Duration, isDTX bool) {
+		payloadSize := 100
+		if isDTX {
+			payloadSize = 1
+		}
+		packet := getPacket(sequenceNumber, 10000+uint32(mediaTime.Milliseconds()*int64(clockRate)/1000), payloadSize)
+		r.Update(
+			startTime.Add(at).UnixNano(),
+			packet.Header.SequenceNumber,
+			packet.Header.Timestamp,
+			packet.Header.Marker,
+			packet.Header.MarshalSize(),
+			len(packet.Payload),
+			0,
+			isDTX,
+		)
+		sequenceNumber++
+	}
+
+	// regular 20 ms frames
+	update(0, 0, false)
+	update(20*time.Millisecond, 20*time.Millisecond, false)
+	update(40*time.Millisecond, 40*time.Millisecond, false)
+	require.Zero(t, r.jitter)
+
+	// silence, DTX packets and the gaps around them do not count as jitter
+	update(130*time.Millisecond, 60*time.Millisecond, true)
+	update(500*time.Millisecond, 460*time.Millisecond, true)
+	update(870*time.Millisecond, 780*time.Millisecond, false)
+	update(890*time.Millisecond, 800*time.Millisecond, false)
+	require.Zero(t, r.jitter)
+	require.Equal(t, uint64(2), r.packetsDTX)
+	require.Zero(t, r.packetsLost)
+
+	// jitter still measured on speech
+	update(930*time.Millisecond, 820*time.Millisecond, false)
+	require.NotZero(t, r.jitter)
+
+	r.Stop()
+}
+
+func Test_RTPStatsReceiver_SeedProto(t *testing.T) {
+	r := NewRTPStatsReceiver(RTPStatsParams{
+		ClockRate: 90000,
+		Logger:    logger.GetLogger(),
+	})
+
+	seedStart := time.Now().Add(-time.Minute)
+	seed := &livekit.RTPStats{
+		StartTime: timestamppb.New(seedStart),
+		EndTime:   timestamppb.New(seedStart.Add(50 * time.Second)),
+		Duration:  50,
+		Packets:   1000,
+		Bytes:     1_000_000,
+		Nacks:     10,
+	}
+	r.SeedProto(seed)
+
+	// stats from previous node before any packet
+	stats := r.ToProto()
+	require.NotNil(t, stats)
+	require.Equal(t, uint32(1000), stats.Packets)
+	require.Equal(t, uint64(1_000_000), stats.Bytes)
+
+	sequenceNumber := uint16(rand.Float64() * float64(1<<16))
+	timestamp := uint32(rand.Float64() * float64(1<<32))
+	bytes := uint64(0)
+	for i := 0; i < 10; i++ {
+		packet := getPacket(sequenceNumber, timestamp, 1000)
+		bytes += uint64(packet.Header.MarshalSize() + len(packet.Payload))
+		r.Update(
+			time.Now().UnixNano(),
+			packet.Header.SequenceNumber,
+			packet.Header.Timestamp,
+			packet.Header.Marker,
+			packet.Header.MarshalSize(),
+			len(packet.Payload),
+			0,
+			false,
+		)
+		sequenceNumber++
+		timestamp += 3000
+	}
+	time.Sleep(10 * time.Millisecond)
+	r.UpdateNack(2)
+
+	// aggregated with stats of this node
+	stats = r.ToProto()
+	require.Equal(t, uint32(1010), stats.Packets)
+	require.Equal(t, 1_000_000+bytes, stats.Bytes)
+	require.Equal(t, uint32(12), stats.Nacks)
+	require.Equal(t, seedStart.UnixNano(), stats.StartTime.AsTime().UnixNano())
+
+	// seed is not modified
+	require.Equal(t, uint32(1000), seed.Packets)
+
+	r.Stop()
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/bits"
 	"time"
 
 	"github.com/pion/rtcp"
@@ -25,11 +26,13 @@ import (
 
 	"github.com/livekit/mediatransportutil"
 	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/utils"
 )
 
 const (
-	cSnInfoSize = 4096
-	cSnInfoMask = cSnInfoSize - 1
+	DefaultSnInfoSize    = 4096
+	DefaultSnInfoMaxSize = 32768
 
 	cSenderReportInitialWait = time.Second
 )
@@ -135,6 +138,9 @@ type senderSnapshot struct {
 	maxJitterFeed float64
 	maxJitter     float64
 
+	lossBursts   uint64
+	maxLossBurst uint32
+
 	extLastRRSN   uint64
 	intervalStats intervalStats
 }
@@ -157,7 +163,17 @@ type RTPStatsSender struct {
 	jitterFromRR    float64
 	maxJitterFromRR float64
 
-	snInfos [cSnInfoSize]snInfo
+	// loss pattern from RTCP XR Loss RLE report blocks, only available from receivers that send XR
+	lossRLEInitialized bool
+	lastSNFromLossRLE  uint16
+	packetsLostFromXR  uint64
+	lossRunFromXR      uint32
+	lossBurstsFromXR   uint64
+	maxLossBurstFromXR uint32
+
+	snInfos       []snInfo
+	snInfoMask    uint64
+	snInfoMaxSize int
 
 	nextSenderSnapshotID uint32
 	senderSnapshots      []senderSnapshot
@@ -170,8 +186,23 @@ type RTPStatsSender struct {
 }
 
 func NewRTPStatsSender(params RTPStatsParams) *RTPStatsSender {
+	size := params.SnInfoSize
+	if size <= 0 {
+		size = DefaultSnInfoSize
+	}
+	size = roundUpToPowerOf2(size)
+
+	maxSize := params.SnInfoMaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultSnInfoMaxSize
+	}
+	maxSize = max(roundUpToPowerOf2(maxSize), size)
+
 	return &RTPStatsSender{
 		rtpStatsBase:         newRTPStatsBase(params),
+		snInfos:              make([]snInfo, size),
+		snInfoMask:           uint64(size - 1),
+		snInfoMaxSize:        maxSize,
 		nextSenderSnapshotID: cFirstSnapshotID,
 		senderSnapshots:      make([]senderSnapshot, 2),
 	}
@@ -200,7 +231,17 @@ func (r *RTPStatsSender) Seed(from *RTPStatsSender) {
 	r.jitterFromRR = from.jitterFromRR
 	r.maxJitterFromRR = from.maxJitterFromRR
 
-	r.snInfos = from.snInfos
+	r.lossRLEInitialized = from.lossRLEInitialized
+	r.lastSNFromLossRLE = from.lastSNFromLossRLE
+	r.packetsLostFromXR = from.packetsLostFromXR
+	r.lossRunFromXR = from.lossRunFromXR
+	r.lossBurstsFromXR = from.lossBurstsFromXR
+	r.maxLossBurstFromXR = from.maxLossBurstFromXR
+
+	r.snInfos = make([]snInfo, len(from.snInfos))
+	copy(r.snInfos, from.snInfos)
+	r.snInfoMask = from.snInfoMask
+	r.snInfoMaxSize = max(r.snInfoMaxSize, from.snInfoMaxSize)
 
 	r.nextSenderSnapshotID = from.nextSenderSnapshotID
 	r.senderSnapshots = make([]senderSnapshot, cap(from.senderSnapshots))
@@ -453,9 +494,7 @@ func (r *RTPStatsSender) UpdateFromReceiverReport(rr rtcp.ReceptionReport) (rtt
 
 	extHighestSNFromRR := r.extHighestSNFromRR&0xFFFF_FFFF_0000_0000 + uint64(rr.LastSequenceNumber)
 	if !r.lastRRTime.IsZero() {
-		if (rr.LastSequenceNumber-r.lastRR.LastSequenceNumber) < (1<<31) && rr.LastSequenceNumber < r.lastRR.LastSequenceNumber {
-			extHighestSNFromRR += (1 << 32)
-		}
+		extHighestSNFromRR = utils.ExtendFrom(rr.LastSequenceNumber, r.extHighestSNFromRR)
 	}
 	if (extHighestSNFromRR + (r.extStartSN & 0xFFFF_FFFF_FFFF_0000)) < r.extStartSN {
 		// it is possible that the `LastSequenceNumber` in the receiver report is before the starting
@@ -486,23 +525,21 @@ func (r *RTPStatsSender) UpdateFromReceiverReport(rr rtcp.ReceptionReport) (rtt
 	}
 
 	// This is 24-bit max in the protocol. So, technically doesn't need extended type. But, done for consistency.
-	packetsLostFromRR := r.packetsLostFromRR&0xFFFF_FFFF_0000_0000 + uint64(rr.TotalLost)
-	if (rr.TotalLost-r.lastRR.TotalLost) < (1<<31) && rr.TotalLost < r.lastRR.TotalLost {
-		packetsLostFromRR += (1 << 32)
-	}
-	r.packetsLostFromRR = packetsLostFromRR
+	r.packetsLostFromRR = utils.ExtendFrom(rr.TotalLost, r.packetsLostFromRR)
 
 	if isRttChanged {
 		r.rtt = rtt
 		if rtt > r.maxRtt {
 			r.maxRtt = rtt
 		}
+		r.rttHistogram.Add(float64(rtt))
 	}
 
 	r.jitterFromRR = float64(rr.Jitter)
 	if r.jitterFromRR > r.maxJitterFromRR {
 		r.maxJitterFromRR = r.jitterFromRR
 	}
+	r.addJitterSampleLocked(r.jitterFromRR)
 
 	// update snapshots
 	for i := uint32(0); i < r.nextSnapshotID-cFirstSnapshotID; i++ {
@@ -543,7 +580,15 @@ func (r *RTPStatsSender) UpdateFromReceiverReport(rr rtcp.ReceptionReport) (rtt
 		is := r.getIntervalStats(s.extLastRRSN+1, extReceivedRRSN+1, r.extHighestSN)
 		eis := &s.intervalStats
 		eis.aggregate(&is)
+
+		// keep cache large enough for packets sent between receiver reports at current packet rate
+		r.maybeGrowSnInfos(r.extHighestSN - s.extLastRRSN)
+
 		if is.packetsNotFound != 0 {
+			if r.params.OnSnInfoOverflow != nil {
+				r.params.OnSnInfoOverflow(is.packetsNotFound)
+			}
+
 			timeSinceLastRR := time.Since(r.lastRRTime)
 			if r.lastRRTime.IsZero() {
 				timeSinceLastRR = time.Since(r.startTime)
@@ -559,6 +604,7 @@ func (r *RTPStatsSender) UpdateFromReceiverReport(rr rtcp.ReceptionReport) (rtt
 					"intervalStats", is.ToString(),
 					"aggregateIntervalStats", eis.ToString(),
 					"count", r.metadataCacheOverflowCount,
+					"cacheSize", len(r.snInfos),
 					"rtpStats", lockedRTPStatsSenderLogEncoder{r},
 				)
 			}
@@ -571,6 +617,61 @@ func (r *RTPStatsSender) UpdateFromReceiverReport(rr rtcp.ReceptionReport) (rtt
 	return
 }
 
+// UpdateFromLossRLE accounts the per-packet loss pattern reported by a receiver in a RTCP XR Loss RLE block.
+// Sequence numbers already covered by a previous report are skipped as report ranges may overlap.
+func (r *RTPStatsSender) UpdateFromLossRLE(b *rtcp.LossRLEReportBlock) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.initialized || !r.endTime.IsZero() {
+		return
+	}
+
+	ForEachLossRLE(b, func(sn uint16, received bool) {
+		if r.lossRLEInitialized {
+			diff := int16(sn - r.lastSNFromLossRLE)
+			if diff <= 0 {
+				return
+			}
+			if diff > 1 {
+				// not reported, loss pattern is unknown across the gap
+				r.endLossRunFromXRLocked()
+			}
+		}
+		r.lossRLEInitialized = true
+		r.lastSNFromLossRLE = sn
+
+		if !received {
+			r.packetsLostFromXR++
+			r.lossRunFromXR++
+			return
+		}
+
+		r.endLossRunFromXRLocked()
+	})
+}
+
+func (r *RTPStatsSender) endLossRunFromXRLocked() {
+	if r.lossRunFromXR == 0 {
+		return
+	}
+
+	run := r.lossRunFromXR
+	r.lossRunFromXR = 0
+
+	r.lossBurstsFromXR++
+	if run > r.maxLossBurstFromXR {
+		r.maxLossBurstFromXR = run
+	}
+
+	for i := uint32(0); i < r.nextSenderSnapshotID-cFirstSnapshotID; i++ {
+		s := &r.senderSnapshots[i]
+		if run > s.maxLossBurst {
+			s.maxLossBurst = run
+		}
+	}
+}
+
 func (r *RTPStatsSender) LastReceiverReportTime() time.Time {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
@@ -711,6 +812,11 @@ func (r *RTPStatsSender) DeltaInfo(snapshotID uint32) *RTPDeltaInfo {
 	return deltaInfo
 }
 
+// NewDeltaInfoSenderStream returns a stream pushing DeltaInfoSender windows, i. e. including loss and jitter from receiver reports.
+func (r *RTPStatsSender) NewDeltaInfoSenderStream() *RTPDeltaInfoStream {
+	return NewRTPDeltaInfoStream(r.NewSenderSnapshotId, r.DeltaInfoSender)
+}
+
 func (r *RTPStatsSender) DeltaInfoSender(senderSnapshotID uint32) *RTPDeltaInfo {
 	r.lock.Lock()
 	defer r.lock.Unlock()
@@ -797,6 +903,8 @@ func (r *RTPStatsSender) DeltaInfoSender(senderSnapshotID uint32) *RTPDeltaInfo
 		Nacks:                now.nacks - then.nacks,
 		Plis:                 now.plis - then.plis,
 		Firs:                 now.firs - then.firs,
+		LossBursts:           uint32(now.lossBursts - then.lossBursts),
+		LossBurstMax:         then.maxLossBurst,
 	}
 }
 
@@ -826,11 +934,11 @@ func (r *RTPStatsSender) ToProto() *livekit.RTPStats {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
 
-	return r.toProto(
+	return r.withSeededProto(r.toProto(
 		r.extStartSN, r.extHighestSN, r.extStartTS, r.extHighestTS,
 		r.packetsLostFromRR,
 		r.jitterFromRR, r.maxJitterFromRR,
-	)
+	))
 }
 
 func (r *RTPStatsSender) getAndResetSenderSnapshot(senderSnapshotID uint32) (*senderSnapshot, *senderSnapshot) {
@@ -886,18 +994,19 @@ func (r *RTPStatsSender) getSenderSnapshot(startTime time.Time, s *senderSnapsho
 		maxRtt:               r.rtt,
 		maxJitterFeed:        r.jitter,
 		maxJitter:            r.jitterFromRR,
+		lossBursts:           r.lossBurstsFromXR,
 		extLastRRSN:          s.extLastRRSN,
 	}
 }
 
 func (r *RTPStatsSender) getSnInfoOutOfOrderSlot(esn uint64, ehsn uint64) int {
 	offset := int64(ehsn - esn)
-	if offset >= cSnInfoSize || offset < 0 {
+	if offset >= int64(len(r.snInfos)) || offset < 0 {
 		// too old OR too new (i. e. ahead of highest)
 		return -1
 	}
 
-	return int(esn & cSnInfoMask)
+	return int(esn & r.snInfoMask)
 }
 
 func (r *RTPStatsSender) setSnInfo(esn uint64, ehsn uint64, pktSize uint16, hdrSize uint8, payloadSize uint16, marker bool, isOutOfOrder bool) {
@@ -908,7 +1017,7 @@ func (r *RTPStatsSender) setSnInfo(esn uint64, ehsn uint64, pktSize uint16, hdrS
 			return
 		}
 	} else {
-		slot = int(esn & cSnInfoMask)
+		slot = int(esn & r.snInfoMask)
 	}
 
 	snInfo := &r.snInfos[slot]
@@ -932,13 +1041,38 @@ func (r *RTPStatsSender) clearSnInfos(extStartInclusive uint64, extEndExclusive
 	}
 
 	for esn := extStartInclusive; esn != extEndExclusive; esn++ {
-		snInfo := &r.snInfos[esn&cSnInfoMask]
+		snInfo := &r.snInfos[esn&r.snInfoMask]
 		snInfo.pktSize = 0
 		snInfo.hdrSize = 0
 		snInfo.flags = 0
 	}
 }
 
+// maybeGrowSnInfos grows the cache to twice the given window when the window
+// takes more than half of it, entries still in the window are carried over.
+func (r *RTPStatsSender) maybeGrowSnInfos(window uint64) {
+	size := len(r.snInfos)
+	if window <= uint64(size/2) || size >= r.snInfoMaxSize {
+		return
+	}
+
+	newSize := size
+	for uint64(newSize/2) < window && newSize < r.snInfoMaxSize {
+		newSize <<= 1
+	}
+
+	snInfos := make([]snInfo, newSize)
+	snInfoMask := uint64(newSize - 1)
+	for i := uint64(0); i < uint64(size); i++ {
+		esn := r.extHighestSN - i
+		snInfos[esn&snInfoMask] = r.snInfos[esn&r.snInfoMask]
+	}
+	r.snInfos = snInfos
+	r.snInfoMask = snInfoMask
+
+	r.logger.Debugw("grown metadata cache", "size", newSize, "window", window)
+}
+
 func (r *RTPStatsSender) isSnInfoLost(esn uint64, ehsn uint64) bool {
 	slot := r.getSnInfoOutOfOrderSlot(esn, ehsn)
 	if slot < 0 {
@@ -1012,5 +1146,19 @@ func (r lockedRTPStatsSenderLogEncoder) MarshalLogObject(e zapcore.ObjectEncoder
 	e.AddUint64("packetsLostFromRR", r.packetsLostFromRR)
 	e.AddFloat64("jitterFromRR", r.jitterFromRR)
 	e.AddFloat64("maxJitterFromRR", r.maxJitterFromRR)
+	if r.lossRLEInitialized {
+		e.AddUint64("packetsLostFromXR", r.packetsLostFromXR)
+		e.AddUint64("lossBurstsFromXR", r.lossBurstsFromXR)
+		e.AddUint32("maxLossBurstFromXR", r.maxLossBurstFromXR)
+	}
 	return nil
 }
+
+// -------------------------------------------------------------------
+
+func roundUpToPowerOf2(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
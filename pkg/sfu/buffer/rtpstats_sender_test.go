@@ -0,0 +1,65 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+)
+
+func TestRTPStatsSenderSnInfoGrow(t *testing.T) {
+	var overflows []uint64
+	r := NewRTPStatsSender(RTPStatsParams{
+		ClockRate:     90000,
+		Logger:        logger.GetLogger(),
+		SnInfoSize:    100,
+		SnInfoMaxSize: 500,
+		OnSnInfoOverflow: func(packetsNotFound uint64) {
+			overflows = append(overflows, packetsNotFound)
+		},
+	})
+	r.NewSenderSnapshotId()
+	require.Len(t, r.snInfos, 128)
+	require.Equal(t, 512, r.snInfoMaxSize)
+
+	now := time.Now().UnixNano()
+	for sn := uint64(100); sn < 300; sn++ {
+		r.Update(now, sn, 1000, false, 12, 100, 0)
+	}
+
+	// 200 packets in receiver report interval, oldest 72 have left the window
+	r.UpdateFromReceiverReport(rtcp.ReceptionReport{LastSequenceNumber: 299})
+	require.Equal(t, []uint64{72}, overflows)
+	require.Len(t, r.snInfos, 512)
+
+	// entries in window are carried over when growing
+	for sn := uint64(300); sn < 310; sn++ {
+		r.Update(now, sn, 1000, false, 12, 100, 0)
+	}
+	require.False(t, r.isSnInfoLost(299, r.extHighestSN))
+
+	// grown window covers more packets in the next interval, but does not grow beyond max
+	for sn := uint64(310); sn < 700; sn++ {
+		r.Update(now, sn, 1000, false, 12, 100, 0)
+	}
+	r.UpdateFromReceiverReport(rtcp.ReceptionReport{LastSequenceNumber: 699})
+	require.Equal(t, []uint64{72}, overflows)
+	require.Len(t, r.snInfos, 512)
+}
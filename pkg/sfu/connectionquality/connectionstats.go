@@ -27,6 +27,7 @@ import (
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 
+	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 )
 
@@ -53,6 +54,7 @@ type ConnectionStatsParams struct {
 	IncludeRTT         bool
 	IncludeJitter      bool
 	EnableBitrateScore bool
+	QualityConfig      config.ConnectionQualityConfig
 	ReceiverProvider   ConnectionStatsReceiverProvider
 	SenderProvider     ConnectionStatsSenderProvider
 	Logger             logger.Logger
@@ -83,6 +85,7 @@ func NewConnectionStats(params ConnectionStatsParams) *ConnectionStats {
 			IncludeRTT:         params.IncludeRTT,
 			IncludeJitter:      params.IncludeJitter,
 			EnableBitrateScore: params.EnableBitrateScore,
+			Model:              newScoreModel(params.QualityConfig, params.Logger),
 			Logger:             params.Logger,
 		}),
 	}
@@ -214,7 +217,10 @@ func (cs *ConnectionStats) updateScoreWithAggregate(agg *buffer.RTPDeltaInfo, la
 		stat.packetsOutOfOrder = agg.PacketsOutOfOrder
 		stat.bytes = agg.Bytes - agg.HeaderBytes // only use media payload size
 		stat.rttMax = agg.RttMax
+		stat.rttFromXR = agg.RttFromXR
 		stat.jitterMax = agg.JitterMax
+		stat.lossBursts = agg.LossBursts
+		stat.lossBurstMax = agg.LossBurstMax
 
 		stat.lastRTCPAt = lastRTCPAt
 	}
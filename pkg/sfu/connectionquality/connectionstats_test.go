@@ -21,6 +21,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
@@ -911,3 +912,77 @@ func TestConnectionQuality(t *testing.T) {
 		}
 	})
 }
+
+func TestMOSScoreModel(t *testing.T) {
+	opusLossWeight := getPacketLossWeight("audio/opus", false)
+	newModel := func(conf config.ConnectionQualityConfig) scoreModel {
+		conf.Model = config.ConnectionQualityModelMOS
+		return newScoreModel(conf, logger.GetLogger())
+	}
+
+	t.Run("no impairments", func(t *testing.T) {
+		score, _ := newModel(config.ConnectionQualityConfig{}).score(
+			&windowStat{packetsExpected: 250},
+			scoreModelInput{packetLossWeight: opusLossWeight, includeRTT: true, includeJitter: true},
+		)
+		require.Equal(t, eModelR0, score)
+		require.Equal(t, livekit.ConnectionQuality_EXCELLENT, scoreToConnectionQuality(score))
+	})
+
+	t.Run("loss", func(t *testing.T) {
+		// 12% loss for Opus
+		stat := &windowStat{packetsExpected: 250, packetsLost: 30}
+		in := scoreModelInput{packetLossWeight: opusLossWeight}
+
+		score, reason := newModel(config.ConnectionQualityConfig{}).score(stat, in)
+		require.Equal(t, "loss", reason)
+		require.Equal(t, livekit.ConnectionQuality_GOOD, scoreToConnectionQuality(score))
+
+		// weighing loss higher drops quality further
+		score, _ = newModel(config.ConnectionQualityConfig{LossWeight: 3.0}).score(stat, in)
+		require.Equal(t, livekit.ConnectionQuality_POOR, scoreToConnectionQuality(score))
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		stat := &windowStat{packetsExpected: 250, rttMax: 400}
+
+		// RTT is not considered unless included
+		score, _ := newModel(config.ConnectionQualityConfig{}).score(stat, scoreModelInput{packetLossWeight: opusLossWeight})
+		require.Equal(t, eModelR0, score)
+
+		in := scoreModelInput{packetLossWeight: opusLossWeight, includeRTT: true}
+		score, reason := newModel(config.ConnectionQualityConfig{}).score(stat, in)
+		require.Equal(t, "delay", reason)
+		require.Equal(t, livekit.ConnectionQuality_EXCELLENT, scoreToConnectionQuality(score))
+
+		score, _ = newModel(config.ConnectionQualityConfig{DelayWeight: 2.0}).score(stat, in)
+		require.Equal(t, livekit.ConnectionQuality_GOOD, scoreToConnectionQuality(score))
+	})
+
+	t.Run("unknown model", func(t *testing.T) {
+		require.Equal(t, defaultScoreModel{}, newScoreModel(config.ConnectionQualityConfig{Model: "unknown"}, logger.GetLogger()))
+	})
+}
+
+func TestBurstRatio(t *testing.T) {
+	// no loss pattern available
+	w := &windowStat{packetsExpected: 100, packetsLost: 10}
+	require.Equal(t, 1.0, w.burstRatio(10))
+
+	// dispersed loss is treated as random
+	w.lossBursts = 10
+	require.Equal(t, 1.0, w.burstRatio(10))
+
+	// four bursts of 2.5 on average, expected mean burst under random loss of 10% is ~1.11
+	w.lossBursts = 4
+	require.InDelta(t, 2.25, w.burstRatio(10), 0.001)
+
+	// capped
+	w.lossBursts = 1
+	require.Equal(t, maxBurstRatio, w.burstRatio(10))
+
+	// bursty loss scores lower than the same amount of random loss
+	random := &windowStat{packetsExpected: 100, packetsLost: 10}
+	bursty := &windowStat{packetsExpected: 100, packetsLost: 10, lossBursts: 2}
+	require.Less(t, bursty.calculatePacketScore(1.0, false, false), random.calculatePacketScore(1.0, false, false))
+}
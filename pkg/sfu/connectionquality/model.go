@@ -0,0 +1,157 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectionquality
+
+import (
+	"math"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/protocol/logger"
+)
+
+// scoreModel calculates the score of a window, on a 0 (worst) - 100 (best) R-factor like scale.
+// Smoothing across windows, mute/pause handling and mapping to quality/MOS are common to all models.
+type scoreModel interface {
+	score(stat *windowStat, in scoreModelInput) (score float64, reason string)
+}
+
+type scoreModelInput struct {
+	packetLossWeight   float64
+	includeRTT         bool
+	includeJitter      bool
+	enableBitrateScore bool
+	expectedBits       int64
+	expectedDistance   float64
+}
+
+func newScoreModel(conf config.ConnectionQualityConfig, logger logger.Logger) scoreModel {
+	switch conf.Model {
+	case config.ConnectionQualityModelDefault:
+		return defaultScoreModel{}
+
+	case config.ConnectionQualityModelMOS:
+		weightOrDefault := func(weight float64) float64 {
+			if weight <= 0 {
+				return 1.0
+			}
+			return weight
+		}
+		return mosScoreModel{
+			lossWeight:    weightOrDefault(conf.LossWeight),
+			delayWeight:   weightOrDefault(conf.DelayWeight),
+			jitterWeight:  weightOrDefault(conf.JitterWeight),
+			bitrateWeight: weightOrDefault(conf.BitrateWeight),
+		}
+
+	default:
+		logger.Warnw("unknown connection quality model, using default", nil, "model", conf.Model)
+		return defaultScoreModel{}
+	}
+}
+
+// ------------------------------------------
+
+// defaultScoreModel uses the worst of packet (loss, delay), bitrate and layer scores
+type defaultScoreModel struct{}
+
+func (d defaultScoreModel) score(stat *windowStat, in scoreModelInput) (float64, string) {
+	packetScore := stat.calculatePacketScore(in.packetLossWeight, in.includeRTT, in.includeJitter)
+	bitrateScore := stat.calculateBitrateScore(in.expectedBits, in.enableBitrateScore)
+	layerScore := math.Max(math.Min(cMaxScore, cMaxScore-(in.expectedDistance*distanceWeight)), 0.0)
+
+	minScore := math.Min(packetScore, bitrateScore)
+	minScore = math.Min(minScore, layerScore)
+
+	switch {
+	case packetScore == minScore:
+		return packetScore, "packet"
+
+	case bitrateScore == minScore:
+		return bitrateScore, "bitrate"
+
+	default:
+		return layerScore, "layer"
+	}
+}
+
+// ------------------------------------------
+
+const (
+	// E-model (ITU-T G.107) default transmission rating without impairments
+	eModelR0 = float64(93.2)
+
+	// packet loss robustness, a codec dependent factor in the E-model. Codec dependence is
+	// handled through packet loss weight, normalised to video, i. e. weight of 10 is robustness of 10.
+	eModelPacketLossRobustness = float64(10.0)
+)
+
+// mosScoreModel estimates R-factor, hence MOS, using a simplified E-model where impairments add up
+//
+//	R = R0 - Id (delay) - Ie-eff (loss) - bitrate impairment - layer impairment
+//
+// Each impairment can be weighted per deployment.
+type mosScoreModel struct {
+	lossWeight    float64
+	delayWeight   float64
+	jitterWeight  float64
+	bitrateWeight float64
+}
+
+func (m mosScoreModel) score(stat *windowStat, in scoreModelInput) (float64, string) {
+	// one way delay, jitter is assumed to be absorbed by a jitter buffer twice its size
+	delay := 0.0
+	if in.includeRTT {
+		delay += float64(stat.rttMax) / 2.0 * m.delayWeight
+	}
+	if in.includeJitter {
+		delay += (stat.jitterMax * 2.0) / 1000.0 * m.jitterWeight
+	}
+	delayImpairment := 0.024 * delay
+	if delay > 177.3 {
+		delayImpairment += 0.11 * (delay - 177.3)
+	}
+
+	actualLost := stat.packetsLost - stat.packetsMissing - stat.packetsOutOfOrder
+	if int32(actualLost) < 0 {
+		actualLost = 0
+	}
+	var lossPercent float64
+	if stat.packetsExpected > 0 {
+		lossPercent = float64(actualLost) * 100.0 / float64(stat.packetsExpected)
+	}
+	lossPercent *= in.packetLossWeight / eModelPacketLossRobustness * m.lossWeight
+	lossImpairment := 95.0 * lossPercent / (lossPercent/stat.burstRatio(actualLost) + eModelPacketLossRobustness)
+
+	bitrateImpairment := (cMaxScore - stat.calculateBitrateScore(in.expectedBits, in.enableBitrateScore)) * m.bitrateWeight
+	layerImpairment := in.expectedDistance * distanceWeight
+
+	score := eModelR0 - delayImpairment - lossImpairment - bitrateImpairment - layerImpairment
+	score = math.Max(math.Min(score, cMaxScore), 0.0)
+
+	reason := "delay"
+	maxImpairment := delayImpairment
+	if lossImpairment > maxImpairment {
+		reason = "loss"
+		maxImpairment = lossImpairment
+	}
+	if bitrateImpairment > maxImpairment {
+		reason = "bitrate"
+		maxImpairment = bitrateImpairment
+	}
+	if layerImpairment > maxImpairment {
+		reason = "layer"
+	}
+	return score, reason
+}
@@ -38,6 +38,8 @@ const (
 
 	distanceWeight = float64(35.0) // each spatial layer missed drops a quality level
 
+	maxBurstRatio = float64(4.0) // cap on how much bursty loss (from RTCP XR) amplifies loss effect
+
 	unmuteTimeThreshold = float64(0.5)
 )
 
@@ -60,10 +62,27 @@ type windowStat struct {
 	packetsOutOfOrder uint32
 	bytes             uint64
 	rttMax            uint32
+	rttFromXR         bool
 	jitterMax         float64
+	lossBursts        uint32
+	lossBurstMax      uint32
 	lastRTCPAt        time.Time
 }
 
+// burstRatio is the E-model (ITU-T G.107) burst ratio, i. e. ratio of observed mean loss burst length
+// to the mean burst length expected under random loss. It is available only when the remote
+// reports loss pattern using RTCP XR Loss RLE, defaults to 1.0 (random loss) otherwise.
+// Loss more dispersed than random is not rewarded.
+func (w *windowStat) burstRatio(actualLost uint32) float64 {
+	if w.lossBursts == 0 || actualLost == 0 || w.packetsExpected == 0 {
+		return 1.0
+	}
+
+	lossRate := math.Min(float64(actualLost)/float64(w.packetsExpected), 1.0)
+	meanBurst := math.Max(float64(actualLost)/float64(w.lossBursts), 1.0)
+	return math.Max(math.Min(meanBurst*(1.0-lossRate), maxBurstRatio), 1.0)
+}
+
 func (w *windowStat) calculatePacketScore(plw float64, includeRTT bool, includeJitter bool) float64 {
 	// this is based on simplified E-model based on packet loss, rtt, jitter as
 	// outlined at https://www.pingman.com/kb/article/how-is-mos-calculated-in-pingplotter-pro-50.html.
@@ -114,7 +133,7 @@ func (w *windowStat) calculatePacketScore(plw float64, includeRTT bool, includeJ
 	if w.packetsExpected > 0 {
 		lossEffect = float64(actualLost) * 100.0 / float64(w.packetsExpected)
 	}
-	lossEffect *= plw
+	lossEffect *= plw * w.burstRatio(actualLost)
 
 	score := cMaxScore - delayEffect - lossEffect
 	if score < 0.0 {
@@ -148,7 +167,7 @@ func (w *windowStat) calculateBitrateScore(expectedBits int64, isEnabled bool) f
 }
 
 func (w *windowStat) String() string {
-	return fmt.Sprintf("start: %+v, dur: %+v, pe: %d, pl: %d, pm: %d, pooo: %d, b: %d, rtt: %d, jitter: %0.2f, lastRTCP: %+v",
+	return fmt.Sprintf("start: %+v, dur: %+v, pe: %d, pl: %d, pm: %d, pooo: %d, b: %d, rtt: %d, rttXR: %v, jitter: %0.2f, lb: %d, lbm: %d, lastRTCP: %+v",
 		w.startedAt,
 		w.duration,
 		w.packetsExpected,
@@ -157,7 +176,10 @@ func (w *windowStat) String() string {
 		w.packetsOutOfOrder,
 		w.bytes,
 		w.rttMax,
+		w.rttFromXR,
 		w.jitterMax,
+		w.lossBursts,
+		w.lossBurstMax,
 		w.lastRTCPAt,
 	)
 }
@@ -175,7 +197,10 @@ func (w *windowStat) MarshalLogObject(e zapcore.ObjectEncoder) error {
 	e.AddUint32("packetsOutOfOrder", w.packetsOutOfOrder)
 	e.AddUint64("bytes", w.bytes)
 	e.AddUint32("rttMax", w.rttMax)
+	e.AddBool("rttFromXR", w.rttFromXR)
 	e.AddFloat64("jitterMax", w.jitterMax)
+	e.AddUint32("lossBursts", w.lossBursts)
+	e.AddUint32("lossBurstMax", w.lossBurstMax)
 	e.AddTime("lastRTCPAt", w.lastRTCPAt)
 	return nil
 }
@@ -187,6 +212,7 @@ type qualityScorerParams struct {
 	IncludeRTT         bool
 	IncludeJitter      bool
 	EnableBitrateScore bool
+	Model              scoreModel
 	Logger             logger.Logger
 }
 
@@ -215,6 +241,9 @@ type qualityScorer struct {
 }
 
 func newQualityScorer(params qualityScorerParams) *qualityScorer {
+	if params.Model == nil {
+		params.Model = defaultScoreModel{}
+	}
 	return &qualityScorer{
 		params: params,
 		score:  cMaxScore,
@@ -404,26 +433,14 @@ func (q *qualityScorer) updateAtLocked(stat *windowStat, at time.Time) {
 			score = qualityTransitionScore[livekit.ConnectionQuality_POOR]
 		}
 	} else {
-		packetScore := stat.calculatePacketScore(plw, q.params.IncludeRTT, q.params.IncludeJitter)
-		bitrateScore := stat.calculateBitrateScore(expectedBits, q.params.EnableBitrateScore)
-		layerScore := math.Max(math.Min(cMaxScore, cMaxScore-(expectedDistance*distanceWeight)), 0.0)
-
-		minScore := math.Min(packetScore, bitrateScore)
-		minScore = math.Min(minScore, layerScore)
-
-		switch {
-		case packetScore == minScore:
-			reason = "packet"
-			score = packetScore
-
-		case bitrateScore == minScore:
-			reason = "bitrate"
-			score = bitrateScore
-
-		case layerScore == minScore:
-			reason = "layer"
-			score = layerScore
-		}
+		score, reason = q.params.Model.score(stat, scoreModelInput{
+			packetLossWeight:   plw,
+			includeRTT:         q.params.IncludeRTT || stat.rttFromXR, // XR measures true RTT, even in the up stream
+			includeJitter:      q.params.IncludeJitter,
+			enableBitrateScore: q.params.EnableBitrateScore,
+			expectedBits:       expectedBits,
+			expectedDistance:   expectedDistance,
+		})
 
 		factor := increaseFactor
 		if score < q.score {
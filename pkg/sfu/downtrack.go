@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +35,7 @@ import (
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 
+	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
@@ -41,6 +43,7 @@ import (
 	dd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/dependencydescriptor"
 	pd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/playoutdelay"
 	"github.com/livekit/livekit-server/pkg/sfu/utils"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 )
 
 // TrackSender defines an interface send media to remote peer
@@ -69,6 +72,8 @@ type TrackSender interface {
 // -------------------------------------------------------------------
 
 const (
+	MimeTypeVideoRTX = "video/rtx"
+
 	RTPPaddingMaxPayloadSize      = 255
 	RTPPaddingEstimatedHeaderSize = 20
 	RTPBlankFramesMuteSeconds     = float32(1.0)
@@ -211,10 +216,27 @@ type DowntrackParams struct {
 	MaxTrack                       int
 	PlayoutDelayLimit              *livekit.PlayoutDelay
 	Pacer                          pacer.Pacer
+	RTXSSRCForPrimary              func(ssrc uint32) uint32
 	Logger                         logger.Logger
 	Trailer                        []byte
 	RTCPWriter                     func([]rtcp.Packet) error
 	DisableSenderReportPassThrough bool
+	RetransmitBufferSize           int
+	RetransmitBudgetKbps           int
+	ConnectionQualityConfig        config.ConnectionQualityConfig
+	// time without media from up track after which blank frames are sent, 0 disables
+	BlankFramesOnStall time.Duration
+	// time without forwarding while up track is sending after which forwarding is restarted, 0 disables
+	FreezeRecovery time.Duration
+	// drop H.264 frames broken by mid-frame loss and request a key frame instead of forwarding partial frames
+	FrameIntegrity bool
+	// send black key frames on publisher mute so that the subscriber does not freeze on the last frame
+	BlankFramesOnMute bool
+	// ranks layers when allocating under constrained bandwidth, balanced policy when nil
+	LayerSelectionPolicy LayerSelectionPolicy
+	// initial and max number of sent packets remembered to account receiver reports, defaults when 0
+	PacketMetadataCacheSize    int
+	PacketMetadataCacheMaxSize int
 }
 
 // DownTrack implements TrackLocal, is the track used to write packets
@@ -234,6 +256,11 @@ type DownTrack struct {
 	payloadType uint8
 	sequencer   *sequencer
 
+	// RTX (RFC 4588) repair stream, used for retransmissions when negotiated
+	rtxSSRC           uint32
+	rtxPayloadType    uint8
+	rtxSequenceNumber atomic.Uint32
+
 	forwarder *Forwarder
 
 	upstreamCodecs []webrtc.RTPCodecParameters
@@ -260,17 +287,29 @@ type DownTrack struct {
 	bound     atomic.Bool
 	onBinding func(error)
 
+	// protected by bindLock, applied to sequencer on bind
+	latencyBudget time.Duration
+
 	isClosed             atomic.Bool
 	connected            atomic.Bool
 	bindAndConnectedOnce atomic.Bool
 	writable             atomic.Bool
 
 	rtpStats *buffer.RTPStatsSender
+	// windowed stats pushed to consumers such as playout delay controller
+	deltaInfoStream *buffer.RTPDeltaInfoStream
 
 	totalRepeatedNACKs atomic.Uint32
+	retransmitBuffer   *retransmitBuffer
+	retransmitBudget   *retransmitBudget
 
 	blankFramesGeneration atomic.Uint32
 
+	// up track stall and forwarding freeze detection, see watchdog
+	lastPacketAt    atomic.Int64
+	lastForwardedAt atomic.Int64
+	stalled         atomic.Bool
+
 	connectionStats            *connectionquality.ConnectionStats
 	deltaStatsSenderSnapshotId uint32
 
@@ -342,16 +381,36 @@ func NewDownTrack(params DowntrackParams) (*DownTrack, error) {
 		false,
 		d.getExpectedRTPTimestamp,
 	)
+	d.forwarder.SetFrameIntegrity(params.FrameIntegrity)
+	d.forwarder.SetLayerSelectionPolicy(params.LayerSelectionPolicy)
 
+	trackType := livekit.TrackType_AUDIO
+	if d.kind == webrtc.RTPCodecTypeVideo {
+		trackType = livekit.TrackType_VIDEO
+	}
 	d.rtpStats = buffer.NewRTPStatsSender(buffer.RTPStatsParams{
-		ClockRate: d.codec.ClockRate,
-		Logger:    d.params.Logger,
+		ClockRate:     d.codec.ClockRate,
+		Logger:        d.params.Logger,
+		SnInfoSize:    params.PacketMetadataCacheSize,
+		SnInfoMaxSize: params.PacketMetadataCacheMaxSize,
+		OnSnInfoOverflow: func(packetsNotFound uint64) {
+			prometheus.RecordMetadataCacheOverflow(trackType, packetsNotFound)
+		},
 	})
 	d.deltaStatsSenderSnapshotId = d.rtpStats.NewSenderSnapshotId()
+	d.deltaInfoStream = d.rtpStats.NewDeltaInfoSenderStream()
+
+	if params.RetransmitBufferSize > 0 {
+		d.retransmitBuffer = newRetransmitBuffer(params.RetransmitBufferSize)
+	}
+	if params.RetransmitBudgetKbps > 0 {
+		d.retransmitBudget = newRetransmitBudget(params.RetransmitBudgetKbps)
+	}
 
 	d.connectionStats = connectionquality.NewConnectionStats(connectionquality.ConnectionStatsParams{
 		MimeType:       codecs[0].MimeType, // LK-TODO have to notify on codec change
 		IsFECEnabled:   strings.EqualFold(codecs[0].MimeType, webrtc.MimeTypeOpus) && strings.Contains(strings.ToLower(codecs[0].SDPFmtpLine), "fec"),
+		QualityConfig:  params.ConnectionQualityConfig,
 		SenderProvider: d,
 		Logger:         d.params.Logger.WithValues("direction", "down"),
 	})
@@ -364,7 +423,7 @@ func NewDownTrack(params DowntrackParams) (*DownTrack, error) {
 	if d.kind == webrtc.RTPCodecTypeVideo {
 		if delay := params.PlayoutDelayLimit; delay.GetEnabled() {
 			var err error
-			d.playoutDelay, err = NewPlayoutDelayController(delay.GetMin(), delay.GetMax(), params.Logger, d.rtpStats)
+			d.playoutDelay, err = NewPlayoutDelayController(delay.GetMin(), delay.GetMax(), params.Logger, d.deltaInfoStream)
 			if err != nil {
 				return nil, err
 			}
@@ -372,6 +431,9 @@ func NewDownTrack(params DowntrackParams) (*DownTrack, error) {
 		go d.maxLayerNotifierWorker()
 		go d.keyFrameRequester()
 	}
+	if d.params.BlankFramesOnStall > 0 || d.params.FreezeRecovery > 0 {
+		go d.watchdog()
+	}
 	d.params.Logger.Debugw("downtrack created", "upstreamCodecs", d.upstreamCodecs)
 
 	return d, nil
@@ -450,6 +512,17 @@ func (d *DownTrack) Bind(t webrtc.TrackLocalContext) (webrtc.RTPCodecParameters,
 
 	d.ssrc = uint32(t.SSRC())
 	d.payloadType = uint8(codec.PayloadType)
+	if d.kind == webrtc.RTPCodecTypeVideo {
+		// use RTX only if a repair stream was signalled for this track
+		if rtxPT := getRTXPayloadType(t.CodecParameters(), codec.PayloadType); rtxPT != 0 && d.params.RTXSSRCForPrimary != nil {
+			if rtxSSRC := d.params.RTXSSRCForPrimary(d.ssrc); rtxSSRC != 0 && rtxSSRC != d.ssrc {
+				d.rtxSSRC = rtxSSRC
+				d.rtxPayloadType = uint8(rtxPT)
+				d.rtxSequenceNumber.Store(uint32(rand.Intn(1 << 15)))
+				d.params.Logger.Debugw("rtx negotiated", "rtxSSRC", d.rtxSSRC, "rtxPayloadType", d.rtxPayloadType)
+			}
+		}
+	}
 	d.writeStream = t.WriteStream()
 	d.mime = strings.ToLower(codec.MimeType)
 	if rr := d.params.BufferFactory.GetOrNew(packetio.RTCPBufferPacket, uint32(t.SSRC())).(*buffer.RTCPReader); rr != nil {
@@ -460,6 +533,7 @@ func (d *DownTrack) Bind(t webrtc.TrackLocalContext) (webrtc.RTPCodecParameters,
 	}
 
 	d.sequencer = newSequencer(d.params.MaxTrack, d.kind == webrtc.RTPCodecTypeVideo, d.params.Logger)
+	d.sequencer.setLatencyBudget(d.latencyBudget)
 
 	d.codec = codec.RTPCodecCapability
 	if d.onBinding != nil {
@@ -631,6 +705,11 @@ func (d *DownTrack) SSRC() uint32 {
 	return d.ssrc
 }
 
+// RTXSSRC returns the SSRC of the RTX repair stream, 0 if RTX was not negotiated
+func (d *DownTrack) RTXSSRC() uint32 {
+	return d.rtxSSRC
+}
+
 func (d *DownTrack) Stop() error {
 	if tr := d.transceiver.Load(); tr != nil {
 		return tr.Stop()
@@ -694,13 +773,107 @@ func (d *DownTrack) keyFrameRequester() {
 
 		locked, layer := d.forwarder.CheckSync()
 		if !locked && layer != buffer.InvalidLayerSpatial && d.writable.Load() {
-			d.params.Logger.Debugw("sending PLI for layer lock", "layer", layer)
-			d.params.Receiver.SendPLI(layer, false)
-			d.rtpStats.UpdateLayerLockPliAndTime(1)
+			if d.params.Receiver.ReplayKeyFrame(d, layer) {
+				// receiver retains a recent key frame, no need to wait for the publisher
+				d.params.Logger.Debugw("replaying recent key frame for layer lock", "layer", layer)
+			} else {
+				d.params.Logger.Debugw("sending PLI for layer lock", "layer", layer)
+				d.params.Receiver.SendPLI(layer, false)
+				d.rtpStats.UpdateLayerLockPliAndTime(1)
+			}
 		}
 	}
 }
 
+// watchdog checks for
+//  1. up track stopping to send media while not muted. Blank frames are sent
+//     so that the subscriber does not freeze on the last decoded frame (video) or keep
+//     playing out residual noise (audio). A stall is cleared by the next packet from up track.
+//  2. forwarding freezing while up track is sending, for example a layer switch
+//     waiting on a key frame that never arrives. Forwarder is resynced and a key frame
+//     requested instead of waiting for the subscriber to restart the track.
+func (d *DownTrack) watchdog() {
+	interval := d.params.BlankFramesOnStall
+	if interval == 0 || (d.params.FreezeRecovery != 0 && d.params.FreezeRecovery < interval) {
+		interval = d.params.FreezeRecovery
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	for !d.IsClosed() {
+		<-ticker.C
+
+		d.checkStallAndFreeze()
+	}
+}
+
+func (d *DownTrack) checkStallAndFreeze() {
+	// freeze timer runs only while forwarding is expected
+	if !d.isFreezeCheckable() {
+		d.lastForwardedAt.Store(time.Now().UnixNano())
+	}
+
+	lastPacketAt := d.lastPacketAt.Load()
+	if lastPacketAt == 0 || d.stalled.Load() || !d.writable.Load() || !d.rtpStats.IsActive() || d.forwarder.IsAnyMuted() {
+		return
+	}
+	if d.kind == webrtc.RTPCodecTypeVideo && d.forwarder.PauseReason() == VideoPauseReasonBandwidth {
+		// not forwarding by choice, up track could still be sending
+		return
+	}
+
+	stalledFor := time.Since(time.Unix(0, lastPacketAt))
+	if d.params.BlankFramesOnStall > 0 && stalledFor >= d.params.BlankFramesOnStall {
+		d.params.Logger.Debugw("up track stalled, sending blank frames", "stalledFor", stalledFor)
+		d.stalled.Store(true)
+
+		// resync so that forwarding restarts cleanly (on a key frame for video) after the blank frames
+		d.forwarder.Resync()
+		d.writeBlankFrameRTP(RTPBlankFramesMuteSeconds, d.blankFramesGeneration.Inc())
+		return
+	}
+
+	if d.params.FreezeRecovery > 0 && stalledFor < d.params.FreezeRecovery {
+		if frozenFor := time.Since(time.Unix(0, d.lastForwardedAt.Load())); frozenFor >= d.params.FreezeRecovery {
+			d.recoverFromFreeze(frozenFor)
+		}
+	}
+}
+
+// isFreezeCheckable returns true when forwarding is expected, i. e. a video track which is
+// bound, not muted and allocated a layer
+func (d *DownTrack) isFreezeCheckable() bool {
+	return d.params.FreezeRecovery > 0 &&
+		d.kind == webrtc.RTPCodecTypeVideo &&
+		!d.stalled.Load() &&
+		d.writable.Load() &&
+		d.rtpStats.IsActive() &&
+		!d.forwarder.IsAnyMuted() &&
+		d.forwarder.PauseReason() == VideoPauseReasonNone &&
+		d.forwarder.TargetLayer().IsValid()
+}
+
+func (d *DownTrack) recoverFromFreeze(frozenFor time.Duration) {
+	targetLayer := d.forwarder.TargetLayer()
+	d.params.Logger.Infow(
+		"forwarding frozen while up track is active, restarting",
+		"frozenFor", frozenFor,
+		"currentLayer", d.forwarder.CurrentLayer(),
+		"targetLayer", targetLayer,
+	)
+	prometheus.RecordTrackFreeze()
+
+	// give the recovery a full period before checking again
+	d.lastForwardedAt.Store(time.Now().UnixNano())
+
+	d.forwarder.Resync()
+	if targetLayer.IsValid() {
+		d.params.Receiver.SendPLI(targetLayer.Spatial, true)
+		d.rtpStats.UpdateLayerLockPliAndTime(1)
+	}
+	d.postKeyFrameRequestEvent()
+}
+
 func (d *DownTrack) postMaxLayerNotifierEvent(event string) {
 	if d.kind != webrtc.RTPCodecTypeVideo {
 		return
@@ -748,7 +921,21 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		return nil
 	}
 
+	if d.params.BlankFramesOnStall > 0 || d.params.FreezeRecovery > 0 {
+		d.lastPacketAt.Store(time.Now().UnixNano())
+		if d.stalled.Swap(false) {
+			d.params.Logger.Debugw("up track resumed after stall")
+			// stop blank frames, forwarder was resynced on stall and
+			// needs a key frame (video) to resume after the blank key frames
+			d.blankFramesGeneration.Inc()
+			d.postKeyFrameRequestEvent()
+		}
+	}
+
 	tp, err := d.forwarder.GetTranslationParams(extPkt, layer)
+	if tp.isFrameBroken {
+		d.postKeyFrameRequestEvent()
+	}
 	if tp.shouldDrop {
 		if err != nil {
 			d.params.Logger.Errorw("could not get translation params", err)
@@ -756,21 +943,31 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		return err
 	}
 
-	poolEntity := PacketFactory.Get().(*[]byte)
-	payload := *poolEntity
-	copy(payload, tp.codecBytes)
-	n := copy(payload[len(tp.codecBytes):], extPkt.Packet.Payload[tp.incomingHeaderSize:])
-	if n != len(extPkt.Packet.Payload[tp.incomingHeaderSize:]) {
-		d.params.Logger.Errorw("payload overflow", nil, "want", len(extPkt.Packet.Payload[tp.incomingHeaderSize:]), "have", n)
-		PacketFactory.Put(poolEntity)
-		return ErrPayloadOverflow
+	var (
+		payload    []byte
+		payloadRef *utils.PacketRef
+	)
+	if extPkt.PayloadRef != nil && len(tp.codecBytes) == 0 && tp.incomingHeaderSize == 0 {
+		// payload is forwarded as is, share it with the other down tracks of the up track instead of copying
+		payload = extPkt.Packet.Payload
+		payloadRef = extPkt.PayloadRef.Retain()
+	} else {
+		payloadRef = utils.NewPacketRef()
+		payload = payloadRef.Bytes()
+		copy(payload, tp.codecBytes)
+		n := copy(payload[len(tp.codecBytes):], extPkt.Packet.Payload[tp.incomingHeaderSize:])
+		if n != len(extPkt.Packet.Payload[tp.incomingHeaderSize:]) {
+			d.params.Logger.Errorw("payload overflow", nil, "want", len(extPkt.Packet.Payload[tp.incomingHeaderSize:]), "have", n)
+			payloadRef.Release()
+			return ErrPayloadOverflow
+		}
+		payload = payload[:len(tp.codecBytes)+n]
 	}
-	payload = payload[:len(tp.codecBytes)+n]
 
 	hdr, err := d.getTranslatedRTPHeader(extPkt, &tp)
 	if err != nil {
 		d.params.Logger.Errorw("could not get translated RTP header", err)
-		PacketFactory.Put(poolEntity)
+		payloadRef.Release()
 		return err
 	}
 
@@ -844,6 +1041,10 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		)
 	}
 
+	if d.retransmitBuffer != nil {
+		d.retransmitBuffer.add(hdr, extensions, payload, payloadRef)
+	}
+
 	d.sendingPacket(
 		hdr,
 		len(payload),
@@ -863,9 +1064,12 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		AbsSendTimeExtID:   uint8(d.absSendTimeExtID),
 		TransportWideExtID: uint8(d.transportWideExtID),
 		WriteStream:        d.writeStream,
-		Pool:               PacketFactory,
-		PoolEntity:         poolEntity,
+		PayloadRef:         payloadRef,
 	})
+
+	if d.params.FreezeRecovery > 0 {
+		d.lastForwardedAt.Store(time.Now().UnixNano())
+	}
 	return nil
 }
 
@@ -982,6 +1186,12 @@ func (d *DownTrack) Mute(muted bool) {
 func (d *DownTrack) PubMute(pubMuted bool) {
 	changed := d.forwarder.PubMute(pubMuted)
 	d.handleMute(pubMuted, changed)
+
+	// forwarder is muted and resynced at this point, a few black key frames
+	// (in case of loss) make the decoder render black instead of the last frame
+	if changed && pubMuted && d.params.BlankFramesOnMute && d.kind == webrtc.RTPCodecTypeVideo {
+		d.writeBlankFrameRTP(RTPBlankFramesCloseSeconds, d.blankFramesGeneration.Load())
+	}
 }
 
 func (d *DownTrack) handleMute(muted bool, changed bool) {
@@ -1091,7 +1301,11 @@ func (d *DownTrack) CloseWithFlush(flush bool) {
 	d.bindLock.Unlock()
 
 	d.connectionStats.Close()
+	if d.retransmitBuffer != nil {
+		d.retransmitBuffer.close()
+	}
 
+	d.deltaInfoStream.Close()
 	d.rtpStats.Stop()
 	d.params.Logger.Debugw("rtp stats",
 		"direction", "downstream",
@@ -1160,6 +1374,11 @@ func (d *DownTrack) SeedState(state DownTrackState) {
 	d.forwarder.SeedState(state.ForwarderState)
 }
 
+// SeedRTPStats carries over stats of the track at the node the subscriber migrated from
+func (d *DownTrack) SeedRTPStats(stats *livekit.RTPStats) {
+	d.rtpStats.SeedProto(stats)
+}
+
 func (d *DownTrack) UpTrackLayersChange() {
 	if sal := d.getStreamAllocatorListener(); sal != nil {
 		sal.OnAvailableLayersChanged(d)
@@ -1382,7 +1601,7 @@ func (d *DownTrack) CreateSourceDescriptionChunks() []rtcp.SourceDescriptionChun
 	if !d.bound.Load() || transceiver == nil {
 		return nil
 	}
-	return []rtcp.SourceDescriptionChunk{
+	chunks := []rtcp.SourceDescriptionChunk{
 		{
 			Source: d.ssrc,
 			Items: []rtcp.SourceDescriptionItem{
@@ -1397,6 +1616,18 @@ func (d *DownTrack) CreateSourceDescriptionChunks() []rtcp.SourceDescriptionChun
 			},
 		},
 	}
+	if d.rtxSSRC != 0 {
+		chunks = append(chunks, rtcp.SourceDescriptionChunk{
+			Source: d.rtxSSRC,
+			Items: []rtcp.SourceDescriptionItem{
+				{
+					Type: rtcp.SDESCNAME,
+					Text: d.params.StreamID,
+				},
+			},
+		})
+	}
+	return chunks
 }
 
 func (d *DownTrack) CreateSenderReport() *rtcp.SenderReport {
@@ -1685,9 +1916,15 @@ func (d *DownTrack) handleRTCP(bytes []byte) {
 			// (libwebrtc/browsers don't send XR to calculate rtt, it only responds)
 			var lastRR uint32
 			for _, report := range p.Reports {
-				if rr, ok := report.(*rtcp.ReceiverReferenceTimeReportBlock); ok {
-					lastRR = uint32(rr.NTPTimestamp >> 16)
-					break
+				switch rr := report.(type) {
+				case *rtcp.ReceiverReferenceTimeReportBlock:
+					if lastRR == 0 {
+						lastRR = uint32(rr.NTPTimestamp >> 16)
+					}
+				case *rtcp.LossRLEReportBlock:
+					if rr.SSRC == d.ssrc {
+						d.rtpStats.UpdateFromLossRLE(rr)
+					}
 				}
 			}
 
@@ -1739,6 +1976,24 @@ func (d *DownTrack) SetActivePaddingOnMuteUpTrack() {
 	d.activePaddingOnMuteUpTrack.Store(true)
 }
 
+// SetLatencyBudget sets the latency the subscriber tolerates on this track, 0 for no budget.
+// With a budget, lost packets are retransmitted only while they can still arrive within it,
+// and a budget spanning several round trips allows more retransmission attempts.
+func (d *DownTrack) SetLatencyBudget(budget time.Duration) {
+	d.bindLock.Lock()
+	defer d.bindLock.Unlock()
+
+	if d.latencyBudget == budget {
+		return
+	}
+
+	d.params.Logger.Debugw("setting latency budget", "budget", budget)
+	d.latencyBudget = budget
+	if d.sequencer != nil {
+		d.sequencer.setLatencyBudget(budget)
+	}
+}
+
 func (d *DownTrack) retransmitPackets(nacks []uint16) {
 	if d.sequencer == nil {
 		return
@@ -1753,12 +2008,13 @@ func (d *DownTrack) retransmitPackets(nacks []uint16) {
 		return
 	}
 
-	src := PacketFactory.Get().(*[]byte)
-	defer PacketFactory.Put(src)
+	src := utils.NewPacketRef()
+	defer src.Release()
 
 	nackAcks := uint32(0)
 	nackMisses := uint32(0)
 	numRepeatedNACKs := uint32(0)
+	nackBudgetExceeded := uint32(0)
 	// STREAM-ALLOCATOR-DATA nackInfos := make([]NackInfo, 0, len(filtered))
 	for _, epm := range d.sequencer.getExtPacketMetas(filtered) {
 		if disallowedLayers[epm.layer] {
@@ -1774,71 +2030,108 @@ func (d *DownTrack) retransmitPackets(nacks []uint16) {
 		})
 		*/
 
-		pktBuff := *src
+		var (
+			hdr        rtp.Header
+			extensions []pacer.ExtensionData
+		)
+		payloadRef := utils.NewPacketRef()
+		payload := payloadRef.Bytes()
+		osnSize := 0
+		if d.rtxPayloadType != 0 {
+			// RFC 4588: RTX payload starts with the original sequence number
+			osnSize = 2
+		}
+
+		pktBuff := src.Bytes()
 		n, err := d.params.Receiver.ReadRTP(pktBuff, uint8(epm.layer), epm.sourceSeqNo)
 		if err != nil {
 			if err == io.EOF {
+				payloadRef.Release()
 				break
 			}
-			nackMisses++
-			continue
-		}
 
-		if epm.nacked > 1 {
-			numRepeatedNACKs++
+			// upstream packet is not available, answer from packets sent on this down track if possible
+			found := false
+			if d.retransmitBuffer != nil {
+				var m int
+				hdr, extensions, m, found = d.retransmitBuffer.get(epm.targetSeqNo, payload[osnSize:])
+				payload = payload[:osnSize+m]
+			}
+			if !found {
+				payloadRef.Release()
+				nackMisses++
+				continue
+			}
+		} else {
+			var pkt rtp.Packet
+			if err = pkt.Unmarshal(pktBuff[:n]); err != nil {
+				d.params.Logger.Errorw("could not unmarshal rtp packet in retransmit", err)
+				payloadRef.Release()
+				continue
+			}
+			hdr = pkt.Header
+			hdr.Marker = epm.marker
+			hdr.SequenceNumber = epm.targetSeqNo
+			hdr.Timestamp = epm.timestamp
+			hdr.SSRC = d.ssrc
+			hdr.PayloadType = d.getTranslatedPayloadType(hdr.PayloadType)
+
+			if len(epm.codecBytesSlice) != 0 {
+				n := copy(payload[osnSize:], epm.codecBytesSlice)
+				m := copy(payload[osnSize+n:], pkt.Payload[epm.numCodecBytesIn:])
+				payload = payload[:osnSize+n+m]
+			} else {
+				copy(payload[osnSize:], epm.codecBytes[:epm.numCodecBytesOut])
+				copy(payload[osnSize+int(epm.numCodecBytesOut):], pkt.Payload[epm.numCodecBytesIn:])
+				payload = payload[:osnSize+int(epm.numCodecBytesOut)+len(pkt.Payload)-int(epm.numCodecBytesIn)]
+			}
+
+			if d.dependencyDescriptorExtID != 0 {
+				var ddBytes []byte
+				if len(epm.ddBytesSlice) != 0 {
+					ddBytes = epm.ddBytesSlice
+				} else {
+					ddBytes = epm.ddBytes[:epm.ddBytesSize]
+				}
+				extensions = append(
+					extensions,
+					pacer.ExtensionData{
+						ID:      uint8(d.dependencyDescriptorExtID),
+						Payload: ddBytes,
+					},
+				)
+			}
+			if d.absCaptureTimeExtID != 0 && len(epm.actBytes) != 0 {
+				extensions = append(
+					extensions,
+					pacer.ExtensionData{
+						ID:      uint8(d.absCaptureTimeExtID),
+						Payload: epm.actBytes,
+					},
+				)
+			}
 		}
 
-		var pkt rtp.Packet
-		if err = pkt.Unmarshal(pktBuff[:n]); err != nil {
-			d.params.Logger.Errorw("could not unmarshal rtp packet in retransmit", err)
+		if d.retransmitBudget != nil && !d.retransmitBudget.allow(hdr.MarshalSize()+len(payload), time.Now()) {
+			payloadRef.Release()
+			nackBudgetExceeded++
 			continue
 		}
-		pkt.Header.Marker = epm.marker
-		pkt.Header.SequenceNumber = epm.targetSeqNo
-		pkt.Header.Timestamp = epm.timestamp
-		pkt.Header.SSRC = d.ssrc
-		pkt.Header.PayloadType = d.getTranslatedPayloadType(pkt.Header.PayloadType)
-
-		poolEntity := PacketFactory.Get().(*[]byte)
-		payload := *poolEntity
-		if len(epm.codecBytesSlice) != 0 {
-			n := copy(payload, epm.codecBytesSlice)
-			m := copy(payload[n:], pkt.Payload[epm.numCodecBytesIn:])
-			payload = payload[:n+m]
-		} else {
-			copy(payload, epm.codecBytes[:epm.numCodecBytesOut])
-			copy(payload[epm.numCodecBytesOut:], pkt.Payload[epm.numCodecBytesIn:])
-			payload = payload[:int(epm.numCodecBytesOut)+len(pkt.Payload)-int(epm.numCodecBytesIn)]
-		}
 
-		var extensions []pacer.ExtensionData
-		if d.dependencyDescriptorExtID != 0 {
-			var ddBytes []byte
-			if len(epm.ddBytesSlice) != 0 {
-				ddBytes = epm.ddBytesSlice
-			} else {
-				ddBytes = epm.ddBytes[:epm.ddBytesSize]
-			}
-			extensions = append(
-				extensions,
-				pacer.ExtensionData{
-					ID:      uint8(d.dependencyDescriptorExtID),
-					Payload: ddBytes,
-				},
-			)
+		if epm.nacked > 1 {
+			numRepeatedNACKs++
 		}
-		if d.absCaptureTimeExtID != 0 && len(epm.actBytes) != 0 {
-			extensions = append(
-				extensions,
-				pacer.ExtensionData{
-					ID:      uint8(d.absCaptureTimeExtID),
-					Payload: epm.actBytes,
-				},
-			)
+
+		if osnSize != 0 {
+			// packet goes out on the repair stream with its own sequence number space
+			binary.BigEndian.PutUint16(payload, epm.targetSeqNo)
+			hdr.SSRC = d.rtxSSRC
+			hdr.PayloadType = d.rtxPayloadType
+			hdr.SequenceNumber = uint16(d.rtxSequenceNumber.Inc())
 		}
 
 		d.sendingPacket(
-			&pkt.Header,
+			&hdr,
 			len(payload),
 			&sendPacketMetadata{
 				layer:             int32(epm.layer),
@@ -1849,20 +2142,22 @@ func (d *DownTrack) retransmitPackets(nacks []uint16) {
 			},
 		)
 		d.pacer.Enqueue(pacer.Packet{
-			Header:             &pkt.Header,
+			Header:             &hdr,
 			Extensions:         extensions,
 			Payload:            payload,
 			AbsSendTimeExtID:   uint8(d.absSendTimeExtID),
 			TransportWideExtID: uint8(d.transportWideExtID),
 			WriteStream:        d.writeStream,
-			Pool:               PacketFactory,
-			PoolEntity:         poolEntity,
+			PayloadRef:         payloadRef,
 		})
 	}
 
 	d.totalRepeatedNACKs.Add(numRepeatedNACKs)
 
 	d.rtpStats.UpdateNackProcessed(nackAcks, nackMisses, numRepeatedNACKs)
+	if nackBudgetExceeded != 0 {
+		d.rtpStats.UpdateNackBudgetExceeded(nackBudgetExceeded)
+	}
 	/* STREAM-ALLOCATOR-DATA
 	// STREAM-ALLOCATOR-EXPERIMENTAL-TODO-START
 	// Need to check on the following
@@ -1882,6 +2177,27 @@ func (d *DownTrack) retransmitPackets(nacks []uint16) {
 	*/
 }
 
+// RTXSSRCForPrimary derives the preferred SSRC of the RTX repair stream from the SSRC of the primary stream.
+// The signalled repair SSRC may differ if the derived one collides with another SSRC in the session.
+func RTXSSRCForPrimary(ssrc uint32) uint32 {
+	return ssrc ^ 0x5A5A5A5A
+}
+
+func getRTXPayloadType(codecs []webrtc.RTPCodecParameters, primaryPT webrtc.PayloadType) webrtc.PayloadType {
+	apt := fmt.Sprintf("apt=%d", primaryPT)
+	for _, c := range codecs {
+		if !strings.EqualFold(c.MimeType, MimeTypeVideoRTX) {
+			continue
+		}
+		for _, param := range strings.Split(c.SDPFmtpLine, ";") {
+			if strings.TrimSpace(param) == apt {
+				return c.PayloadType
+			}
+		}
+	}
+	return 0
+}
+
 func (d *DownTrack) getTranslatedRTPHeader(extPkt *buffer.ExtPacket, tp *TranslationParams) (*rtp.Header, error) {
 	hdr := extPkt.Packet.Header
 	hdr.PayloadType = d.getTranslatedPayloadType(hdr.PayloadType)
@@ -1906,7 +2222,8 @@ func (d *DownTrack) getTranslatedPayloadType(src uint8) uint8 {
 
 func (d *DownTrack) DebugInfo() map[string]interface{} {
 	stats := map[string]interface{}{
-		"LastPli": d.rtpStats.LastPli(),
+		"LastPli":            d.rtpStats.LastPli(),
+		"NackBudgetExceeded": d.rtpStats.NackBudgetExceeded(),
 	}
 	stats["RTPMunger"] = d.forwarder.RTPMungerDebugInfo()
 
@@ -0,0 +1,236 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/pacer"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+func init() {
+	prometheus.Init("test", livekit.NodeType_SERVER, config.PrometheusConfig{})
+}
+
+type testTrackReceiver struct {
+	TrackReceiver
+
+	plis atomic.Int32
+}
+
+func (r *testTrackReceiver) TrackID() livekit.TrackID { return "TR_test" }
+
+func (r *testTrackReceiver) DeleteDownTrack(_participantID livekit.ParticipantID) {}
+
+func (r *testTrackReceiver) SendPLI(_layer int32, _force bool) { r.plis.Inc() }
+
+func (r *testTrackReceiver) ReadRTP(buf []byte, _layer uint8, sn uint16) (int, error) {
+	pkt := rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    96,
+			SequenceNumber: sn,
+			SSRC:           4321,
+		},
+		Payload: []byte{1, 2, 3},
+	}
+	return pkt.MarshalTo(buf)
+}
+
+type testPacer struct {
+	packets []pacer.Packet
+}
+
+func (p *testPacer) Enqueue(pkt pacer.Packet)    { p.packets = append(p.packets, pkt) }
+func (p *testPacer) Stop()                       {}
+func (p *testPacer) SetInterval(_ time.Duration) {}
+func (p *testPacer) SetBitrate(_bitrate int)     {}
+
+func TestDownTrackRetransmitRTX(t *testing.T) {
+	p := &testPacer{}
+	d, err := NewDownTrack(DowntrackParams{
+		Codecs: []webrtc.RTPCodecParameters{
+			{
+				RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000},
+				PayloadType:        96,
+			},
+		},
+		Receiver: &testTrackReceiver{},
+		Pacer:    p,
+		Logger:   logger.GetLogger(),
+	})
+	require.NoError(t, err)
+	defer d.Close()
+
+	d.ssrc = 1234
+	d.payloadType = 96
+	d.sequencer = newSequencer(16, false, logger.GetLogger())
+	d.sequencer.push(time.Now().UnixNano(), 5, 10, 3000, true, 0, nil, 0, nil, nil)
+
+	// without RTX, retransmission goes out on the primary stream
+	time.Sleep((ignoreRetransmission + 10) * time.Millisecond)
+	d.retransmitPackets([]uint16{10})
+	require.Len(t, p.packets, 1)
+	require.Equal(t, uint32(1234), p.packets[0].Header.SSRC)
+	require.Equal(t, uint8(96), p.packets[0].Header.PayloadType)
+	require.Equal(t, uint16(10), p.packets[0].Header.SequenceNumber)
+	require.Equal(t, uint32(3000), p.packets[0].Header.Timestamp)
+	require.Equal(t, []byte{1, 2, 3}, p.packets[0].Payload)
+
+	// with RTX, retransmission goes out on the repair stream with the original sequence number prepended
+	d.rtxSSRC = 5678
+	d.rtxPayloadType = 97
+	d.rtxSequenceNumber.Store(100)
+	time.Sleep((ignoreRetransmission + 10) * time.Millisecond)
+	d.retransmitPackets([]uint16{10})
+	require.Len(t, p.packets, 2)
+	require.Equal(t, uint32(5678), p.packets[1].Header.SSRC)
+	require.Equal(t, uint8(97), p.packets[1].Header.PayloadType)
+	require.Equal(t, uint16(101), p.packets[1].Header.SequenceNumber)
+	require.Equal(t, uint32(3000), p.packets[1].Header.Timestamp)
+	require.Equal(t, []byte{0, 10, 1, 2, 3}, p.packets[1].Payload)
+
+	require.Equal(t, uint32(2), d.rtpStats.ToProto().NackAcks)
+
+	// retransmissions over budget are acknowledged, but dropped
+	d.retransmitBudget = newRetransmitBudget(1)
+	d.retransmitBudget.tokens = 0
+	d.retransmitBudget.lastRefill = time.Now().Add(time.Minute)
+	time.Sleep((ignoreRetransmission + 10) * time.Millisecond)
+	d.retransmitPackets([]uint16{10})
+	require.Len(t, p.packets, 2)
+	require.Equal(t, uint32(3), d.rtpStats.ToProto().NackAcks)
+	require.Zero(t, d.rtpStats.ToProto().NackMisses)
+	require.Equal(t, uint32(1), d.rtpStats.NackBudgetExceeded())
+}
+
+func newTestVideoDownTrack(t *testing.T, receiver *testTrackReceiver) *DownTrack {
+	d, err := NewDownTrack(DowntrackParams{
+		Codecs: []webrtc.RTPCodecParameters{
+			{
+				RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8, ClockRate: 90000},
+				PayloadType:        96,
+			},
+		},
+		Receiver: receiver,
+		Pacer:    &testPacer{},
+		Logger:   logger.GetLogger(),
+	})
+	require.NoError(t, err)
+	t.Cleanup(d.Close)
+
+	// forwarding an allocated layer
+	d.writable.Store(true)
+	d.rtpStats.Update(time.Now().UnixNano(), 1, 1000, true, 12, 100, 0)
+	d.forwarder.vls.SetTarget(buffer.VideoLayer{Spatial: 0, Temporal: 0})
+	d.forwarder.lastAllocation.PauseReason = VideoPauseReasonNone
+	return d
+}
+
+func TestDownTrackStall(t *testing.T) {
+	t.Run("stalled up track sends blank frames", func(t *testing.T) {
+		d := newTestVideoDownTrack(t, &testTrackReceiver{})
+		// set after creation to drive checks without the watchdog goroutine
+		d.params.BlankFramesOnStall = time.Second
+		d.lastPacketAt.Store(time.Now().Add(-2 * time.Second).UnixNano())
+
+		d.checkStallAndFreeze()
+		require.True(t, d.stalled.Load())
+		require.Equal(t, uint32(1), d.blankFramesGeneration.Load())
+
+		// stall is reported once until up track resumes
+		d.checkStallAndFreeze()
+		require.Equal(t, uint32(1), d.blankFramesGeneration.Load())
+	})
+
+	t.Run("muted track is not stalled", func(t *testing.T) {
+		d := newTestVideoDownTrack(t, &testTrackReceiver{})
+		d.params.BlankFramesOnStall = time.Second
+		d.lastPacketAt.Store(time.Now().Add(-2 * time.Second).UnixNano())
+		d.forwarder.Mute(true, true)
+
+		d.checkStallAndFreeze()
+		require.False(t, d.stalled.Load())
+		require.Zero(t, d.blankFramesGeneration.Load())
+	})
+
+	t.Run("paused track is not stalled", func(t *testing.T) {
+		d := newTestVideoDownTrack(t, &testTrackReceiver{})
+		d.params.BlankFramesOnStall = time.Second
+		d.lastPacketAt.Store(time.Now().Add(-2 * time.Second).UnixNano())
+		d.forwarder.lastAllocation.PauseReason = VideoPauseReasonBandwidth
+
+		d.checkStallAndFreeze()
+		require.False(t, d.stalled.Load())
+		require.Zero(t, d.blankFramesGeneration.Load())
+	})
+}
+
+func TestDownTrackFreezeRecovery(t *testing.T) {
+	t.Run("frozen forwarding requests a key frame", func(t *testing.T) {
+		receiver := &testTrackReceiver{}
+		d := newTestVideoDownTrack(t, receiver)
+		// set after creation to drive checks without the watchdog goroutine
+		d.params.FreezeRecovery = time.Second
+		d.lastPacketAt.Store(time.Now().UnixNano())
+		d.lastForwardedAt.Store(time.Now().Add(-2 * time.Second).UnixNano())
+
+		require.True(t, d.isFreezeCheckable())
+		d.checkStallAndFreeze()
+		require.GreaterOrEqual(t, receiver.plis.Load(), int32(1))
+		require.Equal(t, uint32(1), d.rtpStats.ToProto().LayerLockPlis)
+		require.Less(t, time.Since(time.Unix(0, d.lastForwardedAt.Load())), time.Second)
+	})
+
+	t.Run("muted track is not recovered", func(t *testing.T) {
+		receiver := &testTrackReceiver{}
+		d := newTestVideoDownTrack(t, receiver)
+		d.params.FreezeRecovery = time.Second
+		d.lastPacketAt.Store(time.Now().UnixNano())
+		d.lastForwardedAt.Store(time.Now().Add(-2 * time.Second).UnixNano())
+		d.forwarder.Mute(true, true)
+
+		require.False(t, d.isFreezeCheckable())
+		d.checkStallAndFreeze()
+		require.Zero(t, receiver.plis.Load())
+		// freeze timer restarts when forwarding is expected again
+		require.Less(t, time.Since(time.Unix(0, d.lastForwardedAt.Load())), time.Second)
+	})
+
+	t.Run("paused track is not recovered", func(t *testing.T) {
+		receiver := &testTrackReceiver{}
+		d := newTestVideoDownTrack(t, receiver)
+		d.params.FreezeRecovery = time.Second
+		d.lastPacketAt.Store(time.Now().UnixNano())
+		d.lastForwardedAt.Store(time.Now().Add(-2 * time.Second).UnixNano())
+		d.forwarder.lastAllocation.PauseReason = VideoPauseReasonBandwidth
+
+		require.False(t, d.isFreezeCheckable())
+		d.checkStallAndFreeze()
+		require.Zero(t, receiver.plis.Load())
+	})
+}
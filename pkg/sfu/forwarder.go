@@ -32,6 +32,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/codecmunger"
 	dd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/dependencydescriptor"
+	"github.com/livekit/livekit-server/pkg/sfu/utils"
 	"github.com/livekit/livekit-server/pkg/sfu/videolayerselector"
 	"github.com/livekit/livekit-server/pkg/sfu/videolayerselector/temporallayerselector"
 )
@@ -182,6 +183,7 @@ type TranslationParams struct {
 	incomingHeaderSize int
 	codecBytes         []byte
 	marker             bool
+	isFrameBroken      bool
 }
 
 // -------------------------------------------------------------------
@@ -272,9 +274,17 @@ type Forwarder struct {
 
 	rtpMunger *RTPMunger
 
-	vls videolayerselector.VideoLayerSelector
+	vls                     videolayerselector.VideoLayerSelector
+	isH264TemporalAvailable bool
 
 	codecMunger codecmunger.CodecMunger
+
+	layerSelectionPolicy LayerSelectionPolicy
+
+	frameIntegrity         bool
+	isFrameIntegrityActive bool
+	extLastFrameTS         uint64
+	isLastFrameEnded       bool
 }
 
 func NewForwarder(
@@ -293,6 +303,7 @@ func NewForwarder(
 		rtpMunger:               NewRTPMunger(logger),
 		vls:                     videolayerselector.NewNull(logger),
 		codecMunger:             codecmunger.NewNull(logger),
+		layerSelectionPolicy:    BalancedLayerSelectionPolicy{},
 	}
 
 	if f.kind == webrtc.RTPCodecTypeVideo {
@@ -301,6 +312,29 @@ func NewForwarder(
 	return f
 }
 
+// SetFrameIntegrity enables dropping of H.264 frames broken by mid-frame loss, the forwarder
+// drops the remainder of a broken frame and waits for a key frame instead of forwarding a partial frame.
+// Has to be called before the codec is determined.
+func (f *Forwarder) SetFrameIntegrity(enabled bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.frameIntegrity = enabled
+}
+
+// SetLayerSelectionPolicy sets the policy used to rank layers when allocating under constrained bandwidth,
+// nil keeps the current policy.
+func (f *Forwarder) SetLayerSelectionPolicy(policy LayerSelectionPolicy) {
+	if policy == nil {
+		return
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.layerSelectionPolicy = policy
+}
+
 func (f *Forwarder) SetMaxPublishedLayer(maxPublishedLayer int32) bool {
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -346,6 +380,14 @@ func (f *Forwarder) DetermineCodec(codec webrtc.RTPCodecCapability, extensions [
 		}
 		return false
 	}
+	frameMarkingAvailable := func(exts []webrtc.RTPHeaderExtensionParameter) bool {
+		for _, ext := range exts {
+			if ext.URI == buffer.FrameMarkingURI {
+				return true
+			}
+		}
+		return false
+	}
 
 	switch strings.ToLower(codec.MimeType) {
 	case "video/vp8":
@@ -363,6 +405,12 @@ func (f *Forwarder) DetermineCodec(codec webrtc.RTPCodecCapability, extensions [
 		} else {
 			f.vls = videolayerselector.NewSimulcast(f.logger)
 		}
+		// temporal layers of H.264 are known only through frame marking
+		f.isH264TemporalAvailable = frameMarkingAvailable(extensions)
+		if f.isH264TemporalAvailable {
+			f.vls.SetTemporalLayerSelector(temporallayerselector.NewH264(f.logger))
+		}
+		f.isFrameIntegrityActive = f.frameIntegrity
 
 	case "video/vp9":
 		// DD-TODO : we only enable dd layer selector for av1/vp9 now, in the future we can enable it for vp8 too
@@ -1003,46 +1051,16 @@ func (f *Forwarder) ProvisionalAllocateGetCooperativeTransition(allowOvershoot b
 		}
 	}
 
-	findNextLayer := func(
-		minSpatial, maxSpatial int32,
-		minTemporal, maxTemporal int32,
-	) (buffer.VideoLayer, int64) {
-		layers := buffer.InvalidLayer
-		bw := int64(0)
-		for s := minSpatial; s <= maxSpatial; s++ {
-			for t := minTemporal; t <= maxTemporal; t++ {
-				if f.provisional.bitrates[s][t] != 0 {
-					layers = buffer.VideoLayer{Spatial: s, Temporal: t}
-					bw = f.provisional.bitrates[s][t]
-					break
-				}
-			}
-
-			if bw != 0 {
-				break
-			}
-		}
-
-		return layers, bw
-	}
-
 	targetLayer := buffer.InvalidLayer
 	bandwidthRequired := int64(0)
 	if !existingTargetLayer.IsValid() {
-		// currently not streaming, find minimal
-		// NOTE: a layer in feed could have paused and there could be other options than going back to minimal,
-		// but the cooperative scheme knocks things back to minimal
-		targetLayer, bandwidthRequired = findNextLayer(
-			0, f.provisional.maxLayer.Spatial,
-			0, f.provisional.maxLayer.Temporal,
-		)
-
-		// could not find a minimal layer, overshoot if allowed
-		if bandwidthRequired == 0 && f.provisional.maxLayer.IsValid() && allowOvershoot && f.vls.IsOvershootOkay() {
-			targetLayer, bandwidthRequired = findNextLayer(
-				f.provisional.maxLayer.Spatial+1, buffer.DefaultMaxLayerSpatial,
-				0, buffer.DefaultMaxLayerTemporal,
-			)
+		// currently not streaming, find the first available layer that policy prefers to start with
+		for _, layer := range f.layerSelectionPolicy.StartLayers(f.provisional.maxLayer, allowOvershoot && f.vls.IsOvershootOkay()) {
+			if bw := f.provisional.bitrates[layer.Spatial][layer.Temporal]; bw != 0 {
+				targetLayer = layer
+				bandwidthRequired = bw
+				break
+			}
 		}
 	}
 
@@ -1073,10 +1091,8 @@ func (f *Forwarder) ProvisionalAllocateGetBestWeightedTransition() (VideoTransit
 	// This tries to figure out how much this track can contribute back to the pool to enable the track that needs to be unpaused.
 	//   1. Track muted OR feed dry - can contribute everything back in case it was using bandwidth.
 	//   2. Look at all possible down transitions from current target and find the best offer.
-	//      Best offer is calculated as bandwidth saved moving to a down layer divided by cost.
-	//      Cost has two components
-	//        a. Transition cost: Spatial layer switch is expensive due to key frame requirement, but temporal layer switch is free.
-	//        b. Quality cost: The farther away from desired layers, the higher the quality cost.
+	//      Best offer is calculated as bandwidth saved moving to a down layer divided by cost,
+	//      cost of a down transition is determined by the layer selection policy.
 	//
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -1128,24 +1144,19 @@ func (f *Forwarder) ProvisionalAllocateGetBestWeightedTransition() (VideoTransit
 				break
 			}
 
+			layer := buffer.VideoLayer{Spatial: s, Temporal: t}
 			bandwidthDelta := int64(math.Max(float64(0), float64(existingBandwidthNeeded-f.provisional.bitrates[s][t])))
 
-			transitionCost := int32(0)
-			// SVC-TODO: SVC will need a different cost transition
-			if targetLayer.Spatial != s {
-				transitionCost = TransitionCostSpatial
-			}
-
-			qualityCost := (maxReachableLayerTemporal+1)*(targetLayer.Spatial-s) + (targetLayer.Temporal - t)
+			cost := f.layerSelectionPolicy.DowngradeCost(targetLayer, layer, maxReachableLayerTemporal)
 
 			value := float32(0)
-			if (transitionCost + qualityCost) != 0 {
-				value = float32(bandwidthDelta) / float32(transitionCost+qualityCost)
+			if cost != 0 {
+				value = float32(bandwidthDelta) / float32(cost)
 			}
 			if value > bestValue || (value == bestValue && bandwidthDelta > bestBandwidthDelta) {
 				bestValue = value
 				bestBandwidthDelta = bandwidthDelta
-				bestLayer = buffer.VideoLayer{Spatial: s, Temporal: t}
+				bestLayer = layer
 			}
 		}
 	}
@@ -1266,85 +1277,42 @@ func (f *Forwarder) AllocateNextHigher(availableChannelCapacity int64, available
 		alreadyAllocated = brs[targetLayer.Spatial][targetLayer.Temporal]
 	}
 
-	doAllocation := func(
-		minSpatial, maxSpatial int32,
-		minTemporal, maxTemporal int32,
-	) (bool, VideoAllocation, bool) {
-		for s := minSpatial; s <= maxSpatial; s++ {
-			for t := minTemporal; t <= maxTemporal; t++ {
-				bandwidthRequested := brs[s][t]
-				if bandwidthRequested == 0 {
-					continue
-				}
-
-				if (!allowOvershoot || !f.vls.IsOvershootOkay()) && bandwidthRequested-alreadyAllocated > availableChannelCapacity {
-					// next higher available layer does not fit, return
-					return true, f.lastAllocation, false
-				}
-
-				newTargetLayer := buffer.VideoLayer{Spatial: s, Temporal: t}
-				alloc := VideoAllocation{
-					IsDeficient:         true,
-					BandwidthRequested:  bandwidthRequested,
-					BandwidthDelta:      bandwidthRequested - alreadyAllocated,
-					BandwidthNeeded:     optimalBandwidthNeeded,
-					Bitrates:            brs,
-					TargetLayer:         newTargetLayer,
-					RequestLayerSpatial: newTargetLayer.Spatial,
-					MaxLayer:            maxLayer,
-					DistanceToDesired: getDistanceToDesired(
-						f.muted,
-						f.pubMuted,
-						maxSeenLayer,
-						availableLayers,
-						brs,
-						newTargetLayer,
-						maxLayer,
-					),
-				}
-				if newTargetLayer.GreaterThan(maxLayer) || bandwidthRequested >= optimalBandwidthNeeded {
-					alloc.IsDeficient = false
-				}
-
-				return true, f.updateAllocation(alloc, "next-higher"), true
-			}
+	overshoot := allowOvershoot && f.vls.IsOvershootOkay()
+	for _, newTargetLayer := range f.layerSelectionPolicy.NextHigherLayers(targetLayer, maxLayer, overshoot) {
+		bandwidthRequested := brs[newTargetLayer.Spatial][newTargetLayer.Temporal]
+		if bandwidthRequested == 0 {
+			continue
 		}
 
-		return false, VideoAllocation{}, false
-	}
-
-	done := false
-	var allocation VideoAllocation
-	boosted := false
-
-	// try moving temporal layer up in currently streaming spatial layer
-	if targetLayer.IsValid() {
-		done, allocation, boosted = doAllocation(
-			targetLayer.Spatial, targetLayer.Spatial,
-			targetLayer.Temporal+1, maxLayer.Temporal,
-		)
-		if done {
-			return allocation, boosted
+		if !overshoot && bandwidthRequested-alreadyAllocated > availableChannelCapacity {
+			// next higher available layer does not fit, return
+			return f.lastAllocation, false
 		}
-	}
 
-	// try moving spatial layer up if temporal layer move up is not available
-	done, allocation, boosted = doAllocation(
-		targetLayer.Spatial+1, maxLayer.Spatial,
-		0, maxLayer.Temporal,
-	)
-	if done {
-		return allocation, boosted
-	}
-
-	if allowOvershoot && f.vls.IsOvershootOkay() && maxLayer.IsValid() {
-		done, allocation, boosted = doAllocation(
-			maxLayer.Spatial+1, buffer.DefaultMaxLayerSpatial,
-			0, buffer.DefaultMaxLayerTemporal,
-		)
-		if done {
-			return allocation, boosted
+		alloc := VideoAllocation{
+			IsDeficient:         true,
+			BandwidthRequested:  bandwidthRequested,
+			BandwidthDelta:      bandwidthRequested - alreadyAllocated,
+			BandwidthNeeded:     optimalBandwidthNeeded,
+			Bitrates:            brs,
+			TargetLayer:         newTargetLayer,
+			RequestLayerSpatial: newTargetLayer.Spatial,
+			MaxLayer:            maxLayer,
+			DistanceToDesired: getDistanceToDesired(
+				f.muted,
+				f.pubMuted,
+				maxSeenLayer,
+				availableLayers,
+				brs,
+				newTargetLayer,
+				maxLayer,
+			),
 		}
+		if newTargetLayer.GreaterThan(maxLayer) || bandwidthRequested >= optimalBandwidthNeeded {
+			alloc.IsDeficient = false
+		}
+
+		return f.updateAllocation(alloc, "next-higher"), true
 	}
 
 	return f.lastAllocation, false
@@ -1374,66 +1342,21 @@ func (f *Forwarder) GetNextHigherTransition(brs Bitrates, allowOvershoot bool) (
 		alreadyAllocated = brs[targetLayer.Spatial][targetLayer.Temporal]
 	}
 
-	findNextHigher := func(
-		minSpatial, maxSpatial int32,
-		minTemporal, maxTemporal int32,
-	) (bool, VideoTransition, bool) {
-		for s := minSpatial; s <= maxSpatial; s++ {
-			for t := minTemporal; t <= maxTemporal; t++ {
-				bandwidthRequested := brs[s][t]
-				// traverse till finding a layer requiring more bits.
-				// NOTE: it possible that higher temporal layer of lower spatial layer
-				//       could use more bits than lower temporal layer of higher spatial layer.
-				if bandwidthRequested == 0 || bandwidthRequested < alreadyAllocated {
-					continue
-				}
-
-				transition := VideoTransition{
-					From:           targetLayer,
-					To:             buffer.VideoLayer{Spatial: s, Temporal: t},
-					BandwidthDelta: bandwidthRequested - alreadyAllocated,
-				}
-
-				return true, transition, true
-			}
-		}
-
-		return false, VideoTransition{}, false
-	}
-
-	done := false
-	var transition VideoTransition
-	isAvailable := false
-
-	// try moving temporal layer up in currently streaming spatial layer
-	maxLayer := f.vls.GetMax()
-	if targetLayer.IsValid() {
-		done, transition, isAvailable = findNextHigher(
-			targetLayer.Spatial, targetLayer.Spatial,
-			targetLayer.Temporal+1, maxLayer.Temporal,
-		)
-		if done {
-			return transition, isAvailable
+	overshoot := allowOvershoot && f.vls.IsOvershootOkay()
+	for _, layer := range f.layerSelectionPolicy.NextHigherLayers(targetLayer, f.vls.GetMax(), overshoot) {
+		bandwidthRequested := brs[layer.Spatial][layer.Temporal]
+		// traverse till finding a layer requiring more bits.
+		// NOTE: it possible that higher temporal layer of lower spatial layer
+		//       could use more bits than lower temporal layer of higher spatial layer.
+		if bandwidthRequested == 0 || bandwidthRequested < alreadyAllocated {
+			continue
 		}
-	}
-
-	// try moving spatial layer up if temporal layer move up is not available
-	done, transition, isAvailable = findNextHigher(
-		targetLayer.Spatial+1, maxLayer.Spatial,
-		0, maxLayer.Temporal,
-	)
-	if done {
-		return transition, isAvailable
-	}
 
-	if allowOvershoot && f.vls.IsOvershootOkay() && maxLayer.IsValid() {
-		done, transition, isAvailable = findNextHigher(
-			maxLayer.Spatial+1, buffer.DefaultMaxLayerSpatial,
-			0, buffer.DefaultMaxLayerTemporal,
-		)
-		if done {
-			return transition, isAvailable
-		}
+		return VideoTransition{
+			From:           targetLayer,
+			To:             layer,
+			BandwidthDelta: bandwidthRequested - alreadyAllocated,
+		}, true
 	}
 
 	return VideoTransition{}, false
@@ -1486,7 +1409,7 @@ func (f *Forwarder) Pause(availableLayers []int32, brs Bitrates) VideoAllocation
 
 func (f *Forwarder) updateAllocation(alloc VideoAllocation, reason string) VideoAllocation {
 	// restrict target temporal to 0 if codec does not support temporal layers
-	if alloc.TargetLayer.IsValid() && strings.ToLower(f.codec.MimeType) == "video/h264" {
+	if alloc.TargetLayer.IsValid() && strings.ToLower(f.codec.MimeType) == "video/h264" && !f.isH264TemporalAvailable {
 		alloc.TargetLayer.Temporal = 0
 	}
 
@@ -1706,15 +1629,7 @@ func (f *Forwarder) processSourceSwitch(extPkt *buffer.ExtPacket, layer int32) e
 	}
 
 	// adjust extRefTS to current packet's timestamp mapped to that of reference layer's
-	extRefTS = (extRefTS & 0xFFFF_FFFF_0000_0000) + uint64(refTS) + f.dummyStartTSOffset
-	lastTS := uint32(extLastTS)
-	refTS = uint32(extRefTS)
-	if (refTS-lastTS) < 1<<31 && refTS < lastTS {
-		extRefTS += (1 << 32)
-	}
-	if (lastTS-refTS) < 1<<31 && lastTS < refTS && extRefTS >= 1<<32 {
-		extRefTS -= (1 << 32)
-	}
+	extRefTS = utils.ExtendFrom(refTS+uint32(f.dummyStartTSOffset), extLastTS)
 
 	if f.getExpectedRTPTimestamp != nil {
 		tsExt, err := f.getExpectedRTPTimestamp(switchingAt)
@@ -1941,6 +1856,23 @@ func (f *Forwarder) getTranslationParamsVideo(extPkt *buffer.ExtPacket, layer in
 		return tp, err
 	}
 
+	if f.isFrameIntegrityActive && f.isFrameBrokenLocked(extPkt, &tp) {
+		// drop the remainder of the broken frame and all following frames
+		// which reference it, resync to wait for a key frame
+		f.logger.Debugw(
+			"dropping broken frame",
+			"extSequenceNumber", extPkt.ExtSequenceNumber,
+			"extTimestamp", extPkt.ExtTimestamp,
+			"extLastFrameTS", f.extLastFrameTS,
+			"isLastFrameEnded", f.isLastFrameEnded,
+		)
+		f.rtpMunger.PacketDropped(extPkt)
+		f.resyncLocked()
+		tp.shouldDrop = true
+		tp.isFrameBroken = true
+		return tp, nil
+	}
+
 	if FlagPauseOnDowngrade && f.isDeficientLocked() && f.vls.GetTarget().Spatial < f.vls.GetCurrent().Spatial {
 		//
 		// If target layer is lower than both the current and
@@ -1967,6 +1899,25 @@ func (f *Forwarder) getTranslationParamsVideo(extPkt *buffer.ExtPacket, layer in
 	return tp, nil
 }
 
+// should be called with lock held
+func (f *Forwarder) isFrameBrokenLocked(extPkt *buffer.ExtPacket, tp *TranslationParams) bool {
+	if tp.rtp.snOrdering == SequenceNumberOrderingOutOfOrder {
+		// late packet (e.g. a retransmission) of an already forwarded frame
+		return false
+	}
+
+	isBroken := false
+	if !tp.isResuming && !tp.isSwitching && tp.rtp.snOrdering == SequenceNumberOrderingGap {
+		// lost packets are either inside the current frame or at the end of the previous frame,
+		// in both cases the missing part could not be recovered in time
+		isBroken = extPkt.ExtTimestamp == f.extLastFrameTS || !f.isLastFrameEnded
+	}
+
+	f.extLastFrameTS = extPkt.ExtTimestamp
+	f.isLastFrameEnded = tp.marker
+	return isBroken
+}
+
 func (f *Forwarder) translateCodecHeader(extPkt *buffer.ExtPacket, tp *TranslationParams) error {
 	// codec specific forwarding check and any needed packet munging
 	tl := f.vls.SelectTemporal(extPkt)
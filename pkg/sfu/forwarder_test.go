@@ -1112,6 +1112,67 @@ func TestForwarderAllocateNextHigher(t *testing.T) {
 	require.True(t, boosted)
 }
 
+func TestForwarderLayerSelectionPolicy(t *testing.T) {
+	newVideoForwarder := func(policy LayerSelectionPolicy) *Forwarder {
+		f := newForwarder(testutils.TestVP8Codec, webrtc.RTPCodecTypeVideo)
+		f.SetLayerSelectionPolicy(policy)
+		f.SetMaxSpatialLayer(buffer.DefaultMaxLayerSpatial)
+		f.SetMaxTemporalLayer(buffer.DefaultMaxLayerTemporal)
+		f.SetMaxPublishedLayer(buffer.DefaultMaxLayerSpatial)
+		f.SetMaxTemporalLayerSeen(buffer.DefaultMaxLayerTemporal)
+		return f
+	}
+
+	// giving back bits, balanced goes down a spatial layer as temporal layers of top spatial layer save little,
+	// resolution stays at top spatial layer
+	availableLayers := []int32{0, 1, 2}
+	bitrates := Bitrates{
+		{1, 2, 3, 4},
+		{5, 6, 7, 8},
+		{38, 39, 40, 41},
+	}
+	expectedTargets := map[LayerSelectionPolicy]buffer.VideoLayer{
+		BalancedLayerSelectionPolicy{}:   {Spatial: 1, Temporal: 2},
+		ResolutionLayerSelectionPolicy{}: {Spatial: 2, Temporal: 0},
+	}
+	for policy, expectedTarget := range expectedTargets {
+		f := newVideoForwarder(policy)
+		f.ProvisionalAllocatePrepare(availableLayers, bitrates)
+		f.vls.SetTarget(buffer.VideoLayer{Spatial: 2, Temporal: 2})
+		f.lastAllocation.BandwidthRequested = bitrates[2][2]
+
+		transition, _, _ := f.ProvisionalAllocateGetBestWeightedTransition()
+		require.Equal(t, expectedTarget, transition.To)
+		require.Equal(t, bitrates[expectedTarget.Spatial][expectedTarget.Temporal]-bitrates[2][2], transition.BandwidthDelta)
+	}
+
+	// moving up, balanced moves temporal layer up first, resolution moves spatial layer up first
+	bitrates = Bitrates{
+		{2, 3, 0, 0},
+		{4, 0, 0, 5},
+		{0, 7, 0, 0},
+	}
+	expectedTargets = map[LayerSelectionPolicy]buffer.VideoLayer{
+		BalancedLayerSelectionPolicy{}:   {Spatial: 0, Temporal: 1},
+		ResolutionLayerSelectionPolicy{}: {Spatial: 1, Temporal: 0},
+	}
+	for policy, expectedTarget := range expectedTargets {
+		f := newVideoForwarder(policy)
+		f.lastAllocation.IsDeficient = true
+		f.vls.SetTarget(buffer.VideoLayer{Spatial: 0, Temporal: 0})
+		f.vls.SetCurrent(buffer.VideoLayer{Spatial: 0, Temporal: 0})
+
+		transition, isAvailable := f.GetNextHigherTransition(bitrates, false)
+		require.True(t, isAvailable)
+		require.Equal(t, expectedTarget, transition.To)
+
+		result, boosted := f.AllocateNextHigher(100_000_000, nil, bitrates, false)
+		require.True(t, boosted)
+		require.Equal(t, expectedTarget, result.TargetLayer)
+		require.Equal(t, expectedTarget, f.TargetLayer())
+	}
+}
+
 func TestForwarderPause(t *testing.T) {
 	f := newForwarder(testutils.TestVP8Codec, webrtc.RTPCodecTypeVideo)
 	f.SetMaxSpatialLayer(buffer.DefaultMaxLayerSpatial)
@@ -2007,3 +2068,56 @@ func TestForwarderGetPaddingVP8(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, marshalledVP8, buf)
 }
+
+func TestForwarderFrameIntegrity(t *testing.T) {
+	f := NewForwarder(webrtc.RTPCodecTypeVideo, logger.GetLogger(), true, nil)
+	f.SetFrameIntegrity(true)
+	f.DetermineCodec(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeH264, ClockRate: 90000}, nil)
+	f.vls.SetTarget(buffer.VideoLayer{Spatial: 0, Temporal: 0})
+
+	getTP := func(sn uint16, ts uint32, marker bool, isKeyFrame bool) TranslationParams {
+		extPkt, _ := testutils.GetTestExtPacket(&testutils.TestExtPacketParams{
+			SequenceNumber: sn,
+			Timestamp:      ts,
+			SSRC:           0x12345678,
+			PayloadSize:    20,
+			SetMarker:      marker,
+			IsKeyFrame:     isKeyFrame,
+		})
+		tp, err := f.GetTranslationParams(extPkt, 0)
+		require.NoError(t, err)
+		return tp
+	}
+
+	// key frame locks
+	require.False(t, getTP(100, 1000, false, true).shouldDrop)
+	require.False(t, getTP(101, 1000, true, true).shouldDrop)
+
+	// loss between frames does not break a frame
+	tp := getTP(103, 4000, false, false)
+	require.False(t, tp.shouldDrop)
+	require.False(t, tp.isFrameBroken)
+
+	// loss in the middle of a frame drops the remainder and requests a key frame
+	tp = getTP(105, 4000, false, false)
+	require.True(t, tp.shouldDrop)
+	require.True(t, tp.isFrameBroken)
+	tp = getTP(106, 4000, true, false)
+	require.True(t, tp.shouldDrop)
+	require.False(t, tp.isFrameBroken)
+
+	// frames referencing the broken frame are dropped too
+	require.True(t, getTP(107, 7000, true, false).shouldDrop)
+
+	// resumes on key frame
+	tp = getTP(108, 10000, false, true)
+	require.False(t, tp.shouldDrop)
+	require.Equal(t, uint64(104), tp.rtp.extSequenceNumber)
+	require.False(t, getTP(109, 10000, true, true).shouldDrop)
+
+	// loss of the end of a frame breaks it even when the next frame starts cleanly
+	require.False(t, getTP(110, 13000, false, false).shouldDrop)
+	tp = getTP(112, 16000, true, false)
+	require.True(t, tp.shouldDrop)
+	require.True(t, tp.isFrameBroken)
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+// LayerSelectionPolicy ranks the layers a forwarder considers when it moves up or down under constrained bandwidth.
+// Constraints (mute, max layer, available bitrates, channel capacity) are applied by the forwarder,
+// a policy only decides the order in which layers are tried and how expensive a downgrade is.
+type LayerSelectionPolicy interface {
+	// NextHigherLayers returns layers above target, most preferred first. Target is invalid when not streaming.
+	// Layers above max layer are included only when overshoot is allowed.
+	NextHigherLayers(target buffer.VideoLayer, maxLayer buffer.VideoLayer, overshoot bool) []buffer.VideoLayer
+
+	// StartLayers returns layers to try when a stream has to be started in a congested channel, most preferred first.
+	StartLayers(maxLayer buffer.VideoLayer, overshoot bool) []buffer.VideoLayer
+
+	// DowngradeCost returns the cost of moving from target to a lower layer, for the same bits saved,
+	// a lower cost is preferred. maxTemporal is the highest temporal layer available in the feed.
+	DowngradeCost(target buffer.VideoLayer, layer buffer.VideoLayer, maxTemporal int32) int32
+}
+
+// LayerSelectionPolicyFromConfig returns the policy for one of the config.LayerSelectionPolicy* names,
+// balanced policy for an empty or unknown name.
+func LayerSelectionPolicyFromConfig(name string) LayerSelectionPolicy {
+	switch name {
+	case config.LayerSelectionPolicyResolution:
+		return ResolutionLayerSelectionPolicy{}
+	default:
+		return BalancedLayerSelectionPolicy{}
+	}
+}
+
+func appendLayers(layers []buffer.VideoLayer, minSpatial, maxSpatial, minTemporal, maxTemporal int32) []buffer.VideoLayer {
+	for s := minSpatial; s <= maxSpatial; s++ {
+		for t := minTemporal; t <= maxTemporal; t++ {
+			layers = append(layers, buffer.VideoLayer{Spatial: s, Temporal: t})
+		}
+	}
+	return layers
+}
+
+// ------------------------------------------------
+
+// BalancedLayerSelectionPolicy moves up temporal layers of the current spatial layer before moving up
+// a spatial layer and weighs a spatial downgrade by its key frame cost and quality loss.
+type BalancedLayerSelectionPolicy struct{}
+
+func (BalancedLayerSelectionPolicy) NextHigherLayers(target buffer.VideoLayer, maxLayer buffer.VideoLayer, overshoot bool) []buffer.VideoLayer {
+	var layers []buffer.VideoLayer
+
+	// try moving temporal layer up in currently streaming spatial layer
+	if target.IsValid() {
+		layers = appendLayers(layers, target.Spatial, target.Spatial, target.Temporal+1, maxLayer.Temporal)
+	}
+
+	// try moving spatial layer up if temporal layer move up is not available
+	layers = appendLayers(layers, target.Spatial+1, maxLayer.Spatial, 0, maxLayer.Temporal)
+
+	if overshoot && maxLayer.IsValid() {
+		layers = appendLayers(layers, maxLayer.Spatial+1, buffer.DefaultMaxLayerSpatial, 0, buffer.DefaultMaxLayerTemporal)
+	}
+	return layers
+}
+
+func (BalancedLayerSelectionPolicy) StartLayers(maxLayer buffer.VideoLayer, overshoot bool) []buffer.VideoLayer {
+	// minimal first, a layer in feed could have paused and there could be other options than going back to minimal,
+	// but the cooperative scheme knocks things back to minimal
+	layers := appendLayers(nil, 0, maxLayer.Spatial, 0, maxLayer.Temporal)
+
+	// could not find a minimal layer, overshoot if allowed
+	if overshoot && maxLayer.IsValid() {
+		layers = appendLayers(layers, maxLayer.Spatial+1, buffer.DefaultMaxLayerSpatial, 0, buffer.DefaultMaxLayerTemporal)
+	}
+	return layers
+}
+
+func (BalancedLayerSelectionPolicy) DowngradeCost(target buffer.VideoLayer, layer buffer.VideoLayer, maxTemporal int32) int32 {
+	// Cost has two components
+	//   a. Transition cost: Spatial layer switch is expensive due to key frame requirement, but temporal layer switch is free.
+	//   b. Quality cost: The farther away from desired layers, the higher the quality cost.
+	transitionCost := int32(0)
+	// SVC-TODO: SVC will need a different cost transition
+	if target.Spatial != layer.Spatial {
+		transitionCost = TransitionCostSpatial
+	}
+
+	qualityCost := (maxTemporal+1)*(target.Spatial-layer.Spatial) + (target.Temporal - layer.Temporal)
+	return transitionCost + qualityCost
+}
+
+// ------------------------------------------------
+
+// ResolutionLayerSelectionPolicy keeps resolution at the expense of frame rate, suited to screen share
+// where legibility matters more than motion. It moves up spatial layers before temporal layers
+// and gives up temporal layers before going down a spatial layer.
+type ResolutionLayerSelectionPolicy struct {
+	BalancedLayerSelectionPolicy
+}
+
+func (ResolutionLayerSelectionPolicy) NextHigherLayers(target buffer.VideoLayer, maxLayer buffer.VideoLayer, overshoot bool) []buffer.VideoLayer {
+	// try moving spatial layer up first
+	layers := appendLayers(nil, target.Spatial+1, maxLayer.Spatial, 0, maxLayer.Temporal)
+
+	// at highest spatial layer, move temporal layer up
+	if target.IsValid() {
+		layers = appendLayers(layers, target.Spatial, target.Spatial, target.Temporal+1, maxLayer.Temporal)
+	}
+
+	if overshoot && maxLayer.IsValid() {
+		layers = appendLayers(layers, maxLayer.Spatial+1, buffer.DefaultMaxLayerSpatial, 0, buffer.DefaultMaxLayerTemporal)
+	}
+	return layers
+}
+
+func (ResolutionLayerSelectionPolicy) DowngradeCost(target buffer.VideoLayer, layer buffer.VideoLayer, maxTemporal int32) int32 {
+	if target.Spatial == layer.Spatial {
+		return target.Temporal - layer.Temporal
+	}
+
+	// a spatial step costs more than giving up all temporal layers
+	return TransitionCostSpatial*(maxTemporal+1)*(target.Spatial-layer.Spatial) + (target.Temporal - layer.Temporal)
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+func TestVP8Packetizer(t *testing.T) {
+	v := newVP8Packetizer(600_000)
+
+	bytes := 0
+	keyFrames := 0
+	for i := 0; i < videoKeyFrameInterval; i++ {
+		payloads, keyFrame := v.nextFrame(false)
+		require.Equal(t, i == 0, keyFrame)
+		if keyFrame {
+			keyFrames++
+		}
+
+		for j, payload := range payloads {
+			require.LessOrEqual(t, len(payload), maxPayloadSize+6)
+
+			vp8 := buffer.VP8{}
+			require.NoError(t, vp8.Unmarshal(payload))
+			require.Equal(t, j == 0, vp8.S)
+			require.Equal(t, keyFrame && j == 0, vp8.IsKeyFrame)
+			require.Equal(t, v.pictureID, vp8.PictureID)
+			bytes += len(payload) - vp8.HeaderSize
+		}
+	}
+	require.Equal(t, 1, keyFrames)
+	// one key frame interval worth of the target bitrate
+	require.InDelta(t, 600_000/8*videoKeyFrameInterval/videoFPS, bytes, 1000)
+
+	// forced key frame
+	_, keyFrame := v.nextFrame(true)
+	require.True(t, keyFrame)
+}
+
+func TestReceiver(t *testing.T) {
+	for _, kind := range []livekit.TrackType{livekit.TrackType_AUDIO, livekit.TrackType_VIDEO} {
+		t.Run(kind.String(), func(t *testing.T) {
+			r := NewReceiver(ReceiverParams{
+				TrackID:      "TR_synthetic",
+				StreamID:     "PA_synthetic",
+				Kind:         kind,
+				VideoBitrate: 300_000,
+				Logger:       logger.GetLogger(),
+			}, &livekit.TrackInfo{Sid: "TR_synthetic", Type: kind})
+			r.Start()
+
+			sink := NewSink("PA_sub", r.TrackID())
+			require.NoError(t, r.AddDownTrack(sink))
+
+			require.Eventually(t, func() bool {
+				packets, _ := sink.Stats()
+				return packets >= 5
+			}, 2*time.Second, 10*time.Millisecond)
+			require.NotNil(t, r.GetTrackStats())
+
+			r.Close()
+			require.True(t, sink.IsClosed())
+			require.ErrorIs(t, r.AddDownTrack(NewSink("PA_other", r.TrackID())), sfu.ErrReceiverClosed)
+		})
+	}
+}
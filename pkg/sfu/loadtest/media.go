@@ -0,0 +1,163 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+const (
+	vp8PT  = 96
+	opusPT = 111
+
+	videoClockRate = 90000
+	audioClockRate = 48000
+
+	videoFPS = 30
+	// a key frame every 2 seconds
+	videoKeyFrameInterval = 2 * videoFPS
+	// key frames are this many times the size of delta frames
+	videoKeyFrameScale = 4
+	videoWidth         = 640
+	videoHeight        = 360
+
+	audioFrameDuration = 20 * time.Millisecond
+
+	maxPayloadSize = 1100
+)
+
+// a 20 ms Opus frame of silence
+var opusSilenceFrame = []byte{0xf8, 0xff, 0xfe}
+
+// packetizer produces the RTP payloads of a looped frame sequence
+type packetizer interface {
+	// nextFrame returns payloads of the next frame, a key frame when forced
+	nextFrame(forceKeyFrame bool) (payloads [][]byte, keyFrame bool)
+	frameDuration() time.Duration
+	clockRate() uint32
+}
+
+// --------------------------------------
+
+type opusPacketizer struct{}
+
+func (opusPacketizer) nextFrame(_forceKeyFrame bool) ([][]byte, bool) {
+	return [][]byte{opusSilenceFrame}, false
+}
+
+func (opusPacketizer) frameDuration() time.Duration {
+	return audioFrameDuration
+}
+
+func (opusPacketizer) clockRate() uint32 {
+	return audioClockRate
+}
+
+// --------------------------------------
+
+// vp8Packetizer loops a group of frames sized for a target bitrate. Frames carry valid VP8 frame headers
+// and descriptors, so that the SFU detects key frames and munges picture IDs like for real streams, but
+// their partitions are filler, they are not meant to be decoded.
+type vp8Packetizer struct {
+	keyFrame   []byte
+	deltaFrame []byte
+
+	frameIndex int
+	pictureID  uint16
+	tl0PicIdx  uint8
+}
+
+func newVP8Packetizer(bitrate int) *vp8Packetizer {
+	// split bitrate over a group of one key frame and delta frames
+	groupBytes := bitrate / 8 * videoKeyFrameInterval / videoFPS
+	deltaSize := max(groupBytes/(videoKeyFrameInterval-1+videoKeyFrameScale), 16)
+	return &vp8Packetizer{
+		keyFrame:   vp8Frame(true, deltaSize*videoKeyFrameScale),
+		deltaFrame: vp8Frame(false, deltaSize),
+	}
+}
+
+// vp8Frame returns a frame with a VP8 frame tag, and for key frames the start code and dimensions,
+// followed by filler
+func vp8Frame(keyFrame bool, size int) []byte {
+	headerSize := 3
+	if keyFrame {
+		headerSize = 10
+	}
+	size = max(size, headerSize+1)
+
+	frame := make([]byte, size)
+	// frame tag: key frame flag (0 for key frames), version 0, show frame, size of first partition
+	tag := uint32(size-headerSize)<<5 | 1<<4
+	if !keyFrame {
+		tag |= 1
+	}
+	frame[0] = byte(tag)
+	frame[1] = byte(tag >> 8)
+	frame[2] = byte(tag >> 16)
+	if keyFrame {
+		copy(frame[3:], []byte{0x9d, 0x01, 0x2a})
+		binary.LittleEndian.PutUint16(frame[6:], videoWidth)
+		binary.LittleEndian.PutUint16(frame[8:], videoHeight)
+	}
+	for i := headerSize; i < size; i++ {
+		frame[i] = byte(i)
+	}
+	return frame
+}
+
+func (v *vp8Packetizer) nextFrame(forceKeyFrame bool) ([][]byte, bool) {
+	keyFrame := forceKeyFrame || v.frameIndex%videoKeyFrameInterval == 0
+	if keyFrame {
+		v.frameIndex = 0
+	}
+	v.frameIndex++
+
+	frame := v.deltaFrame
+	if keyFrame {
+		frame = v.keyFrame
+	}
+
+	v.pictureID = (v.pictureID + 1) & 0x7fff
+	v.tl0PicIdx++
+
+	var payloads [][]byte
+	for offset := 0; offset < len(frame); offset += maxPayloadSize {
+		chunk := frame[offset:min(len(frame), offset+maxPayloadSize)]
+
+		// payload descriptor with picture ID, TL0PICIDX and TID, all frames are on temporal layer 0
+		payload := make([]byte, 6+len(chunk))
+		payload[0] = 0x80
+		if offset == 0 {
+			payload[0] |= 0x10
+		}
+		payload[1] = 0xe0
+		binary.BigEndian.PutUint16(payload[2:], 0x8000|v.pictureID)
+		payload[4] = v.tl0PicIdx
+		payload[5] = 0x20
+		copy(payload[6:], chunk)
+		payloads = append(payloads, payload)
+	}
+	return payloads, keyFrame
+}
+
+func (v *vp8Packetizer) frameDuration() time.Duration {
+	return time.Second / videoFPS
+}
+
+func (v *vp8Packetizer) clockRate() uint32 {
+	return videoClockRate
+}
@@ -0,0 +1,303 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/mediatransportutil/pkg/bucket"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	bufferSizeVideo = 500
+	bufferSizeAudio = 200
+)
+
+// Receiver is the receiver of a synthetic track. It loops pre-encoded frames, packetized into RTP and fed through
+// a buffer like packets of a publisher, and forwards them to down tracks.
+var _ sfu.TrackReceiver = (*Receiver)(nil)
+
+type ReceiverParams struct {
+	TrackID  livekit.TrackID
+	StreamID string
+	// VP8 video for video tracks, Opus for audio tracks
+	Kind livekit.TrackType
+	// bitrate of video in bps
+	VideoBitrate int
+	Logger       logger.Logger
+}
+
+type Receiver struct {
+	params            ReceiverParams
+	codec             webrtc.RTPCodecParameters
+	packetizer        packetizer
+	buffer            *buffer.Buffer
+	downTrackSpreader *sfu.DownTrackSpreader
+	redReceiver       atomic.Pointer[sfu.RedReceiver]
+	trackInfo         atomic.Pointer[livekit.TrackInfo]
+	keyFrameRequested atomic.Bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func NewReceiver(params ReceiverParams, ti *livekit.TrackInfo) *Receiver {
+	r := &Receiver{
+		params: params,
+		downTrackSpreader: sfu.NewDownTrackSpreader(sfu.DownTrackSpreaderParams{
+			Logger: params.Logger,
+		}),
+		closed: make(chan struct{}),
+	}
+	if params.Kind == livekit.TrackType_VIDEO {
+		r.codec = webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{
+				MimeType:  webrtc.MimeTypeVP8,
+				ClockRate: videoClockRate,
+				RTCPFeedback: []webrtc.RTCPFeedback{
+					{Type: webrtc.TypeRTCPFBNACK},
+					{Type: webrtc.TypeRTCPFBNACK, Parameter: "pli"},
+				},
+			},
+			PayloadType: vp8PT,
+		}
+		r.packetizer = newVP8Packetizer(params.VideoBitrate)
+	} else {
+		r.codec = webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{
+				MimeType:    webrtc.MimeTypeOpus,
+				ClockRate:   audioClockRate,
+				Channels:    2,
+				SDPFmtpLine: "minptime=10;useinbandfec=1",
+			},
+			PayloadType: opusPT,
+		}
+		r.packetizer = opusPacketizer{}
+	}
+	r.buffer = buffer.NewBuffer(rand.Uint32(), bufferSizeVideo, bufferSizeAudio)
+	r.buffer.SetLogger(params.Logger)
+	r.buffer.Bind(webrtc.RTPParameters{Codecs: []webrtc.RTPCodecParameters{r.codec}}, r.codec.RTPCodecCapability, 0)
+	r.trackInfo.Store(ti)
+	return r
+}
+
+// Start starts producing packets, until the receiver is closed
+func (r *Receiver) Start() {
+	go r.generateWorker()
+	go r.forwardWorker()
+}
+
+func (r *Receiver) generateWorker() {
+	frameDuration := r.packetizer.frameDuration()
+	ticksPerFrame := uint32(uint64(r.packetizer.clockRate()) * uint64(frameDuration) / uint64(time.Second))
+	ssrc := r.buffer.GetMediaSSRC()
+	sn := uint16(rand.Uint32())
+	ts := rand.Uint32()
+
+	ticker := time.NewTicker(frameDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closed:
+			return
+		case <-ticker.C:
+		}
+
+		payloads, _ := r.packetizer.nextFrame(r.keyFrameRequested.Swap(false))
+		for i, payload := range payloads {
+			pkt := rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         i == len(payloads)-1,
+					PayloadType:    uint8(r.codec.PayloadType),
+					SequenceNumber: sn,
+					Timestamp:      ts,
+					SSRC:           ssrc,
+				},
+				Payload: payload,
+			}
+			sn++
+
+			b, err := pkt.Marshal()
+			if err != nil {
+				continue
+			}
+			if _, err := r.buffer.Write(b); err == io.EOF {
+				return
+			}
+		}
+		ts += ticksPerFrame
+	}
+}
+
+func (r *Receiver) forwardWorker() {
+	pktBuf := make([]byte, bucket.MaxPktSize)
+	for {
+		pkt, err := r.buffer.ReadExtended(pktBuf)
+		if err == io.EOF {
+			return
+		}
+
+		r.downTrackSpreader.Broadcast(func(dt sfu.TrackSender) {
+			_ = dt.WriteRTP(pkt, 0)
+		})
+
+		if rr := r.redReceiver.Load(); rr != nil {
+			rr.ForwardRTP(pkt, 0)
+		}
+	}
+}
+
+func (r *Receiver) TrackID() livekit.TrackID {
+	return r.params.TrackID
+}
+
+func (r *Receiver) StreamID() string {
+	return r.params.StreamID
+}
+
+func (r *Receiver) Codec() webrtc.RTPCodecParameters {
+	return r.codec
+}
+
+func (r *Receiver) HeaderExtensions() []webrtc.RTPHeaderExtensionParameter {
+	return nil
+}
+
+func (r *Receiver) IsClosed() bool {
+	select {
+	case <-r.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *Receiver) ReadRTP(buf []byte, _layer uint8, sn uint16) (int, error) {
+	return r.buffer.GetPacket(buf, sn)
+}
+
+func (r *Receiver) GetLayeredBitrate() ([]int32, sfu.Bitrates) {
+	return nil, sfu.Bitrates{}
+}
+
+func (r *Receiver) GetAudioLevel() (float64, bool) {
+	return 0, false
+}
+
+func (r *Receiver) SendPLI(_layer int32, _force bool) {
+	r.keyFrameRequested.Store(true)
+}
+
+func (r *Receiver) ReplayKeyFrame(_track sfu.TrackSender, _layer int32) bool {
+	return false
+}
+
+func (r *Receiver) SetUpTrackPaused(_paused bool) {}
+
+func (r *Receiver) SetMaxExpectedSpatialLayer(_layer int32) {}
+
+func (r *Receiver) AddDownTrack(track sfu.TrackSender) error {
+	if r.IsClosed() {
+		return sfu.ErrReceiverClosed
+	}
+
+	if r.downTrackSpreader.HasDownTrack(track.SubscriberID()) {
+		r.params.Logger.Infow("subscriberID already exists, replacing downtrack", "subscriberID", track.SubscriberID())
+	}
+
+	track.TrackInfoAvailable()
+
+	r.downTrackSpreader.Store(track)
+	r.keyFrameRequested.Store(true)
+	r.params.Logger.Debugw("synthetic downtrack added", "subscriberID", track.SubscriberID())
+	return nil
+}
+
+func (r *Receiver) DeleteDownTrack(subscriberID livekit.ParticipantID) {
+	if r.IsClosed() {
+		return
+	}
+
+	r.downTrackSpreader.Free(subscriberID)
+	r.params.Logger.Debugw("synthetic downtrack deleted", "subscriberID", subscriberID)
+}
+
+func (r *Receiver) DebugInfo() map[string]interface{} {
+	return map[string]interface{}{
+		"TrackID":    r.params.TrackID,
+		"DownTracks": r.downTrackSpreader.DownTrackCount(),
+	}
+}
+
+func (r *Receiver) TrackInfo() *livekit.TrackInfo {
+	return r.trackInfo.Load()
+}
+
+func (r *Receiver) UpdateTrackInfo(ti *livekit.TrackInfo) {
+	r.trackInfo.Store(ti)
+}
+
+func (r *Receiver) GetPrimaryReceiverForRed() sfu.TrackReceiver {
+	return r
+}
+
+func (r *Receiver) GetRedReceiver() sfu.TrackReceiver {
+	if r.IsClosed() || r.params.Kind != livekit.TrackType_AUDIO {
+		return r
+	}
+
+	if r.redReceiver.Load() == nil {
+		r.redReceiver.CompareAndSwap(nil, sfu.NewRedReceiver(r, sfu.DownTrackSpreaderParams{
+			Logger: r.params.Logger,
+		}))
+	}
+	return r.redReceiver.Load()
+}
+
+func (r *Receiver) GetTemporalLayerFpsForSpatial(_layer int32) []float32 {
+	return nil
+}
+
+func (r *Receiver) GetTrackStats() *livekit.RTPStats {
+	return r.buffer.GetStats()
+}
+
+func (r *Receiver) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+		_ = r.buffer.Close()
+
+		for _, dt := range r.downTrackSpreader.ResetAndGetDownTracks() {
+			dt.Close()
+		}
+		if rr := r.redReceiver.Load(); rr != nil {
+			rr.Close()
+		}
+	})
+}
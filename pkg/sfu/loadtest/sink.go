@@ -0,0 +1,103 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtest
+
+import (
+	"github.com/pion/webrtc/v3"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+// Sink is the down track of a synthetic subscriber. It takes the packets forwarded to it and counts them,
+// nothing is sent out.
+var _ sfu.TrackSender = (*Sink)(nil)
+
+type Sink struct {
+	subscriberID livekit.ParticipantID
+	trackID      livekit.TrackID
+
+	packets atomic.Uint64
+	bytes   atomic.Uint64
+	closed  atomic.Bool
+}
+
+func NewSink(subscriberID livekit.ParticipantID, trackID livekit.TrackID) *Sink {
+	return &Sink{
+		subscriberID: subscriberID,
+		trackID:      trackID,
+	}
+}
+
+func (s *Sink) TrackID() livekit.TrackID {
+	return s.trackID
+}
+
+// Stats returns packets and bytes forwarded to the sink
+func (s *Sink) Stats() (uint64, uint64) {
+	return s.packets.Load(), s.bytes.Load()
+}
+
+func (s *Sink) WriteRTP(p *buffer.ExtPacket, _layer int32) error {
+	if s.closed.Load() {
+		return nil
+	}
+
+	s.packets.Inc()
+	s.bytes.Add(uint64(len(p.Packet.Payload)))
+	return nil
+}
+
+func (s *Sink) Close() {
+	s.closed.Store(true)
+}
+
+func (s *Sink) IsClosed() bool {
+	return s.closed.Load()
+}
+
+func (s *Sink) ID() string {
+	return string(s.subscriberID)
+}
+
+func (s *Sink) SubscriberID() livekit.ParticipantID {
+	return s.subscriberID
+}
+
+func (s *Sink) UpTrackLayersChange() {}
+
+func (s *Sink) UpTrackBitrateAvailabilityChange() {}
+
+func (s *Sink) UpTrackMaxPublishedLayerChange(_maxPublishedLayer int32) {}
+
+func (s *Sink) UpTrackMaxTemporalLayerSeenChange(_maxTemporalLayerSeen int32) {}
+
+func (s *Sink) UpTrackBitrateReport(_availableLayers []int32, _bitrates sfu.Bitrates) {}
+
+func (s *Sink) TrackInfoAvailable() {}
+
+func (s *Sink) Resync() {}
+
+func (s *Sink) HandleRTCPSenderReportData(
+	_payloadType webrtc.PayloadType,
+	_isSVC bool,
+	_layer int32,
+	_publisherSRData *buffer.RTCPSenderReportData,
+) error {
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package netem emulates degraded networks on media paths of the SFU, dropping, delaying and
+// shaping packets according to programmable conditions. Loss and jitter are drawn from a seeded
+// source, so a run with the same seed and the same packet sequence drops and delays the same packets.
+package netem
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+const (
+	// packets which would wait longer than this behind the bandwidth clamp are dropped,
+	// like a tail drop bottleneck queue
+	DefaultMaxQueueDelay = 500 * time.Millisecond
+)
+
+type Conditions struct {
+	// fraction of packets dropped, in [0, 1]
+	Loss float64
+	// delay added to every packet
+	Latency time.Duration
+	// random delay in [0, Jitter) added on top of Latency, packets can be reordered
+	Jitter time.Duration
+	// bits per second packets are shaped to, 0 for no clamp
+	Bandwidth int64
+	// queueing delay above which shaped packets are dropped, defaults to DefaultMaxQueueDelay
+	MaxQueueDelay time.Duration
+	// seed of the random source for loss and jitter
+	Seed int64
+}
+
+func (c *Conditions) IsNoop() bool {
+	return c == nil || (c.Loss <= 0 && c.Latency <= 0 && c.Jitter <= 0 && c.Bandwidth <= 0)
+}
+
+// ------------------------------------------------
+
+type emulatorState struct {
+	conditions Conditions
+
+	lock     sync.Mutex
+	rand     *rand.Rand
+	nextFree time.Time
+}
+
+// Emulator decides the fate of packets passing through a hook, it is inactive until conditions are set
+type Emulator struct {
+	state atomic.Pointer[emulatorState]
+}
+
+func NewEmulator() *Emulator {
+	return &Emulator{}
+}
+
+// SetConditions replaces the conditions applied, nil or no-op conditions turn emulation off.
+// The random source is reseeded and the bandwidth queue drained on every call.
+func (e *Emulator) SetConditions(c *Conditions) {
+	if c.IsNoop() {
+		e.state.Store(nil)
+		return
+	}
+
+	conditions := *c
+	if conditions.MaxQueueDelay <= 0 {
+		conditions.MaxQueueDelay = DefaultMaxQueueDelay
+	}
+	e.state.Store(&emulatorState{
+		conditions: conditions,
+		rand:       rand.New(rand.NewSource(conditions.Seed)),
+	})
+}
+
+// Conditions returns the conditions applied, nil when emulation is off
+func (e *Emulator) Conditions() *Conditions {
+	s := e.state.Load()
+	if s == nil {
+		return nil
+	}
+
+	c := s.conditions
+	return &c
+}
+
+func (e *Emulator) IsActive() bool {
+	return e.state.Load() != nil
+}
+
+// Admit decides the fate of a packet of size bytes arriving at the hook now,
+// returns whether the packet is delivered and after how long
+func (e *Emulator) Admit(size int) (time.Duration, bool) {
+	return e.AdmitAt(time.Now(), size)
+}
+
+func (e *Emulator) AdmitAt(at time.Time, size int) (time.Duration, bool) {
+	s := e.state.Load()
+	if s == nil {
+		return 0, true
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	c := &s.conditions
+	// draw for both loss and jitter on every packet, so that the sequence of draws
+	// only depends on the sequence of packets
+	lossDraw := s.rand.Float64()
+	jitterDraw := s.rand.Float64()
+	if lossDraw < c.Loss {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if c.Bandwidth > 0 {
+		departure := at
+		if s.nextFree.After(departure) {
+			departure = s.nextFree
+		}
+		queueDelay := departure.Sub(at)
+		if queueDelay > c.MaxQueueDelay {
+			return 0, false
+		}
+		s.nextFree = departure.Add(time.Duration(int64(size) * 8 * int64(time.Second) / c.Bandwidth))
+		delay = queueDelay
+	}
+
+	delay += c.Latency
+	if c.Jitter > 0 {
+		delay += time.Duration(jitterDraw * float64(c.Jitter))
+	}
+	return delay, true
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package netem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmulatorInactive(t *testing.T) {
+	e := NewEmulator()
+	require.False(t, e.IsActive())
+
+	delay, ok := e.Admit(1200)
+	require.True(t, ok)
+	require.Zero(t, delay)
+
+	e.SetConditions(&Conditions{Seed: 1})
+	require.False(t, e.IsActive())
+	require.Nil(t, e.Conditions())
+}
+
+func TestEmulatorLossIsDeterministic(t *testing.T) {
+	run := func(seed int64) []bool {
+		e := NewEmulator()
+		e.SetConditions(&Conditions{Loss: 0.2, Jitter: 10 * time.Millisecond, Seed: seed})
+
+		admitted := make([]bool, 1000)
+		for i := range admitted {
+			_, admitted[i] = e.Admit(1200)
+		}
+		return admitted
+	}
+
+	first := run(42)
+	require.Equal(t, first, run(42))
+	require.NotEqual(t, first, run(43))
+
+	dropped := 0
+	for _, ok := range first {
+		if !ok {
+			dropped++
+		}
+	}
+	require.InDelta(t, 200, dropped, 50)
+}
+
+func TestEmulatorLatencyAndJitter(t *testing.T) {
+	e := NewEmulator()
+	e.SetConditions(&Conditions{Latency: 100 * time.Millisecond, Jitter: 20 * time.Millisecond})
+
+	for i := 0; i < 100; i++ {
+		delay, ok := e.Admit(1200)
+		require.True(t, ok)
+		require.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		require.Less(t, delay, 120*time.Millisecond)
+	}
+}
+
+func TestEmulatorBandwidth(t *testing.T) {
+	e := NewEmulator()
+	e.SetConditions(&Conditions{Bandwidth: 80_000, MaxQueueDelay: 250 * time.Millisecond})
+
+	// 1000 byte packets take 100ms each at 80kbps
+	now := time.Now()
+	delay, ok := e.AdmitAt(now, 1000)
+	require.True(t, ok)
+	require.Zero(t, delay)
+
+	delay, ok = e.AdmitAt(now, 1000)
+	require.True(t, ok)
+	require.Equal(t, 100*time.Millisecond, delay)
+
+	delay, ok = e.AdmitAt(now, 1000)
+	require.True(t, ok)
+	require.Equal(t, 200*time.Millisecond, delay)
+
+	// queue is full
+	_, ok = e.AdmitAt(now, 1000)
+	require.False(t, ok)
+
+	// queue drains with time
+	delay, ok = e.AdmitAt(now.Add(time.Second), 1000)
+	require.True(t, ok)
+	require.Zero(t, delay)
+}
@@ -43,11 +43,7 @@ func (b *Base) SetBitrate(_bitrate int) {
 }
 
 func (b *Base) SendPacket(p *Packet) (int, error) {
-	defer func() {
-		if p.Pool != nil && p.PoolEntity != nil {
-			p.Pool.Put(p.PoolEntity)
-		}
-	}()
+	defer p.release()
 
 	_, err := b.writeRTPHeaderExtensions(p)
 	if err != nil {
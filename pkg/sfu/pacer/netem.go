@@ -0,0 +1,57 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pacer
+
+import (
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/netem"
+)
+
+// NetEm impairs packets according to the conditions of an emulator before handing them
+// to the wrapped pacer, packets pass straight through while the emulator is inactive
+type NetEm struct {
+	Pacer
+
+	emulator *netem.Emulator
+}
+
+func NewNetEm(p Pacer, emulator *netem.Emulator) *NetEm {
+	return &NetEm{
+		Pacer:    p,
+		emulator: emulator,
+	}
+}
+
+func (n *NetEm) Enqueue(pkt Packet) {
+	if !n.emulator.IsActive() {
+		n.Pacer.Enqueue(pkt)
+		return
+	}
+
+	delay, ok := n.emulator.Admit(pkt.Header.MarshalSize() + len(pkt.Payload))
+	if !ok {
+		pkt.release()
+		return
+	}
+	if delay <= 0 {
+		n.Pacer.Enqueue(pkt)
+		return
+	}
+
+	time.AfterFunc(delay, func() {
+		n.Pacer.Enqueue(pkt)
+	})
+}
@@ -15,11 +15,12 @@
 package pacer
 
 import (
-	"sync"
 	"time"
 
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v3"
+
+	"github.com/livekit/livekit-server/pkg/sfu/utils"
 )
 
 type ExtensionData struct {
@@ -34,8 +35,14 @@ type Packet struct {
 	AbsSendTimeExtID   uint8
 	TransportWideExtID uint8
 	WriteStream        webrtc.TrackLocalWriter
-	Pool               *sync.Pool
-	PoolEntity         *[]byte
+	// holds Payload, released once the packet is written or dropped
+	PayloadRef *utils.PacketRef
+}
+
+func (p *Packet) release() {
+	if p.PayloadRef != nil {
+		p.PayloadRef.Release()
+	}
 }
 
 type Pacer interface {
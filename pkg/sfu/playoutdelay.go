@@ -36,6 +36,8 @@ const (
 
 	// limit max delay change to make it smoother for a/v sync
 	maxDelayChangePerSec = 80
+
+	nackStatsInterval = time.Second
 )
 
 func (s PlayoutDelayState) String() string {
@@ -59,13 +61,12 @@ type PlayoutDelayController struct {
 	sendingAtSeq       uint16
 	sendingAtTime      time.Time
 	logger             logger.Logger
-	rtpStats           *buffer.RTPStatsSender
-	snapshotID         uint32
+	nackPercent        atomic.Uint32
 
 	highDelayCount atomic.Uint32
 }
 
-func NewPlayoutDelayController(minDelay, maxDelay uint32, logger logger.Logger, rtpStats *buffer.RTPStatsSender) (*PlayoutDelayController, error) {
+func NewPlayoutDelayController(minDelay, maxDelay uint32, logger logger.Logger, deltaInfoStream *buffer.RTPDeltaInfoStream) (*PlayoutDelayController, error) {
 	if maxDelay == 0 && minDelay > 0 {
 		maxDelay = pd.MaxPlayoutDelayDefault
 	}
@@ -77,18 +78,21 @@ func NewPlayoutDelayController(minDelay, maxDelay uint32, logger logger.Logger,
 		minDelay:     minDelay,
 		maxDelay:     maxDelay,
 		logger:       logger,
-		rtpStats:     rtpStats,
-		snapshotID:   rtpStats.NewSenderSnapshotId(),
 	}
+	deltaInfoStream.Subscribe(nackStatsInterval, c.onDeltaInfo)
 	return c, c.createExtData()
 }
 
-func (c *PlayoutDelayController) SetJitter(jitter uint32) {
-	deltaInfo := c.rtpStats.DeltaInfoSender(c.snapshotID)
+func (c *PlayoutDelayController) onDeltaInfo(deltaInfo *buffer.RTPDeltaInfo) {
 	var nackPercent uint32
-	if deltaInfo != nil && deltaInfo.Packets > 0 {
+	if deltaInfo.Packets > 0 {
 		nackPercent = deltaInfo.Nacks * 100 / deltaInfo.Packets
 	}
+	c.nackPercent.Store(nackPercent)
+}
+
+func (c *PlayoutDelayController) SetJitter(jitter uint32) {
+	nackPercent := c.nackPercent.Load()
 
 	c.lock.Lock()
 	targetDelay := jitter * jitterMultiToDelay
@@ -27,7 +27,9 @@ import (
 
 func TestPlayoutDelay(t *testing.T) {
 	stats := buffer.NewRTPStatsSender(buffer.RTPStatsParams{ClockRate: 900000, Logger: logger.GetLogger()})
-	c, err := NewPlayoutDelayController(100, 120, logger.GetLogger(), stats)
+	deltaInfoStream := stats.NewDeltaInfoSenderStream()
+	defer deltaInfoStream.Close()
+	c, err := NewPlayoutDelayController(100, 120, logger.GetLogger(), deltaInfoStream)
 	require.NoError(t, err)
 
 	ext := c.GetDelayExtension(100)
@@ -0,0 +1,147 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+// pliCoordinator coalesces key frame requests of all down tracks of an up track.
+//
+// A request is answered with a PLI right away when no PLI was sent for the layer within its
+// throttle period. Requests within the period are not dropped, instead a single PLI is sent
+// when the period ends, unless a key frame of the layer arrives before that and answers them.
+type pliCoordinator struct {
+	throttle config.PLIThrottleConfig
+	sendPLI  func(layer int32)
+
+	lock   sync.Mutex
+	layers [buffer.DefaultMaxLayerSpatial + 1]pliLayerState
+	closed bool
+}
+
+type pliLayerState struct {
+	lastSent time.Time
+	timer    *time.Timer
+	timerGen uint64
+}
+
+func newPLICoordinator(throttle config.PLIThrottleConfig, sendPLI func(layer int32)) *pliCoordinator {
+	return &pliCoordinator{
+		throttle: throttle,
+		sendPLI:  sendPLI,
+	}
+}
+
+// request asks for a key frame on layer, returns false when the request was coalesced
+// into a PLI that has been, or will be, sent for another request
+func (p *pliCoordinator) request(layer int32, force bool) bool {
+	if layer < 0 || int(layer) >= len(p.layers) {
+		return false
+	}
+
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return false
+	}
+
+	l := &p.layers[layer]
+	throttle := pliThrottleForLayer(p.throttle, layer)
+	wait := throttle - time.Since(l.lastSent)
+	if !force && wait > 0 {
+		if l.timer == nil {
+			l.timerGen++
+			timerGen := l.timerGen
+			l.timer = time.AfterFunc(wait, func() {
+				p.onThrottleEnd(layer, timerGen)
+			})
+		}
+		p.lock.Unlock()
+		return false
+	}
+
+	l.lastSent = time.Now()
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	p.lock.Unlock()
+
+	p.sendPLI(layer)
+	return true
+}
+
+// onKeyFrame answers pending requests of layer
+func (p *pliCoordinator) onKeyFrame(layer int32) {
+	if layer < 0 || int(layer) >= len(p.layers) {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	l := &p.layers[layer]
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+}
+
+func (p *pliCoordinator) onThrottleEnd(layer int32, timerGen uint64) {
+	p.lock.Lock()
+	l := &p.layers[layer]
+	if p.closed || l.timer == nil || l.timerGen != timerGen {
+		// answered by a key frame or a forced PLI in the meantime
+		p.lock.Unlock()
+		return
+	}
+
+	l.timer = nil
+	l.lastSent = time.Now()
+	p.lock.Unlock()
+
+	p.sendPLI(layer)
+}
+
+func (p *pliCoordinator) close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.closed = true
+	for i := range p.layers {
+		if timer := p.layers[i].timer; timer != nil {
+			timer.Stop()
+			p.layers[i].timer = nil
+		}
+	}
+}
+
+func pliThrottleForLayer(throttle config.PLIThrottleConfig, layer int32) time.Duration {
+	switch layer {
+	case 2:
+		return throttle.HighQuality
+	case 1:
+		return throttle.MidQuality
+	case 0:
+		return throttle.LowQuality
+	default:
+		return throttle.MidQuality
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+func TestPLICoordinator(t *testing.T) {
+	var sent [3]atomic.Int32
+	p := newPLICoordinator(config.PLIThrottleConfig{
+		LowQuality:  100 * time.Millisecond,
+		MidQuality:  time.Minute,
+		HighQuality: time.Minute,
+	}, func(layer int32) {
+		sent[layer].Inc()
+	})
+	defer p.close()
+
+	t.Run("coalesces requests within throttle period", func(t *testing.T) {
+		require.True(t, p.request(0, false))
+		require.False(t, p.request(0, false))
+		require.False(t, p.request(0, false))
+		require.EqualValues(t, 1, sent[0].Load())
+
+		// one PLI for both coalesced requests at the end of the period
+		require.Eventually(t, func() bool { return sent[0].Load() == 2 }, time.Second, 10*time.Millisecond)
+		time.Sleep(200 * time.Millisecond)
+		require.EqualValues(t, 2, sent[0].Load())
+	})
+
+	t.Run("key frame answers coalesced requests", func(t *testing.T) {
+		require.True(t, p.request(1, false))
+		require.False(t, p.request(1, false))
+		p.onKeyFrame(1)
+		require.EqualValues(t, 1, sent[1].Load())
+	})
+
+	t.Run("forced requests are not throttled", func(t *testing.T) {
+		require.True(t, p.request(2, false))
+		require.True(t, p.request(2, true))
+		require.EqualValues(t, 2, sent[2].Load())
+	})
+}
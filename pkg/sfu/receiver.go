@@ -26,7 +26,6 @@ import (
 	"go.uber.org/atomic"
 	"google.golang.org/protobuf/proto"
 
-	"github.com/livekit/mediatransportutil/pkg/bucket"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 
@@ -35,6 +34,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	dd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/dependencydescriptor"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 )
 
 var (
@@ -62,6 +62,9 @@ type TrackReceiver interface {
 	GetAudioLevel() (float64, bool)
 
 	SendPLI(layer int32, force bool)
+	// ReplayKeyFrame primes track with the most recent key frame of layer and the packets after it,
+	// returns false when nothing is retained for layer
+	ReplayKeyFrame(track TrackSender, layer int32) bool
 
 	SetUpTrackPaused(paused bool)
 	SetMaxExpectedSpatialLayer(layer int32)
@@ -89,8 +92,9 @@ type TrackReceiver interface {
 type WebRTCReceiver struct {
 	logger logger.Logger
 
-	pliThrottleConfig config.PLIThrottleConfig
-	audioConfig       config.AudioConfig
+	pliThrottleConfig       config.PLIThrottleConfig
+	audioConfig             config.AudioConfig
+	connectionQualityConfig config.ConnectionQualityConfig
 
 	trackID        livekit.TrackID
 	streamID       string
@@ -112,6 +116,9 @@ type WebRTCReceiver struct {
 	upTracks [buffer.DefaultMaxLayerSpatial + 1]*webrtc.TrackRemote
 	rtt      uint32
 
+	// spatial layers above this are dropped, used when server caps layers a publisher may send
+	maxForwardedSpatialLayer atomic.Int32
+
 	lbThreshold int
 
 	streamTrackerManager *StreamTrackerManager
@@ -130,6 +137,14 @@ type WebRTCReceiver struct {
 	redPktWriter    func(pkt *buffer.ExtPacket, spatialLayer int32) int
 
 	forwardStats *ForwardStats
+
+	replayDuration time.Duration
+	replayBuffer   *replayBuffer
+
+	layerBitrateConfig config.LayerBitrateConfig
+
+	// PLIs are throttled per up track by the coordinator, not by the layer buffers
+	pliCoordinator *pliCoordinator
 }
 
 type ReceiverOpts func(w *WebRTCReceiver) *WebRTCReceiver
@@ -177,6 +192,32 @@ func WithForwardStats(forwardStats *ForwardStats) ReceiverOpts {
 	}
 }
 
+// WithReplayBuffer retains the given duration of recent media, starting at a key frame, to prime
+// late down tracks with. Only applies to non-SVC video.
+func WithReplayBuffer(duration time.Duration) ReceiverOpts {
+	return func(w *WebRTCReceiver) *WebRTCReceiver {
+		w.replayDuration = duration
+		return w
+	}
+}
+
+// WithConnectionQualityConfig selects the model scoring connection quality of the up track
+func WithConnectionQualityConfig(connectionQualityConfig config.ConnectionQualityConfig) ReceiverOpts {
+	return func(w *WebRTCReceiver) *WebRTCReceiver {
+		w.connectionQualityConfig = connectionQualityConfig
+		return w
+	}
+}
+
+// WithLayerBitrate measures bitrates of video layers with an exponentially weighted moving average
+// in the up track buffers, replacing the stream tracker bitrates reported to down tracks
+func WithLayerBitrate(layerBitrateConfig config.LayerBitrateConfig) ReceiverOpts {
+	return func(w *WebRTCReceiver) *WebRTCReceiver {
+		w.layerBitrateConfig = layerBitrateConfig
+		return w
+	}
+}
+
 func WithEverHasDownTrackAdded(f func()) ReceiverOpts {
 	return func(w *WebRTCReceiver) *WebRTCReceiver {
 		w.onDownTrackEverAdded = f
@@ -206,9 +247,19 @@ func NewWebRTCReceiver(
 		isRED:    buffer.IsRedCodec(track.Codec().MimeType),
 	}
 
+	w.maxForwardedSpatialLayer.Store(buffer.DefaultMaxLayerSpatial)
+
 	for _, opt := range opts {
 		w = opt(w)
 	}
+	w.pliCoordinator = newPLICoordinator(w.pliThrottleConfig, func(layer int32) {
+		if buff := w.getBuffer(layer); buff != nil {
+			buff.SendPLI(false)
+		}
+	})
+	if w.replayDuration > 0 && w.kind == webrtc.RTPCodecTypeVideo && !w.isSVC {
+		w.replayBuffer = newReplayBuffer(w.replayDuration)
+	}
 	w.trackInfo.Store(proto.Clone(trackInfo).(*livekit.TrackInfo))
 
 	w.downTrackSpreader = NewDownTrackSpreader(DownTrackSpreaderParams{
@@ -219,6 +270,7 @@ func NewWebRTCReceiver(
 	w.connectionStats = connectionquality.NewConnectionStats(connectionquality.ConnectionStatsParams{
 		MimeType:         w.codec.MimeType,
 		IsFECEnabled:     strings.EqualFold(w.codec.MimeType, webrtc.MimeTypeOpus) && strings.Contains(strings.ToLower(w.codec.SDPFmtpLine), "fec"),
+		QualityConfig:    w.connectionQualityConfig,
 		ReceiverProvider: w,
 		Logger:           w.logger.WithValues("direction", "up"),
 	})
@@ -231,6 +283,9 @@ func NewWebRTCReceiver(
 
 	w.streamTrackerManager = NewStreamTrackerManager(logger, trackInfo, w.isSVC, w.codec.ClockRate, trackersConfig)
 	w.streamTrackerManager.SetListener(w)
+	if w.isLayerBitrateEnabled() {
+		w.streamTrackerManager.SetLayerBitrateProvider(w.getLayerBitrates)
+	}
 	// SVC-TODO: Handle DD for non-SVC cases???
 	if w.isSVC {
 		for _, ext := range receiver.GetParameters().HeaderExtensions {
@@ -336,12 +391,20 @@ func (w *WebRTCReceiver) AddUpTrack(track *webrtc.TrackRemote, buff *buffer.Buff
 	}
 	buff.SetLogger(w.logger.WithValues("layer", layer))
 	buff.SetAudioLevelParams(audio.AudioLevelParams{
-		ActiveLevel:     w.audioConfig.ActiveLevel,
-		MinPercentile:   w.audioConfig.MinPercentile,
-		ObserveDuration: w.audioConfig.UpdateInterval,
-		SmoothIntervals: w.audioConfig.SmoothIntervals,
+		ActiveLevel:         w.audioConfig.ActiveLevel,
+		MinPercentile:       w.audioConfig.MinPercentile,
+		ObserveDuration:     w.audioConfig.UpdateInterval,
+		SmoothIntervals:     w.audioConfig.SmoothIntervals,
+		InactiveLevel:       w.audioConfig.InactiveLevel,
+		ActiveHoldIntervals: w.audioConfig.ActiveHoldIntervals,
 	})
 	buff.SetAudioLossProxying(w.audioConfig.EnableLossProxying)
+	if w.isLayerBitrateEnabled() {
+		buff.SetLayerBitrateParams(buffer.LayerBitrateParams{
+			SampleInterval: w.layerBitrateConfig.SampleInterval,
+			HalfLife:       w.layerBitrateConfig.HalfLife,
+		})
+	}
 	buff.OnRtcpFeedback(w.sendRTCP)
 	buff.OnRtcpSenderReport(func() {
 		srData := buff.GetSenderReportData()
@@ -349,21 +412,8 @@ func (w *WebRTCReceiver) AddUpTrack(track *webrtc.TrackRemote, buff *buffer.Buff
 			_ = dt.HandleRTCPSenderReportData(w.codec.PayloadType, w.isSVC, layer, srData)
 		})
 	})
-
-	var duration time.Duration
-	switch layer {
-	case 2:
-		duration = w.pliThrottleConfig.HighQuality
-	case 1:
-		duration = w.pliThrottleConfig.MidQuality
-	case 0:
-		duration = w.pliThrottleConfig.LowQuality
-	default:
-		duration = w.pliThrottleConfig.MidQuality
-	}
-	if duration != 0 {
-		buff.SetPLIThrottle(duration.Nanoseconds())
-	}
+	// PLIs are throttled per layer by pliCoordinator
+	buff.SetPLIThrottle(0)
 
 	w.bufferMu.Lock()
 	if w.upTracks[layer] != nil {
@@ -418,6 +468,10 @@ func (w *WebRTCReceiver) AddDownTrack(track TrackSender) error {
 	track.UpTrackMaxPublishedLayerChange(w.streamTrackerManager.GetMaxPublishedLayer())
 	track.UpTrackMaxTemporalLayerSeenChange(w.streamTrackerManager.GetMaxTemporalLayerSeen())
 
+	if w.replayBuffer != nil {
+		w.replayBuffer.removeTrack(track.SubscriberID())
+	}
+
 	w.downTrackSpreader.Store(track)
 	w.logger.Debugw("downtrack added", "subscriberID", track.SubscriberID())
 	w.handleDowntrackAdded()
@@ -452,6 +506,14 @@ func (w *WebRTCReceiver) notifyMaxExpectedLayer(layer int32) {
 	w.connectionStats.AddBitrateTransition(expectedBitrate)
 }
 
+// SetMaxForwardedSpatialLayer stops forwarding spatial layers above given layer, even if publisher sends them.
+// Dropped layers are not seen by stream trackers and become unavailable to down tracks.
+func (w *WebRTCReceiver) SetMaxForwardedSpatialLayer(layer int32) {
+	if w.maxForwardedSpatialLayer.Swap(layer) != layer {
+		w.logger.Debugw("setting max forwarded spatial layer", "layer", layer)
+	}
+}
+
 func (w *WebRTCReceiver) SetMaxExpectedSpatialLayer(layer int32) {
 	w.streamTrackerManager.SetMaxExpectedSpatialLayer(layer)
 	w.notifyMaxExpectedLayer(layer)
@@ -513,12 +575,51 @@ func (w *WebRTCReceiver) OnBitrateReport(availableLayers []int32, bitrates Bitra
 	})
 
 	w.connectionStats.AddLayerTransition(w.streamTrackerManager.DistanceToDesired())
+
+	if w.isLayerBitrateEnabled() {
+		for spatial := range bitrates {
+			for temporal, bitrate := range bitrates[spatial] {
+				if bitrate != 0 {
+					prometheus.RecordLayerBitrate(int32(spatial), int32(temporal), bitrate)
+				}
+			}
+		}
+	}
 }
 
 func (w *WebRTCReceiver) GetLayeredBitrate() ([]int32, Bitrates) {
 	return w.streamTrackerManager.GetLayeredBitrate()
 }
 
+func (w *WebRTCReceiver) isLayerBitrateEnabled() bool {
+	return w.layerBitrateConfig.Enabled && w.kind == webrtc.RTPCodecTypeVideo
+}
+
+// getLayerBitrates combines layer bitrates measured by up track buffers,
+// simulcast layers are in separate buffers, SVC layers are in one buffer
+func (w *WebRTCReceiver) getLayerBitrates() Bitrates {
+	var br Bitrates
+
+	w.bufferMu.RLock()
+	defer w.bufferMu.RUnlock()
+
+	for layer, buff := range w.buffers {
+		if buff == nil {
+			continue
+		}
+
+		brs, ok := buff.GetLayerBitrates()
+		if !ok {
+			continue
+		}
+		if w.isSVC {
+			return brs
+		}
+		br[layer] = brs[0]
+	}
+	return br
+}
+
 // OnCloseHandler method to be called on remote tracked removed
 func (w *WebRTCReceiver) OnCloseHandler(fn func()) {
 	w.onCloseHandler = fn
@@ -531,6 +632,9 @@ func (w *WebRTCReceiver) DeleteDownTrack(subscriberID livekit.ParticipantID) {
 	}
 
 	w.downTrackSpreader.Free(subscriberID)
+	if w.replayBuffer != nil {
+		w.replayBuffer.removeTrack(subscriberID)
+	}
 	w.logger.Debugw("downtrack deleted", "subscriberID", subscriberID)
 }
 
@@ -551,7 +655,19 @@ func (w *WebRTCReceiver) SendPLI(layer int32, force bool) {
 		return
 	}
 
-	buff.SendPLI(force)
+	if w.isSVC {
+		// all layers share one stream
+		layer = 0
+	}
+	buff.UpdatePliRequest(!w.pliCoordinator.request(layer, force))
+}
+
+func (w *WebRTCReceiver) ReplayKeyFrame(track TrackSender, layer int32) bool {
+	if w.replayBuffer == nil || w.closed.Load() {
+		return false
+	}
+
+	return w.replayBuffer.request(track, layer)
 }
 
 func (w *WebRTCReceiver) getBuffer(layer int32) *buffer.Buffer {
@@ -605,6 +721,24 @@ func (w *WebRTCReceiver) GetTrackStats() *livekit.RTPStats {
 	return buffer.AggregateRTPStats(stats)
 }
 
+// GetLayerTrackStats returns stats of each spatial layer
+func (w *WebRTCReceiver) GetLayerTrackStats() map[int32]*livekit.RTPStats {
+	w.bufferMu.RLock()
+	defer w.bufferMu.RUnlock()
+
+	stats := make(map[int32]*livekit.RTPStats, len(w.buffers))
+	for layer, buff := range w.buffers {
+		if buff == nil {
+			continue
+		}
+
+		if s := buff.GetStats(); s != nil {
+			stats[int32(layer)] = s
+		}
+	}
+	return stats
+}
+
 func (w *WebRTCReceiver) GetAudioLevel() (float64, bool) {
 	if w.Kind() == webrtc.RTPCodecTypeVideo {
 		return 0, false
@@ -670,8 +804,26 @@ func (w *WebRTCReceiver) GetLastSenderReportTime() time.Time {
 	return latestSRTime
 }
 
+// GetSenderReportData returns the most recently received RTCP sender report across layers
+func (w *WebRTCReceiver) GetSenderReportData() *buffer.RTCPSenderReportData {
+	w.bufferMu.RLock()
+	defer w.bufferMu.RUnlock()
+
+	var latest *buffer.RTCPSenderReportData
+	for _, buff := range w.buffers {
+		if buff == nil {
+			continue
+		}
+
+		if srData := buff.GetSenderReportData(); srData != nil && (latest == nil || srData.At.After(latest.At)) {
+			latest = srData
+		}
+	}
+
+	return latest
+}
+
 func (w *WebRTCReceiver) forwardRTP(layer int32) {
-	pktBuf := make([]byte, bucket.MaxPktSize)
 	tracker := w.streamTrackerManager.GetTracker(layer)
 
 	defer func() {
@@ -697,7 +849,7 @@ func (w *WebRTCReceiver) forwardRTP(layer int32) {
 		buf := w.buffers[layer]
 		redPktWriter := w.redPktWriter
 		w.bufferMu.RUnlock()
-		pkt, err := buf.ReadExtended(pktBuf)
+		pkt, err := buf.ReadExtendedRef()
 		if err == io.EOF {
 			return
 		}
@@ -712,6 +864,22 @@ func (w *WebRTCReceiver) forwardRTP(layer int32) {
 				spatialTracker = w.streamTrackerManager.AddTracker(pkt.Spatial)
 			}
 		}
+		if spatialLayer > w.maxForwardedSpatialLayer.Load() {
+			pkt.PayloadRef.Release()
+			continue
+		}
+		if pkt.KeyFrame {
+			if w.isSVC {
+				w.pliCoordinator.onKeyFrame(0)
+			} else {
+				w.pliCoordinator.onKeyFrame(spatialLayer)
+			}
+		}
+		var priming map[livekit.ParticipantID]struct{}
+		if w.replayBuffer != nil {
+			// retain for down tracks added later, down tracks being primed get the packet from the replay buffer
+			priming = w.replayBuffer.add(pkt, spatialLayer)
+		}
 		if spatialLayer > buffer.DefaultMaxLayerSpatial { // TODO-REMOVE-AFTER-DEBUG
 			w.logger.Warnw(
 				"invalid spatial layer", nil,
@@ -730,6 +898,9 @@ func (w *WebRTCReceiver) forwardRTP(layer int32) {
 		}
 
 		writeCount := w.downTrackSpreader.Broadcast(func(dt TrackSender) {
+			if _, ok := priming[dt.SubscriberID()]; ok {
+				return
+			}
 			_ = dt.WriteRTP(pkt, spatialLayer)
 		})
 
@@ -751,11 +922,15 @@ func (w *WebRTCReceiver) forwardRTP(layer int32) {
 				pkt.DependencyDescriptor,
 			)
 		}
+
+		// down tracks still sending the payload hold references of their own
+		pkt.PayloadRef.Release()
 	}
 }
 
 // closeTracks close all tracks from Receiver
 func (w *WebRTCReceiver) closeTracks() {
+	w.pliCoordinator.close()
 	w.connectionStats.Close()
 	w.streamTrackerManager.Close()
 
@@ -780,11 +955,17 @@ func (w *WebRTCReceiver) DebugInfo() map[string]interface{} {
 	upTrackInfo := make([]map[string]interface{}, 0, len(w.upTracks))
 	for layer, ut := range w.upTracks {
 		if ut != nil {
+			var pliRequests, plisCoalesced uint32
+			if buff := w.buffers[layer]; buff != nil {
+				pliRequests, plisCoalesced = buff.PliRequests()
+			}
 			upTrackInfo = append(upTrackInfo, map[string]interface{}{
-				"Layer": layer,
-				"SSRC":  ut.SSRC(),
-				"Msid":  ut.Msid(),
-				"RID":   ut.RID(),
+				"Layer":         layer,
+				"SSRC":          ut.SSRC(),
+				"Msid":          ut.Msid(),
+				"RID":           ut.RID(),
+				"PliRequests":   pliRequests,
+				"PlisCoalesced": plisCoalesced,
 			})
 		}
 	}
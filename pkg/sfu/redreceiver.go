@@ -81,6 +81,8 @@ func (r *RedReceiver) ForwardRTP(pkt *buffer.ExtPacket, spatialLayer int32) int
 	redRtpPacket.PayloadType = 63
 	redRtpPacket.Payload = r.redPayloadBuf[:redLen]
 	pPkt.Packet = &redRtpPacket
+	// payload is not in the packet buffer of the primary packet
+	pPkt.PayloadRef = nil
 
 	// not modify the ExtPacket.RawPacket here for performance since it is not used by the DownTrack,
 	// otherwise it should be set to the correct value (marshal the primary rtp packet)
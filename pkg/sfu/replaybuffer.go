@@ -0,0 +1,204 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	// cap on packets retained per layer, a long key frame interval at high bitrate
+	// should not hold an unbounded amount of memory
+	maxReplayPacketsPerLayer = 4096
+)
+
+// replayBuffer retains recent packets of each spatial layer, starting at a key frame,
+// so that a down track added late can be primed with a key frame and the media after it
+// instead of waiting for the publisher to answer a PLI.
+//
+// Packets are kept in groups starting at a key frame. The most recent groups covering
+// the configured duration are retained, i. e. replay starts at the latest key frame
+// that is at least that old, or at the oldest one retained.
+//
+// Each down track is primed in its own goroutine, off the forwarding path. Until it has
+// caught up with the retained packets, the forwarding path does not write live packets
+// of that layer to it, they reach the down track through the replay buffer instead.
+type replayBuffer struct {
+	lock     sync.Mutex
+	duration int64
+	layers   [buffer.DefaultMaxLayerSpatial + 1]replayLayer
+	primed   map[livekit.ParticipantID]bool
+}
+
+type replayLayer struct {
+	groups     [][]*buffer.ExtPacket
+	numPackets int
+	// index of the first retained packet among all packets retained for the layer
+	first   uint64
+	priming map[livekit.ParticipantID]struct{}
+}
+
+func newReplayBuffer(duration time.Duration) *replayBuffer {
+	return &replayBuffer{
+		duration: duration.Nanoseconds(),
+		primed:   make(map[livekit.ParticipantID]bool),
+	}
+}
+
+// add retains a copy of pkt, packets received before the first key frame of a layer are ignored.
+// Returns down tracks being primed on layer, the caller should not write pkt to them.
+func (r *replayBuffer) add(pkt *buffer.ExtPacket, layer int32) map[livekit.ParticipantID]struct{} {
+	if layer < 0 || int(layer) >= len(r.layers) {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	l := &r.layers[layer]
+	if pkt.KeyFrame && (len(l.groups) == 0 || l.groups[len(l.groups)-1][0].Packet.Timestamp != pkt.Packet.Timestamp) {
+		l.groups = append(l.groups, nil)
+	}
+	if len(l.groups) == 0 {
+		// not retained, down tracks being primed cannot get it from here
+		l.priming = nil
+		return nil
+	}
+
+	cloned := *pkt
+	cloned.Packet = pkt.Packet.Clone()
+	cloned.RawPacket = nil
+	cloned.PayloadRef = nil
+	l.groups[len(l.groups)-1] = append(l.groups[len(l.groups)-1], &cloned)
+	l.numPackets++
+
+	// drop the oldest group while the rest still covers the duration
+	for len(l.groups) > 1 && (pkt.Arrival-l.groups[1][0].Arrival >= r.duration || l.numPackets > maxReplayPacketsPerLayer) {
+		l.numPackets -= len(l.groups[0])
+		l.first += uint64(len(l.groups[0]))
+		l.groups = l.groups[1:]
+	}
+	if l.numPackets > maxReplayPacketsPerLayer {
+		// single group too large, wait for next key frame
+		l.first += uint64(l.numPackets)
+		l.groups = nil
+		l.numPackets = 0
+		l.priming = nil
+		return nil
+	}
+
+	if len(l.priming) == 0 {
+		return nil
+	}
+	priming := make(map[livekit.ParticipantID]struct{}, len(l.priming))
+	for subscriberID := range l.priming {
+		priming[subscriberID] = struct{}{}
+	}
+	return priming
+}
+
+// request starts priming track with retained packets of layer,
+// returns false if nothing is retained for layer or track was primed before
+func (r *replayBuffer) request(track TrackSender, layer int32) bool {
+	if layer < 0 || int(layer) >= len(r.layers) {
+		return false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	l := &r.layers[layer]
+	if len(l.groups) == 0 || r.primed[track.SubscriberID()] {
+		return false
+	}
+
+	r.primed[track.SubscriberID()] = true
+	if l.priming == nil {
+		l.priming = make(map[livekit.ParticipantID]struct{})
+	}
+	l.priming[track.SubscriberID()] = struct{}{}
+	go r.prime(track, layer, l.first)
+	return true
+}
+
+// prime writes retained packets of layer to track, starting at index next, until it has caught up
+// with the forwarding path
+func (r *replayBuffer) prime(track TrackSender, layer int32, next uint64) {
+	for {
+		packets, ok := r.nextPackets(track.SubscriberID(), layer, next)
+		if !ok {
+			return
+		}
+		if track.IsClosed() {
+			r.stopPriming(track.SubscriberID(), layer)
+			return
+		}
+
+		for _, pkt := range packets {
+			_ = track.WriteRTP(pkt, layer)
+		}
+		next += uint64(len(packets))
+	}
+}
+
+// nextPackets returns packets of layer retained from index next on. When there are none, or packets were
+// dropped before track got them, priming ends and the forwarding path resumes writing to the track.
+func (r *replayBuffer) nextPackets(subscriberID livekit.ParticipantID, layer int32, next uint64) ([]*buffer.ExtPacket, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	l := &r.layers[layer]
+	if _, ok := l.priming[subscriberID]; !ok {
+		return nil, false
+	}
+	if next < l.first || next >= l.first+uint64(l.numPackets) {
+		delete(l.priming, subscriberID)
+		return nil, false
+	}
+
+	skip := int(next - l.first)
+	packets := make([]*buffer.ExtPacket, 0, l.numPackets-skip)
+	for _, group := range l.groups {
+		if skip >= len(group) {
+			skip -= len(group)
+			continue
+		}
+		packets = append(packets, group[skip:]...)
+		skip = 0
+	}
+	return packets, true
+}
+
+func (r *replayBuffer) stopPriming(subscriberID livekit.ParticipantID, layer int32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.layers[layer].priming, subscriberID)
+}
+
+func (r *replayBuffer) removeTrack(subscriberID livekit.ParticipantID) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.primed, subscriberID)
+	for i := range r.layers {
+		delete(r.layers[i].priming, subscriberID)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/testutils"
+)
+
+type replayDowntrack struct {
+	TrackSender
+	lock         sync.Mutex
+	receivedPkts []*rtp.Packet
+}
+
+func (dt *replayDowntrack) WriteRTP(p *buffer.ExtPacket, _ int32) error {
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	dt.receivedPkts = append(dt.receivedPkts, p.Packet)
+	return nil
+}
+
+func (dt *replayDowntrack) sequenceNumbers() []uint16 {
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	var sns []uint16
+	for _, pkt := range dt.receivedPkts {
+		sns = append(sns, pkt.SequenceNumber)
+	}
+	return sns
+}
+
+func TestReplayBuffer(t *testing.T) {
+	r := newReplayBuffer(time.Second)
+	start := time.Now()
+
+	getPacket := func(sn uint16) *buffer.ExtPacket {
+		// one packet per 100ms, key frame every 10 packets
+		pkt, err := testutils.GetTestExtPacket(&testutils.TestExtPacketParams{
+			IsKeyFrame:     sn%10 == 0,
+			SequenceNumber: sn,
+			Timestamp:      uint32(sn) * 9000,
+			PayloadSize:    10,
+			ArrivalTime:    start.Add(time.Duration(sn) * 100 * time.Millisecond),
+		})
+		require.NoError(t, err)
+		return pkt
+	}
+
+	// starting mid GOP
+	for sn := uint16(5); sn < 35; sn++ {
+		require.Nil(t, r.add(getPacket(sn), 0))
+	}
+
+	// nothing retained for other layers
+	dt := &replayDowntrack{TrackSender: &DownTrack{}}
+	require.False(t, r.request(dt, 1))
+
+	require.True(t, r.request(dt, 0))
+	// primed only once
+	require.False(t, r.request(dt, 0))
+
+	// GOP starting at 10 is dropped, GOP starting at 30 alone does not cover a second
+	var expected []uint16
+	for sn := uint16(20); sn < 35; sn++ {
+		expected = append(expected, sn)
+	}
+	require.Eventually(t, func() bool {
+		return len(dt.sequenceNumbers()) == len(expected)
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, expected, dt.sequenceNumbers())
+
+	// caught up, live packets are written by the forwarding path again
+	require.Eventually(t, func() bool {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		return len(r.layers[0].priming) == 0
+	}, time.Second, 10*time.Millisecond)
+	require.Nil(t, r.add(getPacket(35), 0))
+	require.Len(t, dt.sequenceNumbers(), len(expected))
+
+	// can be primed again once removed
+	r.removeTrack(dt.SubscriberID())
+	require.True(t, r.request(dt, 0))
+}
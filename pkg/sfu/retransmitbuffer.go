@@ -0,0 +1,154 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"github.com/livekit/livekit-server/pkg/sfu/pacer"
+	"github.com/livekit/livekit-server/pkg/sfu/utils"
+)
+
+// retransmitBuffer holds a ring of recently sent packets, indexed by outgoing sequence number,
+// so that NACKs can be answered with the exact bytes that were sent on the down track.
+// This is needed when the upstream packet cannot be read back from the receiver,
+// for example, on relay forwarded tracks.
+// Payloads are not copied, entries hold a reference to the pooled buffer the packet was sent from.
+type retransmitBuffer struct {
+	lock    sync.Mutex
+	entries []retransmitEntry
+	closed  bool
+}
+
+type retransmitEntry struct {
+	valid      bool
+	header     rtp.Header
+	extensions []pacer.ExtensionData
+	payload    []byte
+	payloadRef *utils.PacketRef
+}
+
+func newRetransmitBuffer(size int) *retransmitBuffer {
+	return &retransmitBuffer{
+		entries: make([]retransmitEntry, size),
+	}
+}
+
+// add stores a sent packet, payload must be backed by payloadRef, which is retained until
+// the entry is overwritten or the buffer is closed. Packets added after close are not stored.
+func (r *retransmitBuffer) add(hdr *rtp.Header, extensions []pacer.ExtensionData, payload []byte, payloadRef *utils.PacketRef) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	entry := &r.entries[int(hdr.SequenceNumber)%len(r.entries)]
+	if entry.payloadRef != nil {
+		entry.payloadRef.Release()
+	}
+
+	entry.valid = true
+	entry.header = *hdr
+	entry.header.CSRC = append([]uint32{}, hdr.CSRC...)
+	entry.header.Extensions = nil
+
+	entry.extensions = entry.extensions[:0]
+	for _, ext := range extensions {
+		entry.extensions = append(entry.extensions, pacer.ExtensionData{
+			ID:      ext.ID,
+			Payload: append([]byte{}, ext.Payload...),
+		})
+	}
+
+	entry.payload = payload
+	entry.payloadRef = payloadRef.Retain()
+}
+
+// close releases payload references held by the buffer
+func (r *retransmitBuffer) close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.closed = true
+
+	for i := range r.entries {
+		entry := &r.entries[i]
+		if entry.payloadRef != nil {
+			entry.payloadRef.Release()
+		}
+		*entry = retransmitEntry{}
+	}
+}
+
+// get copies payload of the packet with given sequence number into buf and
+// returns the number of bytes copied along with the header and extensions sent
+func (r *retransmitBuffer) get(sn uint16, buf []byte) (rtp.Header, []pacer.ExtensionData, int, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	entry := &r.entries[int(sn)%len(r.entries)]
+	if !entry.valid || entry.header.SequenceNumber != sn || len(buf) < len(entry.payload) {
+		return rtp.Header{}, nil, 0, false
+	}
+
+	extensions := make([]pacer.ExtensionData, len(entry.extensions))
+	copy(extensions, entry.extensions)
+	return entry.header, extensions, copy(buf, entry.payload), true
+}
+
+// ---------------------------------------------------------------------
+
+// retransmitBudget is a token bucket limiting the bitrate spent on retransmissions.
+// It allows a burst of up to one second worth of budget.
+type retransmitBudget struct {
+	lock        sync.Mutex
+	bytesPerSec float64
+	tokens      float64
+	lastRefill  time.Time
+}
+
+func newRetransmitBudget(kbps int) *retransmitBudget {
+	bytesPerSec := float64(kbps) * 1000 / 8
+	return &retransmitBudget{
+		bytesPerSec: bytesPerSec,
+		tokens:      bytesPerSec,
+		lastRefill:  time.Now(),
+	}
+}
+
+func (r *retransmitBudget) allow(size int, at time.Time) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if elapsed := at.Sub(r.lastRefill).Seconds(); elapsed > 0 {
+		r.tokens += elapsed * r.bytesPerSec
+		if r.tokens > r.bytesPerSec {
+			r.tokens = r.bytesPerSec
+		}
+		r.lastRefill = at
+	}
+
+	if r.tokens < float64(size) {
+		return false
+	}
+
+	r.tokens -= float64(size)
+	return true
+}
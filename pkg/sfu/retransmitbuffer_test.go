@@ -0,0 +1,104 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/sfu/pacer"
+	"github.com/livekit/livekit-server/pkg/sfu/utils"
+)
+
+func TestRetransmitBuffer(t *testing.T) {
+	r := newRetransmitBuffer(4)
+
+	for sn := uint16(65534); sn != 3; sn++ {
+		payloadRef := utils.NewPacketRef()
+		payload := payloadRef.Bytes()[:2]
+		payload[0], payload[1] = byte(sn), byte(sn)
+		r.add(
+			&rtp.Header{SequenceNumber: sn, Timestamp: uint32(sn) * 10},
+			[]pacer.ExtensionData{{ID: 1, Payload: []byte{byte(sn)}}},
+			payload,
+			payloadRef,
+		)
+		payloadRef.Release()
+	}
+
+	buf := make([]byte, 10)
+
+	// evicted by wrap around
+	_, _, _, found := r.get(65534, buf)
+	require.False(t, found)
+
+	hdr, extensions, n, found := r.get(2, buf)
+	require.True(t, found)
+	require.Equal(t, uint16(2), hdr.SequenceNumber)
+	require.Equal(t, uint32(20), hdr.Timestamp)
+	require.Equal(t, []pacer.ExtensionData{{ID: 1, Payload: []byte{2}}}, extensions)
+	require.Equal(t, []byte{2, 2}, buf[:n])
+
+	// not enough room to copy payload
+	_, _, _, found = r.get(2, buf[:1])
+	require.False(t, found)
+
+	// entries keep their own reference after the sender released the payload
+	_, _, n, found = r.get(1, buf)
+	require.True(t, found)
+	require.Equal(t, []byte{1, 1}, buf[:n])
+
+	r.close()
+	_, _, _, found = r.get(1, buf)
+	require.False(t, found)
+}
+
+func TestRetransmitBufferAddAfterClose(t *testing.T) {
+	r := newRetransmitBuffer(4)
+
+	payloadRef := utils.NewPacketRef()
+	defer payloadRef.Release()
+	r.add(&rtp.Header{SequenceNumber: 1}, nil, payloadRef.Bytes()[:2], payloadRef)
+	require.Same(t, payloadRef, r.entries[1].payloadRef)
+
+	// closing releases references held by the buffer
+	r.close()
+	require.Nil(t, r.entries[1].payloadRef)
+
+	// packets sent after close, e.g. by a write racing with close, are not held
+	r.add(&rtp.Header{SequenceNumber: 2}, nil, payloadRef.Bytes()[:2], payloadRef)
+	require.Nil(t, r.entries[2].payloadRef)
+	_, _, _, found := r.get(2, make([]byte, 10))
+	require.False(t, found)
+}
+
+func TestRetransmitBudget(t *testing.T) {
+	now := time.Now()
+	b := newRetransmitBudget(80) // 10000 bytes/sec
+	b.lastRefill = now
+
+	require.True(t, b.allow(6000, now))
+	require.False(t, b.allow(6000, now))
+
+	// half a second refills half the budget
+	require.True(t, b.allow(6000, now.Add(500*time.Millisecond)))
+
+	// refill does not exceed one second worth of budget
+	require.True(t, b.allow(10000, now.Add(time.Minute)))
+	require.False(t, b.allow(1, now.Add(time.Minute)))
+}
@@ -27,6 +27,9 @@ const (
 	defaultRtt           = 70
 	ignoreRetransmission = 100 // Ignore packet retransmission after ignoreRetransmission milliseconds
 	maxAck               = 3
+
+	// max retransmission attempts of a packet when the subscriber's latency budget fits more round trips
+	maxAckLatencyBudget = 10
 )
 
 func btoi(b bool) int {
@@ -64,6 +67,8 @@ type packetMeta struct {
 	lastNack uint32
 	// number of NACKs this packet has received
 	nacked uint8
+	// when the packet was sent, same resolution as lastNack
+	sentAt uint32
 	// Spatial layer of packet
 	layer int8
 	// Information that differs depending on the codec
@@ -99,6 +104,9 @@ type sequencer struct {
 	snRangeMap   *utils.RangeMap[uint64, uint64]
 	rtt          uint32
 	logger       logger.Logger
+
+	// latency tolerated by the subscriber in ms, 0 for no budget
+	latencyBudget uint32
 }
 
 func newSequencer(size int, maybeSparse bool, logger logger.Logger) *sequencer {
@@ -127,6 +135,30 @@ func (s *sequencer) setRTT(rtt uint32) {
 	}
 }
 
+func (s *sequencer) setLatencyBudget(budget time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.latencyBudget = uint32(budget.Milliseconds())
+}
+
+// maxNacksLocked returns the number of retransmission attempts allowed per packet,
+// as many as round trips fit in the latency budget when there is one
+func (s *sequencer) maxNacksLocked() uint8 {
+	if s.latencyBudget == 0 || s.rtt == 0 {
+		return maxAck
+	}
+
+	attempts := s.latencyBudget / s.rtt
+	if attempts < 1 {
+		return 1
+	}
+	if attempts > maxAckLatencyBudget {
+		return maxAckLatencyBudget
+	}
+	return uint8(attempts)
+}
+
 func (s *sequencer) push(
 	packetTime int64,
 	extIncomingSN, extModifiedSN uint64,
@@ -198,6 +230,7 @@ func (s *sequencer) push(
 	}
 
 	slot := extModifiedSNAdjusted % uint64(s.size)
+	refTime := s.getRefTime(packetTime)
 	s.meta[slot] = packetMeta{
 		sourceSeqNo:     uint16(extIncomingSN),
 		targetSeqNo:     uint16(extModifiedSN),
@@ -205,7 +238,8 @@ func (s *sequencer) push(
 		marker:          marker,
 		layer:           layer,
 		numCodecBytesIn: uint8(numCodecBytesIn),
-		lastNack:        s.getRefTime(packetTime), // delay retransmissions after the original transmission
+		lastNack:        refTime, // delay retransmissions after the original transmission
+		sentAt:          refTime,
 	}
 	pm := &s.meta[slot]
 
@@ -297,20 +331,15 @@ func (s *sequencer) getExtPacketMetas(seqNo []uint16) []extPacketMeta {
 	var err error
 	extPacketMetas := make([]extPacketMeta, 0, len(seqNo))
 	refTime := s.getRefTime(time.Now().UnixNano())
-	highestSN := uint16(s.extHighestSN)
-	highestTS := uint32(s.extHighestTS)
+	maxNacks := s.maxNacksLocked()
 	for _, sn := range seqNo {
-		diff := highestSN - sn
-		if diff > (1 << 15) {
+		extSN := utils.ExtendFrom(sn, s.extHighestSN)
+		if extSN > s.extHighestSN {
 			// out-of-order from head (should not happen, just be safe)
 			continue
 		}
 
 		// find slot by adjusting for padding only packets that were not recorded in sequencer
-		extSN := uint64(sn) + (s.extHighestSN & 0xFFFF_FFFF_FFFF_0000)
-		if sn > highestSN {
-			extSN -= (1 << 16)
-		}
 
 		if s.snRangeMap != nil {
 			snOffset, err = s.snRangeMap.GetValue(extSN)
@@ -334,18 +363,19 @@ func (s *sequencer) getExtPacketMetas(seqNo []uint16) []extPacketMeta {
 			continue
 		}
 
-		if meta.nacked < maxAck && refTime-meta.lastNack > uint32(math.Min(float64(ignoreRetransmission), float64(2*s.rtt))) {
+		if s.latencyBudget != 0 && refTime-meta.sentAt+s.rtt/2 > s.latencyBudget {
+			// retransmission would arrive after the subscriber's latency budget
+			continue
+		}
+
+		if meta.nacked < maxNacks && refTime-meta.lastNack > uint32(math.Min(float64(ignoreRetransmission), float64(2*s.rtt))) {
 			meta.nacked++
 			meta.lastNack = refTime
 
-			extTS := uint64(meta.timestamp) + (s.extHighestTS & 0xFFFF_FFFF_0000_0000)
-			if meta.timestamp > highestTS {
-				extTS -= (1 << 32)
-			}
 			epm := extPacketMeta{
 				packetMeta:        *meta,
 				extSequenceNumber: extSN,
-				extTimestamp:      extTS,
+				extTimestamp:      utils.ExtendFrom(meta.timestamp, s.extHighestTS),
 			}
 			epm.codecBytesSlice = append([]byte{}, meta.codecBytesSlice...)
 			epm.ddBytesSlice = append([]byte{}, meta.ddBytesSlice...)
@@ -366,3 +366,34 @@ func Test_sequencer_getNACKSeqNo_no_exclusion(t *testing.T) {
 		})
 	}
 }
+
+func Test_sequencer_latencyBudget(t *testing.T) {
+	seq := newSequencer(500, false, logger.GetLogger())
+	require.Equal(t, uint8(maxAck), seq.maxNacksLocked())
+
+	// attempts are limited to round trips fitting in the budget
+	seq.setRTT(70)
+	seq.setLatencyBudget(150 * time.Millisecond)
+	require.Equal(t, uint8(2), seq.maxNacksLocked())
+	seq.setLatencyBudget(50 * time.Millisecond)
+	require.Equal(t, uint8(1), seq.maxNacksLocked())
+	seq.setLatencyBudget(2 * time.Second)
+	require.Equal(t, uint8(maxAckLatencyBudget), seq.maxNacksLocked())
+
+	// packets which cannot be retransmitted within budget are not returned
+	seq.setLatencyBudget(300 * time.Millisecond)
+	now := time.Now()
+	seq.startTime = now.Add(-time.Second).UnixNano()
+	seq.push(now.Add(-500*time.Millisecond).UnixNano(), 1, 1, 123, true, 0, nil, 0, nil, nil)
+	seq.push(now.Add(-150*time.Millisecond).UnixNano(), 2, 2, 123, true, 0, nil, 0, nil, nil)
+
+	res := seq.getExtPacketMetas([]uint16{1, 2})
+	require.Equal(t, 1, len(res))
+	require.Equal(t, uint16(2), res[0].targetSeqNo)
+
+	// without a budget, both are retransmitted
+	seq.setLatencyBudget(0)
+	res = seq.getExtPacketMetas([]uint16{1})
+	require.Equal(t, 1, len(res))
+	require.Equal(t, uint16(1), res[0].targetSeqNo)
+}
@@ -0,0 +1,104 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamallocator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// AllocationPolicy adjusts the priority a track gets during allocation,
+// priority set on the track (or the default for its source) is passed in as base priority.
+// A policy can be shared across stream allocators, after its state changes,
+// it should be applied again using StreamAllocator.SetAllocationPolicy.
+type AllocationPolicy interface {
+	TrackPriority(publisherID livekit.ParticipantID, source livekit.TrackSource, basePriority uint8) uint8
+}
+
+// ------------------------------------------------
+
+// DominantSpeakerPolicy raises the priority of camera tracks published by the dominant speaker,
+// so that they get a first shot at higher layers when the channel is constrained. Camera tracks
+// of other publishers are capped below that priority. Once a speaker is dominant, it stays so
+// for at least the minimum hold time to avoid re-allocations when speakers alternate quickly.
+type DominantSpeakerPolicy struct {
+	priority uint8
+	minHold  time.Duration
+
+	lock            sync.RWMutex
+	dominantSpeaker livekit.ParticipantID
+	changedAt       time.Time
+}
+
+func NewDominantSpeakerPolicy(priority uint8, minHold time.Duration) *DominantSpeakerPolicy {
+	return &DominantSpeakerPolicy{
+		priority: priority,
+		minHold:  minHold,
+	}
+}
+
+// SetDominantSpeaker returns true if dominant speaker changed, empty to clear.
+// Change is ignored while current dominant speaker is within its minimum hold time.
+func (d *DominantSpeakerPolicy) SetDominantSpeaker(publisherID livekit.ParticipantID) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.dominantSpeaker == publisherID {
+		return false
+	}
+	if d.dominantSpeaker != "" && time.Since(d.changedAt) < d.minHold {
+		return false
+	}
+
+	d.dominantSpeaker = publisherID
+	d.changedAt = time.Now()
+	return true
+}
+
+func (d *DominantSpeakerPolicy) DominantSpeaker() livekit.ParticipantID {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return d.dominantSpeaker
+}
+
+func (d *DominantSpeakerPolicy) TrackPriority(publisherID livekit.ParticipantID, source livekit.TrackSource, basePriority uint8) uint8 {
+	if source == livekit.TrackSource_SCREEN_SHARE || publisherID == "" {
+		return basePriority
+	}
+
+	dominantSpeaker := d.DominantSpeaker()
+	if dominantSpeaker == "" {
+		return basePriority
+	}
+
+	if publisherID == dominantSpeaker {
+		if d.priority > basePriority {
+			return d.priority
+		}
+		return basePriority
+	}
+
+	// keep other camera tracks below dominant speaker
+	if basePriority >= d.priority {
+		if d.priority > PriorityMin {
+			return d.priority - 1
+		}
+		return PriorityMin
+	}
+	return basePriority
+}
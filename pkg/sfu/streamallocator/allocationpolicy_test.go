@@ -0,0 +1,94 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamallocator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestDominantSpeakerPolicy(t *testing.T) {
+	t.Run("priority", func(t *testing.T) {
+		d := NewDominantSpeakerPolicy(100, 0)
+
+		// no dominant speaker, no change
+		require.Equal(t, PriorityMin, d.TrackPriority("PA_a", livekit.TrackSource_CAMERA, PriorityMin))
+		require.Equal(t, uint8(150), d.TrackPriority("PA_a", livekit.TrackSource_CAMERA, 150))
+
+		require.True(t, d.SetDominantSpeaker("PA_a"))
+		require.False(t, d.SetDominantSpeaker("PA_a"))
+
+		// dominant speaker camera is boosted, unless already higher
+		require.Equal(t, uint8(100), d.TrackPriority("PA_a", livekit.TrackSource_CAMERA, PriorityMin))
+		require.Equal(t, uint8(150), d.TrackPriority("PA_a", livekit.TrackSource_CAMERA, 150))
+
+		// other cameras are kept below the boost
+		require.Equal(t, PriorityMin, d.TrackPriority("PA_b", livekit.TrackSource_CAMERA, PriorityMin))
+		require.Equal(t, uint8(99), d.TrackPriority("PA_b", livekit.TrackSource_CAMERA, 150))
+
+		// screen shares are not affected
+		require.Equal(t, PriorityDefaultScreenshare, d.TrackPriority("PA_a", livekit.TrackSource_SCREEN_SHARE, PriorityDefaultScreenshare))
+		require.Equal(t, PriorityDefaultScreenshare, d.TrackPriority("PA_b", livekit.TrackSource_SCREEN_SHARE, PriorityDefaultScreenshare))
+	})
+
+	t.Run("min hold", func(t *testing.T) {
+		d := NewDominantSpeakerPolicy(100, 50*time.Millisecond)
+
+		require.True(t, d.SetDominantSpeaker("PA_a"))
+
+		// held, neither switching nor clearing is allowed
+		require.False(t, d.SetDominantSpeaker("PA_b"))
+		require.False(t, d.SetDominantSpeaker(""))
+		require.Equal(t, livekit.ParticipantID("PA_a"), d.DominantSpeaker())
+
+		time.Sleep(60 * time.Millisecond)
+		require.True(t, d.SetDominantSpeaker("PA_b"))
+		require.Equal(t, livekit.ParticipantID("PA_b"), d.DominantSpeaker())
+	})
+}
+
+func TestTrackPriority(t *testing.T) {
+	track := &Track{
+		source:      livekit.TrackSource_CAMERA,
+		publisherID: "PA_a",
+	}
+
+	// default priority by source
+	require.True(t, track.SetPriority(0))
+	require.Equal(t, PriorityDefaultVideo, track.Priority())
+
+	d := NewDominantSpeakerPolicy(100, 0)
+	require.False(t, track.SetAllocationPolicy(d))
+
+	// policy is applied on top of base priority
+	d.SetDominantSpeaker("PA_a")
+	require.True(t, track.SetAllocationPolicy(d))
+	require.Equal(t, uint8(100), track.Priority())
+
+	require.True(t, track.SetPriority(200))
+	require.Equal(t, uint8(200), track.Priority())
+
+	d.SetDominantSpeaker("PA_b")
+	require.True(t, track.SetAllocationPolicy(d))
+	require.Equal(t, uint8(99), track.Priority())
+
+	// removing policy restores base priority
+	require.True(t, track.SetAllocationPolicy(nil))
+	require.Equal(t, uint8(200), track.Priority())
+}
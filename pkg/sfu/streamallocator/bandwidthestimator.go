@@ -0,0 +1,88 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamallocator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/pion/interceptor/pkg/cc"
+	"github.com/pion/interceptor/pkg/gcc"
+
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	BandwidthEstimatorGCC = "gcc"
+
+	defaultInitialBitrate = 1 * 1000 * 1000
+)
+
+var ErrUnknownBandwidthEstimator = errors.New("unknown bandwidth estimator")
+
+type BandwidthEstimatorParams struct {
+	InitialBitrate int
+	Logger         logger.Logger
+}
+
+// BandwidthEstimatorFactory creates the send side bandwidth estimator of a peer connection.
+// The estimator is fed transport-cc feedback by the congestion control interceptor,
+// stream allocator follows its target bitrate and sends probe feedback to it.
+type BandwidthEstimatorFactory func(params BandwidthEstimatorParams) (cc.BandwidthEstimator, error)
+
+var (
+	bandwidthEstimatorsLock sync.RWMutex
+	bandwidthEstimators     = map[string]BandwidthEstimatorFactory{
+		BandwidthEstimatorGCC: newGCCBandwidthEstimator,
+	}
+)
+
+// RegisterBandwidthEstimator makes an estimator available to be selected by name through
+// congestion_control.bandwidth_estimator. Builds with alternative estimators (e. g. delay based only, NADA)
+// register them before the server is started.
+func RegisterBandwidthEstimator(name string, factory BandwidthEstimatorFactory) {
+	bandwidthEstimatorsLock.Lock()
+	defer bandwidthEstimatorsLock.Unlock()
+
+	bandwidthEstimators[name] = factory
+}
+
+// GetBandwidthEstimatorFactory returns the factory of a registered estimator, empty name selects gcc
+func GetBandwidthEstimatorFactory(name string) (BandwidthEstimatorFactory, error) {
+	if name == "" {
+		name = BandwidthEstimatorGCC
+	}
+
+	bandwidthEstimatorsLock.RLock()
+	defer bandwidthEstimatorsLock.RUnlock()
+
+	factory, ok := bandwidthEstimators[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBandwidthEstimator, name)
+	}
+	return factory, nil
+}
+
+func newGCCBandwidthEstimator(params BandwidthEstimatorParams) (cc.BandwidthEstimator, error) {
+	initialBitrate := params.InitialBitrate
+	if initialBitrate == 0 {
+		initialBitrate = defaultInitialBitrate
+	}
+	return gcc.NewSendSideBWE(
+		gcc.SendSideBWEInitialBitrate(initialBitrate),
+		gcc.SendSideBWEPacer(gcc.NewNoOpPacer()),
+	)
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamallocator
+
+import (
+	"testing"
+
+	"github.com/pion/interceptor/pkg/cc"
+	"github.com/pion/interceptor/pkg/gcc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidthEstimatorFactory(t *testing.T) {
+	factory, err := GetBandwidthEstimatorFactory("")
+	require.NoError(t, err)
+	bwe, err := factory(BandwidthEstimatorParams{})
+	require.NoError(t, err)
+	require.IsType(t, &gcc.SendSideBWE{}, bwe)
+	require.NoError(t, bwe.Close())
+
+	_, err = GetBandwidthEstimatorFactory("nada")
+	require.ErrorIs(t, err, ErrUnknownBandwidthEstimator)
+
+	t.Cleanup(func() {
+		bandwidthEstimatorsLock.Lock()
+		delete(bandwidthEstimators, "nada")
+		bandwidthEstimatorsLock.Unlock()
+	})
+	var created BandwidthEstimatorParams
+	RegisterBandwidthEstimator("nada", func(params BandwidthEstimatorParams) (cc.BandwidthEstimator, error) {
+		created = params
+		return gcc.NewSendSideBWE()
+	})
+	factory, err = GetBandwidthEstimatorFactory("nada")
+	require.NoError(t, err)
+	bwe, err = factory(BandwidthEstimatorParams{InitialBitrate: 500_000})
+	require.NoError(t, err)
+	require.Equal(t, 500_000, created.InitialBitrate)
+	require.NoError(t, bwe.Close())
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamallocator
+
+import (
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+const (
+	lossBasedIncreaseLossThreshold = 0.02
+	lossBasedDecreaseLossThreshold = 0.10
+	lossBasedIncreaseFactor        = 1.05
+	// estimate does not grow past what has been used, so that it does not run away while only low layers are sent
+	lossBasedMaxUsageFactor = 1.5
+)
+
+type LossBasedEstimatorParams struct {
+	InitialEstimate int64
+	MinEstimate     int64
+	MaxEstimate     int64
+	UpdateInterval  time.Duration
+}
+
+var DefaultLossBasedEstimatorParams = LossBasedEstimatorParams{
+	InitialEstimate: 2_500_000,
+	MinEstimate:     100_000,
+	MaxEstimate:     ChannelCapacityInfinity,
+	UpdateInterval:  time.Second,
+}
+
+// LossBasedEstimator estimates channel capacity from fraction lost of RTCP receiver reports,
+// for subscribers that negotiate neither transport-cc nor REMB. Like the loss based controller of
+// GCC (draft-ietf-rmcat-gcc-02, section 6), estimate is increased by 5% while loss is below 2%,
+// held between 2% and 10%, and decreased in proportion to loss above 10%.
+//
+// Not thread safe, it is driven by the event loop of the stream allocator.
+type LossBasedEstimator struct {
+	params LossBasedEstimatorParams
+
+	estimate        int64
+	maxFractionLost uint8
+	numReports      int
+	lastUpdateAt    time.Time
+}
+
+func NewLossBasedEstimator(params LossBasedEstimatorParams) *LossBasedEstimator {
+	return &LossBasedEstimator{
+		params:   params,
+		estimate: params.InitialEstimate,
+	}
+}
+
+func (l *LossBasedEstimator) GetEstimate() int64 {
+	return l.estimate
+}
+
+// HandleReceiverReport accumulates loss of a report, once per update interval the estimate is updated
+// with worst loss seen and expected usage in that interval. Returns true if estimate was updated.
+func (l *LossBasedEstimator) HandleReceiverReport(report *rtcp.ReceiverReport, expectedUsage int64, at time.Time) bool {
+	for _, rr := range report.Reports {
+		if rr.FractionLost > l.maxFractionLost {
+			l.maxFractionLost = rr.FractionLost
+		}
+		l.numReports++
+	}
+
+	if l.lastUpdateAt.IsZero() {
+		l.lastUpdateAt = at
+		return false
+	}
+	if l.numReports == 0 || at.Sub(l.lastUpdateAt) < l.params.UpdateInterval {
+		return false
+	}
+
+	l.update(float64(l.maxFractionLost)/256.0, expectedUsage)
+
+	l.maxFractionLost = 0
+	l.numReports = 0
+	l.lastUpdateAt = at
+	return true
+}
+
+func (l *LossBasedEstimator) update(loss float64, expectedUsage int64) {
+	estimate := l.estimate
+	switch {
+	case loss < lossBasedIncreaseLossThreshold:
+		increased := int64(float64(estimate) * lossBasedIncreaseFactor)
+		if expectedUsage > 0 {
+			if usageCap := int64(float64(expectedUsage) * lossBasedMaxUsageFactor); increased > usageCap {
+				increased = max(usageCap, estimate)
+			}
+		}
+		estimate = increased
+
+	case loss > lossBasedDecreaseLossThreshold:
+		// reduce from what is being sent when that is lower, estimate could be well above usage
+		if expectedUsage > 0 && expectedUsage < estimate {
+			estimate = expectedUsage
+		}
+		estimate = int64(float64(estimate) * (1.0 - 0.5*loss))
+	}
+
+	l.estimate = min(max(estimate, l.params.MinEstimate), l.params.MaxEstimate)
+}
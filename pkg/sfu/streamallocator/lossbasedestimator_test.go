@@ -0,0 +1,80 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamallocator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLossBasedEstimator(t *testing.T) {
+	report := func(fractionLost uint8) *rtcp.ReceiverReport {
+		return &rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{{SSRC: 1234, FractionLost: fractionLost}}}
+	}
+
+	params := LossBasedEstimatorParams{
+		InitialEstimate: 1_000_000,
+		MinEstimate:     100_000,
+		MaxEstimate:     10_000_000,
+		UpdateInterval:  time.Second,
+	}
+
+	t.Run("increase on low loss", func(t *testing.T) {
+		l := NewLossBasedEstimator(params)
+		now := time.Now()
+		require.False(t, l.HandleReceiverReport(report(0), 1_000_000, now))
+		// within update interval
+		require.False(t, l.HandleReceiverReport(report(0), 1_000_000, now.Add(500*time.Millisecond)))
+		require.True(t, l.HandleReceiverReport(report(0), 1_000_000, now.Add(time.Second)))
+		require.Equal(t, int64(1_050_000), l.GetEstimate())
+	})
+
+	t.Run("increase capped by usage", func(t *testing.T) {
+		l := NewLossBasedEstimator(params)
+		now := time.Now()
+		l.HandleReceiverReport(report(0), 0, now)
+		require.True(t, l.HandleReceiverReport(report(0), 500_000, now.Add(time.Second)))
+		// does not grow past usage, but is not reduced either
+		require.Equal(t, int64(1_000_000), l.GetEstimate())
+	})
+
+	t.Run("hold on moderate loss", func(t *testing.T) {
+		l := NewLossBasedEstimator(params)
+		now := time.Now()
+		l.HandleReceiverReport(report(0), 0, now)
+		require.True(t, l.HandleReceiverReport(report(13), 1_000_000, now.Add(time.Second))) // ~5%
+		require.Equal(t, int64(1_000_000), l.GetEstimate())
+	})
+
+	t.Run("decrease on high loss", func(t *testing.T) {
+		l := NewLossBasedEstimator(params)
+		now := time.Now()
+		// worst loss of the interval counts
+		l.HandleReceiverReport(report(64), 0, now)
+		l.HandleReceiverReport(report(0), 0, now.Add(500*time.Millisecond))
+		require.True(t, l.HandleReceiverReport(report(64), 800_000, now.Add(time.Second))) // 25%
+		// reduced from usage
+		require.Equal(t, int64(700_000), l.GetEstimate())
+
+		// bounded by minimum
+		for i := 2; i < 20; i++ {
+			l.HandleReceiverReport(report(255), 0, now.Add(time.Duration(i)*time.Second))
+		}
+		require.Equal(t, int64(100_000), l.GetEstimate())
+	})
+}
@@ -44,6 +44,7 @@ type ProbeController struct {
 	probeTrendObserved        bool
 	probeEndTime              time.Time
 	probeDuration             time.Duration
+	consecutiveFailures       int
 }
 
 func NewProbeController(params ProbeControllerParams) *ProbeController {
@@ -62,6 +63,7 @@ func (p *ProbeController) Reset() {
 
 	p.lastProbeStartTime = time.Now()
 
+	p.consecutiveFailures = 0
 	p.resetProbeIntervalLocked()
 	p.resetProbeDurationLocked()
 
@@ -81,7 +83,7 @@ func (p *ProbeController) ProbeClusterDone(info ProbeClusterInfo) {
 	p.doneProbeClusterInfo = info
 }
 
-func (p *ProbeController) CheckProbe(trend ChannelTrend, highestEstimate int64) {
+func (p *ProbeController) CheckProbe(trend ChannelTrend, highestEstimate int64, nackRatio float64) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -108,6 +110,15 @@ func (p *ProbeController) CheckProbe(trend ChannelTrend, highestEstimate int64)
 		p.params.Logger.Debugw("stream allocator: probe: aborting, channel is congesting", "cluster", p.probeClusterId)
 		p.abortProbeLocked()
 
+	case p.params.Config.AbortLossPct > 0 && nackRatio*100.0 > p.params.Config.AbortLossPct:
+		p.params.Logger.Debugw(
+			"stream allocator: probe: aborting, loss",
+			"cluster", p.probeClusterId,
+			"nackRatio", nackRatio,
+			"threshold", p.params.Config.AbortLossPct,
+		)
+		p.abortProbeLocked()
+
 	case highestEstimate > p.probeGoalBps:
 		// reached goal, stop probing
 		p.params.Logger.Infow(
@@ -188,13 +199,17 @@ func (p *ProbeController) finalizeProbeLocked(trend ChannelTrend) (isNotFailing
 	p.clearProbeLocked()
 
 	if aborted || trend == ChannelTrendCongesting {
-		// failed probe, backoff
-		p.backoffProbeIntervalLocked()
+		// failed probe, backoff once enough probes have failed in a row
+		p.consecutiveFailures++
+		if p.consecutiveFailures >= p.params.Config.BackoffAfterFailures {
+			p.backoffProbeIntervalLocked()
+		}
 		p.resetProbeDurationLocked()
 		return false
 	}
 
 	// reset probe interval and increase probe duration on a upward trending probe
+	p.consecutiveFailures = 0
 	p.resetProbeIntervalLocked()
 	if trend == ChannelTrendClearing {
 		p.increaseProbeDurationLocked()
@@ -242,7 +257,7 @@ func (p *ProbeController) clearProbeLocked() {
 }
 
 func (p *ProbeController) backoffProbeIntervalLocked() {
-	p.probeInterval = time.Duration(p.probeInterval.Seconds()*p.params.Config.BackoffFactor) * time.Second
+	p.probeInterval = time.Duration(float64(p.probeInterval) * p.params.Config.BackoffFactor)
 	if p.probeInterval > p.params.Config.MaxInterval {
 		p.probeInterval = p.params.Config.MaxInterval
 	}
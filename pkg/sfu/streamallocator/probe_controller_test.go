@@ -0,0 +1,113 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package streamallocator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+func newTestProbeController(backoffAfterFailures int) *ProbeController {
+	p := NewProbeController(ProbeControllerParams{
+		Config: config.CongestionControlProbeConfig{
+			BaseInterval:         time.Second,
+			BackoffFactor:        2,
+			MaxInterval:          10 * time.Second,
+			BackoffAfterFailures: backoffAfterFailures,
+			AbortLossPct:         5,
+
+			TrendWait: time.Minute,
+
+			OveragePct:             120,
+			MinBps:                 100_000,
+			MinDuration:            100 * time.Millisecond,
+			MaxDuration:            time.Second,
+			DurationOverflowFactor: 1.25,
+			DurationIncreaseFactor: 1.5,
+		},
+		Prober: NewProber(ProberParams{Logger: logger.GetLogger()}),
+		Logger: logger.GetLogger(),
+	})
+	return p
+}
+
+// runs a probe which is aborted due to loss and returns whether it was not failing
+func runTestProbe(t *testing.T, p *ProbeController, nackRatio float64) bool {
+	clusterId, _ := p.InitProbe(100_000, 500_000)
+	require.NotEqual(t, ProbeClusterIdInvalid, clusterId)
+
+	p.CheckProbe(ChannelTrendClearing, 0, nackRatio)
+	require.True(t, p.DoesProbeNeedFinalize())
+	p.ProbeClusterDone(ProbeClusterInfo{Id: clusterId})
+
+	isHandled, isNotFailing, _ := p.MaybeFinalizeProbe(true, ChannelTrendClearing, 0)
+	require.True(t, isHandled)
+	return isNotFailing
+}
+
+func TestProbeControllerAbortOnLoss(t *testing.T) {
+	p := newTestProbeController(1)
+
+	// loss below threshold does not abort
+	clusterId, _ := p.InitProbe(100_000, 500_000)
+	p.CheckProbe(ChannelTrendClearing, 0, 0.04)
+	require.False(t, p.DoesProbeNeedFinalize())
+	require.True(t, p.IsInProbe())
+
+	// loss above threshold aborts
+	p.CheckProbe(ChannelTrendClearing, 0, 0.06)
+	require.True(t, p.DoesProbeNeedFinalize())
+
+	p.ProbeClusterDone(ProbeClusterInfo{Id: clusterId})
+	isHandled, isNotFailing, isGoalReached := p.MaybeFinalizeProbe(true, ChannelTrendClearing, 0)
+	require.True(t, isHandled)
+	require.False(t, isNotFailing)
+	require.False(t, isGoalReached)
+	require.Equal(t, 2*time.Second, p.probeInterval)
+}
+
+func TestProbeControllerBackoffAfterFailures(t *testing.T) {
+	p := newTestProbeController(2)
+
+	// first failure does not back off
+	require.False(t, runTestProbe(t, p, 0.5))
+	require.Equal(t, time.Second, p.probeInterval)
+
+	// second consecutive failure backs off, further failures keep backing off upto max
+	require.False(t, runTestProbe(t, p, 0.5))
+	require.Equal(t, 2*time.Second, p.probeInterval)
+	require.False(t, runTestProbe(t, p, 0.5))
+	require.Equal(t, 4*time.Second, p.probeInterval)
+	require.False(t, runTestProbe(t, p, 0.5))
+	require.False(t, runTestProbe(t, p, 0.5))
+	require.Equal(t, 10*time.Second, p.probeInterval)
+
+	// successful probe resets interval and failure count
+	clusterId, _ := p.InitProbe(100_000, 500_000)
+	p.ProbeClusterDone(ProbeClusterInfo{Id: clusterId})
+	isHandled, isNotFailing, _ := p.MaybeFinalizeProbe(true, ChannelTrendClearing, 0)
+	require.True(t, isHandled)
+	require.True(t, isNotFailing)
+	require.Equal(t, time.Second, p.probeInterval)
+
+	require.False(t, runTestProbe(t, p, 0.5))
+	require.Equal(t, time.Second, p.probeInterval)
+}
@@ -85,6 +85,9 @@ const (
 	streamAllocatorSignalResume
 	streamAllocatorSignalSetAllowPause
 	streamAllocatorSignalSetChannelCapacity
+	streamAllocatorSignalSetChannelCapacityCeiling
+	streamAllocatorSignalEnableLossBasedEstimation
+	streamAllocatorSignalReceiverReport
 	// STREAM-ALLOCATOR-DATA streamAllocatorSignalNACK
 	// STREAM-ALLOCATOR-DATA streamAllocatorSignalRTCPReceiverReport
 )
@@ -111,6 +114,12 @@ func (s streamAllocatorSignal) String() string {
 		return "SET_ALLOW_PAUSE"
 	case streamAllocatorSignalSetChannelCapacity:
 		return "SET_CHANNEL_CAPACITY"
+	case streamAllocatorSignalSetChannelCapacityCeiling:
+		return "SET_CHANNEL_CAPACITY_CEILING"
+	case streamAllocatorSignalEnableLossBasedEstimation:
+		return "ENABLE_LOSS_BASED_ESTIMATION"
+	case streamAllocatorSignalReceiverReport:
+		return "RECEIVER_REPORT"
 		/* STREAM-ALLOCATOR-DATA
 		case streamAllocatorSignalNACK:
 			return "NACK"
@@ -149,11 +158,14 @@ type StreamAllocator struct {
 
 	bwe cc.BandwidthEstimator
 
+	lossBasedEstimator *LossBasedEstimator
+
 	allowPause bool
 
 	lastReceivedEstimate      int64
 	committedChannelCapacity  int64
 	overriddenChannelCapacity int64
+	channelCapacityCeiling    int64
 
 	probeController *ProbeController
 
@@ -164,6 +176,7 @@ type StreamAllocator struct {
 
 	videoTracksMu        sync.RWMutex
 	videoTracks          map[livekit.TrackID]*Track
+	allocationPolicy     AllocationPolicy
 	isAllocateAllPending bool
 	rembTrackingSSRC     uint32
 
@@ -246,6 +259,7 @@ func (s *StreamAllocator) AddTrack(downTrack *sfu.DownTrack, params AddTrackPara
 
 	trackID := livekit.TrackID(downTrack.ID())
 	s.videoTracksMu.Lock()
+	track.SetAllocationPolicy(s.allocationPolicy)
 	oldTrack := s.videoTracks[trackID]
 	s.videoTracks[trackID] = track
 	s.videoTracksMu.Unlock()
@@ -291,6 +305,26 @@ func (s *StreamAllocator) SetTrackPriority(downTrack *sfu.DownTrack, priority ui
 	s.videoTracksMu.Unlock()
 }
 
+// SetAllocationPolicy sets (or re-applies after a state change of) the policy adjusting track priorities, nil to clear
+func (s *StreamAllocator) SetAllocationPolicy(policy AllocationPolicy) {
+	s.videoTracksMu.Lock()
+	s.allocationPolicy = policy
+
+	changed := false
+	for _, track := range s.videoTracks {
+		if track.SetAllocationPolicy(policy) {
+			changed = true
+		}
+	}
+	if changed && !s.isAllocateAllPending {
+		s.isAllocateAllPending = true
+		s.postEvent(Event{
+			Signal: streamAllocatorSignalAllocateAllTracks,
+		})
+	}
+	s.videoTracksMu.Unlock()
+}
+
 func (s *StreamAllocator) SetAllowPause(allowPause bool) {
 	s.postEvent(Event{
 		Signal: streamAllocatorSignalSetAllowPause,
@@ -305,6 +339,31 @@ func (s *StreamAllocator) SetChannelCapacity(channelCapacity int64) {
 	})
 }
 
+// SetChannelCapacityCeiling limits channel capacity used for allocation irrespective of estimate,
+// used to share a bandwidth budget among multiple allocators, 0 removes the ceiling
+func (s *StreamAllocator) SetChannelCapacityCeiling(ceiling int64) {
+	s.postEvent(Event{
+		Signal: streamAllocatorSignalSetChannelCapacityCeiling,
+		Data:   ceiling,
+	})
+}
+
+// EnableLossBasedEstimation sets channel capacity from loss in receiver reports,
+// a fallback for peer connections without transport-cc or REMB feedback
+func (s *StreamAllocator) EnableLossBasedEstimation() {
+	s.postEvent(Event{
+		Signal: streamAllocatorSignalEnableLossBasedEstimation,
+	})
+}
+
+// called when an RTCP receiver report is received, used with loss based estimation only
+func (s *StreamAllocator) OnReceiverReport(report *rtcp.ReceiverReport) {
+	s.postEvent(Event{
+		Signal: streamAllocatorSignalReceiverReport,
+		Data:   report,
+	})
+}
+
 func (s *StreamAllocator) resetState() {
 	s.channelObserver = s.newChannelObserverNonProbe()
 	s.probeController.Reset()
@@ -599,6 +658,12 @@ func (s *StreamAllocator) postEvent(event Event) {
 			event.handleSignalSetAllowPause(event)
 		case streamAllocatorSignalSetChannelCapacity:
 			event.handleSignalSetChannelCapacity(event)
+		case streamAllocatorSignalSetChannelCapacityCeiling:
+			event.handleSignalSetChannelCapacityCeiling(event)
+		case streamAllocatorSignalEnableLossBasedEstimation:
+			event.handleSignalEnableLossBasedEstimation(event)
+		case streamAllocatorSignalReceiverReport:
+			event.handleSignalReceiverReport(event)
 			/* STREAM-ALLOCATOR-DATA
 			case streamAllocatorSignalNACK:
 				event.s.handleSignalNACK(event)
@@ -722,6 +787,51 @@ func (s *StreamAllocator) handleSignalSetChannelCapacity(event Event) {
 	}
 }
 
+func (s *StreamAllocator) handleSignalSetChannelCapacityCeiling(event Event) {
+	ceiling := event.Data.(int64)
+	if s.channelCapacityCeiling == ceiling {
+		return
+	}
+
+	s.params.Logger.Debugw("setting channel capacity ceiling", "from", s.channelCapacityCeiling, "to", ceiling)
+	s.channelCapacityCeiling = ceiling
+	s.allocateAllTracks()
+}
+
+func (s *StreamAllocator) handleSignalEnableLossBasedEstimation(Event) {
+	if s.lossBasedEstimator != nil {
+		return
+	}
+
+	s.lossBasedEstimator = NewLossBasedEstimator(DefaultLossBasedEstimatorParams)
+	s.params.Logger.Infow("enabling loss based estimation", "estimate", s.lossBasedEstimator.GetEstimate())
+	s.setLossBasedChannelCapacity()
+}
+
+func (s *StreamAllocator) handleSignalReceiverReport(event Event) {
+	if s.lossBasedEstimator == nil {
+		return
+	}
+
+	report := event.Data.(*rtcp.ReceiverReport)
+	before := s.lossBasedEstimator.GetEstimate()
+	if !s.lossBasedEstimator.HandleReceiverReport(report, s.getExpectedBandwidthUsage(), time.Now()) {
+		return
+	}
+
+	if estimate := s.lossBasedEstimator.GetEstimate(); estimate != before {
+		s.params.Logger.Debugw("loss based estimate", "from", before, "to", estimate)
+		s.setLossBasedChannelCapacity()
+	}
+}
+
+// loss based estimate is applied as channel capacity override, same as SetChannelCapacity,
+// as there is no other estimate to be overridden
+func (s *StreamAllocator) setLossBasedChannelCapacity() {
+	s.overriddenChannelCapacity = s.lossBasedEstimator.GetEstimate()
+	s.allocateAllTracks()
+}
+
 /* STREAM-ALLOCATOR-DATA
 func (s *StreamAllocator) handleSignalNACK(event Event) {
 	nackInfos := event.Data.([]sfu.NackInfo)
@@ -786,7 +896,7 @@ func (s *StreamAllocator) handleNewEstimateInProbe() {
 	s.channelObserver.AddNack(packetDelta, repeatedNackDelta)
 
 	trend, _ := s.channelObserver.GetTrend()
-	s.probeController.CheckProbe(trend, s.channelObserver.GetHighestEstimate())
+	s.probeController.CheckProbe(trend, s.channelObserver.GetHighestEstimate(), s.channelObserver.GetNackRatio())
 }
 
 func (s *StreamAllocator) handleNewEstimateInNonProbe() {
@@ -870,8 +980,9 @@ func (s *StreamAllocator) allocateTrack(track *Track) {
 	// abort any probe that may be running when a track specific change needs allocation
 	s.probeController.AbortProbe()
 
-	// if not deficient, free pass allocate track
-	if !s.params.Config.Enabled || s.state == streamAllocatorStateStable || !track.IsManaged() {
+	// if not deficient, free pass allocate track, unless constrained by a ceiling
+	isFreePass := s.state == streamAllocatorStateStable && s.channelCapacityCeiling == 0
+	if !s.params.Config.Enabled || isFreePass || !track.IsManaged() {
 		update := NewStreamStateUpdate()
 		allocation := track.AllocateOptimal(FlagAllowOvershootWhileOptimal)
 		updateStreamStateChange(track, allocation, update)
@@ -1215,6 +1326,9 @@ func (s *StreamAllocator) getAvailableChannelCapacity(allowOverride bool) int64
 			"override", availableChannelCapacity,
 		)
 	}
+	if s.channelCapacityCeiling > 0 && availableChannelCapacity > s.channelCapacityCeiling {
+		availableChannelCapacity = s.channelCapacityCeiling
+	}
 	if allowOverride && s.overriddenChannelCapacity > 0 {
 		availableChannelCapacity = s.overriddenChannelCapacity
 		s.params.Logger.Debugw(
@@ -1322,6 +1436,10 @@ func (s *StreamAllocator) maybeProbe() {
 		// do not probe if channel capacity is overridden
 		return
 	}
+	if s.channelCapacityCeiling > 0 && s.committedChannelCapacity >= s.channelCapacityCeiling {
+		// no need to probe beyond ceiling
+		return
+	}
 	if !s.probeController.CanProbe() {
 		return
 	}
@@ -30,6 +30,10 @@ type Track struct {
 	publisherID livekit.ParticipantID
 	logger      logger.Logger
 
+	// priority is base priority adjusted by allocation policy
+	basePriority     uint8
+	allocationPolicy AllocationPolicy
+
 	maxLayer buffer.VideoLayer
 
 	totalPackets       uint32
@@ -113,6 +117,23 @@ func (t *Track) SetPriority(priority uint8) bool {
 		}
 	}
 
+	t.basePriority = priority
+	return t.updatePriority()
+}
+
+func (t *Track) SetAllocationPolicy(policy AllocationPolicy) bool {
+	t.allocationPolicy = policy
+	return t.updatePriority()
+}
+
+func (t *Track) updatePriority() bool {
+	priority := t.basePriority
+	if t.allocationPolicy != nil {
+		if policyPriority := t.allocationPolicy.TrackPriority(t.publisherID, t.source, t.basePriority); policyPriority != 0 {
+			priority = policyPriority
+		}
+	}
+
 	if t.priority == priority {
 		return false
 	}
@@ -66,6 +66,9 @@ type StreamTrackerManager struct {
 	closed core.Fuse
 
 	listener StreamTrackerManagerListener
+
+	// bitrates measured outside of stream trackers, replace tracker bitrates when set
+	layerBitrateProvider func() Bitrates
 }
 
 func NewStreamTrackerManager(
@@ -111,6 +114,14 @@ func (s *StreamTrackerManager) SetListener(listener StreamTrackerManagerListener
 	s.lock.Unlock()
 }
 
+// SetLayerBitrateProvider sets the source of layer bitrates, bitrates have to be accumulated
+// with lower temporal and spatial layers. Layers are reported only while they are available.
+func (s *StreamTrackerManager) SetLayerBitrateProvider(provider func() Bitrates) {
+	s.lock.Lock()
+	s.layerBitrateProvider = provider
+	s.lock.Unlock()
+}
+
 func (s *StreamTrackerManager) getListener() StreamTrackerManagerListener {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
@@ -412,6 +423,20 @@ func (s *StreamTrackerManager) GetLayeredBitrate() ([]int32, Bitrates) {
 func (s *StreamTrackerManager) getLayeredBitrateLocked() ([]int32, Bitrates) {
 	var br Bitrates
 
+	if s.layerBitrateProvider != nil {
+		br = s.layerBitrateProvider()
+		for i, tracker := range s.trackers {
+			if tracker == nil || !s.hasSpatialLayerLocked(int32(i)) {
+				br[i] = [buffer.DefaultMaxLayerTemporal + 1]int64{}
+			}
+		}
+
+		availableLayers := make([]int32, len(s.availableLayers))
+		copy(availableLayers, s.availableLayers)
+
+		return availableLayers, br
+	}
+
 	for i, tracker := range s.trackers {
 		if tracker != nil {
 			tls := make([]int64, buffer.DefaultMaxLayerTemporal+1)
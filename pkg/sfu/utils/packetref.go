@@ -0,0 +1,62 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/livekit/mediatransportutil/pkg/bucket"
+)
+
+var packetRefPool = sync.Pool{
+	New: func() interface{} {
+		return &PacketRef{
+			buf: make([]byte, bucket.MaxPktSize),
+		}
+	},
+}
+
+// PacketRef is a pooled, reference counted packet buffer. Stages of the forwarding path
+// (buffer, forwarder, pacer, track write) share the bytes instead of copying them, the
+// buffer returns to the pool when the last holder releases it. Shared bytes are read only.
+type PacketRef struct {
+	buf  []byte
+	refs atomic.Int32
+}
+
+// NewPacketRef returns a buffer of bucket.MaxPktSize bytes, referenced once by the caller
+func NewPacketRef() *PacketRef {
+	p := packetRefPool.Get().(*PacketRef)
+	p.refs.Store(1)
+	return p
+}
+
+func (p *PacketRef) Bytes() []byte {
+	return p.buf
+}
+
+// Retain adds a reference for another holder, which must release it when done with the bytes
+func (p *PacketRef) Retain() *PacketRef {
+	p.refs.Inc()
+	return p
+}
+
+func (p *PacketRef) Release() {
+	if p.refs.Dec() == 0 {
+		packetRefPool.Put(p)
+	}
+}
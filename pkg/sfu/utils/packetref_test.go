@@ -0,0 +1,44 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/mediatransportutil/pkg/bucket"
+)
+
+func TestPacketRef(t *testing.T) {
+	p := NewPacketRef()
+	require.Len(t, p.Bytes(), bucket.MaxPktSize)
+	require.EqualValues(t, 1, p.refs.Load())
+
+	require.Same(t, p, p.Retain())
+	require.Same(t, p, p.Retain())
+	require.EqualValues(t, 3, p.refs.Load())
+
+	p.Release()
+	p.Release()
+	require.EqualValues(t, 1, p.refs.Load())
+
+	// last release returns the buffer to the pool, taken again it starts with a single reference
+	p.Release()
+	require.Zero(t, p.refs.Load())
+	q := NewPacketRef()
+	require.EqualValues(t, 1, q.refs.Load())
+	q.Release()
+}
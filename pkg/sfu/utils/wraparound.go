@@ -211,6 +211,28 @@ func (w *WrapAround[T, ET]) isWrapBack(earlier T, later T) bool {
 
 // ------------------------------------
 
+// IsNewer returns true if val is at or ahead of ref, i. e. within half the number range after ref
+func IsNewer[T number](val T, ref T) bool {
+	var t T
+	return uint64(val-ref) < (1 << (unsafe.Sizeof(t)*8 - 1))
+}
+
+// ExtendFrom returns the extended value of val using a nearby extended reference,
+// val could be ahead of or behind the reference by less than half the number range
+func ExtendFrom[T number, ET extendedNumber](val T, extRef ET) ET {
+	ref := T(extRef)
+	if IsNewer(val, ref) {
+		return extRef + ET(val-ref)
+	}
+
+	behind := ET(ref - val)
+	if behind > extRef {
+		// reference has not completed a cycle yet, cannot go back further
+		return ET(val)
+	}
+	return extRef - behind
+}
+
 func getExtended[T number, ET extendedNumber](cycles ET, val T) ET {
 	return cycles + ET(val)
 }
@@ -676,3 +676,26 @@ func TestWrapAroundUint32(t *testing.T) {
 		})
 	}
 }
+
+func TestExtendFrom(t *testing.T) {
+	// ahead of reference, across wrap
+	require.True(t, IsNewer(uint16(5), uint16(65530)))
+	require.Equal(t, uint64(65536+5), ExtendFrom(uint16(5), uint64(65530)))
+
+	// behind reference, across wrap
+	require.False(t, IsNewer(uint16(65530), uint16(5)))
+	require.Equal(t, uint64(65530), ExtendFrom(uint16(65530), uint64(65536+5)))
+
+	// same cycle
+	require.True(t, IsNewer(uint16(100), uint16(100)))
+	require.Equal(t, uint64(3*65536+100), ExtendFrom(uint16(100), uint64(3*65536+90)))
+	require.Equal(t, uint64(3*65536+80), ExtendFrom(uint16(80), uint64(3*65536+90)))
+
+	// behind reference in first cycle does not go negative
+	require.Equal(t, uint64(65530), ExtendFrom(uint16(65530), uint64(5)))
+
+	// 32-bit
+	require.True(t, IsNewer(uint32(10), uint32(0xFFFF_FFF0)))
+	require.Equal(t, uint64(1<<32+10), ExtendFrom(uint32(10), uint64(0xFFFF_FFF0)))
+	require.Equal(t, uint64(0xFFFF_FFF0), ExtendFrom(uint32(0xFFFF_FFF0), uint64(1<<32+10)))
+}
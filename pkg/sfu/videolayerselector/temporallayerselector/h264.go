@@ -0,0 +1,58 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package temporallayerselector
+
+import (
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/protocol/logger"
+)
+
+// H264 selects temporal layers of H.264 streams using the frame marking header extension
+type H264 struct {
+	logger logger.Logger
+}
+
+func NewH264(logger logger.Logger) *H264 {
+	return &H264{
+		logger: logger,
+	}
+}
+
+func (h *H264) Select(extPkt *buffer.ExtPacket, current int32, target int32) (this int32, next int32) {
+	this = current
+	next = current
+	if current == target {
+		return
+	}
+
+	fm, ok := extPkt.Payload.(buffer.FrameMarking)
+	if !ok || !fm.IsScalable {
+		return
+	}
+
+	tid := int32(fm.TID)
+	if current < target {
+		// switch up at the start of a frame which does not depend on frames of higher layers than the base layer
+		if tid > current && tid <= target && fm.S && (fm.B || fm.I) {
+			this = tid
+			next = tid
+		}
+	} else {
+		if fm.E || extPkt.Packet.Marker {
+			next = target
+		}
+	}
+	return
+}
@@ -16,6 +16,10 @@ package telemetry
 
 import (
 	"context"
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
 
 	"go.uber.org/atomic"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -40,17 +44,28 @@ type analyticsService struct {
 	analyticsKey   string
 	nodeID         string
 	sequenceNumber atomic.Uint64
+	conf           config.TelemetryConfig
 
 	events    rpc.AnalyticsRecorderService_IngestEventsClient
 	stats     rpc.AnalyticsRecorderService_IngestStatsClient
 	nodeRooms rpc.AnalyticsRecorderService_IngestNodeRoomStatesClient
+
+	// pending batches when batching is enabled
+	batchMu       sync.Mutex
+	pendingStats  []*livekit.AnalyticsStat
+	pendingEvents []*livekit.AnalyticsEvent
 }
 
-func NewAnalyticsService(_ *config.Config, currentNode routing.LocalNode) AnalyticsService {
-	return &analyticsService{
+func NewAnalyticsService(conf *config.Config, currentNode routing.LocalNode) AnalyticsService {
+	a := &analyticsService{
 		analyticsKey: "", // TODO: conf.AnalyticsKey
 		nodeID:       currentNode.Id,
+		conf:         conf.Telemetry,
+	}
+	if a.conf.FlushInterval > 0 {
+		go a.flushWorker()
 	}
+	return a
 }
 
 func (a *analyticsService) SendStats(_ context.Context, stats []*livekit.AnalyticsStat) {
@@ -58,14 +73,35 @@ func (a *analyticsService) SendStats(_ context.Context, stats []*livekit.Analyti
 		return
 	}
 
+	sampled := make([]*livekit.AnalyticsStat, 0, len(stats))
 	for _, stat := range stats {
+		if !isSampled(stat.ParticipantId+stat.TrackId, a.conf.StatsSampleRate) {
+			continue
+		}
+
 		stat.Id = guid.New("AS_")
 		stat.AnalyticsKey = a.analyticsKey
 		stat.Node = a.nodeID
+		sampled = append(sampled, stat)
 	}
-	if err := a.stats.Send(&livekit.AnalyticsStats{Stats: stats}); err != nil {
-		logger.Errorw("failed to send stats", err)
+	if len(sampled) == 0 {
+		return
 	}
+
+	if a.conf.FlushInterval > 0 {
+		a.batchMu.Lock()
+		a.pendingStats = append(a.pendingStats, sampled...)
+		var batch []*livekit.AnalyticsStat
+		if len(a.pendingStats) >= a.conf.BatchSize {
+			batch, a.pendingStats = a.pendingStats, nil
+		}
+		a.batchMu.Unlock()
+
+		a.sendStats(batch)
+		return
+	}
+
+	a.sendStats(sampled)
 }
 
 func (a *analyticsService) SendEvent(_ context.Context, event *livekit.AnalyticsEvent) {
@@ -73,13 +109,67 @@ func (a *analyticsService) SendEvent(_ context.Context, event *livekit.Analytics
 		return
 	}
 
+	roomID := event.RoomId
+	if roomID == "" {
+		roomID = event.Room.GetSid()
+	}
+	if !isSampled(roomID, a.conf.LifecycleSampleRate) {
+		return
+	}
+
 	event.Id = guid.New("AE_")
 	event.NodeId = a.nodeID
 	event.AnalyticsKey = a.analyticsKey
-	if err := a.events.Send(&livekit.AnalyticsEvents{
-		Events: []*livekit.AnalyticsEvent{event},
-	}); err != nil {
-		logger.Errorw("failed to send event", err, "eventType", event.Type.String())
+
+	if a.conf.FlushInterval > 0 {
+		a.batchMu.Lock()
+		a.pendingEvents = append(a.pendingEvents, event)
+		var batch []*livekit.AnalyticsEvent
+		if len(a.pendingEvents) >= a.conf.BatchSize {
+			batch, a.pendingEvents = a.pendingEvents, nil
+		}
+		a.batchMu.Unlock()
+
+		a.sendEvents(batch)
+		return
+	}
+
+	a.sendEvents([]*livekit.AnalyticsEvent{event})
+}
+
+func (a *analyticsService) sendStats(stats []*livekit.AnalyticsStat) {
+	if len(stats) == 0 {
+		return
+	}
+
+	if err := a.stats.Send(&livekit.AnalyticsStats{Stats: stats}); err != nil {
+		logger.Errorw("failed to send stats", err, "count", len(stats))
+	}
+}
+
+func (a *analyticsService) sendEvents(events []*livekit.AnalyticsEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	if err := a.events.Send(&livekit.AnalyticsEvents{Events: events}); err != nil {
+		logger.Errorw("failed to send events", err, "count", len(events), "eventType", events[0].Type.String())
+	}
+}
+
+func (a *analyticsService) flush() {
+	a.batchMu.Lock()
+	stats, events := a.pendingStats, a.pendingEvents
+	a.pendingStats, a.pendingEvents = nil, nil
+	a.batchMu.Unlock()
+
+	a.sendStats(stats)
+	a.sendEvents(events)
+}
+
+func (a *analyticsService) flushWorker() {
+	for range time.Tick(a.conf.FlushInterval) {
+		a.flush()
 	}
 }
 
@@ -95,3 +185,18 @@ func (a *analyticsService) SendNodeRoomStates(_ context.Context, nodeRooms *live
 		logger.Errorw("failed to send node room states", err)
 	}
 }
+
+// isSampled deterministically selects a fraction of keys, so that all analytics of a sampled
+// track or room are sent. Analytics without a key are always sent.
+func isSampled(key string, rate float64) bool {
+	if rate >= 1 || key == "" {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum32()) < rate*math.MaxUint32
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/rpc"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+type testStatsClient struct {
+	rpc.AnalyticsRecorderService_IngestStatsClient
+
+	lock    sync.Mutex
+	batches [][]*livekit.AnalyticsStat
+}
+
+func (c *testStatsClient) Send(stats *livekit.AnalyticsStats) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.batches = append(c.batches, stats.Stats)
+	return nil
+}
+
+func (c *testStatsClient) getBatches() [][]*livekit.AnalyticsStat {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.batches
+}
+
+type testEventsClient struct {
+	rpc.AnalyticsRecorderService_IngestEventsClient
+
+	lock    sync.Mutex
+	batches [][]*livekit.AnalyticsEvent
+}
+
+func (c *testEventsClient) Send(events *livekit.AnalyticsEvents) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.batches = append(c.batches, events.Events)
+	return nil
+}
+
+func newTestAnalyticsService(conf config.TelemetryConfig) (*analyticsService, *testStatsClient, *testEventsClient) {
+	stats := &testStatsClient{}
+	events := &testEventsClient{}
+	a := &analyticsService{
+		nodeID: "node",
+		conf:   conf,
+		stats:  stats,
+		events: events,
+	}
+	return a, stats, events
+}
+
+func TestAnalyticsBatching(t *testing.T) {
+	a, stats, events := newTestAnalyticsService(config.TelemetryConfig{
+		FlushInterval:       time.Minute,
+		BatchSize:           3,
+		StatsSampleRate:     1,
+		LifecycleSampleRate: 1,
+	})
+
+	a.SendStats(context.Background(), []*livekit.AnalyticsStat{{TrackId: "TR_1"}, {TrackId: "TR_2"}})
+	require.Empty(t, stats.getBatches())
+
+	// reaching batch size sends right away
+	a.SendStats(context.Background(), []*livekit.AnalyticsStat{{TrackId: "TR_3"}})
+	require.Len(t, stats.getBatches(), 1)
+	require.Len(t, stats.getBatches()[0], 3)
+
+	// remaining stats and events are sent on flush
+	a.SendStats(context.Background(), []*livekit.AnalyticsStat{{TrackId: "TR_4"}})
+	a.SendEvent(context.Background(), &livekit.AnalyticsEvent{RoomId: "RM_1"})
+	a.SendEvent(context.Background(), &livekit.AnalyticsEvent{RoomId: "RM_1"})
+	require.Empty(t, events.batches)
+
+	a.flush()
+	require.Len(t, stats.getBatches(), 2)
+	require.Len(t, stats.getBatches()[1], 1)
+	require.Len(t, events.batches, 1)
+	require.Len(t, events.batches[0], 2)
+	require.Equal(t, "node", events.batches[0][0].NodeId)
+}
+
+func TestAnalyticsSampling(t *testing.T) {
+	a, stats, events := newTestAnalyticsService(config.TelemetryConfig{
+		StatsSampleRate:     0.5,
+		LifecycleSampleRate: 0,
+	})
+
+	var sent []*livekit.AnalyticsStat
+	for i := 0; i < 1000; i++ {
+		stat := &livekit.AnalyticsStat{ParticipantId: "PA_1", TrackId: fmt.Sprintf("TR_%d", i)}
+		sent = append(sent, stat)
+	}
+	a.SendStats(context.Background(), sent)
+	require.Len(t, stats.getBatches(), 1)
+	require.InDelta(t, 500, len(stats.getBatches()[0]), 100)
+
+	// sampling is deterministic per track
+	a.SendStats(context.Background(), sent)
+	require.Equal(t, len(stats.getBatches()[0]), len(stats.getBatches()[1]))
+
+	// lifecycle events of rooms are dropped, events without a room are always sent
+	a.SendEvent(context.Background(), &livekit.AnalyticsEvent{RoomId: "RM_1"})
+	a.SendEvent(context.Background(), &livekit.AnalyticsEvent{Room: &livekit.Room{Sid: "RM_2"}})
+	a.SendEvent(context.Background(), &livekit.AnalyticsEvent{})
+	require.Len(t, events.batches, 1)
+}
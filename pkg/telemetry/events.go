@@ -479,6 +479,35 @@ func (t *telemetryService) IngressEnded(ctx context.Context, info *livekit.Ingre
 	})
 }
 
+func (t *telemetryService) DataPacketReceived(
+	ctx context.Context,
+	roomID livekit.RoomID,
+	roomName livekit.RoomName,
+	participantID livekit.ParticipantID,
+	identity livekit.ParticipantIdentity,
+	kind livekit.DataPacket_Kind,
+	dp *livekit.DataPacket,
+	size int,
+) {
+	t.enqueue(func() {
+		user := dp.GetUser()
+		values := []interface{}{
+			"room", roomName,
+			"roomID", roomID,
+			"participant", identity,
+			"pID", participantID,
+			"kind", kind,
+			"topic", user.GetTopic(),
+			"size", size,
+			"destinationIdentities", dp.GetDestinationIdentities(),
+		}
+		if payload := user.GetPayload(); payload != nil {
+			values = append(values, "payload", payload)
+		}
+		logger.GetLogger().WithComponent("data_audit").Infow("data packet", values...)
+	})
+}
+
 // returns a livekit.Room with only name and sid filled out
 // returns nil if room is not found
 func (t *telemetryService) getRoomDetails(participantID livekit.ParticipantID) *livekit.Room {
@@ -0,0 +1,94 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promICEMuxConnections *prometheus.GaugeVec
+	promICEMuxPackets     *prometheus.CounterVec
+	promICEMuxBytes       *prometheus.CounterVec
+	promICEMuxErrors      *prometheus.CounterVec
+
+	promICEMuxPacketsUDPIncoming prometheus.Counter
+	promICEMuxPacketsUDPOutgoing prometheus.Counter
+	promICEMuxBytesUDPIncoming   prometheus.Counter
+	promICEMuxBytesUDPOutgoing   prometheus.Counter
+)
+
+func initICEMuxStats(nodeID string, nodeType livekit.NodeType) {
+	promICEMuxConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "ice_mux",
+		Name:        "connections",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "ICE connections demultiplexed on shared ports.",
+	}, []string{"protocol"})
+	promICEMuxPackets = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "ice_mux",
+		Name:        "packets",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"protocol", "direction"})
+	promICEMuxBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "ice_mux",
+		Name:        "bytes",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"protocol", "direction"})
+	promICEMuxErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "ice_mux",
+		Name:        "errors",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Failures to get a demultiplexed connection for an ICE session.",
+	}, []string{"protocol"})
+
+	prometheus.MustRegister(promICEMuxConnections)
+	prometheus.MustRegister(promICEMuxPackets)
+	prometheus.MustRegister(promICEMuxBytes)
+	prometheus.MustRegister(promICEMuxErrors)
+
+	promICEMuxPacketsUDPIncoming = promICEMuxPackets.WithLabelValues("udp", string(Incoming))
+	promICEMuxPacketsUDPOutgoing = promICEMuxPackets.WithLabelValues("udp", string(Outgoing))
+	promICEMuxBytesUDPIncoming = promICEMuxBytes.WithLabelValues("udp", string(Incoming))
+	promICEMuxBytesUDPOutgoing = promICEMuxBytes.WithLabelValues("udp", string(Outgoing))
+}
+
+func AddICEMuxConnection(protocol string) {
+	promICEMuxConnections.WithLabelValues(protocol).Add(1)
+}
+
+func SubICEMuxConnection(protocol string) {
+	promICEMuxConnections.WithLabelValues(protocol).Sub(1)
+}
+
+func IncrementICEMuxUDPPacket(direction Direction, bytes int) {
+	if direction == Incoming {
+		promICEMuxPacketsUDPIncoming.Inc()
+		promICEMuxBytesUDPIncoming.Add(float64(bytes))
+	} else {
+		promICEMuxPacketsUDPOutgoing.Inc()
+		promICEMuxBytesUDPOutgoing.Add(float64(bytes))
+	}
+}
+
+func IncrementICEMuxError(protocol string) {
+	promICEMuxErrors.WithLabelValues(protocol).Add(1)
+}
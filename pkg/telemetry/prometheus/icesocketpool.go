@@ -0,0 +1,58 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promICESocketPoolIdle  prometheus.Gauge
+	promICESocketPoolDraws *prometheus.CounterVec
+)
+
+func initICESocketPoolStats(nodeID string, nodeType livekit.NodeType) {
+	promICESocketPoolIdle = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "ice_socket_pool",
+		Name:        "idle",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Pre-bound UDP sockets waiting for a peer connection.",
+	})
+	promICESocketPoolDraws = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "ice_socket_pool",
+		Name:        "draws",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "UDP sockets requested for candidate gathering, by whether the pool had one ready.",
+	}, []string{"result"})
+
+	prometheus.MustRegister(promICESocketPoolIdle)
+	prometheus.MustRegister(promICESocketPoolDraws)
+}
+
+func AddICESocketPoolIdle(n int) {
+	promICESocketPoolIdle.Add(float64(n))
+}
+
+func RecordICESocketPoolDraw(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	promICESocketPoolDraws.WithLabelValues(result).Inc()
+}
@@ -47,7 +47,7 @@ var (
 	cpuStats *hwstats.CPUStats
 )
 
-func Init(nodeID string, nodeType livekit.NodeType) error {
+func Init(nodeID string, nodeType livekit.NodeType, conf config.PrometheusConfig) error {
 	if initialized.Swap(true) {
 		return nil
 	}
@@ -122,10 +122,14 @@ func Init(nodeID string, nodeType livekit.NodeType) error {
 
 	sysPacketsStart, sysDroppedPacketsStart, _ = getTCStats()
 
-	initPacketStats(nodeID, nodeType)
+	initPacketStats(nodeID, nodeType, conf)
 	initRoomStats(nodeID, nodeType)
 	rpc.InitPSRPCStats(prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()})
 	initQualityStats(nodeID, nodeType)
+	initICEMuxStats(nodeID, nodeType)
+	initICESocketPoolStats(nodeID, nodeType)
+	initTenantStats(nodeID, nodeType)
+	initParticipantResourceStats(nodeID, nodeType)
 
 	var err error
 	cpuStats, err = hwstats.NewCPUStats(nil)
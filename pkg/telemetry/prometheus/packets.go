@@ -15,10 +15,16 @@
 package prometheus
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/atomic"
 
 	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 )
 
 type Direction string
@@ -49,6 +55,9 @@ var (
 	promPacketBytes     *prometheus.CounterVec
 	promRTCPLabels      = []string{"direction"}
 	promStreamLabels    = []string{"direction", "source", "type"}
+	promCodecLabels     = []string{"direction", "codec"}
+	promCodecPackets    *prometheus.CounterVec
+	promCodecBytes      *prometheus.CounterVec
 	promNackTotal       *prometheus.CounterVec
 	promPliTotal        *prometheus.CounterVec
 	promFirTotal        *prometheus.CounterVec
@@ -60,6 +69,11 @@ var (
 	promConnections     *prometheus.GaugeVec
 	promForwardLatency  prometheus.Gauge
 	promForwardJitter   prometheus.Gauge
+	promLayerBitrate    *prometheus.HistogramVec
+	promSSRCCollisions  prometheus.Counter
+
+	promMetadataCacheOverflow        *prometheus.CounterVec
+	promMetadataCacheOverflowPackets *prometheus.CounterVec
 
 	promPacketTotalIncomingInitial    prometheus.Counter
 	promPacketTotalIncomingRetransmit prometheus.Counter
@@ -71,7 +85,16 @@ var (
 	promPacketBytesOutgoingRetransmit prometheus.Counter
 )
 
-func initPacketStats(nodeID string, nodeType livekit.NodeType) {
+func initPacketStats(nodeID string, nodeType livekit.NodeType, conf config.PrometheusConfig) {
+	jitterBuckets := conf.JitterBucketsUs
+	if len(jitterBuckets) == 0 {
+		jitterBuckets = buffer.DefaultJitterHistogramBucketsUs
+	}
+	rttBuckets := conf.RTTBucketsMs
+	if len(rttBuckets) == 0 {
+		rttBuckets = buffer.DefaultRTTHistogramBucketsMs
+	}
+
 	promPacketTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace:   livekitNamespace,
 		Subsystem:   "packet",
@@ -108,6 +131,13 @@ func initPacketStats(nodeID string, nodeType livekit.NodeType) {
 		Name:        "total",
 		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
 	}, promStreamLabels)
+	promSSRCCollisions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "ssrc_collision",
+		Name:        "total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Incoming streams using an SSRC of another stream still in use, remapped to a buffer of their own.",
+	})
 	promPacketLoss = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace:   livekitNamespace,
 		Subsystem:   "packet_loss",
@@ -120,17 +150,27 @@ func initPacketStats(nodeID string, nodeType livekit.NodeType) {
 		Subsystem:   "jitter",
 		Name:        "us",
 		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
-
-		// 1ms, 10ms, 30ms, 50ms, 70ms, 100ms, 300ms, 600ms, 1s
-		Buckets: []float64{1000, 10000, 30000, 50000, 70000, 100000, 300000, 600000, 1000000},
+		Buckets:     jitterBuckets,
 	}, promStreamLabels)
 	promRTT = prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Namespace:   livekitNamespace,
 		Subsystem:   "rtt",
 		Name:        "ms",
 		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
-		Buckets:     []float64{50, 100, 150, 200, 250, 500, 750, 1000, 5000, 10000},
+		Buckets:     rttBuckets,
 	}, promStreamLabels)
+	promCodecPackets = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "codec_packet",
+		Name:        "total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, promCodecLabels)
+	promCodecBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "codec_packet",
+		Name:        "bytes",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, promCodecLabels)
 	promParticipantJoin = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace:   livekitNamespace,
 		Subsystem:   "participant_join",
@@ -155,6 +195,27 @@ func initPacketStats(nodeID string, nodeType livekit.NodeType) {
 		Name:        "jitter",
 		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
 	})
+	promLayerBitrate = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "layer",
+		Name:        "bitrate",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Buckets:     []float64{50_000, 100_000, 250_000, 500_000, 750_000, 1_000_000, 1_500_000, 2_500_000, 4_000_000, 6_000_000},
+	}, []string{"spatial", "temporal"})
+	promMetadataCacheOverflow = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "metadata_cache_overflow",
+		Name:        "total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Receiver reports of subscribed tracks covering packets no longer in the sent packet metadata cache.",
+	}, []string{"type"})
+	promMetadataCacheOverflowPackets = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "metadata_cache_overflow",
+		Name:        "packets",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Packets reported by receiver reports that were no longer in the sent packet metadata cache.",
+	}, []string{"type"})
 
 	prometheus.MustRegister(promPacketTotal)
 	prometheus.MustRegister(promPacketBytes)
@@ -162,13 +223,19 @@ func initPacketStats(nodeID string, nodeType livekit.NodeType) {
 	prometheus.MustRegister(promPliTotal)
 	prometheus.MustRegister(promFirTotal)
 	prometheus.MustRegister(promPacketLossTotal)
+	prometheus.MustRegister(promSSRCCollisions)
 	prometheus.MustRegister(promPacketLoss)
 	prometheus.MustRegister(promJitter)
 	prometheus.MustRegister(promRTT)
+	prometheus.MustRegister(promCodecPackets)
+	prometheus.MustRegister(promCodecBytes)
 	prometheus.MustRegister(promParticipantJoin)
 	prometheus.MustRegister(promConnections)
 	prometheus.MustRegister(promForwardLatency)
 	prometheus.MustRegister(promForwardJitter)
+	prometheus.MustRegister(promLayerBitrate)
+	prometheus.MustRegister(promMetadataCacheOverflow)
+	prometheus.MustRegister(promMetadataCacheOverflowPackets)
 
 	promPacketTotalIncomingInitial = promPacketTotal.WithLabelValues(string(Incoming), transmissionInitial)
 	promPacketTotalIncomingRetransmit = promPacketTotal.WithLabelValues(string(Incoming), transmissionRetransmit)
@@ -243,6 +310,10 @@ func IncrementRTCP(direction Direction, nack, pli, fir uint32) {
 	}
 }
 
+func IncrementSSRCCollision() {
+	promSSRCCollisions.Inc()
+}
+
 func RecordPacketLoss(direction Direction, trackSource livekit.TrackSource, trackType livekit.TrackType, lost, total uint32) {
 	if total > 0 {
 		promPacketLoss.WithLabelValues(string(direction), trackSource.String(), trackType.String()).Observe(float64(lost) / float64(total) * 100)
@@ -264,6 +335,28 @@ func RecordRTT(direction Direction, trackSource livekit.TrackSource, trackType l
 	}
 }
 
+// IncrementCodecPackets counts packets and bytes per codec, rate of bytes gives the bitrate of each codec
+func IncrementCodecPackets(direction Direction, mime string, packets uint64, bytes uint64) {
+	codec := codecLabel(mime)
+	if packets > 0 {
+		promCodecPackets.WithLabelValues(string(direction), codec).Add(float64(packets))
+	}
+	if bytes > 0 {
+		promCodecBytes.WithLabelValues(string(direction), codec).Add(float64(bytes))
+	}
+}
+
+// codecLabel maps mime type to a bounded set of label values
+func codecLabel(mime string) string {
+	_, codec, _ := strings.Cut(strings.ToLower(mime), "/")
+	switch codec {
+	case "opus", "red", "vp8", "vp9", "h264", "av1":
+		return codec
+	default:
+		return "other"
+	}
+}
+
 func IncrementParticipantJoin(join uint32) {
 	if join > 0 {
 		participantSignalConnected.Add(uint64(join))
@@ -308,3 +401,16 @@ func RecordForwardJitter(_, jitterAvg uint32) {
 	forwardJitter.Store(jitterAvg)
 	promForwardJitter.Set(float64(jitterAvg))
 }
+
+// RecordLayerBitrate observes the measured bitrate (bps) of a published video layer,
+// accumulated with lower layers
+func RecordLayerBitrate(spatial int32, temporal int32, bitrate int64) {
+	promLayerBitrate.WithLabelValues(strconv.Itoa(int(spatial)), strconv.Itoa(int(temporal))).Observe(float64(bitrate))
+}
+
+// RecordMetadataCacheOverflow counts a receiver report of a subscribed track that covered packets
+// no longer in the sent packet metadata cache
+func RecordMetadataCacheOverflow(trackType livekit.TrackType, packetsNotFound uint64) {
+	promMetadataCacheOverflow.WithLabelValues(trackType.String()).Inc()
+	promMetadataCacheOverflowPackets.WithLabelValues(trackType.String()).Add(float64(packetsNotFound))
+}
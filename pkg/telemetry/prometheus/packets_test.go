@@ -0,0 +1,52 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+func TestCodecPackets(t *testing.T) {
+	require.NoError(t, Init("test", livekit.NodeType_SERVER, config.PrometheusConfig{}))
+
+	for mime, expected := range map[string]string{
+		"audio/opus":  "opus",
+		"audio/red":   "red",
+		"video/VP8":   "vp8",
+		"video/vp9":   "vp9",
+		"video/H264":  "h264",
+		"video/AV1":   "av1",
+		"video/H265":  "other",
+		"unsupported": "other",
+	} {
+		require.Equal(t, expected, codecLabel(mime), mime)
+	}
+
+	IncrementCodecPackets(Outgoing, "video/VP8", 2, 2000)
+	IncrementCodecPackets(Outgoing, "video/vp8", 1, 1000)
+	IncrementCodecPackets(Incoming, "audio/opus", 5, 500)
+
+	require.Equal(t, float64(3), testutil.ToFloat64(promCodecPackets.WithLabelValues(string(Outgoing), "vp8")))
+	require.Equal(t, float64(3000), testutil.ToFloat64(promCodecBytes.WithLabelValues(string(Outgoing), "vp8")))
+	require.Equal(t, float64(5), testutil.ToFloat64(promCodecPackets.WithLabelValues(string(Incoming), "opus")))
+	require.Equal(t, float64(500), testutil.ToFloat64(promCodecBytes.WithLabelValues(string(Incoming), "opus")))
+}
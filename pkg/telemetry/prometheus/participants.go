@@ -0,0 +1,102 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// ParticipantResources is load a participant session puts on the node
+type ParticipantResources struct {
+	Room          livekit.RoomName
+	ParticipantID livekit.ParticipantID
+	Identity      livekit.ParticipantIdentity
+	Goroutines    int
+	QueuedOps     int
+	PacketsPerSec float64
+}
+
+var (
+	promParticipantGoroutines    *prometheus.GaugeVec
+	promParticipantQueuedOps     *prometheus.GaugeVec
+	promParticipantPacketsPerSec *prometheus.GaugeVec
+)
+
+func initParticipantResourceStats(nodeID string, nodeType livekit.NodeType) {
+	labels := []string{"room", "participant_id", "identity"}
+	promParticipantGoroutines = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "participant",
+		Name:        "goroutines",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Goroutines of the participants running the most goroutines on the node.",
+	}, labels)
+	promParticipantQueuedOps = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "participant",
+		Name:        "queued_ops",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Queued ops of the participants with the most queued ops on the node.",
+	}, labels)
+	promParticipantPacketsPerSec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "participant",
+		Name:        "packets_per_sec",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Packet rate of the participants processing the most packets on the node.",
+	}, labels)
+
+	prometheus.MustRegister(promParticipantGoroutines)
+	prometheus.MustRegister(promParticipantQueuedOps)
+	prometheus.MustRegister(promParticipantPacketsPerSec)
+}
+
+// RecordHottestParticipants replaces the per participant gauges with the top n participants by each resource,
+// labels are limited to a few participants to keep cardinality bounded
+func RecordHottestParticipants(participants []ParticipantResources, n int) {
+	if promParticipantGoroutines == nil {
+		return
+	}
+
+	recordHottest(promParticipantGoroutines, participants, n, func(p ParticipantResources) float64 { return float64(p.Goroutines) })
+	recordHottest(promParticipantQueuedOps, participants, n, func(p ParticipantResources) float64 { return float64(p.QueuedOps) })
+	recordHottest(promParticipantPacketsPerSec, participants, n, func(p ParticipantResources) float64 { return p.PacketsPerSec })
+}
+
+func recordHottest(gauge *prometheus.GaugeVec, participants []ParticipantResources, n int, value func(p ParticipantResources) float64) {
+	gauge.Reset()
+
+	sorted := slices.Clone(participants)
+	slices.SortFunc(sorted, func(a, b ParticipantResources) int {
+		va, vb := value(a), value(b)
+		switch {
+		case va > vb:
+			return -1
+		case va < vb:
+			return 1
+		default:
+			return 0
+		}
+	})
+	for _, p := range sorted[:min(n, len(sorted))] {
+		if v := value(p); v > 0 {
+			gauge.WithLabelValues(string(p.Room), string(p.ParticipantID), string(p.Identity)).Set(v)
+		}
+	}
+}
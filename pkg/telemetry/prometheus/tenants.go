@@ -0,0 +1,75 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promTenantRoomCurrent        *prometheus.GaugeVec
+	promTenantParticipantCurrent *prometheus.GaugeVec
+	promTenantQuotaExceeded      *prometheus.CounterVec
+)
+
+func initTenantStats(nodeID string, nodeType livekit.NodeType) {
+	promTenantRoomCurrent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "tenant",
+		Name:        "room_total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Rooms of a tenant hosted on the node.",
+	}, []string{"tenant"})
+	promTenantParticipantCurrent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "tenant",
+		Name:        "participant_total",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Participants in rooms of a tenant hosted on the node.",
+	}, []string{"tenant"})
+	promTenantQuotaExceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "tenant",
+		Name:        "quota_exceeded",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Requests rejected because a tenant quota was reached.",
+	}, []string{"tenant", "quota"})
+
+	prometheus.MustRegister(promTenantRoomCurrent)
+	prometheus.MustRegister(promTenantParticipantCurrent)
+	prometheus.MustRegister(promTenantQuotaExceeded)
+}
+
+func TenantRoomStarted(tenant string) {
+	promTenantRoomCurrent.WithLabelValues(tenant).Add(1)
+}
+
+func TenantRoomEnded(tenant string) {
+	promTenantRoomCurrent.WithLabelValues(tenant).Sub(1)
+}
+
+func AddTenantParticipant(tenant string) {
+	promTenantParticipantCurrent.WithLabelValues(tenant).Add(1)
+}
+
+func SubTenantParticipant(tenant string) {
+	promTenantParticipantCurrent.WithLabelValues(tenant).Sub(1)
+}
+
+func RecordTenantQuotaExceeded(tenant string, quota string) {
+	promTenantQuotaExceeded.WithLabelValues(tenant, quota).Add(1)
+}
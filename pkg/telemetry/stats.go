@@ -62,6 +62,15 @@ func (t *telemetryService) TrackStats(key StatsKey, stat *livekit.AnalyticsStat)
 		retransmitBytes := uint64(0)
 		retransmitPackets := uint32(0)
 		for _, stream := range stat.Streams {
+			if key.track && stat.Mime != "" {
+				prometheus.IncrementCodecPackets(
+					direction,
+					stat.Mime,
+					uint64(stream.PrimaryPackets+stream.RetransmitPackets+stream.PaddingPackets),
+					stream.PrimaryBytes+stream.RetransmitBytes+stream.PaddingBytes,
+				)
+			}
+
 			nacks += stream.Nacks
 			plis += stream.Plis
 			firs += stream.Firs
@@ -10,6 +10,18 @@ import (
 )
 
 type FakeTelemetryService struct {
+	DataPacketReceivedStub        func(context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantID, livekit.ParticipantIdentity, livekit.DataPacket_Kind, *livekit.DataPacket, int)
+	dataPacketReceivedMutex       sync.RWMutex
+	dataPacketReceivedArgsForCall []struct {
+		arg1 context.Context
+		arg2 livekit.RoomID
+		arg3 livekit.RoomName
+		arg4 livekit.ParticipantID
+		arg5 livekit.ParticipantIdentity
+		arg6 livekit.DataPacket_Kind
+		arg7 *livekit.DataPacket
+		arg8 int
+	}
 	EgressEndedStub        func(context.Context, *livekit.EgressInfo)
 	egressEndedMutex       sync.RWMutex
 	egressEndedArgsForCall []struct {
@@ -257,6 +269,45 @@ type FakeTelemetryService struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeTelemetryService) DataPacketReceived(arg1 context.Context, arg2 livekit.RoomID, arg3 livekit.RoomName, arg4 livekit.ParticipantID, arg5 livekit.ParticipantIdentity, arg6 livekit.DataPacket_Kind, arg7 *livekit.DataPacket, arg8 int) {
+	fake.dataPacketReceivedMutex.Lock()
+	fake.dataPacketReceivedArgsForCall = append(fake.dataPacketReceivedArgsForCall, struct {
+		arg1 context.Context
+		arg2 livekit.RoomID
+		arg3 livekit.RoomName
+		arg4 livekit.ParticipantID
+		arg5 livekit.ParticipantIdentity
+		arg6 livekit.DataPacket_Kind
+		arg7 *livekit.DataPacket
+		arg8 int
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8})
+	stub := fake.DataPacketReceivedStub
+	fake.recordInvocation("DataPacketReceived", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8})
+	fake.dataPacketReceivedMutex.Unlock()
+	if stub != nil {
+		fake.DataPacketReceivedStub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	}
+}
+
+func (fake *FakeTelemetryService) DataPacketReceivedCallCount() int {
+	fake.dataPacketReceivedMutex.RLock()
+	defer fake.dataPacketReceivedMutex.RUnlock()
+	return len(fake.dataPacketReceivedArgsForCall)
+}
+
+func (fake *FakeTelemetryService) DataPacketReceivedCalls(stub func(context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantID, livekit.ParticipantIdentity, livekit.DataPacket_Kind, *livekit.DataPacket, int)) {
+	fake.dataPacketReceivedMutex.Lock()
+	defer fake.dataPacketReceivedMutex.Unlock()
+	fake.DataPacketReceivedStub = stub
+}
+
+func (fake *FakeTelemetryService) DataPacketReceivedArgsForCall(i int) (context.Context, livekit.RoomID, livekit.RoomName, livekit.ParticipantID, livekit.ParticipantIdentity, livekit.DataPacket_Kind, *livekit.DataPacket, int) {
+	fake.dataPacketReceivedMutex.RLock()
+	defer fake.dataPacketReceivedMutex.RUnlock()
+	argsForCall := fake.dataPacketReceivedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7, argsForCall.arg8
+}
+
 func (fake *FakeTelemetryService) EgressEnded(arg1 context.Context, arg2 *livekit.EgressInfo) {
 	fake.egressEndedMutex.Lock()
 	fake.egressEndedArgsForCall = append(fake.egressEndedArgsForCall, struct {
@@ -1419,6 +1470,8 @@ func (fake *FakeTelemetryService) TrackUnsubscribedArgsForCall(i int) (context.C
 func (fake *FakeTelemetryService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.dataPacketReceivedMutex.RLock()
+	defer fake.dataPacketReceivedMutex.RUnlock()
 	fake.egressEndedMutex.RLock()
 	defer fake.egressEndedMutex.RUnlock()
 	fake.egressStartedMutex.RLock()
@@ -75,6 +75,9 @@ type TelemetryService interface {
 	IngressUpdated(ctx context.Context, info *livekit.IngressInfo)
 	IngressEnded(ctx context.Context, info *livekit.IngressInfo)
 	LocalRoomState(ctx context.Context, info *livekit.AnalyticsNodeRooms)
+	// DataPacketReceived - a user data packet was sent by a participant, used to audit data traffic,
+	// payload could have been stripped, size is the size of the original payload
+	DataPacketReceived(ctx context.Context, roomID livekit.RoomID, roomName livekit.RoomName, participantID livekit.ParticipantID, identity livekit.ParticipantIdentity, kind livekit.DataPacket_Kind, dp *livekit.DataPacket, size int)
 
 	// helpers
 	AnalyticsService
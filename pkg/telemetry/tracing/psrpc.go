@@ -0,0 +1,57 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/tracer"
+	"github.com/livekit/psrpc"
+	"github.com/livekit/psrpc/pkg/metadata"
+)
+
+// AppendToOutgoingContext carries the span in ctx to the receiver of psrpc requests made with the returned context
+func AppendToOutgoingContext(ctx context.Context) context.Context {
+	if tp := Inject(ctx); tp != "" {
+		return metadata.AppendMetadataToOutgoingContext(ctx, TraceParentKey, tp)
+	}
+	return ctx
+}
+
+// FromIncomingContext returns a context whose spans are children of the span of the psrpc request sender
+func FromIncomingContext(ctx context.Context) context.Context {
+	if head := metadata.IncomingHeader(ctx); head != nil {
+		return Extract(ctx, head.Metadata[TraceParentKey])
+	}
+	return ctx
+}
+
+// PSRPCServerInterceptor wraps each handled psrpc request in a server span continuing the sender's trace
+func PSRPCServerInterceptor() psrpc.ServerRPCInterceptor {
+	return func(ctx context.Context, req proto.Message, info psrpc.RPCInfo, handler psrpc.ServerRPCHandler) (proto.Message, error) {
+		ctx, span := tracer.Start(FromIncomingContext(ctx), info.Service+"."+info.Method, SpanKindServer,
+			Attr("rpc.system", "psrpc"),
+			Attr("rpc.service", info.Service),
+			Attr("rpc.method", info.Method),
+		)
+		defer span.End()
+
+		res, err := handler(ctx, req)
+		span.RecordError(err)
+		return res, err
+	}
+}
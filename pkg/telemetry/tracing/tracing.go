@@ -0,0 +1,190 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/tracer"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/version"
+)
+
+// TraceParentKey is the W3C trace context header, also used as psrpc metadata key
+const TraceParentKey = "traceparent"
+
+// SpanKind values match trace.SpanKind
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota + 1
+	SpanKindServer
+	SpanKindClient
+)
+
+// Attribute is passed as a start option to tracer.Start or to SetAttributes.
+// Values are exported as string, int, float or bool, anything else is formatted with %v
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Init installs an OTLP exporting tracer as the protocol tracer when an endpoint is configured.
+// The returned function flushes pending spans and must be called on shutdown
+func Init(conf config.TracingConfig, nodeID string) func() {
+	if conf.OTLPEndpoint == "" {
+		return func() {}
+	}
+
+	provider, err := newTracerProvider(conf, nodeID)
+	if err != nil {
+		logger.Errorw("could not create trace exporter", err, "endpoint", conf.OTLPEndpoint)
+		return func() {}
+	}
+	tracer.SetTracer(NewTracer(provider))
+	logger.Infow("tracing enabled", "endpoint", conf.OTLPEndpoint, "sampleRatio", conf.SampleRatio)
+	return func() {
+		tracer.SetTracer(&tracer.NoOpTracer{})
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logger.Warnw("failed to export spans", err)
+		}
+	}
+}
+
+// Inject returns the traceparent of the span in ctx, empty when there is none
+func Inject(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get(TraceParentKey)
+}
+
+// Extract returns a context whose spans are children of the remote span described by traceparent.
+// ctx is returned as is when traceparent is empty or malformed
+func Extract(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{TraceParentKey: traceParent})
+}
+
+// SetAttributes adds attributes to a span started by this package's tracer, other spans are ignored
+func SetAttributes(span tracer.Span, attrs ...Attribute) {
+	if s, ok := span.(*Span); ok {
+		s.SetAttributes(attrs...)
+	}
+}
+
+// ------------------------------------------------
+
+// Tracer adapts an OpenTelemetry tracer provider to the protocol tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: provider.Tracer("github.com/livekit/livekit-server", trace.WithInstrumentationVersion(version.Version)),
+	}
+}
+
+func (t *Tracer) Start(ctx context.Context, spanName string, opts ...interface{}) (context.Context, tracer.Span) {
+	kind := SpanKindInternal
+	var attrs []attribute.KeyValue
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case SpanKind:
+			kind = o
+		case Attribute:
+			attrs = append(attrs, toKeyValue(o))
+		case []Attribute:
+			attrs = append(attrs, toKeyValues(o)...)
+		}
+	}
+
+	ctx, span := t.tracer.Start(ctx, spanName, trace.WithSpanKind(trace.SpanKind(kind)), trace.WithAttributes(attrs...))
+	return ctx, &Span{span: span}
+}
+
+// ------------------------------------------------
+
+type Span struct {
+	span trace.Span
+}
+
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.span.SetAttributes(toKeyValues(attrs)...)
+}
+
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *Span) End() {
+	s.span.End()
+}
+
+func toKeyValues(attrs []Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		kvs = append(kvs, toKeyValue(a))
+	}
+	return kvs
+}
+
+func toKeyValue(a Attribute) attribute.KeyValue {
+	switch v := a.Value.(type) {
+	case string:
+		return attribute.String(a.Key, v)
+	case bool:
+		return attribute.Bool(a.Key, v)
+	case float64:
+		return attribute.Float64(a.Key, v)
+	case float32:
+		return attribute.Float64(a.Key, float64(v))
+	case int:
+		return attribute.Int(a.Key, v)
+	case int32:
+		return attribute.Int64(a.Key, int64(v))
+	case int64:
+		return attribute.Int64(a.Key, v)
+	case uint32:
+		return attribute.Int64(a.Key, int64(v))
+	case uint64:
+		return attribute.Int64(a.Key, int64(v))
+	case fmt.Stringer:
+		return attribute.String(a.Key, v.String())
+	default:
+		return attribute.String(a.Key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+func newTestTracer(sampleRatio float64) (*Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	return NewTracer(provider), recorder
+}
+
+func TestTraceParent(t *testing.T) {
+	tr, recorder := newTestTracer(1)
+	ctx, span := tr.Start(context.Background(), "root")
+	span.End()
+
+	tp := Inject(ctx)
+	require.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, tp)
+
+	remote := Extract(context.Background(), tp)
+	require.Equal(t, tp, Inject(remote))
+
+	_, child := tr.Start(remote, "child")
+	child.End()
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 2)
+	require.Equal(t, ended[0].SpanContext().TraceID(), ended[1].SpanContext().TraceID())
+	require.Equal(t, ended[0].SpanContext().SpanID(), ended[1].Parent().SpanID())
+	require.True(t, ended[1].SpanContext().IsSampled())
+
+	for _, invalid := range []string{
+		"",
+		"garbage",
+		"00-00000000000000000000000000000000-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b-01",
+	} {
+		require.Empty(t, Inject(Extract(context.Background(), invalid)), invalid)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	tr, recorder := newTestTracer(0)
+
+	ctx, root := tr.Start(context.Background(), "root")
+	_, child := tr.Start(ctx, "child")
+	child.End()
+	root.End()
+	require.Empty(t, recorder.Ended())
+	require.Regexp(t, `-00$`, Inject(ctx))
+
+	// sampled remote parent is followed regardless of ratio
+	remote := Extract(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, child = tr.Start(remote, "child")
+	child.End()
+	child.End()
+	require.Len(t, recorder.Ended(), 1)
+}
+
+func TestExporter(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path)
+		require.Equal(t, "secret", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	provider, err := newTracerProvider(config.TracingConfig{
+		OTLPEndpoint: srv.URL + "/",
+		Headers:      map[string]string{"Authorization": "secret"},
+		SampleRatio:  1,
+	}, "node")
+	require.NoError(t, err)
+	tr := NewTracer(provider)
+
+	ctx, root := tr.Start(context.Background(), "root", SpanKindServer, Attr("room", "myroom"))
+	_, child := tr.Start(ctx, "child", []Attribute{Attr("count", 3), Attr("ok", true)})
+	child.RecordError(errors.New("failed"))
+	child.End()
+	root.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	var req coltracepb.ExportTraceServiceRequest
+	select {
+	case body := <-bodies:
+		require.NoError(t, proto.Unmarshal(body, &req))
+	case <-time.After(5 * time.Second):
+		t.Fatal("spans not exported")
+	}
+
+	require.Len(t, req.ResourceSpans, 1)
+	rs := req.ResourceSpans[0]
+	var serviceName string
+	for _, kv := range rs.Resource.Attributes {
+		if kv.Key == "service.name" {
+			serviceName = kv.Value.GetStringValue()
+		}
+	}
+	require.Equal(t, "livekit-server", serviceName)
+
+	spans := rs.ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	c, r := spans[0], spans[1]
+	require.Equal(t, "child", c.Name)
+	require.Equal(t, r.TraceId, c.TraceId)
+	require.Equal(t, r.SpanId, c.ParentSpanId)
+	require.Equal(t, tracepb.Span_SPAN_KIND_INTERNAL, c.Kind)
+	require.Equal(t, int64(3), c.Attributes[0].Value.GetIntValue())
+	require.True(t, c.Attributes[1].Value.GetBoolValue())
+	require.Equal(t, tracepb.Status_STATUS_CODE_ERROR, c.Status.Code)
+	require.Equal(t, "failed", c.Status.Message)
+
+	require.Equal(t, "root", r.Name)
+	require.Empty(t, r.ParentSpanId)
+	require.Equal(t, tracepb.Span_SPAN_KIND_SERVER, r.Kind)
+	require.Equal(t, "myroom", r.Attributes[0].Value.GetStringValue())
+	require.Equal(t, tracepb.Status_STATUS_CODE_UNSET, r.Status.GetCode())
+}
+
+func TestSpanKind(t *testing.T) {
+	require.Equal(t, trace.SpanKindInternal, trace.SpanKind(SpanKindInternal))
+	require.Equal(t, trace.SpanKindServer, trace.SpanKind(SpanKindServer))
+	require.Equal(t, trace.SpanKindClient, trace.SpanKind(SpanKindClient))
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pion/transport/v2"
+
+	"github.com/livekit/protocol/logger"
+)
+
+// DSCP code points of RFC 4594 service classes commonly used for real-time traffic
+const (
+	DSCPExpeditedForwarding uint8 = 46 // EF, telephony/audio
+	DSCPAF41                uint8 = 34 // AF41, interactive video
+)
+
+var ErrDSCPNotSupported = errors.New("DSCP marking not supported on this platform")
+
+// DSCP code points are carried in the upper six bits of the IPv4 TOS and IPv6 traffic class fields
+func dscpToTOS(dscp uint8) int {
+	return int(dscp&0x3f) << 2
+}
+
+// SetDSCP marks packets sent on a socket with a DSCP code point
+func SetDSCP(conn syscall.Conn, ipv6 bool, dscp uint8) error {
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var setErr error
+	if err := rc.Control(func(fd uintptr) {
+		setErr = setTOS(fd, ipv6, dscpToTOS(dscp))
+	}); err != nil {
+		return err
+	}
+	return setErr
+}
+
+// DSCPControlMessage returns a socket control message marking a single packet with a DSCP code point,
+// to be passed as oob data to WriteMsgUDP. It is nil where per packet marking is not supported.
+func DSCPControlMessage(ipv6 bool, dscp uint8) []byte {
+	return tosControlMessage(ipv6, dscpToTOS(dscp))
+}
+
+// DSCPControl returns a net.ListenConfig/net.Dialer control function marking sockets with a DSCP code point
+func DSCPControl(dscp uint8) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var setErr error
+		if err := c.Control(func(fd uintptr) {
+			setErr = setTOS(fd, strings.HasSuffix(network, "6"), dscpToTOS(dscp))
+		}); err != nil {
+			return err
+		}
+		if setErr != nil {
+			logger.Warnw("could not set DSCP", setErr, "network", network, "address", address)
+		}
+		return nil
+	}
+}
+
+// IsIPv6Addr returns true for addresses of IPv6 sockets, including the unspecified address of dual-stack sockets
+func IsIPv6Addr(addr net.Addr) bool {
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		ip = a.IP
+	case *net.TCPAddr:
+		ip = a.IP
+	default:
+		return false
+	}
+	return ip.To4() == nil
+}
+
+// DSCPNet marks packets sent on UDP sockets it creates with a DSCP code point,
+// used as the network of pion ICE agents and TURN relays
+type DSCPNet struct {
+	transport.Net
+	DSCP uint8
+
+	warnOnce sync.Once
+}
+
+func (n *DSCPNet) ListenUDP(network string, laddr *net.UDPAddr) (transport.UDPConn, error) {
+	conn, err := n.Net.ListenUDP(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	n.mark(conn, conn.LocalAddr())
+	return conn, nil
+}
+
+func (n *DSCPNet) ListenPacket(network string, address string) (net.PacketConn, error) {
+	conn, err := n.Net.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	n.mark(conn, conn.LocalAddr())
+	return conn, nil
+}
+
+func (n *DSCPNet) mark(conn any, addr net.Addr) {
+	if n.DSCP == 0 {
+		return
+	}
+
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return
+	}
+	if err := SetDSCP(sc, IsIPv6Addr(addr), n.DSCP); err != nil {
+		n.warnOnce.Do(func() {
+			logger.Warnw("could not set DSCP", err, "addr", addr)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package utils
+
+import (
+	"encoding/binary"
+	"syscall"
+	"unsafe"
+)
+
+func setTOS(fd uintptr, ipv6 bool, tos int) error {
+	if ipv6 {
+		if err := syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS, tos); err != nil {
+			return err
+		}
+		// dual-stack sockets send IPv4 packets too, IPv6 only sockets reject the option
+		_ = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, tos)
+		return nil
+	}
+	return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, tos)
+}
+
+func tosControlMessage(ipv6 bool, tos int) []byte {
+	level, typ := syscall.IPPROTO_IP, syscall.IP_TOS
+	if ipv6 {
+		level, typ = syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS
+	}
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	h := (*syscall.Cmsghdr)(unsafe.Pointer(&oob[0]))
+	h.Level = int32(level)
+	h.Type = int32(typ)
+	h.SetLen(syscall.CmsgLen(4))
+	binary.NativeEndian.PutUint32(oob[syscall.CmsgLen(0):], uint32(tos))
+	return oob
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package utils
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/pion/transport/v2/stdnet"
+	"github.com/stretchr/testify/require"
+)
+
+func getTOS(t *testing.T, conn syscall.Conn, ipv6 bool) int {
+	rc, err := conn.SyscallConn()
+	require.NoError(t, err)
+
+	var tos int
+	var getErr error
+	require.NoError(t, rc.Control(func(fd uintptr) {
+		if ipv6 {
+			tos, getErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS)
+		} else {
+			tos, getErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS)
+		}
+	}))
+	require.NoError(t, getErr)
+	return tos
+}
+
+func TestDSCPNet(t *testing.T) {
+	sn, err := stdnet.NewNet()
+	require.NoError(t, err)
+
+	n := &DSCPNet{Net: sn, DSCP: DSCPAF41}
+
+	t.Run("udp", func(t *testing.T) {
+		conn, err := n.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.Equal(t, 34<<2, getTOS(t, conn.(syscall.Conn), false))
+	})
+
+	t.Run("packet", func(t *testing.T) {
+		conn, err := n.ListenPacket("udp4", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.Equal(t, 34<<2, getTOS(t, conn.(syscall.Conn), false))
+	})
+
+	t.Run("listen control", func(t *testing.T) {
+		lc := net.ListenConfig{Control: DSCPControl(DSCPExpeditedForwarding)}
+		ln, err := lc.Listen(context.Background(), "tcp4", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		require.Equal(t, 46<<2, getTOS(t, ln.(syscall.Conn), false))
+	})
+}
+
+func TestDSCPControlMessage(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	oob := DSCPControlMessage(false, DSCPExpeditedForwarding)
+	require.NotNil(t, oob)
+
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	require.Equal(t, int32(syscall.IPPROTO_IP), msgs[0].Header.Level)
+	require.Equal(t, int32(syscall.IP_TOS), msgs[0].Header.Type)
+
+	// kernel accepts the control message
+	n, oobn, err := conn.WriteMsgUDP([]byte{1, 2, 3}, oob, conn.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, len(oob), oobn)
+}
@@ -12,22 +12,14 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package sfu
+//go:build !linux
 
-import (
-	"sync"
-)
+package utils
 
-var (
-	PacketFactory *sync.Pool
-)
+func setTOS(_ uintptr, _ bool, _ int) error {
+	return ErrDSCPNotSupported
+}
 
-func init() {
-	// Init packet factory
-	PacketFactory = &sync.Pool{
-		New: func() interface{} {
-			b := make([]byte, 1460)
-			return &b
-		},
-	}
+func tosControlMessage(_ bool, _ int) []byte {
+	return nil
 }
@@ -132,6 +132,14 @@ func (oq *opsQueueBase[T]) Enqueue(op T) {
 	}
 }
 
+// Len returns the number of ops waiting to run
+func (oq *opsQueueBase[T]) Len() int {
+	oq.lock.Lock()
+	defer oq.lock.Unlock()
+
+	return oq.ops.Len()
+}
+
 func (oq *opsQueueBase[T]) process() {
 	defer close(oq.doneChan)
 
@@ -0,0 +1,73 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a rate limiter refilling at a steady rate up to a burst.
+// Callers reserve a token and may be asked to wait for it, allowing short queues
+// instead of outright rejections when a burst is exhausted.
+type TokenBucket struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a full bucket, refilling at rate tokens per second
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Reserve takes a token, returning how long the caller has to wait before using it.
+// If the wait would exceed maxWait, no token is taken and ok is false,
+// wait is then the time until a token becomes available.
+func (t *TokenBucket) Reserve(maxWait time.Duration) (wait time.Duration, ok bool) {
+	return t.reserveAt(time.Now(), maxWait)
+}
+
+func (t *TokenBucket) reserveAt(now time.Time, maxWait time.Duration) (time.Duration, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if elapsed := now.Sub(t.last); elapsed > 0 {
+		t.tokens = min(t.burst, t.tokens+elapsed.Seconds()*t.rate)
+		t.last = now
+	}
+
+	tokens := t.tokens - 1
+	var wait time.Duration
+	if tokens < 0 {
+		wait = time.Duration(-tokens / t.rate * float64(time.Second))
+	}
+	if wait > maxWait {
+		return wait, false
+	}
+
+	t.tokens = tokens
+	return wait, true
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("burst", func(t *testing.T) {
+		tb := NewTokenBucket(10, 2)
+		now := tb.last
+
+		for i := 0; i < 2; i++ {
+			wait, ok := tb.reserveAt(now, 0)
+			require.True(t, ok)
+			require.Zero(t, wait)
+		}
+
+		wait, ok := tb.reserveAt(now, 0)
+		require.False(t, ok)
+		require.Equal(t, 100*time.Millisecond, wait)
+
+		// refilled
+		wait, ok = tb.reserveAt(now.Add(100*time.Millisecond), 0)
+		require.True(t, ok)
+		require.Zero(t, wait)
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		tb := NewTokenBucket(10, 1)
+		now := tb.last
+
+		_, ok := tb.reserveAt(now, 0)
+		require.True(t, ok)
+
+		// queued requests wait in turn
+		wait, ok := tb.reserveAt(now, 250*time.Millisecond)
+		require.True(t, ok)
+		require.Equal(t, 100*time.Millisecond, wait)
+
+		wait, ok = tb.reserveAt(now, 250*time.Millisecond)
+		require.True(t, ok)
+		require.Equal(t, 200*time.Millisecond, wait)
+
+		// rejected request does not take a token
+		wait, ok = tb.reserveAt(now, 250*time.Millisecond)
+		require.False(t, ok)
+		require.Equal(t, 300*time.Millisecond, wait)
+
+		wait, ok = tb.reserveAt(now, 250*time.Millisecond)
+		require.False(t, ok)
+		require.Equal(t, 300*time.Millisecond, wait)
+	})
+}
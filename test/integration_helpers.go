@@ -58,7 +58,7 @@ var roomClient livekit.RoomService
 func init() {
 	config.InitLoggerFromConfig(&config.DefaultConfig.Logging)
 
-	prometheus.Init("test", livekit.NodeType_SERVER)
+	prometheus.Init("test", livekit.NodeType_SERVER, config.DefaultConfig.Prometheus)
 }
 
 func setupSingleNodeTest(name string) (*service.LivekitServer, func()) {